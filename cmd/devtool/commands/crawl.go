@@ -0,0 +1,140 @@
+package commands
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"github.com/tedwangl/go-util/pkg/cobrax"
+	"github.com/tedwangl/go-util/pkg/collyx"
+	"github.com/tedwangl/go-util/pkg/collyx/storage"
+)
+
+// RegisterCrawlCommands 注册爬虫相关命令
+func RegisterCrawlCommands(tool *cobrax.Tool) {
+	crawlGroup := cobrax.NewCommandGroup("crawl")
+
+	crawlCmd := tool.NewCommand(
+		"crawl",
+		"爬虫工具",
+		"collyx 爬虫数据工具（存储与 collyx 配置一致：sqlite 使用 <dir>/crawler.db，mysql、postgres 使用 --dsn）",
+		nil,
+	)
+	crawlCmd.Command.GroupID = "crawl"
+	crawlCmd.AddPersistentFlag("type", "t", "sqlite", "存储类型: sqlite, mysql, postgres")
+	crawlCmd.AddPersistentFlag("dir", "", "./data", "存储目录（sqlite）")
+	crawlCmd.AddPersistentFlag("dsn", "", "", "数据库连接（mysql、postgres）")
+
+	// crawl export - 导出爬取的内容
+	exportCmd := tool.NewCommand(
+		"export",
+		"导出爬取的内容",
+		"将爬取的内容导出为 CSV、JSON Lines 或 Parquet，分批读取，不需要直接查询爬虫数据库\n\n"+
+			"  devtool crawl export --format csv --fields url,title,price -o items.csv\n"+
+			"  devtool crawl export --item-type data --format parquet -o items.parquet",
+		cobrax.CmdRunnerFunc(func(cmd *cobra.Command, args []string) error {
+			format := collyx.ExportFormat(viper.GetString("format"))
+			output := viper.GetString("output")
+			if output == "" || output == "-" {
+				if format == collyx.ExportParquet {
+					return fmt.Errorf("Parquet 格式需要使用 --output 指定输出文件")
+				}
+			} else if format == "" {
+				// 未指定格式时按文件扩展名判断
+				format = collyx.ExportFormat(strings.TrimPrefix(filepath.Ext(output), "."))
+			}
+			if format == "" {
+				format = collyx.ExportCSV
+			}
+
+			filter := &storage.ItemFilter{
+				TaskID: viper.GetString("task"),
+				Limit:  viper.GetInt("limit"),
+			}
+			for _, t := range splitList(viper.GetString("item-type")) {
+				filter.Type = append(filter.Type, storage.ItemType(t))
+			}
+			for _, s := range splitList(viper.GetString("status")) {
+				filter.Status = append(filter.Status, storage.ItemStatus(s))
+			}
+
+			store, err := openCrawlStorage()
+			if err != nil {
+				return err
+			}
+			defer store.Close()
+
+			var w io.Writer = os.Stdout
+			if output != "" && output != "-" {
+				f, err := os.Create(output)
+				if err != nil {
+					return fmt.Errorf("创建输出文件失败: %w", err)
+				}
+				defer f.Close()
+				w = f
+			}
+
+			n, err := collyx.Export(store, filter, format, w, splitList(viper.GetString("fields"))...)
+			if err != nil {
+				return err
+			}
+			if w != os.Stdout {
+				fmt.Fprintf(os.Stderr, "已导出 %d 条内容到 %s\n", n, output)
+			}
+			return nil
+		}),
+	)
+	exportCmd.AddFlag("format", "f", "", "导出格式: csv, jsonl, parquet（默认按输出文件扩展名，否则为 csv）")
+	exportCmd.AddFlag("output", "o", "", "输出文件（默认输出到标准输出）")
+	exportCmd.AddFlag("fields", "", "", "导出字段，逗号分隔（内容列名或元数据字段，如 url,title,price）")
+	exportCmd.AddFlag("task", "", "", "只导出该任务的内容")
+	exportCmd.AddFlag("item-type", "", "", "内容类型，逗号分隔: html, image, file, data")
+	exportCmd.AddFlag("status", "s", "", "内容状态，逗号分隔: pending, saved, failed, skipped")
+	exportCmd.AddFlag("limit", "n", 0, "最多导出的数量（0 为不限制）")
+
+	crawlCmd.Command.AddCommand(exportCmd.Command)
+
+	crawlGroup.AddCommand(crawlCmd)
+	tool.AddGroupLogic(crawlGroup)
+}
+
+// openCrawlStorage 按命令参数打开爬虫存储
+func openCrawlStorage() (storage.Storage, error) {
+	var (
+		store storage.Storage
+		err   error
+	)
+	switch typ := viper.GetString("type"); typ {
+	case "sqlite":
+		dbPath := filepath.Join(viper.GetString("dir"), "crawler.db")
+		if _, statErr := os.Stat(dbPath); statErr != nil {
+			return nil, fmt.Errorf("爬虫数据库不存在: %s", dbPath)
+		}
+		store, err = storage.NewSQLiteStorage(dbPath)
+	case "mysql":
+		store, err = storage.NewMySQLStorage(viper.GetString("dsn"))
+	case "postgres":
+		store, err = storage.NewPostgresStorage(viper.GetString("dsn"))
+	default:
+		return nil, fmt.Errorf("不支持的存储类型: %s", typ)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("打开存储失败: %w", err)
+	}
+	return store, nil
+}
+
+// splitList 拆分逗号分隔的参数，去除空项
+func splitList(s string) []string {
+	var list []string
+	for _, part := range strings.Split(s, ",") {
+		if part = strings.TrimSpace(part); part != "" {
+			list = append(list, part)
+		}
+	}
+	return list
+}
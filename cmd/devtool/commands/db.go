@@ -0,0 +1,552 @@
+package commands
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"github.com/tedwangl/go-util/pkg/cobrax"
+	"github.com/tedwangl/go-util/pkg/gormx"
+	"gopkg.in/yaml.v3"
+)
+
+// readOnlyStatements 不修改数据的语句（其余语句执行前需要确认）
+var readOnlyStatements = map[string]bool{
+	"SELECT":   true,
+	"SHOW":     true,
+	"DESCRIBE": true,
+	"DESC":     true,
+	"EXPLAIN":  true,
+	"PRAGMA":   true,
+	"VALUES":   true,
+	"WITH":     true,
+}
+
+type (
+	// queryResult 查询结果
+	queryResult struct {
+		Columns []string
+		Rows    [][]any
+	}
+
+	// columnInfo 表结构中的一列
+	columnInfo struct {
+		Name     string `json:"name"`
+		Type     string `json:"type"`
+		Nullable bool   `json:"nullable"`
+		Primary  bool   `json:"primary"`
+		Default  string `json:"default,omitempty"`
+		Comment  string `json:"comment,omitempty"`
+	}
+)
+
+// RegisterDBCommands 注册数据库相关命令
+//
+// 连接配置从全局配置文件读取：db.<conn> 为 gormx 配置（driver、dsn 等），默认使用 db.default
+func RegisterDBCommands(tool *cobrax.Tool) {
+	dbGroup := cobrax.NewCommandGroup("db")
+
+	dbCmd := tool.NewCommand(
+		"db",
+		"数据库工具",
+		"基于 gormx 的 SQL 工具，支持 mysql、postgres、sqlite（连接配置见 db.<conn>）",
+		nil,
+	)
+	dbCmd.Command.GroupID = "db"
+	dbCmd.AddPersistentFlag("conn", "", "default", "连接配置名称（db.<conn>）")
+	dbCmd.AddPersistentFlag("driver", "", "", "数据库类型（与 --dsn 一起使用时忽略连接配置）")
+	dbCmd.AddPersistentFlag("dsn", "", "", "连接地址")
+
+	// db ping - 测试连接
+	pingCmd := tool.NewCommand(
+		"ping",
+		"测试数据库连接",
+		"连接数据库并输出延迟",
+		cobrax.CmdRunnerFunc(func(cmd *cobra.Command, args []string) error {
+			return withDB(func(c *gormx.Client, cfg *gormx.Config) error {
+				start := time.Now()
+				if err := c.Ping(); err != nil {
+					return fmt.Errorf("连接失败: %w", err)
+				}
+				fmt.Printf("%s 连接正常 (%s)\n", cfg.Driver, time.Since(start).Round(time.Microsecond))
+				return nil
+			})
+		}),
+	)
+
+	// db tables - 列出表
+	tablesCmd := tool.NewCommand(
+		"tables",
+		"列出所有表",
+		"列出当前数据库中的表",
+		cobrax.CmdRunnerFunc(func(cmd *cobra.Command, args []string) error {
+			return withDB(func(c *gormx.Client, cfg *gormx.Config) error {
+				tables, err := c.GetDB().Migrator().GetTables()
+				if err != nil {
+					return fmt.Errorf("获取表列表失败: %w", err)
+				}
+
+				result := &queryResult{Columns: []string{"table"}}
+				for _, t := range tables {
+					result.Rows = append(result.Rows, []any{t})
+				}
+				return printQueryResult(result, viper.GetString("format"))
+			})
+		}),
+	)
+	tablesCmd.AddFlag("format", "f", "table", "输出格式（table、json、csv）")
+
+	// db describe table - 查看表结构
+	describeCmd := tool.NewCommand(
+		"describe",
+		"查看表结构",
+		"devtool db describe <表名>，输出列的类型、是否可空、主键、默认值和注释",
+		cobrax.CmdRunnerFunc(func(cmd *cobra.Command, args []string) error {
+			if len(args) == 0 {
+				return fmt.Errorf("用法: devtool db describe <表名>")
+			}
+
+			return withDB(func(c *gormx.Client, cfg *gormx.Config) error {
+				columns, err := describeTable(c, args[0])
+				if err != nil {
+					return err
+				}
+
+				result := &queryResult{Columns: []string{"name", "type", "nullable", "primary", "default", "comment"}}
+				for _, col := range columns {
+					result.Rows = append(result.Rows, []any{col.Name, col.Type, col.Nullable, col.Primary, col.Default, col.Comment})
+				}
+				return printQueryResult(result, viper.GetString("format"))
+			})
+		}),
+	)
+	describeCmd.AddFlag("format", "f", "table", "输出格式（table、json、csv）")
+
+	// db query sql - 执行 SQL
+	queryCmd := tool.NewCommand(
+		"query",
+		"执行 SQL",
+		"devtool db query \"<SQL>\"，查询语句输出结果，修改数据的语句执行前需要确认（--yes 跳过）",
+		cobrax.CmdRunnerFunc(func(cmd *cobra.Command, args []string) error {
+			if len(args) == 0 {
+				return fmt.Errorf("用法: devtool db query \"<SQL>\"")
+			}
+			query := strings.Join(args, " ")
+
+			return withDB(func(c *gormx.Client, cfg *gormx.Config) error {
+				db := c.GetDB()
+				if isReadOnlySQL(query) {
+					result, err := runQuery(c, query)
+					if err != nil {
+						return err
+					}
+					return printQueryResult(result, viper.GetString("format"))
+				}
+
+				ok, err := cobrax.Confirm(cmd, fmt.Sprintf("将在 %s 上执行修改语句:\n  %s", cfg.Driver, query))
+				if err != nil {
+					return err
+				}
+				if !ok {
+					return fmt.Errorf("已取消")
+				}
+				res := db.Exec(query)
+				if res.Error != nil {
+					return fmt.Errorf("执行失败: %w", res.Error)
+				}
+				fmt.Printf("执行成功，影响 %d 行\n", res.RowsAffected)
+				return nil
+			})
+		}),
+	)
+	queryCmd.AddFlag("format", "f", "table", "输出格式（table、json、csv）")
+
+	// db seed [name...] - 执行种子数据
+	seedCmd := tool.NewCommand(
+		"seed",
+		"执行种子数据",
+		"devtool db seed [名称...]，执行 --dir 目录中的 SQL 种子数据（<dir>/*.sql 适用于所有环境，<dir>/<env>/*.sql 只在该环境执行），\n"+
+			"文件开头的 \"-- depends: a, b\" 声明依赖；已执行的种子数据记录在 gormx_seeds 表中，不会重复执行",
+		cobrax.CmdRunnerFunc(func(cmd *cobra.Command, args []string) error {
+			seeds, err := gormx.LoadSQLSeeds(os.DirFS(viper.GetString("dir")), ".")
+			if err != nil {
+				return err
+			}
+			env := viper.GetString("env")
+
+			return withDB(func(c *gormx.Client, cfg *gormx.Config) error {
+				seeder := gormx.NewSeeder(c.GetDB())
+				if err := seeder.Register(seeds...); err != nil {
+					return err
+				}
+
+				if viper.GetBool("status") {
+					return printSeedStatus(cmd.Context(), seeder, env)
+				}
+				if names := viper.GetStringSlice("forget"); len(names) > 0 {
+					if err := seeder.Forget(cmd.Context(), names...); err != nil {
+						return fmt.Errorf("删除执行记录失败: %w", err)
+					}
+					fmt.Printf("已删除 %d 条执行记录\n", len(names))
+					return nil
+				}
+
+				pending, err := pendingSeeds(cmd.Context(), seeder, env, args)
+				if err != nil {
+					return err
+				}
+				if len(pending) == 0 {
+					fmt.Println("没有需要执行的种子数据")
+					return nil
+				}
+
+				ok, err := cobrax.Confirm(cmd, fmt.Sprintf("将在 %s（环境 %s）上执行种子数据:\n  %s", cfg.Driver, env, strings.Join(pending, "\n  ")))
+				if err != nil {
+					return err
+				}
+				if !ok {
+					return fmt.Errorf("已取消")
+				}
+
+				applied, err := seeder.Run(cmd.Context(), env, args...)
+				for _, name := range applied {
+					fmt.Printf("✓ %s\n", name)
+				}
+				if err != nil {
+					return err
+				}
+				fmt.Printf("执行完成，共 %d 条\n", len(applied))
+				return nil
+			})
+		}),
+	)
+	seedCmd.AddFlag("dir", "", "seeds", "种子数据目录")
+	seedCmd.AddFlag("env", "e", "dev", "环境（执行 <dir>/<env>/ 中的种子数据）")
+	seedCmd.AddFlag("status", "s", false, "只查看执行状态")
+	seedCmd.AddFlag("forget", "", []string{}, "删除指定种子数据的执行记录，下次重新执行")
+
+	dbCmd.Command.AddCommand(pingCmd.Command, tablesCmd.Command, describeCmd.Command, queryCmd.Command, seedCmd.Command)
+
+	dbGroup.AddCommand(dbCmd)
+	tool.AddGroupLogic(dbGroup)
+}
+
+// loadDBConfig 读取连接配置：--driver/--dsn 优先，其次是 db.<conn>
+// 命令行工具关闭 SQL 日志、指标、健康检查和预编译
+func loadDBConfig() (*gormx.Config, error) {
+	var cfg *gormx.Config
+	if dsn := viper.GetString("dsn"); dsn != "" {
+		driver := viper.GetString("driver")
+		if driver == "" {
+			return nil, fmt.Errorf("使用 --dsn 时必须指定 --driver")
+		}
+		cfg = gormx.NewConfig(driver, dsn)
+	} else {
+		key := "db." + viper.GetString("conn")
+		if !viper.IsSet(key) {
+			return nil, fmt.Errorf("连接配置不存在: %s（或使用 --driver 和 --dsn）", key)
+		}
+
+		// 转为 YAML 后按 gormx.Config 的字段解析
+		data, err := yaml.Marshal(viper.GetStringMap(key))
+		if err != nil {
+			return nil, fmt.Errorf("解析连接配置失败: %w", err)
+		}
+		cfg = gormx.NewConfig("", "")
+		if err := yaml.Unmarshal(data, cfg); err != nil {
+			return nil, fmt.Errorf("解析连接配置失败: %w", err)
+		}
+		if cfg.Driver == "" || cfg.DSN == "" {
+			return nil, fmt.Errorf("连接配置 %s 缺少 driver 或 dsn", key)
+		}
+	}
+
+	cfg.DSN = os.ExpandEnv(cfg.DSN)
+	cfg.LogLevel = "silent"
+	cfg.DisableMetrics = true
+	cfg.DisableTracing = true
+	cfg.HealthCheckInterval = 0
+	cfg.SlowQueryLimit = 0
+	cfg.PrepareStmt = false
+	cfg.MaxOpenConns = 1
+	return cfg, nil
+}
+
+// withDB 连接数据库并执行操作
+func withDB(fn func(c *gormx.Client, cfg *gormx.Config) error) error {
+	cfg, err := loadDBConfig()
+	if err != nil {
+		return err
+	}
+
+	c, err := gormx.NewClient(cfg)
+	if err != nil {
+		return fmt.Errorf("连接数据库失败: %w", err)
+	}
+	defer c.Close()
+
+	return fn(c, cfg)
+}
+
+// isReadOnlySQL 根据第一个关键字判断语句是否只读（忽略开头的注释）
+// 包含多条语句、WITH 中包含修改语句、EXPLAIN ANALYZE（会实际执行语句）和 PRAGMA 赋值视为修改
+func isReadOnlySQL(query string) bool {
+	q := strings.TrimSpace(query)
+	for {
+		switch {
+		case strings.HasPrefix(q, "--"):
+			_, rest, _ := strings.Cut(q, "\n")
+			q = strings.TrimSpace(rest)
+		case strings.HasPrefix(q, "/*"):
+			_, rest, _ := strings.Cut(q, "*/")
+			q = strings.TrimSpace(rest)
+		default:
+			if hasMultipleStatements(q) {
+				return false
+			}
+
+			fields := strings.Fields(strings.ToUpper(strings.TrimLeft(q, "( ")))
+			if len(fields) == 0 {
+				return false
+			}
+			first := strings.TrimRight(fields[0], "(;")
+			if !readOnlyStatements[first] {
+				return false
+			}
+
+			switch first {
+			case "WITH":
+				for _, f := range fields {
+					switch strings.Trim(f, "(;") {
+					case "INSERT", "UPDATE", "DELETE", "MERGE":
+						return false
+					}
+				}
+			case "EXPLAIN":
+				for _, f := range fields[1:] {
+					switch strings.Trim(f, "(,;") {
+					case "ANALYZE", "ANALYSE":
+						return false
+					}
+				}
+			case "PRAGMA":
+				if strings.Contains(q, "=") {
+					return false
+				}
+			}
+			return true
+		}
+	}
+}
+
+// hasMultipleStatements 是否包含多条语句（忽略字符串、引用的标识符和注释中的分号，允许末尾的分号）
+// 不处理反斜杠转义，无法确定时按多条语句处理
+func hasMultipleStatements(q string) bool {
+	ended := false
+	for i := 0; i < len(q); i++ {
+		switch c := q[i]; {
+		case c == '\'' || c == '"' || c == '`':
+			if ended {
+				return true
+			}
+			// 跳到配对的引号，连续两个引号为转义
+			for i++; i < len(q); i++ {
+				if q[i] == c {
+					if i+1 < len(q) && q[i+1] == c {
+						i++
+						continue
+					}
+					break
+				}
+			}
+		case strings.HasPrefix(q[i:], "--"):
+			end := strings.IndexByte(q[i:], '\n')
+			if end < 0 {
+				return false
+			}
+			i += end
+		case strings.HasPrefix(q[i:], "/*"):
+			end := strings.Index(q[i+2:], "*/")
+			if end < 0 {
+				return false
+			}
+			i += end + 3
+		case c == ';':
+			ended = true
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+		default:
+			if ended {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// runQuery 执行查询并读取所有行（[]byte 转为字符串）
+func runQuery(c *gormx.Client, query string) (*queryResult, error) {
+	rows, err := c.GetDB().Raw(query).Rows()
+	if err != nil {
+		return nil, fmt.Errorf("查询失败: %w", err)
+	}
+	defer rows.Close()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return nil, err
+	}
+
+	result := &queryResult{Columns: columns}
+	for rows.Next() {
+		values := make([]any, len(columns))
+		ptrs := make([]any, len(columns))
+		for i := range values {
+			ptrs[i] = &values[i]
+		}
+		if err := rows.Scan(ptrs...); err != nil {
+			return nil, err
+		}
+		for i, v := range values {
+			if b, ok := v.([]byte); ok {
+				values[i] = string(b)
+			}
+		}
+		result.Rows = append(result.Rows, values)
+	}
+	return result, rows.Err()
+}
+
+// describeTable 读取表的列信息
+func describeTable(c *gormx.Client, table string) ([]columnInfo, error) {
+	migrator := c.GetDB().Migrator()
+	if !migrator.HasTable(table) {
+		return nil, fmt.Errorf("表不存在: %s", table)
+	}
+
+	types, err := migrator.ColumnTypes(table)
+	if err != nil {
+		return nil, fmt.Errorf("读取表结构失败: %w", err)
+	}
+
+	columns := make([]columnInfo, 0, len(types))
+	for _, t := range types {
+		col := columnInfo{Name: t.Name(), Type: t.DatabaseTypeName()}
+		if full, ok := t.ColumnType(); ok {
+			col.Type = full
+		}
+		col.Nullable, _ = t.Nullable()
+		col.Primary, _ = t.PrimaryKey()
+		col.Default, _ = t.DefaultValue()
+		col.Comment, _ = t.Comment()
+		columns = append(columns, col)
+	}
+	return columns, nil
+}
+
+// pendingSeeds 返回尚未执行的种子数据（执行顺序）
+func pendingSeeds(ctx context.Context, seeder *gormx.Seeder, env string, names []string) ([]string, error) {
+	order, err := seeder.Plan(env, names...)
+	if err != nil {
+		return nil, err
+	}
+	statuses, err := seeder.Status(ctx, env)
+	if err != nil {
+		return nil, err
+	}
+
+	applied := make(map[string]bool, len(statuses))
+	for _, st := range statuses {
+		applied[st.Name] = st.Applied
+	}
+	var pending []string
+	for _, name := range order {
+		if !applied[name] {
+			pending = append(pending, name)
+		}
+	}
+	return pending, nil
+}
+
+// printSeedStatus 输出种子数据的执行状态
+func printSeedStatus(ctx context.Context, seeder *gormx.Seeder, env string) error {
+	statuses, err := seeder.Status(ctx, env)
+	if err != nil {
+		return err
+	}
+
+	result := &queryResult{Columns: []string{"name", "depends_on", "envs", "applied_at"}}
+	for _, st := range statuses {
+		var appliedAt any
+		if st.AppliedAt != nil {
+			appliedAt = *st.AppliedAt
+		}
+		result.Rows = append(result.Rows, []any{st.Name, strings.Join(st.DependsOn, ","), strings.Join(st.Envs, ","), appliedAt})
+	}
+	return printQueryResult(result, "table")
+}
+
+// printQueryResult 按格式输出查询结果
+func printQueryResult(result *queryResult, format string) error {
+	switch format {
+	case "json":
+		rows := make([]map[string]any, 0, len(result.Rows))
+		for _, row := range result.Rows {
+			m := make(map[string]any, len(row))
+			for i, col := range result.Columns {
+				m[col] = row[i]
+			}
+			rows = append(rows, m)
+		}
+		return printJSON(rows)
+
+	case "csv":
+		w := csv.NewWriter(os.Stdout)
+		w.Write(result.Columns)
+		for _, row := range result.Rows {
+			record := make([]string, len(row))
+			for i, v := range row {
+				record[i] = formatCell(v)
+			}
+			w.Write(record)
+		}
+		w.Flush()
+		return w.Error()
+
+	case "table":
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, strings.ToUpper(strings.Join(result.Columns, "\t")))
+		for _, row := range result.Rows {
+			cells := make([]string, len(row))
+			for i, v := range row {
+				cells[i] = strings.ReplaceAll(formatCell(v), "\n", `\n`)
+			}
+			fmt.Fprintln(w, strings.Join(cells, "\t"))
+		}
+		if err := w.Flush(); err != nil {
+			return err
+		}
+		fmt.Printf("(%d 行)\n", len(result.Rows))
+		return nil
+
+	default:
+		return fmt.Errorf("不支持的输出格式: %s（table、json、csv）", format)
+	}
+}
+
+// formatCell 格式化单元格（NULL 输出为 NULL）
+func formatCell(v any) string {
+	switch val := v.(type) {
+	case nil:
+		return "NULL"
+	case time.Time:
+		return val.Format("2006-01-02 15:04:05")
+	default:
+		return fmt.Sprint(val)
+	}
+}
@@ -0,0 +1,13 @@
+//go:build windows
+
+package commands
+
+import (
+	"os/exec"
+	"syscall"
+)
+
+// detach 守护进程不继承当前控制台
+func detach(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{CreationFlags: 0x00000008} // DETACHED_PROCESS
+}
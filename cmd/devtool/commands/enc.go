@@ -0,0 +1,440 @@
+package commands
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"github.com/tedwangl/go-util/pkg/cobrax"
+)
+
+type (
+	// encResult 编解码结果（--json 输出）
+	encResult struct {
+		Op     string `json:"op"`
+		Input  string `json:"input,omitempty"`
+		Output string `json:"output"`
+	}
+
+	// jwtInfo JWT 解码结果（不校验签名）
+	jwtInfo struct {
+		Header    map[string]any `json:"header"`
+		Payload   map[string]any `json:"payload"`
+		Signature string         `json:"signature"`
+		IssuedAt  string         `json:"issued_at,omitempty"`
+		NotBefore string         `json:"not_before,omitempty"`
+		ExpiresAt string         `json:"expires_at,omitempty"`
+		Expired   bool           `json:"expired"`
+	}
+
+	// hashResult 摘要结果（--json 输出）
+	hashResult struct {
+		Algo  string `json:"algo"`
+		Input string `json:"input,omitempty"` // 字符串输入
+		File  string `json:"file,omitempty"`  // 文件输入
+		Hash  string `json:"hash"`
+	}
+)
+
+// RegisterEncCommands 注册编解码与摘要相关命令
+func RegisterEncCommands(tool *cobrax.Tool) {
+	encGroup := cobrax.NewCommandGroup("enc")
+
+	encCmd := tool.NewCommand(
+		"enc",
+		"编解码工具",
+		"base64、hex、URL 编解码，JWT 解码，生成 UUID 和随机数\n输入可通过参数传入，省略或为 - 时从标准输入读取",
+		nil,
+	)
+	encCmd.Command.GroupID = "enc"
+
+	// enc base64
+	base64Cmd := tool.NewCommand(
+		"base64 [input]",
+		"base64 编解码",
+		"base64 编码，--decode 解码（自动兼容 URL 安全字符和无填充格式）",
+		cobrax.CmdRunnerFunc(func(cmd *cobra.Command, args []string) error {
+			return runCodec("base64", args, func(in []byte) ([]byte, error) {
+				if viper.GetBool("decode") {
+					return decodeBase64(string(in))
+				}
+				enc := base64.StdEncoding
+				if viper.GetBool("url") {
+					enc = base64.URLEncoding
+				}
+				if viper.GetBool("no-padding") {
+					enc = enc.WithPadding(base64.NoPadding)
+				}
+				return []byte(enc.EncodeToString(in)), nil
+			})
+		}),
+	)
+	addCodecFlags(base64Cmd)
+	base64Cmd.AddFlag("url", "", false, "使用 URL 安全字符集")
+	base64Cmd.AddFlag("no-padding", "", false, "不输出 = 填充")
+
+	// enc hex
+	hexCmd := tool.NewCommand(
+		"hex [input]",
+		"hex 编解码",
+		"十六进制编码，--decode 解码（忽略空白字符）",
+		cobrax.CmdRunnerFunc(func(cmd *cobra.Command, args []string) error {
+			return runCodec("hex", args, func(in []byte) ([]byte, error) {
+				if viper.GetBool("decode") {
+					s := strings.Join(strings.Fields(string(in)), "")
+					out, err := hex.DecodeString(s)
+					if err != nil {
+						return nil, fmt.Errorf("hex 解码失败: %w", err)
+					}
+					return out, nil
+				}
+				return []byte(hex.EncodeToString(in)), nil
+			})
+		}),
+	)
+	addCodecFlags(hexCmd)
+
+	// enc url
+	urlCmd := tool.NewCommand(
+		"url [input]",
+		"URL 编解码",
+		"URL 查询参数编码，--path 按路径段编码，--decode 解码",
+		cobrax.CmdRunnerFunc(func(cmd *cobra.Command, args []string) error {
+			return runCodec("url", args, func(in []byte) ([]byte, error) {
+				s := string(in)
+				if viper.GetBool("decode") {
+					unescape := url.QueryUnescape
+					if viper.GetBool("path") {
+						unescape = url.PathUnescape
+					}
+					out, err := unescape(s)
+					if err != nil {
+						return nil, fmt.Errorf("URL 解码失败: %w", err)
+					}
+					return []byte(out), nil
+				}
+				if viper.GetBool("path") {
+					return []byte(url.PathEscape(s)), nil
+				}
+				return []byte(url.QueryEscape(s)), nil
+			})
+		}),
+	)
+	addCodecFlags(urlCmd)
+	urlCmd.AddFlag("path", "", false, "按路径段编解码（空格编码为 %20）")
+
+	// enc jwt-decode
+	jwtCmd := tool.NewCommand(
+		"jwt-decode [token]",
+		"解码 JWT",
+		"解码 JWT 的 header 和 payload，并显示 iat/nbf/exp 时间（不校验签名）",
+		cobrax.CmdRunnerFunc(func(cmd *cobra.Command, args []string) error {
+			in, err := readInput(args)
+			if err != nil {
+				return err
+			}
+			token := strings.TrimPrefix(strings.TrimSpace(string(in)), "Bearer ")
+
+			info, err := decodeJWT(token, time.Now())
+			if err != nil {
+				return err
+			}
+			if viper.GetBool("json") {
+				return printJSON(info)
+			}
+
+			fmt.Println("Header:")
+			printIndentedJSON(info.Header)
+			fmt.Println("Payload:")
+			printIndentedJSON(info.Payload)
+			if info.IssuedAt != "" {
+				fmt.Printf("签发时间: %s\n", info.IssuedAt)
+			}
+			if info.NotBefore != "" {
+				fmt.Printf("生效时间: %s\n", info.NotBefore)
+			}
+			if info.ExpiresAt != "" {
+				state := "有效"
+				if info.Expired {
+					state = "已过期"
+				}
+				fmt.Printf("过期时间: %s（%s）\n", info.ExpiresAt, state)
+			}
+			return nil
+		}),
+	)
+	jwtCmd.AddFlag("json", "", false, "以 JSON 输出")
+
+	// enc uuid
+	uuidCmd := tool.NewCommand(
+		"uuid",
+		"生成 UUID",
+		"生成 UUID，默认 v4，--v7 生成按时间排序的 v7",
+		cobrax.CmdRunnerFunc(func(cmd *cobra.Command, args []string) error {
+			count := viper.GetInt("count")
+			if count <= 0 {
+				return fmt.Errorf("数量必须大于 0")
+			}
+
+			ids := make([]string, 0, count)
+			for i := 0; i < count; i++ {
+				id := uuid.New()
+				if viper.GetBool("v7") {
+					var err error
+					if id, err = uuid.NewV7(); err != nil {
+						return err
+					}
+				}
+				s := id.String()
+				if viper.GetBool("no-dash") {
+					s = strings.ReplaceAll(s, "-", "")
+				}
+				if viper.GetBool("upper") {
+					s = strings.ToUpper(s)
+				}
+				ids = append(ids, s)
+			}
+
+			if viper.GetBool("json") {
+				return printJSON(ids)
+			}
+			for _, id := range ids {
+				fmt.Println(id)
+			}
+			return nil
+		}),
+	)
+	uuidCmd.AddFlag("count", "n", 1, "生成数量")
+	uuidCmd.AddFlag("v7", "", false, "生成 UUID v7")
+	uuidCmd.AddFlag("no-dash", "", false, "去掉横线")
+	uuidCmd.AddFlag("upper", "", false, "大写输出")
+	uuidCmd.AddFlag("json", "", false, "以 JSON 数组输出")
+
+	// enc random
+	randomCmd := tool.NewCommand(
+		"random",
+		"生成随机字节",
+		"使用 crypto/rand 生成随机字节，可输出为 hex、base64 或 base64url（适合生成密钥、令牌）",
+		cobrax.CmdRunnerFunc(func(cmd *cobra.Command, args []string) error {
+			n := viper.GetInt("bytes")
+			if n <= 0 {
+				return fmt.Errorf("字节数必须大于 0")
+			}
+			buf := make([]byte, n)
+			if _, err := rand.Read(buf); err != nil {
+				return err
+			}
+
+			var out string
+			switch format := viper.GetString("format"); format {
+			case "hex":
+				out = hex.EncodeToString(buf)
+			case "base64":
+				out = base64.StdEncoding.EncodeToString(buf)
+			case "base64url":
+				out = base64.RawURLEncoding.EncodeToString(buf)
+			default:
+				return fmt.Errorf("无效的输出格式: %s（hex, base64, base64url）", format)
+			}
+
+			if viper.GetBool("json") {
+				return printJSON(encResult{Op: "random", Output: out})
+			}
+			fmt.Println(out)
+			return nil
+		}),
+	)
+	randomCmd.AddFlag("bytes", "b", 32, "随机字节数")
+	randomCmd.AddFlag("format", "f", "hex", "输出格式: hex, base64, base64url")
+	randomCmd.AddFlag("json", "", false, "以 JSON 输出")
+
+	encCmd.Command.AddCommand(base64Cmd.Command, hexCmd.Command, urlCmd.Command, jwtCmd.Command, uuidCmd.Command, randomCmd.Command)
+
+	// hash - 计算摘要
+	hashCmd := tool.NewCommand(
+		"hash",
+		"计算摘要",
+		"计算字符串或文件的摘要\n参数为已存在的文件时计算文件摘要（--string 强制按字符串处理），省略或为 - 时从标准输入读取",
+		nil,
+	)
+	hashCmd.Command.GroupID = "enc"
+
+	for _, algo := range []string{"md5", "sha1", "sha256", "sha512"} {
+		algoCmd := tool.NewCommand(
+			algo+" [input|file]",
+			"计算 "+algo+" 摘要",
+			"计算字符串、文件或标准输入的 "+algo+" 摘要",
+			cobrax.CmdRunnerFunc(func(cmd *cobra.Command, args []string) error {
+				result, err := hashInput(algo, args, viper.GetBool("string"))
+				if err != nil {
+					return err
+				}
+				if viper.GetBool("json") {
+					return printJSON(result)
+				}
+				fmt.Println(result.Hash)
+				return nil
+			}),
+		)
+		algoCmd.AddFlag("string", "s", false, "参数按字符串处理，即使存在同名文件")
+		algoCmd.AddFlag("json", "", false, "以 JSON 输出")
+		hashCmd.Command.AddCommand(algoCmd.Command)
+	}
+
+	encGroup.AddCommand(encCmd, hashCmd)
+	tool.AddGroupLogic(encGroup)
+}
+
+// addCodecFlags 编解码命令的公共参数
+func addCodecFlags(c *cobrax.Command) {
+	c.AddFlag("decode", "D", false, "解码")
+	c.AddFlag("json", "", false, "以 JSON 输出")
+}
+
+// readInput 读取命令输入：参数省略或为 - 时读取标准输入
+func readInput(args []string) ([]byte, error) {
+	if len(args) > 0 && args[0] != "-" {
+		return []byte(strings.Join(args, " ")), nil
+	}
+	data, err := io.ReadAll(os.Stdin)
+	if err != nil {
+		return nil, fmt.Errorf("读取标准输入失败: %w", err)
+	}
+	return data, nil
+}
+
+// runCodec 读取输入、转换并输出结果；从标准输入读取时去掉末尾换行（echo 的输出）
+func runCodec(op string, args []string, fn func([]byte) ([]byte, error)) error {
+	in, err := readInput(args)
+	if err != nil {
+		return err
+	}
+	if len(args) == 0 || args[0] == "-" {
+		in = bytes.TrimRight(in, "\r\n")
+	}
+
+	out, err := fn(in)
+	if err != nil {
+		return err
+	}
+
+	if viper.GetBool("json") {
+		return printJSON(encResult{Op: op, Input: string(in), Output: string(out)})
+	}
+	os.Stdout.Write(out)
+	// 解码结果可能是二进制，不追加换行以免破坏内容（重定向到文件时）
+	if !viper.GetBool("decode") || isTerminal(os.Stdout) {
+		fmt.Println()
+	}
+	return nil
+}
+
+// isTerminal 文件是否为终端（字符设备）
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// decodeBase64 解码 base64，兼容标准/URL 安全字符集以及有无填充
+func decodeBase64(s string) ([]byte, error) {
+	s = strings.Join(strings.Fields(s), "")
+	if strings.ContainsAny(s, "-_") {
+		s = strings.NewReplacer("-", "+", "_", "/").Replace(s)
+	}
+	out, err := base64.RawStdEncoding.DecodeString(strings.TrimRight(s, "="))
+	if err != nil {
+		return nil, fmt.Errorf("base64 解码失败: %w", err)
+	}
+	return out, nil
+}
+
+// decodeJWT 解码 JWT（不校验签名）
+func decodeJWT(token string, now time.Time) (*jwtInfo, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("无效的 JWT: 应包含 3 段，实际 %d 段", len(parts))
+	}
+
+	info := &jwtInfo{Signature: parts[2]}
+	for i, dst := range []*map[string]any{&info.Header, &info.Payload} {
+		data, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(parts[i], "="))
+		if err != nil {
+			return nil, fmt.Errorf("无效的 JWT: 第 %d 段 base64 解码失败: %w", i+1, err)
+		}
+		dec := json.NewDecoder(bytes.NewReader(data))
+		dec.UseNumber()
+		if err := dec.Decode(dst); err != nil {
+			return nil, fmt.Errorf("无效的 JWT: 第 %d 段不是 JSON 对象: %w", i+1, err)
+		}
+	}
+
+	claimTime := func(key string) (time.Time, bool) {
+		n, ok := info.Payload[key].(json.Number)
+		if !ok {
+			return time.Time{}, false
+		}
+		sec, err := n.Float64()
+		if err != nil {
+			return time.Time{}, false
+		}
+		return time.Unix(int64(sec), 0), true
+	}
+	if t, ok := claimTime("iat"); ok {
+		info.IssuedAt = t.Format(time.RFC3339)
+	}
+	if t, ok := claimTime("nbf"); ok {
+		info.NotBefore = t.Format(time.RFC3339)
+	}
+	if t, ok := claimTime("exp"); ok {
+		info.ExpiresAt = t.Format(time.RFC3339)
+		info.Expired = now.After(t)
+	}
+	return info, nil
+}
+
+// printIndentedJSON 以缩进格式输出 JSON（每行前加两个空格）
+func printIndentedJSON(v any) {
+	data, _ := json.MarshalIndent(v, "  ", "  ")
+	fmt.Printf("  %s\n", data)
+}
+
+// hashInput 计算参数（字符串或文件）或标准输入的摘要
+func hashInput(algo string, args []string, forceString bool) (*hashResult, error) {
+	result := &hashResult{Algo: algo}
+
+	if len(args) > 0 && args[0] != "-" && !forceString {
+		if info, err := os.Stat(args[0]); err == nil && info.Mode().IsRegular() {
+			sum, err := hashFile(args[0], algo)
+			if err != nil {
+				return nil, err
+			}
+			result.File = args[0]
+			result.Hash = sum
+			return result, nil
+		}
+	}
+
+	in, err := readInput(args)
+	if err != nil {
+		return nil, err
+	}
+	// 字符串参数记录原文，标准输入可能很大，不记录
+	if len(args) > 0 && args[0] != "-" {
+		result.Input = string(in)
+	}
+	if result.Hash, err = hashReader(bytes.NewReader(in), algo); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
@@ -0,0 +1,574 @@
+package commands
+
+import (
+	"context"
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"io/fs"
+	"os"
+	"os/exec"
+	"os/signal"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"sort"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"github.com/tedwangl/go-util/pkg/cobrax"
+	"github.com/tedwangl/go-util/pkg/utils/humanize"
+)
+
+type (
+	// fileHash 文件摘要
+	fileHash struct {
+		Path string `json:"path"`
+		Hash string `json:"hash"`
+		Size int64  `json:"size"`
+	}
+
+	// renamePlan 单个文件的重命名计划
+	renamePlan struct {
+		From string `json:"from"`
+		To   string `json:"to"`
+	}
+
+	// dupGroup 内容相同的一组文件（按路径排序，第一个为保留文件）
+	dupGroup struct {
+		Hash  string   `json:"hash"`
+		Size  int64    `json:"size"`
+		Files []string `json:"files"`
+	}
+)
+
+// watchSkipDirs 监听时默认忽略的目录
+var watchSkipDirs = map[string]bool{
+	".git":         true,
+	".idea":        true,
+	".vscode":      true,
+	"node_modules": true,
+	"vendor":       true,
+	"__pycache__":  true,
+}
+
+// RegisterFileCommands 注册文件操作相关命令
+func RegisterFileCommands(tool *cobrax.Tool) {
+	fileGroup := cobrax.NewCommandGroup("file")
+
+	fileCmd := tool.NewCommand(
+		"file",
+		"文件工具",
+		"文件摘要、目录监听、批量重命名、重复文件查找",
+		nil,
+	)
+	fileCmd.Command.GroupID = "file"
+
+	// file hash - 计算文件摘要
+	hashCmd := tool.NewCommand(
+		"hash <path>...",
+		"计算文件摘要",
+		"计算文件摘要（md5, sha1, sha256, sha512），目录会递归计算其中所有文件",
+		cobrax.CmdRunnerFunc(func(cmd *cobra.Command, args []string) error {
+			if len(args) == 0 {
+				return fmt.Errorf("请指定文件或目录")
+			}
+			algo := viper.GetString("algo")
+			if _, err := newHash(algo); err != nil {
+				return err
+			}
+
+			var results []fileHash
+			for _, root := range args {
+				err := walkFiles(root, func(path string, info fs.FileInfo) error {
+					sum, err := hashFile(path, algo)
+					if err != nil {
+						return err
+					}
+					results = append(results, fileHash{Path: path, Hash: sum, Size: info.Size()})
+					return nil
+				})
+				if err != nil {
+					return err
+				}
+			}
+
+			if viper.GetBool("json") {
+				return printJSON(results)
+			}
+			// 与 sha256sum 等命令输出格式一致
+			for _, r := range results {
+				fmt.Printf("%s  %s\n", r.Hash, r.Path)
+			}
+			return nil
+		}),
+	)
+	hashCmd.AddFlag("algo", "a", "sha256", "摘要算法: md5, sha1, sha256, sha512")
+	hashCmd.AddFlag("json", "", false, "以 JSON 输出")
+
+	// file watch - 监听目录变化
+	watchCmd := tool.NewCommand(
+		"watch [dir]",
+		"监听目录变化并执行命令",
+		"递归监听目录下的文件变化（忽略 .git、node_modules 等目录），变化平息后执行 --exec 指定的命令，Ctrl+C 退出\n"+
+			"命令中可通过环境变量 DEVTOOL_CHANGED 获取最后变化的文件",
+		cobrax.CmdRunnerFunc(func(cmd *cobra.Command, args []string) error {
+			dir := "."
+			if len(args) > 0 {
+				dir = args[0]
+			}
+			debounce, err := time.ParseDuration(viper.GetString("debounce"))
+			if err != nil || debounce < 0 {
+				return fmt.Errorf("无效的防抖间隔: %s", viper.GetString("debounce"))
+			}
+
+			var patterns []string
+			if ext := viper.GetString("ext"); ext != "" {
+				for _, e := range strings.Split(ext, ",") {
+					patterns = append(patterns, "*."+strings.TrimPrefix(strings.TrimSpace(e), "."))
+				}
+			}
+
+			ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+			defer stop()
+
+			return watchDir(ctx, dir, patterns, debounce, viper.GetString("exec"))
+		}),
+	)
+	watchCmd.AddFlag("exec", "e", "", "文件变化后执行的命令（为空时只输出事件）")
+	watchCmd.AddFlag("ext", "", "", "只关注指定扩展名，逗号分隔，如 go,mod")
+	watchCmd.AddFlag("debounce", "", "300ms", "防抖间隔，间隔内的多次变化只执行一次命令")
+
+	// file rename - 批量重命名
+	renameCmd := tool.NewCommand(
+		"rename [dir]",
+		"按正则批量重命名",
+		"对目录下文件名匹配 --pattern 的文件按 --replace 重命名（支持 $1 等分组引用），建议先用 --dry-run 预览",
+		cobrax.CmdRunnerFunc(func(cmd *cobra.Command, args []string) error {
+			dir := "."
+			if len(args) > 0 {
+				dir = args[0]
+			}
+			if viper.GetString("pattern") == "" {
+				return fmt.Errorf("请指定 --pattern")
+			}
+			re, err := regexp.Compile(viper.GetString("pattern"))
+			if err != nil {
+				return fmt.Errorf("无效的正则: %w", err)
+			}
+
+			plans, err := planRenames(dir, re, viper.GetString("replace"), viper.GetBool("recursive"))
+			if err != nil {
+				return err
+			}
+			if len(plans) == 0 {
+				fmt.Println("没有匹配的文件")
+				return nil
+			}
+
+			for _, p := range plans {
+				fmt.Printf("%s -> %s\n", p.From, p.To)
+			}
+			if viper.GetBool("dry-run") {
+				fmt.Printf("\n共 %d 个文件（预览，未执行）\n", len(plans))
+				return nil
+			}
+
+			for _, p := range plans {
+				if err := os.Rename(p.From, p.To); err != nil {
+					return fmt.Errorf("重命名 %s 失败: %w", p.From, err)
+				}
+			}
+			fmt.Printf("\n已重命名 %d 个文件\n", len(plans))
+			return nil
+		}),
+	)
+	renameCmd.AddFlag("pattern", "p", "", "匹配文件名的正则表达式")
+	renameCmd.AddFlag("replace", "r", "", "替换内容，支持 $1 等分组引用")
+	renameCmd.AddFlag("recursive", "R", false, "递归处理子目录")
+	renameCmd.AddFlag("dry-run", "n", false, "只预览，不实际重命名")
+
+	// file dedupe - 查找重复文件
+	dedupeCmd := tool.NewCommand(
+		"dedupe [dir]",
+		"按内容查找重复文件",
+		"先按大小分组，再按 sha256 比较内容，列出重复文件；--delete 时每组保留路径排序后的第一个文件",
+		cobrax.CmdRunnerFunc(func(cmd *cobra.Command, args []string) error {
+			dir := "."
+			if len(args) > 0 {
+				dir = args[0]
+			}
+
+			minSize, err := humanize.ParseBytes(viper.GetString("min-size"))
+			if err != nil {
+				return fmt.Errorf("无效的 --min-size: %w", err)
+			}
+
+			groups, err := findDuplicates(dir, int64(minSize))
+			if err != nil {
+				return err
+			}
+
+			var wasted int64
+			for _, g := range groups {
+				wasted += g.Size * int64(len(g.Files)-1)
+			}
+
+			if viper.GetBool("json") {
+				if groups == nil {
+					groups = make([]dupGroup, 0)
+				}
+				if err := printJSON(groups); err != nil {
+					return err
+				}
+			} else {
+				if len(groups) == 0 {
+					fmt.Println("没有重复文件")
+					return nil
+				}
+				for _, g := range groups {
+					fmt.Printf("%s  %s × %d\n", g.Hash[:12], humanize.Bytes(uint64(g.Size)), len(g.Files))
+					for _, f := range g.Files {
+						fmt.Printf("  %s\n", f)
+					}
+				}
+				fmt.Printf("\n%d 组重复文件，可释放 %s\n", len(groups), humanize.Bytes(uint64(wasted)))
+			}
+
+			if !viper.GetBool("delete") || len(groups) == 0 {
+				return nil
+			}
+			ok, err := cobrax.Confirm(cmd, fmt.Sprintf("删除重复文件（每组保留第一个），释放 %s", humanize.Bytes(uint64(wasted))))
+			if err != nil {
+				return err
+			}
+			if !ok {
+				fmt.Println("已取消")
+				return nil
+			}
+
+			removed := 0
+			for _, g := range groups {
+				for _, f := range g.Files[1:] {
+					if err := os.Remove(f); err != nil {
+						return fmt.Errorf("删除 %s 失败: %w", f, err)
+					}
+					removed++
+				}
+			}
+			fmt.Printf("已删除 %d 个文件\n", removed)
+			return nil
+		}),
+	)
+	dedupeCmd.AddFlag("min-size", "", "1", "忽略小于该大小的文件（如: 512, 4K, 1MiB）")
+	dedupeCmd.AddFlag("delete", "", false, "删除重复文件，每组保留一个")
+	dedupeCmd.AddFlag("json", "", false, "以 JSON 输出")
+
+	fileCmd.Command.AddCommand(hashCmd.Command, watchCmd.Command, renameCmd.Command, dedupeCmd.Command)
+
+	fileGroup.AddCommand(fileCmd)
+	tool.AddGroupLogic(fileGroup)
+}
+
+// newHash 根据算法名创建摘要器
+func newHash(algo string) (hash.Hash, error) {
+	switch strings.ToLower(algo) {
+	case "md5":
+		return md5.New(), nil
+	case "sha1":
+		return sha1.New(), nil
+	case "sha256":
+		return sha256.New(), nil
+	case "sha512":
+		return sha512.New(), nil
+	default:
+		return nil, fmt.Errorf("不支持的摘要算法: %s（md5, sha1, sha256, sha512）", algo)
+	}
+}
+
+// hashReader 计算数据流的十六进制摘要
+func hashReader(r io.Reader, algo string) (string, error) {
+	h, err := newHash(algo)
+	if err != nil {
+		return "", err
+	}
+	if _, err := io.Copy(h, r); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// hashFile 计算文件的十六进制摘要
+func hashFile(path, algo string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	return hashReader(f, algo)
+}
+
+// walkFiles 遍历 root 下的普通文件（root 为文件时只处理它本身），按路径顺序回调
+func walkFiles(root string, fn func(path string, info fs.FileInfo) error) error {
+	return filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		return fn(path, info)
+	})
+}
+
+// watchDir 递归监听目录，变化平息 debounce 后执行命令
+func watchDir(ctx context.Context, dir string, patterns []string, debounce time.Duration, command string) error {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return fmt.Errorf("创建监听器失败: %w", err)
+	}
+	defer watcher.Close()
+
+	if err := addWatchDirs(watcher, dir); err != nil {
+		return err
+	}
+	fmt.Printf("正在监听 %s，按 Ctrl+C 退出\n", dir)
+
+	timer := time.NewTimer(debounce)
+	timer.Stop()
+	var changed string
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return nil
+			}
+			fmt.Fprintf(os.Stderr, "监听错误: %v\n", err)
+
+		case event, ok := <-watcher.Events:
+			if !ok {
+				return nil
+			}
+			// 新建的目录需要单独加入监听
+			if event.Has(fsnotify.Create) {
+				if info, err := os.Stat(event.Name); err == nil && info.IsDir() {
+					if watchSkipDirs[info.Name()] {
+						continue
+					}
+					if err := addWatchDirs(watcher, event.Name); err != nil {
+						fmt.Fprintf(os.Stderr, "监听 %s 失败: %v\n", event.Name, err)
+					}
+					continue
+				}
+			}
+			if event.Op == fsnotify.Chmod || !matchPatterns(event.Name, patterns) {
+				continue
+			}
+
+			fmt.Printf("%s  %-6s %s\n", time.Now().Format("15:04:05"), eventName(event.Op), event.Name)
+			changed = event.Name
+			timer.Reset(debounce)
+
+		case <-timer.C:
+			if command == "" {
+				continue
+			}
+			if err := runShell(ctx, command, changed); err != nil {
+				fmt.Fprintf(os.Stderr, "命令执行失败: %v\n", err)
+			}
+		}
+	}
+}
+
+// addWatchDirs 将 root 及其子目录加入监听（跳过 watchSkipDirs）
+func addWatchDirs(watcher *fsnotify.Watcher, root string) error {
+	return filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() {
+			return nil
+		}
+		if path != root && watchSkipDirs[d.Name()] {
+			return filepath.SkipDir
+		}
+		if err := watcher.Add(path); err != nil {
+			return fmt.Errorf("监听 %s 失败: %w", path, err)
+		}
+		return nil
+	})
+}
+
+// matchPatterns 文件名是否匹配任一模式（模式为空时全部匹配）
+func matchPatterns(path string, patterns []string) bool {
+	if len(patterns) == 0 {
+		return true
+	}
+	base := filepath.Base(path)
+	for _, p := range patterns {
+		if ok, _ := filepath.Match(p, base); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// eventName 事件类型的简短名称
+func eventName(op fsnotify.Op) string {
+	switch {
+	case op.Has(fsnotify.Create):
+		return "CREATE"
+	case op.Has(fsnotify.Write):
+		return "WRITE"
+	case op.Has(fsnotify.Remove):
+		return "REMOVE"
+	case op.Has(fsnotify.Rename):
+		return "RENAME"
+	default:
+		return op.String()
+	}
+}
+
+// runShell 通过系统 shell 执行命令，输出直接打印到终端
+func runShell(ctx context.Context, command, changed string) error {
+	var c *exec.Cmd
+	if runtime.GOOS == "windows" {
+		c = exec.CommandContext(ctx, "cmd", "/C", command)
+	} else {
+		c = exec.CommandContext(ctx, "sh", "-c", command)
+	}
+	c.Env = append(os.Environ(), "DEVTOOL_CHANGED="+changed)
+	c.Stdout = os.Stdout
+	c.Stderr = os.Stderr
+
+	fmt.Printf("$ %s\n", command)
+	start := time.Now()
+	err := c.Run()
+	if err == nil {
+		fmt.Printf("完成，耗时 %s\n", time.Since(start).Round(time.Millisecond))
+	}
+	return err
+}
+
+// planRenames 生成重命名计划，目标冲突时返回错误
+func planRenames(dir string, re *regexp.Regexp, replace string, recursive bool) ([]renamePlan, error) {
+	var plans []renamePlan
+	targets := make(map[string]string)
+
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if path != dir && !recursive {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		name := d.Name()
+		if !re.MatchString(name) {
+			return nil
+		}
+		newName := re.ReplaceAllString(name, replace)
+		if newName == name {
+			return nil
+		}
+		if newName == "" || strings.ContainsAny(newName, `/\`) {
+			return fmt.Errorf("无效的目标文件名: %s -> %q", name, newName)
+		}
+
+		to := filepath.Join(filepath.Dir(path), newName)
+		if prev, ok := targets[to]; ok {
+			return fmt.Errorf("重命名冲突: %s 和 %s 都将重命名为 %s", prev, path, to)
+		}
+		targets[to] = path
+		plans = append(plans, renamePlan{From: path, To: to})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// 目标已存在且不会被本次重命名移走时视为冲突
+	sources := make(map[string]bool, len(plans))
+	for _, p := range plans {
+		sources[p.From] = true
+	}
+	for _, p := range plans {
+		if sources[p.To] {
+			return nil, fmt.Errorf("重命名冲突: %s 的目标 %s 也在重命名列表中，请分步执行", p.From, p.To)
+		}
+		if _, err := os.Lstat(p.To); err == nil {
+			return nil, fmt.Errorf("重命名冲突: 目标文件已存在: %s", p.To)
+		} else if !errors.Is(err, fs.ErrNotExist) {
+			return nil, err
+		}
+	}
+	return plans, nil
+}
+
+// findDuplicates 查找内容相同的文件，按浪费空间从大到小排序
+func findDuplicates(dir string, minSize int64) ([]dupGroup, error) {
+	// 先按大小分组，大小唯一的文件无需计算摘要
+	bySize := make(map[int64][]string)
+	err := walkFiles(dir, func(path string, info fs.FileInfo) error {
+		if info.Size() >= minSize {
+			bySize[info.Size()] = append(bySize[info.Size()], path)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var groups []dupGroup
+	for size, paths := range bySize {
+		if len(paths) < 2 {
+			continue
+		}
+		byHash := make(map[string][]string)
+		for _, path := range paths {
+			sum, err := hashFile(path, "sha256")
+			if err != nil {
+				return nil, err
+			}
+			byHash[sum] = append(byHash[sum], path)
+		}
+		for sum, files := range byHash {
+			if len(files) < 2 {
+				continue
+			}
+			sort.Strings(files)
+			groups = append(groups, dupGroup{Hash: sum, Size: size, Files: files})
+		}
+	}
+
+	sort.Slice(groups, func(i, j int) bool {
+		wi := groups[i].Size * int64(len(groups[i].Files)-1)
+		wj := groups[j].Size * int64(len(groups[j].Files)-1)
+		if wi != wj {
+			return wi > wj
+		}
+		return groups[i].Files[0] < groups[j].Files[0]
+	})
+	return groups, nil
+}
@@ -4,10 +4,12 @@ import (
 	"fmt"
 	"os"
 	"os/exec"
+	"path/filepath"
 
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 	"github.com/tedwangl/go-util/pkg/cobrax"
+	"github.com/tedwangl/go-util/pkg/workflowlint"
 )
 
 // RegisterGoCommands 注册 Go 相关命令
@@ -187,6 +189,89 @@ func RegisterGoCommands(tool *cobrax.Tool) {
 	buildCmd.AddFlag("output", "o", "", "输出文件名")
 	buildCmd.AddFlag("race", "r", false, "启用竞态检测")
 
-	goGroup.AddCommand(testCmd, benchCmd, getCmd, modCmd, buildCmd)
+	// go new - 创建项目脚手架
+	newCmd := tool.NewCommand(
+		"new <name>",
+		"创建 Go 项目",
+		"基于内置模板创建 Go 项目，包含 go.mod、Makefile 和示例测试\n"+
+			"  cli     - cobrax 命令行工具\n"+
+			"  service - gormx + redisx HTTP 服务\n"+
+			"  worker  - Temporal Worker",
+		cobrax.CmdRunnerFunc(func(cmd *cobra.Command, args []string) error {
+			if len(args) != 1 {
+				return fmt.Errorf("请指定项目名")
+			}
+
+			dir := args[0]
+			name := filepath.Base(dir)
+			tmpl := viper.GetString("template")
+			data := newScaffoldData(name, viper.GetString("module"))
+
+			files, err := renderScaffold(tmpl, dir, data)
+			if err != nil {
+				return err
+			}
+			fmt.Printf("已创建 %s 项目 %s（模块 %s）\n", tmpl, dir, data.Module)
+			for _, f := range files {
+				fmt.Printf("  %s\n", f)
+			}
+
+			if viper.GetBool("no-tidy") {
+				return nil
+			}
+
+			fmt.Println("\n执行 go mod tidy...")
+			goCmd := exec.Command("go", "mod", "tidy")
+			goCmd.Dir = dir
+			goCmd.Stdout = os.Stdout
+			goCmd.Stderr = os.Stderr
+			if err := goCmd.Run(); err != nil {
+				return fmt.Errorf("go mod tidy 失败（可稍后手动执行）: %w", err)
+			}
+			return nil
+		}),
+	)
+	newCmd.AddFlag("template", "t", "cli", "项目模板: cli, service, worker")
+	newCmd.AddFlag("module", "m", "", "模块路径（默认为项目名）")
+	newCmd.AddFlag("no-tidy", "", false, "不执行 go mod tidy")
+
+	// go check-workflows - 检查工作流确定性
+	checkWorkflowsCmd := tool.NewCommand(
+		"check-workflows",
+		"检查 Temporal 工作流的确定性",
+		"检查目录（默认当前目录）下以 workflow.Context 为第一个参数的函数，\n"+
+			"报告 time.Now、随机数、I/O、原生 goroutine/channel、遍历 map 等重放时不确定的代码",
+		cobrax.CmdRunnerFunc(func(cmd *cobra.Command, args []string) error {
+			dir := "."
+			if len(args) > 0 {
+				dir = args[0]
+			}
+
+			issues, err := workflowlint.CheckDir(dir)
+			if err != nil {
+				return err
+			}
+
+			if viper.GetBool("json") {
+				if err := printJSON(issues); err != nil {
+					return err
+				}
+			} else {
+				for _, issue := range issues {
+					fmt.Println(issue)
+				}
+			}
+			if len(issues) > 0 {
+				return fmt.Errorf("发现 %d 处不确定的代码", len(issues))
+			}
+			if !viper.GetBool("json") {
+				fmt.Println("未发现问题")
+			}
+			return nil
+		}),
+	)
+	checkWorkflowsCmd.AddFlag("json", "", false, "以 JSON 输出")
+
+	goGroup.AddCommand(testCmd, benchCmd, getCmd, modCmd, buildCmd, newCmd, checkWorkflowsCmd)
 	tool.AddGroupLogic(goGroup)
 }
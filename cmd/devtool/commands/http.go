@@ -0,0 +1,379 @@
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/signal"
+	"sort"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"github.com/tedwangl/go-util/pkg/cobrax"
+	"github.com/tedwangl/go-util/pkg/restyx"
+)
+
+type (
+	// httpResult 请求结果（--json 输出）
+	httpResult struct {
+		Method  string          `json:"method"`
+		URL     string          `json:"url"`
+		Status  int             `json:"status"`
+		Headers http.Header     `json:"headers"`
+		Body    json.RawMessage `json:"body,omitempty"` // JSON 响应原样输出，其他内容为字符串
+		Size    int             `json:"size"`
+		Timing  *httpTiming     `json:"timing,omitempty"`
+	}
+
+	// httpTiming 耗时分解（毫秒）
+	httpTiming struct {
+		DNS        float64 `json:"dns_ms"`
+		Connect    float64 `json:"connect_ms"`
+		TLS        float64 `json:"tls_ms"`
+		TTFB       float64 `json:"ttfb_ms"`
+		Transfer   float64 `json:"transfer_ms"`
+		Total      float64 `json:"total_ms"`
+		ConnReused bool    `json:"conn_reused"`
+		Attempt    int     `json:"attempt"`
+		RemoteAddr string  `json:"remote_addr,omitempty"`
+	}
+
+	// echoResponse echo 服务器的响应
+	echoResponse struct {
+		Method     string              `json:"method"`
+		Path       string              `json:"path"`
+		Query      map[string][]string `json:"query,omitempty"`
+		Headers    http.Header         `json:"headers"`
+		Body       string              `json:"body,omitempty"`
+		RemoteAddr string              `json:"remote_addr"`
+	}
+
+	// statusRecorder 记录响应状态码（用于访问日志）
+	statusRecorder struct {
+		http.ResponseWriter
+		status int
+	}
+)
+
+// RegisterHTTPCommands 注册 HTTP 调试相关命令
+func RegisterHTTPCommands(tool *cobrax.Tool) {
+	httpGroup := cobrax.NewCommandGroup("http")
+
+	httpCmd := tool.NewCommand(
+		"http",
+		"HTTP 调试工具",
+		"类似 curl 的 HTTP 请求工具（基于 restyx），以及本地 echo/文件服务器",
+		nil,
+	)
+	httpCmd.Command.GroupID = "http"
+
+	// http get url - GET 请求
+	getCmd := tool.NewCommand(
+		"get",
+		"发送 GET 请求",
+		"devtool http get <URL>",
+		cobrax.CmdRunnerFunc(func(cmd *cobra.Command, args []string) error {
+			if len(args) == 0 {
+				return fmt.Errorf("用法: devtool http get <URL>")
+			}
+			return doHTTP(cmd, http.MethodGet, args[0])
+		}),
+	)
+	addHTTPFlags(getCmd)
+
+	// http post url - POST 请求
+	postCmd := tool.NewCommand(
+		"post",
+		"发送 POST 请求",
+		"devtool http post <URL> -d <请求体>，请求体以 @ 开头时读取文件，为 - 时读取标准输入",
+		cobrax.CmdRunnerFunc(func(cmd *cobra.Command, args []string) error {
+			if len(args) == 0 {
+				return fmt.Errorf("用法: devtool http post <URL> -d <请求体>")
+			}
+			return doHTTP(cmd, http.MethodPost, args[0])
+		}),
+	)
+	addHTTPFlags(postCmd)
+	postCmd.AddFlag("data", "d", "", "请求体（@文件 或 - 读取标准输入）")
+
+	// http head url - HEAD 请求
+	headCmd := tool.NewCommand(
+		"head",
+		"发送 HEAD 请求",
+		"devtool http head <URL>，输出响应头",
+		cobrax.CmdRunnerFunc(func(cmd *cobra.Command, args []string) error {
+			if len(args) == 0 {
+				return fmt.Errorf("用法: devtool http head <URL>")
+			}
+			return doHTTP(cmd, http.MethodHead, args[0])
+		}),
+	)
+	addHTTPFlags(headCmd)
+
+	// http serve [dir] - 本地服务器
+	serveCmd := tool.NewCommand(
+		"serve",
+		"启动本地 echo/文件服务器",
+		"指定目录时作为静态文件服务器，否则以 JSON 回显请求的方法、路径、请求头和请求体",
+		cobrax.CmdRunnerFunc(func(cmd *cobra.Command, args []string) error {
+			var handler http.Handler = http.HandlerFunc(echoHandler)
+			mode := "echo"
+			if len(args) > 0 {
+				if info, err := os.Stat(args[0]); err != nil || !info.IsDir() {
+					return fmt.Errorf("目录不存在: %s", args[0])
+				}
+				handler = http.FileServer(http.Dir(args[0]))
+				mode = "文件服务 " + args[0]
+			}
+
+			addr := viper.GetString("addr")
+			server := &http.Server{Addr: addr, Handler: accessLog(handler)}
+
+			ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
+			defer stop()
+			go func() {
+				<-ctx.Done()
+				shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+				defer cancel()
+				server.Shutdown(shutdownCtx)
+			}()
+
+			fmt.Printf("监听 %s（%s），按 Ctrl+C 退出\n", addr, mode)
+			if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				return err
+			}
+			return nil
+		}),
+	)
+	serveCmd.AddFlag("addr", "a", "127.0.0.1:8080", "监听地址（如: :8080 监听所有网卡）")
+
+	httpCmd.Command.AddCommand(getCmd.Command, postCmd.Command, headCmd.Command, serveCmd.Command)
+
+	httpGroup.AddCommand(httpCmd)
+	tool.AddGroupLogic(httpGroup)
+}
+
+// addHTTPFlags 添加请求命令的公共参数
+func addHTTPFlags(cmd *cobrax.Command) {
+	cmd.AddFlag("header", "H", []string{}, "请求头，如 -H 'Accept: text/plain'（可重复）")
+	cmd.AddFlag("user", "u", "", "Basic 认证（用户名:密码）")
+	cmd.AddFlag("token", "", "", "Bearer Token")
+	cmd.AddFlag("retries", "r", 0, "网络错误或 5xx 时的重试次数")
+	cmd.AddFlag("timeout", "t", "30s", "请求超时时间")
+	cmd.AddFlag("insecure", "k", false, "跳过 TLS 证书校验")
+	cmd.AddFlag("include", "i", false, "输出响应头")
+	cmd.AddFlag("timing", "", false, "输出耗时分解（DNS/连接/TLS/首字节）")
+	cmd.AddFlag("fail", "f", false, "状态码 >= 400 时返回非零退出码")
+	cmd.AddFlag("json", "", false, "以 JSON 输出请求结果")
+}
+
+// doHTTP 发送请求并输出结果（耗时分解输出到标准错误，便于管道处理响应体）
+func doHTTP(cmd *cobra.Command, method, url string) error {
+	if !strings.Contains(url, "://") {
+		url = "http://" + url
+	}
+
+	timeout, err := time.ParseDuration(viper.GetString("timeout"))
+	if err != nil {
+		return fmt.Errorf("无效的超时时间: %w", err)
+	}
+
+	cfg := restyx.DefaultConfig()
+	cfg.Timeout = timeout
+	cfg.RetryCount = viper.GetInt("retries")
+	cfg.InsecureSkipVerify = viper.GetBool("insecure")
+	cfg.DefaultHeaders = map[string]string{"User-Agent": "devtool/1.0"}
+	client := restyx.New(cfg, nil)
+
+	opts := []restyx.RequestOption{restyx.WithContext(cmd.Context()), restyx.WithTrace()}
+	for _, h := range viper.GetStringSlice("header") {
+		key, value, ok := strings.Cut(h, ":")
+		if !ok {
+			return fmt.Errorf("无效的请求头: %s（格式 名称: 值）", h)
+		}
+		opts = append(opts, restyx.WithHeader(strings.TrimSpace(key), strings.TrimSpace(value)))
+	}
+	if user := viper.GetString("user"); user != "" {
+		username, password, _ := strings.Cut(user, ":")
+		opts = append(opts, restyx.WithBasicAuth(username, password))
+	}
+	if token := viper.GetString("token"); token != "" {
+		opts = append(opts, restyx.WithBearerToken(token))
+	}
+	if method == http.MethodPost {
+		body, err := readBody(viper.GetString("data"))
+		if err != nil {
+			return err
+		}
+		if body != nil {
+			opts = append(opts, restyx.WithBody(body))
+		}
+	}
+
+	var resp *restyx.Response
+	switch method {
+	case http.MethodPost:
+		resp, err = client.Post(url, opts...)
+	case http.MethodHead:
+		resp, err = client.Head(url, opts...)
+	default:
+		resp, err = client.Get(url, opts...)
+	}
+	if err != nil {
+		return fmt.Errorf("请求失败: %w", err)
+	}
+
+	if viper.GetBool("json") {
+		if err := printJSON(newHTTPResult(method, url, resp)); err != nil {
+			return err
+		}
+	} else {
+		if viper.GetBool("include") || method == http.MethodHead {
+			printHeaders(resp)
+		}
+		os.Stdout.Write(resp.Body)
+		if len(resp.Body) > 0 && resp.Body[len(resp.Body)-1] != '\n' {
+			fmt.Println()
+		}
+		if viper.GetBool("timing") {
+			printTiming(resp)
+		}
+	}
+
+	if viper.GetBool("fail") && resp.StatusCode >= 400 {
+		return fmt.Errorf("请求返回 %d %s", resp.StatusCode, http.StatusText(resp.StatusCode))
+	}
+	return nil
+}
+
+// readBody 读取请求体：@path 读取文件，- 读取标准输入，其他为字面内容
+func readBody(spec string) ([]byte, error) {
+	switch {
+	case spec == "":
+		return nil, nil
+	case spec == "-":
+		return io.ReadAll(os.Stdin)
+	case strings.HasPrefix(spec, "@"):
+		data, err := os.ReadFile(spec[1:])
+		if err != nil {
+			return nil, fmt.Errorf("读取请求体文件失败: %w", err)
+		}
+		return data, nil
+	default:
+		return []byte(spec), nil
+	}
+}
+
+// newHTTPResult 构建 JSON 输出
+func newHTTPResult(method, url string, resp *restyx.Response) *httpResult {
+	result := &httpResult{
+		Method:  method,
+		URL:     url,
+		Status:  resp.StatusCode,
+		Headers: resp.Headers,
+		Size:    len(resp.Body),
+		Timing:  newHTTPTiming(resp.Trace),
+	}
+	if len(resp.Body) > 0 {
+		if json.Valid(resp.Body) {
+			result.Body = resp.Body
+		} else {
+			result.Body, _ = json.Marshal(string(resp.Body))
+		}
+	}
+	return result
+}
+
+// newHTTPTiming 转换耗时分解为毫秒
+func newHTTPTiming(trace *restyx.TraceInfo) *httpTiming {
+	if trace == nil {
+		return nil
+	}
+	ms := func(d time.Duration) float64 { return float64(d.Microseconds()) / 1000 }
+	return &httpTiming{
+		DNS:        ms(trace.DNSLookup),
+		Connect:    ms(trace.TCPConnect),
+		TLS:        ms(trace.TLSHandshake),
+		TTFB:       ms(trace.ServerTime),
+		Transfer:   ms(trace.ResponseTime),
+		Total:      ms(trace.TotalTime),
+		ConnReused: trace.ConnReused,
+		Attempt:    trace.Attempt,
+		RemoteAddr: trace.RemoteAddr,
+	}
+}
+
+// printHeaders 输出状态行和响应头（按名称排序）
+func printHeaders(resp *restyx.Response) {
+	fmt.Printf("%d %s\n", resp.StatusCode, http.StatusText(resp.StatusCode))
+	keys := make([]string, 0, len(resp.Headers))
+	for k := range resp.Headers {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		for _, v := range resp.Headers[k] {
+			fmt.Printf("%s: %s\n", k, v)
+		}
+	}
+	fmt.Println()
+}
+
+// printTiming 输出耗时分解到标准错误
+func printTiming(resp *restyx.Response) {
+	t := newHTTPTiming(resp.Trace)
+	if t == nil {
+		fmt.Fprintf(os.Stderr, "\n总耗时: %s\n", resp.Time.Round(time.Microsecond))
+		return
+	}
+
+	fmt.Fprintln(os.Stderr)
+	fmt.Fprintf(os.Stderr, "DNS 解析:  %8.2fms\n", t.DNS)
+	fmt.Fprintf(os.Stderr, "TCP 连接:  %8.2fms\n", t.Connect)
+	fmt.Fprintf(os.Stderr, "TLS 握手:  %8.2fms\n", t.TLS)
+	fmt.Fprintf(os.Stderr, "首字节:    %8.2fms\n", t.TTFB)
+	fmt.Fprintf(os.Stderr, "内容传输:  %8.2fms\n", t.Transfer)
+	fmt.Fprintf(os.Stderr, "总耗时:    %8.2fms\n", t.Total)
+	if t.RemoteAddr != "" {
+		fmt.Fprintf(os.Stderr, "服务端:    %s（连接复用: %v，第 %d 次尝试）\n", t.RemoteAddr, t.ConnReused, t.Attempt)
+	}
+}
+
+// echoHandler 以 JSON 回显请求
+func echoHandler(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+	w.Header().Set("Content-Type", "application/json")
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	enc.Encode(&echoResponse{
+		Method:     r.Method,
+		Path:       r.URL.Path,
+		Query:      r.URL.Query(),
+		Headers:    r.Header,
+		Body:       string(body),
+		RemoteAddr: r.RemoteAddr,
+	})
+}
+
+// accessLog 输出访问日志
+func accessLog(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(rec, r)
+		fmt.Printf("%s %s %s %d %s\n", start.Format("15:04:05"), r.Method, r.URL.RequestURI(), rec.status, time.Since(start).Round(time.Microsecond))
+	})
+}
+
+// WriteHeader 记录状态码
+func (r *statusRecorder) WriteHeader(code int) {
+	r.status = code
+	r.ResponseWriter.WriteHeader(code)
+}
@@ -0,0 +1,906 @@
+package commands
+
+import (
+	"bufio"
+	"bytes"
+	"cmp"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"os/signal"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"text/tabwriter"
+	"time"
+	"unicode/utf8"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"github.com/tedwangl/go-util/pkg/cobrax"
+	"github.com/tedwangl/go-util/pkg/utils/humanize"
+)
+
+// zapx 文件模式的默认字段名（LogConf.FieldKeys），同时兼容 zap 默认的 ts、msg
+var (
+	logTimeKeys    = []string{"@timestamp", "ts", "time"}
+	logLevelKeys   = []string{"level"}
+	logContentKeys = []string{"content", "msg"}
+)
+
+const (
+	logCallerKey      = "caller"
+	logDurationKey    = "duration"
+	logFingerprintKey = "fingerprint"
+	logErrorKey       = "error"
+)
+
+// logLevels 日志级别的顺序
+var logLevels = map[string]int{
+	"debug":  0,
+	"info":   1,
+	"warn":   2,
+	"error":  3,
+	"dpanic": 4,
+	"panic":  5,
+	"fatal":  6,
+}
+
+// logLevelAliases zapx 的日志类型对应写入的级别（severe 写入 error，slow 写入 warn）
+var logLevelAliases = map[string]string{
+	"warning": "warn",
+	"severe":  "error",
+	"slow":    "warn",
+	"stat":    "info",
+}
+
+type (
+	// logEntry 一行日志
+	logEntry struct {
+		File    string
+		Raw     string
+		Fields  map[string]any // JSON 日志的字段，非 JSON 时为空
+		Time    time.Time
+		Level   string
+		Content string
+	}
+
+	// logFilter 日志过滤条件
+	logFilter struct {
+		minLevel int // -1 表示不过滤
+		fields   []logFieldMatch
+		grep     *regexp.Regexp
+		since    time.Time
+	}
+
+	// logFieldMatch 字段匹配（值为 path.Match 模式，与 zapx 路由一致）
+	logFieldMatch struct {
+		key     string
+		pattern string
+	}
+
+	// logPrinter 输出日志（多个文件共用，避免行交错）
+	logPrinter struct {
+		mu       sync.Mutex
+		raw      bool
+		showFile bool
+		levels   map[string]*color.Color
+		dim      *color.Color
+	}
+
+	// logStats 日志统计
+	logStats struct {
+		Files    int             `json:"files"`
+		Total    int64           `json:"total"`
+		From     *time.Time      `json:"from,omitempty"`
+		To       *time.Time      `json:"to,omitempty"`
+		PerMin   float64         `json:"per_minute"`
+		Levels   []logCount      `json:"levels"`
+		Sources  []logCount      `json:"sources"`
+		Contents []logCount      `json:"top_contents"`
+		Errors   []logCount      `json:"top_errors,omitempty"` // 按 zapx 的错误指纹分组
+		Duration *logDurationSum `json:"duration,omitempty"`
+	}
+
+	// logCount 分组计数
+	logCount struct {
+		Key    string `json:"key"`
+		Count  int64  `json:"count"`
+		Level  string `json:"level,omitempty"`
+		Sample string `json:"sample,omitempty"`
+	}
+
+	// logDurationSum duration 字段的分布（zapx 的慢日志、访问日志）
+	logDurationSum struct {
+		Count int64   `json:"count"`
+		Avg   float64 `json:"avg_ms"`
+		P50   float64 `json:"p50_ms"`
+		P95   float64 `json:"p95_ms"`
+		P99   float64 `json:"p99_ms"`
+		Max   float64 `json:"max_ms"`
+	}
+)
+
+// RegisterLogCommands 注册日志查看相关命令
+//
+// 面向 zapx 文件模式输出的 JSON 日志（Path 目录下的 access.log、error.log 等），
+// 路径为目录时读取目录及一级子目录（zapx 路由目录）下的 *.log。
+// tail、stats 挂在 logs 命令下，logs [任务名] 仍查看定时任务执行日志，需在 RegisterScheduleCommands 之后调用
+func RegisterLogCommands(tool *cobrax.Tool) {
+	// logs tail - 查看日志
+	tailCmd := tool.NewCommand(
+		"tail",
+		"查看 zapx 日志",
+		"输出最后 N 条匹配的日志，--follow 持续输出新日志（跟随文件轮转）；多个文件时按时间合并",
+		cobrax.CmdRunnerFunc(func(cmd *cobra.Command, args []string) error {
+			files, err := logFiles(args)
+			if err != nil {
+				return err
+			}
+			filter, err := newLogFilter()
+			if err != nil {
+				return err
+			}
+
+			p := newLogPrinter(viper.GetBool("raw"), len(files) > 1, viper.GetBool("no-color"))
+			offsets, err := tailLogs(files, filter, viper.GetInt("lines"), p)
+			if err != nil {
+				return err
+			}
+			if !viper.GetBool("follow") {
+				return nil
+			}
+
+			ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
+			defer stop()
+			return followLogs(ctx, files, offsets, filter, p)
+		}),
+	)
+	tailCmd.AddFlag("follow", "f", false, "持续输出新日志")
+	tailCmd.AddFlag("lines", "n", 10, "输出最后的条数")
+	tailCmd.AddFlag("raw", "", false, "输出原始 JSON 行")
+	tailCmd.AddFlag("no-color", "", false, "不使用颜色")
+	tailCmd.AddExample("持续查看错误日志", "devtool logs tail logs --follow --level error")
+	tailCmd.AddExample("按字段过滤", "devtool logs tail logs/access.log --json-field status=500 -n 50")
+
+	// logs stats - 统计日志
+	statsCmd := tool.NewCommand(
+		"stats",
+		"统计 zapx 日志",
+		"统计各级别、各文件的日志数量，出现最多的内容和错误（按 zapx 错误指纹分组），以及 duration 字段的分布",
+		cobrax.CmdRunnerFunc(func(cmd *cobra.Command, args []string) error {
+			files, err := logFiles(args)
+			if err != nil {
+				return err
+			}
+			filter, err := newLogFilter()
+			if err != nil {
+				return err
+			}
+
+			stats, err := statLogs(files, filter, viper.GetInt("top"))
+			if err != nil {
+				return err
+			}
+			if viper.GetBool("json") {
+				return printJSON(stats)
+			}
+			return printLogStats(stats)
+		}),
+	)
+	statsCmd.AddFlag("top", "t", 10, "输出出现最多的内容条数")
+	statsCmd.AddFlag("json", "", false, "以 JSON 输出")
+	statsCmd.AddExample("最近一小时的统计", "devtool logs stats logs --since 1h")
+
+	// 过滤参数不能作为 logs 的持久化标志，-l 与 logs --limit 冲突
+	for _, cmd := range []*cobrax.Command{tailCmd, statsCmd} {
+		cmd.AddFlag("level", "l", "", "最低级别（debug, info, warn, error，severe 等同 error）")
+		cmd.AddFlag("json-field", "F", []string{}, "字段过滤 key=value，值支持通配符（如 status=5*），可指定多个")
+		cmd.AddFlag("grep", "g", "", "按正则过滤整行")
+		cmd.AddFlag("since", "s", "", "只看最近一段时间的日志（如 30m, 1h, 2d）")
+	}
+
+	root := tool.GetRootCommand()
+	for _, cmd := range root.Command.Commands() {
+		if cmd.Name() == "logs" {
+			cmd.AddCommand(tailCmd.Command, statsCmd.Command)
+			return
+		}
+	}
+
+	// 未注册定时任务命令时单独注册 logs
+	logGroup := cobrax.NewCommandGroup("log")
+	logsCmd := tool.NewCommand(
+		"logs",
+		"日志工具",
+		"查看和统计 zapx 输出的 JSON 日志，路径为目录时读取其中的 *.log（默认 ./logs）",
+		nil,
+		tailCmd, statsCmd,
+	)
+	logGroup.AddCommand(logsCmd)
+	tool.AddGroupLogic(logGroup)
+}
+
+// logFiles 展开日志路径，目录读取其中及一级子目录下的 *.log，没有参数时使用 ./logs
+func logFiles(args []string) ([]string, error) {
+	if len(args) == 0 {
+		args = []string{"logs"}
+	}
+
+	var files []string
+	for _, arg := range args {
+		info, err := os.Stat(arg)
+		if err != nil {
+			return nil, err
+		}
+		if !info.IsDir() {
+			files = append(files, arg)
+			continue
+		}
+
+		for _, pattern := range []string{"*.log", "*/*.log"} {
+			matches, err := filepath.Glob(filepath.Join(arg, pattern))
+			if err != nil {
+				return nil, err
+			}
+			files = append(files, matches...)
+		}
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("未找到日志文件（*.log）")
+	}
+	return files, nil
+}
+
+// newLogFilter 从命令行参数创建过滤条件
+func newLogFilter() (*logFilter, error) {
+	f := &logFilter{minLevel: -1}
+
+	if level := viper.GetString("level"); level != "" {
+		rank, ok := logLevels[normalizeLevel(level)]
+		if !ok {
+			return nil, fmt.Errorf("无效的日志级别: %s", level)
+		}
+		f.minLevel = rank
+	}
+
+	for _, kv := range viper.GetStringSlice("json-field") {
+		key, pattern, ok := strings.Cut(kv, "=")
+		if !ok || key == "" {
+			return nil, fmt.Errorf("无效的字段过滤 %s，格式为 key=value", kv)
+		}
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("无效的字段过滤 %s: %w", kv, err)
+		}
+		f.fields = append(f.fields, logFieldMatch{key: key, pattern: pattern})
+	}
+
+	if expr := viper.GetString("grep"); expr != "" {
+		re, err := regexp.Compile(expr)
+		if err != nil {
+			return nil, fmt.Errorf("无效的正则: %w", err)
+		}
+		f.grep = re
+	}
+
+	if since := viper.GetString("since"); since != "" {
+		d, err := humanize.ParseDuration(since)
+		if err != nil {
+			return nil, fmt.Errorf("无效的时间范围: %w", err)
+		}
+		f.since = time.Now().Add(-d)
+	}
+
+	return f, nil
+}
+
+// match 日志是否满足条件（没有时间的日志不受 since 限制）
+func (f *logFilter) match(e *logEntry) bool {
+	if f.minLevel >= 0 {
+		rank, ok := logLevels[e.Level]
+		if !ok || rank < f.minLevel {
+			return false
+		}
+	}
+	if !f.since.IsZero() && !e.Time.IsZero() && e.Time.Before(f.since) {
+		return false
+	}
+	for _, m := range f.fields {
+		value, ok := e.Fields[m.key]
+		if !ok {
+			return false
+		}
+		if matched, _ := path.Match(m.pattern, logValue(value)); !matched {
+			return false
+		}
+	}
+	if f.grep != nil && !f.grep.MatchString(e.Raw) {
+		return false
+	}
+	return true
+}
+
+// parseLogLine 解析一行日志
+//
+// JSON 日志按 zapx 的字段名解析；zapx console 编码（时间\t级别\t...）解析出时间和级别，其余作为内容
+func parseLogLine(file, line string) *logEntry {
+	e := &logEntry{File: file, Raw: line}
+
+	if strings.HasPrefix(line, "{") {
+		dec := json.NewDecoder(strings.NewReader(line))
+		dec.UseNumber()
+		if err := dec.Decode(&e.Fields); err == nil {
+			e.Time = parseLogTime(lookupField(e.Fields, logTimeKeys))
+			e.Level = normalizeLevel(logValue(lookupField(e.Fields, logLevelKeys)))
+			e.Content = logValue(lookupField(e.Fields, logContentKeys))
+			return e
+		}
+	}
+
+	e.Content = line
+	if parts := strings.SplitN(line, "\t", 3); len(parts) == 3 {
+		level := normalizeLevel(parts[1])
+		if _, ok := logLevels[level]; ok {
+			e.Time = parseLogTime(parts[0])
+			e.Level = level
+			e.Content = parts[2]
+		}
+	}
+	return e
+}
+
+// lookupField 返回第一个存在的字段值
+func lookupField(fields map[string]any, keys []string) any {
+	for _, key := range keys {
+		if v, ok := fields[key]; ok {
+			return v
+		}
+	}
+	return nil
+}
+
+// parseLogTime 解析时间，支持 RFC3339（zapx 默认格式）和秒级时间戳（zap 默认格式）
+func parseLogTime(v any) time.Time {
+	switch v := v.(type) {
+	case json.Number:
+		if f, err := v.Float64(); err == nil {
+			sec, frac := math.Modf(f)
+			return time.Unix(int64(sec), int64(frac*1e9))
+		}
+	case string:
+		for _, layout := range []string{time.RFC3339Nano, "2006-01-02T15:04:05.000Z0700", "2006-01-02 15:04:05.000", "2006-01-02 15:04:05"} {
+			if t, err := time.ParseInLocation(layout, v, time.Local); err == nil {
+				return t
+			}
+		}
+	}
+	return time.Time{}
+}
+
+// normalizeLevel 统一级别名称（小写，zapx 的日志类型转为写入的级别）
+func normalizeLevel(level string) string {
+	level = strings.ToLower(strings.TrimSpace(level))
+	if alias, ok := logLevelAliases[level]; ok {
+		return alias
+	}
+	return level
+}
+
+// logValue 字段值的字符串形式
+func logValue(v any) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case json.Number:
+		return v.String()
+	case bool:
+		return strconv.FormatBool(v)
+	}
+	data, _ := json.Marshal(v)
+	return string(data)
+}
+
+// tailLogs 输出各文件最后 n 条匹配的日志（按时间合并），返回各文件当前的大小
+func tailLogs(files []string, filter *logFilter, n int, p *logPrinter) ([]int64, error) {
+	offsets := make([]int64, len(files))
+	var entries []*logEntry
+	for i, file := range files {
+		found, size, err := lastLogEntries(file, filter, n)
+		if err != nil {
+			return nil, err
+		}
+		offsets[i] = size
+		entries = append(entries, found...)
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].Time.Before(entries[j].Time)
+	})
+	if len(entries) > n {
+		entries = entries[len(entries)-n:]
+	}
+	for _, e := range entries {
+		p.print(e)
+	}
+	return offsets, nil
+}
+
+// lastLogEntries 从文件末尾向前读取最后 n 条匹配的日志
+func lastLogEntries(file string, filter *logFilter, n int) ([]*logEntry, int64, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return nil, 0, err
+	}
+	size := info.Size()
+	if n <= 0 {
+		return nil, size, nil
+	}
+
+	const blockSize = 64 * 1024
+	var (
+		entries []*logEntry
+		tail    []byte // 上一块开头不完整的行
+		pos     = size
+	)
+	for pos > 0 && len(entries) < n {
+		readSize := min(int64(blockSize), pos)
+		pos -= readSize
+		block := make([]byte, readSize, int(readSize)+len(tail))
+		if _, err := f.ReadAt(block, pos); err != nil {
+			return nil, 0, err
+		}
+		block = append(block, tail...)
+
+		lines := bytes.Split(block, []byte{'\n'})
+		// 没读到文件开头时第一行可能不完整，留到下一块
+		start := 0
+		if pos > 0 {
+			tail, start = lines[0], 1
+		}
+		for i := len(lines) - 1; i >= start && len(entries) < n; i-- {
+			if line := strings.TrimRight(string(lines[i]), "\r"); line != "" {
+				if e := parseLogLine(file, line); filter.match(e) {
+					entries = append(entries, e)
+				}
+			}
+		}
+	}
+
+	// 倒序读取，恢复为文件中的顺序
+	for i, j := 0, len(entries)-1; i < j; i, j = i+1, j-1 {
+		entries[i], entries[j] = entries[j], entries[i]
+	}
+	return entries, size, nil
+}
+
+// followLogs 持续输出各文件新增的日志，文件被轮转（替换或截断）时从新文件开头读取
+func followLogs(ctx context.Context, files []string, offsets []int64, filter *logFilter, p *logPrinter) error {
+	var wg sync.WaitGroup
+	errs := make([]error, len(files))
+	for i, file := range files {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs[i] = followLog(ctx, file, offsets[i], filter, p)
+		}()
+	}
+	wg.Wait()
+
+	return errors.Join(errs...)
+}
+
+func followLog(ctx context.Context, file string, offset int64, filter *logFilter, p *logPrinter) error {
+	const interval = 500 * time.Millisecond
+
+	f, err := os.Open(file)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		f.Close()
+	}()
+	if _, err := f.Seek(offset, io.SeekStart); err != nil {
+		return err
+	}
+	r := bufio.NewReader(f)
+
+	var partial string
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		// 读到末尾为止，不完整的行等下次补全
+		for {
+			line, err := r.ReadString('\n')
+			if err != nil {
+				partial += line
+				break
+			}
+			offset += int64(len(line))
+			line = strings.TrimRight(partial+line, "\r\n")
+			partial = ""
+			if line == "" {
+				continue
+			}
+			if e := parseLogLine(file, line); filter.match(e) {
+				p.print(e)
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+
+		current, statErr := os.Stat(file)
+		opened, err := f.Stat()
+		if err != nil {
+			return err
+		}
+		rotated := statErr == nil && !os.SameFile(current, opened)
+		truncated := opened.Size() < offset+int64(len(partial))
+		if !rotated && !truncated {
+			continue
+		}
+
+		if rotated {
+			// 读完旧文件剩余的内容再切换
+			if rest, _ := io.ReadAll(r); len(partial)+len(rest) > 0 {
+				for _, line := range strings.Split(strings.TrimRight(partial+string(rest), "\n"), "\n") {
+					if e := parseLogLine(file, line); line != "" && filter.match(e) {
+						p.print(e)
+					}
+				}
+			}
+			nf, err := os.Open(file)
+			if err != nil {
+				return err
+			}
+			f.Close()
+			f = nf
+		} else if _, err := f.Seek(0, io.SeekStart); err != nil {
+			return err
+		}
+		r.Reset(f)
+		offset, partial = 0, ""
+	}
+}
+
+func newLogPrinter(raw, showFile, noColor bool) *logPrinter {
+	p := &logPrinter{
+		raw:      raw,
+		showFile: showFile,
+		levels: map[string]*color.Color{
+			"debug":  color.New(color.FgHiBlack),
+			"info":   color.New(color.FgGreen),
+			"warn":   color.New(color.FgYellow),
+			"error":  color.New(color.FgRed),
+			"dpanic": color.New(color.FgRed, color.Bold),
+			"panic":  color.New(color.FgRed, color.Bold),
+			"fatal":  color.New(color.FgRed, color.Bold),
+		},
+		dim: color.New(color.Faint),
+	}
+	if noColor {
+		for _, c := range p.levels {
+			c.DisableColor()
+		}
+		p.dim.DisableColor()
+	}
+	return p
+}
+
+// print 输出一条日志：时间 级别 [文件] 内容 key=value... caller
+func (p *logPrinter) print(e *logEntry) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.raw {
+		fmt.Println(e.Raw)
+		return
+	}
+
+	var b strings.Builder
+	if !e.Time.IsZero() {
+		b.WriteString(p.dim.Sprint(e.Time.Local().Format("2006-01-02 15:04:05.000")))
+		b.WriteByte(' ')
+	}
+	if e.Level != "" {
+		level := fmt.Sprintf("%-5s", strings.ToUpper(e.Level))
+		if c, ok := p.levels[e.Level]; ok {
+			level = c.Sprint(level)
+		}
+		b.WriteString(level)
+		b.WriteByte(' ')
+	}
+	if p.showFile {
+		b.WriteString(p.dim.Sprintf("[%s] ", filepath.Base(e.File)))
+	}
+	b.WriteString(e.Content)
+
+	keys := make([]string, 0, len(e.Fields))
+	for key := range e.Fields {
+		if !isLogMetaKey(key) {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		fmt.Fprintf(&b, " %s=%s", p.dim.Sprint(key), logValue(e.Fields[key]))
+	}
+	if caller := logValue(e.Fields[logCallerKey]); caller != "" {
+		b.WriteString(p.dim.Sprint(" " + caller))
+	}
+
+	fmt.Println(b.String())
+}
+
+// isLogMetaKey 时间、级别、内容和调用位置单独输出
+func isLogMetaKey(key string) bool {
+	for _, keys := range [][]string{logTimeKeys, logLevelKeys, logContentKeys} {
+		for _, k := range keys {
+			if k == key {
+				return true
+			}
+		}
+	}
+	return key == logCallerKey
+}
+
+// statLogs 统计日志
+func statLogs(files []string, filter *logFilter, top int) (*logStats, error) {
+	stats := &logStats{Files: len(files)}
+	levels := make(map[string]int64)
+	sources := make(map[string]int64)
+	contents := make(map[string]*logCount)
+	fingerprints := make(map[string]*logCount)
+	var durations []float64
+	var from, to time.Time
+
+	for _, file := range files {
+		err := scanLogFile(file, func(e *logEntry) {
+			if !filter.match(e) {
+				return
+			}
+
+			stats.Total++
+			levels[cmp.Or(e.Level, "-")]++
+			sources[file]++
+			if !e.Time.IsZero() {
+				if from.IsZero() || e.Time.Before(from) {
+					from = e.Time
+				}
+				if e.Time.After(to) {
+					to = e.Time
+				}
+			}
+
+			content := truncateLogContent(e.Content, 100)
+			c, ok := contents[content]
+			if !ok {
+				c = &logCount{Key: content, Level: e.Level}
+				contents[content] = c
+			}
+			c.Count++
+
+			if fp := logValue(e.Fields[logFingerprintKey]); fp != "" {
+				c, ok := fingerprints[fp]
+				if !ok {
+					sample := logValue(e.Fields[logErrorKey])
+					if sample == "" {
+						sample = e.Content
+					}
+					c = &logCount{Key: fp, Level: e.Level, Sample: truncateLogContent(sample, 100)}
+					fingerprints[fp] = c
+				}
+				c.Count++
+			}
+
+			if v, ok := e.Fields[logDurationKey]; ok {
+				if d, ok := parseLogDuration(v); ok {
+					durations = append(durations, d)
+				}
+			}
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if !from.IsZero() {
+		stats.From, stats.To = &from, &to
+		if span := to.Sub(from); span >= time.Minute {
+			stats.PerMin = math.Round(float64(stats.Total)/span.Minutes()*10) / 10
+		}
+	}
+
+	for level, n := range levels {
+		stats.Levels = append(stats.Levels, logCount{Key: level, Count: n})
+	}
+	sort.Slice(stats.Levels, func(i, j int) bool {
+		ri, ok := logLevels[stats.Levels[i].Key]
+		if !ok {
+			ri = -1
+		}
+		rj, ok := logLevels[stats.Levels[j].Key]
+		if !ok {
+			rj = -1
+		}
+		return ri < rj
+	})
+	for file, n := range sources {
+		stats.Sources = append(stats.Sources, logCount{Key: file, Count: n})
+	}
+	sortLogCounts(stats.Sources)
+	stats.Contents = topLogCounts(contents, top)
+	stats.Errors = topLogCounts(fingerprints, top)
+	stats.Duration = summarizeDurations(durations)
+
+	return stats, nil
+}
+
+// scanLogFile 逐行解析日志文件
+func scanLogFile(file string, fn func(e *logEntry)) error {
+	f, err := os.Open(file)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	r := bufio.NewReader(f)
+	for {
+		line, err := r.ReadString('\n')
+		if line = strings.TrimRight(line, "\r\n"); line != "" {
+			fn(parseLogLine(file, line))
+		}
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// parseLogDuration 解析 duration 字段（zapx 输出为 "12.3ms" 形式，数字按毫秒处理），返回毫秒
+func parseLogDuration(v any) (float64, bool) {
+	switch v := v.(type) {
+	case json.Number:
+		f, err := v.Float64()
+		return f, err == nil
+	case string:
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			return 0, false
+		}
+		return float64(d.Microseconds()) / 1000, true
+	}
+	return 0, false
+}
+
+func summarizeDurations(durations []float64) *logDurationSum {
+	if len(durations) == 0 {
+		return nil
+	}
+
+	sort.Float64s(durations)
+	var sum float64
+	for _, d := range durations {
+		sum += d
+	}
+	percentile := func(p float64) float64 {
+		return durations[int(math.Ceil(p*float64(len(durations))))-1]
+	}
+	round := func(f float64) float64 {
+		return math.Round(f*100) / 100
+	}
+	return &logDurationSum{
+		Count: int64(len(durations)),
+		Avg:   round(sum / float64(len(durations))),
+		P50:   round(percentile(0.5)),
+		P95:   round(percentile(0.95)),
+		P99:   round(percentile(0.99)),
+		Max:   round(durations[len(durations)-1]),
+	}
+}
+
+func topLogCounts(counts map[string]*logCount, top int) []logCount {
+	result := make([]logCount, 0, len(counts))
+	for _, c := range counts {
+		result = append(result, *c)
+	}
+	sortLogCounts(result)
+	if top > 0 && len(result) > top {
+		result = result[:top]
+	}
+	return result
+}
+
+// sortLogCounts 按数量降序排列，数量相同时按 Key 排序
+func sortLogCounts(counts []logCount) {
+	sort.Slice(counts, func(i, j int) bool {
+		if counts[i].Count != counts[j].Count {
+			return counts[i].Count > counts[j].Count
+		}
+		return counts[i].Key < counts[j].Key
+	})
+}
+
+// truncateLogContent 截断内容并去掉换行
+func truncateLogContent(s string, n int) string {
+	s = strings.Join(strings.Fields(s), " ")
+	if utf8.RuneCountInString(s) <= n {
+		return s
+	}
+	return string([]rune(s)[:n]) + "..."
+}
+
+// printLogStats 输出日志统计
+func printLogStats(s *logStats) error {
+	if s.Total == 0 {
+		fmt.Println("没有匹配的日志")
+		return nil
+	}
+
+	fmt.Printf("文件: %d，日志: %s", s.Files, humanize.Comma(s.Total))
+	if s.From != nil {
+		fmt.Printf("（%s ~ %s", s.From.Local().Format("2006-01-02 15:04:05"), s.To.Local().Format("2006-01-02 15:04:05"))
+		if s.PerMin > 0 {
+			fmt.Printf("，%s 条/分钟", humanize.CommaFloat(s.PerMin, 1))
+		}
+		fmt.Print("）")
+	}
+	fmt.Println()
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "\nLEVEL\tCOUNT\tPERCENT")
+	for _, c := range s.Levels {
+		fmt.Fprintf(w, "%s\t%s\t%.1f%%\n", c.Key, humanize.Comma(c.Count), float64(c.Count)*100/float64(s.Total))
+	}
+
+	if len(s.Sources) > 1 {
+		fmt.Fprintln(w, "\nFILE\tCOUNT\t")
+		for _, c := range s.Sources {
+			fmt.Fprintf(w, "%s\t%s\t\n", c.Key, humanize.Comma(c.Count))
+		}
+	}
+
+	fmt.Fprintln(w, "\nCOUNT\tLEVEL\tCONTENT")
+	for _, c := range s.Contents {
+		fmt.Fprintf(w, "%s\t%s\t%s\n", humanize.Comma(c.Count), c.Level, c.Key)
+	}
+
+	if len(s.Errors) > 0 {
+		fmt.Fprintln(w, "\nCOUNT\tFINGERPRINT\tERROR")
+		for _, c := range s.Errors {
+			fmt.Fprintf(w, "%s\t%s\t%s\n", humanize.Comma(c.Count), c.Key, c.Sample)
+		}
+	}
+
+	if d := s.Duration; d != nil {
+		fmt.Fprintln(w, "\nDURATION\tAVG\tP50\tP95\tP99\tMAX")
+		ms := func(f float64) string {
+			return (time.Duration(f * float64(time.Millisecond))).Round(time.Microsecond * 100).String()
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n", humanize.Comma(d.Count), ms(d.Avg), ms(d.P50), ms(d.P95), ms(d.P99), ms(d.Max))
+	}
+	return w.Flush()
+}
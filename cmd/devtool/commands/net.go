@@ -1,11 +1,16 @@
 package commands
 
 import (
+	"cmp"
 	"fmt"
 	"os"
 	"os/exec"
+	"os/signal"
+	"syscall"
+	"time"
 
 	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
 	"github.com/tedwangl/go-util/pkg/cobrax"
 )
 
@@ -122,25 +127,43 @@ func RegisterNetCommands(tool *cobrax.Tool) {
 	)
 	ncCmd.Command.GroupID = "net"
 
-	// nc -zv host port - 测试端口
+	// nc test host port - 测试端口（原生 TCP 连接，不依赖 nc）
 	ncTestCmd := tool.NewCommand(
 		"test",
 		"测试端口连通性",
-		"nc -zv <主机> <端口>",
+		"通过 TCP 连接测试端口是否开放，端口可以是服务名（如 ssh），不可达时返回非零退出码",
 		cobrax.CmdRunnerFunc(func(cmd *cobra.Command, args []string) error {
 			if len(args) < 2 {
 				return fmt.Errorf("用法: devtool nc test <主机> <端口>")
 			}
 
 			host := args[0]
-			port := args[1]
+			port, err := parsePort(args[1])
+			if err != nil {
+				return err
+			}
+			timeout, err := time.ParseDuration(viper.GetString("timeout"))
+			if err != nil {
+				return fmt.Errorf("无效的超时时间: %w", err)
+			}
 
-			c := exec.Command("nc", "-zv", host, port)
-			c.Stdout = os.Stdout
-			c.Stderr = os.Stderr
-			return c.Run()
+			r := probe(cmd.Context(), host, port, timeout)
+			if viper.GetBool("json") {
+				if err := printJSON(r); err != nil {
+					return err
+				}
+			} else if r.State == portOpen {
+				fmt.Printf("%s:%d 开放 (%s, %.1fms)\n", host, port, cmp.Or(r.Service, "unknown"), r.Latency)
+			}
+
+			if r.State != portOpen {
+				return fmt.Errorf("%s:%d 不可达: %s", host, port, r.Error)
+			}
+			return nil
 		}),
 	)
+	ncTestCmd.AddFlag("timeout", "t", "3s", "连接超时时间")
+	ncTestCmd.AddFlag("json", "", false, "以 JSON 输出")
 
 	// nc -l port - 监听端口
 	ncListenCmd := tool.NewCommand(
@@ -256,24 +279,28 @@ func RegisterNetCommands(tool *cobrax.Tool) {
 	)
 	nmapCmd.Command.GroupID = "net"
 
-	// nmap -sT host - TCP 扫描
+	// nmap tcp host - TCP 扫描（原生并发 connect 扫描，不依赖 nmap）
 	nmapTcpCmd := tool.NewCommand(
 		"tcp",
 		"TCP 端口扫描",
-		"nmap -sT <主机>",
+		"并发 TCP connect 扫描，默认扫描常见服务端口，--ports 指定端口（如 22,80,8000-8100）",
 		cobrax.CmdRunnerFunc(func(cmd *cobra.Command, args []string) error {
 			if len(args) == 0 {
 				return fmt.Errorf("用法: devtool nmap tcp <主机>")
 			}
 
-			host := args[0]
-			fmt.Printf("扫描主机 %s 的 TCP 端口...\n", host)
-			c := exec.Command("nmap", "-sT", host)
-			c.Stdout = os.Stdout
-			c.Stderr = os.Stderr
-			return c.Run()
+			ports := commonPorts()
+			if spec := viper.GetString("ports"); spec != "" {
+				var err error
+				if ports, err = parsePorts(spec); err != nil {
+					return err
+				}
+			}
+			return runScan(cmd, args[0], ports)
 		}),
 	)
+	nmapTcpCmd.AddFlag("ports", "p", "", "扫描的端口（默认常见服务端口）")
+	addScanFlags(nmapTcpCmd)
 
 	// nmap -sU host - UDP 扫描
 	nmapUdpCmd := tool.NewCommand(
@@ -294,25 +321,24 @@ func RegisterNetCommands(tool *cobrax.Tool) {
 		}),
 	)
 
-	// nmap -p 80,443 host - 扫描指定端口
+	// nmap port ports host - 扫描指定端口
 	nmapPortCmd := tool.NewCommand(
 		"port",
 		"扫描指定端口",
-		"nmap -p <端口> <主机>",
+		"并发 TCP connect 扫描指定端口，支持逗号分隔和范围（如 22,80,8000-8100）",
 		cobrax.CmdRunnerFunc(func(cmd *cobra.Command, args []string) error {
 			if len(args) < 2 {
 				return fmt.Errorf("用法: devtool nmap port <端口> <主机>")
 			}
 
-			port := args[0]
-			host := args[1]
-			fmt.Printf("扫描主机 %s 的端口 %s...\n", host, port)
-			c := exec.Command("nmap", "-p", port, host)
-			c.Stdout = os.Stdout
-			c.Stderr = os.Stderr
-			return c.Run()
+			ports, err := parsePorts(args[0])
+			if err != nil {
+				return err
+			}
+			return runScan(cmd, args[1], ports)
 		}),
 	)
+	addScanFlags(nmapPortCmd)
 
 	// nmap -sn 192.168.1.0/24 - 主机发现
 	nmapPingCmd := tool.NewCommand(
@@ -338,3 +364,43 @@ func RegisterNetCommands(tool *cobrax.Tool) {
 	netGroup.AddCommand(netstatCmd, ssCmd, ncCmd, tcpdumpCmd, nmapCmd)
 	tool.AddGroupLogic(netGroup)
 }
+
+// addScanFlags 添加端口扫描的公共参数
+func addScanFlags(cmd *cobrax.Command) {
+	cmd.AddFlag("timeout", "t", "1s", "单个端口的连接超时时间")
+	cmd.AddFlag("workers", "w", 200, "并发连接数")
+	cmd.AddFlag("all", "a", false, "同时输出关闭和被过滤的端口")
+	cmd.AddFlag("json", "", false, "以 JSON 输出")
+}
+
+// runScan 扫描主机端口并输出结果（Ctrl+C 中断时输出已完成的部分）
+func runScan(cmd *cobra.Command, host string, ports []int) error {
+	timeout, err := time.ParseDuration(viper.GetString("timeout"))
+	if err != nil {
+		return fmt.Errorf("无效的超时时间: %w", err)
+	}
+	asJSON := viper.GetBool("json")
+
+	ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if !asJSON {
+		fmt.Printf("扫描主机 %s 的 %d 个 TCP 端口...\n", host, len(ports))
+	}
+	start := time.Now()
+	results := scanPorts(ctx, host, ports, timeout, viper.GetInt("workers"))
+	if err := printScanResults(results, asJSON, viper.GetBool("all")); err != nil {
+		return err
+	}
+
+	if !asJSON {
+		open := 0
+		for _, r := range results {
+			if r.State == portOpen {
+				open++
+			}
+		}
+		fmt.Printf("扫描完成: %d 个端口开放，耗时 %s\n", open, time.Since(start).Round(time.Millisecond))
+	}
+	return nil
+}
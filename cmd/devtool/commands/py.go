@@ -1,9 +1,13 @@
 package commands
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"os/exec"
+	"strings"
+	"text/tabwriter"
 
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
@@ -21,10 +25,105 @@ func RegisterPyCommands(tool *cobrax.Tool) {
 		"列出所有 conda 环境",
 		"显示所有 conda 环境（* 表示当前环境）",
 		cobrax.CmdRunnerFunc(func(cmd *cobra.Command, args []string) error {
-			c := exec.Command("conda", "env", "list")
-			c.Stdout = os.Stdout
-			c.Stderr = os.Stderr
-			return c.Run()
+			envs, err := conda.ListEnvs()
+			if err != nil {
+				return err
+			}
+
+			w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+			for _, env := range envs {
+				active := " "
+				if env.Active {
+					active = "*"
+				}
+				name := env.Name
+				if name == "" {
+					name = "-"
+				}
+				fmt.Fprintf(w, "%s\t%s\t%s\n", name, active, env.Path)
+			}
+			return w.Flush()
+		}),
+	)
+
+	// conda create-env - 创建环境
+	createCmd := tool.NewCommand(
+		"create-env",
+		"创建 conda 环境",
+		"创建 conda 环境，可以同时安装包（devtool create-env myenv -p 3.11 numpy pandas）",
+		cobrax.CmdRunnerFunc(func(cmd *cobra.Command, args []string) error {
+			if len(args) == 0 {
+				return fmt.Errorf("请指定环境名称")
+			}
+
+			envName := args[0]
+			pythonVersion := viper.GetString("python")
+			fmt.Printf("正在创建环境 %s...\n", envName)
+			if err := conda.CreateEnv(envName, pythonVersion, args[1:]...); err != nil {
+				if errors.Is(err, conda.ErrEnvExists) {
+					return fmt.Errorf("环境 %s 已存在", envName)
+				}
+				return err
+			}
+
+			fmt.Printf("环境 %s 创建完成，执行 conda activate %s 切换\n", envName, envName)
+			return nil
+		}),
+	)
+	createCmd.AddFlag("python", "p", "", "Python 版本（如 3.11）")
+
+	// conda export-env - 导出环境
+	exportCmd := tool.NewCommand(
+		"export-env",
+		"导出 conda 环境",
+		"导出环境为 environment.yml（默认当前环境，输出到标准输出）",
+		cobrax.CmdRunnerFunc(func(cmd *cobra.Command, args []string) error {
+			envName := conda.GetCurrentEnv()
+			if len(args) > 0 {
+				envName = args[0]
+			}
+
+			data, err := conda.ExportEnv(envName, viper.GetBool("from-history"))
+			if err != nil {
+				return err
+			}
+
+			output := viper.GetString("output")
+			if output == "" {
+				_, err = os.Stdout.Write(data)
+				return err
+			}
+			if err := os.WriteFile(output, data, 0o644); err != nil {
+				return err
+			}
+			fmt.Printf("已导出环境 %s 到 %s\n", envName, output)
+			return nil
+		}),
+	)
+	exportCmd.AddFlag("output", "o", "", "输出文件（默认输出到标准输出）")
+	exportCmd.AddFlag("from-history", "", false, "只导出显式安装的包，便于跨平台重建")
+
+	// conda clone-env - 复制环境
+	cloneCmd := tool.NewCommand(
+		"clone-env",
+		"复制 conda 环境",
+		"复制已有环境为新环境（devtool clone-env <源环境> <新环境>）",
+		cobrax.CmdRunnerFunc(func(cmd *cobra.Command, args []string) error {
+			if len(args) < 2 {
+				return fmt.Errorf("请指定源环境和新环境名称")
+			}
+
+			src, dst := args[0], args[1]
+			fmt.Printf("正在复制环境 %s 到 %s...\n", src, dst)
+			if err := conda.CloneEnv(src, dst); err != nil {
+				if errors.Is(err, conda.ErrEnvExists) {
+					return fmt.Errorf("环境 %s 已存在", dst)
+				}
+				return err
+			}
+
+			fmt.Printf("环境 %s 创建完成\n", dst)
+			return nil
 		}),
 	)
 
@@ -56,10 +155,12 @@ func RegisterPyCommands(tool *cobrax.Tool) {
 			}
 
 			envName := args[0]
-			c := exec.Command("conda", "env", "remove", "-n", envName, "-y")
-			c.Stdout = os.Stdout
-			c.Stderr = os.Stderr
-			return c.Run()
+			if err := conda.RemoveEnv(envName); err != nil {
+				return err
+			}
+
+			fmt.Printf("环境 %s 已删除\n", envName)
+			return nil
 		}),
 	)
 
@@ -134,52 +235,53 @@ func RegisterPyCommands(tool *cobrax.Tool) {
 	runCmd := tool.NewCommand(
 		"run",
 		"运行 Python 脚本",
-		"在指定 conda 环境运行 Python 脚本",
+		"在 Python 环境运行脚本（conda、venv、pyenv、poetry，默认从项目目录识别）",
 		cobrax.CmdRunnerFunc(func(cmd *cobra.Command, args []string) error {
 			if len(args) == 0 {
 				return fmt.Errorf("请指定要运行的 Python 脚本")
 			}
 
-			envName := viper.GetString("env")
-			if envName == "" {
-				envName = conda.GetCurrentEnv()
+			env, err := resolvePyEnv(viper.GetString("backend"), viper.GetString("env"))
+			if err != nil {
+				return err
 			}
 
 			script := args[0]
-			scriptArgs := args[1:]
-
-			fmt.Printf("在环境 %s 中运行: %s\n", envName, script)
-			return conda.RunPython(envName, script, scriptArgs...)
+			fmt.Printf("在环境 %s 中运行: %s\n", env, script)
+			c := env.command(args...)
+			c.Stdout = os.Stdout
+			c.Stderr = os.Stderr
+			c.Stdin = os.Stdin
+			return c.Run()
 		}),
 	)
-	runCmd.AddFlag("env", "e", "", "环境名称（默认当前环境）")
+	addPyEnvFlags(runCmd)
 
 	// py exec - 执行 Python 命令
 	execCmd := tool.NewCommand(
 		"exec",
 		"执行 Python 命令",
-		"在指定环境执行 Python 命令",
+		"在 Python 环境执行 Python 命令",
 		cobrax.CmdRunnerFunc(func(cmd *cobra.Command, args []string) error {
 			if len(args) == 0 {
 				return fmt.Errorf("请指定要执行的 Python 命令")
 			}
 
-			envName := viper.GetString("env")
-			if envName == "" {
-				envName = conda.GetCurrentEnv()
+			env, err := resolvePyEnv(viper.GetString("backend"), viper.GetString("env"))
+			if err != nil {
+				return err
 			}
 
-			command := args[0]
-			output, err := conda.RunPythonCommand(envName, command)
+			output, err := env.command("-c", args[0]).CombinedOutput()
 			if err != nil {
-				return err
+				return fmt.Errorf("执行失败: %w\n%s", err, output)
 			}
 
-			fmt.Println(output)
+			fmt.Println(strings.TrimSpace(string(output)))
 			return nil
 		}),
 	)
-	execCmd.AddFlag("env", "e", "", "环境名称（默认当前环境）")
+	addPyEnvFlags(execCmd)
 
 	// py pip - pip 包管理
 	pipCmd := tool.NewCommand(
@@ -200,17 +302,20 @@ func RegisterPyCommands(tool *cobrax.Tool) {
 				return fmt.Errorf("请指定要安装的包名")
 			}
 
-			envName := viper.GetString("env")
-			if envName == "" {
-				envName = conda.GetCurrentEnv()
+			env, err := resolvePyEnv(viper.GetString("backend"), viper.GetString("env"))
+			if err != nil {
+				return err
 			}
 
 			packageName := args[0]
-			fmt.Printf("在环境 %s 中使用 pip 安装 %s...\n", envName, packageName)
-			return conda.PipInstall(envName, packageName)
+			fmt.Printf("在环境 %s 中使用 pip 安装 %s...\n", env, packageName)
+			c := env.command("-m", "pip", "install", packageName)
+			c.Stdout = os.Stdout
+			c.Stderr = os.Stderr
+			return c.Run()
 		}),
 	)
-	pipInstallCmd.AddFlag("env", "e", "", "环境名称（默认当前环境）")
+	addPyEnvFlags(pipInstallCmd)
 
 	// py pip uninstall
 	pipUninstallCmd := tool.NewCommand(
@@ -222,17 +327,20 @@ func RegisterPyCommands(tool *cobrax.Tool) {
 				return fmt.Errorf("请指定要卸载的包名")
 			}
 
-			envName := viper.GetString("env")
-			if envName == "" {
-				envName = conda.GetCurrentEnv()
+			env, err := resolvePyEnv(viper.GetString("backend"), viper.GetString("env"))
+			if err != nil {
+				return err
 			}
 
 			packageName := args[0]
-			fmt.Printf("在环境 %s 中使用 pip 卸载 %s...\n", envName, packageName)
-			return conda.PipUninstall(envName, packageName)
+			fmt.Printf("在环境 %s 中使用 pip 卸载 %s...\n", env, packageName)
+			c := env.command("-m", "pip", "uninstall", "-y", packageName)
+			c.Stdout = os.Stdout
+			c.Stderr = os.Stderr
+			return c.Run()
 		}),
 	)
-	pipUninstallCmd.AddFlag("env", "e", "", "环境名称（默认当前环境）")
+	addPyEnvFlags(pipUninstallCmd)
 
 	// py pip list
 	pipListCmd := tool.NewCommand(
@@ -240,17 +348,22 @@ func RegisterPyCommands(tool *cobrax.Tool) {
 		"列出 pip 包",
 		"列出 pip 安装的所有包",
 		cobrax.CmdRunnerFunc(func(cmd *cobra.Command, args []string) error {
-			envName := viper.GetString("env")
-			if envName == "" {
-				envName = conda.GetCurrentEnv()
+			env, err := resolvePyEnv(viper.GetString("backend"), viper.GetString("env"))
+			if err != nil {
+				return err
 			}
 
-			packages, err := conda.PipList(envName)
+			output, err := env.command("-m", "pip", "list", "--format=json").Output()
 			if err != nil {
-				return err
+				return fmt.Errorf("执行 pip list 失败: %w", err)
 			}
 
-			fmt.Printf("环境 %s 的 pip 包列表:\n", envName)
+			var packages []conda.Package
+			if err := json.Unmarshal(output, &packages); err != nil {
+				return fmt.Errorf("解析包列表失败: %w", err)
+			}
+
+			fmt.Printf("环境 %s 的 pip 包列表:\n", env)
 			fmt.Println("----------------------------------------")
 			for _, pkg := range packages {
 				fmt.Printf("%-40s %s\n", pkg.Name, pkg.Version)
@@ -258,9 +371,17 @@ func RegisterPyCommands(tool *cobrax.Tool) {
 			return nil
 		}),
 	)
-	pipListCmd.AddFlag("env", "e", "", "环境名称（默认当前环境）")
+	addPyEnvFlags(pipListCmd)
 
 	pipCmd.Command.AddCommand(pipInstallCmd.Command, pipUninstallCmd.Command, pipListCmd.Command)
-	pyGroup.AddCommand(envsCmd, activateCmd, removeEnvCmd, installCmd, channelsCmd, addChannelCmd, removeChannelCmd, runCmd, execCmd, pipCmd)
+	removeEnvCmd.RequireTypedConfirmation("删除 conda 环境", "")
+
+	pyGroup.AddCommand(envsCmd, createCmd, exportCmd, cloneCmd, activateCmd, removeEnvCmd, installCmd, channelsCmd, addChannelCmd, removeChannelCmd, runCmd, execCmd, pipCmd)
 	tool.AddGroupLogic(pyGroup)
 }
+
+// addPyEnvFlags 添加选择 Python 环境的参数
+func addPyEnvFlags(c *cobrax.Command) {
+	c.AddFlag("backend", "b", "", "Python 后端: conda, venv, pyenv, poetry（默认从项目目录识别）")
+	c.AddFlag("env", "e", "", "环境（conda 环境名、venv 目录、pyenv 版本或 poetry 的 Python 版本，默认当前环境）")
+}
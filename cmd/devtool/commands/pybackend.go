@@ -0,0 +1,259 @@
+package commands
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/tedwangl/go-util/pkg/conda"
+)
+
+// Python 环境后端
+const (
+	pyBackendConda  = "conda"
+	pyBackendVenv   = "venv"
+	pyBackendPyenv  = "pyenv"
+	pyBackendPoetry = "poetry"
+)
+
+type (
+	// pyEnv 解析出的 Python 环境
+	pyEnv struct {
+		Backend string
+		Name    string // conda 环境名、venv 目录、pyenv 版本或 poetry 项目
+		Python  string // python 可执行文件路径
+	}
+
+	// pyBackend Python 环境后端
+	pyBackend interface {
+		// detect 项目目录 dir 是否使用该后端
+		detect(dir string) bool
+		// resolve 解析环境 env，env 为空时使用项目或当前激活的环境
+		resolve(dir, env string) (*pyEnv, error)
+	}
+
+	condaBackend  struct{}
+	venvBackend   struct{}
+	pyenvBackend  struct{}
+	poetryBackend struct{}
+)
+
+// pyBackends 按识别顺序排列的后端
+var pyBackends = []struct {
+	name    string
+	backend pyBackend
+}{
+	{pyBackendPoetry, poetryBackend{}},
+	{pyBackendVenv, venvBackend{}},
+	{pyBackendPyenv, pyenvBackend{}},
+	{pyBackendConda, condaBackend{}},
+}
+
+// pyProjectMarkers 识别项目目录的文件
+var pyProjectMarkers = []string{"pyproject.toml", ".python-version", ".venv", "venv", "environment.yml"}
+
+// venvDirs 项目内 venv 的常用目录名
+var venvDirs = []string{".venv", "venv", "env"}
+
+// resolvePyEnv 解析 Python 环境
+//
+// backend 为空时从当前目录向上查找项目目录（包含 pyproject.toml、.python-version 等），
+// 依次检查 poetry、venv、pyenv、conda，都不匹配时使用已激活的 venv 或 conda
+func resolvePyEnv(backend, env string) (*pyEnv, error) {
+	dir := findPyProject()
+
+	if backend != "" {
+		for _, b := range pyBackends {
+			if b.name == backend {
+				return b.backend.resolve(dir, env)
+			}
+		}
+		return nil, fmt.Errorf("不支持的 Python 后端: %s（支持: conda, venv, pyenv, poetry）", backend)
+	}
+
+	for _, b := range pyBackends {
+		if b.backend.detect(dir) {
+			return b.backend.resolve(dir, env)
+		}
+	}
+
+	if os.Getenv("VIRTUAL_ENV") != "" {
+		return venvBackend{}.resolve(dir, env)
+	}
+	if _, err := exec.LookPath("conda"); err == nil {
+		return condaBackend{}.resolve(dir, env)
+	}
+	return nil, fmt.Errorf("未识别到 Python 环境，请使用 --backend 指定（conda, venv, pyenv, poetry）")
+}
+
+// findPyProject 从当前目录向上查找项目目录，找不到时返回当前目录
+func findPyProject() string {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return "."
+	}
+
+	for dir := cwd; ; dir = filepath.Dir(dir) {
+		for _, marker := range pyProjectMarkers {
+			if fileExists(filepath.Join(dir, marker)) {
+				return dir
+			}
+		}
+		if filepath.Dir(dir) == dir {
+			return cwd
+		}
+	}
+}
+
+// command 创建在环境中执行 python 的命令
+func (e *pyEnv) command(args ...string) *exec.Cmd {
+	return exec.Command(e.Python, args...)
+}
+
+// String 环境描述，如 conda:base
+func (e *pyEnv) String() string {
+	return e.Backend + ":" + e.Name
+}
+
+func (condaBackend) detect(dir string) bool {
+	return fileExists(filepath.Join(dir, "environment.yml")) || os.Getenv("CONDA_DEFAULT_ENV") != ""
+}
+
+func (condaBackend) resolve(_, env string) (*pyEnv, error) {
+	if env == "" {
+		env = conda.GetCurrentEnv()
+	}
+
+	python, err := conda.GetPythonPath(env)
+	if err != nil {
+		return nil, err
+	}
+	return &pyEnv{Backend: pyBackendConda, Name: env, Python: python}, nil
+}
+
+func (venvBackend) detect(dir string) bool {
+	return findVenv(dir) != ""
+}
+
+// resolve env 为 venv 目录（相对于项目目录），为空时使用项目内的 venv 或已激活的 venv
+func (venvBackend) resolve(dir, env string) (*pyEnv, error) {
+	venv := env
+	switch {
+	case venv != "" && !filepath.IsAbs(venv):
+		venv = filepath.Join(dir, venv)
+	case venv == "":
+		if venv = findVenv(dir); venv == "" {
+			venv = os.Getenv("VIRTUAL_ENV")
+		}
+	}
+	if venv == "" {
+		return nil, fmt.Errorf("未找到 venv，请先执行 python -m venv .venv 或使用 --env 指定目录")
+	}
+
+	python := venvPython(venv)
+	if !fileExists(python) {
+		return nil, fmt.Errorf("%s 不是有效的 venv（未找到 %s）", venv, python)
+	}
+	return &pyEnv{Backend: pyBackendVenv, Name: venv, Python: python}, nil
+}
+
+// findVenv 返回项目内的 venv 目录（包含 pyvenv.cfg）
+func findVenv(dir string) string {
+	for _, name := range venvDirs {
+		venv := filepath.Join(dir, name)
+		if fileExists(filepath.Join(venv, "pyvenv.cfg")) {
+			return venv
+		}
+	}
+	return ""
+}
+
+// venvPython venv 中的 python 路径
+func venvPython(venv string) string {
+	if runtime.GOOS == "windows" {
+		return filepath.Join(venv, "Scripts", "python.exe")
+	}
+	return filepath.Join(venv, "bin", "python")
+}
+
+func (pyenvBackend) detect(dir string) bool {
+	return fileExists(filepath.Join(dir, ".python-version"))
+}
+
+// resolve env 为 pyenv 版本，为空时使用 .python-version 或全局版本
+func (pyenvBackend) resolve(dir, env string) (*pyEnv, error) {
+	cmd := exec.Command("pyenv", "which", "python")
+	cmd.Dir = dir
+	if env != "" {
+		cmd.Env = append(os.Environ(), "PYENV_VERSION="+env)
+	}
+	python, err := outputLine(cmd)
+	if err != nil {
+		return nil, fmt.Errorf("执行 pyenv which python 失败: %w", err)
+	}
+
+	name := env
+	if name == "" {
+		cmd := exec.Command("pyenv", "version-name")
+		cmd.Dir = dir
+		if name, err = outputLine(cmd); err != nil {
+			name = filepath.Base(filepath.Dir(filepath.Dir(python)))
+		}
+	}
+	return &pyEnv{Backend: pyBackendPyenv, Name: name, Python: python}, nil
+}
+
+func (poetryBackend) detect(dir string) bool {
+	data, err := os.ReadFile(filepath.Join(dir, "pyproject.toml"))
+	return err == nil && bytes.Contains(data, []byte("[tool.poetry]"))
+}
+
+// resolve 使用项目的 poetry 环境，env 不为空时为 poetry env use 的 Python 版本
+func (poetryBackend) resolve(dir, env string) (*pyEnv, error) {
+	if env != "" {
+		cmd := exec.Command("poetry", "env", "use", env)
+		cmd.Dir = dir
+		cmd.Stdout = os.Stderr
+		cmd.Stderr = os.Stderr
+		if err := cmd.Run(); err != nil {
+			return nil, fmt.Errorf("执行 poetry env use %s 失败: %w", env, err)
+		}
+	}
+
+	cmd := exec.Command("poetry", "env", "info", "--executable")
+	cmd.Dir = dir
+	python, err := outputLine(cmd)
+	if err != nil {
+		return nil, fmt.Errorf("执行 poetry env info 失败: %w", err)
+	}
+	if python == "" || python == "NA" {
+		return nil, fmt.Errorf("poetry 环境未创建，请先执行 poetry install")
+	}
+	return &pyEnv{Backend: pyBackendPoetry, Name: filepath.Base(dir), Python: python}, nil
+}
+
+// outputLine 执行命令并返回输出的第一行
+func outputLine(cmd *exec.Cmd) (string, error) {
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	output, err := cmd.Output()
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", fmt.Errorf("%w: %s", err, msg)
+		}
+		return "", err
+	}
+
+	line, _, _ := strings.Cut(strings.TrimSpace(string(output)), "\n")
+	return strings.TrimSpace(line), nil
+}
+
+// fileExists 文件或目录是否存在
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
@@ -0,0 +1,694 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"sort"
+	"strings"
+	"sync"
+	"syscall"
+	"text/tabwriter"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"github.com/tedwangl/go-util/pkg/cobrax"
+	"github.com/tedwangl/go-util/pkg/redisx/client"
+	redisxconfig "github.com/tedwangl/go-util/pkg/redisx/config"
+	"github.com/tedwangl/go-util/pkg/redisx/migrate"
+	"github.com/tedwangl/go-util/pkg/utils/humanize"
+)
+
+// redisScanBatch 每次 SCAN 返回的键数量提示
+const redisScanBatch = 500
+
+type (
+	// redisValue get 命令的输出
+	redisValue struct {
+		Key   string `json:"key"`
+		Type  string `json:"type"`
+		TTL   string `json:"ttl"` // 永不过期时为 -1
+		Value any    `json:"value"`
+	}
+
+	// slowlogEntry 慢查询记录
+	slowlogEntry struct {
+		Node     string    `json:"node"`
+		ID       int64     `json:"id"`
+		Time     time.Time `json:"time"`
+		Duration float64   `json:"duration_ms"`
+		Command  string    `json:"command"`
+		Client   string    `json:"client,omitempty"`
+	}
+
+	// bigKey 键的内存占用
+	bigKey struct {
+		Key    string `json:"key"`
+		Type   string `json:"type"`
+		Bytes  int64  `json:"bytes"`
+		Length int64  `json:"length"` // 元素个数（string 为字节数）
+	}
+
+	// keyTypeSummary 按类型汇总的键数量和内存占用
+	keyTypeSummary struct {
+		Type  string `json:"type"`
+		Keys  int    `json:"keys"`
+		Bytes int64  `json:"bytes"`
+	}
+)
+
+// RegisterRedisCommands 注册 Redis 相关命令
+//
+// 连接配置从全局配置文件读取：redis.<conn> 可以是 redisx 配置文件路径，
+// 也可以直接写 redisx 配置（mode、single/sentinel/cluster 等），默认使用 redis.default，
+// redis.default 的每一项都可以用环境变量覆盖，如 DEVTOOL_REDIS_DEFAULT_SINGLE_ADDR
+func RegisterRedisCommands(tool *cobrax.Tool) {
+	redisxconfig.BindViper(viper.GetViper(), "redis.default")
+
+	redisGroup := cobrax.NewCommandGroup("redis")
+
+	redisCmd := tool.NewCommand(
+		"redis",
+		"Redis 工具",
+		"基于 redisx 的 Redis 查看工具，支持单节点、哨兵和集群（连接配置见 redis.<conn>）",
+		nil,
+	)
+	redisCmd.Command.GroupID = "redis"
+	redisCmd.AddPersistentFlag("conn", "", "default", "连接配置名称（redis.<conn>）")
+	redisCmd.AddPersistentFlag("addr", "", "", "直接连接单节点地址（忽略连接配置）")
+
+	// redis ping - 测试连接
+	pingCmd := tool.NewCommand(
+		"ping",
+		"测试连接",
+		"发送 PING 并输出延迟",
+		cobrax.CmdRunnerFunc(func(cmd *cobra.Command, args []string) error {
+			return withRedis(cmd, func(ctx context.Context, c client.Client, rdb redis.UniversalClient) error {
+				start := time.Now()
+				pong, err := c.Ping(ctx).Result()
+				if err != nil {
+					return fmt.Errorf("PING 失败: %w", err)
+				}
+				fmt.Printf("%s (%s)\n", pong, time.Since(start).Round(time.Microsecond))
+				return nil
+			})
+		}),
+	)
+
+	// redis get key - 查看键
+	getCmd := tool.NewCommand(
+		"get",
+		"查看键的值",
+		"按类型读取键的值（string/hash/list/set/zset）和过期时间",
+		cobrax.CmdRunnerFunc(func(cmd *cobra.Command, args []string) error {
+			if len(args) == 0 {
+				return fmt.Errorf("用法: devtool redis get <键>")
+			}
+
+			return withRedis(cmd, func(ctx context.Context, c client.Client, rdb redis.UniversalClient) error {
+				v, err := readRedisValue(ctx, rdb, args[0])
+				if err != nil {
+					return err
+				}
+				if viper.GetBool("json") {
+					return printJSON(v)
+				}
+
+				fmt.Printf("类型: %s  TTL: %s\n", v.Type, v.TTL)
+				switch val := v.Value.(type) {
+				case string:
+					fmt.Println(val)
+				case []string:
+					for i, item := range val {
+						fmt.Printf("%d) %s\n", i+1, item)
+					}
+				case map[string]string:
+					keys := make([]string, 0, len(val))
+					for k := range val {
+						keys = append(keys, k)
+					}
+					sort.Strings(keys)
+					w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+					for _, k := range keys {
+						fmt.Fprintf(w, "%s\t%s\n", k, val[k])
+					}
+					w.Flush()
+				case []redis.Z:
+					w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+					for _, z := range val {
+						fmt.Fprintf(w, "%v\t%g\n", z.Member, z.Score)
+					}
+					w.Flush()
+				}
+				return nil
+			})
+		}),
+	)
+	getCmd.AddFlag("json", "", false, "以 JSON 输出")
+
+	// redis set key value - 设置键
+	setCmd := tool.NewCommand(
+		"set",
+		"设置字符串键",
+		"devtool redis set <键> <值> [--ttl 10m] [--nx]",
+		cobrax.CmdRunnerFunc(func(cmd *cobra.Command, args []string) error {
+			if len(args) < 2 {
+				return fmt.Errorf("用法: devtool redis set <键> <值>")
+			}
+
+			var ttl time.Duration
+			if s := viper.GetString("ttl"); s != "" {
+				d, err := time.ParseDuration(s)
+				if err != nil {
+					return fmt.Errorf("无效的过期时间: %w", err)
+				}
+				ttl = d
+			}
+
+			return withRedis(cmd, func(ctx context.Context, c client.Client, rdb redis.UniversalClient) error {
+				if viper.GetBool("nx") {
+					ok, err := c.SetNX(ctx, args[0], args[1], ttl).Result()
+					if err != nil {
+						return err
+					}
+					if !ok {
+						return fmt.Errorf("键已存在: %s", args[0])
+					}
+				} else if err := c.Set(ctx, args[0], args[1], ttl).Err(); err != nil {
+					return err
+				}
+				fmt.Println("OK")
+				return nil
+			})
+		}),
+	)
+	setCmd.AddFlag("ttl", "t", "", "过期时间（如 10m，默认不过期）")
+	setCmd.AddFlag("nx", "", false, "仅在键不存在时设置")
+
+	// redis keys pattern - 扫描键
+	keysCmd := tool.NewCommand(
+		"keys",
+		"按模式扫描键",
+		"使用 SCAN 遍历匹配的键（不会像 KEYS 一样阻塞服务端），集群模式遍历所有主节点",
+		cobrax.CmdRunnerFunc(func(cmd *cobra.Command, args []string) error {
+			pattern := "*"
+			if len(args) > 0 {
+				pattern = args[0]
+			}
+			limit := viper.GetInt("limit")
+
+			return withRedis(cmd, func(ctx context.Context, c client.Client, rdb redis.UniversalClient) error {
+				var keys []string
+				err := scanKeys(ctx, rdb, pattern, viper.GetString("type"), func(_ *redis.Client, batch []string) bool {
+					keys = append(keys, batch...)
+					return limit <= 0 || len(keys) < limit
+				})
+				if err != nil {
+					return err
+				}
+				if limit > 0 && len(keys) > limit {
+					keys = keys[:limit]
+				}
+				sort.Strings(keys)
+
+				if viper.GetBool("json") {
+					return printJSON(keys)
+				}
+				for _, k := range keys {
+					fmt.Println(k)
+				}
+				if limit > 0 && len(keys) == limit {
+					fmt.Fprintf(os.Stderr, "已达到上限 %d 个，使用 --limit 调整\n", limit)
+				}
+				return nil
+			})
+		}),
+	)
+	keysCmd.AddFlag("type", "", "", "只返回指定类型的键（string/hash/list/set/zset/stream）")
+	keysCmd.AddFlag("limit", "n", 1000, "最多返回的键数量（0 为不限制）")
+	keysCmd.AddFlag("json", "", false, "以 JSON 输出")
+
+	// redis monitor-slowlog - 监控慢查询
+	slowlogCmd := tool.NewCommand(
+		"monitor-slowlog",
+		"监控慢查询日志",
+		"输出最近的慢查询，并按间隔轮询新增记录（集群模式轮询所有主节点），Ctrl+C 退出",
+		cobrax.CmdRunnerFunc(func(cmd *cobra.Command, args []string) error {
+			interval, err := time.ParseDuration(viper.GetString("interval"))
+			if err != nil || interval <= 0 {
+				return fmt.Errorf("无效的轮询间隔: %s", viper.GetString("interval"))
+			}
+			count := int64(viper.GetInt("count"))
+			once := viper.GetBool("once")
+			asJSON := viper.GetBool("json")
+
+			return withRedis(cmd, func(ctx context.Context, c client.Client, rdb redis.UniversalClient) error {
+				ctx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+				defer stop()
+
+				lastID := make(map[string]int64)
+				var mu sync.Mutex
+				poll := func() error {
+					var entries []slowlogEntry
+					err := forEachRedisNode(ctx, rdb, func(ctx context.Context, node *redis.Client) error {
+						logs, err := node.SlowLogGet(ctx, count).Result()
+						if err != nil {
+							return err
+						}
+
+						addr := node.Options().Addr
+						mu.Lock()
+						defer mu.Unlock()
+						last, seen := lastID[addr]
+						for _, l := range logs {
+							if seen && l.ID <= last {
+								continue
+							}
+							entries = append(entries, slowlogEntry{
+								Node:     addr,
+								ID:       l.ID,
+								Time:     l.Time,
+								Duration: float64(l.Duration.Microseconds()) / 1000,
+								Command:  strings.Join(l.Args, " "),
+								Client:   l.ClientAddr,
+							})
+							last = max(last, l.ID)
+						}
+						lastID[addr] = last
+						return nil
+					})
+					if err != nil {
+						return fmt.Errorf("读取慢查询失败: %w", err)
+					}
+
+					sort.Slice(entries, func(i, j int) bool { return entries[i].Time.Before(entries[j].Time) })
+					for _, e := range entries {
+						if asJSON {
+							printJSONLine(e)
+							continue
+						}
+						fmt.Printf("%s  %-21s  #%-6d %9.2fms  %s\n", e.Time.Format("2006-01-02 15:04:05"), e.Node, e.ID, e.Duration, e.Command)
+					}
+					return nil
+				}
+
+				if err := poll(); err != nil || once {
+					return err
+				}
+
+				ticker := time.NewTicker(interval)
+				defer ticker.Stop()
+				for {
+					select {
+					case <-ctx.Done():
+						return nil
+					case <-ticker.C:
+						if err := poll(); err != nil {
+							return err
+						}
+					}
+				}
+			})
+		}),
+	)
+	slowlogCmd.AddFlag("interval", "i", "2s", "轮询间隔")
+	slowlogCmd.AddFlag("count", "n", 20, "每次读取的最大条数")
+	slowlogCmd.AddFlag("once", "", false, "只输出当前的慢查询，不持续监控")
+	slowlogCmd.AddFlag("json", "", false, "每条记录输出一行 JSON")
+
+	// redis bigkeys - 查找大键
+	bigkeysCmd := tool.NewCommand(
+		"bigkeys",
+		"查找占用内存最多的键",
+		"SCAN 遍历键并通过 MEMORY USAGE 统计内存占用，输出最大的键和各类型汇总",
+		cobrax.CmdRunnerFunc(func(cmd *cobra.Command, args []string) error {
+			pattern := "*"
+			if len(args) > 0 {
+				pattern = args[0]
+			}
+			top := viper.GetInt("top")
+			samples := viper.GetInt("samples")
+
+			return withRedis(cmd, func(ctx context.Context, c client.Client, rdb redis.UniversalClient) error {
+				var (
+					keys    []bigKey
+					scanned int
+				)
+				err := scanKeys(ctx, rdb, pattern, "", func(node *redis.Client, batch []string) bool {
+					stats, err := measureKeys(ctx, node, batch, samples)
+					if err != nil {
+						fmt.Fprintf(os.Stderr, "统计失败: %v\n", err)
+						return true
+					}
+					keys = append(keys, stats...)
+					scanned += len(batch)
+					return true
+				})
+				if err != nil {
+					return err
+				}
+
+				sort.Slice(keys, func(i, j int) bool { return keys[i].Bytes > keys[j].Bytes })
+				summary := summarizeKeys(keys)
+				if top > 0 && len(keys) > top {
+					keys = keys[:top]
+				}
+
+				if viper.GetBool("json") {
+					return printJSON(map[string]any{"scanned": scanned, "top": keys, "types": summary})
+				}
+
+				fmt.Printf("共扫描 %s 个键\n\n", humanize.Comma(int64(scanned)))
+				w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+				fmt.Fprintln(w, "KEY\tTYPE\tMEMORY\tLENGTH")
+				for _, k := range keys {
+					fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", k.Key, k.Type, humanize.Bytes(uint64(k.Bytes)), humanize.Comma(k.Length))
+				}
+				fmt.Fprintln(w)
+				fmt.Fprintln(w, "TYPE\tKEYS\tMEMORY\t")
+				for _, s := range summary {
+					fmt.Fprintf(w, "%s\t%s\t%s\t\n", s.Type, humanize.Comma(int64(s.Keys)), humanize.Bytes(uint64(s.Bytes)))
+				}
+				return w.Flush()
+			})
+		}),
+	)
+	bigkeysCmd.AddFlag("top", "n", 20, "输出最大的键的数量")
+	bigkeysCmd.AddFlag("samples", "", 5, "MEMORY USAGE 对集合类型的采样数（0 为全部，较慢）")
+	bigkeysCmd.AddFlag("json", "", false, "以 JSON 输出")
+
+	// redis migrate pattern --to conn - 迁移键
+	migrateCmd := tool.NewCommand(
+		"migrate",
+		"在实例或集群之间复制键",
+		"按模式 SCAN 源端（--conn/--addr）的键，复制到目标端（--to/--to-addr），保留过期时间；\n"+
+			"默认使用 DUMP/RESTORE，跨版本时使用 --method command；--verify 只校验不写入",
+		cobrax.CmdRunnerFunc(func(cmd *cobra.Command, args []string) error {
+			pattern := "*"
+			if len(args) > 0 {
+				pattern = args[0]
+			}
+
+			toConn, toAddr := viper.GetString("to"), viper.GetString("to-addr")
+			if toConn == "" && toAddr == "" {
+				return fmt.Errorf("需要指定目标端: --to <conn> 或 --to-addr <地址>")
+			}
+			if srcAddr := viper.GetString("addr"); toAddr != "" && toAddr == srcAddr ||
+				toAddr == "" && srcAddr == "" && toConn == viper.GetString("conn") {
+				return fmt.Errorf("源端和目标端相同")
+			}
+			progress, err := time.ParseDuration(viper.GetString("progress"))
+			if err != nil {
+				return fmt.Errorf("无效的进度输出间隔: %s", viper.GetString("progress"))
+			}
+			dstCfg, err := loadRedisConn(toConn, toAddr)
+			if err != nil {
+				return err
+			}
+
+			method := migrate.Method(viper.GetString("method"))
+			verify := viper.GetBool("verify")
+			asJSON := viper.GetBool("json")
+			opts := []migrate.Option{
+				migrate.WithPattern(pattern),
+				migrate.WithType(viper.GetString("type")),
+				migrate.WithMethod(method),
+				migrate.WithRate(float64(viper.GetInt("rate"))),
+				migrate.WithBatchSize(viper.GetInt("batch")),
+				migrate.WithProgress(func(s migrate.Stats) {
+					fmt.Fprintf(os.Stderr, "已扫描 %s，复制 %s，跳过 %s，失败 %s（%s）\n",
+						humanize.Comma(s.Scanned), humanize.Comma(s.Copied+s.Matched), humanize.Comma(s.Skipped),
+						humanize.Comma(s.Failed+s.Missing+s.Mismatched), s.Elapsed.Round(time.Second))
+				}, progress),
+			}
+			if viper.GetBool("replace") {
+				opts = append(opts, migrate.WithReplace())
+			}
+
+			return withRedis(cmd, func(ctx context.Context, c client.Client, rdb redis.UniversalClient) error {
+				ctx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+				defer stop()
+
+				dst, err := client.NewClient(dstCfg)
+				if err != nil {
+					return fmt.Errorf("连接目标端失败: %w", err)
+				}
+				defer dst.Close()
+
+				run := migrate.Copy
+				if verify {
+					run = migrate.Verify
+				}
+				stats, err := run(ctx, c, dst, opts...)
+				if stats == nil {
+					return err
+				}
+
+				if asJSON {
+					if jsonErr := printJSON(stats); jsonErr != nil {
+						return jsonErr
+					}
+				} else {
+					if verify {
+						fmt.Printf("校验 %s 个键：一致 %s，目标端缺失 %s，不一致 %s，失败 %s，跳过 %s（%s）\n",
+							humanize.Comma(stats.Scanned), humanize.Comma(stats.Matched), humanize.Comma(stats.Missing),
+							humanize.Comma(stats.Mismatched), humanize.Comma(stats.Failed), humanize.Comma(stats.Skipped), stats.Elapsed.Round(time.Millisecond))
+					} else {
+						fmt.Printf("扫描 %s 个键：复制 %s，跳过 %s，失败 %s（%s）\n",
+							humanize.Comma(stats.Scanned), humanize.Comma(stats.Copied), humanize.Comma(stats.Skipped),
+							humanize.Comma(stats.Failed), stats.Elapsed.Round(time.Millisecond))
+					}
+					for _, f := range stats.Failures {
+						fmt.Printf("  %s: %s\n", f.Key, f.Error)
+					}
+				}
+
+				if err != nil {
+					return err
+				}
+				if stats.Failed+stats.Missing+stats.Mismatched > 0 {
+					return fmt.Errorf("%s 个键失败或不一致", humanize.Comma(stats.Failed+stats.Missing+stats.Mismatched))
+				}
+				return nil
+			})
+		}),
+	)
+	migrateCmd.AddFlag("to", "", "", "目标端连接配置名称（redis.<conn>）")
+	migrateCmd.AddFlag("to-addr", "", "", "直接连接目标端单节点地址")
+	migrateCmd.AddFlag("type", "", "", "只迁移指定类型的键（string/hash/list/set/zset/stream）")
+	migrateCmd.AddFlag("method", "m", string(migrate.MethodDump), "复制方式：dump（DUMP/RESTORE）或 command（按类型读写）")
+	migrateCmd.AddFlag("replace", "", false, "覆盖目标端已存在的键（默认跳过）")
+	migrateCmd.AddFlag("rate", "r", 0, "每秒最多迁移的键数量（0 为不限制）")
+	migrateCmd.AddFlag("batch", "b", 500, "每次 SCAN 的数量")
+	migrateCmd.AddFlag("progress", "", "5s", "进度输出间隔")
+	migrateCmd.AddFlag("verify", "", false, "只校验目标端的数据与源端一致，不写入")
+	migrateCmd.AddFlag("json", "", false, "以 JSON 输出统计")
+
+	redisCmd.Command.AddCommand(pingCmd.Command, getCmd.Command, setCmd.Command, keysCmd.Command, slowlogCmd.Command, bigkeysCmd.Command, migrateCmd.Command)
+
+	redisGroup.AddCommand(redisCmd)
+	tool.AddGroupLogic(redisGroup)
+}
+
+// loadRedisConfig 读取连接配置：--addr 优先，其次是 redis.<conn>（配置文件路径或内联配置）
+func loadRedisConfig() (*redisxconfig.Config, error) {
+	return loadRedisConn(viper.GetString("conn"), viper.GetString("addr"))
+}
+
+// loadRedisConn 读取指定的连接配置，addr 不为空时直接连接单节点
+func loadRedisConn(conn, addr string) (*redisxconfig.Config, error) {
+	if addr != "" {
+		cfg := redisxconfig.DefaultConfig()
+		cfg.Single.Addr = addr
+		return cfg, nil
+	}
+
+	key := "redis." + conn
+	if conn != "default" && !viper.IsSet(key) {
+		return nil, fmt.Errorf("连接配置不存在: %s", key)
+	}
+	return redisxconfig.LoadFromViper(viper.GetViper(), key)
+}
+
+// withRedis 连接 Redis 并执行操作（multi-master 模式没有统一的底层客户端，不支持）
+func withRedis(cmd *cobra.Command, fn func(ctx context.Context, c client.Client, rdb redis.UniversalClient) error) error {
+	cfg, err := loadRedisConfig()
+	if err != nil {
+		return err
+	}
+
+	c, err := client.NewClient(cfg)
+	if err != nil {
+		return fmt.Errorf("连接 Redis 失败: %w", err)
+	}
+	defer c.Close()
+
+	rdb, ok := c.GetClient().(redis.UniversalClient)
+	if !ok {
+		return fmt.Errorf("不支持的部署模式: %s", cfg.Mode)
+	}
+	return fn(cmd.Context(), c, rdb)
+}
+
+// forEachRedisNode 在每个节点上执行（集群模式为所有主节点）
+func forEachRedisNode(ctx context.Context, rdb redis.UniversalClient, fn func(ctx context.Context, node *redis.Client) error) error {
+	switch c := rdb.(type) {
+	case *redis.ClusterClient:
+		return c.ForEachMaster(ctx, fn)
+	case *redis.Client:
+		return fn(ctx, c)
+	default:
+		return fmt.Errorf("不支持的客户端类型: %T", rdb)
+	}
+}
+
+// scanKeys 在每个节点上 SCAN 匹配的键，fn 返回 false 时停止
+func scanKeys(ctx context.Context, rdb redis.UniversalClient, pattern, keyType string, fn func(node *redis.Client, batch []string) bool) error {
+	var (
+		mu      sync.Mutex
+		stopped bool
+	)
+	return forEachRedisNode(ctx, rdb, func(ctx context.Context, node *redis.Client) error {
+		var cursor uint64
+		for {
+			var (
+				batch []string
+				err   error
+			)
+			if keyType != "" {
+				batch, cursor, err = node.ScanType(ctx, cursor, pattern, redisScanBatch, keyType).Result()
+			} else {
+				batch, cursor, err = node.Scan(ctx, cursor, pattern, redisScanBatch).Result()
+			}
+			if err != nil {
+				return fmt.Errorf("%s: SCAN 失败: %w", node.Options().Addr, err)
+			}
+
+			mu.Lock()
+			if !stopped && len(batch) > 0 {
+				stopped = !fn(node, batch)
+			}
+			done := stopped
+			mu.Unlock()
+
+			if done || cursor == 0 {
+				return nil
+			}
+		}
+	})
+}
+
+// readRedisValue 按类型读取键的值
+func readRedisValue(ctx context.Context, rdb redis.UniversalClient, key string) (*redisValue, error) {
+	keyType, err := rdb.Type(ctx, key).Result()
+	if err != nil {
+		return nil, err
+	}
+	if keyType == "none" {
+		return nil, fmt.Errorf("键不存在: %s", key)
+	}
+
+	v := &redisValue{Key: key, Type: keyType, TTL: "-1"}
+	if ttl, err := rdb.TTL(ctx, key).Result(); err == nil && ttl > 0 {
+		v.TTL = ttl.String()
+	}
+
+	switch keyType {
+	case "string":
+		v.Value, err = rdb.Get(ctx, key).Result()
+	case "hash":
+		v.Value, err = rdb.HGetAll(ctx, key).Result()
+	case "list":
+		v.Value, err = rdb.LRange(ctx, key, 0, -1).Result()
+	case "set":
+		v.Value, err = rdb.SMembers(ctx, key).Result()
+	case "zset":
+		v.Value, err = rdb.ZRangeWithScores(ctx, key, 0, -1).Result()
+	default:
+		return nil, fmt.Errorf("暂不支持查看 %s 类型", keyType)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+// measureKeys 通过管道批量读取键的类型、内存占用和长度
+func measureKeys(ctx context.Context, node *redis.Client, keys []string, samples int) ([]bigKey, error) {
+	pipe := node.Pipeline()
+	typeCmds := make([]*redis.StatusCmd, len(keys))
+	memCmds := make([]*redis.IntCmd, len(keys))
+	for i, key := range keys {
+		typeCmds[i] = pipe.Type(ctx, key)
+		memCmds[i] = pipe.MemoryUsage(ctx, key, samples)
+	}
+	if _, err := pipe.Exec(ctx); err != nil && !errors.Is(err, redis.Nil) {
+		return nil, err
+	}
+
+	lenPipe := node.Pipeline()
+	lenCmds := make([]*redis.IntCmd, len(keys))
+	stats := make([]bigKey, 0, len(keys))
+	for i, key := range keys {
+		keyType := typeCmds[i].Val()
+		if keyType == "" || keyType == "none" {
+			continue // 扫描后被删除
+		}
+		stats = append(stats, bigKey{Key: key, Type: keyType, Bytes: memCmds[i].Val()})
+
+		switch keyType {
+		case "string":
+			lenCmds[i] = lenPipe.StrLen(ctx, key)
+		case "hash":
+			lenCmds[i] = lenPipe.HLen(ctx, key)
+		case "list":
+			lenCmds[i] = lenPipe.LLen(ctx, key)
+		case "set":
+			lenCmds[i] = lenPipe.SCard(ctx, key)
+		case "zset":
+			lenCmds[i] = lenPipe.ZCard(ctx, key)
+		case "stream":
+			lenCmds[i] = lenPipe.XLen(ctx, key)
+		}
+	}
+	if _, err := lenPipe.Exec(ctx); err != nil && !errors.Is(err, redis.Nil) {
+		return nil, err
+	}
+
+	n := 0
+	for i := range keys {
+		if typeCmds[i].Val() == "" || typeCmds[i].Val() == "none" {
+			continue
+		}
+		if lenCmds[i] != nil {
+			stats[n].Length = lenCmds[i].Val()
+		}
+		n++
+	}
+	return stats, nil
+}
+
+// summarizeKeys 按类型汇总键数量和内存占用
+func summarizeKeys(keys []bigKey) []keyTypeSummary {
+	byType := make(map[string]*keyTypeSummary)
+	for _, k := range keys {
+		s, ok := byType[k.Type]
+		if !ok {
+			s = &keyTypeSummary{Type: k.Type}
+			byType[k.Type] = s
+		}
+		s.Keys++
+		s.Bytes += k.Bytes
+	}
+
+	summary := make([]keyTypeSummary, 0, len(byType))
+	for _, s := range byType {
+		summary = append(summary, *s)
+	}
+	sort.Slice(summary, func(i, j int) bool { return summary[i].Bytes > summary[j].Bytes })
+	return summary
+}
@@ -0,0 +1,730 @@
+package commands
+
+import (
+	"bufio"
+	"bytes"
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"github.com/tedwangl/go-util/pkg/cobrax"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/agent"
+	"golang.org/x/crypto/ssh/knownhosts"
+)
+
+type (
+	// remoteHost 远程主机
+	remoteHost struct {
+		Name string // 命令行或主机组中的写法，用于输出前缀
+		User string
+		Host string
+		Port int
+	}
+
+	// remoteResult 单台主机的执行结果
+	remoteResult struct {
+		Host     string  `json:"host"`
+		ExitCode int     `json:"exit_code"`   // -1 表示未拿到退出码（连接失败、超时等）
+		Duration float64 `json:"duration_ms"` // 耗时（毫秒）
+		Stdout   string  `json:"stdout,omitempty"`
+		Stderr   string  `json:"stderr,omitempty"`
+		Bytes    int64   `json:"bytes,omitempty"` // copy 上传的字节数
+		Error    string  `json:"error,omitempty"`
+	}
+
+	// remoteTask 在一台主机上执行的任务
+	remoteTask func(ctx context.Context, client *ssh.Client, h remoteHost, r *remoteResult) error
+
+	// prefixWriter 按行输出，每行加上主机前缀（多台主机共用一个锁，避免行交错）
+	prefixWriter struct {
+		mu     *sync.Mutex
+		out    io.Writer
+		prefix string
+		buf    []byte
+	}
+)
+
+// defaultKeyFiles 未指定私钥时尝试的文件（~/.ssh 下）
+var defaultKeyFiles = []string{"id_ed25519", "id_ecdsa", "id_rsa"}
+
+// RegisterRemoteCommands 注册远程执行相关命令
+//
+// 主机写法为 [user@]host[:port]，主机组在全局配置 remote.groups.<name> 中定义：
+//
+//	remote:
+//	  user: deploy
+//	  groups:
+//	    web: [web1, web2, deploy@10.0.0.3:2222]
+//
+// 认证依次使用 ssh-agent、私钥（--key 或 ~/.ssh/id_*）和 remote.password，
+// 主机密钥按 ~/.ssh/known_hosts 校验
+func RegisterRemoteCommands(tool *cobrax.Tool) {
+	remoteGroup := cobrax.NewCommandGroup("remote")
+
+	remoteCmd := tool.NewCommand(
+		"remote",
+		"远程执行工具",
+		"通过 SSH 在多台主机上并发执行命令、上传文件（主机组见 remote.groups.<name>）",
+		nil,
+	)
+	remoteCmd.Command.GroupID = "remote"
+	remoteCmd.AddPersistentFlag("hosts", "H", "", "主机列表，逗号分隔（[user@]host[:port]）")
+	remoteCmd.AddPersistentFlag("group", "g", []string{}, "主机组（remote.groups.<name>），可指定多个")
+	remoteCmd.AddPersistentFlag("user", "u", "", "默认用户（默认 remote.user 或当前用户）")
+	remoteCmd.AddPersistentFlag("port", "P", 0, "默认端口（默认 remote.port 或 22）")
+	remoteCmd.AddPersistentFlag("key", "i", "", "私钥文件（默认 remote.key 或 ~/.ssh/id_*）")
+	remoteCmd.AddPersistentFlag("parallel", "n", 10, "并发主机数")
+	remoteCmd.AddPersistentFlag("connect-timeout", "", "10s", "连接超时时间")
+	remoteCmd.AddPersistentFlag("timeout", "t", "0s", "单台主机的执行超时时间（0 表示不限制）")
+	remoteCmd.AddPersistentFlag("insecure", "", false, "不校验主机密钥（known_hosts）")
+	remoteCmd.AddPersistentFlag("json", "", false, "以 JSON 输出执行结果")
+
+	// remote run - 执行命令
+	runCmd := tool.NewCommand(
+		"run",
+		"在多台主机上执行命令",
+		"并发执行命令，输出按行加上 [主机] 前缀，结束后输出汇总；任一主机失败时返回错误",
+		cobrax.CmdRunnerFunc(func(cmd *cobra.Command, args []string) error {
+			if len(args) == 0 {
+				return fmt.Errorf("用法: devtool remote run --hosts host1,host2 \"<命令>\"")
+			}
+
+			command := strings.Join(args, " ")
+			stream := !viper.GetBool("json")
+			var mu sync.Mutex
+			return runRemote(cmd, func(ctx context.Context, client *ssh.Client, h remoteHost, r *remoteResult) error {
+				return remoteExec(client, h, command, r, stream, &mu)
+			})
+		}),
+	)
+	runCmd.AddExample("在 web 组上查看磁盘", "devtool remote run -g web \"df -h /\"")
+	runCmd.AddExample("指定主机并以 JSON 输出", "devtool remote run -H root@10.0.0.1,10.0.0.2:2222 --json uptime")
+
+	// remote copy - 上传文件
+	copyCmd := tool.NewCommand(
+		"copy",
+		"上传文件到多台主机",
+		"通过 scp 协议上传本地文件或目录（目录递归上传），多个本地路径时远程路径必须是目录",
+		cobrax.CmdRunnerFunc(func(cmd *cobra.Command, args []string) error {
+			if len(args) < 2 {
+				return fmt.Errorf("用法: devtool remote copy <本地路径>... <远程路径>")
+			}
+
+			sources, target := args[:len(args)-1], args[len(args)-1]
+			for _, src := range sources {
+				if _, err := os.Stat(src); err != nil {
+					return err
+				}
+			}
+			return runRemote(cmd, func(ctx context.Context, client *ssh.Client, h remoteHost, r *remoteResult) error {
+				return scpUpload(client, sources, target, r)
+			})
+		}),
+	)
+	copyCmd.AddExample("上传配置到 web 组", "devtool remote copy -g web ./nginx.conf /etc/nginx/nginx.conf")
+	copyCmd.AddExample("上传目录", "devtool remote copy -H web1,web2 ./dist ~/app/")
+
+	// remote groups - 列出主机组
+	groupsCmd := tool.NewCommand(
+		"groups",
+		"列出配置的主机组",
+		"列出全局配置 remote.groups 中的主机组",
+		cobrax.CmdRunnerFunc(func(cmd *cobra.Command, args []string) error {
+			groups := viper.GetStringMap("remote.groups")
+			names := make([]string, 0, len(groups))
+			for name := range groups {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+
+			if viper.GetBool("json") {
+				result := make(map[string][]string, len(names))
+				for _, name := range names {
+					result[name] = viper.GetStringSlice("remote.groups." + name)
+				}
+				return printJSON(result)
+			}
+
+			if len(names) == 0 {
+				fmt.Println("未配置主机组（remote.groups.<name>）")
+				return nil
+			}
+			w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "GROUP\tHOSTS")
+			for _, name := range names {
+				fmt.Fprintf(w, "%s\t%s\n", name, strings.Join(viper.GetStringSlice("remote.groups."+name), ","))
+			}
+			return w.Flush()
+		}),
+	)
+
+	remoteCmd.AddCommand(runCmd, copyCmd, groupsCmd)
+
+	remoteGroup.AddCommand(remoteCmd)
+	tool.AddGroupLogic(remoteGroup)
+}
+
+// runRemote 解析主机和 SSH 配置，并发执行任务并输出汇总
+func runRemote(cmd *cobra.Command, task remoteTask) error {
+	hosts, err := remoteHosts()
+	if err != nil {
+		return err
+	}
+	config, cleanup, err := sshClientConfig()
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	if config.Timeout, err = time.ParseDuration(viper.GetString("connect-timeout")); err != nil {
+		return fmt.Errorf("无效的连接超时时间: %w", err)
+	}
+	timeout, err := time.ParseDuration(viper.GetString("timeout"))
+	if err != nil {
+		return fmt.Errorf("无效的超时时间: %w", err)
+	}
+
+	ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	results := make([]remoteResult, len(hosts))
+	sem := make(chan struct{}, max(viper.GetInt("parallel"), 1))
+	var wg sync.WaitGroup
+	for i, h := range hosts {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			ctx := ctx
+			if timeout > 0 {
+				var cancel context.CancelFunc
+				ctx, cancel = context.WithTimeout(ctx, timeout)
+				defer cancel()
+			}
+			results[i] = runOnHost(ctx, h, config, task)
+		}()
+	}
+	wg.Wait()
+
+	failed := 0
+	for _, r := range results {
+		if r.Error != "" {
+			failed++
+		}
+	}
+
+	if viper.GetBool("json") {
+		if err := printJSON(results); err != nil {
+			return err
+		}
+	} else {
+		if err := printRemoteSummary(results); err != nil {
+			return err
+		}
+		fmt.Printf("完成: %d 台成功，%d 台失败\n", len(results)-failed, failed)
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d 台主机执行失败", failed)
+	}
+	return nil
+}
+
+// runOnHost 连接主机并执行任务，ctx 结束时断开连接
+func runOnHost(ctx context.Context, h remoteHost, base *ssh.ClientConfig, task remoteTask) remoteResult {
+	start := time.Now()
+	r := remoteResult{Host: h.Name, ExitCode: -1}
+
+	err := func() error {
+		config := *base
+		config.User = h.User
+		client, err := dialSSH(ctx, h, &config)
+		if err != nil {
+			return err
+		}
+		defer client.Close()
+
+		stop := context.AfterFunc(ctx, func() {
+			client.Close()
+		})
+		defer stop()
+
+		return task(ctx, client, h, &r)
+	}()
+
+	switch {
+	case errors.Is(ctx.Err(), context.DeadlineExceeded):
+		r.Error = "执行超时"
+	case ctx.Err() != nil:
+		r.Error = "已中断"
+	case err != nil:
+		r.Error = err.Error()
+	}
+	r.Duration = float64(time.Since(start).Microseconds()) / 1000
+	return r
+}
+
+// dialSSH 建立 SSH 连接（握手也受 ctx 控制）
+func dialSSH(ctx context.Context, h remoteHost, config *ssh.ClientConfig) (*ssh.Client, error) {
+	addr := h.addr()
+	d := net.Dialer{Timeout: config.Timeout}
+	conn, err := d.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+
+	stop := context.AfterFunc(ctx, func() {
+		conn.Close()
+	})
+	defer stop()
+
+	if config.Timeout > 0 {
+		conn.SetDeadline(time.Now().Add(config.Timeout))
+	}
+	c, chans, reqs, err := ssh.NewClientConn(conn, addr, config)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	conn.SetDeadline(time.Time{})
+
+	return ssh.NewClient(c, chans, reqs), nil
+}
+
+// remoteExec 执行命令，stream 时按行输出到终端，否则保存到结果中
+func remoteExec(client *ssh.Client, h remoteHost, command string, r *remoteResult, stream bool, mu *sync.Mutex) error {
+	session, err := client.NewSession()
+	if err != nil {
+		return err
+	}
+	defer session.Close()
+
+	var stdout, stderr bytes.Buffer
+	if stream {
+		prefix := "[" + h.Name + "] "
+		out := &prefixWriter{mu: mu, out: os.Stdout, prefix: prefix}
+		errOut := &prefixWriter{mu: mu, out: os.Stderr, prefix: prefix}
+		defer out.Flush()
+		defer errOut.Flush()
+		session.Stdout, session.Stderr = out, errOut
+	} else {
+		session.Stdout, session.Stderr = &stdout, &stderr
+	}
+
+	err = session.Run(command)
+	r.Stdout, r.Stderr = stdout.String(), stderr.String()
+
+	var exitErr *ssh.ExitError
+	switch {
+	case err == nil:
+		r.ExitCode = 0
+	case errors.As(err, &exitErr):
+		r.ExitCode = exitErr.ExitStatus()
+		return fmt.Errorf("退出码 %d", r.ExitCode)
+	}
+	return err
+}
+
+// scpUpload 通过 scp 协议（远程执行 scp -t）上传文件或目录
+func scpUpload(client *ssh.Client, sources []string, target string, r *remoteResult) error {
+	session, err := client.NewSession()
+	if err != nil {
+		return err
+	}
+	defer session.Close()
+
+	stdin, err := session.StdinPipe()
+	if err != nil {
+		return err
+	}
+	stdout, err := session.StdoutPipe()
+	if err != nil {
+		return err
+	}
+	var stderr bytes.Buffer
+	session.Stderr = &stderr
+
+	args := []string{"scp", "-t"}
+	for _, src := range sources {
+		if info, err := os.Stat(src); err == nil && info.IsDir() {
+			args = append(args, "-r")
+			break
+		}
+	}
+	if len(sources) > 1 {
+		args = append(args, "-d")
+	}
+	if err := session.Start(strings.Join(args, " ") + " " + remoteQuote(target)); err != nil {
+		return err
+	}
+
+	s := &scpSender{w: stdin, r: bufio.NewReader(stdout)}
+	err = s.ack()
+	for _, src := range sources {
+		if err != nil {
+			break
+		}
+		err = s.send(src)
+	}
+	r.Bytes = s.bytes
+	stdin.Close()
+
+	waitErr := session.Wait()
+	var exitErr *ssh.ExitError
+	if errors.As(waitErr, &exitErr) {
+		r.ExitCode = exitErr.ExitStatus()
+	} else if waitErr == nil {
+		r.ExitCode = 0
+	}
+	if err == nil {
+		err = waitErr
+	}
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return fmt.Errorf("%w: %s", err, msg)
+		}
+	}
+	return err
+}
+
+// scpSender scp 协议的发送端
+type scpSender struct {
+	w     io.Writer
+	r     *bufio.Reader
+	bytes int64
+}
+
+// ack 读取接收端的确认，非 0 时后面是错误信息（如 "scp: /x: No such file or directory"）
+func (s *scpSender) ack() error {
+	b, err := s.r.ReadByte()
+	if err != nil {
+		return fmt.Errorf("scp: %w", err)
+	}
+	if b == 0 {
+		return nil
+	}
+
+	msg, _ := s.r.ReadString('\n')
+	return errors.New(strings.TrimSpace(msg))
+}
+
+// send 发送文件或目录（跟随符号链接，跳过其他特殊文件）
+func (s *scpSender) send(path string) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case info.IsDir():
+		return s.sendDir(path, info)
+	case info.Mode().IsRegular():
+		return s.sendFile(path, info)
+	}
+	return nil
+}
+
+func (s *scpSender) sendFile(path string, info os.FileInfo) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	if _, err := fmt.Fprintf(s.w, "C%04o %d %s\n", info.Mode().Perm(), info.Size(), info.Name()); err != nil {
+		return err
+	}
+	if err := s.ack(); err != nil {
+		return err
+	}
+
+	n, err := io.CopyN(s.w, f, info.Size())
+	s.bytes += n
+	if err != nil {
+		return fmt.Errorf("上传 %s 失败: %w", path, err)
+	}
+	if _, err := s.w.Write([]byte{0}); err != nil {
+		return err
+	}
+	return s.ack()
+}
+
+func (s *scpSender) sendDir(path string, info os.FileInfo) error {
+	entries, err := os.ReadDir(path)
+	if err != nil {
+		return err
+	}
+
+	if _, err := fmt.Fprintf(s.w, "D%04o 0 %s\n", info.Mode().Perm(), info.Name()); err != nil {
+		return err
+	}
+	if err := s.ack(); err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		if err := s.send(filepath.Join(path, entry.Name())); err != nil {
+			return err
+		}
+	}
+	if _, err := io.WriteString(s.w, "E\n"); err != nil {
+		return err
+	}
+	return s.ack()
+}
+
+// remoteHosts 解析 --hosts 和 --group 指定的主机（去重，保持顺序）
+func remoteHosts() ([]remoteHost, error) {
+	var specs []string
+	for _, spec := range strings.Split(viper.GetString("hosts"), ",") {
+		if spec = strings.TrimSpace(spec); spec != "" {
+			specs = append(specs, spec)
+		}
+	}
+	for _, group := range viper.GetStringSlice("group") {
+		key := "remote.groups." + group
+		if !viper.IsSet(key) {
+			return nil, fmt.Errorf("主机组 %s 未配置（%s）", group, key)
+		}
+		specs = append(specs, viper.GetStringSlice(key)...)
+	}
+	if len(specs) == 0 {
+		return nil, fmt.Errorf("未指定主机，请使用 --hosts 或 --group")
+	}
+
+	user := cmp.Or(viper.GetString("user"), viper.GetString("remote.user"), os.Getenv("USER"))
+	port := cmp.Or(viper.GetInt("port"), viper.GetInt("remote.port"), 22)
+
+	seen := make(map[string]bool)
+	hosts := make([]remoteHost, 0, len(specs))
+	for _, spec := range specs {
+		h, err := parseRemoteHost(spec, user, port)
+		if err != nil {
+			return nil, err
+		}
+		if key := h.User + "@" + h.addr(); !seen[key] {
+			seen[key] = true
+			hosts = append(hosts, h)
+		}
+	}
+	return hosts, nil
+}
+
+// parseRemoteHost 解析 [user@]host[:port]，IPv6 地址带端口时写作 [::1]:22
+func parseRemoteHost(spec, user string, port int) (remoteHost, error) {
+	h := remoteHost{Name: spec, User: user, Port: port}
+
+	hostPort := spec
+	if i := strings.LastIndex(spec, "@"); i >= 0 {
+		h.User, hostPort = spec[:i], spec[i+1:]
+	}
+
+	h.Host = hostPort
+	if strings.HasPrefix(hostPort, "[") || strings.Count(hostPort, ":") == 1 {
+		host, p, err := net.SplitHostPort(hostPort)
+		if err != nil {
+			return h, fmt.Errorf("无效的主机 %s: %w", spec, err)
+		}
+		h.Host = host
+		if h.Port, err = strconv.Atoi(p); err != nil || h.Port <= 0 || h.Port > 65535 {
+			return h, fmt.Errorf("无效的主机 %s: 端口错误", spec)
+		}
+	}
+
+	if h.Host == "" || h.User == "" {
+		return h, fmt.Errorf("无效的主机 %s", spec)
+	}
+	return h, nil
+}
+
+func (h remoteHost) addr() string {
+	return net.JoinHostPort(h.Host, strconv.Itoa(h.Port))
+}
+
+// sshClientConfig 创建 SSH 配置（不含用户），cleanup 关闭 ssh-agent 连接
+func sshClientConfig() (*ssh.ClientConfig, func(), error) {
+	cleanup := func() {}
+	var auths []ssh.AuthMethod
+
+	if sock := os.Getenv("SSH_AUTH_SOCK"); sock != "" {
+		if conn, err := net.Dial("unix", sock); err == nil {
+			auths = append(auths, ssh.PublicKeysCallback(agent.NewClient(conn).Signers))
+			cleanup = func() { conn.Close() }
+		}
+	}
+
+	signers, err := sshSigners()
+	if err != nil {
+		cleanup()
+		return nil, nil, err
+	}
+	if len(signers) > 0 {
+		auths = append(auths, ssh.PublicKeys(signers...))
+	}
+
+	if password := viper.GetString("remote.password"); password != "" {
+		auths = append(auths, ssh.Password(password))
+	}
+
+	if len(auths) == 0 {
+		cleanup()
+		return nil, nil, fmt.Errorf("没有可用的认证方式（ssh-agent、私钥或 remote.password）")
+	}
+
+	hostKeyCallback, err := sshHostKeyCallback()
+	if err != nil {
+		cleanup()
+		return nil, nil, err
+	}
+
+	return &ssh.ClientConfig{
+		Auth:            auths,
+		HostKeyCallback: hostKeyCallback,
+	}, cleanup, nil
+}
+
+// sshSigners 加载私钥，指定的私钥加载失败时返回错误，默认私钥有密码保护时跳过
+func sshSigners() ([]ssh.Signer, error) {
+	passphrase := viper.GetString("remote.passphrase")
+	parse := func(path string) (ssh.Signer, error) {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		if passphrase != "" {
+			return ssh.ParsePrivateKeyWithPassphrase(data, []byte(passphrase))
+		}
+		return ssh.ParsePrivateKey(data)
+	}
+
+	if key := cmp.Or(viper.GetString("key"), viper.GetString("remote.key")); key != "" {
+		signer, err := parse(expandHome(key))
+		if err != nil {
+			var missing *ssh.PassphraseMissingError
+			if errors.As(err, &missing) {
+				return nil, fmt.Errorf("私钥 %s 有密码保护，请添加到 ssh-agent 或配置 remote.passphrase", key)
+			}
+			return nil, fmt.Errorf("加载私钥 %s 失败: %w", key, err)
+		}
+		return []ssh.Signer{signer}, nil
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return nil, nil
+	}
+	var signers []ssh.Signer
+	for _, name := range defaultKeyFiles {
+		if signer, err := parse(filepath.Join(home, ".ssh", name)); err == nil {
+			signers = append(signers, signer)
+		}
+	}
+	return signers, nil
+}
+
+// sshHostKeyCallback 按 known_hosts（默认 ~/.ssh/known_hosts，remote.known_hosts 指定）校验主机密钥
+func sshHostKeyCallback() (ssh.HostKeyCallback, error) {
+	if viper.GetBool("insecure") || viper.GetBool("remote.insecure") {
+		return ssh.InsecureIgnoreHostKey(), nil
+	}
+
+	path := viper.GetString("remote.known_hosts")
+	if path == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return nil, err
+		}
+		path = filepath.Join(home, ".ssh", "known_hosts")
+	}
+
+	callback, err := knownhosts.New(expandHome(path))
+	if err != nil {
+		return nil, fmt.Errorf("加载 known_hosts 失败（可使用 --insecure 跳过校验）: %w", err)
+	}
+	return callback, nil
+}
+
+// printRemoteSummary 输出执行汇总
+func printRemoteSummary(results []remoteResult) error {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "HOST\tSTATUS\tEXIT\tDURATION\tERROR")
+	for _, r := range results {
+		status := "ok"
+		if r.Error != "" {
+			status = "failed"
+		}
+		duration := (time.Duration(r.Duration*1000) * time.Microsecond).Round(time.Millisecond)
+		fmt.Fprintf(w, "%s\t%s\t%d\t%s\t%s\n", r.Host, status, r.ExitCode, duration, r.Error)
+	}
+	return w.Flush()
+}
+
+// remoteQuote 为远程 shell 转义路径，保留开头的 ~/ 以便展开
+func remoteQuote(path string) string {
+	prefix := ""
+	if rest, ok := strings.CutPrefix(path, "~/"); ok {
+		prefix, path = "~/", rest
+		if path == "" {
+			return prefix
+		}
+	}
+	return prefix + "'" + strings.ReplaceAll(path, "'", `'\''`) + "'"
+}
+
+// expandHome 展开路径开头的 ~/
+func expandHome(path string) string {
+	if rest, ok := strings.CutPrefix(path, "~/"); ok {
+		if home, err := os.UserHomeDir(); err == nil {
+			return filepath.Join(home, rest)
+		}
+	}
+	return path
+}
+
+func (w *prefixWriter) Write(p []byte) (int, error) {
+	w.buf = append(w.buf, p...)
+
+	lines := w.buf
+	for {
+		i := bytes.IndexByte(lines, '\n')
+		if i < 0 {
+			break
+		}
+		w.writeLine(lines[:i+1])
+		lines = lines[i+1:]
+	}
+	w.buf = append(w.buf[:0], lines...)
+
+	return len(p), nil
+}
+
+// Flush 输出最后不完整的一行
+func (w *prefixWriter) Flush() {
+	if len(w.buf) > 0 {
+		w.writeLine(append(w.buf, '\n'))
+		w.buf = w.buf[:0]
+	}
+}
+
+func (w *prefixWriter) writeLine(line []byte) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	fmt.Fprintf(w.out, "%s%s", w.prefix, line)
+}
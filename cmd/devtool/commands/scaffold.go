@@ -0,0 +1,111 @@
+package commands
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"text/template"
+)
+
+// goTemplates 项目脚手架模板，文件均以 .tmpl 结尾（避免 go.mod 和 .go 文件被当作源码处理）
+//
+//go:embed templates/go
+var goTemplates embed.FS
+
+// scaffoldData 模板渲染参数
+type scaffoldData struct {
+	Name      string // 项目名（目录名）
+	Module    string // 模块路径
+	GoVersion string // go.mod 中的 Go 版本
+	EnvPrefix string // cli 模板的环境变量前缀
+	TaskQueue string // worker 模板的任务队列
+}
+
+// scaffoldTemplates 可用的项目模板
+func scaffoldTemplates() []string {
+	entries, _ := goTemplates.ReadDir("templates/go")
+	names := make([]string, 0, len(entries))
+	for _, e := range entries {
+		if e.IsDir() {
+			names = append(names, e.Name())
+		}
+	}
+	return names
+}
+
+// newScaffoldData 根据项目名和模块路径生成渲染参数
+func newScaffoldData(name, module string) scaffoldData {
+	if module == "" {
+		module = name
+	}
+	return scaffoldData{
+		Name:      name,
+		Module:    module,
+		GoVersion: goVersion(),
+		EnvPrefix: strings.ToUpper(strings.NewReplacer("-", "_", ".", "_").Replace(name)),
+		TaskQueue: name + "-task-queue",
+	}
+}
+
+// goVersion 当前 Go 版本（如 1.24.6），开发版返回 1.24
+func goVersion() string {
+	v := strings.TrimPrefix(runtime.Version(), "go")
+	if v == "" || strings.ContainsAny(v, " +") || strings.HasPrefix(v, "devel") {
+		return "1.24"
+	}
+	return v
+}
+
+// renderScaffold 将模板渲染到 dir，返回生成的文件列表（相对路径）
+func renderScaffold(tmpl, dir string, data scaffoldData) ([]string, error) {
+	root := path.Join("templates/go", tmpl)
+	if _, err := fs.Stat(goTemplates, root); err != nil {
+		return nil, fmt.Errorf("未知模板: %s（可选: %s）", tmpl, strings.Join(scaffoldTemplates(), ", "))
+	}
+
+	if entries, err := os.ReadDir(dir); err == nil && len(entries) > 0 {
+		return nil, fmt.Errorf("目录已存在且不为空: %s", dir)
+	}
+
+	var files []string
+	err := fs.WalkDir(goTemplates, root, func(p string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+
+		rel := strings.TrimSuffix(strings.TrimPrefix(p, root+"/"), ".tmpl")
+		content, err := goTemplates.ReadFile(p)
+		if err != nil {
+			return err
+		}
+
+		t, err := template.New(rel).Option("missingkey=error").Parse(string(content))
+		if err != nil {
+			return fmt.Errorf("解析模板 %s 失败: %w", rel, err)
+		}
+		var buf bytes.Buffer
+		if err := t.Execute(&buf, data); err != nil {
+			return fmt.Errorf("渲染模板 %s 失败: %w", rel, err)
+		}
+
+		target := filepath.Join(dir, filepath.FromSlash(rel))
+		if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+			return err
+		}
+		if err := os.WriteFile(target, buf.Bytes(), 0644); err != nil {
+			return err
+		}
+		files = append(files, rel)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return files, nil
+}
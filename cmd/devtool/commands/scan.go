@@ -0,0 +1,262 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"text/tabwriter"
+	"time"
+)
+
+// 端口状态（与 nmap 一致：拒绝连接为 closed，超时为 filtered）
+const (
+	portOpen     = "open"
+	portClosed   = "closed"
+	portFiltered = "filtered"
+)
+
+// knownServices 常见端口对应的服务名
+var knownServices = map[int]string{
+	20:    "ftp-data",
+	21:    "ftp",
+	22:    "ssh",
+	23:    "telnet",
+	25:    "smtp",
+	53:    "domain",
+	80:    "http",
+	110:   "pop3",
+	111:   "rpcbind",
+	135:   "msrpc",
+	139:   "netbios-ssn",
+	143:   "imap",
+	389:   "ldap",
+	443:   "https",
+	445:   "microsoft-ds",
+	465:   "smtps",
+	587:   "submission",
+	631:   "ipp",
+	993:   "imaps",
+	995:   "pop3s",
+	1080:  "socks",
+	1433:  "mssql",
+	1521:  "oracle",
+	2049:  "nfs",
+	2181:  "zookeeper",
+	2375:  "docker",
+	2376:  "docker-tls",
+	2379:  "etcd",
+	3000:  "grafana",
+	3306:  "mysql",
+	3389:  "rdp",
+	4222:  "nats",
+	5000:  "upnp",
+	5432:  "postgresql",
+	5601:  "kibana",
+	5672:  "amqp",
+	5900:  "vnc",
+	6379:  "redis",
+	6443:  "kubernetes",
+	7233:  "temporal",
+	8000:  "http-alt",
+	8080:  "http-proxy",
+	8443:  "https-alt",
+	8888:  "http-alt",
+	9000:  "cslistener",
+	9090:  "prometheus",
+	9092:  "kafka",
+	9200:  "elasticsearch",
+	9300:  "elasticsearch",
+	11211: "memcached",
+	15672: "rabbitmq",
+	27017: "mongodb",
+}
+
+// scanResult 单个端口的探测结果
+type scanResult struct {
+	Host    string  `json:"host"`
+	Port    int     `json:"port"`
+	Service string  `json:"service,omitempty"`
+	State   string  `json:"state"`                // open, closed, filtered
+	Latency float64 `json:"latency_ms,omitempty"` // 建立连接耗时（毫秒）
+	Error   string  `json:"error,omitempty"`
+}
+
+// commonPorts 默认扫描的常见端口
+func commonPorts() []int {
+	ports := make([]int, 0, len(knownServices))
+	for port := range knownServices {
+		ports = append(ports, port)
+	}
+	sort.Ints(ports)
+	return ports
+}
+
+// serviceName 端口对应的服务名（未知时为空）
+func serviceName(port int) string {
+	return knownServices[port]
+}
+
+// parsePorts 解析端口列表，支持逗号分隔和范围，如 22,80,8000-8100
+func parsePorts(spec string) ([]int, error) {
+	seen := make(map[int]bool)
+	var ports []int
+	add := func(port int) {
+		if !seen[port] {
+			seen[port] = true
+			ports = append(ports, port)
+		}
+	}
+
+	for _, part := range strings.Split(spec, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		lo, hi, isRange := strings.Cut(part, "-")
+		start, err := parsePort(lo)
+		if err != nil {
+			return nil, err
+		}
+		end := start
+		if isRange {
+			if end, err = parsePort(hi); err != nil {
+				return nil, err
+			}
+			if end < start {
+				return nil, fmt.Errorf("无效的端口范围: %s", part)
+			}
+		}
+		for port := start; port <= end; port++ {
+			add(port)
+		}
+	}
+
+	if len(ports) == 0 {
+		return nil, fmt.Errorf("端口列表为空")
+	}
+	sort.Ints(ports)
+	return ports, nil
+}
+
+// parsePort 解析单个端口（支持服务名，如 http、ssh）
+func parsePort(s string) (int, error) {
+	s = strings.TrimSpace(s)
+	port, err := strconv.Atoi(s)
+	if err != nil {
+		if port, err = net.LookupPort("tcp", s); err != nil {
+			return 0, fmt.Errorf("无效的端口: %s", s)
+		}
+	}
+	if port < 1 || port > 65535 {
+		return 0, fmt.Errorf("端口超出范围: %d", port)
+	}
+	return port, nil
+}
+
+// probe 通过 TCP 连接探测端口是否开放
+func probe(ctx context.Context, host string, port int, timeout time.Duration) scanResult {
+	result := scanResult{Host: host, Port: port, Service: serviceName(port)}
+
+	dialer := net.Dialer{Timeout: timeout}
+	start := time.Now()
+	conn, err := dialer.DialContext(ctx, "tcp", net.JoinHostPort(host, strconv.Itoa(port)))
+	if err != nil {
+		result.State, result.Error = dialError(err)
+		return result
+	}
+	conn.Close()
+
+	result.State = portOpen
+	result.Latency = float64(time.Since(start).Microseconds()) / 1000
+	return result
+}
+
+// dialError 根据连接错误判断端口状态，并简化错误信息
+func dialError(err error) (string, string) {
+	var netErr net.Error
+	switch {
+	// Windows 上为 WSAECONNREFUSED，与 syscall.ECONNREFUSED 不相等
+	case errors.Is(err, syscall.ECONNREFUSED) || strings.Contains(err.Error(), "refused"):
+		return portClosed, "connection refused"
+	case errors.As(err, &netErr) && netErr.Timeout():
+		return portFiltered, "timeout"
+	default:
+		return portFiltered, err.Error()
+	}
+}
+
+// scanPorts 并发探测主机的端口，结果按端口排序
+func scanPorts(ctx context.Context, host string, ports []int, timeout time.Duration, workers int) []scanResult {
+	if workers <= 0 {
+		workers = 1
+	}
+	workers = min(workers, len(ports))
+
+	jobs := make(chan int)
+	results := make([]scanResult, 0, len(ports))
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for port := range jobs {
+				r := probe(ctx, host, port, timeout)
+				mu.Lock()
+				results = append(results, r)
+				mu.Unlock()
+			}
+		}()
+	}
+
+send:
+	for _, port := range ports {
+		select {
+		case jobs <- port:
+		case <-ctx.Done():
+			break send
+		}
+	}
+	close(jobs)
+	wg.Wait()
+
+	sort.Slice(results, func(i, j int) bool { return results[i].Port < results[j].Port })
+	return results
+}
+
+// printScanResults 输出扫描结果（默认只输出开放端口，all 为 true 时输出全部）
+func printScanResults(results []scanResult, asJSON, all bool) error {
+	if !all {
+		open := make([]scanResult, 0)
+		for _, r := range results {
+			if r.State == portOpen {
+				open = append(open, r)
+			}
+		}
+		results = open
+	}
+
+	if asJSON {
+		return printJSON(results)
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "PORT\tSTATE\tSERVICE\tLATENCY")
+	for _, r := range results {
+		latency := fmt.Sprintf("%.1fms", r.Latency)
+		if r.State != portOpen {
+			latency = r.Error
+		}
+		fmt.Fprintf(w, "%d/tcp\t%s\t%s\t%s\n", r.Port, r.State, r.Service, latency)
+	}
+	return w.Flush()
+}
@@ -2,11 +2,15 @@ package commands
 
 import (
 	"fmt"
+	"io"
+	"net"
 	"os"
 	"os/exec"
 	"os/signal"
 	"path/filepath"
+	"sort"
 	"strconv"
+	"strings"
 	"syscall"
 	"time"
 
@@ -14,11 +18,13 @@ import (
 	"github.com/spf13/viper"
 	"github.com/tedwangl/go-util/pkg/cobrax"
 	"github.com/tedwangl/go-util/pkg/daemon"
+	redisxconfig "github.com/tedwangl/go-util/pkg/redisx/config"
+	"github.com/tedwangl/go-util/pkg/utils/humanize"
 )
 
 var (
-	dbPath  = filepath.Join(os.Getenv("HOME"), ".devtool", "schedule.db")
-	pidFile = filepath.Join(os.Getenv("HOME"), ".devtool", "schedule.pid")
+	dbPath     = filepath.Join(os.Getenv("HOME"), ".devtool", "schedule.db")
+	socketPath = filepath.Join(os.Getenv("HOME"), ".devtool", "schedule.sock")
 )
 
 // RegisterScheduleCommands 注册定时任务相关命令
@@ -46,22 +52,21 @@ func RegisterScheduleCommands(tool *cobrax.Tool) {
 			daemonCmd.Stdout = nil
 			daemonCmd.Stderr = nil
 			daemonCmd.Stdin = nil
-			daemonCmd.SysProcAttr = &syscall.SysProcAttr{
-				Setsid: true,
-			}
+			detach(daemonCmd)
 
 			if err := daemonCmd.Start(); err != nil {
 				return fmt.Errorf("启动守护进程失败: %w", err)
 			}
 
-			// 保存 PID
-			pidData := []byte(strconv.Itoa(daemonCmd.Process.Pid))
-			if err := os.WriteFile(pidFile, pidData, 0644); err != nil {
-				return fmt.Errorf("保存 PID 失败: %w", err)
+			// 等待控制接口就绪
+			for i := 0; i < 50; i++ {
+				if isRunning() {
+					fmt.Printf("调度器已启动 (PID: %d)\n", daemonCmd.Process.Pid)
+					return nil
+				}
+				time.Sleep(100 * time.Millisecond)
 			}
-
-			fmt.Println("调度器已启动")
-			return nil
+			return fmt.Errorf("守护进程启动超时，请运行 'devtool daemon' 查看错误")
 		}),
 	)
 
@@ -71,25 +76,15 @@ func RegisterScheduleCommands(tool *cobrax.Tool) {
 		"停止调度守护进程",
 		"停止后台调度守护进程",
 		cobrax.CmdRunnerFunc(func(cmd *cobra.Command, args []string) error {
-			if !isRunning() {
-				return fmt.Errorf("调度器未运行")
-			}
-
-			pid, err := getPID()
+			client, err := controlClient()
 			if err != nil {
-				return err
-			}
-
-			process, err := os.FindProcess(pid)
-			if err != nil {
-				return fmt.Errorf("查找进程失败: %w", err)
+				return fmt.Errorf("调度器未运行")
 			}
 
-			if err := process.Signal(syscall.SIGTERM); err != nil {
-				return fmt.Errorf("停止进程失败: %w", err)
+			if err := client.Shutdown(); err != nil {
+				return fmt.Errorf("停止调度器失败: %w", err)
 			}
 
-			os.Remove(pidFile)
 			fmt.Println("调度器已停止")
 			return nil
 		}),
@@ -101,11 +96,41 @@ func RegisterScheduleCommands(tool *cobrax.Tool) {
 		"查看调度器状态",
 		"查看调度守护进程状态",
 		cobrax.CmdRunnerFunc(func(cmd *cobra.Command, args []string) error {
-			if isRunning() {
-				pid, _ := getPID()
-				fmt.Printf("调度器正在运行 (PID: %d)\n", pid)
-			} else {
+			client, err := controlClient()
+			if err != nil {
 				fmt.Println("调度器未运行")
+				return nil
+			}
+
+			status, err := client.Status()
+			if err != nil {
+				return err
+			}
+
+			fmt.Printf("调度器正在运行 (PID: %d)\n", status.PID)
+			fmt.Printf("启动时间: %s (%s)\n", status.StartedAt.Format("2006-01-02 15:04:05"), humanize.Ago(status.StartedAt))
+			if status.Node != "" {
+				role := "Follower（等待接管）"
+				if status.Leader {
+					role = "Leader"
+				}
+				fmt.Printf("节点: %s [%s]\n", status.Node, role)
+			}
+			fmt.Printf("定时任务: %d 个\n", len(status.Jobs))
+			sort.Slice(status.Jobs, func(i, j int) bool { return status.Jobs[i].Next.Before(status.Jobs[j].Next) })
+			for _, job := range status.Jobs {
+				fmt.Printf("  %s  下次执行: %s (%s)\n", job.Name, job.Next.Format("2006-01-02 15:04:05"), humanize.Ago(job.Next))
+				if job.LastRun.IsZero() {
+					continue
+				}
+				result := "成功"
+				if job.LastError != "" {
+					result = "失败: " + job.LastError
+				}
+				fmt.Printf("    上次执行: %s (%s, 耗时: %s) %s，共 %d 次，失败 %d 次\n",
+					job.LastRun.Format("2006-01-02 15:04:05"), humanize.Ago(job.LastRun),
+					job.LastDuration.Round(time.Millisecond),
+					result, job.Runs, job.Failures)
 			}
 			return nil
 		}),
@@ -117,123 +142,67 @@ func RegisterScheduleCommands(tool *cobrax.Tool) {
 		"守护进程（内部使用）",
 		"后台守护进程，不要直接调用",
 		cobrax.CmdRunnerFunc(func(cmd *cobra.Command, args []string) error {
-			d, err := daemon.NewDaemon(dbPath)
+			d, err := openDaemon()
 			if err != nil {
 				return err
 			}
 			defer d.Close()
 
-			if err := d.Start(); err != nil {
+			// 多节点选主（config.yaml 中的 schedule.ha，需配合 schedule.mysql_dsn 共享任务数据库）
+			if viper.IsSet("schedule.ha.redis") {
+				redisCfg, err := redisxconfig.LoadFromViper(viper.GetViper(), "schedule.ha.redis")
+				if err != nil {
+					return fmt.Errorf("加载 Redis 配置失败: %w", err)
+				}
+				if err := d.EnableLeader(&daemon.LeaderConfig{
+					Redis:  redisCfg,
+					Key:    viper.GetString("schedule.ha.key"),
+					TTL:    viper.GetDuration("schedule.ha.ttl"),
+					NodeID: viper.GetInt64("schedule.ha.node_id"),
+				}); err != nil {
+					return err
+				}
+			}
+
+			// 全局通知配置（config.yaml 中的 schedule.notify）
+			if viper.IsSet("schedule.notify") {
+				var notify daemon.NotifyConfig
+				if err := viper.UnmarshalKey("schedule.notify", &notify); err != nil {
+					return fmt.Errorf("解析通知配置失败: %w", err)
+				}
+				d.SetNotify(&notify)
+			}
+
+			// 控制接口（CLI 通过 unix socket 添加/删除/执行任务），先监听以保证只有一个守护进程
+			server, err := daemon.NewControlServer(d, socketPath)
+			if err != nil {
 				return err
 			}
+			defer server.Close()
+
+			if err := d.Start(); err != nil {
+				fmt.Printf("部分任务注册失败: %v\n", err)
+			}
+
+			go func() {
+				if err := server.Serve(); err != nil {
+					fmt.Printf("控制接口异常: %v\n", err)
+				}
+			}()
 
 			fmt.Println("调度器守护进程已启动")
 
 			// 监听信号
 			sigChan := make(chan os.Signal, 1)
-			signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM, syscall.SIGHUP, syscall.SIGUSR1, syscall.SIGUSR2)
-
-			// 记录当前调度器中的任务（用于检测删除）
-			currentTasks := make(map[string]bool)
-			for _, job := range d.GetScheduler().ListJobs() {
-				currentTasks[job.Name] = true
-			}
-
-			// 同步任务的函数（信号和定时器共用）
-			syncTasks := func() {
-				var tasks []daemon.Task
-				if err := d.DB.Where("enabled = ? AND completed = ? AND schedule != ''", true, false).Find(&tasks).Error; err != nil {
-					fmt.Printf("查询任务失败: %v\n", err)
-					return
-				}
-
-				// 构建数据库任务集合
-				dbTasks := make(map[string]*daemon.Task)
-				for i := range tasks {
-					dbTasks[tasks[i].Name] = &tasks[i]
-				}
-
-				// 1. 添加新任务（数据库有但调度器没有）
-				for name, task := range dbTasks {
-					if !currentTasks[name] {
-						// 检查是否是特殊任务（@once 或 @delay）
-						if task.Schedule == "@once" || (len(task.Schedule) > 7 && task.Schedule[:7] == "@delay:") {
-							// 一次性任务或延迟任务：使用 goroutine 执行
-							fmt.Printf("添加一次性/延迟任务: %s\n", name)
-							go d.ExecuteOnceTask(task)
-							currentTasks[name] = true
-						} else {
-							// 普通定时任务：添加到调度器
-							fmt.Printf("添加定时任务: %s\n", name)
-							if err := d.AddJobToScheduler(task); err != nil {
-								fmt.Printf("添加失败: %v\n", err)
-							} else {
-								currentTasks[name] = true
-								fmt.Printf("任务 %s 已添加到调度器\n", name)
-							}
-						}
-					}
-				}
+			signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM)
 
-				// 2. 删除任务（调度器有但数据库没有，或任务已完成）
-				for taskName := range currentTasks {
-					task, exists := dbTasks[taskName]
-					if !exists || task.Completed {
-						fmt.Printf("删除任务: %s\n", taskName)
-						if err := d.RemoveJobFromScheduler(taskName); err != nil {
-							fmt.Printf("删除失败: %v\n", err)
-						} else {
-							delete(currentTasks, taskName)
-							fmt.Printf("任务 %s 已从调度器移除\n", taskName)
-						}
-					}
-				}
-			}
-
-			// 定期同步定时器（每 30 秒检查一次，兜底机制）
-			ticker := time.NewTicker(30 * time.Second)
-			defer ticker.Stop()
-
-			for {
-				select {
-				case <-ticker.C:
-					// 定期同步
-					fmt.Println("定期检查任务变化...")
-					syncTasks()
-
-				case sig := <-sigChan:
-					switch sig {
-					case syscall.SIGUSR1:
-						// 添加任务信号：立即同步
-						fmt.Println("收到添加任务信号，立即同步...")
-						syncTasks()
-
-					case syscall.SIGUSR2:
-						// 删除任务信号：立即同步
-						fmt.Println("收到删除任务信号，立即同步...")
-						syncTasks()
-
-					case syscall.SIGHUP:
-						// 重载所有任务
-						fmt.Println("收到重载信号，重新加载所有任务...")
-						if err := d.Reload(); err != nil {
-							fmt.Printf("重载失败: %v\n", err)
-						} else {
-							// 更新任务列表
-							currentTasks = make(map[string]bool)
-							for _, job := range d.GetScheduler().ListJobs() {
-								currentTasks[job.Name] = true
-							}
-							fmt.Println("任务重载成功")
-						}
-
-					case syscall.SIGINT, syscall.SIGTERM:
-						// 停止守护进程
-						fmt.Println("\n收到停止信号，正在关闭...")
-						return nil
-					}
-				}
+			select {
+			case <-sigChan:
+				fmt.Println("\n收到停止信号，正在关闭...")
+			case <-server.Done():
+				fmt.Println("收到停止请求，正在关闭...")
 			}
+			return nil
 		}),
 	)
 	daemonCmd.Command.Hidden = true // 隐藏此命令
@@ -244,7 +213,7 @@ func RegisterScheduleCommands(tool *cobrax.Tool) {
 		"列出所有定时任务",
 		"显示所有已配置的定时任务",
 		cobrax.CmdRunnerFunc(func(cmd *cobra.Command, args []string) error {
-			d, err := daemon.NewDaemon(dbPath)
+			d, err := openDaemon()
 			if err != nil {
 				return err
 			}
@@ -276,6 +245,39 @@ func RegisterScheduleCommands(tool *cobrax.Tool) {
 				fmt.Printf("%d. [%s] %s (ID: %d)\n", i+1, status, task.Name, task.ID)
 				fmt.Printf("   调度: %s\n", scheduleInfo)
 				fmt.Printf("   命令: %s\n", task.Command)
+				if task.Timeout > 0 || task.MaxRetries > 0 {
+					fmt.Printf("   超时: %s, 重试: %d 次\n", task.Timeout, task.MaxRetries)
+				}
+				if len(task.DependsOn) > 0 {
+					fmt.Printf("   依赖: %s\n", strings.Join(task.DependsOn, ", "))
+				}
+				if task.Timezone != "" {
+					fmt.Printf("   时区: %s\n", task.Timezone)
+				}
+				if task.SkipWeekends || len(task.SkipDates) > 0 {
+					skips := task.SkipDates
+					if task.SkipWeekends {
+						skips = append([]string{"周末"}, skips...)
+					}
+					fmt.Printf("   排除: %s\n", strings.Join(skips, ", "))
+				}
+				if task.RunAsUser != "" {
+					fmt.Printf("   用户: %s\n", task.RunAsUser)
+				}
+				if task.WorkDir != "" {
+					fmt.Printf("   目录: %s\n", task.WorkDir)
+				}
+				if limits := formatLimits(&task.TaskOptions); limits != "" {
+					fmt.Printf("   限制: %s\n", limits)
+				}
+				if env := formatEnv(&task.TaskOptions); env != "" {
+					fmt.Printf("   环境: %s\n", env)
+				}
+				if task.AllowConcurrent {
+					fmt.Printf("   重叠: 允许并发\n")
+				} else if task.Overlap == daemon.OverlapQueue {
+					fmt.Printf("   重叠: 排队\n")
+				}
 				fmt.Printf("   创建: %s\n", task.CreatedAt.Format("2006-01-02 15:04:05"))
 				if task.CompletedAt != nil {
 					fmt.Printf("   完成: %s\n", task.CompletedAt.Format("2006-01-02 15:04:05"))
@@ -293,20 +295,32 @@ func RegisterScheduleCommands(tool *cobrax.Tool) {
 		"添加新的定时任务、延迟任务或一次性任务",
 		cobrax.CmdRunnerFunc(func(cmd *cobra.Command, args []string) error {
 			if len(args) < 1 {
-				return fmt.Errorf("用法: devtool add <命令> [--schedule <cron> | --delay <时长> | --once]")
+				return fmt.Errorf("用法: devtool add <命令> [--schedule <cron> | --delay <时长> | --once] [--after <任务>]")
 			}
 
 			command := args[0]
 			schedule := viper.GetString("schedule")
 			delay := viper.GetString("delay")
 			once := viper.GetBool("once")
+			after := viper.GetStringSlice("after")
+
+			// --every 等价于 --schedule "@every <间隔>"
+			if every := viper.GetString("every"); every != "" {
+				if schedule != "" {
+					return fmt.Errorf("--schedule 和 --every 只能指定一个")
+				}
+				if _, err := time.ParseDuration(every); err != nil {
+					return fmt.Errorf("无效的间隔格式: %v（示例: 5m, 1h, 30s）", err)
+				}
+				schedule = "@every " + every
+			}
 
-			// 验证参数：必须指定 schedule、delay 或 once 之一
-			if schedule == "" && delay == "" && !once {
-				return fmt.Errorf("必须指定 --schedule、--delay 或 --once 之一")
+			// 验证参数：必须指定 schedule、delay 或 once 之一（只指定 after 时由依赖触发）
+			if schedule == "" && delay == "" && !once && len(after) == 0 {
+				return fmt.Errorf("必须指定 --schedule、--every、--delay、--once 或 --after 之一")
 			}
 			if (schedule != "" && delay != "") || (schedule != "" && once) || (delay != "" && once) {
-				return fmt.Errorf("--schedule、--delay 和 --once 只能指定一个")
+				return fmt.Errorf("--schedule/--every、--delay 和 --once 只能指定一个")
 			}
 
 			name := viper.GetString("name")
@@ -314,6 +328,11 @@ func RegisterScheduleCommands(tool *cobrax.Tool) {
 				name = fmt.Sprintf("task-%d", time.Now().Unix())
 			}
 
+			opts, err := taskOptions()
+			if err != nil {
+				return err
+			}
+
 			// 构建 schedule 字符串
 			var scheduleStr string
 			var runAt *time.Time
@@ -322,25 +341,42 @@ func RegisterScheduleCommands(tool *cobrax.Tool) {
 				now := time.Now()
 				runAt = &now
 			} else if delay != "" {
-				duration, err := time.ParseDuration(delay)
+				duration, err := humanize.ParseDuration(delay)
 				if err != nil {
-					return fmt.Errorf("无效的延迟时间格式: %v（示例: 5m, 1h, 30s）", err)
+					return fmt.Errorf("无效的延迟时间格式: %v（示例: 30s, 5m, 1h30m, 2d, 1w）", err)
 				}
 				scheduleStr = "@delay:" + delay
 				runAtTime := time.Now().Add(duration)
 				runAt = &runAtTime
-			} else {
+			} else if schedule != "" {
 				scheduleStr = schedule
+			} else {
+				scheduleStr = daemon.ScheduleAfter
 			}
 
-			d, err := daemon.NewDaemon(dbPath)
-			if err != nil {
-				return err
-			}
-			defer d.Close()
+			// 守护进程运行时由其写入并立即调度（cron 表达式错误等会直接返回）
+			client, clientErr := controlClient()
+			if clientErr == nil {
+				if _, err := client.AddTask(&daemon.AddTaskRequest{
+					Name:     name,
+					Command:  command,
+					Schedule: scheduleStr,
+					RunAt:    runAt,
 
-			if err := d.AddTaskWithRunAt(name, command, scheduleStr, runAt); err != nil {
-				return err
+					TaskOptions: opts,
+				}); err != nil {
+					return err
+				}
+			} else {
+				d, err := openDaemon()
+				if err != nil {
+					return err
+				}
+				defer d.Close()
+
+				if err := d.AddTaskWithOptions(name, command, scheduleStr, runAt, opts); err != nil {
+					return err
+				}
 			}
 
 			fmt.Printf("任务 %s 添加成功\n", name)
@@ -348,21 +384,27 @@ func RegisterScheduleCommands(tool *cobrax.Tool) {
 				fmt.Printf("类型: 一次性任务（立即执行）\n")
 			} else if delay != "" {
 				fmt.Printf("类型: 延迟任务（%s 后执行）\n", delay)
-				fmt.Printf("执行时间: %s\n", runAt.Format("2006-01-02 15:04:05"))
-			} else {
+				fmt.Printf("执行时间: %s (%s)\n", runAt.Format("2006-01-02 15:04:05"), humanize.Ago(*runAt))
+			} else if schedule != "" {
 				fmt.Printf("调度: %s\n", schedule)
+			} else {
+				fmt.Printf("类型: 依赖触发（%s 成功后执行）\n", strings.Join(after, ", "))
+			}
+			if schedule != "" && len(after) > 0 {
+				fmt.Printf("依赖: %s\n", strings.Join(after, ", "))
 			}
 			fmt.Printf("命令: %s\n", command)
+			if opts.Timeout > 0 {
+				fmt.Printf("超时: %s\n", opts.Timeout)
+			}
+			if opts.MaxRetries > 0 {
+				fmt.Printf("重试: 最多 %d 次（间隔 %s 起，每次翻倍）\n", opts.MaxRetries, opts.RetryBackoff)
+			}
+			if limits := formatLimits(&opts); limits != "" {
+				fmt.Printf("限制: %s\n", limits)
+			}
 
-			// 通知守护进程添加任务
-			if isRunning() {
-				pid, _ := getPID()
-				process, err := os.FindProcess(pid)
-				if err == nil {
-					process.Signal(syscall.SIGUSR1)
-					fmt.Println("已通知守护进程添加任务")
-				}
-			} else {
+			if clientErr != nil {
 				fmt.Println("\n提示: 使用 'devtool start' 启动调度器")
 			}
 
@@ -370,9 +412,34 @@ func RegisterScheduleCommands(tool *cobrax.Tool) {
 		}),
 	)
 	addCmd.AddFlag("name", "n", "", "任务名称")
-	addCmd.AddFlag("schedule", "s", "", "cron 表达式（定时任务）")
-	addCmd.AddFlag("delay", "", "", "延迟时间（如: 5m, 1h, 30s）")
+	addCmd.AddFlag("schedule", "s", "", "cron 表达式（定时任务，秒级 6 字段，支持 CRON_TZ= 前缀）")
+	addCmd.AddFlag("every", "", "", "固定间隔执行（如: 5m, 1h）")
+	addCmd.AddFlag("tz", "", "", "cron 表达式的时区（如: Asia/Shanghai）")
+	addCmd.AddFlag("skip-weekends", "", false, "周末不执行")
+	addCmd.AddFlag("skip-dates", "", []string{}, "不执行的日期（如节假日，格式: 2025-10-01）")
+	addCmd.AddFlag("delay", "", "", "延迟时间（如: 30s, 5m, 1h30m, 2d, 1w）")
 	addCmd.AddFlag("once", "o", false, "立即执行一次")
+	addCmd.AddFlag("timeout", "t", "", "单次执行超时（如: 30s, 10m）")
+	addCmd.AddFlag("retries", "r", 0, "失败后最大重试次数")
+	addCmd.AddFlag("backoff", "", "10s", "首次重试间隔（之后每次翻倍）")
+	addCmd.AddFlag("concurrent", "", false, "允许上次未结束时再次执行")
+	addCmd.AddFlag("overlap", "", "skip", "上次未结束时的处理: skip 跳过, queue 排队")
+	addCmd.AddFlag("after", "a", []string{}, "依赖的任务（最近一次执行成功后才执行，可重复指定）")
+	addCmd.AddFlag("window", "w", "24h", "依赖成功的有效期")
+	addCmd.AddFlag("env", "e", []string{}, "环境变量 KEY=VALUE（可重复指定）")
+	addCmd.AddFlag("secret", "", []string{}, "敏感环境变量 KEY=VALUE（列表和输出中隐藏）")
+	addCmd.AddFlag("workdir", "", "", "工作目录（绝对路径，默认为执行用户的 HOME）")
+	addCmd.AddFlag("user", "u", "", "执行用户（需要以 root 运行守护进程）")
+	addCmd.AddFlag("cpus", "", "", "CPU 上限（核数，如: 0.5, 2，需要 cgroup v2）")
+	addCmd.AddFlag("memory", "m", "", "内存上限（如: 512M, 2G，cgroup v2 不可用时使用 rlimit）")
+	addCmd.AddFlag("cpu-time", "", "", "CPU 时间上限（如: 30s, 10m，超出后进程被终止）")
+	addCmd.AddFlag("notify-webhook", "", "", "通知 Webhook 地址")
+	addCmd.AddFlag("notify-email", "", []string{}, "通知邮箱（使用全局配置的 SMTP 服务器）")
+	addCmd.AddFlag("notify-exec", "", "", "通知命令（事件通过 TASK_* 环境变量传入）")
+	addCmd.AddFlag("notify-on", "", []string{}, "通知事件: failure, recovery, missed（默认 failure, recovery）")
+	addCmd.AddExample("每天凌晨 2 点备份", `devtool add "backup.sh" -n backup -s "0 0 2 * * *"`)
+	addCmd.AddExample("每 5 分钟执行一次，失败重试 3 次", `devtool add "sync.sh" -n sync --every 5m -r 3`)
+	addCmd.AddExample("备份成功后执行清理", `devtool add "clean.sh" -n clean -s "0 30 2 * * *" --after backup`)
 
 	// schedule remove - 删除任务
 	removeCmd := tool.NewCommand(
@@ -385,7 +452,17 @@ func RegisterScheduleCommands(tool *cobrax.Tool) {
 			}
 
 			name := args[0]
-			d, err := daemon.NewDaemon(dbPath)
+
+			// 守护进程运行时由其移出调度并删除
+			if client, err := controlClient(); err == nil {
+				if err := client.RemoveTask(name); err != nil {
+					return err
+				}
+				fmt.Printf("任务 %s 已删除\n", name)
+				return nil
+			}
+
+			d, err := openDaemon()
 			if err != nil {
 				return err
 			}
@@ -396,26 +473,93 @@ func RegisterScheduleCommands(tool *cobrax.Tool) {
 			}
 
 			fmt.Printf("任务 %s 已从数据库删除\n", name)
+			return nil
+		}),
+	)
 
-			// 通知守护进程移除任务
-			if isRunning() {
-				pid, _ := getPID()
-				process, err := os.FindProcess(pid)
-				if err == nil {
-					process.Signal(syscall.SIGUSR2)
-					fmt.Println("已通知守护进程移除任务")
+	// schedule run - 立即执行
+	runCmd := tool.NewCommand(
+		"run",
+		"立即执行任务",
+		"由守护进程立即执行一次指定任务并记录日志（不影响定时调度，需要调度器运行）\n"+
+			"默认等待执行结束并输出结果，--follow 实时输出命令的输出，--detach 不等待",
+		cobrax.CmdRunnerFunc(func(cmd *cobra.Command, args []string) error {
+			if len(args) == 0 {
+				return fmt.Errorf("请指定要执行的任务名称")
+			}
+			name := args[0]
+			if viper.GetBool("detach") && viper.GetBool("follow") {
+				return fmt.Errorf("--detach 和 --follow 不能同时使用")
+			}
+
+			client, err := controlClient()
+			if err != nil {
+				return fmt.Errorf("调度器未运行，请先使用 'devtool start' 启动")
+			}
+
+			if viper.GetBool("detach") {
+				if err := client.RunNow(name); err != nil {
+					return err
 				}
+				fmt.Printf("任务 %s 已开始执行，使用 'devtool logs %s' 查看结果\n", name, name)
+				return nil
 			}
 
+			var stdout, stderr io.Writer
+			if viper.GetBool("follow") {
+				stdout, stderr = os.Stdout, os.Stderr
+			}
+			fmt.Fprintf(os.Stderr, "任务 %s 开始执行...\n", name)
+			log, err := client.RunAndWait(name, stdout, stderr)
+			if log == nil {
+				return err
+			}
+
+			duration := ""
+			if log.EndTime != nil {
+				duration = log.EndTime.Sub(log.StartTime).Round(time.Millisecond).String()
+			}
+			fmt.Fprintf(os.Stderr, "任务 %s 执行结束: %s (退出码: %d, 耗时: %s)\n", name, log.Status, log.ExitCode, duration)
+			if log.Killed != "" {
+				fmt.Fprintf(os.Stderr, "终止原因: %s\n", log.Killed)
+			}
+			if err != nil {
+				if !viper.GetBool("follow") && log.Stderr != "" {
+					fmt.Fprintf(os.Stderr, "stderr:\n%s\n", indentOutput(log.Stderr))
+				}
+				return fmt.Errorf("任务 %s 执行失败: %w", name, err)
+			}
 			return nil
 		}),
 	)
+	runCmd.AddFlag("follow", "f", false, "实时输出命令的标准输出和标准错误")
+	runCmd.AddFlag("detach", "", false, "不等待执行结束")
+
+	// schedule reload - 重新加载
+	reloadCmd := tool.NewCommand(
+		"reload",
+		"重新加载任务",
+		"守护进程重新从数据库加载所有任务",
+		cobrax.CmdRunnerFunc(func(cmd *cobra.Command, args []string) error {
+			client, err := controlClient()
+			if err != nil {
+				return fmt.Errorf("调度器未运行")
+			}
+
+			if err := client.Reload(); err != nil {
+				return err
+			}
 
-	// schedule logs - 查看日志（只显示状态）
+			fmt.Println("任务重载成功")
+			return nil
+		}),
+	)
+
+	// schedule logs - 查看日志（--output 显示命令输出）
 	logsCmd := tool.NewCommand(
 		"logs",
 		"查看任务执行日志",
-		"查看任务执行历史记录（只显示状态）",
+		"查看任务执行历史记录，--output 同时显示捕获的标准输出和标准错误；logs tail、logs stats 查看和统计 zapx 日志",
 		cobrax.CmdRunnerFunc(func(cmd *cobra.Command, args []string) error {
 			taskName := ""
 			if len(args) > 0 {
@@ -426,8 +570,9 @@ func RegisterScheduleCommands(tool *cobrax.Tool) {
 			if limit == 0 {
 				limit = 20
 			}
+			showOutput := viper.GetBool("output")
 
-			daemon, err := daemon.NewDaemon(dbPath)
+			daemon, err := openDaemon()
 			if err != nil {
 				return err
 			}
@@ -451,17 +596,63 @@ func RegisterScheduleCommands(tool *cobrax.Tool) {
 					duration = fmt.Sprintf(" (耗时: %s)", log.EndTime.Sub(log.StartTime).Round(time.Millisecond))
 				}
 
-				fmt.Printf("[%s] %s - %s%s\n",
+				fmt.Printf("[%s] %s - %s (退出码: %d, PID: %d)%s\n",
 					log.StartTime.Format("2006-01-02 15:04:05"),
 					log.TaskName,
 					log.Status,
+					log.ExitCode,
+					log.PID,
 					duration,
 				)
+				if log.Killed != "" {
+					fmt.Printf("  终止原因: %s\n", log.Killed)
+				}
+				if log.Limits != "" {
+					fmt.Printf("  资源限制: %s\n", log.Limits)
+				}
+
+				if showOutput {
+					if log.Stdout != "" {
+						fmt.Printf("  stdout:\n%s\n", indentOutput(log.Stdout))
+					}
+					if log.Stderr != "" {
+						fmt.Printf("  stderr:\n%s\n", indentOutput(log.Stderr))
+					}
+				}
 			}
 			return nil
 		}),
 	)
 	logsCmd.AddFlag("limit", "l", 20, "显示条数")
+	logsCmd.AddFlag("output", "o", false, "显示命令输出")
+
+	// schedule graph - 显示依赖树
+	graphCmd := tool.NewCommand(
+		"graph",
+		"显示任务依赖树",
+		"以树形显示任务之间的依赖关系（子节点在父节点成功后执行）",
+		cobrax.CmdRunnerFunc(func(cmd *cobra.Command, args []string) error {
+			d, err := openDaemon()
+			if err != nil {
+				return err
+			}
+			defer d.Close()
+
+			tasks, err := d.ListTasks()
+			if err != nil {
+				return err
+			}
+			if len(tasks) == 0 {
+				fmt.Println("暂无定时任务")
+				return nil
+			}
+
+			for _, node := range daemon.DependencyTree(tasks) {
+				printTaskNode(node, "", "")
+			}
+			return nil
+		}),
+	)
 
 	// schedule clean - 清理已完成任务
 	cleanCmd := tool.NewCommand(
@@ -469,7 +660,7 @@ func RegisterScheduleCommands(tool *cobrax.Tool) {
 		"清理已完成任务",
 		"删除所有已完成的一次性/延迟任务记录",
 		cobrax.CmdRunnerFunc(func(cmd *cobra.Command, args []string) error {
-			d, err := daemon.NewDaemon(dbPath)
+			d, err := openDaemon()
 			if err != nil {
 				return err
 			}
@@ -486,38 +677,234 @@ func RegisterScheduleCommands(tool *cobrax.Tool) {
 		}),
 	)
 
-	scheduleGroup.AddCommand(startCmd, stopCmd, statusCmd, listCmd, addCmd, removeCmd, logsCmd, cleanCmd, daemonCmd)
+	// schedule web - 管理页面
+	webCmd := tool.NewCommand(
+		"web",
+		"任务管理页面",
+		"启动本地管理页面：任务列表、下次执行时间、执行日志和输出，支持立即执行、启用和禁用\n"+
+			"页面可以执行任务，默认只监听本机，监听其他地址时注意访问控制",
+		cobrax.CmdRunnerFunc(func(cmd *cobra.Command, args []string) error {
+			d, err := openDaemon()
+			if err != nil {
+				return err
+			}
+			defer d.Close()
+
+			addr := viper.GetString("addr")
+			server := daemon.NewWebServer(d, socketPath, addr)
+			errCh := make(chan error, 1)
+			go func() {
+				errCh <- server.ListenAndServe()
+			}()
+
+			host, port, _ := net.SplitHostPort(addr)
+			if host == "" || host == "0.0.0.0" || host == "::" {
+				host = "localhost"
+			}
+			fmt.Printf("管理页面: http://%s\n", net.JoinHostPort(host, port))
+			if !isRunning() {
+				fmt.Println("提示: 调度器未运行，立即执行不可用，使用 'devtool start' 启动")
+			}
+
+			sigChan := make(chan os.Signal, 1)
+			signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM)
+			select {
+			case err := <-errCh:
+				return err
+			case <-sigChan:
+				return server.Close()
+			}
+		}),
+	)
+	webCmd.AddFlag("addr", "", "127.0.0.1:8686", "监听地址（如: :8686 监听所有网卡）")
+
+	listCmd.SetAliases("ls")
+	removeCmd.SetAliases("rm")
+	removeCmd.RequireConfirmation("删除定时任务")
+	cleanCmd.RequireTypedConfirmation("删除所有已完成的一次性/延迟任务记录", "")
+
+	scheduleGroup.AddCommand(startCmd, stopCmd, statusCmd, listCmd, addCmd, removeCmd, runCmd, reloadCmd, logsCmd, graphCmd, webCmd, cleanCmd, daemonCmd)
 	tool.AddGroupLogic(scheduleGroup)
 }
 
 // isRunning 检查守护进程是否运行
 func isRunning() bool {
-	pid, err := getPID()
+	_, err := controlClient()
+	return err == nil
+}
+
+// controlClient 连接守护进程控制接口（守护进程未运行时返回错误）
+func controlClient() (*daemon.ControlClient, error) {
+	client, err := daemon.DialControl(socketPath)
 	if err != nil {
-		return false
+		return nil, err
+	}
+	if _, err := client.Status(); err != nil {
+		return nil, err
+	}
+	return client, nil
+}
+
+// indentOutput 输出每行缩进，便于与日志行区分
+func indentOutput(s string) string {
+	return "    " + strings.ReplaceAll(strings.TrimRight(s, "\n"), "\n", "\n    ")
+}
+
+// taskOptions 从命令行参数构建任务执行选项
+func taskOptions() (daemon.TaskOptions, error) {
+	opts := daemon.TaskOptions{
+		MaxRetries:      viper.GetInt("retries"),
+		AllowConcurrent: viper.GetBool("concurrent"),
+		Overlap:         viper.GetString("overlap"),
+		DependsOn:       viper.GetStringSlice("after"),
 	}
 
-	process, err := os.FindProcess(pid)
+	if timeout := viper.GetString("timeout"); timeout != "" {
+		d, err := time.ParseDuration(timeout)
+		if err != nil {
+			return opts, fmt.Errorf("无效的超时时间格式: %v（示例: 30s, 10m）", err)
+		}
+		opts.Timeout = d
+	}
+	if opts.MaxRetries > 0 {
+		d, err := time.ParseDuration(viper.GetString("backoff"))
+		if err != nil {
+			return opts, fmt.Errorf("无效的重试间隔格式: %v（示例: 10s, 1m）", err)
+		}
+		opts.RetryBackoff = d
+	}
+	if len(opts.DependsOn) > 0 {
+		d, err := time.ParseDuration(viper.GetString("window"))
+		if err != nil {
+			return opts, fmt.Errorf("无效的依赖有效期格式: %v（示例: 1h, 24h）", err)
+		}
+		opts.DependsWindow = d
+	}
+
+	env, err := parseEnv(viper.GetStringSlice("env"))
+	if err != nil {
+		return opts, err
+	}
+	secrets, err := parseEnv(viper.GetStringSlice("secret"))
 	if err != nil {
-		return false
+		return opts, err
+	}
+	opts.Env = env
+	opts.SecretEnv = secrets
+	opts.Timezone = viper.GetString("tz")
+	opts.SkipWeekends = viper.GetBool("skip-weekends")
+	opts.SkipDates = viper.GetStringSlice("skip-dates")
+	opts.WorkDir = viper.GetString("workdir")
+	opts.RunAsUser = viper.GetString("user")
+
+	if cpus := viper.GetString("cpus"); cpus != "" {
+		v, err := strconv.ParseFloat(cpus, 64)
+		if err != nil || v <= 0 {
+			return opts, fmt.Errorf("无效的 CPU 上限: %s（示例: 0.5, 2）", cpus)
+		}
+		opts.CPULimit = v
+	}
+	if memory := viper.GetString("memory"); memory != "" {
+		v, err := humanize.ParseBytes(memory)
+		if err != nil {
+			return opts, fmt.Errorf("无效的内存上限: %v（示例: 512M, 2G）", err)
+		}
+		opts.MemoryLimit = int64(v)
+	}
+	if cpuTime := viper.GetString("cpu-time"); cpuTime != "" {
+		d, err := time.ParseDuration(cpuTime)
+		if err != nil {
+			return opts, fmt.Errorf("无效的 CPU 时间格式: %v（示例: 30s, 10m）", err)
+		}
+		opts.CPUTime = d
 	}
 
-	// 发送信号 0 检查进程是否存在
-	err = process.Signal(syscall.Signal(0))
-	return err == nil
+	notify := &daemon.NotifyConfig{On: viper.GetStringSlice("notify-on")}
+	if url := viper.GetString("notify-webhook"); url != "" {
+		notify.Webhook = &daemon.WebhookConfig{URL: url}
+	}
+	if to := viper.GetStringSlice("notify-email"); len(to) > 0 {
+		notify.Email = &daemon.EmailConfig{To: to}
+	}
+	if command := viper.GetString("notify-exec"); command != "" {
+		notify.Exec = &daemon.ExecConfig{Command: command}
+	}
+	if notify.Webhook != nil || notify.Email != nil || notify.Exec != nil {
+		opts.Notify = notify
+	}
+
+	return opts, opts.Validate()
 }
 
-// getPID 获取守护进程 PID
-func getPID() (int, error) {
-	data, err := os.ReadFile(pidFile)
-	if err != nil {
-		return 0, err
+// printTaskNode 打印依赖树节点
+func printTaskNode(node *daemon.TaskNode, prefix, childPrefix string) {
+	task := node.Task
+	info := task.Schedule
+	if task.Completed {
+		info += ", 已完成"
+	} else if !task.Enabled {
+		info += ", 已禁用"
 	}
+	fmt.Printf("%s%s [%s]\n", prefix, task.Name, info)
+
+	for i, child := range node.Children {
+		if i == len(node.Children)-1 {
+			printTaskNode(child, childPrefix+"└── ", childPrefix+"    ")
+		} else {
+			printTaskNode(child, childPrefix+"├── ", childPrefix+"│   ")
+		}
+	}
+}
 
-	pid, err := strconv.Atoi(string(data))
-	if err != nil {
-		return 0, err
+// parseEnv 解析 KEY=VALUE 列表
+func parseEnv(pairs []string) (map[string]string, error) {
+	if len(pairs) == 0 {
+		return nil, nil
 	}
 
-	return pid, nil
+	env := make(map[string]string, len(pairs))
+	for _, pair := range pairs {
+		key, value, ok := strings.Cut(pair, "=")
+		if !ok || key == "" {
+			return nil, fmt.Errorf("无效的环境变量格式: %s（示例: KEY=VALUE）", pair)
+		}
+		env[key] = value
+	}
+	return env, nil
+}
+
+// formatEnv 格式化环境变量，敏感变量的值显示为 ******
+func formatEnv(opts *daemon.TaskOptions) string {
+	var pairs []string
+	for k, v := range opts.Env {
+		pairs = append(pairs, k+"="+v)
+	}
+	for k := range opts.SecretEnv {
+		pairs = append(pairs, k+"=******")
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, " ")
+}
+
+// formatLimits 资源限制的显示文本
+func formatLimits(opts *daemon.TaskOptions) string {
+	var parts []string
+	if opts.CPULimit > 0 {
+		parts = append(parts, fmt.Sprintf("CPU %g 核", opts.CPULimit))
+	}
+	if opts.MemoryLimit > 0 {
+		parts = append(parts, "内存 "+humanize.Bytes(uint64(opts.MemoryLimit)))
+	}
+	if opts.CPUTime > 0 {
+		parts = append(parts, "CPU 时间 "+opts.CPUTime.String())
+	}
+	return strings.Join(parts, ", ")
+}
+
+// openDaemon 打开任务数据库（配置了 schedule.mysql_dsn 时使用共享的 MySQL，否则使用本地 SQLite）
+func openDaemon() (*daemon.Daemon, error) {
+	if dsn := viper.GetString("schedule.mysql_dsn"); dsn != "" {
+		return daemon.NewMySQLDaemon(dsn)
+	}
+	return daemon.NewDaemon(dbPath)
 }
@@ -0,0 +1,388 @@
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/signal"
+	"sort"
+	"syscall"
+	"text/tabwriter"
+	"time"
+
+	"github.com/shirou/gopsutil/v3/cpu"
+	"github.com/shirou/gopsutil/v3/disk"
+	"github.com/shirou/gopsutil/v3/mem"
+	"github.com/shirou/gopsutil/v3/process"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"github.com/tedwangl/go-util/pkg/cobrax"
+	"github.com/tedwangl/go-util/pkg/utils/humanize"
+)
+
+type (
+	// procInfo 进程资源占用
+	procInfo struct {
+		PID        int32   `json:"pid"`
+		Name       string  `json:"name"`
+		User       string  `json:"user"`
+		CPUPercent float64 `json:"cpu_percent"` // 采样间隔内的 CPU 使用率（多核可超过 100）
+		MemPercent float32 `json:"mem_percent"`
+		RSS        uint64  `json:"rss"`
+	}
+
+	// diskInfo 分区使用情况
+	diskInfo struct {
+		Device      string  `json:"device"`
+		Mountpoint  string  `json:"mountpoint"`
+		Fstype      string  `json:"fstype"`
+		Total       uint64  `json:"total"`
+		Used        uint64  `json:"used"`
+		Free        uint64  `json:"free"`
+		UsedPercent float64 `json:"used_percent"`
+	}
+
+	// memInfo 内存和交换分区使用情况
+	memInfo struct {
+		Total           uint64  `json:"total"`
+		Used            uint64  `json:"used"`
+		Available       uint64  `json:"available"`
+		UsedPercent     float64 `json:"used_percent"`
+		SwapTotal       uint64  `json:"swap_total"`
+		SwapUsed        uint64  `json:"swap_used"`
+		SwapUsedPercent float64 `json:"swap_used_percent"`
+	}
+
+	// sysSample watch 的一次采样
+	sysSample struct {
+		Time       time.Time  `json:"time"`
+		CPUPercent float64    `json:"cpu_percent"`
+		MemPercent float64    `json:"mem_percent"`
+		Disks      []diskInfo `json:"disks"`
+		Alerts     []string   `json:"alerts,omitempty"`
+	}
+)
+
+// RegisterSysCommands 注册系统资源监控相关命令
+func RegisterSysCommands(tool *cobrax.Tool) {
+	sysGroup := cobrax.NewCommandGroup("sys")
+
+	sysCmd := tool.NewCommand(
+		"sys",
+		"系统资源监控",
+		"查看进程、磁盘、内存使用情况（跨平台，不依赖系统命令）",
+		nil,
+	)
+	sysCmd.Command.GroupID = "sys"
+
+	// sys top - 进程列表
+	topCmd := tool.NewCommand(
+		"top",
+		"按 CPU/内存排序的进程列表",
+		"采样一段时间内各进程的 CPU 使用率，按 CPU 或内存排序输出",
+		cobrax.CmdRunnerFunc(func(cmd *cobra.Command, args []string) error {
+			sortBy := viper.GetString("sort")
+			if sortBy != "cpu" && sortBy != "mem" {
+				return fmt.Errorf("无效的排序字段: %s（cpu 或 mem）", sortBy)
+			}
+			interval, err := time.ParseDuration(viper.GetString("interval"))
+			if err != nil || interval <= 0 {
+				return fmt.Errorf("无效的采样间隔: %s", viper.GetString("interval"))
+			}
+
+			procs, err := sampleProcesses(interval)
+			if err != nil {
+				return err
+			}
+
+			sort.Slice(procs, func(i, j int) bool {
+				if sortBy == "mem" {
+					return procs[i].RSS > procs[j].RSS
+				}
+				return procs[i].CPUPercent > procs[j].CPUPercent
+			})
+			if limit := viper.GetInt("limit"); limit > 0 && len(procs) > limit {
+				procs = procs[:limit]
+			}
+
+			if viper.GetBool("json") {
+				return printJSON(procs)
+			}
+
+			w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "PID\tUSER\tCPU%\tMEM%\tRSS\tNAME")
+			for _, p := range procs {
+				fmt.Fprintf(w, "%d\t%s\t%.1f\t%.1f\t%s\t%s\n", p.PID, p.User, p.CPUPercent, p.MemPercent, humanize.Bytes(p.RSS), p.Name)
+			}
+			return w.Flush()
+		}),
+	)
+	topCmd.AddFlag("sort", "s", "cpu", "排序字段（cpu 或 mem）")
+	topCmd.AddFlag("limit", "n", 20, "显示的进程数（0 为全部）")
+	topCmd.AddFlag("interval", "i", "1s", "CPU 采样间隔")
+	topCmd.AddFlag("json", "", false, "以 JSON 输出")
+
+	// sys disk - 磁盘使用情况
+	diskCmd := tool.NewCommand(
+		"disk",
+		"查看磁盘使用情况",
+		"列出已挂载分区的容量和使用率",
+		cobrax.CmdRunnerFunc(func(cmd *cobra.Command, args []string) error {
+			disks, err := diskUsage()
+			if err != nil {
+				return err
+			}
+
+			if viper.GetBool("json") {
+				return printJSON(disks)
+			}
+
+			w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "MOUNT\tDEVICE\tTYPE\tTOTAL\tUSED\tFREE\tUSE%")
+			for _, d := range disks {
+				fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%.1f\n", d.Mountpoint, d.Device, d.Fstype,
+					humanize.Bytes(d.Total), humanize.Bytes(d.Used), humanize.Bytes(d.Free), d.UsedPercent)
+			}
+			return w.Flush()
+		}),
+	)
+	diskCmd.AddFlag("json", "", false, "以 JSON 输出")
+
+	// sys mem - 内存使用情况
+	memCmd := tool.NewCommand(
+		"mem",
+		"查看内存使用情况",
+		"查看物理内存和交换分区的使用情况",
+		cobrax.CmdRunnerFunc(func(cmd *cobra.Command, args []string) error {
+			info, err := memUsage()
+			if err != nil {
+				return err
+			}
+
+			if viper.GetBool("json") {
+				return printJSON(info)
+			}
+
+			w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "\tTOTAL\tUSED\tAVAILABLE\tUSE%")
+			fmt.Fprintf(w, "Mem\t%s\t%s\t%s\t%.1f\n", humanize.Bytes(info.Total), humanize.Bytes(info.Used), humanize.Bytes(info.Available), info.UsedPercent)
+			fmt.Fprintf(w, "Swap\t%s\t%s\t%s\t%.1f\n", humanize.Bytes(info.SwapTotal), humanize.Bytes(info.SwapUsed), humanize.Bytes(info.SwapTotal-info.SwapUsed), info.SwapUsedPercent)
+			return w.Flush()
+		}),
+	)
+	memCmd.AddFlag("json", "", false, "以 JSON 输出")
+
+	// sys watch - 持续监控并告警
+	watchCmd := tool.NewCommand(
+		"watch",
+		"持续监控 CPU/内存/磁盘使用率",
+		"按间隔采样系统使用率，超过阈值时输出告警（阈值为 0 时不检查），Ctrl+C 退出",
+		cobrax.CmdRunnerFunc(func(cmd *cobra.Command, args []string) error {
+			interval, err := time.ParseDuration(viper.GetString("interval"))
+			if err != nil || interval <= 0 {
+				return fmt.Errorf("无效的采样间隔: %s", viper.GetString("interval"))
+			}
+			cpuLimit := viper.GetInt("cpu")
+			memLimit := viper.GetInt("mem")
+			diskLimit := viper.GetInt("disk")
+			asJSON := viper.GetBool("json")
+
+			ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+			defer stop()
+
+			// 首次调用建立 CPU 基准
+			cpu.Percent(0, false)
+
+			ticker := time.NewTicker(interval)
+			defer ticker.Stop()
+
+			if !asJSON {
+				fmt.Printf("每 %s 采样一次，按 Ctrl+C 退出\n", interval)
+			}
+			for {
+				select {
+				case <-ctx.Done():
+					return nil
+				case <-ticker.C:
+				}
+
+				sample, err := sampleSystem()
+				if err != nil {
+					return err
+				}
+				sample.Alerts = checkThresholds(sample, cpuLimit, memLimit, diskLimit)
+
+				if asJSON {
+					printJSONLine(sample)
+					continue
+				}
+
+				maxDisk := 0.0
+				for _, d := range sample.Disks {
+					maxDisk = max(maxDisk, d.UsedPercent)
+				}
+				fmt.Printf("%s  CPU %5.1f%%  内存 %5.1f%%  磁盘(最高) %5.1f%%\n",
+					sample.Time.Format("15:04:05"), sample.CPUPercent, sample.MemPercent, maxDisk)
+				for _, alert := range sample.Alerts {
+					fmt.Fprintf(os.Stderr, "告警: %s\n", alert)
+				}
+			}
+		}),
+	)
+	watchCmd.AddFlag("interval", "i", "2s", "采样间隔")
+	watchCmd.AddFlag("cpu", "", 90, "CPU 使用率告警阈值（%）")
+	watchCmd.AddFlag("mem", "", 90, "内存使用率告警阈值（%）")
+	watchCmd.AddFlag("disk", "", 90, "磁盘使用率告警阈值（%）")
+	watchCmd.AddFlag("json", "", false, "每次采样输出一行 JSON")
+
+	sysCmd.Command.AddCommand(topCmd.Command, diskCmd.Command, memCmd.Command, watchCmd.Command)
+
+	sysGroup.AddCommand(sysCmd)
+	tool.AddGroupLogic(sysGroup)
+}
+
+// sampleProcesses 两次读取进程 CPU 时间，按间隔计算使用率（采样期间退出或无权限的进程被忽略）
+func sampleProcesses(interval time.Duration) ([]procInfo, error) {
+	procs, err := process.Processes()
+	if err != nil {
+		return nil, fmt.Errorf("获取进程列表失败: %w", err)
+	}
+
+	before := make(map[int32]float64, len(procs))
+	for _, p := range procs {
+		if t, err := p.Times(); err == nil {
+			before[p.Pid] = t.User + t.System
+		}
+	}
+	start := time.Now()
+	time.Sleep(interval)
+	elapsed := time.Since(start).Seconds()
+
+	infos := make([]procInfo, 0, len(procs))
+	for _, p := range procs {
+		t, err := p.Times()
+		if err != nil {
+			continue
+		}
+		info := procInfo{PID: p.Pid}
+		if prev, ok := before[p.Pid]; ok {
+			info.CPUPercent = (t.User + t.System - prev) / elapsed * 100
+		}
+		info.Name, _ = p.Name()
+		info.User, _ = p.Username()
+		info.MemPercent, _ = p.MemoryPercent()
+		if m, err := p.MemoryInfo(); err == nil {
+			info.RSS = m.RSS
+		}
+		infos = append(infos, info)
+	}
+	return infos, nil
+}
+
+// diskUsage 物理分区的使用情况（跳过无法读取的挂载点）
+func diskUsage() ([]diskInfo, error) {
+	parts, err := disk.Partitions(false)
+	if err != nil {
+		return nil, fmt.Errorf("获取分区列表失败: %w", err)
+	}
+
+	seen := make(map[string]bool, len(parts))
+	disks := make([]diskInfo, 0, len(parts))
+	for _, p := range parts {
+		if seen[p.Mountpoint] {
+			continue
+		}
+		seen[p.Mountpoint] = true
+
+		u, err := disk.Usage(p.Mountpoint)
+		if err != nil || u.Total == 0 {
+			continue
+		}
+		disks = append(disks, diskInfo{
+			Device:      p.Device,
+			Mountpoint:  p.Mountpoint,
+			Fstype:      p.Fstype,
+			Total:       u.Total,
+			Used:        u.Used,
+			Free:        u.Free,
+			UsedPercent: u.UsedPercent,
+		})
+	}
+	sort.Slice(disks, func(i, j int) bool { return disks[i].Mountpoint < disks[j].Mountpoint })
+	return disks, nil
+}
+
+// memUsage 内存和交换分区使用情况
+func memUsage() (*memInfo, error) {
+	vm, err := mem.VirtualMemory()
+	if err != nil {
+		return nil, fmt.Errorf("获取内存信息失败: %w", err)
+	}
+	info := &memInfo{
+		Total:       vm.Total,
+		Used:        vm.Used,
+		Available:   vm.Available,
+		UsedPercent: vm.UsedPercent,
+	}
+	if swap, err := mem.SwapMemory(); err == nil {
+		info.SwapTotal = swap.Total
+		info.SwapUsed = swap.Used
+		info.SwapUsedPercent = swap.UsedPercent
+	}
+	return info, nil
+}
+
+// sampleSystem 采样整体 CPU（自上次调用以来）、内存和磁盘使用率
+func sampleSystem() (*sysSample, error) {
+	cpus, err := cpu.Percent(0, false)
+	if err != nil || len(cpus) == 0 {
+		return nil, fmt.Errorf("获取 CPU 使用率失败: %v", err)
+	}
+	vm, err := mem.VirtualMemory()
+	if err != nil {
+		return nil, fmt.Errorf("获取内存信息失败: %w", err)
+	}
+	disks, err := diskUsage()
+	if err != nil {
+		return nil, err
+	}
+	return &sysSample{
+		Time:       time.Now(),
+		CPUPercent: cpus[0],
+		MemPercent: vm.UsedPercent,
+		Disks:      disks,
+	}, nil
+}
+
+// checkThresholds 返回超过阈值的告警信息（阈值 <= 0 时不检查）
+func checkThresholds(s *sysSample, cpuLimit, memLimit, diskLimit int) []string {
+	var alerts []string
+	if cpuLimit > 0 && s.CPUPercent >= float64(cpuLimit) {
+		alerts = append(alerts, fmt.Sprintf("CPU 使用率 %.1f%% 超过阈值 %d%%", s.CPUPercent, cpuLimit))
+	}
+	if memLimit > 0 && s.MemPercent >= float64(memLimit) {
+		alerts = append(alerts, fmt.Sprintf("内存使用率 %.1f%% 超过阈值 %d%%", s.MemPercent, memLimit))
+	}
+	if diskLimit > 0 {
+		for _, d := range s.Disks {
+			if d.UsedPercent >= float64(diskLimit) {
+				alerts = append(alerts, fmt.Sprintf("磁盘 %s 使用率 %.1f%% 超过阈值 %d%%", d.Mountpoint, d.UsedPercent, diskLimit))
+			}
+		}
+	}
+	return alerts
+}
+
+// printJSON 以缩进 JSON 输出（不转义 &、<、>，便于输出 URL）
+func printJSON(v any) error {
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	enc.SetEscapeHTML(false)
+	return enc.Encode(v)
+}
+
+// printJSONLine 输出单行 JSON（用于持续输出的命令）
+func printJSONLine(v any) error {
+	return json.NewEncoder(os.Stdout).Encode(v)
+}
@@ -0,0 +1,228 @@
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"github.com/tedwangl/go-util/pkg/cobrax"
+	"github.com/tedwangl/go-util/pkg/restyx"
+	"github.com/tedwangl/go-util/pkg/utils/humanize"
+)
+
+type (
+	// temporalExecution 工作流执行信息（HTTP API 的 WorkflowExecutionInfo）
+	temporalExecution struct {
+		Execution struct {
+			WorkflowID string `json:"workflowId"`
+			RunID      string `json:"runId"`
+		} `json:"execution"`
+		Type struct {
+			Name string `json:"name"`
+		} `json:"type"`
+		StartTime time.Time  `json:"startTime"`
+		CloseTime *time.Time `json:"closeTime,omitempty"`
+		Status    string     `json:"status"`
+		TaskQueue string     `json:"taskQueue"`
+	}
+
+	// temporalStartRequest 启动工作流请求
+	temporalStartRequest struct {
+		WorkflowID   string            `json:"workflowId"`
+		WorkflowType map[string]string `json:"workflowType"`
+		TaskQueue    map[string]string `json:"taskQueue"`
+		Input        []json.RawMessage `json:"input,omitempty"`
+		CronSchedule string            `json:"cronSchedule,omitempty"`
+		RequestID    string            `json:"requestId"`
+	}
+)
+
+// RegisterTemporalCommands 注册 Temporal 工作流相关命令（通过 Temporal HTTP API，无需 SDK）
+func RegisterTemporalCommands(tool *cobrax.Tool) {
+	temporalGroup := cobrax.NewCommandGroup("temporal")
+
+	temporalCmd := tool.NewCommand(
+		"temporal",
+		"Temporal 工作流工具",
+		"通过 Temporal HTTP API（默认端口 7243）启动、查看和终止工作流",
+		nil,
+	)
+	temporalCmd.Command.GroupID = "temporal"
+	temporalCmd.AddPersistentFlag("addr", "", "http://localhost:7243", "Temporal HTTP API 地址")
+	temporalCmd.AddPersistentFlag("namespace", "n", "default", "命名空间")
+
+	// temporal start type - 启动工作流
+	startCmd := tool.NewCommand(
+		"start",
+		"启动工作流",
+		"devtool temporal start <工作流类型> [JSON 参数...] -q <任务队列>，参数按顺序传入，--cron 启动定时工作流",
+		cobrax.CmdRunnerFunc(func(cmd *cobra.Command, args []string) error {
+			if len(args) == 0 {
+				return fmt.Errorf("用法: devtool temporal start <工作流类型> -q <任务队列>")
+			}
+			taskQueue := viper.GetString("task-queue")
+			if taskQueue == "" {
+				return fmt.Errorf("必须指定任务队列 (-q)")
+			}
+
+			id := viper.GetString("id")
+			if id == "" {
+				id = fmt.Sprintf("%s-%d", args[0], time.Now().UnixMilli())
+			}
+			req := temporalStartRequest{
+				WorkflowID:   id,
+				WorkflowType: map[string]string{"name": args[0]},
+				TaskQueue:    map[string]string{"name": taskQueue},
+				CronSchedule: viper.GetString("cron"),
+				RequestID:    strconv.FormatInt(time.Now().UnixNano(), 36),
+			}
+			for _, input := range args[1:] {
+				if !json.Valid([]byte(input)) {
+					return fmt.Errorf("参数不是有效的 JSON: %s（字符串需要加引号，如 '\"World\"'）", input)
+				}
+				req.Input = append(req.Input, json.RawMessage(input))
+			}
+
+			var result struct {
+				RunID string `json:"runId"`
+			}
+			if err := temporalRequest(cmd, "POST", "/workflows/"+url.PathEscape(id), req, &result); err != nil {
+				return err
+			}
+
+			fmt.Printf("工作流已启动\n  WorkflowID: %s\n  RunID:      %s\n", id, result.RunID)
+			return nil
+		}),
+	)
+	startCmd.AddFlag("task-queue", "q", "", "任务队列")
+	startCmd.AddFlag("id", "", "", "工作流 ID（默认 <类型>-<毫秒时间戳>）")
+	startCmd.AddFlag("cron", "", "", "cron 表达式，如 '*/5 * * * *'")
+
+	// temporal list [query] - 列出工作流
+	listCmd := tool.NewCommand(
+		"list",
+		"列出工作流",
+		"devtool temporal list [可见性查询]，如 \"ExecutionStatus='Running'\"",
+		cobrax.CmdRunnerFunc(func(cmd *cobra.Command, args []string) error {
+			query := url.Values{}
+			query.Set("pageSize", strconv.Itoa(viper.GetInt("limit")))
+			if len(args) > 0 {
+				query.Set("query", strings.Join(args, " "))
+			} else if viper.GetBool("running") {
+				query.Set("query", "ExecutionStatus='Running'")
+			}
+
+			var result struct {
+				Executions []temporalExecution `json:"executions"`
+			}
+			if err := temporalRequest(cmd, "GET", "/workflows?"+query.Encode(), nil, &result); err != nil {
+				return err
+			}
+
+			if viper.GetBool("json") {
+				return printJSON(result.Executions)
+			}
+			if len(result.Executions) == 0 {
+				fmt.Println("没有工作流")
+				return nil
+			}
+
+			w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "WORKFLOW ID\tTYPE\tSTATUS\tSTARTED\tTASK QUEUE")
+			for _, e := range result.Executions {
+				status := strings.TrimPrefix(e.Status, "WORKFLOW_EXECUTION_STATUS_")
+				fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", e.Execution.WorkflowID, e.Type.Name, status,
+					humanize.Ago(e.StartTime), e.TaskQueue)
+			}
+			return w.Flush()
+		}),
+	)
+	listCmd.AddFlag("limit", "l", 20, "最多显示的数量")
+	listCmd.AddFlag("running", "r", false, "只显示运行中的工作流")
+	listCmd.AddFlag("json", "", false, "以 JSON 输出")
+
+	// temporal terminate id - 终止工作流
+	terminateCmd := tool.NewCommand(
+		"terminate",
+		"终止工作流",
+		"devtool temporal terminate <工作流 ID> [--reason 原因]，强制终止，不会执行补偿",
+		cobrax.CmdRunnerFunc(func(cmd *cobra.Command, args []string) error {
+			if len(args) == 0 {
+				return fmt.Errorf("用法: devtool temporal terminate <工作流 ID>")
+			}
+
+			body := map[string]any{"reason": viper.GetString("reason")}
+			if runID := viper.GetString("run-id"); runID != "" {
+				body["workflowExecution"] = map[string]string{"runId": runID}
+			}
+			path := "/workflows/" + url.PathEscape(args[0]) + "/terminate"
+			if err := temporalRequest(cmd, "POST", path, body, nil); err != nil {
+				return err
+			}
+
+			fmt.Printf("工作流 %s 已终止\n", args[0])
+			return nil
+		}),
+	)
+	terminateCmd.AddFlag("reason", "", "terminated by devtool", "终止原因")
+	terminateCmd.AddFlag("run-id", "", "", "指定执行（默认最新一次）")
+	terminateCmd.RequireConfirmation("强制终止工作流（不会执行补偿）")
+
+	temporalCmd.Command.AddCommand(startCmd.Command, listCmd.Command, terminateCmd.Command)
+
+	temporalGroup.AddCommand(temporalCmd)
+	tool.AddGroupLogic(temporalGroup)
+}
+
+// temporalRequest 调用 Temporal HTTP API，path 相对于 /api/v1/namespaces/<namespace>
+func temporalRequest(cmd *cobra.Command, method, path string, body, result any) error {
+	addr := strings.TrimSuffix(viper.GetString("addr"), "/")
+	if !strings.Contains(addr, "://") {
+		addr = "http://" + addr
+	}
+	endpoint := addr + "/api/v1/namespaces/" + url.PathEscape(viper.GetString("namespace")) + path
+
+	cfg := restyx.DefaultConfig()
+	cfg.Timeout = 10 * time.Second
+	cfg.RetryCount = 0
+	client := restyx.New(cfg, nil)
+
+	opts := []restyx.RequestOption{restyx.WithContext(cmd.Context())}
+	if body != nil {
+		opts = append(opts, restyx.WithBody(body))
+	}
+
+	var (
+		resp *restyx.Response
+		err  error
+	)
+	if method == "POST" {
+		resp, err = client.Post(endpoint, opts...)
+	} else {
+		resp, err = client.Get(endpoint, opts...)
+	}
+	if err != nil {
+		return fmt.Errorf("请求 Temporal 失败: %w", err)
+	}
+	if resp.StatusCode >= 400 {
+		var apiErr struct {
+			Message string `json:"message"`
+		}
+		if json.Unmarshal(resp.Body, &apiErr) == nil && apiErr.Message != "" {
+			return fmt.Errorf("Temporal 返回 %d: %s", resp.StatusCode, apiErr.Message)
+		}
+		return fmt.Errorf("Temporal 返回 %d: %s", resp.StatusCode, strings.TrimSpace(string(resp.Body)))
+	}
+
+	if result == nil || len(resp.Body) == 0 {
+		return nil
+	}
+	return json.Unmarshal(resp.Body, result)
+}
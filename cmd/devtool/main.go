@@ -28,6 +28,19 @@ func main() {
 	// 设置配置文件
 	tool.SetConfig(os.ExpandEnv("$HOME/.devtool/config.yaml"))
 
+	// 配置输出命令（数据库、Redis 密码等可使用 env://、file://、vault:// 引用，输出时脱敏）
+	tool.AddConfigCommand()
+
+	// 命令使用统计（配置 telemetry: true 或 DEVTOOL_TELEMETRY=true 开启，只保存在本地）
+	tool.EnableUsageStats(os.ExpandEnv("$HOME/.devtool/stats.db"))
+	tool.AddStatsCommand()
+
+	// 用户别名（配置文件 alias 下定义，如 alias.sls: list）
+	tool.AddAliasCommand()
+
+	// 配置 profile（配置文件 profiles 下定义，--profile 或 DEVTOOL_PROFILE 选择）
+	tool.AddProfileCommand()
+
 	// 设置错误处理器
 	tool.SetErrorHandler(cobrax.LoggingErrorHandler(tool.GetLogger()))
 
@@ -36,6 +49,16 @@ func main() {
 	commands.RegisterScheduleCommands(tool)
 	commands.RegisterNetCommands(tool)
 	commands.RegisterGoCommands(tool)
+	commands.RegisterSysCommands(tool)
+	commands.RegisterHTTPCommands(tool)
+	commands.RegisterRedisCommands(tool)
+	commands.RegisterDBCommands(tool)
+	commands.RegisterFileCommands(tool)
+	commands.RegisterEncCommands(tool)
+	commands.RegisterTemporalCommands(tool)
+	commands.RegisterCrawlCommands(tool)
+	commands.RegisterRemoteCommands(tool)
+	commands.RegisterLogCommands(tool)
 
 	// 执行
 	os.Exit(tool.Execute())
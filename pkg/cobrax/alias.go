@@ -0,0 +1,187 @@
+package cobrax
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// AliasConfigKey 配置文件中用户自定义别名的配置项
+//
+//	alias:
+//	  sls: list
+//	  slg: logs --limit 50
+const AliasConfigKey = "alias"
+
+// SetAliases 设置命令别名（显示在帮助和命令树中）
+func (c *Command) SetAliases(aliases ...string) {
+	c.Command.Aliases = append(c.Command.Aliases, aliases...)
+}
+
+// AddAlias 添加用户别名，执行时 name 展开为 expansion（按空白分隔）
+// 配置文件中的同名别名优先
+func (t *Tool) AddAlias(name, expansion string) {
+	if t.aliases == nil {
+		t.aliases = make(map[string]string)
+	}
+	t.aliases[name] = expansion
+}
+
+// Aliases 获取所有用户别名（代码中添加的和配置文件中的）
+func (t *Tool) Aliases() map[string]string {
+	return t.loadAliases(t.configFile(os.Args[1:]))
+}
+
+// AddAliasCommand 添加 alias 命令，列出用户别名
+func (t *Tool) AddAliasCommand() {
+	aliasCmd := &cobra.Command{
+		Use:   "alias",
+		Short: "列出用户别名",
+		Long:  fmt.Sprintf("列出用户别名，在配置文件的 %s 下添加，例如 %s.sls: list", AliasConfigKey, AliasConfigKey),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			aliases := t.Aliases()
+			if len(aliases) == 0 {
+				fmt.Printf("暂无别名，在配置文件的 %s 下添加\n", AliasConfigKey)
+				return nil
+			}
+
+			names := make([]string, 0, len(aliases))
+			for name := range aliases {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+
+			w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "ALIAS\tCOMMAND")
+			for _, name := range names {
+				fmt.Fprintf(w, "%s\t%s\n", name, aliases[name])
+			}
+			return w.Flush()
+		},
+	}
+	t.rootCmd.Command.AddCommand(aliasCmd)
+}
+
+// loadAliases 合并代码中添加的别名和配置文件中的别名
+// 配置文件在解析参数之前读取，所以使用独立的 viper 实例
+func (t *Tool) loadAliases(cfgFile string) map[string]string {
+	aliases := make(map[string]string, len(t.aliases))
+	for name, expansion := range t.aliases {
+		aliases[name] = expansion
+	}
+
+	if cfgFile == "" {
+		return aliases
+	}
+	v := viper.New()
+	v.SetConfigFile(cfgFile)
+	if err := v.ReadInConfig(); err != nil {
+		return aliases
+	}
+	for name, expansion := range v.GetStringMapString(AliasConfigKey) {
+		aliases[name] = expansion
+	}
+	return aliases
+}
+
+// configFile 获取配置文件路径，命令行的 --config 优先
+func (t *Tool) configFile(args []string) string {
+	for i, arg := range args {
+		if arg == "--" {
+			break
+		}
+		for _, name := range []string{"--config", "-c"} {
+			if arg == name && i+1 < len(args) {
+				return args[i+1]
+			}
+			if value, ok := strings.CutPrefix(arg, name+"="); ok {
+				return value
+			}
+		}
+	}
+	return t.cfgFile
+}
+
+// expandAliases 展开参数中的用户别名（只展开第一个非标志参数，内置命令优先）
+// 别名可以引用其他别名，出现循环时返回错误
+func (t *Tool) expandAliases(args []string) ([]string, error) {
+	aliases := t.loadAliases(t.configFile(args))
+	if len(aliases) == 0 {
+		return args, nil
+	}
+
+	pos := t.firstCommandArg(args)
+	if pos < 0 {
+		return args, nil
+	}
+
+	var chain []string
+	for {
+		name := args[pos]
+		expansion, ok := aliases[name]
+		if !ok || t.isCommand(name) {
+			return args, nil
+		}
+
+		for _, seen := range chain {
+			if seen == name {
+				return nil, fmt.Errorf("别名循环: %s -> %s", strings.Join(chain, " -> "), name)
+			}
+		}
+		chain = append(chain, name)
+
+		words := strings.Fields(expansion)
+		if len(words) == 0 {
+			return nil, fmt.Errorf("别名 %s 为空", name)
+		}
+
+		expanded := make([]string, 0, len(args)+len(words)-1)
+		expanded = append(expanded, args[:pos]...)
+		expanded = append(expanded, words...)
+		expanded = append(expanded, args[pos+1:]...)
+		args = expanded
+	}
+}
+
+// firstCommandArg 返回第一个非标志参数的位置，跳过根命令标志的值，没有时返回 -1
+func (t *Tool) firstCommandArg(args []string) int {
+	flags := t.rootCmd.PersistentFlags()
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if arg == "--" {
+			return -1
+		}
+		if !strings.HasPrefix(arg, "-") || arg == "-" {
+			return i
+		}
+		if strings.Contains(arg, "=") {
+			continue
+		}
+
+		// 非布尔标志的值在下一个参数中
+		name := strings.TrimLeft(arg, "-")
+		flag := flags.Lookup(name)
+		if flag == nil && len(name) == 1 {
+			flag = flags.ShorthandLookup(name)
+		}
+		if flag != nil && flag.Value.Type() != "bool" {
+			i++
+		}
+	}
+	return -1
+}
+
+// isCommand 判断 name 是否为根命令下的子命令或子命令别名
+func (t *Tool) isCommand(name string) bool {
+	for _, cmd := range t.rootCmd.Commands() {
+		if cmd.Name() == name || cmd.HasAlias(name) {
+			return true
+		}
+	}
+	return false
+}
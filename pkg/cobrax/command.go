@@ -126,6 +126,11 @@ func (c *Command) ChainPersistentPostRunE(parent *Command, fn func(cmd *cobra.Co
 	}
 }
 
+// AddExample 添加命令示例，在帮助信息的 Examples 中显示
+func (c *Command) AddExample(desc, cmdline string) {
+	c.Examples = append(c.Examples, Example{Desc: desc, Cmd: cmdline})
+}
+
 // SetPostRunE 设置后置钩子（仅当前命令）
 func (c *Command) SetPostRunE(fn func(cmd *cobra.Command, args []string) error) {
 	c.Command.PostRunE = fn
@@ -171,125 +176,7 @@ func CustomHelpFunc(customHelp func(cmd *cobra.Command) string) func(*cobra.Comm
 	}
 }
 
-// PrintCommandTree 打印命令树形结构
+// PrintCommandTree 打印命令树形结构（不带颜色）
 func (t *Tool) PrintCommandTree() string {
-	return printTree(t.rootCmd.Command, "", true, true)
-}
-
-// printTree 递归打印命令树
-func printTree(cmd *cobra.Command, prefix string, isLast bool, isRoot bool) string {
-	result := ""
-
-	// 打印当前命令
-	if isRoot {
-		result += fmt.Sprintf("%s\n", cmd.Name())
-	} else {
-		connector := "├── "
-		if isLast {
-			connector = "└── "
-		}
-
-		groupInfo := ""
-		if cmd.GroupID != "" {
-			groupInfo = fmt.Sprintf(" [group:%s]", cmd.GroupID)
-		}
-
-		result += fmt.Sprintf("%s%s%s%s\n", prefix, connector, cmd.Name(), groupInfo)
-	}
-
-	// 获取子命令
-	subCmds := cmd.Commands()
-	if len(subCmds) == 0 {
-		return result
-	}
-
-	// 过滤掉内置命令和 tree 命令
-	var filteredCmds []*cobra.Command
-	for _, subCmd := range subCmds {
-		if subCmd.Name() != "completion" && subCmd.Name() != "help" && subCmd.Name() != "tree" && subCmd.Name() != "version" {
-			filteredCmds = append(filteredCmds, subCmd)
-		}
-	}
-
-	// 按组分类命令
-	type groupCommands struct {
-		name     string
-		commands []*cobra.Command
-	}
-
-	groupMap := make(map[string]*groupCommands)
-	var ungrouped []*cobra.Command
-	var groupOrder []string
-
-	for _, subCmd := range filteredCmds {
-		if subCmd.GroupID != "" {
-			if _, exists := groupMap[subCmd.GroupID]; !exists {
-				groupMap[subCmd.GroupID] = &groupCommands{name: subCmd.GroupID}
-				groupOrder = append(groupOrder, subCmd.GroupID)
-			}
-			groupMap[subCmd.GroupID].commands = append(groupMap[subCmd.GroupID].commands, subCmd)
-		} else {
-			ungrouped = append(ungrouped, subCmd)
-		}
-	}
-
-	// 先打印分组命令
-	totalGroups := len(groupOrder) + len(ungrouped)
-	currentIndex := 0
-
-	for _, groupID := range groupOrder {
-		group := groupMap[groupID]
-		isLastGroup := currentIndex == totalGroups-1
-
-		connector := "├── "
-		if isLastGroup {
-			connector = "└── "
-		}
-
-		var newPrefix string
-		if isRoot {
-			newPrefix = ""
-		} else if isLast {
-			newPrefix = prefix + "    "
-		} else {
-			newPrefix = prefix + "│   "
-		}
-
-		// 打印组名
-		result += fmt.Sprintf("%s%s[%s]\n", newPrefix, connector, groupID)
-
-		// 打印组内命令
-		groupPrefix := newPrefix
-		if isLastGroup {
-			groupPrefix += "    "
-		} else {
-			groupPrefix += "│   "
-		}
-
-		for i, subCmd := range group.commands {
-			isLastInGroup := i == len(group.commands)-1
-			result += printTree(subCmd, groupPrefix, isLastInGroup, false)
-		}
-
-		currentIndex++
-	}
-
-	// 再打印未分组命令
-	for _, subCmd := range ungrouped {
-		isLastChild := currentIndex == totalGroups-1
-
-		var newPrefix string
-		if isRoot {
-			newPrefix = ""
-		} else if isLast {
-			newPrefix = prefix + "    "
-		} else {
-			newPrefix = prefix + "│   "
-		}
-
-		result += printTree(subCmd, newPrefix, isLastChild, false)
-		currentIndex++
-	}
-
-	return result
+	return t.newHelpRenderer(nil).Tree(t.rootCmd.Command)
 }
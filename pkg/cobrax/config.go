@@ -10,6 +10,7 @@ import (
 
 // SetConfig 设置配置文件并初始化viper
 func (t *Tool) SetConfig(cfgFile string) {
+	t.cfgFile = cfgFile
 	originalPreRunE := t.rootCmd.PersistentPreRunE
 
 	t.rootCmd.PersistentPreRunE = func(cmd *cobra.Command, args []string) error {
@@ -35,7 +36,22 @@ func (t *Tool) SetConfig(cfgFile string) {
 			return err
 		}
 
-		// 4. 执行原有的 PreRunE（如果存在）
+		// 4. 合并选中的 profile（--profile 或环境变量）
+		if err := t.applyProfile(); err != nil {
+			return err
+		}
+
+		// 5. 解析秘密引用（env://、file://、vault:// 等）
+		if err := t.resolveSecrets(); err != nil {
+			return err
+		}
+
+		// 6. profile 中的值设置到未指定的标志上
+		if err := t.applyProfileFlags(cmd); err != nil {
+			return err
+		}
+
+		// 7. 执行原有的 PreRunE（如果存在）
 		if originalPreRunE != nil {
 			return originalPreRunE(cmd, args)
 		}
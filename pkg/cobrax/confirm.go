@@ -0,0 +1,103 @@
+package cobrax
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// ErrNoConfirmInput 无法读取确认输入（非交互模式下未指定 --yes）
+var ErrNoConfirmInput = errors.New("无法读取确认输入，非交互模式请使用 --yes")
+
+// confirmation 命令执行前的确认要求
+type confirmation struct {
+	message string
+	phrase  string // 需要输入的确认短语，为空时只需 y/N
+	typed   bool
+}
+
+// RequireConfirmation 执行前要求用户确认（y/N），--yes 跳过
+func (c *Command) RequireConfirmation(message string) {
+	c.confirmation = &confirmation{message: message}
+}
+
+// RequireTypedConfirmation 执行前要求用户输入确认短语，用于高危操作，--yes 跳过
+// phrase 为空时需要输入第一个参数（如要删除的名称），没有参数时输入命令名
+func (c *Command) RequireTypedConfirmation(message, phrase string) {
+	c.confirmation = &confirmation{message: message, phrase: phrase, typed: true}
+}
+
+// confirm 按命令的确认要求询问用户
+func (c *Command) confirm(cmd *cobra.Command, args []string) (bool, error) {
+	if c.confirmation == nil {
+		return true, nil
+	}
+
+	prompt := c.confirmation.message
+	if len(args) > 0 {
+		prompt = fmt.Sprintf("%s: %s", prompt, strings.Join(args, " "))
+	}
+	if !c.confirmation.typed {
+		return Confirm(cmd, prompt)
+	}
+
+	phrase := c.confirmation.phrase
+	if phrase == "" {
+		phrase = cmd.Name()
+		if len(args) > 0 {
+			phrase = args[0]
+		}
+	}
+	return ConfirmPhrase(cmd, prompt, phrase)
+}
+
+// AssumeYes 判断是否跳过确认（--yes 标志或 <PREFIX>_YES 环境变量）
+func AssumeYes(cmd *cobra.Command) bool {
+	if yes, err := cmd.Flags().GetBool("yes"); err == nil && yes {
+		return true
+	}
+	return viper.GetBool("yes")
+}
+
+// Confirm 询问用户确认（默认否），指定 --yes 时直接返回 true
+func Confirm(cmd *cobra.Command, prompt string) (bool, error) {
+	if AssumeYes(cmd) {
+		return true, nil
+	}
+
+	fmt.Fprintf(cmd.ErrOrStderr(), "%s\n确认继续? [y/N] ", prompt)
+	answer, err := readAnswer(cmd.InOrStdin())
+	if err != nil {
+		return false, err
+	}
+	answer = strings.ToLower(answer)
+	return answer == "y" || answer == "yes", nil
+}
+
+// ConfirmPhrase 要求用户输入 phrase 确认，指定 --yes 时直接返回 true
+func ConfirmPhrase(cmd *cobra.Command, prompt, phrase string) (bool, error) {
+	if AssumeYes(cmd) {
+		return true, nil
+	}
+
+	fmt.Fprintf(cmd.ErrOrStderr(), "%s\n此操作不可恢复，请输入 %s 确认: ", prompt, phrase)
+	answer, err := readAnswer(cmd.InOrStdin())
+	if err != nil {
+		return false, err
+	}
+	return answer == phrase, nil
+}
+
+// readAnswer 读取一行输入，输入已关闭且没有内容时返回 ErrNoConfirmInput
+func readAnswer(r io.Reader) (string, error) {
+	line, err := bufio.NewReader(r).ReadString('\n')
+	if err != nil && (err != io.EOF || line == "") {
+		return "", ErrNoConfirmInput
+	}
+	return strings.TrimSpace(line), nil
+}
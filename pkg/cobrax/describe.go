@@ -0,0 +1,193 @@
+package cobrax
+
+import (
+	"encoding/json"
+	"os"
+	"reflect"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// DescribeCommandName 输出自描述 JSON 的隐藏命令名
+const DescribeCommandName = "__describe"
+
+type (
+	// ToolDescription 工具的完整自描述，供界面生成、文档站点、补全引擎等外部工具使用
+	ToolDescription struct {
+		Name        string              `json:"name"`
+		Version     string              `json:"version"`
+		Description string              `json:"description"`
+		EnvPrefix   string              `json:"env_prefix"` // 环境变量前缀
+		Command     *CommandDescription `json:"command"`    // 根命令（含全局标志和全部子命令）
+	}
+
+	// CommandDescription 命令描述
+	CommandDescription struct {
+		Name       string                `json:"name"`
+		Path       string                `json:"path"` // 完整命令路径，如 "devtool db migrate"
+		Use        string                `json:"use"`
+		Aliases    []string              `json:"aliases,omitempty"`
+		Short      string                `json:"short,omitempty"`
+		Long       string                `json:"long,omitempty"`
+		Group      string                `json:"group,omitempty"`
+		Examples   []Example             `json:"examples,omitempty"`
+		Runnable   bool                  `json:"runnable"` // false 时只是命令分组
+		Hidden     bool                  `json:"hidden,omitempty"`
+		Deprecated string                `json:"deprecated,omitempty"`
+		Confirm    string                `json:"confirm,omitempty"` // 执行前的确认提示，为空时不需要确认
+		Flags      []FlagDescription     `json:"flags,omitempty"`   // 命令自身定义的标志（不含继承的）
+		Commands   []*CommandDescription `json:"commands,omitempty"`
+	}
+
+	// FlagDescription 标志描述
+	FlagDescription struct {
+		Name       string                 `json:"name"`
+		Shorthand  string                 `json:"shorthand,omitempty"`
+		Type       string                 `json:"type"`    // pflag 类型名，如 string、int、bool、stringSlice、duration
+		Default    string                 `json:"default"` // 默认值的字符串形式
+		Usage      string                 `json:"usage"`
+		Persistent bool                   `json:"persistent,omitempty"` // 是否被子命令继承
+		Required   bool                   `json:"required,omitempty"`
+		Hidden     bool                   `json:"hidden,omitempty"`
+		Deprecated string                 `json:"deprecated,omitempty"`
+		Validators []ValidatorDescription `json:"validators,omitempty"`
+	}
+
+	// ValidatorDescription 校验器描述，Type 为校验器类型名去掉 Validator 后缀的小写形式（如 min_length）
+	ValidatorDescription struct {
+		Type   string         `json:"type"`
+		Params map[string]any `json:"params,omitempty"`
+	}
+)
+
+// AddDescribeCommand 添加隐藏的自描述命令，以 JSON 输出命令树、标志和校验器
+func (t *Tool) AddDescribeCommand() {
+	describeCmd := &cobra.Command{
+		Use:    DescribeCommandName,
+		Short:  "以 JSON 输出命令、标志和校验器的完整描述",
+		Hidden: true,
+		Args:   cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			enc := json.NewEncoder(os.Stdout)
+			enc.SetIndent("", "  ")
+			return enc.Encode(t.Describe())
+		},
+	}
+	t.rootCmd.Command.AddCommand(describeCmd)
+}
+
+// Describe 返回工具的完整自描述
+func (t *Tool) Describe() *ToolDescription {
+	return &ToolDescription{
+		Name:        t.name,
+		Version:     t.version,
+		Description: t.desc,
+		EnvPrefix:   t.envPrefix,
+		Command:     t.describeCommand(t.rootCmd.Command),
+	}
+}
+
+// describeCommand 递归描述命令及其子命令（自描述命令本身除外）
+func (t *Tool) describeCommand(cmd *cobra.Command) *CommandDescription {
+	d := &CommandDescription{
+		Name:       cmd.Name(),
+		Path:       cmd.CommandPath(),
+		Use:        cmd.Use,
+		Aliases:    cmd.Aliases,
+		Short:      cmd.Short,
+		Long:       cmd.Long,
+		Group:      cmd.GroupID,
+		Runnable:   cmd.Runnable(),
+		Hidden:     cmd.Hidden,
+		Deprecated: cmd.Deprecated,
+	}
+
+	wrapped := t.commands[cmd]
+	if wrapped != nil {
+		d.Examples = wrapped.Examples
+		if wrapped.confirmation != nil {
+			d.Confirm = wrapped.confirmation.message
+		}
+	}
+
+	persistent := cmd.PersistentFlags()
+	describe := func(f *pflag.Flag) {
+		fd := FlagDescription{
+			Name:       f.Name,
+			Shorthand:  f.Shorthand,
+			Type:       f.Value.Type(),
+			Default:    f.DefValue,
+			Usage:      f.Usage,
+			Persistent: persistent.Lookup(f.Name) == f,
+			Hidden:     f.Hidden,
+			Deprecated: f.Deprecated,
+		}
+		if v := f.Annotations[cobra.BashCompOneRequiredFlag]; len(v) > 0 && v[0] == "true" {
+			fd.Required = true
+		}
+		if wrapped != nil {
+			for _, v := range wrapped.validators[f.Name] {
+				fd.Validators = append(fd.Validators, describeValidator(v))
+			}
+		}
+		d.Flags = append(d.Flags, fd)
+	}
+	cmd.LocalNonPersistentFlags().VisitAll(describe)
+	persistent.VisitAll(describe)
+
+	for _, sub := range cmd.Commands() {
+		if sub.Name() == DescribeCommandName {
+			continue
+		}
+		d.Commands = append(d.Commands, t.describeCommand(sub))
+	}
+	return d
+}
+
+// describeValidator 描述内置校验器的参数，自定义校验器只输出类型名
+func describeValidator(v ParamValidator) ValidatorDescription {
+	var d ValidatorDescription
+	var message string
+	switch val := v.(type) {
+	case *RequiredValidator:
+		d, message = ValidatorDescription{Type: "required"}, val.Message
+	case *MinLengthValidator:
+		d, message = ValidatorDescription{Type: "min_length", Params: map[string]any{"min": val.Min}}, val.Message
+	case *MaxLengthValidator:
+		d, message = ValidatorDescription{Type: "max_length", Params: map[string]any{"max": val.Max}}, val.Message
+	case *RegexValidator:
+		d, message = ValidatorDescription{Type: "regex", Params: map[string]any{"pattern": val.Pattern}}, val.Message
+	case *MinValueValidator:
+		d, message = ValidatorDescription{Type: "min_value", Params: map[string]any{"min": val.Min}}, val.Message
+	case *MaxValueValidator:
+		d, message = ValidatorDescription{Type: "max_value", Params: map[string]any{"max": val.Max}}, val.Message
+	default:
+		name := reflect.Indirect(reflect.ValueOf(v)).Type().Name()
+		return ValidatorDescription{Type: toSnakeCase(strings.TrimSuffix(name, "Validator"))}
+	}
+
+	if message != "" {
+		if d.Params == nil {
+			d.Params = make(map[string]any)
+		}
+		d.Params["message"] = message
+	}
+	return d
+}
+
+// toSnakeCase 将驼峰命名转为小写下划线形式（MinLength -> min_length）
+func toSnakeCase(s string) string {
+	var b strings.Builder
+	for i, r := range s {
+		if r >= 'A' && r <= 'Z' {
+			if i > 0 {
+				b.WriteByte('_')
+			}
+			r += 'a' - 'A'
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
@@ -1,6 +1,7 @@
 package cobrax
 
 import (
+	"errors"
 	"fmt"
 	"os"
 
@@ -8,8 +9,12 @@ import (
 	"go.uber.org/zap"
 )
 
-// DefaultErrorHandler 默认错误处理函数
+// DefaultErrorHandler 默认错误处理函数（超时不显示使用方法）
 func DefaultErrorHandler(err error, cmd *cobra.Command) error {
+	if errors.Is(err, ErrTimeout) {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return err
+	}
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 		fmt.Fprintf(os.Stderr, "\n使用方法:\n")
@@ -21,6 +26,14 @@ func DefaultErrorHandler(err error, cmd *cobra.Command) error {
 // LoggingErrorHandler 带日志记录的错误处理函数
 func LoggingErrorHandler(logger *zap.Logger) ErrorHandler {
 	return func(err error, cmd *cobra.Command) error {
+		if errors.Is(err, ErrTimeout) {
+			logger.Error("命令执行超时",
+				zap.String("command", cmd.CommandPath()),
+				zap.Error(err),
+			)
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			return err
+		}
 		if err != nil {
 			logger.Error("命令执行失败",
 				zap.String("command", cmd.CommandPath()),
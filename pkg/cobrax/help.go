@@ -0,0 +1,297 @@
+package cobrax
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/mattn/go-isatty"
+	"github.com/spf13/cobra"
+)
+
+// helpRenderer 渲染帮助信息和命令树，输出到终端时使用 ANSI 颜色（设置 NO_COLOR 时关闭）
+type helpRenderer struct {
+	tool    *Tool
+	heading *color.Color // 小节标题
+	name    *color.Color // 命令名
+	dim     *color.Color // 别名、分组等次要信息
+}
+
+// newHelpRenderer 创建帮助渲染器，w 不是终端时不使用颜色
+func (t *Tool) newHelpRenderer(w io.Writer) *helpRenderer {
+	r := &helpRenderer{
+		tool:    t,
+		heading: color.New(color.FgYellow, color.Bold),
+		name:    color.New(color.FgCyan),
+		dim:     color.New(color.Faint),
+	}
+	for _, c := range []*color.Color{r.heading, r.name, r.dim} {
+		if useColor(w) {
+			c.EnableColor()
+		} else {
+			c.DisableColor()
+		}
+	}
+	return r
+}
+
+// useColor 判断是否对 w 使用颜色
+func useColor(w io.Writer) bool {
+	if _, ok := os.LookupEnv("NO_COLOR"); ok {
+		return false
+	}
+	f, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+	return isatty.IsTerminal(f.Fd()) || isatty.IsCygwinTerminal(f.Fd())
+}
+
+// setupHelp 用渲染器替换 cobra 默认的帮助和用法模板
+func (t *Tool) setupHelp() {
+	t.rootCmd.SetHelpFunc(func(cmd *cobra.Command, args []string) {
+		out := cmd.OutOrStdout()
+		fmt.Fprint(out, t.newHelpRenderer(out).Help(cmd))
+	})
+	t.rootCmd.SetUsageFunc(func(cmd *cobra.Command) error {
+		out := cmd.OutOrStderr()
+		_, err := fmt.Fprint(out, t.newHelpRenderer(out).Usage(cmd))
+		return err
+	})
+}
+
+// Help 渲染完整帮助信息（描述 + 用法）
+func (r *helpRenderer) Help(cmd *cobra.Command) string {
+	var b strings.Builder
+	desc := cmd.Long
+	if desc == "" {
+		desc = cmd.Short
+	}
+	if desc = strings.TrimSpace(desc); desc != "" {
+		b.WriteString(desc + "\n\n")
+	}
+	b.WriteString(r.Usage(cmd))
+	return b.String()
+}
+
+// Usage 渲染用法、别名、示例、子命令和标志
+func (r *helpRenderer) Usage(cmd *cobra.Command) string {
+	var b strings.Builder
+
+	b.WriteString(r.heading.Sprint("Usage:") + "\n")
+	if cmd.Runnable() {
+		fmt.Fprintf(&b, "  %s\n", cmd.UseLine())
+	}
+	if cmd.HasAvailableSubCommands() {
+		fmt.Fprintf(&b, "  %s [command]\n", cmd.CommandPath())
+	}
+
+	if len(cmd.Aliases) > 0 {
+		b.WriteString("\n" + r.heading.Sprint("Aliases:") + "\n")
+		fmt.Fprintf(&b, "  %s\n", strings.Join(append([]string{cmd.Name()}, cmd.Aliases...), ", "))
+	}
+
+	if examples := r.examples(cmd); examples != "" {
+		b.WriteString("\n" + r.heading.Sprint("Examples:") + "\n")
+		b.WriteString(examples)
+	}
+
+	if cmd.HasAvailableSubCommands() {
+		sections := r.commandSections(cmd)
+		width := 0
+		for _, sec := range sections {
+			for _, sub := range sec.commands {
+				width = max(width, len(sub.Name()))
+			}
+		}
+		for _, sec := range sections {
+			b.WriteString("\n" + r.heading.Sprint(sec.title+":") + "\n")
+			for _, sub := range sec.commands {
+				name := fmt.Sprintf("%-*s", width, sub.Name())
+				fmt.Fprintf(&b, "  %s  %s\n", r.name.Sprint(name), sub.Short)
+			}
+		}
+	}
+
+	if cmd.HasAvailableLocalFlags() {
+		b.WriteString("\n" + r.heading.Sprint("Flags:") + "\n")
+		b.WriteString(cmd.LocalFlags().FlagUsages())
+	}
+	if cmd.HasAvailableInheritedFlags() {
+		b.WriteString("\n" + r.heading.Sprint("Global Flags:") + "\n")
+		b.WriteString(cmd.InheritedFlags().FlagUsages())
+	}
+
+	if cmd.HasAvailableSubCommands() {
+		fmt.Fprintf(&b, "\nUse \"%s [command] --help\" for more information about a command.\n", cmd.CommandPath())
+	}
+	return b.String()
+}
+
+// examples 渲染命令示例，优先使用 Command.Examples，否则使用 cobra 的 Example 文本
+func (r *helpRenderer) examples(cmd *cobra.Command) string {
+	var b strings.Builder
+	if c, ok := r.tool.commands[cmd]; ok && len(c.Examples) > 0 {
+		for i, ex := range c.Examples {
+			if i > 0 {
+				b.WriteString("\n")
+			}
+			if ex.Desc != "" {
+				b.WriteString("  " + r.dim.Sprint("# "+ex.Desc) + "\n")
+			}
+			fmt.Fprintf(&b, "  $ %s\n", ex.Cmd)
+		}
+		return b.String()
+	}
+
+	if example := strings.TrimRight(cmd.Example, "\n"); example != "" {
+		for _, line := range strings.Split(example, "\n") {
+			b.WriteString("  " + strings.TrimLeft(line, " \t") + "\n")
+		}
+	}
+	return b.String()
+}
+
+// commandSection 帮助信息中的一组子命令
+type commandSection struct {
+	title    string
+	group    string // 分组 ID，未分组时为空
+	commands []*cobra.Command
+}
+
+// commandSections 按分组整理子命令，分组按添加顺序，组内按 CommandGroup 中的顺序
+// 未分组的命令放在最后的 Additional Commands 中
+func (r *helpRenderer) commandSections(cmd *cobra.Command) []commandSection {
+	var sections []commandSection
+	for _, group := range cmd.Groups() {
+		var cmds []*cobra.Command
+		for _, sub := range r.groupCommands(cmd, group.ID) {
+			if sub.IsAvailableCommand() || sub.Name() == "help" {
+				cmds = append(cmds, sub)
+			}
+		}
+		if len(cmds) > 0 {
+			sections = append(sections, commandSection{title: strings.TrimSuffix(group.Title, ":"), group: group.ID, commands: cmds})
+		}
+	}
+
+	var rest []*cobra.Command
+	for _, sub := range cmd.Commands() {
+		if sub.GroupID == "" && (sub.IsAvailableCommand() || sub.Name() == "help") {
+			rest = append(rest, sub)
+		}
+	}
+	if len(rest) > 0 {
+		title := "Available Commands"
+		if len(sections) > 0 {
+			title = "Additional Commands"
+		}
+		sections = append(sections, commandSection{title: title, commands: rest})
+	}
+	return sections
+}
+
+// groupCommands 返回分组内的命令，通过 AddGroupLogic 添加的分组保持 CommandGroup 中的顺序
+func (r *helpRenderer) groupCommands(parent *cobra.Command, groupID string) []*cobra.Command {
+	var cmds []*cobra.Command
+	if parent == r.tool.rootCmd.Command {
+		for _, group := range r.tool.groups {
+			if group.Name != groupID {
+				continue
+			}
+			for _, c := range group.Commands {
+				cmds = append(cmds, c.Command)
+			}
+			return cmds
+		}
+	}
+
+	for _, sub := range parent.Commands() {
+		if sub.GroupID == groupID {
+			cmds = append(cmds, sub)
+		}
+	}
+	return cmds
+}
+
+// Tree 渲染命令树
+func (r *helpRenderer) Tree(root *cobra.Command) string {
+	var b strings.Builder
+	b.WriteString(r.name.Sprint(root.Name()) + "\n")
+	r.writeTree(&b, root, "")
+	return b.String()
+}
+
+// treeNode 命令树中的节点，group 不为空时表示分组
+type treeNode struct {
+	group    string
+	cmd      *cobra.Command
+	children []*cobra.Command
+}
+
+// writeTree 递归渲染 cmd 的子命令
+func (r *helpRenderer) writeTree(b *strings.Builder, cmd *cobra.Command, prefix string) {
+	var nodes []treeNode
+	for _, sec := range r.commandSections(cmd) {
+		var cmds []*cobra.Command
+		for _, sub := range sec.commands {
+			if !treeBuiltin(sub) {
+				cmds = append(cmds, sub)
+			}
+		}
+		if len(cmds) == 0 {
+			continue
+		}
+
+		if sec.group == "" {
+			for _, sub := range cmds {
+				nodes = append(nodes, treeNode{cmd: sub})
+			}
+			continue
+		}
+		nodes = append(nodes, treeNode{group: sec.group, children: cmds})
+	}
+
+	for i, node := range nodes {
+		connector, childPrefix := "├── ", prefix+"│   "
+		if i == len(nodes)-1 {
+			connector, childPrefix = "└── ", prefix+"    "
+		}
+
+		if node.group == "" {
+			b.WriteString(prefix + connector + r.treeLabel(node.cmd) + "\n")
+			r.writeTree(b, node.cmd, childPrefix)
+			continue
+		}
+
+		b.WriteString(prefix + connector + r.dim.Sprintf("[%s]", node.group) + "\n")
+		for j, sub := range node.children {
+			subConnector, subPrefix := "├── ", childPrefix+"│   "
+			if j == len(node.children)-1 {
+				subConnector, subPrefix = "└── ", childPrefix+"    "
+			}
+			b.WriteString(childPrefix + subConnector + r.treeLabel(sub) + "\n")
+			r.writeTree(b, sub, subPrefix)
+		}
+	}
+}
+
+// treeLabel 命令树中命令的显示文本：命令名（别名）
+func (r *helpRenderer) treeLabel(cmd *cobra.Command) string {
+	label := r.name.Sprint(cmd.Name())
+	if len(cmd.Aliases) > 0 {
+		label += " " + r.dim.Sprintf("(%s)", strings.Join(cmd.Aliases, ", "))
+	}
+	return label
+}
+
+// treeBuiltin 命令树中不显示的内置命令
+func treeBuiltin(cmd *cobra.Command) bool {
+	switch cmd.Name() {
+	case "completion", "help", "tree", "version":
+		return true
+	}
+	return false
+}
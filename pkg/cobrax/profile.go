@@ -0,0 +1,138 @@
+package cobrax
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+)
+
+// ProfileConfigKey 配置文件中命名 profile 的配置项，选中的 profile 中的值作为标志和配置的默认值
+//
+//	profiles:
+//	  prod:
+//	    db-host: db.prod.internal
+//	    timeout: 30s
+//	    remote.groups.web: [web1, web2]
+const ProfileConfigKey = "profiles"
+
+// Profile 获取选中的 profile（--profile 或 <PREFIX>_PROFILE 环境变量），未选中时为空
+// 子命令不要定义同名的 profile 标志，否则会遮蔽全局标志
+func (t *Tool) Profile() string {
+	if profile, _ := t.rootCmd.PersistentFlags().GetString("profile"); profile != "" {
+		return profile
+	}
+	return os.Getenv(strings.ToUpper(t.envPrefix) + "_PROFILE")
+}
+
+// Profiles 获取配置文件中定义的所有 profile 名称（已排序）
+func (t *Tool) Profiles() []string {
+	profiles := viper.GetStringMap(ProfileConfigKey)
+	names := make([]string, 0, len(profiles))
+	for name := range profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// applyProfile 将选中 profile 的值合并到配置中
+// 优先级：命令行标志 > 环境变量 > profile > 配置文件 > 标志默认值
+func (t *Tool) applyProfile() error {
+	name := t.Profile()
+	if name == "" {
+		return nil
+	}
+
+	key := ProfileConfigKey + "." + name
+	if !viper.IsSet(key) {
+		names := t.Profiles()
+		if len(names) == 0 {
+			return fmt.Errorf("profile %s 不存在，配置文件中没有定义 %s", name, ProfileConfigKey)
+		}
+		return fmt.Errorf("profile %s 不存在（可用: %s）", name, strings.Join(names, ", "))
+	}
+
+	if err := viper.MergeConfigMap(viper.GetStringMap(key)); err != nil {
+		return fmt.Errorf("应用 profile %s 失败: %w", name, err)
+	}
+	return nil
+}
+
+// applyProfileFlags 将选中 profile 中与标志同名的值设置到未在命令行指定的标志上，
+// 使直接读取标志的代码（如 --timeout）也使用 profile 中的值。需要在解析秘密之后调用
+func (t *Tool) applyProfileFlags(cmd *cobra.Command) error {
+	name := t.Profile()
+	if name == "" {
+		return nil
+	}
+
+	values := viper.GetStringMap(ProfileConfigKey + "." + name)
+	var err error
+	cmd.Flags().VisitAll(func(f *pflag.Flag) {
+		if _, ok := values[strings.ToLower(f.Name)]; !ok || f.Changed || err != nil {
+			return
+		}
+		// 按 viper 的优先级取值，环境变量仍然优先于 profile
+		if e := f.Value.Set(flagString(viper.Get(f.Name))); e != nil {
+			err = fmt.Errorf("profile %s 中 %s 的值无效: %w", name, f.Name, e)
+		}
+	})
+	return err
+}
+
+// flagString 将配置值转换为标志的字符串形式，列表以逗号连接
+func flagString(value any) string {
+	switch val := value.(type) {
+	case []string:
+		return strings.Join(val, ",")
+	case []any:
+		items := make([]string, len(val))
+		for i, item := range val {
+			items[i] = fmt.Sprint(item)
+		}
+		return strings.Join(items, ",")
+	default:
+		return fmt.Sprint(value)
+	}
+}
+
+// AddProfileCommand 添加 profile 命令，列出配置文件中的 profile
+func (t *Tool) AddProfileCommand() {
+	profileCmd := &cobra.Command{
+		Use:   "profile",
+		Short: "列出配置 profile",
+		Long: fmt.Sprintf("列出配置文件 %s 下定义的 profile，使用 --profile <name> 或 %s_PROFILE 环境变量选择，"+
+			"profile 中的值作为标志和配置的默认值（命令行标志和环境变量优先）", ProfileConfigKey, strings.ToUpper(t.envPrefix)),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			names := t.Profiles()
+			if len(names) == 0 {
+				fmt.Printf("暂无 profile，在配置文件的 %s 下添加\n", ProfileConfigKey)
+				return nil
+			}
+
+			current := t.Profile()
+			w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "PROFILE\tKEYS\tCURRENT")
+			for _, name := range names {
+				var keys []string
+				if sub := viper.Sub(ProfileConfigKey + "." + name); sub != nil {
+					keys = sub.AllKeys()
+					sort.Strings(keys)
+				}
+				mark := ""
+				if name == current {
+					mark = "*"
+				}
+				fmt.Fprintf(w, "%s\t%s\t%s\n", name, strings.Join(keys, ", "), mark)
+			}
+			return w.Flush()
+		},
+	}
+	t.rootCmd.Command.AddCommand(profileCmd)
+}
@@ -0,0 +1,343 @@
+package cobrax
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/spf13/viper"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+const secretMask = "******"
+
+type (
+	// EnvResolver 解析 env://NAME，读取环境变量
+	EnvResolver struct{}
+
+	// FileResolver 解析 file:///path，读取文件内容（去掉末尾换行）
+	FileResolver struct{}
+
+	// VaultConfig Vault 配置，为空时读取 VAULT_ADDR、VAULT_TOKEN、VAULT_NAMESPACE 环境变量
+	VaultConfig struct {
+		Address   string
+		Token     string
+		Namespace string
+		Timeout   time.Duration
+	}
+
+	// VaultResolver 解析 vault://path#key，读取 Vault KV 秘密（兼容 v1 和 v2）
+	// 例如 vault://secret/data/db#password
+	VaultResolver struct {
+		config VaultConfig
+		client *http.Client
+	}
+
+	// secretStore 记录已解析的秘密，用于日志和配置输出脱敏
+	secretStore struct {
+		lock   sync.RWMutex
+		values []string
+	}
+
+	// maskCore 对日志消息和字符串字段中的秘密脱敏
+	maskCore struct {
+		zapcore.Core
+		secrets *secretStore
+	}
+)
+
+// NewVaultResolver 创建 Vault 解析器
+func NewVaultResolver(c VaultConfig) *VaultResolver {
+	if c.Timeout <= 0 {
+		c.Timeout = 10 * time.Second
+	}
+
+	return &VaultResolver{
+		config: c,
+		client: &http.Client{Timeout: c.Timeout},
+	}
+}
+
+// Scheme 实现 SecretResolver 接口
+func (r EnvResolver) Scheme() string {
+	return "env"
+}
+
+// Resolve 实现 SecretResolver 接口
+func (r EnvResolver) Resolve(ref *url.URL) (string, error) {
+	name := ref.Host + ref.Path
+	value, ok := os.LookupEnv(name)
+	if !ok {
+		return "", fmt.Errorf("环境变量 %s 未设置", name)
+	}
+	return value, nil
+}
+
+// Scheme 实现 SecretResolver 接口
+func (r FileResolver) Scheme() string {
+	return "file"
+}
+
+// Resolve 实现 SecretResolver 接口
+func (r FileResolver) Resolve(ref *url.URL) (string, error) {
+	data, err := os.ReadFile(ref.Host + ref.Path)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimRight(string(data), "\r\n"), nil
+}
+
+// Scheme 实现 SecretResolver 接口
+func (r *VaultResolver) Scheme() string {
+	return "vault"
+}
+
+// Resolve 实现 SecretResolver 接口
+func (r *VaultResolver) Resolve(ref *url.URL) (string, error) {
+	addr := firstNonEmpty(r.config.Address, os.Getenv("VAULT_ADDR"))
+	token := firstNonEmpty(r.config.Token, os.Getenv("VAULT_TOKEN"))
+	if addr == "" || token == "" {
+		return "", errors.New("未配置 Vault 地址或 Token（VAULT_ADDR/VAULT_TOKEN）")
+	}
+	if ref.Fragment == "" {
+		return "", errors.New("缺少秘密的键，格式 vault://path#key")
+	}
+
+	req, err := http.NewRequest(http.MethodGet, strings.TrimSuffix(addr, "/")+"/v1/"+ref.Host+ref.Path, nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("X-Vault-Token", token)
+	if ns := firstNonEmpty(r.config.Namespace, os.Getenv("VAULT_NAMESPACE")); ns != "" {
+		req.Header.Set("X-Vault-Namespace", ns)
+	}
+
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("Vault 返回 %s", resp.Status)
+	}
+
+	var body struct {
+		Data map[string]any `json:"data"`
+	}
+	if err = json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return "", fmt.Errorf("解析 Vault 响应失败: %w", err)
+	}
+
+	// KV v2 的秘密在 data.data 中
+	data := body.Data
+	if nested, ok := data["data"].(map[string]any); ok {
+		if _, ok = data["metadata"]; ok {
+			data = nested
+		}
+	}
+	value, ok := data[ref.Fragment]
+	if !ok {
+		return "", fmt.Errorf("秘密中没有键 %s", ref.Fragment)
+	}
+	if s, ok := value.(string); ok {
+		return s, nil
+	}
+	return fmt.Sprint(value), nil
+}
+
+// AddSecretResolver 添加秘密解析器，与已有解析器 scheme 相同时替换。
+// 默认支持 env://、file:// 和 vault://
+func (t *Tool) AddSecretResolver(resolvers ...SecretResolver) {
+	if t.secretResolvers == nil {
+		t.secretResolvers = make(map[string]SecretResolver)
+	}
+	for _, r := range resolvers {
+		t.secretResolvers[r.Scheme()] = r
+	}
+}
+
+// ResolveSecret 解析秘密引用，不是秘密引用时原样返回
+func (t *Tool) ResolveSecret(value string) (string, error) {
+	scheme, _, ok := strings.Cut(value, "://")
+	if !ok {
+		return value, nil
+	}
+	resolver, ok := t.secretResolvers[scheme]
+	if !ok {
+		return value, nil
+	}
+
+	ref, err := url.Parse(value)
+	if err != nil {
+		return "", fmt.Errorf("无效的秘密引用 %s: %w", value, err)
+	}
+	secret, err := resolver.Resolve(ref)
+	if err != nil {
+		return "", fmt.Errorf("解析秘密 %s 失败: %w", value, err)
+	}
+	t.secrets.add(secret)
+
+	return secret, nil
+}
+
+// MaskSecrets 将字符串中已解析的秘密替换为 ******
+func (t *Tool) MaskSecrets(s string) string {
+	return t.secrets.mask(s)
+}
+
+// MaskedSettings 返回所有配置项，秘密已脱敏，用于输出配置
+func (t *Tool) MaskedSettings() map[string]any {
+	return t.secrets.maskValue(viper.AllSettings()).(map[string]any)
+}
+
+// resolveSecrets 解析所有配置项和标志中的秘密引用，结果写回 viper
+// profile 中的值合并到配置后再解析，未选中的 profile 不解析
+func (t *Tool) resolveSecrets() error {
+	keys := viper.AllKeys()
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		if strings.HasPrefix(key, ProfileConfigKey+".") {
+			continue
+		}
+		switch value := viper.Get(key).(type) {
+		case string:
+			secret, err := t.ResolveSecret(value)
+			if err != nil {
+				return fmt.Errorf("%s: %w", key, err)
+			}
+			if secret != value {
+				viper.Set(key, secret)
+			}
+		case []string:
+			resolved := make([]string, len(value))
+			changed := false
+			for i, v := range value {
+				secret, err := t.ResolveSecret(v)
+				if err != nil {
+					return fmt.Errorf("%s: %w", key, err)
+				}
+				resolved[i] = secret
+				changed = changed || secret != v
+			}
+			if changed {
+				viper.Set(key, resolved)
+			}
+		}
+	}
+
+	return nil
+}
+
+// wrapLogger 为日志器添加秘密脱敏
+func (t *Tool) wrapLogger(logger *zap.Logger) *zap.Logger {
+	return logger.WithOptions(zap.WrapCore(func(core zapcore.Core) zapcore.Core {
+		return &maskCore{Core: core, secrets: t.secrets}
+	}))
+}
+
+func (c *maskCore) With(fields []zapcore.Field) zapcore.Core {
+	return &maskCore{Core: c.Core.With(c.secrets.maskFields(fields)), secrets: c.secrets}
+}
+
+func (c *maskCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+	return ce
+}
+
+func (c *maskCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	ent.Message = c.secrets.mask(ent.Message)
+	return c.Core.Write(ent, c.secrets.maskFields(fields))
+}
+
+func (s *secretStore) add(secret string) {
+	if secret == "" {
+		return
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	for _, v := range s.values {
+		if v == secret {
+			return
+		}
+	}
+	s.values = append(s.values, secret)
+	// 先替换较长的秘密，避免被其子串部分替换
+	sort.Slice(s.values, func(i, j int) bool { return len(s.values[i]) > len(s.values[j]) })
+}
+
+func (s *secretStore) mask(str string) string {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	for _, v := range s.values {
+		str = strings.ReplaceAll(str, v, secretMask)
+	}
+	return str
+}
+
+func (s *secretStore) maskFields(fields []zapcore.Field) []zapcore.Field {
+	masked := make([]zapcore.Field, len(fields))
+	for i, f := range fields {
+		switch f.Type {
+		case zapcore.StringType:
+			f.String = s.mask(f.String)
+		case zapcore.ErrorType:
+			if err, ok := f.Interface.(error); ok {
+				f = zap.String(f.Key, s.mask(err.Error()))
+			}
+		case zapcore.StringerType:
+			if v, ok := f.Interface.(fmt.Stringer); ok {
+				f = zap.String(f.Key, s.mask(v.String()))
+			}
+		}
+		masked[i] = f
+	}
+	return masked
+}
+
+func (s *secretStore) maskValue(v any) any {
+	switch val := v.(type) {
+	case string:
+		return s.mask(val)
+	case []string:
+		masked := make([]string, len(val))
+		for i, item := range val {
+			masked[i] = s.mask(item)
+		}
+		return masked
+	case []any:
+		masked := make([]any, len(val))
+		for i, item := range val {
+			masked[i] = s.maskValue(item)
+		}
+		return masked
+	case map[string]any:
+		masked := make(map[string]any, len(val))
+		for k, item := range val {
+			masked[k] = s.maskValue(item)
+		}
+		return masked
+	default:
+		return v
+	}
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
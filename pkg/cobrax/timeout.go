@@ -0,0 +1,65 @@
+package cobrax
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// TimeoutExitCode 命令超时时的退出码（与 timeout 命令一致）
+const TimeoutExitCode = 124
+
+// ErrTimeout 命令执行超过 --timeout，可用 errors.Is 判断
+var ErrTimeout = errors.New("命令执行超时")
+
+// Timeout 获取 --timeout 标志值（0 为不限制）
+// 子命令定义了同名标志（如单次请求的超时）时，该子命令使用自己的标志，不受全局超时限制
+func (t *Tool) Timeout() time.Duration {
+	timeout, _ := t.rootCmd.PersistentFlags().GetDuration("timeout")
+	return timeout
+}
+
+// runWithTimeout 设置了 --timeout 时为命令的 context 设置截止时间并执行
+// 命令应通过 cmd.Context() 响应取消；未响应时到期后直接返回 ErrTimeout，不等待命令结束
+func (t *Tool) runWithTimeout(cmd *cobra.Command, run func() error) error {
+	timeout := t.Timeout()
+	if timeout <= 0 {
+		return run()
+	}
+
+	ctx, cancel := context.WithTimeout(cmd.Context(), timeout)
+	defer cancel()
+	cmd.SetContext(ctx)
+
+	done := make(chan error, 1)
+	panicked := make(chan any, 1)
+	go func() {
+		// panic 交给 Execute 处理
+		defer func() {
+			if r := recover(); r != nil {
+				panicked <- r
+			}
+		}()
+		done <- run()
+	}()
+
+	var err error
+	select {
+	case err = <-done:
+		if err == nil || !errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return err
+		}
+		err = fmt.Errorf("%w（%s）: %w", ErrTimeout, timeout, err)
+	case r := <-panicked:
+		panic(r)
+	case <-ctx.Done():
+		err = fmt.Errorf("%w（%s）", ErrTimeout, timeout)
+	}
+
+	// 超时不是用法错误，不显示使用方法
+	cmd.SilenceUsage = true
+	return err
+}
@@ -1,10 +1,13 @@
 package cobrax
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"runtime/debug"
 	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
 	"go.uber.org/zap"
@@ -28,11 +31,16 @@ func NewTool(name, version, desc string) *Tool {
 		desc:       desc,
 		errHandler: DefaultErrorHandler,
 		envPrefix:  "CLI", // 默认环境变量前缀
+		secrets:    &secretStore{},
+		commands:   map[*cobra.Command]*Command{rootCmd.Command: rootCmd},
 	}
+	tool.AddSecretResolver(EnvResolver{}, FileResolver{}, NewVaultResolver(VaultConfig{}))
 
 	tool.AddVersionCommand()
 	tool.AddTreeCommand()
+	tool.AddDescribeCommand()
 	tool.SetGlobalFlags()
+	tool.setupHelp()
 	return tool
 }
 
@@ -67,26 +75,47 @@ func (t *Tool) AddTreeCommand() {
 		Use:   "tree",
 		Short: "显示命令树形结构",
 		Run: func(cmd *cobra.Command, args []string) {
-			fmt.Println(t.PrintCommandTree())
+			fmt.Println(t.newHelpRenderer(os.Stdout).Tree(t.rootCmd.Command))
 		},
 	}
 	t.rootCmd.Command.AddCommand(treeCmd)
 }
 
+// AddConfigCommand 添加配置命令，以 JSON 输出合并后的配置（秘密已脱敏）
+func (t *Tool) AddConfigCommand() {
+	configCmd := &cobra.Command{
+		Use:   "config",
+		Short: "显示当前配置（秘密已脱敏）",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			enc := json.NewEncoder(os.Stdout)
+			enc.SetIndent("", "  ")
+			return enc.Encode(t.MaskedSettings())
+		},
+	}
+	t.rootCmd.Command.AddCommand(configCmd)
+}
+
 // SetGlobalFlags 设置全局标志
 func (t *Tool) SetGlobalFlags() {
 	t.rootCmd.PersistentFlags().BoolP("verbose", "v", false, "显示详细信息")
 	t.rootCmd.PersistentFlags().BoolP("debug", "d", false, "显示调试信息")
 	t.rootCmd.PersistentFlags().StringP("config", "c", "", "配置文件路径")
+	t.rootCmd.PersistentFlags().BoolP("yes", "y", false, "跳过所有确认")
+	t.rootCmd.PersistentFlags().String("profile", "", "使用配置文件中的 profile 作为标志默认值")
+	t.rootCmd.PersistentFlags().Duration("timeout", 0, "命令超时时间（如: 30s, 5m，0 为不限制）")
+	t.rootCmd.PersistentFlags().Duration("watch", 0, "按间隔重复执行命令并刷新输出（如: 2s，0 为只执行一次）")
+	t.rootCmd.PersistentFlags().String("watch-until", "", "满足条件时停止 --watch: success、error、change 或 match=<正则>")
+	t.rootCmd.PersistentFlags().Bool("watch-diff", false, "--watch 时高亮与上次输出不同的行")
 }
 
-// Execute 执行命令
+// Execute 执行命令，命令超时（--timeout）时返回 TimeoutExitCode
 func (t *Tool) Execute() int {
 	if t.errHandler != nil {
 		t.rootCmd.ErrHandler = t.errHandler
 	}
 
 	// 捕获panic
+	code := 0
 	done := make(chan struct{})
 	go func() {
 		defer func() {
@@ -100,16 +129,30 @@ func (t *Tool) Execute() int {
 			}
 		}()
 
+		// 展开用户别名
+		args, err := t.expandAliases(os.Args[1:])
+		if err != nil {
+			if handler := t.errHandler; handler != nil {
+				handler(err, t.rootCmd.Command)
+			}
+			close(done)
+			return
+		}
+		t.rootCmd.SetArgs(args)
+
 		if err := t.rootCmd.Command.Execute(); err != nil {
 			if handler := t.errHandler; handler != nil {
 				handler(err, t.rootCmd.Command)
 			}
+			if errors.Is(err, ErrTimeout) {
+				code = TimeoutExitCode
+			}
 		}
 		close(done)
 	}()
 
 	<-done
-	return 0
+	return code
 }
 
 // NewCommand 创建一个新的子命令
@@ -125,7 +168,11 @@ func (t *Tool) NewCommand(use, short, long string, runner CmdRunner, subCmds ...
 		validators: make(map[string][]ParamValidator),
 	}
 
-	cmd.RunE = func(cobraCmd *cobra.Command, args []string) error {
+	cmd.RunE = func(cobraCmd *cobra.Command, args []string) (err error) {
+		// 记录使用统计（未开启时不记录）
+		start := time.Now()
+		defer func() { t.recordUsage(cobraCmd.CommandPath(), start, err) }()
+
 		// 执行参数校验
 		if err := cmd.ValidateFlags(); err != nil {
 			if t.logger != nil {
@@ -137,12 +184,26 @@ func (t *Tool) NewCommand(use, short, long string, runner CmdRunner, subCmds ...
 			return err
 		}
 
+		// 确认高危操作
+		ok, err := cmd.confirm(cobraCmd, args)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			fmt.Fprintln(cobraCmd.ErrOrStderr(), "已取消")
+			return nil
+		}
+
 		// 执行命令
 		if cmd.Runner != nil {
 			if t.logger != nil {
 				t.logger.Info("执行命令", zap.String("command", cobraCmd.CommandPath()))
 			}
-			return cmd.Runner.Run(cobraCmd, args)
+			return t.runWatched(cobraCmd, args, func() error {
+				return t.runWithTimeout(cobraCmd, func() error {
+					return cmd.Runner.Run(cobraCmd, args)
+				})
+			})
 		}
 		return nil
 	}
@@ -153,6 +214,7 @@ func (t *Tool) NewCommand(use, short, long string, runner CmdRunner, subCmds ...
 		}
 	}
 
+	t.commands[cmd.Command] = cmd
 	return cmd
 }
 
@@ -172,6 +234,7 @@ func (t *Tool) AddGroupLogic(cmdGroup *CommandGroup) {
 		Title: fmt.Sprintf("%s Commands", strings.ToUpper(cmdGroup.Name[:1])+cmdGroup.Name[1:]),
 	}
 	t.rootCmd.Command.AddGroup(group)
+	t.groups = append(t.groups, cmdGroup)
 
 	for _, cmd := range cmdGroup.Commands {
 		cmd.Command.GroupID = group.ID
@@ -239,7 +302,7 @@ func (t *Tool) InitDefaultLogger(cfg LoggerConfig) error {
 	if err != nil {
 		return fmt.Errorf("初始化日志器失败: %w", err)
 	}
-	t.logger = logger
+	t.logger = t.wrapLogger(logger)
 	return nil
 }
 
@@ -1,6 +1,8 @@
 package cobrax
 
 import (
+	"net/url"
+
 	"github.com/spf13/cobra"
 	"go.uber.org/zap"
 )
@@ -18,6 +20,17 @@ type (
 		Validate(value any) error
 	}
 
+	// SecretResolver 定义秘密解析器接口，解析 <scheme>://... 形式的标志和配置值
+	SecretResolver interface {
+		Scheme() string
+		Resolve(ref *url.URL) (string, error)
+	}
+
+	// UsageExporter 定义使用统计导出器接口，每次命令执行后调用，用于集中收集
+	UsageExporter interface {
+		Export(rec UsageRecord) error
+	}
+
 	// ErrorHandler 定义错误处理函数类型
 	ErrorHandler func(err error, cmd *cobra.Command) error
 
@@ -32,6 +45,16 @@ type (
 		errHandler ErrorHandler
 		logger     *zap.Logger
 		envPrefix  string // 环境变量前缀
+		cfgFile    string // 配置文件路径
+
+		aliases  map[string]string           // 用户别名，执行前展开
+		commands map[*cobra.Command]*Command // 通过 NewCommand 创建的命令，用于渲染帮助
+		groups   []*CommandGroup             // 通过 AddGroupLogic 添加的分组，按添加顺序
+
+		secretResolvers map[string]SecretResolver // 按 scheme 索引的秘密解析器
+		secrets         *secretStore              // 已解析的秘密，用于脱敏
+
+		usage *usageTracker // 命令使用统计，未启用时为 nil
 	}
 
 	// Command 是对cobra.Command的包装，提供更简洁的API
@@ -39,7 +62,10 @@ type (
 		*cobra.Command
 		Runner     CmdRunner
 		ErrHandler ErrorHandler
+		Examples   []Example // 命令示例，在帮助信息中显示
 		validators map[string][]ParamValidator
+
+		confirmation *confirmation // 执行前的确认要求，为 nil 时不确认
 	}
 
 	// ==================== 辅助类型 ====================
@@ -47,6 +73,9 @@ type (
 	// CmdRunnerFunc 是函数类型的CmdRunner实现
 	CmdRunnerFunc func(cmd *cobra.Command, args []string) error
 
+	// UsageExporterFunc 是函数类型的UsageExporter实现
+	UsageExporterFunc func(rec UsageRecord) error
+
 	// Flag 标志定义
 	Flag struct {
 		Name         string
@@ -55,6 +84,12 @@ type (
 		Usage        string
 	}
 
+	// Example 命令示例
+	Example struct {
+		Desc string `json:"desc"` // 说明
+		Cmd  string `json:"cmd"`  // 完整命令行
+	}
+
 	// CommandGroup 命令组
 	CommandGroup struct {
 		Name     string
@@ -110,3 +145,8 @@ type (
 func (f CmdRunnerFunc) Run(cmd *cobra.Command, args []string) error {
 	return f(cmd, args)
 }
+
+// Export 实现UsageExporter接口
+func (f UsageExporterFunc) Export(rec UsageRecord) error {
+	return f(rec)
+}
@@ -0,0 +1,280 @@
+package cobrax
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"github.com/tedwangl/go-util/pkg/utils/humanize"
+	"go.uber.org/zap"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// UsageConfigKey 命令使用统计的开关配置项（配置文件 telemetry: true 或环境变量 <PREFIX>_TELEMETRY=true）
+const UsageConfigKey = "telemetry"
+
+type (
+	// UsageRecord 一次命令执行记录（只记录命令路径、耗时和是否成功，不记录参数）
+	UsageRecord struct {
+		ID        uint          `gorm:"primarykey" json:"-"`
+		Command   string        `gorm:"index;size:191;not null" json:"command"`
+		Duration  time.Duration `json:"duration"`
+		Success   bool          `json:"success"`
+		CreatedAt time.Time     `gorm:"index" json:"created_at"`
+	}
+
+	// UsageStats 单个命令的聚合统计
+	UsageStats struct {
+		Command     string        `json:"command"`
+		Count       int64         `json:"count"`
+		Errors      int64         `json:"errors"`
+		ErrorRate   float64       `json:"error_rate"`
+		AvgDuration time.Duration `json:"avg_duration"`
+		LastUsed    time.Time     `json:"last_used"`
+	}
+
+	// UsageStore 命令使用统计存储（本地 SQLite）
+	UsageStore struct {
+		db *gorm.DB
+	}
+
+	// usageTracker 命令使用统计记录器，数据库在首次记录时打开
+	usageTracker struct {
+		dbPath    string
+		exporters []UsageExporter
+
+		once  sync.Once
+		store *UsageStore
+		err   error
+	}
+)
+
+// OpenUsageStore 打开命令使用统计存储
+func OpenUsageStore(dbPath string) (*UsageStore, error) {
+	if err := os.MkdirAll(filepath.Dir(dbPath), 0755); err != nil {
+		return nil, fmt.Errorf("创建目录失败: %w", err)
+	}
+
+	return newUsageStore(sqlite.Open(dbPath))
+}
+
+// newUsageStore 打开数据库并迁移表结构
+func newUsageStore(dialector gorm.Dialector) (*UsageStore, error) {
+	db, err := gorm.Open(dialector, &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("打开数据库失败: %w", err)
+	}
+
+	if err := db.AutoMigrate(&UsageRecord{}); err != nil {
+		return nil, fmt.Errorf("数据库迁移失败: %w", err)
+	}
+
+	return &UsageStore{db: db}, nil
+}
+
+// Record 保存一次命令执行记录
+func (s *UsageStore) Record(rec *UsageRecord) error {
+	return s.db.Create(rec).Error
+}
+
+// Stats 统计 since 之后的命令使用情况，按执行次数降序，limit<=0 时返回全部
+func (s *UsageStore) Stats(since time.Time, limit int) ([]UsageStats, error) {
+	var records []UsageRecord
+	if err := s.db.Where("created_at >= ?", since).Find(&records).Error; err != nil {
+		return nil, fmt.Errorf("查询使用记录失败: %w", err)
+	}
+
+	stats := aggregateUsage(records)
+	if limit > 0 && len(stats) > limit {
+		stats = stats[:limit]
+	}
+	return stats, nil
+}
+
+// Prune 删除 before 之前的记录，返回删除的条数
+func (s *UsageStore) Prune(before time.Time) (int64, error) {
+	result := s.db.Where("created_at < ?", before).Delete(&UsageRecord{})
+	return result.RowsAffected, result.Error
+}
+
+// Close 关闭数据库连接
+func (s *UsageStore) Close() error {
+	sqlDB, err := s.db.DB()
+	if err != nil {
+		return err
+	}
+	return sqlDB.Close()
+}
+
+// aggregateUsage 按命令聚合执行记录
+func aggregateUsage(records []UsageRecord) []UsageStats {
+	index := make(map[string]*UsageStats)
+	total := make(map[string]time.Duration)
+	for _, rec := range records {
+		st, ok := index[rec.Command]
+		if !ok {
+			st = &UsageStats{Command: rec.Command}
+			index[rec.Command] = st
+		}
+		st.Count++
+		if !rec.Success {
+			st.Errors++
+		}
+		total[rec.Command] += rec.Duration
+		if rec.CreatedAt.After(st.LastUsed) {
+			st.LastUsed = rec.CreatedAt
+		}
+	}
+
+	stats := make([]UsageStats, 0, len(index))
+	for cmd, st := range index {
+		st.ErrorRate = float64(st.Errors) / float64(st.Count)
+		st.AvgDuration = total[cmd] / time.Duration(st.Count)
+		stats = append(stats, *st)
+	}
+	sort.Slice(stats, func(i, j int) bool {
+		if stats[i].Count != stats[j].Count {
+			return stats[i].Count > stats[j].Count
+		}
+		return stats[i].Command < stats[j].Command
+	})
+	return stats
+}
+
+// EnableUsageStats 启用命令使用统计（仍需用户在配置中设置 telemetry: true 才会记录）
+// 数据保存在本地 dbPath，exporters 在每次记录后调用，用于团队集中收集
+func (t *Tool) EnableUsageStats(dbPath string, exporters ...UsageExporter) {
+	t.usage = &usageTracker{dbPath: dbPath, exporters: exporters}
+}
+
+// AddUsageExporter 添加使用统计导出器
+func (t *Tool) AddUsageExporter(exporters ...UsageExporter) {
+	if t.usage != nil {
+		t.usage.exporters = append(t.usage.exporters, exporters...)
+	}
+}
+
+// UsageStatsEnabled 判断是否记录命令使用统计（已调用 EnableUsageStats 且用户已开启）
+func (t *Tool) UsageStatsEnabled() bool {
+	return t.usage != nil && viper.GetBool(UsageConfigKey)
+}
+
+// recordUsage 记录一次命令执行，失败只记日志，不影响命令结果
+func (t *Tool) recordUsage(command string, start time.Time, runErr error) {
+	if !t.UsageStatsEnabled() {
+		return
+	}
+
+	rec := &UsageRecord{
+		Command:   command,
+		Duration:  time.Since(start),
+		Success:   runErr == nil,
+		CreatedAt: start,
+	}
+
+	u := t.usage
+	u.once.Do(func() {
+		u.store, u.err = OpenUsageStore(u.dbPath)
+	})
+	if u.err != nil {
+		t.Debug("打开使用统计存储失败", zap.Error(u.err))
+	} else if err := u.store.Record(rec); err != nil {
+		t.Debug("保存使用统计失败", zap.Error(err))
+	}
+
+	for _, exporter := range u.exporters {
+		if err := exporter.Export(*rec); err != nil {
+			t.Debug("导出使用统计失败", zap.Error(err))
+		}
+	}
+}
+
+// AddStatsCommand 添加 stats 命令，显示最常用的命令、错误率和平均耗时
+func (t *Tool) AddStatsCommand() {
+	statsCmd := &cobra.Command{
+		Use:   "stats",
+		Short: "显示命令使用统计",
+		Long:  fmt.Sprintf("显示命令使用统计（需在配置中设置 %s: true 开启，数据只保存在本地）", UsageConfigKey),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if t.usage == nil {
+				return fmt.Errorf("未启用命令使用统计")
+			}
+
+			sinceStr, _ := cmd.Flags().GetString("since")
+			limit, _ := cmd.Flags().GetInt("limit")
+			asJSON, _ := cmd.Flags().GetBool("json")
+			prune, _ := cmd.Flags().GetBool("prune")
+
+			window, err := humanize.ParseDuration(sinceStr)
+			if err != nil {
+				return fmt.Errorf("无效的时间范围: %w", err)
+			}
+			since := time.Now().Add(-window)
+
+			if _, err := os.Stat(t.usage.dbPath); os.IsNotExist(err) {
+				if !t.UsageStatsEnabled() {
+					fmt.Printf("命令使用统计未开启，在配置中设置 %s: true 开启\n", UsageConfigKey)
+				} else {
+					fmt.Println("暂无使用记录")
+				}
+				return nil
+			}
+
+			store, err := OpenUsageStore(t.usage.dbPath)
+			if err != nil {
+				return err
+			}
+			defer store.Close()
+
+			if prune {
+				n, err := store.Prune(since)
+				if err != nil {
+					return fmt.Errorf("清理使用记录失败: %w", err)
+				}
+				fmt.Printf("已清理 %d 条 %s 之前的记录\n", n, since.Format(time.DateTime))
+				return nil
+			}
+
+			stats, err := store.Stats(since, limit)
+			if err != nil {
+				return err
+			}
+
+			if asJSON {
+				enc := json.NewEncoder(os.Stdout)
+				enc.SetIndent("", "  ")
+				return enc.Encode(stats)
+			}
+
+			if len(stats) == 0 {
+				fmt.Println("暂无使用记录")
+				return nil
+			}
+
+			w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "COMMAND\tCOUNT\tERRORS\tERROR RATE\tAVG TIME\tLAST USED")
+			for _, st := range stats {
+				fmt.Fprintf(w, "%s\t%d\t%d\t%.1f%%\t%s\t%s\n",
+					st.Command, st.Count, st.Errors, st.ErrorRate*100,
+					st.AvgDuration.Round(time.Millisecond), humanize.Ago(st.LastUsed))
+			}
+			return w.Flush()
+		},
+	}
+	statsCmd.Flags().String("since", "30d", "统计时间范围（如 24h、7d）")
+	statsCmd.Flags().Int("limit", 20, "显示的命令数量（0 表示全部）")
+	statsCmd.Flags().Bool("json", false, "以 JSON 输出")
+	statsCmd.Flags().Bool("prune", false, "删除时间范围之前的记录")
+	t.rootCmd.Command.AddCommand(statsCmd)
+}
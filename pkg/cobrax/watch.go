@@ -0,0 +1,260 @@
+package cobrax
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"regexp"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+)
+
+// watchCondition 监视的停止条件，prev 为上一次的输出（第一次执行时为 nil）
+type watchCondition func(prev, output []byte, err error) bool
+
+// watchScreen 渲染监视模式的输出
+type watchScreen struct {
+	out      *os.File
+	terminal bool // 输出到终端，每次清屏重绘
+	raw      bool // 终端处于 raw 模式，换行需要输出 \r\n
+	diff     bool // 高亮与上次输出不同的行
+	header   *color.Color
+	changed  *color.Color
+	failed   *color.Color
+}
+
+// WatchInterval 获取 --watch 标志值（0 为不重复执行）
+func (t *Tool) WatchInterval() time.Duration {
+	interval, _ := t.rootCmd.PersistentFlags().GetDuration("watch")
+	return interval
+}
+
+// runWatched 设置了 --watch 时按间隔重复执行命令并刷新输出，否则只执行一次
+//
+// 每次执行都会捕获命令写到 stdout、stderr 的内容，输出到终端时清屏重绘。
+// 终端中按 q、Esc 或 Ctrl+C 退出，空格或回车立即重新执行；满足 --watch-until 时停止并返回最后一次的结果。
+// --timeout 作用于每一次执行
+func (t *Tool) runWatched(cmd *cobra.Command, args []string, run func() error) error {
+	interval := t.WatchInterval()
+	if interval <= 0 {
+		return run()
+	}
+
+	flags := t.rootCmd.PersistentFlags()
+	untilExpr, _ := flags.GetString("watch-until")
+	until, err := parseWatchUntil(untilExpr)
+	if err != nil {
+		return err
+	}
+	diff, _ := flags.GetBool("watch-diff")
+
+	ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	screen := newWatchScreen(os.Stdout, diff)
+	refresh, restore := watchKeys(cancel)
+	defer restore()
+	screen.raw = refresh != nil
+
+	title := strings.Join(append([]string{cmd.CommandPath()}, args...), " ")
+	var prev []byte
+	for n := 1; ; n++ {
+		// --timeout 以 cmd.Context() 为父 context，每次执行前重置
+		cmd.SetContext(ctx)
+		output, err := captureOutput(cmd, run)
+		if ctx.Err() != nil {
+			return nil
+		}
+
+		screen.render(title, interval, n, prev, output, err)
+		if until != nil && until(prev, output, err) {
+			return err
+		}
+		prev = output
+
+		timer := time.NewTimer(interval)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil
+		case <-refresh:
+			timer.Stop()
+		case <-timer.C:
+		}
+	}
+}
+
+// parseWatchUntil 解析 --watch-until：success、error、change 或 match=<正则>
+func parseWatchUntil(expr string) (watchCondition, error) {
+	switch expr {
+	case "":
+		return nil, nil
+	case "success":
+		return func(_, _ []byte, err error) bool { return err == nil }, nil
+	case "error":
+		return func(_, _ []byte, err error) bool { return err != nil }, nil
+	case "change":
+		return func(prev, output []byte, _ error) bool {
+			return prev != nil && !bytes.Equal(prev, output)
+		}, nil
+	}
+
+	pattern, ok := strings.CutPrefix(expr, "match=")
+	if !ok {
+		return nil, fmt.Errorf("无效的 --watch-until: %s（支持 success、error、change、match=<正则>）", expr)
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("无效的 --watch-until 正则 %q: %w", pattern, err)
+	}
+	return func(_, output []byte, _ error) bool { return re.Match(output) }, nil
+}
+
+// watchKeys stdin 是终端时切换到 raw 模式读取按键：q、Esc、Ctrl+C 调用 quit，空格、回车发送到 refresh
+// stdin 不是终端时 refresh 为 nil，只能通过 Ctrl+C（SIGINT）退出
+func watchKeys(quit func()) (refresh <-chan struct{}, restore func()) {
+	fd := int(os.Stdin.Fd())
+	if !term.IsTerminal(fd) || !term.IsTerminal(int(os.Stdout.Fd())) {
+		return nil, func() {}
+	}
+	state, err := term.MakeRaw(fd)
+	if err != nil {
+		return nil, func() {}
+	}
+
+	ch := make(chan struct{}, 1)
+	go func() {
+		buf := make([]byte, 16)
+		for {
+			n, err := os.Stdin.Read(buf)
+			if err != nil {
+				return
+			}
+			for _, b := range buf[:n] {
+				switch b {
+				case 'q', 'Q', 0x1b, 0x03:
+					quit()
+					return
+				case ' ', '\r', '\n':
+					select {
+					case ch <- struct{}{}:
+					default:
+					}
+				}
+			}
+		}
+	}()
+	return ch, func() { term.Restore(fd, state) }
+}
+
+// captureOutput 执行 run，返回期间写到 os.Stdout、os.Stderr 和命令输出的内容
+func captureOutput(cmd *cobra.Command, run func() error) (output []byte, err error) {
+	r, w, err := os.Pipe()
+	if err != nil {
+		return nil, run()
+	}
+
+	done := make(chan []byte, 1)
+	go func() {
+		data, _ := io.ReadAll(r)
+		r.Close()
+		done <- data
+	}()
+
+	stdout, stderr := os.Stdout, os.Stderr
+	os.Stdout, os.Stderr = w, w
+	cmd.SetOut(w)
+	cmd.SetErr(w)
+	defer func() {
+		os.Stdout, os.Stderr = stdout, stderr
+		cmd.SetOut(nil)
+		cmd.SetErr(nil)
+		w.Close()
+		output = <-done
+	}()
+
+	return nil, run()
+}
+
+// newWatchScreen 创建监视输出，out 不是终端时不清屏、不使用颜色，每次的输出依次追加
+func newWatchScreen(out *os.File, diff bool) *watchScreen {
+	s := &watchScreen{
+		out:      out,
+		terminal: term.IsTerminal(int(out.Fd())),
+		diff:     diff,
+		header:   color.New(color.Faint),
+		changed:  color.New(color.ReverseVideo),
+		failed:   color.New(color.FgRed),
+	}
+	for _, c := range []*color.Color{s.header, s.changed, s.failed} {
+		if useColor(out) {
+			c.EnableColor()
+		} else {
+			c.DisableColor()
+		}
+	}
+	return s
+}
+
+// render 输出第 n 次执行的结果
+func (s *watchScreen) render(title string, interval time.Duration, n int, prev, output []byte, err error) {
+	var b strings.Builder
+	if s.terminal {
+		b.WriteString("\033[H\033[2J")
+	} else if n > 1 {
+		b.WriteString("\n")
+	}
+
+	header := fmt.Sprintf("每 %s 执行: %s    第 %d 次  %s", interval, title, n, time.Now().Format("15:04:05"))
+	if s.raw {
+		header += "    q 退出，空格刷新"
+	}
+	b.WriteString(s.header.Sprint(header) + "\n\n")
+
+	lines := splitLines(output)
+	prevLines := splitLines(prev)
+	limit := len(lines)
+	if s.terminal {
+		// 预留标题和错误信息的行数
+		if _, height, err := term.GetSize(int(s.out.Fd())); err == nil && height > 4 && limit > height-4 {
+			limit = height - 4
+		}
+	}
+	for i, line := range lines[:limit] {
+		if s.diff && prev != nil && (i >= len(prevLines) || prevLines[i] != line) {
+			line = s.changed.Sprint(line)
+		}
+		b.WriteString(line + "\n")
+	}
+	if limit < len(lines) {
+		b.WriteString(s.header.Sprintf("... 省略 %d 行", len(lines)-limit) + "\n")
+	}
+	if err != nil {
+		b.WriteString(s.failed.Sprintf("Error: %v", err) + "\n")
+	}
+
+	text := b.String()
+	if s.raw {
+		text = strings.ReplaceAll(text, "\n", "\r\n")
+	}
+	io.WriteString(s.out, text)
+}
+
+// splitLines 按行拆分输出（忽略末尾换行）
+func splitLines(output []byte) []string {
+	text := strings.TrimRight(string(output), "\n")
+	if text == "" {
+		return nil
+	}
+	return strings.Split(text, "\n")
+}
@@ -0,0 +1,161 @@
+package collyx
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gocolly/colly/v2"
+	"github.com/tedwangl/go-util/pkg/collyx/storage"
+)
+
+// AssetConfig 二进制资源（图片、PDF 等）下载配置，资源保存到内容存储（需要设置 BlobType）
+//
+// 页面中匹配 Selectors 的链接会被下载（不增加深度），类型匹配 Types 且大小在限制内的响应
+// 保存到内容存储并记录 Item（图片为 ItemTypeImage，其他为 ItemTypeFile）
+type AssetConfig struct {
+	Types     []string // 保存的 MIME 类型（支持 image/* 通配），默认 image/* 和 application/pdf
+	Selectors []string // 从 HTML 页面发现资源链接的选择器（取 src 或 href），默认 img[src] 和 a[href$=".pdf"]
+	MaxSize   int64    // 单个资源的最大字节数，超过时跳过，默认 20MB
+	MinSize   int64    // 小于该字节数时跳过（如追踪像素）
+}
+
+// assetDownloader 资源下载器
+type assetDownloader struct {
+	cfg    *AssetConfig
+	client *Client
+}
+
+// newAssetDownloader 创建资源下载器，注册链接发现和大小检查（需要在内容存储之后创建）
+func newAssetDownloader(c *Client, cfg *AssetConfig) (*assetDownloader, error) {
+	if c.blobs == nil {
+		return nil, fmt.Errorf("资源下载需要配置内容存储（BlobType）")
+	}
+	if len(cfg.Types) == 0 {
+		cfg.Types = []string{"image/*", "application/pdf"}
+	}
+	if len(cfg.Selectors) == 0 {
+		cfg.Selectors = []string{"img[src]", `a[href$=".pdf"]`}
+	}
+	if cfg.MaxSize <= 0 {
+		cfg.MaxSize = 20 << 20
+	}
+
+	// 超过 MaxBodySize 的响应体会被截断，放宽到 MaxSize+1 以便识别超限的资源
+	if limit := int(cfg.MaxSize + 1); c.collector.MaxBodySize > 0 && c.collector.MaxBodySize < limit {
+		c.collector.MaxBodySize = limit
+	}
+
+	d := &assetDownloader{cfg: cfg, client: c}
+	for _, selector := range cfg.Selectors {
+		c.collector.OnHTML(selector, d.HandleElement)
+	}
+	c.collector.OnResponseHeaders(d.HandleResponseHeaders)
+	return d, nil
+}
+
+// HandleElement 下载页面中发现的资源链接（深度不变，来源页作为 Referer）
+func (d *assetDownloader) HandleElement(e *colly.HTMLElement) {
+	link := e.Attr("src")
+	if link == "" {
+		link = e.Attr("href")
+	}
+	abs := e.Request.AbsoluteURL(link)
+	if !strings.HasPrefix(abs, "http://") && !strings.HasPrefix(abs, "https://") {
+		return // 忽略 data:、javascript: 等
+	}
+
+	c := d.client
+	abs = c.normalizeURL(abs, nil)
+	if c.skipTask(abs) {
+		return
+	}
+
+	req, err := e.Request.New(http.MethodGet, abs, nil)
+	if err != nil {
+		log.Printf("[资源下载失败] URL: %s, 错误: %v", abs, err)
+		return
+	}
+	// 与来源页同深度，使用独立的上下文避免资源的 blobKey 等写入来源页
+	req.Depth = e.Request.Depth
+	req.Ctx = colly.NewContext()
+	req.Headers.Set("Referer", e.Request.URL.String())
+
+	err = req.Do()
+	var visited *colly.AlreadyVisitedError
+	if err != nil && !errors.As(err, &visited) {
+		log.Printf("[资源下载失败] URL: %s, 错误: %v", abs, err)
+	}
+}
+
+// HandleResponseHeaders Content-Length 超过 MaxSize 的资源不下载响应体
+func (d *assetDownloader) HandleResponseHeaders(r *colly.Response) {
+	if !matchAnyMIME(d.cfg.Types, detectMIME(r.Headers.Get("Content-Type"), nil)) {
+		return
+	}
+	size, err := strconv.ParseInt(r.Headers.Get("Content-Length"), 10, 64)
+	if err == nil && size > d.cfg.MaxSize {
+		log.Printf("[跳过资源] URL: %s, 大小 %d 超过限制 %d", r.Request.URL.String(), size, d.cfg.MaxSize)
+		r.Request.Abort()
+	}
+}
+
+// save 保存匹配类型的资源，返回是否为资源响应（非资源响应交给 saveBody 按正文保存）
+func (d *assetDownloader) save(r *colly.Response) bool {
+	mediaType := ResponseMIME(r)
+	if !matchAnyMIME(d.cfg.Types, mediaType) {
+		return false
+	}
+
+	c := d.client
+	url := r.Request.URL.String()
+	size := int64(len(r.Body))
+	if size > d.cfg.MaxSize || size < d.cfg.MinSize {
+		log.Printf("[跳过资源] URL: %s, 大小 %d 不在限制范围 [%d, %d]", url, size, d.cfg.MinSize, d.cfg.MaxSize)
+		return true
+	}
+
+	key, err := c.blobs.Put(c.ctx, r.Body, mediaType)
+	if err != nil {
+		log.Printf("[保存资源失败] URL: %s, 错误: %v", url, err)
+		return true
+	}
+	r.Ctx.Put("blobKey", key)
+
+	if c.storage == nil {
+		return true
+	}
+
+	itemType := storage.ItemTypeFile
+	if strings.HasPrefix(mediaType, "image/") {
+		itemType = storage.ItemTypeImage
+	}
+	metadata := map[string]any{"content_type": mediaType}
+	if header := r.Headers.Get("Content-Type"); header != "" && !strings.HasPrefix(strings.ToLower(header), mediaType) {
+		metadata["header_content_type"] = header // 响应头与识别的类型不一致
+	}
+	if referer := r.Request.Headers.Get("Referer"); referer != "" {
+		metadata["referer"] = referer
+	}
+
+	item := &storage.Item{
+		ID:          storage.HashURL(url),
+		TaskID:      storage.HashURL(url),
+		URL:         url,
+		Type:        itemType,
+		Status:      storage.ItemStatusSaved,
+		FilePath:    storage.BlobPath(key),
+		ContentHash: key,
+		Size:        size,
+		Metadata:    metadata,
+		CreatedAt:   time.Now(),
+	}
+	if err := c.storage.SaveItem(item); err != nil {
+		log.Printf("[保存元数据失败] URL: %s, 错误: %v", url, err)
+	}
+	return true
+}
@@ -0,0 +1,142 @@
+package collyx
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/gocolly/colly/v2"
+	"github.com/tedwangl/go-util/pkg/collyx/storage"
+)
+
+func TestDetectMIME(t *testing.T) {
+	png := []byte("\x89PNG\r\n\x1a\n0000")
+	cases := []struct {
+		header string
+		body   []byte
+		want   string
+	}{
+		{"image/JPEG; charset=binary", nil, "image/jpeg"},
+		{"application/octet-stream", png, "image/png"},
+		{"", []byte("%PDF-1.7"), "application/pdf"},
+		{"", nil, ""},
+	}
+	for _, c := range cases {
+		if got := detectMIME(c.header, c.body); got != c.want {
+			t.Errorf("detectMIME(%q) 期望 %s，实际 %s", c.header, c.want, got)
+		}
+	}
+
+	if !matchMIME("image/*", "image/png") || matchMIME("image/*", "application/pdf") || !matchMIME("*/*", "text/html") {
+		t.Error("matchMIME 结果错误")
+	}
+}
+
+func TestAssetDownloader(t *testing.T) {
+	png := append([]byte("\x89PNG\r\n\x1a\n"), bytes.Repeat([]byte{1}, 100)...)
+	mux := http.NewServeMux()
+	mux.HandleFunc("/index", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		w.Write([]byte(`<a href="/page">page</a>`))
+	})
+	mux.HandleFunc("/page", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		w.Write([]byte(`<img src="/logo"><img src="/pixel.gif"><img src="data:image/png;base64,AA==">
+<a href="/docs/big.pdf">big</a><a href="/docs/small.pdf">small</a>`))
+	})
+	mux.HandleFunc("/logo", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/octet-stream") // 按内容识别为 image/png
+		w.Write(png)
+	})
+	mux.HandleFunc("/pixel.gif", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "image/gif")
+		w.Write([]byte("GIF89a"))
+	})
+	mux.HandleFunc("/docs/big.pdf", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/pdf")
+		w.Write(bytes.Repeat([]byte("%PDF"), 1024))
+	})
+	mux.HandleFunc("/docs/small.pdf", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/pdf")
+		w.Write([]byte("%PDF-1.7 small document"))
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	dir := t.TempDir()
+	cfg := DefaultConfig()
+	cfg.Parallelism = 1
+	cfg.Delay = 0
+	cfg.RandomDelay = 0
+	cfg.EnableStorage = true
+	cfg.StorageDir = dir
+	cfg.BlobType = "fs"
+	cfg.BlobDir = dir + "/blobs"
+	cfg.Assets = &AssetConfig{MaxSize: 1024, MinSize: 10}
+	cfg.MaxDepth = 2
+	cfg.OnHTML[`a[href="/page"]`] = func(e *colly.HTMLElement) {
+		e.Request.Visit(e.Attr("href"))
+	}
+
+	var mu sync.Mutex
+	var images []string
+	cfg.OnContentType["image/*"] = func(r *colly.Response) {
+		mu.Lock()
+		images = append(images, r.Request.URL.Path)
+		mu.Unlock()
+		// 资源与来源页同深度
+		if r.Request.Depth != 2 {
+			t.Errorf("资源 %s 深度应为 2，实际 %d", r.Request.URL.Path, r.Request.Depth)
+		}
+	}
+
+	client, err := NewClient(cfg)
+	if err != nil {
+		t.Fatalf("NewClient 失败: %v", err)
+	}
+	defer client.Close()
+
+	client.Visit(srv.URL + "/index")
+	client.Wait()
+
+	if len(images) != 2 {
+		t.Errorf("期望 2 个图片响应，实际 %v", images)
+	}
+
+	items, err := client.Storage().ListItems(&storage.ItemFilter{Type: []storage.ItemType{storage.ItemTypeImage, storage.ItemTypeFile}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := make(map[string]*storage.Item)
+	for _, item := range items {
+		got[item.URL] = item
+	}
+	if len(got) != 2 {
+		t.Fatalf("期望保存 2 个资源（跳过过小和过大的），实际 %d", len(got))
+	}
+
+	logo := got[srv.URL+"/logo"]
+	if logo == nil || logo.Type != storage.ItemTypeImage || logo.Size != int64(len(png)) ||
+		logo.Metadata["content_type"] != "image/png" || logo.Metadata["referer"] != srv.URL+"/page" {
+		t.Errorf("图片记录错误: %+v", logo)
+	}
+	if pdf := got[srv.URL+"/docs/small.pdf"]; pdf == nil || pdf.Type != storage.ItemTypeFile {
+		t.Errorf("PDF 记录错误: %+v", pdf)
+	}
+
+	data, err := client.BlobStore().Get(context.Background(), logo.ContentHash)
+	if err != nil || !bytes.Equal(data, png) {
+		t.Errorf("读取图片内容失败: %v", err)
+	}
+}
+
+func TestAssetDownloaderRequiresBlobStore(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Assets = &AssetConfig{}
+	if _, err := NewClient(cfg); err == nil {
+		t.Fatal("未配置内容存储时应返回错误")
+	}
+}
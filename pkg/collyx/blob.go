@@ -0,0 +1,99 @@
+package collyx
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/gocolly/colly/v2"
+	"github.com/tedwangl/go-util/pkg/collyx/storage"
+)
+
+// newBlobStore 根据配置创建内容存储
+func (c *Client) newBlobStore() (storage.BlobStore, error) {
+	switch c.config.BlobType {
+	case "fs":
+		return storage.NewFSBlobStore(c.config.BlobDir)
+	case "s3":
+		return storage.NewS3BlobStore(c.ctx, c.config.BlobS3)
+	default:
+		return nil, fmt.Errorf("不支持的内容存储类型: %s", c.config.BlobType)
+	}
+}
+
+// saveBody 保存响应正文到内容存储，启用存储时记录元数据（Item.FilePath 为内容路径）
+func (c *Client) saveBody(r *colly.Response) {
+	if len(r.Body) == 0 {
+		return
+	}
+	if c.assets != nil && c.assets.save(r) {
+		return
+	}
+
+	url := r.Request.URL.String()
+	contentType := r.Headers.Get("Content-Type")
+
+	key, err := c.blobs.Put(c.ctx, r.Body, contentType)
+	if err != nil {
+		log.Printf("[保存正文失败] URL: %s, 错误: %v", url, err)
+		return
+	}
+	r.Ctx.Put("blobKey", key)
+
+	if c.storage == nil {
+		return
+	}
+
+	itemType := storage.ItemTypeFile
+	if strings.Contains(contentType, "html") {
+		itemType = storage.ItemTypeHTML
+	}
+
+	item := &storage.Item{
+		ID:          storage.HashURL(url),
+		TaskID:      storage.HashURL(url),
+		URL:         url,
+		Type:        itemType,
+		Status:      storage.ItemStatusSaved,
+		FilePath:    storage.BlobPath(key),
+		ContentHash: key,
+		Size:        int64(len(r.Body)),
+		Metadata:    map[string]any{"content_type": contentType},
+		CreatedAt:   time.Now(),
+	}
+	if err := c.storage.SaveItem(item); err != nil {
+		log.Printf("[保存元数据失败] URL: %s, 错误: %v", url, err)
+	}
+}
+
+// runRetention 按保留策略定期清理内容，爬虫停止时退出
+func (c *Client) runRetention() {
+	interval := c.config.BlobRetention.Interval
+	if interval <= 0 {
+		interval = time.Hour
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		removed, freed, err := storage.ApplyRetention(c.ctx, c.blobs, c.config.BlobRetention)
+		if err != nil && c.ctx.Err() == nil {
+			log.Printf("[内容清理失败] 错误: %v", err)
+		} else if removed > 0 {
+			log.Printf("[内容清理] 删除 %d 个，释放 %d 字节", removed, freed)
+		}
+
+		select {
+		case <-c.ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// BlobStore 返回内容存储（如果启用）
+func (c *Client) BlobStore() storage.BlobStore {
+	return c.blobs
+}
@@ -0,0 +1,308 @@
+package collyx
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gobwas/glob"
+	"github.com/gocolly/colly/v2"
+	"github.com/tedwangl/go-util/pkg/collyx/storage"
+)
+
+// ErrBudgetExceeded 已达到抓取预算，不再接收新请求
+var ErrBudgetExceeded = errors.New("已达到抓取预算")
+
+// 停止条件
+const (
+	StopMaxPages    = "max_pages"
+	StopMaxBytes    = "max_bytes"
+	StopMaxDuration = "max_duration"
+	StopMaxErrors   = "max_errors"
+)
+
+// Budget 抓取预算（零值表示不限制），防止无人值守的抓取失控
+//
+// 任一条件达到时停止：不再发出新请求（包括重试），队列中的请求保留，进行中的请求继续完成；
+// 停止原因通过 OnStop 回调和 Client.StopReason 获取，启用存储时写入 StopRecord
+//
+//	cfg.Budget = &collyx.Budget{
+//	    MaxPages:     10000,
+//	    MaxDuration:  2 * time.Hour,
+//	    MaxErrors:    500,
+//	    DomainQuotas: map[string]int64{"*.example.com": 2000},
+//	    OnStop:       func(r *collyx.StopReason) { alert(r.Reason) },
+//	}
+type Budget struct {
+	MaxPages    int64         // 最多抓取的页面数（收到的响应）
+	MaxBytes    int64         // 最多下载的字节数（响应体）
+	MaxDuration time.Duration // 最长抓取时间（从第一个请求开始计时）
+	MaxErrors   int64         // 最多失败的请求数（每次重试都计数）
+
+	// 按域名（host 的 glob，如 *.example.com）限制页面数，达到后只跳过匹配的域名，不停止抓取
+	DomainQuotas map[string]int64
+
+	OnStop        func(reason *StopReason) // 达到预算停止时调用（只调用一次）
+	OnDomainQuota func(domain string)      // 域名达到配额时调用（每个域名规则调用一次）
+}
+
+// StopReason 停止原因和停止时的用量
+type StopReason struct {
+	Condition string        // 触发的条件（StopMaxPages 等）
+	Reason    string        // 可读的原因
+	Pages     int64         // 已抓取页面数
+	Bytes     int64         // 已下载字节数
+	Errors    int64         // 失败的请求数
+	Elapsed   time.Duration // 已抓取时间
+	At        time.Time     // 停止时间
+}
+
+// domainQuota 编译后的域名配额
+type domainQuota struct {
+	pattern string
+	glob    glob.Glob
+	limit   int64
+	used    int64
+	tripped bool
+}
+
+// crawlBudget 集中统计用量并检查停止条件
+type crawlBudget struct {
+	cfg *Budget
+
+	pages  atomic.Int64
+	bytes  atomic.Int64
+	errors atomic.Int64
+
+	startOnce sync.Once
+	start     time.Time
+	timer     *time.Timer
+
+	mu      sync.Mutex
+	quotas  []*domainQuota
+	reason  *StopReason
+	stopped atomic.Bool
+	onStop  []func(*StopReason)
+}
+
+// newCrawlBudget 创建抓取预算（越具体的域名规则越优先匹配）
+func newCrawlBudget(cfg *Budget) (*crawlBudget, error) {
+	b := &crawlBudget{cfg: cfg}
+	for pattern, limit := range cfg.DomainQuotas {
+		g, err := glob.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("域名配额 %s 无效: %w", pattern, err)
+		}
+		b.quotas = append(b.quotas, &domainQuota{pattern: pattern, glob: g, limit: limit})
+	}
+	sort.Slice(b.quotas, func(i, j int) bool {
+		wi, wj := strings.Count(b.quotas[i].pattern, "*"), strings.Count(b.quotas[j].pattern, "*")
+		if wi != wj {
+			return wi < wj
+		}
+		return len(b.quotas[i].pattern) > len(b.quotas[j].pattern)
+	})
+	if cfg.OnStop != nil {
+		b.onStop = append(b.onStop, cfg.OnStop)
+	}
+	return b, nil
+}
+
+// HandleRequest 已停止或域名配额用完时取消请求，第一个请求开始计时
+func (b *crawlBudget) HandleRequest(r *colly.Request) {
+	if b.stopped.Load() {
+		r.Abort()
+		return
+	}
+
+	b.startOnce.Do(func() {
+		b.mu.Lock()
+		b.start = time.Now()
+		if b.cfg.MaxDuration > 0 {
+			b.timer = time.AfterFunc(b.cfg.MaxDuration, func() {
+				b.trip(StopMaxDuration, fmt.Sprintf("抓取时间达到 %v", b.cfg.MaxDuration))
+			})
+		}
+		b.mu.Unlock()
+	})
+
+	if !b.takeQuota(r.URL.Hostname()) {
+		log.Printf("[跳过请求] URL: %s, 原因: 已达到域名配额", r.URL.String())
+		r.Abort()
+	}
+}
+
+// takeQuota 占用 host 所属域名规则的一个配额
+func (b *crawlBudget) takeQuota(host string) bool {
+	b.mu.Lock()
+	var tripped *domainQuota
+	allowed := true
+	for _, q := range b.quotas {
+		if !q.glob.Match(host) {
+			continue
+		}
+		if q.used >= q.limit {
+			allowed = false
+			if !q.tripped {
+				q.tripped = true
+				tripped = q
+			}
+		} else {
+			q.used++
+		}
+		break
+	}
+	b.mu.Unlock()
+
+	if tripped != nil {
+		log.Printf("[域名配额用完] 域名: %s, 配额: %d", tripped.pattern, tripped.limit)
+		if b.cfg.OnDomainQuota != nil {
+			b.cfg.OnDomainQuota(tripped.pattern)
+		}
+	}
+	return allowed
+}
+
+// HandleResponse 统计页面数和字节数
+func (b *crawlBudget) HandleResponse(r *colly.Response) {
+	pages := b.pages.Add(1)
+	bytes := b.bytes.Add(int64(len(r.Body)))
+
+	if b.cfg.MaxPages > 0 && pages >= b.cfg.MaxPages {
+		b.trip(StopMaxPages, fmt.Sprintf("页面数达到 %d", b.cfg.MaxPages))
+	}
+	if b.cfg.MaxBytes > 0 && bytes >= b.cfg.MaxBytes {
+		b.trip(StopMaxBytes, fmt.Sprintf("下载字节数达到 %d", b.cfg.MaxBytes))
+	}
+}
+
+// HandleError 统计错误数（只中止下载的请求不计数）
+func (b *crawlBudget) HandleError(r *colly.Response, err error) {
+	if errors.Is(err, colly.ErrAbortedAfterHeaders) {
+		return
+	}
+	b.bytes.Add(int64(len(r.Body)))
+	if errs := b.errors.Add(1); b.cfg.MaxErrors > 0 && errs >= b.cfg.MaxErrors {
+		b.trip(StopMaxErrors, fmt.Sprintf("失败请求数达到 %d", b.cfg.MaxErrors))
+	}
+}
+
+// trip 停止抓取（只有第一次生效）并调用回调
+func (b *crawlBudget) trip(condition, reason string) {
+	b.mu.Lock()
+	if b.reason != nil {
+		b.mu.Unlock()
+		return
+	}
+	now := time.Now()
+	b.reason = &StopReason{
+		Condition: condition,
+		Reason:    reason,
+		Pages:     b.pages.Load(),
+		Bytes:     b.bytes.Load(),
+		Errors:    b.errors.Load(),
+		Elapsed:   now.Sub(b.start),
+		At:        now,
+	}
+	b.stopped.Store(true)
+	if b.timer != nil {
+		b.timer.Stop()
+	}
+	stop, callbacks := *b.reason, b.onStop
+	b.mu.Unlock()
+
+	log.Printf("[抓取预算] %s，停止接收新请求（页面: %d, 字节: %d, 错误: %d, 耗时: %v）",
+		reason, stop.Pages, stop.Bytes, stop.Errors, stop.Elapsed.Round(time.Millisecond))
+	for _, fn := range callbacks {
+		fn(&stop)
+	}
+}
+
+// addOnStop 添加停止回调
+func (b *crawlBudget) addOnStop(fn func(*StopReason)) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.onStop = append(b.onStop, fn)
+}
+
+// stopReason 停止原因（未停止时为 nil）
+func (b *crawlBudget) stopReason() *StopReason {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.reason == nil {
+		return nil
+	}
+	reason := *b.reason
+	return &reason
+}
+
+// close 停止计时器
+func (b *crawlBudget) close() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.timer != nil {
+		b.timer.Stop()
+	}
+}
+
+// setupBudget 注册抓取预算，停止时写入存储
+func (c *Client) setupBudget() error {
+	if c.config.Budget == nil {
+		return nil
+	}
+	b, err := newCrawlBudget(c.config.Budget)
+	if err != nil {
+		return err
+	}
+	c.budget = b
+	c.collector.OnRequest(b.HandleRequest)
+	c.collector.OnResponse(b.HandleResponse)
+	c.collector.OnError(b.HandleError)
+	b.addOnStop(c.saveStopRecord)
+	return nil
+}
+
+// saveStopRecord 将停止原因写入存储
+func (c *Client) saveStopRecord(reason *StopReason) {
+	if c.storage == nil {
+		return
+	}
+	record := &storage.StopRecord{
+		Job:       c.jobName,
+		Condition: reason.Condition,
+		Reason:    reason.Reason,
+		Pages:     reason.Pages,
+		Bytes:     reason.Bytes,
+		Errors:    reason.Errors,
+		Elapsed:   reason.Elapsed,
+		CreatedAt: reason.At,
+	}
+	if err := c.storage.SaveStopRecord(record); err != nil {
+		log.Printf("[保存停止记录失败] 错误: %v", err)
+	}
+}
+
+// OnStop 注册达到抓取预算时的回调（未配置 Budget 时不会调用）
+func (c *Client) OnStop(fn func(reason *StopReason)) {
+	if c.budget != nil {
+		c.budget.addOnStop(fn)
+	}
+}
+
+// StopReason 达到抓取预算的停止原因，未停止时返回 nil
+func (c *Client) StopReason() *StopReason {
+	if c.budget == nil {
+		return nil
+	}
+	return c.budget.stopReason()
+}
+
+// budgetExceeded 是否已达到抓取预算
+func (c *Client) budgetExceeded() bool {
+	return c.budget != nil && c.budget.stopped.Load()
+}
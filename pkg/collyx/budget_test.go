@@ -0,0 +1,161 @@
+package collyx
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gocolly/colly/v2"
+	"github.com/tedwangl/go-util/pkg/collyx/storage"
+)
+
+// newBudgetClient 创建跟随链接的测试客户端
+func newBudgetClient(t *testing.T, cfg *Config) *Client {
+	t.Helper()
+	cfg.Parallelism = 1
+	cfg.Delay = 0
+	cfg.RandomDelay = 0
+	cfg.MaxDepth = 0
+	cfg.MaxRetries = 0
+
+	client, err := NewClient(cfg)
+	if err != nil {
+		t.Fatalf("NewClient 失败: %v", err)
+	}
+	t.Cleanup(func() { client.Close() })
+	client.Collector().OnHTML("a[href]", func(e *colly.HTMLElement) {
+		client.Follow(e.Request, e.Attr("href"))
+	})
+	return client
+}
+
+func TestBudget_MaxPages(t *testing.T) {
+	srv := newChainServer(10, 0)
+	defer srv.Close()
+
+	var stops atomic.Int32
+	cfg := DefaultConfig()
+	cfg.EnableStorage = true
+	cfg.StorageDir = t.TempDir()
+	cfg.Budget = &Budget{MaxPages: 3, OnStop: func(*StopReason) { stops.Add(1) }}
+	client := newBudgetClient(t, cfg)
+
+	var responses atomic.Int32
+	client.Collector().OnResponse(func(*colly.Response) { responses.Add(1) })
+
+	client.Visit(srv.URL + "/p/1")
+	client.Wait()
+
+	if n := responses.Load(); n != 3 {
+		t.Errorf("期望抓取 3 页，实际 %d", n)
+	}
+	reason := client.StopReason()
+	if reason == nil || reason.Condition != StopMaxPages || reason.Pages != 3 || reason.Bytes == 0 {
+		t.Fatalf("停止原因错误: %+v", reason)
+	}
+	if n := stops.Load(); n != 1 {
+		t.Errorf("OnStop 应调用 1 次，实际 %d", n)
+	}
+	if err := client.Visit(srv.URL + "/p/1"); !errors.Is(err, ErrBudgetExceeded) {
+		t.Errorf("停止后应拒绝新请求，实际 %v", err)
+	}
+
+	records, err := client.Storage().ListStopRecords(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 1 || records[0].Condition != StopMaxPages || records[0].Pages != 3 {
+		t.Errorf("停止记录错误: %+v", records)
+	}
+}
+
+func TestBudget_MaxErrorsAndDuration(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.NotFound(w, r)
+	}))
+	defer srv.Close()
+
+	cfg := DefaultConfig()
+	cfg.Budget = &Budget{MaxErrors: 2}
+	client := newBudgetClient(t, cfg)
+	for _, path := range []string{"/a", "/b", "/c", "/d"} {
+		client.Visit(srv.URL + path)
+	}
+	client.Wait()
+	if reason := client.StopReason(); reason == nil || reason.Condition != StopMaxErrors || reason.Errors != 2 {
+		t.Errorf("停止原因错误: %+v", reason)
+	}
+
+	slow := newChainServer(100, 20*time.Millisecond)
+	defer slow.Close()
+
+	cfg = DefaultConfig()
+	cfg.Budget = &Budget{MaxDuration: 100 * time.Millisecond}
+	client = newBudgetClient(t, cfg)
+	start := time.Now()
+	client.Visit(slow.URL + "/p/1")
+	client.Wait()
+	if reason := client.StopReason(); reason == nil || reason.Condition != StopMaxDuration {
+		t.Errorf("停止原因错误: %+v", reason)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("达到时长后应尽快停止，实际耗时 %v", elapsed)
+	}
+}
+
+func TestBudget_DomainQuota(t *testing.T) {
+	srv := newChainServer(10, 0)
+	defer srv.Close()
+
+	var domains []string
+	cfg := DefaultConfig()
+	cfg.Budget = &Budget{
+		DomainQuotas:  map[string]int64{"127.0.0.*": 4, "*": 100},
+		OnDomainQuota: func(domain string) { domains = append(domains, domain) },
+	}
+	client := newBudgetClient(t, cfg)
+
+	var responses atomic.Int32
+	client.Collector().OnResponse(func(*colly.Response) { responses.Add(1) })
+
+	client.Visit(srv.URL + "/p/1")
+	client.Wait()
+
+	if n := responses.Load(); n != 4 {
+		t.Errorf("期望抓取 4 页，实际 %d", n)
+	}
+	if len(domains) != 1 || domains[0] != "127.0.0.*" {
+		t.Errorf("域名配额回调错误: %v", domains)
+	}
+	if client.StopReason() != nil {
+		t.Error("域名配额不应停止抓取")
+	}
+}
+
+func TestJobManager_Budget(t *testing.T) {
+	srv := newChainServer(10, 0)
+	defer srv.Close()
+
+	store := newMemJobStore()
+	m := newTestJobManager(store)
+	defer m.Close()
+
+	if _, err := m.Save(JobSpec{Name: "chain", Seeds: []string{srv.URL + "/p/1"}, Overrides: JobOverrides{MaxPages: 2}}); err != nil {
+		t.Fatalf("Save 失败: %v", err)
+	}
+	if err := m.Start("chain"); err != nil {
+		t.Fatalf("Start 失败: %v", err)
+	}
+	m.Wait("chain")
+
+	job, err := m.Get("chain")
+	if err != nil {
+		t.Fatalf("Get 失败: %v", err)
+	}
+	if job.Status != storage.JobStatusCompleted || job.Responses != 2 || job.StopReason == "" {
+		t.Errorf("任务应达到预算后完成，实际状态 %s, 响应 %d, 原因 %q", job.Status, job.Responses, job.StopReason)
+	}
+}
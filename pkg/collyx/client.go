@@ -4,9 +4,9 @@ import (
 	"context"
 	"fmt"
 	"log"
-	"math"
 	"net/http"
-	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/gocolly/colly/v2"
@@ -18,10 +18,33 @@ type Client struct {
 	collector *colly.Collector
 	config    *Config
 	logger    *Logger
-	queue     *Queue
+	queue     QueueBackend
+	polite    *politeness
+	render    *renderFallback
+	metrics   *crawlMetrics
 	storage   storage.Storage
-	ctx       context.Context
-	cancel    context.CancelFunc
+	blobs     storage.BlobStore
+	jars      *CookieJars
+
+	jsonMu       sync.RWMutex
+	jsonHandlers []jsonHandler
+
+	contentMu       sync.RWMutex
+	contentHandlers []contentTypeHandler
+	assets          *assetDownloader
+
+	budget  *crawlBudget
+	jobName string // JobManager 运行时的任务名称（写入停止记录）
+
+	fetched sync.Map // 规范化后已抓取（或通过 canonical 已抓取）的 URL
+
+	paused atomic.Bool // ProcessQueue 暂停取出请求
+
+	retryPending atomic.Int64  // 等待中的延迟重试数
+	retryDone    chan struct{} // 延迟重试结束时通知 Wait
+
+	ctx    context.Context
+	cancel context.CancelFunc
 }
 
 // NewClient 创建爬虫客户端
@@ -62,22 +85,33 @@ func NewClient(cfg *Config) (*Client, error) {
 		c.SetRequestTimeout(cfg.RequestTimeout)
 	}
 
-	// 设置限流
-	if err := c.Limit(&colly.LimitRule{
+	// 创建上下文
+	ctx, cancel := context.WithCancel(context.Background())
+
+	// 设置礼貌策略
+	polite, err := newPoliteness(ctx, cfg)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+
+	// 设置限流（域名规则优先，最后是全局规则）
+	rules := append(polite.limitRules(cfg), &colly.LimitRule{
 		DomainGlob:  "*",
 		Parallelism: cfg.Parallelism,
 		Delay:       cfg.Delay,
 		RandomDelay: cfg.RandomDelay,
-	}); err != nil {
+	})
+	if err := c.Limits(rules); err != nil {
+		cancel()
 		return nil, fmt.Errorf("设置限流失败: %w", err)
 	}
 
-	// 创建上下文
-	ctx, cancel := context.WithCancel(context.Background())
-
 	client := &Client{
 		collector: c,
 		config:    cfg,
+		polite:    polite,
+		retryDone: make(chan struct{}, 1),
 		ctx:       ctx,
 		cancel:    cancel,
 	}
@@ -85,6 +119,35 @@ func NewClient(cfg *Config) (*Client, error) {
 	// 设置重定向处理器
 	client.setupRedirectHandler()
 
+	// URL 规范化需在礼貌策略之前执行（按规范化后的 URL 检查路径和去重）
+	client.setupNormalizer()
+
+	// 礼貌策略需在其他处理器之前执行
+	client.collector.OnRequest(client.polite.HandleRequest)
+
+	// 抓取预算在礼貌策略之后检查（被礼貌策略跳过的请求不占用配额）
+	if err := client.setupBudget(); err != nil {
+		cancel()
+		return nil, err
+	}
+
+	// 请求中间件需在日志等处理器之前执行（记录修改后的请求）
+	if err := client.setupMiddlewares(); err != nil {
+		cancel()
+		return nil, err
+	}
+
+	// 渲染回退需在其他响应处理器之前执行
+	if cfg.Render != nil {
+		render, err := newRenderFallback(ctx, cfg.Render, cfg.UserAgent)
+		if err != nil {
+			cancel()
+			return nil, err
+		}
+		client.render = render
+		client.collector.OnResponse(render.HandleResponse)
+	}
+
 	// 设置日志
 	if cfg.EnableLogger {
 		client.logger = NewLogger(cfg.LogLevel, cfg.LogDir)
@@ -93,26 +156,58 @@ func NewClient(cfg *Config) (*Client, error) {
 		client.setupLoggerHandlers()
 	}
 
+	// 设置指标
+	if cfg.MetricsAddr != "" {
+		client.metrics = newCrawlMetrics(client.queueSize)
+		client.collector.OnRequest(client.metrics.HandleRequest)
+		client.collector.OnResponse(client.metrics.HandleResponse)
+		client.collector.OnError(client.metrics.HandleError)
+	}
+
 	// 设置重试
 	client.setupRetryHandler()
 
 	// 设置用户自定义处理器
 	client.setupUserHandlers()
 
+	// 设置 JSON 处理器和翻页
+	if err := client.setupJSONHandlers(); err != nil {
+		cancel()
+		return nil, err
+	}
+
+	// 设置 Content-Type 处理器
+	client.setupContentTypeHandlers()
+
 	// 设置队列
 	if cfg.EnableQueue {
-		client.queue = NewQueue()
+		switch cfg.QueueType {
+		case "", "memory":
+			client.queue = NewQueue()
+		case "redis":
+			queue, err := NewRedisQueueFromConfig(cfg.QueueRedis, cfg.QueueName, cfg.QueueLease)
+			if err != nil {
+				return nil, fmt.Errorf("初始化队列失败: %w", err)
+			}
+			client.queue = queue
+		default:
+			return nil, fmt.Errorf("不支持的队列类型: %s", cfg.QueueType)
+		}
+		client.collector.OnScraped(func(r *colly.Response) {
+			client.finishLease(r.Request, true)
+		})
 	}
 
 	// 设置存储
 	if cfg.EnableStorage {
-		var err error
 		switch cfg.StorageType {
 		case "sqlite":
 			dbPath := cfg.StorageDir + "/crawler.db"
 			client.storage, err = storage.NewSQLiteStorage(dbPath)
 		case "mysql":
 			client.storage, err = storage.NewMySQLStorage(cfg.StorageDSN)
+		case "postgres":
+			client.storage, err = storage.NewPostgresStorage(cfg.StorageDSN)
 		default:
 			return nil, fmt.Errorf("不支持的存储类型: %s", cfg.StorageType)
 		}
@@ -121,9 +216,49 @@ func NewClient(cfg *Config) (*Client, error) {
 		}
 	}
 
+	// 设置内容存储（响应正文）
+	if cfg.BlobType != "" {
+		client.blobs, err = client.newBlobStore()
+		if err != nil {
+			return nil, fmt.Errorf("初始化内容存储失败: %w", err)
+		}
+		client.collector.OnResponse(client.saveBody)
+		if cfg.BlobRetention.Enabled() {
+			go client.runRetention()
+		}
+	}
+
+	// 设置资源下载（依赖内容存储）
+	if cfg.Assets != nil {
+		client.assets, err = newAssetDownloader(client, cfg.Assets)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// 设置流水线（依赖存储）
+	client.setupPipelines()
+
+	// 启动指标服务
+	if client.metrics != nil {
+		if err := client.metrics.serve(cfg.MetricsAddr, client.Progress); err != nil {
+			return nil, fmt.Errorf("启动指标服务失败: %w", err)
+		}
+	}
+
 	return client, nil
 }
 
+// setupPipelines 设置结构化抓取流水线
+func (c *Client) setupPipelines() {
+	for _, p := range c.config.Pipelines {
+		if p.storage == nil {
+			p.storage = c.storage
+		}
+		c.collector.OnResponse(p.handleResponse)
+	}
+}
+
 // setupRedirectHandler 设置重定向处理器
 func (c *Client) setupRedirectHandler() {
 	if c.config.RedirectHandler != nil {
@@ -148,74 +283,79 @@ func (c *Client) setupLoggerHandlers() {
 }
 
 // setupRetryHandler 设置重试处理器
+// 延迟优先使用 Retry-After，否则为 full jitter 指数退避；超过次数或总时长的请求写入死信
 func (c *Client) setupRetryHandler() {
 	c.collector.OnError(func(r *colly.Response, err error) {
-		// 获取重试次数
-		retryCount := 0
-		if val := r.Request.Ctx.GetAny("retryCount"); val != nil {
-			if count, ok := val.(int); ok {
-				retryCount = count
-			}
+		if !c.shouldRetry(r, err) {
+			c.finishLease(r.Request, c.ctx.Err() == nil)
+			return
 		}
 
-		// 判断是否需要重试
-		shouldRetry := false
-
-		// 检查 HTTP 状态码
-		for _, code := range c.config.RetryHTTPCodes {
-			if r.StatusCode == code {
-				shouldRetry = true
-				break
-			}
+		url := r.Request.URL.String()
+		retryCount, _ := r.Ctx.GetAny(retryKey("count", url)).(int)
+		firstError, ok := r.Ctx.GetAny(retryKey("start", url)).(time.Time)
+		if !ok {
+			firstError = time.Now()
+			r.Ctx.Put(retryKey("start", url), firstError)
 		}
 
-		// 检查超时错误
-		if c.config.RetryOnTimeout && (strings.Contains(err.Error(), "timeout") ||
-			strings.Contains(err.Error(), "context deadline exceeded")) {
-			shouldRetry = true
+		if retryCount >= c.config.MaxRetries {
+			log.Printf("[达到最大重试次数] URL: %s, 已尝试 %d 次", url, retryCount)
+			c.deadLetter(r, err, retryCount, firstError)
+			c.finishLease(r.Request, true)
+			return
 		}
 
-		// 404 不重试
-		if r.StatusCode == 404 {
-			shouldRetry = false
+		delay := c.retryDelay(r, retryCount)
+		if limit := c.config.RetryMaxElapsed; limit > 0 {
+			remaining := limit - time.Since(firstError)
+			if remaining <= 0 {
+				log.Printf("[超过最长重试时间] URL: %s, 已重试 %d 次，上限: %v", url, retryCount, limit)
+				c.deadLetter(r, err, retryCount, firstError)
+				c.finishLease(r.Request, true)
+				return
+			}
+			delay = min(delay, remaining)
 		}
 
-		// 如果需要重试且未超过最大重试次数
-		if shouldRetry && retryCount < c.config.MaxRetries {
-			// 指数退避：1s → 2s → 4s
-			delay := time.Duration(math.Pow(2, float64(retryCount))) * time.Second
-			log.Printf("[准备重试] URL: %s, 第 %d 次重试，延迟: %v",
-				r.Request.URL.String(), retryCount+1, delay)
-
-			// 创建新的上下文
-			newCtx := r.Request.Ctx
-			newCtx.Put("retryCount", retryCount+1)
-
-			// 延迟后重试
-			go func() {
-				select {
-				case <-c.ctx.Done():
-					log.Printf("[请求取消] URL: %s, 爬虫已停止", r.Request.URL.String())
-					return
-				case <-time.After(delay):
-				}
+		log.Printf("[准备重试] URL: %s, 第 %d 次重试，延迟: %v", url, retryCount+1, delay)
+		c.metrics.retry()
+		r.Ctx.Put(retryKey("count", url), retryCount+1)
+		c.scheduleRetry(r.Request, delay)
+	})
+}
 
-				if c.ctx.Err() != nil {
-					log.Printf("[请求取消] URL: %s, 爬虫已停止", r.Request.URL.String())
-					return
-				}
+// scheduleRetry 延迟后重试请求，等待期间不占用 collector 的 worker，爬虫停止时取消（队列请求放回队列）。
+// 等待中的重试计入 retryPending，Wait 在其发出并完成前不会返回
+func (c *Client) scheduleRetry(req *colly.Request, delay time.Duration) {
+	if lease := requestLease(req); lease != nil {
+		lease.retried.Store(true)
+	}
+	c.retryPending.Add(1)
+	go func() {
+		defer func() {
+			c.retryPending.Add(-1)
+			select {
+			case c.retryDone <- struct{}{}:
+			default:
+			}
+		}()
+
+		timer := time.NewTimer(delay)
+		defer timer.Stop()
+		select {
+		case <-c.ctx.Done():
+			log.Printf("[请求取消] URL: %s, 爬虫已停止", req.URL.String())
+			c.finishLease(req, false)
+			return
+		case <-timer.C:
+		}
 
-				// 重试请求
-				if err := c.collector.Request(r.Request.Method, r.Request.URL.String(),
-					r.Request.Body, newCtx, nil); err != nil {
-					log.Printf("[重试失败] URL: %s, 错误: %v", r.Request.URL.String(), err)
-				}
-			}()
-		} else if retryCount >= c.config.MaxRetries {
-			log.Printf("[达到最大重试次数] URL: %s, 已尝试 %d 次",
-				r.Request.URL.String(), c.config.MaxRetries)
+		// Retry 跳过已访问检查，异步模式下返回前已计入 collector 的等待计数
+		if err := req.Retry(); err != nil {
+			log.Printf("[重试失败] URL: %s, 错误: %v", req.URL.String(), err)
 		}
-	})
+	}()
 }
 
 // setupUserHandlers 设置用户自定义处理器
@@ -243,47 +383,114 @@ func (c *Client) setupUserHandlers() {
 
 // Visit 访问 URL
 func (c *Client) Visit(url string) error {
+	return c.visit(url, 0)
+}
+
+// visit 规范化、去重后加入队列（启用队列时）或直接访问
+func (c *Client) visit(url string, priority int) error {
 	if c.ctx.Err() != nil {
 		return fmt.Errorf("爬虫已停止: %w", c.ctx.Err())
 	}
+	if c.budgetExceeded() {
+		return ErrBudgetExceeded
+	}
+	url = c.normalizeURL(url, nil)
 
 	// 去重检查
-	if c.storage != nil {
-		skip, task, err := storage.ShouldSkipTask(c.storage, url, c.config.DuplicateStrategy)
-		if err == nil && skip {
-			log.Printf("[跳过任务] URL: %s, 原因: 已存在（状态: %s）", url, task.Status)
-			return nil
-		}
+	if c.skipTask(url) {
+		return nil
 	}
 
 	// 如果启用队列，添加到队列
-	if c.queue != nil && c.queue.IsEnabled() {
-		c.queue.Add(&Request{
+	if c.queue != nil && c.queueEnabled() {
+		return c.queue.Add(&Request{
 			URL:       url,
 			Method:    "GET",
-			Priority:  0,
+			Priority:  priority,
 			Timestamp: time.Now(),
 		})
-		return nil
 	}
 
 	// 直接访问
 	return c.collector.Visit(url)
 }
 
+// Follow 访问页面中发现的链接：相对页面 URL 解析、规范化、去重后加入队列或直接访问（深度加 1）
+//
+//	cfg.OnHTML["a[href]"] = func(e *colly.HTMLElement) {
+//	    client.Follow(e.Request, e.Attr("href"))
+//	}
+func (c *Client) Follow(r *colly.Request, link string) error {
+	if c.ctx.Err() != nil {
+		return fmt.Errorf("爬虫已停止: %w", c.ctx.Err())
+	}
+	if c.budgetExceeded() {
+		return ErrBudgetExceeded
+	}
+
+	// AbsoluteURL 处理 <base>、相对路径和协议相对 URL
+	abs := r.AbsoluteURL(link)
+	if abs == "" {
+		return nil
+	}
+	abs = c.normalizeURL(abs, nil)
+
+	if c.skipTask(abs) {
+		return nil
+	}
+
+	if c.queue != nil && c.queueEnabled() {
+		return c.queue.Add(&Request{
+			URL:       abs,
+			Method:    "GET",
+			Depth:     r.Depth + 1,
+			Timestamp: time.Now(),
+		})
+	}
+	return r.Visit(abs)
+}
+
+// skipTask 存储中已存在的任务跳过
+func (c *Client) skipTask(url string) bool {
+	if c.storage == nil {
+		return false
+	}
+	skip, task, err := storage.ShouldSkipTask(c.storage, url, c.config.DuplicateStrategy)
+	if err == nil && skip {
+		log.Printf("[跳过任务] URL: %s, 原因: 已存在（状态: %s）", url, task.Status)
+		return true
+	}
+	return false
+}
+
 // VisitWithPriority 带优先级访问 URL（需要启用队列）
 func (c *Client) VisitWithPriority(url string, priority int) error {
 	if c.queue == nil {
 		return fmt.Errorf("队列未启用")
 	}
 
-	c.queue.Add(&Request{
-		URL:       url,
+	return c.queue.Add(&Request{
+		URL:       c.normalizeURL(url, nil),
 		Method:    "GET",
 		Priority:  priority,
 		Timestamp: time.Now(),
 	})
-	return nil
+}
+
+// queueSize 队列中待处理的请求数
+func (c *Client) queueSize() int {
+	if c.queue == nil {
+		return 0
+	}
+	return c.queue.Size()
+}
+
+// queueEnabled 队列是否启用（内存队列可通过 Disable 临时关闭）
+func (c *Client) queueEnabled() bool {
+	if q, ok := c.queue.(*Queue); ok {
+		return q.IsEnabled()
+	}
+	return true
 }
 
 // ProcessQueue 处理队列（需要启用队列）
@@ -298,7 +505,22 @@ func (c *Client) ProcessQueue(stopWhenEmpty bool) error {
 			break
 		}
 
-		req := c.queue.Pop()
+		if c.paused.Load() {
+			time.Sleep(50 * time.Millisecond)
+			continue
+		}
+
+		if c.budgetExceeded() {
+			log.Println("[队列处理停止] 已达到抓取预算")
+			break
+		}
+
+		req, err := c.queue.Pop()
+		if err != nil {
+			log.Printf("[队列读取失败] 错误: %v", err)
+			time.Sleep(time.Second)
+			continue
+		}
 		if req == nil {
 			if stopWhenEmpty {
 				log.Println("[队列处理完成] 队列为空")
@@ -308,23 +530,100 @@ func (c *Client) ProcessQueue(stopWhenEmpty bool) error {
 			continue
 		}
 
-		// 执行请求
-		if err := c.executeRequest(req); err != nil {
+		// 租约在响应处理完成（OnScraped）或失败且不再重试时确认，爬虫停止时放回队列
+		lease := &queueLease{req: req, depth: 1}
+		if err := c.executeRequest(req, lease); err != nil {
 			log.Printf("[请求执行失败] URL: %s, 错误: %v", req.URL, err)
+			c.settleLease(lease, c.ctx.Err() == nil)
+			continue
+		}
+
+		// 同步模式下请求已执行完，在 OnRequest 中被中止（礼貌策略、预算等）的请求没有回调，在这里确认；
+		// 异步模式下被中止的请求在租约超时后重新入队
+		if !c.collector.Async && !lease.retried.Load() {
+			c.settleLease(lease, true)
 		}
 	}
 
 	return nil
 }
 
-// executeRequest 执行请求
-func (c *Client) executeRequest(req *Request) error {
+// queueLeaseKey 队列请求的租约在 colly 上下文中的键
+const queueLeaseKey = "queue:lease"
+
+// queueLease 从队列取出的请求的租约，只结束一次
+type queueLease struct {
+	req     *Request
+	depth   int         // 租约请求的深度，共享上下文的子请求（Request.Visit）不结束租约
+	retried atomic.Bool // 已安排重试，由重试请求结束租约
+	done    atomic.Bool
+}
+
+// requestLease 请求对应的队列租约，非队列请求或子请求返回 nil
+func requestLease(r *colly.Request) *queueLease {
+	if r == nil || r.Ctx == nil {
+		return nil
+	}
+	lease, ok := r.Ctx.GetAny(queueLeaseKey).(*queueLease)
+	if !ok || r.Depth != lease.depth {
+		return nil
+	}
+	return lease
+}
+
+// finishLease 结束请求的租约，ack 为 false 时放回队列
+func (c *Client) finishLease(r *colly.Request, ack bool) {
+	if lease := requestLease(r); lease != nil {
+		c.settleLease(lease, ack)
+	}
+}
+
+// settleLease 确认或放回租约中的请求
+func (c *Client) settleLease(lease *queueLease, ack bool) {
+	if !lease.done.CompareAndSwap(false, true) {
+		return
+	}
+
+	req := lease.req
+	if !ack {
+		if err := c.queue.Nack(req); err != nil {
+			log.Printf("[请求放回失败] URL: %s, 错误: %v", req.URL, err)
+		}
+		return
+	}
+	if err := c.queue.Ack(req); err != nil {
+		log.Printf("[请求确认失败] URL: %s, 错误: %v", req.URL, err)
+	}
+}
+
+// Pause 暂停处理队列（进行中的请求继续完成，队列中的请求保留）
+func (c *Client) Pause() {
+	if !c.paused.Swap(true) {
+		log.Println("[队列处理暂停]")
+	}
+}
+
+// Resume 继续处理队列
+func (c *Client) Resume() {
+	if c.paused.Swap(false) {
+		log.Println("[队列处理继续]")
+	}
+}
+
+// Paused 队列处理是否暂停
+func (c *Client) Paused() bool {
+	return c.paused.Load()
+}
+
+// executeRequest 执行请求，租约通过 colly 上下文传给响应处理器
+func (c *Client) executeRequest(req *Request, lease *queueLease) error {
 	if c.ctx.Err() != nil {
 		return fmt.Errorf("爬虫已停止")
 	}
 
 	// 创建 colly 上下文
 	ctx := colly.NewContext()
+	ctx.Put(queueLeaseKey, lease)
 	ctx.Put("priority", req.Priority)
 	ctx.Put("depth", req.Depth)
 	ctx.Put("timestamp", req.Timestamp.Format(time.RFC3339))
@@ -347,8 +646,22 @@ func (c *Client) Wait() {
 	waitDone := make(chan struct{})
 
 	go func() {
-		c.collector.Wait()
-		close(waitDone)
+		defer close(waitDone)
+		// 先等待延迟重试发出（同步模式下重试请求在其中执行完），再等待 collector 中的请求，
+		// 请求可能再次失败并安排新的重试，直到两者都没有剩余
+		for {
+			for c.retryPending.Load() > 0 {
+				select {
+				case <-c.retryDone:
+				case <-c.ctx.Done():
+					return
+				}
+			}
+			c.collector.Wait()
+			if c.retryPending.Load() == 0 {
+				return
+			}
+		}
 	}()
 
 	select {
@@ -378,6 +691,34 @@ func (c *Client) Close() error {
 		}
 	}
 
+	if c.metrics != nil {
+		if err := c.metrics.close(); err != nil {
+			return err
+		}
+	}
+
+	if c.budget != nil {
+		c.budget.close()
+	}
+
+	if c.render != nil {
+		if err := c.render.Close(); err != nil {
+			return err
+		}
+	}
+
+	if c.queue != nil {
+		if err := c.queue.Close(); err != nil {
+			return err
+		}
+	}
+
+	if c.blobs != nil {
+		if err := c.blobs.Close(); err != nil {
+			return err
+		}
+	}
+
 	if c.storage != nil {
 		if err := c.storage.Close(); err != nil {
 			return err
@@ -392,11 +733,22 @@ func (c *Client) Collector() *colly.Collector {
 	return c.collector
 }
 
-// Queue 返回队列（如果启用）
+// Queue 返回内存队列（如果启用且类型为 memory）
 func (c *Client) Queue() *Queue {
+	q, _ := c.queue.(*Queue)
+	return q
+}
+
+// QueueBackend 返回队列后端（如果启用）
+func (c *Client) QueueBackend() QueueBackend {
 	return c.queue
 }
 
+// CookieJars 返回按域名隔离的 Cookie Jar
+func (c *Client) CookieJars() *CookieJars {
+	return c.jars
+}
+
 // Logger 返回日志器（如果启用）
 func (c *Client) Logger() *Logger {
 	return c.logger
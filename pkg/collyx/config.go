@@ -6,6 +6,7 @@ import (
 
 	"github.com/gocolly/colly/v2"
 	"github.com/tedwangl/go-util/pkg/collyx/storage"
+	redisxconfig "github.com/tedwangl/go-util/pkg/redisx/config"
 )
 
 // Config 爬虫配置
@@ -25,13 +26,23 @@ type Config struct {
 	Delay       time.Duration // 延迟，默认 500ms
 	RandomDelay time.Duration // 随机延迟，默认 500ms
 
+	// 礼貌策略
+	DomainPolicies    map[string]DomainPolicy // 按域名覆盖限流/路径/页数（key 为域名 glob，如 *.example.com）
+	RespectCrawlDelay bool                    // 是否遵守 robots.txt 的 Crawl-delay，默认 true
+
+	// 抓取预算（nil 表示不限制），达到后停止接收新请求
+	Budget *Budget
+
 	// 重定向配置
 	MaxRedirects int // 最大重定向次数，默认 3
 
 	// 重试配置
-	MaxRetries     int   // 最大重试次数，默认 3
-	RetryHTTPCodes []int // 需要重试的 HTTP 状态码，默认 5xx 和 403
-	RetryOnTimeout bool  // 超时是否重试，默认 true
+	MaxRetries      int           // 最大重试次数，默认 3
+	RetryHTTPCodes  []int         // 需要重试的 HTTP 状态码，默认 5xx、403 和 429
+	RetryOnTimeout  bool          // 超时是否重试，默认 true
+	RetryBaseDelay  time.Duration // 退避基数，第 n 次重试在 [0, base*2^n) 内随机等待，默认 1s
+	RetryMaxDelay   time.Duration // 单次退避上限（包括 Retry-After），默认 30s
+	RetryMaxElapsed time.Duration // 单个 URL 的最长重试时间（退避不超过剩余时间），默认 10m，0 不限制
 
 	// 日志配置
 	EnableLogger bool     // 是否启用日志，默认 false
@@ -40,21 +51,56 @@ type Config struct {
 	PrintHeaders bool     // 是否打印请求头和响应头
 	PrintCookies bool     // 是否打印 Cookie
 
+	// 监控配置
+	MetricsAddr string // 指标服务地址（如 :9090），提供 /metrics 和 /progress，为空不启用
+
 	// 队列配置
-	EnableQueue bool // 是否启用队列，默认 false
+	EnableQueue bool                 // 是否启用队列，默认 false
+	QueueType   string               // 队列类型：memory/redis，默认 memory
+	QueueName   string               // 队列名称（redis），相同名称的进程共享队列，默认 collyx
+	QueueLease  time.Duration        // 租约时长（redis），超时未确认的请求重新入队，默认 5m
+	QueueRedis  *redisxconfig.Config // Redis 连接配置（redis）
 
 	// 存储配置
 	EnableStorage     bool                      // 是否启用存储，默认 false
-	StorageType       string                    // 存储类型：sqlite/mysql，默认 sqlite
+	StorageType       string                    // 存储类型：sqlite/mysql/postgres，默认 sqlite
 	StorageDir        string                    // 存储目录（sqlite），默认 ./data
-	StorageDSN        string                    // 数据库连接（mysql）
+	StorageDSN        string                    // 数据库连接（mysql/postgres）
 	DuplicateStrategy storage.DuplicateStrategy // 去重策略，默认 url
 
+	// 内容存储（保存原始响应正文，元数据仍记录在存储中）
+	BlobType      string                  // 内容存储类型：fs/s3，为空不保存
+	BlobDir       string                  // 内容目录（fs），默认 ./data/blobs
+	BlobS3        *storage.S3Config       // S3 兼容存储配置（s3）
+	BlobRetention storage.RetentionPolicy // 保留策略（按时间/容量清理）
+	Assets        *AssetConfig            // 图片、PDF 等资源下载（nil 表示不启用），需要配置 BlobType
+
+	// URL 规范化（nil 表示不启用），在去重和入队前执行
+	Normalize *URLNormalizer
+
+	// 请求中间件（按顺序执行：User-Agent 轮换 → 固定请求头 → 登录会话 → Middlewares）
+	UserAgents  []string                  // User-Agent 轮换列表，为空使用 UserAgent
+	Headers     map[string]string         // 每个请求附加的固定请求头
+	Cookies     map[string][]*http.Cookie // 初始 Cookie（域名 -> Cookie），每个注册域名使用独立的 Cookie Jar
+	Session     *SessionConfig            // 登录会话（nil 表示不启用），会话失效时自动重新登录
+	Middlewares []RequestMiddleware       // 自定义请求中间件
+
 	// 自定义处理器
-	OnRequest  []func(*colly.Request)
-	OnResponse []func(*colly.Response)
-	OnHTML     map[string]func(*colly.HTMLElement) // CSS 选择器 -> 处理函数
-	OnError    []func(*colly.Response, error)
+	OnRequest     []func(*colly.Request)
+	OnResponse    []func(*colly.Response)
+	OnHTML        map[string]func(*colly.HTMLElement) // CSS 选择器 -> 处理函数
+	OnJSON        map[string]func(*JSONElement)       // JSONPath -> 处理函数（每个匹配值调用一次）
+	OnContentType map[string]func(*colly.Response)    // MIME 类型（支持 image/* 通配）-> 处理函数
+	OnError       []func(*colly.Response, error)
+
+	// JSON 接口自动翻页（nil 表示不翻页）
+	JSONPagination *JSONPagination
+
+	// 无头浏览器渲染（nil 表示不启用）
+	Render *RenderConfig
+
+	// 结构化抓取流水线（提取 → 转换 → 去重 → 存储）
+	Pipelines []*Pipeline
 
 	// 自定义重定向处理器
 	RedirectHandler func(req *http.Request, via []*http.Request) error
@@ -70,18 +116,28 @@ func DefaultConfig() *Config {
 		Parallelism:       10,
 		Delay:             500 * time.Millisecond,
 		RandomDelay:       500 * time.Millisecond,
+		RespectCrawlDelay: true,
 		MaxRedirects:      3,
 		MaxRetries:        3,
-		RetryHTTPCodes:    []int{500, 502, 503, 504, 403},
+		RetryHTTPCodes:    []int{500, 502, 503, 504, 403, 429},
 		RetryOnTimeout:    true,
+		RetryBaseDelay:    time.Second,
+		RetryMaxDelay:     30 * time.Second,
+		RetryMaxElapsed:   10 * time.Minute,
 		EnableLogger:      false,
 		LogLevel:          LogLevelInfo,
 		LogDir:            "log",
 		EnableQueue:       false,
+		QueueType:         "memory",
+		QueueName:         "collyx",
+		QueueLease:        5 * time.Minute,
 		EnableStorage:     false,
 		StorageType:       "sqlite",
 		StorageDir:        "./data",
 		DuplicateStrategy: storage.DuplicateStrategyURL,
+		BlobDir:           "./data/blobs",
 		OnHTML:            make(map[string]func(*colly.HTMLElement)),
+		OnJSON:            make(map[string]func(*JSONElement)),
+		OnContentType:     make(map[string]func(*colly.Response)),
 	}
 }
@@ -0,0 +1,95 @@
+package collyx
+
+import (
+	"mime"
+	"net/http"
+	"strings"
+
+	"github.com/gocolly/colly/v2"
+)
+
+// contentTypeHandler OnContentType 注册的处理器
+type contentTypeHandler struct {
+	pattern string
+	fn      func(*colly.Response)
+}
+
+// OnContentType 注册按 Content-Type 匹配的响应处理器，pattern 为 MIME 类型，支持通配（如 image/*、*/*）
+//
+// 响应头缺少 Content-Type 或为 application/octet-stream 时按内容识别类型
+//
+//	client.OnContentType("image/*", func(r *colly.Response) {
+//	    r.Save(path.Base(r.Request.URL.Path))
+//	})
+func (c *Client) OnContentType(pattern string, fn func(*colly.Response)) {
+	c.contentMu.Lock()
+	defer c.contentMu.Unlock()
+	c.contentHandlers = append(c.contentHandlers, contentTypeHandler{pattern: strings.ToLower(pattern), fn: fn})
+}
+
+// setupContentTypeHandlers 注册配置中的 Content-Type 处理器
+func (c *Client) setupContentTypeHandlers() {
+	for pattern, fn := range c.config.OnContentType {
+		c.OnContentType(pattern, fn)
+	}
+	c.collector.OnResponse(c.handleContentType)
+}
+
+// handleContentType 按响应类型调用处理器
+func (c *Client) handleContentType(r *colly.Response) {
+	c.contentMu.RLock()
+	handlers := c.contentHandlers
+	c.contentMu.RUnlock()
+
+	if len(handlers) == 0 {
+		return
+	}
+
+	contentType := ResponseMIME(r)
+	for _, h := range handlers {
+		if matchMIME(h.pattern, contentType) {
+			h.fn(r)
+		}
+	}
+}
+
+// ResponseMIME 响应的 MIME 类型（不含参数，小写）
+//
+// 响应头缺少 Content-Type 或为通用二进制类型时，根据响应体前 512 字节识别
+func ResponseMIME(r *colly.Response) string {
+	return detectMIME(r.Headers.Get("Content-Type"), r.Body)
+}
+
+// detectMIME 解析 Content-Type，无法确定具体类型时按内容识别
+func detectMIME(header string, body []byte) string {
+	mediaType, _, err := mime.ParseMediaType(header)
+	if err != nil {
+		mediaType = ""
+	}
+	mediaType = strings.ToLower(mediaType)
+	if (mediaType == "" || mediaType == "application/octet-stream" || mediaType == "binary/octet-stream") && len(body) > 0 {
+		mediaType, _, _ = mime.ParseMediaType(http.DetectContentType(body))
+	}
+	return mediaType
+}
+
+// matchMIME MIME 类型是否匹配模式（支持 type/* 和 */*）
+func matchMIME(pattern, mediaType string) bool {
+	if pattern == "*/*" || pattern == "*" || pattern == mediaType {
+		return true
+	}
+	if prefix, ok := strings.CutSuffix(pattern, "/*"); ok {
+		return strings.HasPrefix(mediaType, prefix+"/")
+	}
+	return false
+}
+
+// matchAnyMIME MIME 类型是否匹配任一模式
+func matchAnyMIME(patterns []string, mediaType string) bool {
+	for _, p := range patterns {
+		if matchMIME(strings.ToLower(p), mediaType) {
+			return true
+		}
+	}
+	return false
+}
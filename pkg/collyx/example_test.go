@@ -1,12 +1,17 @@
 package collyx_test
 
 import (
+	"context"
 	"fmt"
 	"log"
+	"net/http"
+	"net/url"
 	"time"
 
 	"github.com/gocolly/colly/v2"
 	"github.com/tedwangl/go-util/pkg/collyx"
+	"github.com/tedwangl/go-util/pkg/collyx/storage"
+	redisxconfig "github.com/tedwangl/go-util/pkg/redisx/config"
 )
 
 // Example_basic 基础用法
@@ -108,6 +113,32 @@ func Example_queuePersistence() {
 	client.Wait()
 }
 
+// Example_redisQueue 分布式队列（多个进程共享同一个队列）
+func Example_redisQueue() {
+	cfg := collyx.DefaultConfig()
+	cfg.EnableQueue = true
+	cfg.QueueType = "redis"
+	cfg.QueueName = "crawler:example"
+	cfg.QueueLease = 2 * time.Minute // 进程崩溃后，2 分钟未确认的请求会被其他进程重新取出
+	cfg.QueueRedis = redisxconfig.DefaultConfig()
+	cfg.QueueRedis.Single.Addr = "127.0.0.1:6379"
+
+	client, err := collyx.NewClient(cfg)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer client.Close()
+
+	// 任意进程都可以添加种子 URL
+	client.VisitWithPriority("https://example.com", 0)
+
+	// 每个进程从共享队列中取请求
+	go client.ProcessQueue(false)
+
+	time.Sleep(time.Minute)
+	client.Stop()
+}
+
 // Example_customHandlers 自定义处理器
 func Example_customHandlers() {
 	cfg := collyx.DefaultConfig()
@@ -151,6 +182,107 @@ func Example_customHandlers() {
 	client.Wait()
 }
 
+// Example_pipeline 结构化抓取
+func Example_pipeline() {
+	cfg := collyx.DefaultConfig()
+	cfg.EnableStorage = true // 记录保存为 storage.Item（Type=data）
+
+	products := collyx.NewPipeline(collyx.NewExtractor(
+		collyx.Field("title", "h2", collyx.Required),
+		collyx.Field("price", ".price", collyx.AsFloat),
+		collyx.Field("link", "a", collyx.Attr("href")),
+	).Each(".product")).
+		Dedupe("link").
+		NearDedupe(3, "title"). // 标题几乎相同（海明距离 ≤ 3）的商品只保留一个
+		OnItem(func(r *colly.Response, rec collyx.Record) {
+			fmt.Println(rec["title"], rec["price"])
+		})
+
+	cfg.Pipelines = []*collyx.Pipeline{products}
+
+	client, _ := collyx.NewClient(cfg)
+	defer client.Close()
+
+	client.Visit("https://example.com/products")
+	client.Wait()
+}
+
+// Example_blobStore 原始正文保存到 S3，元数据保存到 PostgreSQL
+func Example_blobStore() {
+	cfg := collyx.DefaultConfig()
+	cfg.EnableStorage = true
+	cfg.StorageType = "postgres"
+	cfg.StorageDSN = "host=127.0.0.1 user=crawler password=secret dbname=crawler sslmode=disable"
+
+	cfg.BlobType = "s3" // 本地保存使用 "fs"，目录为 cfg.BlobDir
+	cfg.BlobS3 = &storage.S3Config{
+		Endpoint:  "127.0.0.1:9000",
+		AccessKey: "minioadmin",
+		SecretKey: "minioadmin",
+		Bucket:    "crawler",
+		Prefix:    "bodies",
+	}
+	cfg.BlobRetention = storage.RetentionPolicy{
+		MaxAge:   30 * 24 * time.Hour, // 保留 30 天
+		MaxBytes: 10 << 30,            // 最多 10GB
+	}
+
+	client, err := collyx.NewClient(cfg)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer client.Close()
+
+	client.Visit("https://example.com")
+	client.Wait()
+
+	// 通过元数据读取正文（ContentHash 即内容 Key）
+	items, _ := client.Storage().ListItems(&storage.ItemFilter{Type: []storage.ItemType{storage.ItemTypeHTML}})
+	for _, item := range items {
+		body, err := client.BlobStore().Get(context.Background(), item.ContentHash)
+		if err == nil {
+			fmt.Println(item.URL, len(body))
+		}
+	}
+}
+
+// Example_session 登录会话和请求头轮换
+func Example_session() {
+	cfg := collyx.DefaultConfig()
+	cfg.UserAgents = []string{
+		"Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36",
+		"Mozilla/5.0 (Macintosh; Intel Mac OS X 14_0) AppleWebKit/605.1.15",
+	}
+	cfg.Headers = map[string]string{"Accept-Language": "zh-CN,zh;q=0.9"}
+
+	// 表单登录：登录接口设置的 Cookie 会保存到爬虫的 Cookie Jar
+	// 页面返回 401 或被重定向到 /login 时自动重新登录并重试
+	cfg.Session = &collyx.SessionConfig{
+		Domains:   []string{"*.example.com"},
+		LoginPath: "/login",
+		Login: func(ctx context.Context, client *http.Client) (http.Header, error) {
+			resp, err := client.PostForm("https://www.example.com/login", url.Values{
+				"username": {"user"},
+				"password": {"secret"},
+			})
+			if err != nil {
+				return nil, err
+			}
+			resp.Body.Close()
+			return nil, nil
+		},
+	}
+
+	client, err := collyx.NewClient(cfg)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer client.Close()
+
+	client.Visit("https://www.example.com/account")
+	client.Wait()
+}
+
 // Example_advancedConfig 高级配置
 func Example_advancedConfig() {
 	cfg := collyx.DefaultConfig()
@@ -166,6 +298,17 @@ func Example_advancedConfig() {
 	cfg.Delay = 1 * time.Second
 	cfg.RandomDelay = 500 * time.Millisecond
 
+	// 小站单独限流，只抓取 /blog/ 下最多 200 个页面
+	cfg.DomainPolicies = map[string]collyx.DomainPolicy{
+		"www.example.com": {
+			Parallelism:  1,
+			Delay:        3 * time.Second,
+			AllowedPaths: []string{"/blog/"},
+			MaxPages:     200,
+		},
+	}
+	cfg.RespectCrawlDelay = true // 遵守 robots.txt 的 Crawl-delay
+
 	// 重试
 	cfg.MaxRetries = 5
 	cfg.RetryHTTPCodes = []int{500, 502, 503, 504, 403, 429}
@@ -176,6 +319,13 @@ func Example_advancedConfig() {
 	// 缓存
 	cfg.CacheDir = "./cache"
 
+	// SPA 页面用无头浏览器渲染（需要本机安装 Chrome）
+	cfg.Render = &collyx.RenderConfig{
+		Patterns:     []string{`^https://example\.com/app/`},
+		AutoDetect:   true, // 其他页面正文过少且包含脚本时也渲染
+		WaitSelector: "#app .loaded",
+	}
+
 	client, _ := collyx.NewClient(cfg)
 	defer client.Close()
 
@@ -0,0 +1,248 @@
+package collyx
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/tedwangl/go-util/pkg/collyx/storage"
+)
+
+// ExportFormat 导出格式
+type ExportFormat string
+
+const (
+	ExportCSV     ExportFormat = "csv"     // CSV（第一行为字段名）
+	ExportJSONL   ExportFormat = "jsonl"   // JSON Lines（每行一条内容）
+	ExportParquet ExportFormat = "parquet" // Parquet（列式存储，适合数据分析）
+)
+
+const (
+	exportChunkSize    = 500     // 每次从存储读取的内容数
+	parquetRowGroupMax = 8 << 20 // Parquet 行组大小，限制写入时的内存占用
+)
+
+// DefaultExportFields 默认导出字段（CSV、Parquet；JSONL 不指定字段时导出完整内容）
+var DefaultExportFields = []string{"id", "task_id", "url", "type", "status", "title", "content_hash", "size", "created_at", "metadata"}
+
+// Export 按过滤条件分批读取内容并写入 w，返回导出的内容数
+//
+// fields 为导出字段：内容的列名（id、url、title、content 等）、metadata（完整元数据的 JSON），
+// 其他名称从元数据中读取（流水线提取的字段），也可以写成 metadata.price
+//
+//	f, _ := os.Create("items.csv")
+//	n, err := client.Export(&storage.ItemFilter{Type: []storage.ItemType{storage.ItemTypeData}}, collyx.ExportCSV, f, "url", "title", "price")
+func (c *Client) Export(filter *storage.ItemFilter, format ExportFormat, w io.Writer, fields ...string) (int64, error) {
+	if c.storage == nil {
+		return 0, fmt.Errorf("存储未启用")
+	}
+	return Export(c.storage, filter, format, w, fields...)
+}
+
+// Export 从存储导出内容（见 Client.Export）
+func Export(store storage.Storage, filter *storage.ItemFilter, format ExportFormat, w io.Writer, fields ...string) (int64, error) {
+	var enc itemEncoder
+	var err error
+	switch format {
+	case ExportCSV:
+		enc, err = newCSVEncoder(w, exportFields(fields))
+	case ExportJSONL:
+		enc = newJSONLEncoder(w, fields)
+	case ExportParquet:
+		enc, err = newParquetEncoder(w, exportFields(fields))
+	default:
+		return 0, fmt.Errorf("不支持的导出格式: %s", format)
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	var n int64
+	err = eachItem(store, filter, func(item *storage.Item) error {
+		if err := enc.Encode(item); err != nil {
+			return fmt.Errorf("导出内容 %s 失败: %w", item.ID, err)
+		}
+		n++
+		return nil
+	})
+	if err != nil {
+		return n, err
+	}
+	if err := enc.Close(); err != nil {
+		return n, fmt.Errorf("写入导出文件失败: %w", err)
+	}
+	return n, nil
+}
+
+// eachItem 分批遍历内容（filter 的 Limit/Offset 作为总数限制和起始位置）
+func eachItem(store storage.Storage, filter *storage.ItemFilter, fn func(item *storage.Item) error) error {
+	f := storage.ItemFilter{}
+	if filter != nil {
+		f = *filter
+	}
+	total, offset := f.Limit, f.Offset
+	if f.OrderBy == "" {
+		// 分页需要稳定的顺序
+		f.OrderBy = "created_at, id"
+	}
+
+	var read int
+	for {
+		size := exportChunkSize
+		if total > 0 && total-read < size {
+			size = total - read
+		}
+		if size <= 0 {
+			return nil
+		}
+
+		f.Limit, f.Offset = size, offset+read
+		items, err := store.ListItems(&f)
+		if err != nil {
+			return fmt.Errorf("读取内容失败: %w", err)
+		}
+		for _, item := range items {
+			if err := fn(item); err != nil {
+				return err
+			}
+		}
+		read += len(items)
+		if len(items) < size {
+			return nil
+		}
+	}
+}
+
+// exportFields 未指定字段时使用默认字段
+func exportFields(fields []string) []string {
+	if len(fields) == 0 {
+		return DefaultExportFields
+	}
+	return fields
+}
+
+// itemField 读取内容的字段
+func itemField(item *storage.Item, field string) any {
+	switch field {
+	case "id":
+		return item.ID
+	case "task_id":
+		return item.TaskID
+	case "url":
+		return item.URL
+	case "type":
+		return string(item.Type)
+	case "status":
+		return string(item.Status)
+	case "title":
+		return item.Title
+	case "content":
+		return item.Content
+	case "file_path":
+		return item.FilePath
+	case "content_hash":
+		return item.ContentHash
+	case "sim_hash":
+		return item.SimHash
+	case "size":
+		return item.Size
+	case "error":
+		return item.Error
+	case "created_at":
+		return item.CreatedAt
+	case "updated_at":
+		return item.UpdatedAt
+	case "metadata":
+		if len(item.Metadata) == 0 {
+			return nil
+		}
+		return item.Metadata
+	}
+	return item.Metadata[strings.TrimPrefix(field, "metadata.")]
+}
+
+// exportString 字段值的文本形式（时间为 RFC3339，对象和数组为 JSON）
+func exportString(v any) string {
+	switch val := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return val
+	case time.Time:
+		if val.IsZero() {
+			return ""
+		}
+		return val.Format(time.RFC3339)
+	case int64:
+		return strconv.FormatInt(val, 10)
+	default:
+		return jsonScalar(val)
+	}
+}
+
+// itemEncoder 内容编码器
+type itemEncoder interface {
+	Encode(item *storage.Item) error
+	Close() error
+}
+
+// csvEncoder CSV 编码器
+type csvEncoder struct {
+	w      *csv.Writer
+	fields []string
+	row    []string
+}
+
+func newCSVEncoder(w io.Writer, fields []string) (*csvEncoder, error) {
+	e := &csvEncoder{w: csv.NewWriter(w), fields: fields, row: make([]string, len(fields))}
+	if err := e.w.Write(fields); err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+func (e *csvEncoder) Encode(item *storage.Item) error {
+	for i, f := range e.fields {
+		e.row[i] = exportString(itemField(item, f))
+	}
+	return e.w.Write(e.row)
+}
+
+func (e *csvEncoder) Close() error {
+	e.w.Flush()
+	return e.w.Error()
+}
+
+// jsonlEncoder JSON Lines 编码器
+type jsonlEncoder struct {
+	w      *bufio.Writer
+	enc    *json.Encoder
+	fields []string
+}
+
+func newJSONLEncoder(w io.Writer, fields []string) *jsonlEncoder {
+	bw := bufio.NewWriter(w)
+	enc := json.NewEncoder(bw)
+	enc.SetEscapeHTML(false)
+	return &jsonlEncoder{w: bw, enc: enc, fields: fields}
+}
+
+func (e *jsonlEncoder) Encode(item *storage.Item) error {
+	if len(e.fields) == 0 {
+		return e.enc.Encode(item)
+	}
+	record := make(map[string]any, len(e.fields))
+	for _, f := range e.fields {
+		record[f] = itemField(item, f)
+	}
+	return e.enc.Encode(record)
+}
+
+func (e *jsonlEncoder) Close() error {
+	return e.w.Flush()
+}
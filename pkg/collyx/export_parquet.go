@@ -0,0 +1,65 @@
+package collyx
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/tedwangl/go-util/pkg/collyx/storage"
+	"github.com/xitongsys/parquet-go/writer"
+)
+
+// parquetEncoder Parquet 编码器（size 为 INT64，时间为毫秒时间戳，其他字段为可空字符串）
+type parquetEncoder struct {
+	w      *writer.CSVWriter
+	fields []string
+}
+
+func newParquetEncoder(w io.Writer, fields []string) (*parquetEncoder, error) {
+	schema := make([]string, len(fields))
+	for i, f := range fields {
+		// 列名中的 . 表示嵌套结构，替换为 _
+		name := strings.ReplaceAll(f, ".", "_")
+		switch f {
+		case "size":
+			schema[i] = fmt.Sprintf("name=%s, type=INT64, repetitiontype=REQUIRED", name)
+		case "created_at", "updated_at":
+			schema[i] = fmt.Sprintf("name=%s, type=INT64, convertedtype=TIMESTAMP_MILLIS, repetitiontype=OPTIONAL", name)
+		default:
+			schema[i] = fmt.Sprintf("name=%s, type=BYTE_ARRAY, convertedtype=UTF8, repetitiontype=OPTIONAL", name)
+		}
+	}
+
+	pw, err := writer.NewCSVWriterFromWriter(schema, w, 1)
+	if err != nil {
+		return nil, fmt.Errorf("创建 Parquet 写入器失败: %w", err)
+	}
+	pw.RowGroupSize = parquetRowGroupMax
+	return &parquetEncoder{w: pw, fields: fields}, nil
+}
+
+func (e *parquetEncoder) Encode(item *storage.Item) error {
+	// 写入器在行组写出前保留每行的引用，每行使用新的切片
+	row := make([]any, len(e.fields))
+	for i, f := range e.fields {
+		v := itemField(item, f)
+		switch f {
+		case "size":
+			row[i] = item.Size
+		case "created_at", "updated_at":
+			if t, ok := v.(time.Time); ok && !t.IsZero() {
+				row[i] = t.UnixMilli()
+			}
+		default:
+			if v != nil {
+				row[i] = exportString(v)
+			}
+		}
+	}
+	return e.w.Write(row)
+}
+
+func (e *parquetEncoder) Close() error {
+	return e.w.WriteStop()
+}
@@ -0,0 +1,157 @@
+package collyx
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/tedwangl/go-util/pkg/collyx/storage"
+)
+
+// memItemStore 只实现 ListItems 的内存存储，记录每次读取的数量
+type memItemStore struct {
+	storage.Storage
+
+	items []*storage.Item
+	reads []int
+}
+
+func newMemItemStore(n int) *memItemStore {
+	s := &memItemStore{}
+	created := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	for i := 0; i < n; i++ {
+		typ := storage.ItemTypeHTML
+		if i%2 == 1 {
+			typ = storage.ItemTypeData
+		}
+		s.items = append(s.items, &storage.Item{
+			ID:        fmt.Sprintf("item-%04d", i),
+			URL:       fmt.Sprintf("https://example.com/p/%d", i),
+			Type:      typ,
+			Title:     fmt.Sprintf("标题 %d", i),
+			Size:      int64(i),
+			CreatedAt: created,
+			Metadata:  map[string]any{"price": float64(i) + 0.5, "tags": []any{"a", "b"}},
+		})
+	}
+	return s
+}
+
+func (s *memItemStore) ListItems(filter *storage.ItemFilter) ([]*storage.Item, error) {
+	var matched []*storage.Item
+	for _, item := range s.items {
+		if len(filter.Type) > 0 && item.Type != filter.Type[0] {
+			continue
+		}
+		matched = append(matched, item)
+	}
+	if filter.Offset >= len(matched) {
+		matched = nil
+	} else {
+		matched = matched[filter.Offset:]
+	}
+	if filter.Limit > 0 && len(matched) > filter.Limit {
+		matched = matched[:filter.Limit]
+	}
+	s.reads = append(s.reads, len(matched))
+	return matched, nil
+}
+
+func TestExportCSV(t *testing.T) {
+	store := newMemItemStore(3)
+
+	var buf bytes.Buffer
+	n, err := Export(store, nil, ExportCSV, &buf, "url", "title", "size", "created_at", "price", "metadata.tags")
+	if err != nil {
+		t.Fatalf("Export 失败: %v", err)
+	}
+	if n != 3 {
+		t.Errorf("应导出 3 条内容，实际 %d", n)
+	}
+
+	records, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatalf("解析 CSV 失败: %v", err)
+	}
+	if len(records) != 4 {
+		t.Fatalf("应有表头和 3 行数据，实际 %d 行", len(records))
+	}
+	if got := strings.Join(records[0], ","); got != "url,title,size,created_at,price,metadata.tags" {
+		t.Errorf("表头为 %q", got)
+	}
+	want := []string{"https://example.com/p/1", "标题 1", "1", "2024-01-02T03:04:05Z", "1.5", `["a","b"]`}
+	if got := records[2]; strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Errorf("第 2 行为 %q，期望 %q", got, want)
+	}
+}
+
+func TestExportJSONL(t *testing.T) {
+	store := newMemItemStore(4)
+	filter := &storage.ItemFilter{Type: []storage.ItemType{storage.ItemTypeData}}
+
+	var buf bytes.Buffer
+	n, err := Export(store, filter, ExportJSONL, &buf, "id", "price")
+	if err != nil {
+		t.Fatalf("Export 失败: %v", err)
+	}
+	if n != 2 {
+		t.Errorf("应导出 2 条 data 内容，实际 %d", n)
+	}
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	var record map[string]any
+	if err := json.Unmarshal([]byte(lines[0]), &record); err != nil {
+		t.Fatalf("解析 JSON 失败: %v", err)
+	}
+	if record["id"] != "item-0001" || record["price"] != 1.5 || len(record) != 2 {
+		t.Errorf("只应导出指定字段，实际 %v", record)
+	}
+
+	// 不指定字段时导出完整内容
+	buf.Reset()
+	if _, err := Export(store, filter, ExportJSONL, &buf); err != nil {
+		t.Fatalf("Export 失败: %v", err)
+	}
+	var item storage.Item
+	if err := json.Unmarshal([]byte(strings.SplitN(buf.String(), "\n", 2)[0]), &item); err != nil {
+		t.Fatalf("解析 JSON 失败: %v", err)
+	}
+	if item.URL != "https://example.com/p/1" || item.Metadata["price"] != 1.5 {
+		t.Errorf("完整内容导出不正确: %+v", item)
+	}
+}
+
+func TestExportChunked(t *testing.T) {
+	store := newMemItemStore(exportChunkSize*2 + 10)
+
+	var buf bytes.Buffer
+	n, err := Export(store, nil, ExportJSONL, &buf, "id")
+	if err != nil {
+		t.Fatalf("Export 失败: %v", err)
+	}
+	if n != int64(len(store.items)) {
+		t.Errorf("应导出 %d 条内容，实际 %d", len(store.items), n)
+	}
+	if fmt.Sprint(store.reads) != fmt.Sprint([]int{exportChunkSize, exportChunkSize, 10}) {
+		t.Errorf("应分批读取，实际每批数量 %v", store.reads)
+	}
+
+	// Limit 和 Offset 作为总数和起始位置
+	store.reads = nil
+	buf.Reset()
+	n, err = Export(store, &storage.ItemFilter{Limit: exportChunkSize + 5, Offset: 3}, ExportCSV, &buf, "id")
+	if err != nil {
+		t.Fatalf("Export 失败: %v", err)
+	}
+	if n != exportChunkSize+5 || !strings.HasPrefix(buf.String(), "id\nitem-0003\n") {
+		t.Errorf("应从第 3 条开始导出 %d 条，实际 %d 条", exportChunkSize+5, n)
+	}
+
+	if _, err := Export(store, nil, "xml", &buf); err == nil {
+		t.Error("不支持的格式应返回错误")
+	}
+}
@@ -0,0 +1,248 @@
+package collyx
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/antchfx/htmlquery"
+	"github.com/gocolly/colly/v2"
+	"golang.org/x/net/html"
+)
+
+// Record 一条结构化数据（字段名 → 转换后的值）
+type Record map[string]any
+
+// Extractor 从响应中提取结构化数据
+type Extractor interface {
+	Extract(resp *colly.Response) ([]Record, error)
+}
+
+// Converter 字段值转换
+type Converter func(raw string) (any, error)
+
+// FieldSpec 字段定义
+type FieldSpec struct {
+	Name     string    // 字段名
+	Selector string    // CSS 选择器或 XPath 表达式
+	XPath    bool      // Selector 是否为 XPath
+	Attr     string    // 取属性值，为空取文本
+	Multiple bool      // 是否取所有匹配（结果为 []any）
+	Required bool      // 必填，为空或转换失败时丢弃整条记录
+	Convert  Converter // 值转换，默认保留字符串，转换失败的值记录日志后忽略
+}
+
+// FieldOption 字段选项
+type FieldOption func(*FieldSpec)
+
+// Field 定义 CSS 字段
+//
+//	collyx.Field("title", "h1")
+//	collyx.Field("price", ".price", collyx.AsFloat)
+//	collyx.Field("link", "a.detail", collyx.Attr("href"))
+func Field(name, selector string, opts ...FieldOption) FieldSpec {
+	f := FieldSpec{Name: name, Selector: selector}
+	for _, opt := range opts {
+		opt(&f)
+	}
+	return f
+}
+
+// XPathField 定义 XPath 字段
+func XPathField(name, expr string, opts ...FieldOption) FieldSpec {
+	f := Field(name, expr, opts...)
+	f.XPath = true
+	return f
+}
+
+// Attr 取属性值
+func Attr(name string) FieldOption {
+	return func(f *FieldSpec) {
+		f.Attr = name
+	}
+}
+
+// Multiple 取所有匹配的值
+func Multiple(f *FieldSpec) {
+	f.Multiple = true
+}
+
+// Required 字段为空或转换失败时丢弃记录
+func Required(f *FieldSpec) {
+	f.Required = true
+}
+
+// Convert 自定义转换
+func Convert(fn Converter) FieldOption {
+	return func(f *FieldSpec) {
+		f.Convert = fn
+	}
+}
+
+// numberPattern 提取文本中的第一个数字（兼容 "¥1,299.00" 这类格式）
+var numberPattern = regexp.MustCompile(`-?\d[\d,]*(\.\d+)?`)
+
+// AsInt 转换为 int64
+func AsInt(f *FieldSpec) {
+	f.Convert = func(raw string) (any, error) {
+		num := strings.ReplaceAll(numberPattern.FindString(raw), ",", "")
+		if dot := strings.Index(num, "."); dot >= 0 {
+			num = num[:dot]
+		}
+		return strconv.ParseInt(num, 10, 64)
+	}
+}
+
+// AsFloat 转换为 float64
+func AsFloat(f *FieldSpec) {
+	f.Convert = func(raw string) (any, error) {
+		return strconv.ParseFloat(strings.ReplaceAll(numberPattern.FindString(raw), ",", ""), 64)
+	}
+}
+
+// AsBool 转换为 bool
+func AsBool(f *FieldSpec) {
+	f.Convert = func(raw string) (any, error) {
+		return strconv.ParseBool(strings.ToLower(raw))
+	}
+}
+
+// SelectorExtractor 基于 CSS/XPath 字段映射的提取器
+type SelectorExtractor struct {
+	root     string
+	rootPath bool
+	fields   []FieldSpec
+}
+
+// NewExtractor 创建提取器（默认整个页面提取一条记录）
+func NewExtractor(fields ...FieldSpec) *SelectorExtractor {
+	return &SelectorExtractor{fields: fields}
+}
+
+// Each 每个匹配 CSS 选择器的元素提取一条记录（列表页）
+func (e *SelectorExtractor) Each(selector string) *SelectorExtractor {
+	e.root = selector
+	e.rootPath = false
+	return e
+}
+
+// EachXPath 每个匹配 XPath 的元素提取一条记录
+func (e *SelectorExtractor) EachXPath(expr string) *SelectorExtractor {
+	e.root = expr
+	e.rootPath = true
+	return e
+}
+
+// Extract 实现 Extractor
+func (e *SelectorExtractor) Extract(resp *colly.Response) ([]Record, error) {
+	doc, err := html.Parse(bytes.NewReader(resp.Body))
+	if err != nil {
+		return nil, fmt.Errorf("解析 HTML 失败: %w", err)
+	}
+
+	roots := []*html.Node{doc}
+	if e.root != "" {
+		if e.rootPath {
+			roots, err = htmlquery.QueryAll(doc, e.root)
+			if err != nil {
+				return nil, fmt.Errorf("XPath %s 无效: %w", e.root, err)
+			}
+		} else {
+			roots = goquery.NewDocumentFromNode(doc).Find(e.root).Nodes
+		}
+	}
+
+	records := make([]Record, 0, len(roots))
+	for _, root := range roots {
+		record, err := e.extractRecord(resp.Request.URL.String(), root)
+		if err != nil {
+			return nil, err
+		}
+		if record != nil {
+			records = append(records, record)
+		}
+	}
+	return records, nil
+}
+
+// extractRecord 从单个节点提取记录，必填字段为空时返回 nil
+// 转换失败的值记录日志后视为空值，不影响页面中的其他记录
+func (e *SelectorExtractor) extractRecord(url string, root *html.Node) (Record, error) {
+	record := make(Record, len(e.fields))
+	for _, f := range e.fields {
+		raws, err := f.values(root)
+		if err != nil {
+			return nil, err
+		}
+
+		values := make([]any, 0, len(raws))
+		for _, raw := range raws {
+			value, err := f.convert(raw)
+			if err != nil {
+				log.Printf("[字段转换失败] URL: %s, 值: %q, 错误: %v", url, raw, err)
+				continue
+			}
+			values = append(values, value)
+		}
+		if len(values) == 0 {
+			if f.Required {
+				return nil, nil
+			}
+			continue
+		}
+
+		if f.Multiple {
+			record[f.Name] = values
+		} else {
+			record[f.Name] = values[0]
+		}
+	}
+	return record, nil
+}
+
+// values 查询字段的原始值（去除首尾空白，忽略空值）
+func (f *FieldSpec) values(root *html.Node) ([]string, error) {
+	var nodes []*html.Node
+	if f.XPath {
+		var err error
+		nodes, err = htmlquery.QueryAll(root, f.Selector)
+		if err != nil {
+			return nil, fmt.Errorf("字段 %s 的 XPath 无效: %w", f.Name, err)
+		}
+	} else {
+		nodes = goquery.NewDocumentFromNode(root).Find(f.Selector).Nodes
+	}
+
+	values := make([]string, 0, len(nodes))
+	for _, node := range nodes {
+		var raw string
+		if f.Attr != "" {
+			raw = htmlquery.SelectAttr(node, f.Attr)
+		} else {
+			raw = htmlquery.InnerText(node)
+		}
+		if raw = strings.TrimSpace(raw); raw != "" {
+			values = append(values, raw)
+		}
+		if !f.Multiple && len(values) > 0 {
+			break
+		}
+	}
+	return values, nil
+}
+
+// convert 转换字段值
+func (f *FieldSpec) convert(raw string) (any, error) {
+	if f.Convert == nil {
+		return raw, nil
+	}
+	value, err := f.Convert(raw)
+	if err != nil {
+		return nil, fmt.Errorf("字段 %s 转换失败: %w", f.Name, err)
+	}
+	return value, nil
+}
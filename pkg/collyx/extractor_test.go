@@ -0,0 +1,167 @@
+package collyx
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/gocolly/colly/v2"
+	"github.com/tedwangl/go-util/pkg/collyx/storage"
+)
+
+const productListHTML = `<html><body>
+<div class="item"><h2>Apple</h2><span class="price">¥1,299.50</span><a href="/p/1">详情</a><i class="tag">水果</i><i class="tag">红色</i></div>
+<div class="item"><h2>Banana</h2><span class="price">3</span><a href="/p/2">详情</a></div>
+<div class="item"><span class="price">9.9</span></div>
+</body></html>`
+
+func newTestResponse(body string) *colly.Response {
+	u, _ := url.Parse("https://example.com/list")
+	headers := http.Header{"Content-Type": []string{"text/html; charset=utf-8"}}
+	ctx := colly.NewContext()
+	return &colly.Response{
+		StatusCode: 200,
+		Body:       []byte(body),
+		Headers:    &headers,
+		Ctx:        ctx,
+		Request:    &colly.Request{URL: u, Ctx: ctx},
+	}
+}
+
+func TestSelectorExtractor(t *testing.T) {
+	extractor := NewExtractor(
+		Field("title", "h2", Required),
+		Field("price", ".price", AsFloat),
+		Field("link", "a", Attr("href")),
+		XPathField("tags", ".//i[@class='tag']", Multiple),
+	).Each(".item")
+
+	records, err := extractor.Extract(newTestResponse(productListHTML))
+	if err != nil {
+		t.Fatalf("Extract 失败: %v", err)
+	}
+	if len(records) != 2 {
+		t.Fatalf("期望 2 条记录（缺少 title 的记录被丢弃），实际 %d", len(records))
+	}
+
+	first := records[0]
+	if first["title"] != "Apple" || first["price"] != 1299.5 || first["link"] != "/p/1" {
+		t.Errorf("第一条记录错误: %v", first)
+	}
+	if tags, ok := first["tags"].([]any); !ok || len(tags) != 2 || tags[1] != "红色" {
+		t.Errorf("tags 错误: %v", first["tags"])
+	}
+	if _, ok := records[1]["tags"]; ok {
+		t.Errorf("没有匹配的字段不应出现在记录中: %v", records[1])
+	}
+}
+
+func TestSelectorExtractorConvertFailed(t *testing.T) {
+	body := `<html><body>
+<div class="item"><h2>Apple</h2><span class="price">面议</span><em>1</em></div>
+<div class="item"><h2>Banana</h2><span class="price">3</span><em>无</em></div>
+</body></html>`
+
+	records, err := NewExtractor(
+		Field("title", "h2"),
+		Field("price", ".price", AsFloat),
+		Field("stock", "em", AsInt, Required),
+	).Each(".item").Extract(newTestResponse(body))
+	if err != nil {
+		t.Fatalf("转换失败不应影响整个页面: %v", err)
+	}
+	// 必填字段转换失败时丢弃记录，其他字段转换失败时忽略该字段
+	if len(records) != 1 {
+		t.Fatalf("期望 1 条记录，实际 %d", len(records))
+	}
+	if _, ok := records[0]["price"]; ok || records[0]["title"] != "Apple" || records[0]["stock"] != int64(1) {
+		t.Errorf("记录错误: %v", records[0])
+	}
+}
+
+func TestPipelineDedupe(t *testing.T) {
+	var items []Record
+	p := NewPipeline(NewExtractor(
+		Field("title", "h2"),
+		Field("price", ".price", AsInt),
+	).Each(".item")).
+		Transform(func(resp *colly.Response, record Record) (Record, error) {
+			if record["title"] == nil {
+				return nil, nil
+			}
+			return record, nil
+		}).
+		Dedupe("title").
+		OnItem(func(resp *colly.Response, record Record) {
+			items = append(items, record)
+		})
+
+	if _, err := p.Process(newTestResponse(productListHTML)); err != nil {
+		t.Fatalf("Process 失败: %v", err)
+	}
+	if len(items) != 2 || items[0]["price"] != int64(1299) {
+		t.Fatalf("期望 2 条记录，实际 %v", items)
+	}
+
+	// 第二次处理相同页面全部去重
+	kept, err := p.Process(newTestResponse(productListHTML))
+	if err != nil {
+		t.Fatalf("Process 失败: %v", err)
+	}
+	if len(kept) != 0 {
+		t.Errorf("期望全部去重，实际保留 %d 条", len(kept))
+	}
+}
+
+// failingItemStore 前 fails 次保存失败的存储
+type failingItemStore struct {
+	storage.Storage
+
+	fails int
+	saved []*storage.Item
+}
+
+func (s *failingItemStore) GetItemByContentHash(string) (*storage.Item, error) {
+	return nil, errors.New("not found")
+}
+
+func (s *failingItemStore) FindSimilarItem(uint64, int) (*storage.Item, error) {
+	return nil, errors.New("not found")
+}
+
+func (s *failingItemStore) SaveItem(item *storage.Item) error {
+	if s.fails > 0 {
+		s.fails--
+		return errors.New("db unavailable")
+	}
+	s.saved = append(s.saved, item)
+	return nil
+}
+
+func TestPipelineDedupeSaveFailed(t *testing.T) {
+	store := &failingItemStore{fails: 1}
+	p := NewPipeline(NewExtractor(Field("title", "h2")).Each(".item")).
+		Dedupe("title").
+		NearDedupe(0, "title").
+		Store(store)
+
+	html := `<div class="item"><h2>Apple</h2></div>`
+	if _, err := p.Process(newTestResponse(html)); err == nil {
+		t.Fatal("期望保存失败")
+	}
+
+	// 保存失败的记录不应被视为已处理
+	kept, err := p.Process(newTestResponse(html))
+	if err != nil {
+		t.Fatalf("Process 失败: %v", err)
+	}
+	if len(kept) != 1 || len(store.saved) != 1 {
+		t.Fatalf("期望重新保存 1 条记录，实际保留 %d 条，保存 %d 条", len(kept), len(store.saved))
+	}
+
+	// 保存成功后继续去重
+	if kept, _ := p.Process(newTestResponse(html)); len(kept) != 0 {
+		t.Errorf("期望全部去重，实际保留 %d 条", len(kept))
+	}
+}
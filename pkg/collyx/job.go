@@ -0,0 +1,568 @@
+package collyx
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gocolly/colly/v2"
+	"github.com/robfig/cron/v3"
+	"github.com/tedwangl/go-util/pkg/collyx/storage"
+)
+
+const (
+	jobSaveInterval     = 5 * time.Second  // 运行中任务进度的保存间隔
+	jobScheduleInterval = 10 * time.Second // 定时任务的检查间隔
+)
+
+var (
+	ErrJobNotFound   = errors.New("爬取任务不存在")
+	ErrJobRunning    = errors.New("爬取任务正在运行")
+	ErrJobNotRunning = errors.New("爬取任务未运行")
+)
+
+// JobOverrides 任务级配置覆盖（零值表示沿用基础配置），随任务持久化
+type JobOverrides struct {
+	MaxDepth       int               `json:"max_depth,omitempty"`
+	Parallelism    int               `json:"parallelism,omitempty"`
+	Delay          time.Duration     `json:"delay,omitempty"`
+	RandomDelay    time.Duration     `json:"random_delay,omitempty"`
+	MaxRetries     int               `json:"max_retries,omitempty"`
+	UserAgent      string            `json:"user_agent,omitempty"`
+	AllowedDomains []string          `json:"allowed_domains,omitempty"`
+	Headers        map[string]string `json:"headers,omitempty"` // 与基础配置的请求头合并
+
+	// 抓取预算，与基础配置的 Budget 合并
+	MaxPages    int64         `json:"max_pages,omitempty"`
+	MaxBytes    int64         `json:"max_bytes,omitempty"`
+	MaxDuration time.Duration `json:"max_duration,omitempty"`
+	MaxErrors   int64         `json:"max_errors,omitempty"`
+}
+
+// apply 在基础配置的副本上应用覆盖
+func (o JobOverrides) apply(base *Config) *Config {
+	cfg := *base
+	if o.MaxDepth > 0 {
+		cfg.MaxDepth = o.MaxDepth
+	}
+	if o.Parallelism > 0 {
+		cfg.Parallelism = o.Parallelism
+	}
+	if o.Delay > 0 {
+		cfg.Delay = o.Delay
+	}
+	if o.RandomDelay > 0 {
+		cfg.RandomDelay = o.RandomDelay
+	}
+	if o.MaxRetries > 0 {
+		cfg.MaxRetries = o.MaxRetries
+	}
+	if o.UserAgent != "" {
+		cfg.UserAgent = o.UserAgent
+	}
+	if len(o.AllowedDomains) > 0 {
+		cfg.AllowedDomains = o.AllowedDomains
+	}
+	if len(o.Headers) > 0 {
+		headers := make(map[string]string, len(base.Headers)+len(o.Headers))
+		for k, v := range base.Headers {
+			headers[k] = v
+		}
+		for k, v := range o.Headers {
+			headers[k] = v
+		}
+		cfg.Headers = headers
+	}
+	if o.MaxPages > 0 || o.MaxBytes > 0 || o.MaxDuration > 0 || o.MaxErrors > 0 {
+		budget := Budget{}
+		if base.Budget != nil {
+			budget = *base.Budget
+		}
+		if o.MaxPages > 0 {
+			budget.MaxPages = o.MaxPages
+		}
+		if o.MaxBytes > 0 {
+			budget.MaxBytes = o.MaxBytes
+		}
+		if o.MaxDuration > 0 {
+			budget.MaxDuration = o.MaxDuration
+		}
+		if o.MaxErrors > 0 {
+			budget.MaxErrors = o.MaxErrors
+		}
+		cfg.Budget = &budget
+	}
+	return &cfg
+}
+
+// JobSpec 爬取任务定义
+type JobSpec struct {
+	Name      string
+	Seeds     []string
+	Overrides JobOverrides
+	Schedule  string // cron 表达式（如 "0 3 * * *"、"@every 6h"），为空只手动运行
+}
+
+// JobManager 爬取任务管理：一个进程管理多个独立的爬取任务，每个任务使用独立的 Client 和队列
+//
+//	jobs := collyx.NewJobManager(cfg, store)
+//	jobs.OnStart(func(job *storage.Job, c *collyx.Client) {
+//	    c.Collector().OnHTML("a[href]", func(e *colly.HTMLElement) { c.Follow(e.Request, e.Attr("href")) })
+//	})
+//	jobs.Save(collyx.JobSpec{Name: "news", Seeds: []string{"https://example.com/news"}, Schedule: "@every 6h"})
+//	jobs.Start("news")
+//	jobs.StartScheduler()
+//
+// 任务进程内暂停后可继续；进程重启后暂停的任务通过 Resume 按种子重新开始
+// （使用 redis 队列时从队列中剩余的请求继续）
+type JobManager struct {
+	base    *Config
+	store   storage.Storage
+	onStart []func(job *storage.Job, client *Client)
+
+	mu   sync.Mutex
+	runs map[string]*jobRun
+
+	stop     chan struct{}
+	stopOnce sync.Once
+	wg       sync.WaitGroup
+}
+
+// jobRun 运行中的任务
+type jobRun struct {
+	client *Client
+	done   chan struct{}
+
+	mu  sync.Mutex
+	job *storage.Job
+
+	cancelled   atomic.Bool // Cancel 取消
+	interrupted atomic.Bool // Close 中断，保留为暂停状态
+
+	requests  atomic.Int64
+	responses atomic.Int64
+	errors    atomic.Int64
+}
+
+// NewJobManager 创建任务管理器，base 为各任务共享的基础配置（nil 使用默认配置）
+func NewJobManager(base *Config, store storage.Storage) *JobManager {
+	if base == nil {
+		base = DefaultConfig()
+	}
+	return &JobManager{
+		base:  base,
+		store: store,
+		runs:  make(map[string]*jobRun),
+		stop:  make(chan struct{}),
+	}
+}
+
+// OnStart 添加任务启动回调（Client 创建后、种子入队前调用），用于注册处理器
+func (m *JobManager) OnStart(fn func(job *storage.Job, client *Client)) {
+	m.onStart = append(m.onStart, fn)
+}
+
+// Save 创建或更新任务定义（运行中的任务不能修改）
+func (m *JobManager) Save(spec JobSpec) (*storage.Job, error) {
+	if spec.Name == "" {
+		return nil, fmt.Errorf("任务名称不能为空")
+	}
+	if len(spec.Seeds) == 0 {
+		return nil, fmt.Errorf("任务 %s 没有种子 URL", spec.Name)
+	}
+	if m.running(spec.Name) != nil {
+		return nil, fmt.Errorf("%w: %s", ErrJobRunning, spec.Name)
+	}
+
+	overrides, err := encodeOverrides(spec.Overrides)
+	if err != nil {
+		return nil, err
+	}
+
+	job, err := m.store.GetJob(spec.Name)
+	if err != nil {
+		job = &storage.Job{Name: spec.Name, Status: storage.JobStatusPending}
+	}
+	job.Seeds = spec.Seeds
+	job.Config = overrides
+	job.Schedule = spec.Schedule
+	job.NextRunAt = nil
+	if spec.Schedule != "" {
+		next, err := nextRun(spec.Schedule, time.Now())
+		if err != nil {
+			return nil, err
+		}
+		job.NextRunAt = &next
+	}
+
+	if err := m.store.SaveJob(job); err != nil {
+		return nil, fmt.Errorf("保存任务失败: %w", err)
+	}
+	return job, nil
+}
+
+// Delete 删除任务（运行中的任务需先取消）
+func (m *JobManager) Delete(name string) error {
+	if m.running(name) != nil {
+		return fmt.Errorf("%w: %s", ErrJobRunning, name)
+	}
+	return m.store.DeleteJob(name)
+}
+
+// Get 获取任务（运行中的任务包含实时进度）
+func (m *JobManager) Get(name string) (*storage.Job, error) {
+	if run := m.running(name); run != nil {
+		return run.snapshot(), nil
+	}
+	job, err := m.store.GetJob(name)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrJobNotFound, name)
+	}
+	return job, nil
+}
+
+// List 列出所有任务
+func (m *JobManager) List() ([]*storage.Job, error) {
+	jobs, err := m.store.ListJobs()
+	if err != nil {
+		return nil, err
+	}
+	for i, job := range jobs {
+		if run := m.running(job.Name); run != nil {
+			jobs[i] = run.snapshot()
+		}
+	}
+	return jobs, nil
+}
+
+// Start 启动任务（后台运行，使用 Wait 等待完成）
+func (m *JobManager) Start(name string) error {
+	job, err := m.store.GetJob(name)
+	if err != nil {
+		return fmt.Errorf("%w: %s", ErrJobNotFound, name)
+	}
+
+	var overrides JobOverrides
+	if err := decodeOverrides(job.Config, &overrides); err != nil {
+		return err
+	}
+	cfg := overrides.apply(m.base)
+	cfg.EnableQueue = true
+	cfg.QueueName = m.base.QueueName + ":" + name
+	cfg.MetricsAddr = "" // 各任务的进度通过 Get 获取
+
+	m.mu.Lock()
+	if _, ok := m.runs[name]; ok {
+		m.mu.Unlock()
+		return fmt.Errorf("%w: %s", ErrJobRunning, name)
+	}
+	client, err := NewClient(cfg)
+	if err != nil {
+		m.mu.Unlock()
+		return fmt.Errorf("启动任务 %s 失败: %w", name, err)
+	}
+	client.jobName = name
+	run := &jobRun{client: client, job: job, done: make(chan struct{})}
+	m.runs[name] = run
+	m.wg.Add(1)
+	m.mu.Unlock()
+
+	client.collector.OnRequest(func(*colly.Request) { run.requests.Add(1) })
+	client.collector.OnResponse(func(*colly.Response) { run.responses.Add(1) })
+	client.collector.OnError(func(*colly.Response, error) { run.errors.Add(1) })
+	for _, fn := range m.onStart {
+		fn(job, client)
+	}
+
+	now := time.Now()
+	run.update(m.store, func(job *storage.Job) {
+		job.Status = storage.JobStatusRunning
+		job.Error = ""
+		job.StopReason = ""
+		job.Runs++
+		job.StartedAt = &now
+		job.FinishedAt = nil
+		if job.Schedule != "" {
+			if next, err := nextRun(job.Schedule, now); err == nil {
+				job.NextRunAt = &next
+			}
+		}
+	})
+
+	for _, seed := range job.Seeds {
+		if err := client.Visit(seed); err != nil {
+			log.Printf("[种子 URL 添加失败] 任务: %s, URL: %s, 错误: %v", name, seed, err)
+		}
+	}
+
+	log.Printf("[任务启动] 任务: %s, 种子: %d, 第 %d 次运行", name, len(job.Seeds), job.Runs)
+	go m.run(name, run)
+	return nil
+}
+
+// run 处理任务队列直到完成、取消或中断
+func (m *JobManager) run(name string, run *jobRun) {
+	defer m.wg.Done()
+	defer close(run.done)
+
+	saveDone := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(jobSaveInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-saveDone:
+				return
+			case <-ticker.C:
+				run.update(m.store, nil)
+			}
+		}
+	}()
+
+	// 重试的请求可能继续向队列添加链接，队列为空且没有等待中的重试时结束
+	client := run.client
+	for {
+		if err := client.ProcessQueue(true); err != nil {
+			log.Printf("[任务队列处理失败] 任务: %s, 错误: %v", name, err)
+			break
+		}
+		client.Wait()
+		if client.ctx.Err() != nil || client.budgetExceeded() || client.queueSize() == 0 {
+			break
+		}
+	}
+	close(saveDone)
+
+	now := time.Now()
+	run.update(m.store, func(job *storage.Job) {
+		job.FinishedAt = &now
+		switch {
+		case run.cancelled.Load():
+			job.Status = storage.JobStatusCancelled
+		case run.interrupted.Load():
+			job.Status = storage.JobStatusPaused
+			job.FinishedAt = nil
+		case run.responses.Load() == 0 && run.errors.Load() > 0:
+			job.Status = storage.JobStatusFailed
+			job.Error = "所有请求均失败"
+		default:
+			job.Status = storage.JobStatusCompleted
+		}
+		if reason := client.StopReason(); reason != nil {
+			job.StopReason = reason.Reason
+		}
+	})
+
+	if err := client.Close(); err != nil {
+		log.Printf("[任务关闭失败] 任务: %s, 错误: %v", name, err)
+	}
+
+	m.mu.Lock()
+	delete(m.runs, name)
+	m.mu.Unlock()
+
+	job := run.snapshot()
+	log.Printf("[任务结束] 任务: %s, 状态: %s, 请求: %d, 响应: %d, 错误: %d",
+		name, job.Status, job.Requests, job.Responses, job.Errors)
+}
+
+// Pause 暂停任务（进行中的请求继续完成）
+func (m *JobManager) Pause(name string) error {
+	run := m.running(name)
+	if run == nil {
+		return fmt.Errorf("%w: %s", ErrJobNotRunning, name)
+	}
+	run.client.Pause()
+	run.update(m.store, func(job *storage.Job) {
+		job.Status = storage.JobStatusPaused
+	})
+	return nil
+}
+
+// Resume 继续暂停的任务；任务不在本进程运行时（如进程重启后）重新启动
+func (m *JobManager) Resume(name string) error {
+	run := m.running(name)
+	if run == nil {
+		job, err := m.store.GetJob(name)
+		if err != nil {
+			return fmt.Errorf("%w: %s", ErrJobNotFound, name)
+		}
+		if job.Status != storage.JobStatusPaused && job.Status != storage.JobStatusRunning {
+			return fmt.Errorf("%w: %s（状态: %s）", ErrJobNotRunning, name, job.Status)
+		}
+		return m.Start(name)
+	}
+
+	run.client.Resume()
+	run.update(m.store, func(job *storage.Job) {
+		job.Status = storage.JobStatusRunning
+	})
+	return nil
+}
+
+// Cancel 取消任务，等待进行中的请求结束后返回
+func (m *JobManager) Cancel(name string) error {
+	run := m.running(name)
+	if run == nil {
+		job, err := m.store.GetJob(name)
+		if err != nil {
+			return fmt.Errorf("%w: %s", ErrJobNotFound, name)
+		}
+		// 进程重启后遗留的暂停任务
+		if job.Status != storage.JobStatusPaused && job.Status != storage.JobStatusRunning {
+			return fmt.Errorf("%w: %s", ErrJobNotRunning, name)
+		}
+		job.Status = storage.JobStatusCancelled
+		return m.store.SaveJob(job)
+	}
+
+	run.cancelled.Store(true)
+	run.client.Stop()
+	<-run.done
+	return nil
+}
+
+// Wait 等待任务运行结束（任务未运行时立即返回）
+func (m *JobManager) Wait(name string) {
+	if run := m.running(name); run != nil {
+		<-run.done
+	}
+}
+
+// StartScheduler 启动定时调度：到达 NextRunAt 的任务自动启动（运行中和暂停的任务跳过）
+func (m *JobManager) StartScheduler() {
+	m.wg.Add(1)
+	go func() {
+		defer m.wg.Done()
+
+		ticker := time.NewTicker(jobScheduleInterval)
+		defer ticker.Stop()
+		for {
+			m.runDue(time.Now())
+			select {
+			case <-m.stop:
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// runDue 启动到期的定时任务
+func (m *JobManager) runDue(now time.Time) {
+	jobs, err := m.store.ListJobs()
+	if err != nil {
+		log.Printf("[任务调度失败] 错误: %v", err)
+		return
+	}
+	for _, job := range jobs {
+		if job.Schedule == "" || job.NextRunAt == nil || job.NextRunAt.After(now) {
+			continue
+		}
+		if job.Status == storage.JobStatusPaused || m.running(job.Name) != nil {
+			continue
+		}
+		if err := m.Start(job.Name); err != nil {
+			log.Printf("[任务调度失败] 任务: %s, 错误: %v", job.Name, err)
+		}
+	}
+}
+
+// Close 停止调度并中断运行中的任务（任务保留为暂停状态，可通过 Resume 继续）
+func (m *JobManager) Close() error {
+	m.stopOnce.Do(func() { close(m.stop) })
+
+	m.mu.Lock()
+	runs := make([]*jobRun, 0, len(m.runs))
+	for _, run := range m.runs {
+		runs = append(runs, run)
+	}
+	m.mu.Unlock()
+
+	for _, run := range runs {
+		run.interrupted.Store(true)
+		run.client.Stop()
+	}
+	m.wg.Wait()
+	return nil
+}
+
+// running 返回运行中的任务
+func (m *JobManager) running(name string) *jobRun {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.runs[name]
+}
+
+// update 修改任务并写入实时进度后保存（fn 为 nil 时只保存进度）
+func (r *jobRun) update(store storage.Storage, fn func(job *storage.Job)) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if fn != nil {
+		fn(r.job)
+	}
+	r.fillProgress(r.job)
+	if err := store.SaveJob(r.job); err != nil {
+		log.Printf("[任务保存失败] 任务: %s, 错误: %v", r.job.Name, err)
+	}
+}
+
+// snapshot 返回包含实时进度的任务副本
+func (r *jobRun) snapshot() *storage.Job {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	job := *r.job
+	r.fillProgress(&job)
+	return &job
+}
+
+// fillProgress 写入实时进度
+func (r *jobRun) fillProgress(job *storage.Job) {
+	job.Requests = r.requests.Load()
+	job.Responses = r.responses.Load()
+	job.Errors = r.errors.Load()
+	job.Queued = int64(r.client.queueSize())
+}
+
+// nextRun 计算 cron 表达式的下次运行时间
+func nextRun(schedule string, now time.Time) (time.Time, error) {
+	sched, err := cron.ParseStandard(schedule)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("无效的调度表达式 %s: %w", schedule, err)
+	}
+	return sched.Next(now), nil
+}
+
+// encodeOverrides 将配置覆盖编码为存储格式
+func encodeOverrides(o JobOverrides) (map[string]any, error) {
+	data, err := json.Marshal(o)
+	if err != nil {
+		return nil, fmt.Errorf("序列化配置覆盖失败: %w", err)
+	}
+	var m map[string]any
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, fmt.Errorf("序列化配置覆盖失败: %w", err)
+	}
+	return m, nil
+}
+
+// decodeOverrides 从存储格式解码配置覆盖
+func decodeOverrides(m map[string]any, o *JobOverrides) error {
+	if len(m) == 0 {
+		return nil
+	}
+	data, err := json.Marshal(m)
+	if err != nil {
+		return fmt.Errorf("解析配置覆盖失败: %w", err)
+	}
+	if err := json.Unmarshal(data, o); err != nil {
+		return fmt.Errorf("解析配置覆盖失败: %w", err)
+	}
+	return nil
+}
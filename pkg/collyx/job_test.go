@@ -0,0 +1,213 @@
+package collyx
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gocolly/colly/v2"
+	"github.com/tedwangl/go-util/pkg/collyx/storage"
+)
+
+// memJobStore 只实现 Job 相关方法的内存存储
+type memJobStore struct {
+	storage.Storage
+
+	mu   sync.Mutex
+	jobs map[string]storage.Job
+}
+
+func newMemJobStore() *memJobStore {
+	return &memJobStore{jobs: make(map[string]storage.Job)}
+}
+
+func (s *memJobStore) SaveJob(job *storage.Job) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.jobs[job.Name] = *job
+	return nil
+}
+
+func (s *memJobStore) GetJob(name string) (*storage.Job, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	job, ok := s.jobs[name]
+	if !ok {
+		return nil, fmt.Errorf("爬取任务不存在: %s", name)
+	}
+	return &job, nil
+}
+
+func (s *memJobStore) ListJobs() ([]*storage.Job, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	jobs := make([]*storage.Job, 0, len(s.jobs))
+	for _, job := range s.jobs {
+		job := job
+		jobs = append(jobs, &job)
+	}
+	sort.Slice(jobs, func(i, j int) bool { return jobs[i].Name < jobs[j].Name })
+	return jobs, nil
+}
+
+func (s *memJobStore) DeleteJob(name string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.jobs, name)
+	return nil
+}
+
+// newChainServer 返回 /p/1 → /p/2 → ... → /p/n 的链式页面
+func newChainServer(n int, delay time.Duration) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(delay)
+		i, _ := strconv.Atoi(strings.TrimPrefix(r.URL.Path, "/p/"))
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		if i < n {
+			fmt.Fprintf(w, `<html><body><a href="/p/%d">next</a></body></html>`, i+1)
+			return
+		}
+		fmt.Fprint(w, `<html><body>end</body></html>`)
+	}))
+}
+
+func newTestJobManager(store storage.Storage) *JobManager {
+	cfg := DefaultConfig()
+	cfg.Delay = 0
+	cfg.RandomDelay = 0
+	cfg.MaxDepth = 0
+
+	m := NewJobManager(cfg, store)
+	m.OnStart(func(job *storage.Job, c *Client) {
+		c.Collector().OnHTML("a[href]", func(e *colly.HTMLElement) {
+			c.Follow(e.Request, e.Attr("href"))
+		})
+	})
+	return m
+}
+
+func TestJobManager_Run(t *testing.T) {
+	srv := newChainServer(5, 0)
+	defer srv.Close()
+
+	store := newMemJobStore()
+	m := newTestJobManager(store)
+	defer m.Close()
+
+	if _, err := m.Save(JobSpec{Name: "chain", Seeds: []string{srv.URL + "/p/1"}, Overrides: JobOverrides{MaxRetries: 1}}); err != nil {
+		t.Fatalf("Save 失败: %v", err)
+	}
+	if err := m.Start("chain"); err != nil {
+		t.Fatalf("Start 失败: %v", err)
+	}
+	if err := m.Start("chain"); !errors.Is(err, ErrJobRunning) {
+		t.Errorf("重复启动应返回 ErrJobRunning，实际 %v", err)
+	}
+	m.Wait("chain")
+
+	job, err := m.Get("chain")
+	if err != nil {
+		t.Fatalf("Get 失败: %v", err)
+	}
+	if job.Status != storage.JobStatusCompleted || job.Responses != 5 || job.Runs != 1 {
+		t.Errorf("任务应完成并抓取 5 页，实际状态 %s, 响应 %d, 运行 %d 次", job.Status, job.Responses, job.Runs)
+	}
+	if job.Config["max_retries"] != float64(1) {
+		t.Errorf("配置覆盖应持久化，实际 %v", job.Config)
+	}
+
+	if err := m.Start("missing"); !errors.Is(err, ErrJobNotFound) {
+		t.Errorf("启动不存在的任务应返回 ErrJobNotFound，实际 %v", err)
+	}
+}
+
+func TestJobManager_PauseResumeCancel(t *testing.T) {
+	srv := newChainServer(50, 20*time.Millisecond)
+	defer srv.Close()
+
+	store := newMemJobStore()
+	m := newTestJobManager(store)
+	defer m.Close()
+
+	m.Save(JobSpec{Name: "slow", Seeds: []string{srv.URL + "/p/1"}})
+	if err := m.Start("slow"); err != nil {
+		t.Fatalf("Start 失败: %v", err)
+	}
+	time.Sleep(100 * time.Millisecond)
+
+	if err := m.Pause("slow"); err != nil {
+		t.Fatalf("Pause 失败: %v", err)
+	}
+	time.Sleep(100 * time.Millisecond) // 等待进行中的请求结束
+	job, _ := m.Get("slow")
+	if job.Status != storage.JobStatusPaused {
+		t.Errorf("状态应为 paused，实际 %s", job.Status)
+	}
+	paused := job.Responses
+	time.Sleep(200 * time.Millisecond)
+	if job, _ := m.Get("slow"); job.Responses != paused {
+		t.Errorf("暂停后不应继续抓取，响应数从 %d 变为 %d", paused, job.Responses)
+	}
+
+	if err := m.Resume("slow"); err != nil {
+		t.Fatalf("Resume 失败: %v", err)
+	}
+	time.Sleep(100 * time.Millisecond)
+	if job, _ := m.Get("slow"); job.Responses <= paused {
+		t.Errorf("继续后应恢复抓取，响应数 %d", job.Responses)
+	}
+
+	if err := m.Cancel("slow"); err != nil {
+		t.Fatalf("Cancel 失败: %v", err)
+	}
+	job, _ = m.Get("slow")
+	if job.Status != storage.JobStatusCancelled || job.Responses >= 50 {
+		t.Errorf("任务应被取消，实际状态 %s, 响应 %d", job.Status, job.Responses)
+	}
+	if err := m.Pause("slow"); !errors.Is(err, ErrJobNotRunning) {
+		t.Errorf("暂停已结束的任务应返回 ErrJobNotRunning，实际 %v", err)
+	}
+}
+
+func TestJobManager_Schedule(t *testing.T) {
+	srv := newChainServer(1, 0)
+	defer srv.Close()
+
+	store := newMemJobStore()
+	m := newTestJobManager(store)
+	defer m.Close()
+
+	if _, err := m.Save(JobSpec{Name: "bad", Seeds: []string{srv.URL + "/p/1"}, Schedule: "every day"}); err == nil {
+		t.Error("无效的调度表达式应返回错误")
+	}
+
+	job, err := m.Save(JobSpec{Name: "hourly", Seeds: []string{srv.URL + "/p/1"}, Schedule: "@every 1h"})
+	if err != nil {
+		t.Fatalf("Save 失败: %v", err)
+	}
+	if job.NextRunAt == nil || time.Until(*job.NextRunAt) < 59*time.Minute {
+		t.Fatalf("NextRunAt 应为一小时后，实际 %v", job.NextRunAt)
+	}
+
+	m.runDue(time.Now())
+	if job, _ := m.Get("hourly"); job.Runs != 0 {
+		t.Error("未到时间的任务不应启动")
+	}
+
+	m.runDue(time.Now().Add(2 * time.Hour))
+	m.Wait("hourly")
+	job, _ = m.Get("hourly")
+	if job.Runs != 1 || job.Status != storage.JobStatusCompleted {
+		t.Errorf("到期的任务应启动并完成，实际运行 %d 次，状态 %s", job.Runs, job.Status)
+	}
+	if job.NextRunAt == nil || !job.NextRunAt.After(time.Now()) {
+		t.Errorf("运行后应计算下次运行时间，实际 %v", job.NextRunAt)
+	}
+}
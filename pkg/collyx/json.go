@@ -0,0 +1,409 @@
+package collyx
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gocolly/colly/v2"
+)
+
+// jsonPageKey 上下文中记录的 JSON 翻页页码
+const jsonPageKey = "collyx.json.page"
+
+// JSONElement JSONPath 匹配到的值
+type JSONElement struct {
+	Value    any // 匹配的值（数字为 json.Number）
+	Index    int // 在所有匹配中的序号
+	Request  *colly.Request
+	Response *colly.Response
+}
+
+// Get 返回相对路径的第一个匹配值（如 "user.name"），不存在时返回 nil
+func (e *JSONElement) Get(path string) any {
+	p, err := CompileJSONPath(path)
+	if err != nil {
+		return nil
+	}
+	v, _ := p.First(e.Value)
+	return v
+}
+
+// String 返回相对路径的值的字符串形式，不存在时返回空字符串
+func (e *JSONElement) String(path string) string {
+	return jsonScalar(e.Get(path))
+}
+
+// Int 返回相对路径的整数值，不存在或不是数字时返回 0
+func (e *JSONElement) Int(path string) int64 {
+	n, _ := strconv.ParseInt(e.String(path), 10, 64)
+	return n
+}
+
+// Float 返回相对路径的浮点数值，不存在或不是数字时返回 0
+func (e *JSONElement) Float(path string) float64 {
+	f, _ := strconv.ParseFloat(e.String(path), 64)
+	return f
+}
+
+// ForEach 对相对路径的每个匹配值调用 fn
+func (e *JSONElement) ForEach(path string, fn func(i int, el *JSONElement)) {
+	p, err := CompileJSONPath(path)
+	if err != nil {
+		return
+	}
+	for i, v := range p.Find(e.Value) {
+		fn(i, &JSONElement{Value: v, Index: i, Request: e.Request, Response: e.Response})
+	}
+}
+
+// Unmarshal 将值解码到结构体
+func (e *JSONElement) Unmarshal(v any) error {
+	data, err := json.Marshal(e.Value)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+// JSONPagination JSON 接口自动翻页
+//
+//	// {"data": [...], "paging": {"next": "https://api.example.com/items?cursor=abc"}}
+//	cfg.JSONPagination = &collyx.JSONPagination{NextPath: "$.paging.next"}
+//
+//	// {"data": [...], "next_cursor": "abc", "has_more": true}
+//	cfg.JSONPagination = &collyx.JSONPagination{NextPath: "$.next_cursor", Param: "cursor", HasMorePath: "$.has_more"}
+type JSONPagination struct {
+	NextPath    string                          // 下一页的 JSONPath：值为完整 URL、相对路径，或配合 Param 使用的页码/游标
+	Param       string                          // 查询参数名，设置后把 NextPath 的值写入当前 URL 的该参数
+	HasMorePath string                          // 是否还有下一页的 JSONPath（可选），值为 false 时停止
+	MaxPages    int                             // 最多抓取的页数（包含第一页），0 不限制
+	Match       func(resp *colly.Response) bool // 只对满足条件的响应翻页（可选）
+}
+
+// jsonHandler OnJSON 注册的处理器
+type jsonHandler struct {
+	path *JSONPath
+	fn   func(*JSONElement)
+}
+
+// OnJSON 注册 JSON 响应处理器，path 的每个匹配值调用一次 fn
+//
+//	client.OnJSON("$.data.items[*]", func(e *collyx.JSONElement) {
+//	    fmt.Println(e.String("id"), e.String("title"))
+//	})
+func (c *Client) OnJSON(path string, fn func(*JSONElement)) error {
+	p, err := CompileJSONPath(path)
+	if err != nil {
+		return err
+	}
+
+	c.jsonMu.Lock()
+	defer c.jsonMu.Unlock()
+	c.jsonHandlers = append(c.jsonHandlers, jsonHandler{path: p, fn: fn})
+	return nil
+}
+
+// setupJSONHandlers 注册配置中的 JSON 处理器和翻页
+func (c *Client) setupJSONHandlers() error {
+	for path, fn := range c.config.OnJSON {
+		if err := c.OnJSON(path, fn); err != nil {
+			return err
+		}
+	}
+	if p := c.config.JSONPagination; p != nil {
+		if _, err := CompileJSONPath(p.NextPath); err != nil {
+			return fmt.Errorf("翻页配置无效: %w", err)
+		}
+		if p.HasMorePath != "" {
+			if _, err := CompileJSONPath(p.HasMorePath); err != nil {
+				return fmt.Errorf("翻页配置无效: %w", err)
+			}
+		}
+	}
+	c.collector.OnResponse(c.handleJSON)
+	return nil
+}
+
+// handleJSON 解析 JSON 响应，调用处理器并翻页
+func (c *Client) handleJSON(resp *colly.Response) {
+	c.jsonMu.RLock()
+	handlers := c.jsonHandlers
+	c.jsonMu.RUnlock()
+
+	if len(handlers) == 0 && c.config.JSONPagination == nil {
+		return
+	}
+	if !isJSONResponse(resp) {
+		return
+	}
+
+	data, err := decodeJSON(resp.Body)
+	if err != nil {
+		log.Printf("[JSON 解析失败] URL: %s, 错误: %v", resp.Request.URL.String(), err)
+		return
+	}
+
+	for _, h := range handlers {
+		for i, v := range h.path.Find(data) {
+			h.fn(&JSONElement{Value: v, Index: i, Request: resp.Request, Response: resp})
+		}
+	}
+
+	c.followJSONPage(resp, data)
+}
+
+// followJSONPage 根据翻页配置访问下一页（启用队列时加入队列）
+func (c *Client) followJSONPage(resp *colly.Response, data any) {
+	p := c.config.JSONPagination
+	if p == nil || (p.Match != nil && !p.Match(resp)) {
+		return
+	}
+
+	page, _ := strconv.Atoi(resp.Ctx.Get(jsonPageKey))
+	if page <= 0 {
+		page = 1
+	}
+	if p.MaxPages > 0 && page >= p.MaxPages {
+		return
+	}
+
+	next, ok := p.nextURL(resp.Request.URL, data)
+	if !ok || next == resp.Request.URL.String() {
+		return
+	}
+	nextPage := strconv.Itoa(page + 1)
+
+	if c.queue != nil && c.queueEnabled() {
+		if err := c.queue.Add(&Request{
+			URL:       next,
+			Method:    "GET",
+			Headers:   resp.Request.Headers,
+			Timestamp: time.Now(),
+			Ctx:       map[string]string{jsonPageKey: nextPage},
+		}); err != nil {
+			log.Printf("[翻页失败] URL: %s, 错误: %v", next, err)
+		}
+		return
+	}
+
+	// 翻页不增加深度，沿用当前请求的请求头（如 Authorization）
+	ctx := colly.NewContext()
+	ctx.Put(jsonPageKey, nextPage)
+	var headers http.Header
+	if resp.Request.Headers != nil {
+		headers = resp.Request.Headers.Clone()
+	}
+	if err := c.collector.Request("GET", next, nil, ctx, headers); err != nil {
+		log.Printf("[翻页失败] URL: %s, 错误: %v", next, err)
+	}
+}
+
+// nextURL 计算下一页的 URL，没有下一页时返回 false
+func (p *JSONPagination) nextURL(current *url.URL, data any) (string, bool) {
+	if p.HasMorePath != "" {
+		v, _ := MustCompileJSONPath(p.HasMorePath).First(data)
+		if !jsonTruthy(v) {
+			return "", false
+		}
+	}
+
+	v, _ := MustCompileJSONPath(p.NextPath).First(data)
+	if !jsonTruthy(v) {
+		return "", false
+	}
+	next := jsonScalar(v)
+
+	if p.Param != "" {
+		u := *current
+		q := u.Query()
+		q.Set(p.Param, next)
+		u.RawQuery = q.Encode()
+		return u.String(), true
+	}
+
+	ref, err := url.Parse(next)
+	if err != nil {
+		return "", false
+	}
+	return current.ResolveReference(ref).String(), true
+}
+
+// JSONExtractor 基于 JSONPath 的提取器，字段的 Selector 为相对于每条记录的 JSONPath
+//
+//	collyx.NewJSONExtractor(
+//	    collyx.Field("id", "id", collyx.Required),
+//	    collyx.Field("title", "title"),
+//	    collyx.Field("tags", "tags[*].name", collyx.Multiple),
+//	).Each("$.data.items[*]")
+//
+// 未设置 Convert 时保留 JSON 的原始类型（整数为 int64，小数为 float64）
+type JSONExtractor struct {
+	root   string
+	fields []FieldSpec
+}
+
+// NewJSONExtractor 创建 JSON 提取器（默认整个响应提取一条记录）
+func NewJSONExtractor(fields ...FieldSpec) *JSONExtractor {
+	return &JSONExtractor{fields: fields}
+}
+
+// Each 每个匹配 JSONPath 的值提取一条记录（列表接口）
+func (e *JSONExtractor) Each(path string) *JSONExtractor {
+	e.root = path
+	return e
+}
+
+// Extract 实现 Extractor
+func (e *JSONExtractor) Extract(resp *colly.Response) ([]Record, error) {
+	data, err := decodeJSON(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("解析 JSON 失败: %w", err)
+	}
+
+	roots := []any{data}
+	if e.root != "" {
+		p, err := CompileJSONPath(e.root)
+		if err != nil {
+			return nil, err
+		}
+		roots = p.Find(data)
+	}
+
+	records := make([]Record, 0, len(roots))
+	for _, root := range roots {
+		record, err := e.extractRecord(resp.Request.URL.String(), root)
+		if err != nil {
+			return nil, err
+		}
+		if record != nil {
+			records = append(records, record)
+		}
+	}
+	return records, nil
+}
+
+// extractRecord 从单个值提取记录，必填字段为空时返回 nil，转换失败的值视为空值
+func (e *JSONExtractor) extractRecord(url string, root any) (Record, error) {
+	record := make(Record, len(e.fields))
+	for _, f := range e.fields {
+		p, err := CompileJSONPath(f.Selector)
+		if err != nil {
+			return nil, fmt.Errorf("字段 %s 的 %w", f.Name, err)
+		}
+
+		var matches []any
+		for _, v := range p.Find(root) {
+			if v != nil && v != "" {
+				matches = append(matches, v)
+			}
+		}
+		if !f.Multiple && len(matches) > 1 {
+			matches = matches[:1]
+		}
+
+		values := make([]any, 0, len(matches))
+		for _, v := range matches {
+			if f.Convert != nil {
+				raw := jsonScalar(v)
+				if v, err = f.convert(raw); err != nil {
+					log.Printf("[字段转换失败] URL: %s, 值: %q, 错误: %v", url, raw, err)
+					continue
+				}
+			}
+			values = append(values, normalizeJSONNumber(v))
+		}
+		if len(values) == 0 {
+			if f.Required {
+				return nil, nil
+			}
+			continue
+		}
+		if f.Multiple {
+			record[f.Name] = values
+		} else {
+			record[f.Name] = values[0]
+		}
+	}
+	return record, nil
+}
+
+// isJSONResponse 判断响应是否为 JSON（按 Content-Type，缺失时按正文首字符）
+func isJSONResponse(resp *colly.Response) bool {
+	if resp.Headers != nil {
+		if ct := strings.ToLower(resp.Headers.Get("Content-Type")); ct != "" && !strings.HasPrefix(ct, "text/plain") {
+			return strings.Contains(ct, "json")
+		}
+	}
+	body := bytes.TrimSpace(resp.Body)
+	return len(body) > 0 && (body[0] == '{' || body[0] == '[')
+}
+
+// decodeJSON 解码 JSON（数字保留为 json.Number，避免大整数 ID 丢失精度）
+func decodeJSON(data []byte) (any, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var v any
+	if err := dec.Decode(&v); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+// jsonScalar 将 JSON 值转换为字符串（对象和数组编码为 JSON）
+func jsonScalar(v any) string {
+	switch val := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return val
+	case json.Number:
+		return val.String()
+	case bool:
+		return strconv.FormatBool(val)
+	case float64:
+		return strconv.FormatFloat(val, 'f', -1, 64)
+	default:
+		data, err := json.Marshal(val)
+		if err != nil {
+			return fmt.Sprint(val)
+		}
+		return string(data)
+	}
+}
+
+// jsonTruthy 判断值是否表示"有下一页"（null、false、空字符串为否）
+func jsonTruthy(v any) bool {
+	switch val := v.(type) {
+	case nil:
+		return false
+	case bool:
+		return val
+	case string:
+		return val != ""
+	}
+	return true
+}
+
+// normalizeJSONNumber 将 json.Number 转换为 int64 或 float64（超出 int64 的整数保留字符串，避免精度丢失）
+func normalizeJSONNumber(v any) any {
+	n, ok := v.(json.Number)
+	if !ok {
+		return v
+	}
+	if i, err := n.Int64(); err == nil {
+		return i
+	}
+	if strings.ContainsAny(n.String(), ".eE") {
+		if f, err := n.Float64(); err == nil {
+			return f
+		}
+	}
+	return n.String()
+}
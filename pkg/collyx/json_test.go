@@ -0,0 +1,214 @@
+package collyx
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gocolly/colly/v2"
+)
+
+func newJSONResponse(body string) *colly.Response {
+	resp := newTestResponse(body)
+	resp.Headers.Set("Content-Type", "application/json; charset=utf-8")
+	return resp
+}
+
+func TestJSONPath(t *testing.T) {
+	data, err := decodeJSON([]byte(`{
+		"data": {
+			"items": [
+				{"id": 1, "name": "a", "tags": [{"name": "x"}, {"name": "y"}]},
+				{"id": 2, "name": "b", "tags": []}
+			],
+			"total": 2
+		},
+		"meta": {"name": "root"}
+	}`))
+	if err != nil {
+		t.Fatalf("decodeJSON 失败: %v", err)
+	}
+
+	tests := []struct {
+		path string
+		want []string
+	}{
+		{"$.data.total", []string{"2"}},
+		{"data.total", []string{"2"}},
+		{"$['data']['total']", []string{"2"}},
+		{"$.data.items[0].name", []string{"a"}},
+		{"$.data.items[-1].name", []string{"b"}},
+		{"$.data.items[*].id", []string{"1", "2"}},
+		{"$.data.items[0].tags[*].name", []string{"x", "y"}},
+		{"$.meta.*", []string{"root"}},
+		{"$..name", []string{"a", "x", "y", "b", "root"}},
+		{"$.data.items[5].name", nil},
+		{"$.missing", nil},
+	}
+	for _, tt := range tests {
+		var got []string
+		for _, v := range MustCompileJSONPath(tt.path).Find(data) {
+			got = append(got, jsonScalar(v))
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s = %v，期望 %v", tt.path, got, tt.want)
+		}
+	}
+
+	for _, expr := range []string{"$.", "$..", "$.a[", "$.a[x]"} {
+		if _, err := CompileJSONPath(expr); err == nil {
+			t.Errorf("%q 应编译失败", expr)
+		}
+	}
+}
+
+func TestJSONExtractor(t *testing.T) {
+	extractor := NewJSONExtractor(
+		Field("id", "id", Required),
+		Field("title", "title"),
+		Field("price", "price"),
+		Field("tags", "tags[*].name", Multiple),
+		Field("views", "stats.views", AsInt),
+	).Each("$.data.items[*]")
+
+	resp := newJSONResponse(`{"data": {"items": [
+		{"id": 9007199254740993, "title": "first", "price": 9.5, "tags": [{"name": "go"}, {"name": "json"}], "stats": {"views": "12"}},
+		{"title": "no id"},
+		{"id": 2, "title": "second", "price": 3}
+	]}}`)
+
+	records, err := extractor.Extract(resp)
+	if err != nil {
+		t.Fatalf("Extract 失败: %v", err)
+	}
+	if len(records) != 2 {
+		t.Fatalf("应提取 2 条记录（缺少必填字段的跳过），实际 %d", len(records))
+	}
+
+	first := records[0]
+	if first["id"] != int64(9007199254740993) {
+		t.Errorf("大整数 ID 不应丢失精度，实际 %#v", first["id"])
+	}
+	if first["price"] != 9.5 {
+		t.Errorf("price 应为 float64 9.5，实际 %#v", first["price"])
+	}
+	if !reflect.DeepEqual(first["tags"], []any{"go", "json"}) {
+		t.Errorf("tags 期望 [go json]，实际 %v", first["tags"])
+	}
+	if first["views"] != int64(12) {
+		t.Errorf("views 应按 AsInt 转换为 12，实际 %#v", first["views"])
+	}
+
+	second := records[1]
+	if second["price"] != int64(3) {
+		t.Errorf("整数 price 应为 int64，实际 %#v", second["price"])
+	}
+	if _, ok := second["tags"]; ok {
+		t.Error("缺失的可选字段不应出现在记录中")
+	}
+
+	if _, err := extractor.Extract(newJSONResponse(`{"data": `)); err == nil {
+		t.Error("无效 JSON 应返回错误")
+	}
+}
+
+func TestJSONPaginationNextURL(t *testing.T) {
+	current, _ := url.Parse("https://api.example.com/items?page=1&size=20")
+
+	tests := []struct {
+		name   string
+		p      JSONPagination
+		body   string
+		want   string
+		wantOK bool
+	}{
+		{"完整 URL", JSONPagination{NextPath: "$.paging.next"},
+			`{"paging": {"next": "https://api.example.com/items?cursor=abc"}}`, "https://api.example.com/items?cursor=abc", true},
+		{"相对路径", JSONPagination{NextPath: "$.next"},
+			`{"next": "/items?page=2"}`, "https://api.example.com/items?page=2", true},
+		{"查询参数", JSONPagination{NextPath: "$.next_page", Param: "page"},
+			`{"next_page": 2}`, "https://api.example.com/items?page=2&size=20", true},
+		{"没有更多", JSONPagination{NextPath: "$.cursor", Param: "cursor", HasMorePath: "$.has_more"},
+			`{"cursor": "abc", "has_more": false}`, "", false},
+		{"下一页为空", JSONPagination{NextPath: "$.paging.next"},
+			`{"paging": {"next": null}}`, "", false},
+	}
+	for _, tt := range tests {
+		data, _ := decodeJSON([]byte(tt.body))
+		got, ok := tt.p.nextURL(current, data)
+		if got != tt.want || ok != tt.wantOK {
+			t.Errorf("%s: nextURL = %q, %v，期望 %q, %v", tt.name, got, ok, tt.want, tt.wantOK)
+		}
+	}
+}
+
+func TestIsJSONResponse(t *testing.T) {
+	if !isJSONResponse(newJSONResponse(`{}`)) {
+		t.Error("application/json 应识别为 JSON")
+	}
+	if isJSONResponse(newTestResponse(`{}`)) {
+		t.Error("text/html 不应识别为 JSON")
+	}
+
+	resp := newTestResponse(` [1, 2]`)
+	resp.Headers.Set("Content-Type", "text/plain")
+	if !isJSONResponse(resp) {
+		t.Error("text/plain 应按正文判断")
+	}
+}
+
+func TestOnJSONPagination(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/robots.txt" {
+			return
+		}
+		page := r.URL.Query().Get("page")
+		if page == "" {
+			page = "1"
+		}
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"items": [{"id": "%s-a"}, {"id": "%s-b"}], "next": %s}`, page, page, page+"1")
+	}))
+	defer srv.Close()
+
+	cfg := DefaultConfig()
+	cfg.Delay = 0
+	cfg.RandomDelay = 0
+	cfg.JSONPagination = &JSONPagination{NextPath: "$.next", Param: "page", MaxPages: 3}
+
+	var mu sync.Mutex
+	var ids []string
+	cfg.OnJSON["$.items[*]"] = func(e *JSONElement) {
+		mu.Lock()
+		defer mu.Unlock()
+		ids = append(ids, e.String("id"))
+	}
+
+	client, err := NewClient(cfg)
+	if err != nil {
+		t.Fatalf("NewClient 失败: %v", err)
+	}
+	defer client.Close()
+
+	done := make(chan struct{})
+	go func() {
+		client.Visit(srv.URL + "/items")
+		client.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(10 * time.Second):
+		t.Fatal("翻页未结束")
+	}
+
+	want := []string{"1-a", "1-b", "11-a", "11-b", "111-a", "111-b"}
+	if !reflect.DeepEqual(ids, want) {
+		t.Errorf("应按 MaxPages 抓取 3 页，实际 %v", ids)
+	}
+}
@@ -0,0 +1,228 @@
+package collyx
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// JSONPath 编译后的 JSONPath 表达式
+//
+// 支持的语法（常用子集）：
+//
+//	$                根节点（可省略）
+//	.name / ['name'] 对象字段
+//	[0] / [-1]       数组下标（负数从末尾计算）
+//	[*] / .*         所有元素或字段值
+//	..name           递归查找字段
+type JSONPath struct {
+	expr  string
+	steps []jsonStep
+}
+
+// jsonStep 路径中的一步
+type jsonStep struct {
+	key       string // 字段名
+	index     int    // 数组下标
+	isIndex   bool
+	wildcard  bool
+	recursive bool // ..name
+}
+
+var jsonPathCache sync.Map // map[string]*JSONPath
+
+// CompileJSONPath 编译 JSONPath 表达式
+func CompileJSONPath(expr string) (*JSONPath, error) {
+	if p, ok := jsonPathCache.Load(expr); ok {
+		return p.(*JSONPath), nil
+	}
+
+	steps, err := parseJSONPath(expr)
+	if err != nil {
+		return nil, fmt.Errorf("JSONPath %s 无效: %w", expr, err)
+	}
+	p := &JSONPath{expr: expr, steps: steps}
+	jsonPathCache.Store(expr, p)
+	return p, nil
+}
+
+// MustCompileJSONPath 编译 JSONPath 表达式，无效时 panic
+func MustCompileJSONPath(expr string) *JSONPath {
+	p, err := CompileJSONPath(expr)
+	if err != nil {
+		panic(err)
+	}
+	return p
+}
+
+// String 返回原始表达式
+func (p *JSONPath) String() string {
+	return p.expr
+}
+
+// Find 返回所有匹配的值
+func (p *JSONPath) Find(data any) []any {
+	current := []any{data}
+	for _, step := range p.steps {
+		var next []any
+		for _, v := range current {
+			next = step.apply(v, next)
+		}
+		if len(next) == 0 {
+			return nil
+		}
+		current = next
+	}
+	return current
+}
+
+// First 返回第一个匹配的值
+func (p *JSONPath) First(data any) (any, bool) {
+	values := p.Find(data)
+	if len(values) == 0 {
+		return nil, false
+	}
+	return values[0], true
+}
+
+// apply 对单个值执行一步，结果追加到 out
+func (s jsonStep) apply(v any, out []any) []any {
+	if s.recursive {
+		return collectRecursive(v, s.key, out)
+	}
+
+	switch val := v.(type) {
+	case map[string]any:
+		if s.wildcard {
+			// map 无序，按字段名排序保证结果稳定
+			keys := make([]string, 0, len(val))
+			for k := range val {
+				keys = append(keys, k)
+			}
+			sort.Strings(keys)
+			for _, k := range keys {
+				out = append(out, val[k])
+			}
+		} else if !s.isIndex {
+			if child, ok := val[s.key]; ok {
+				out = append(out, child)
+			}
+		}
+	case []any:
+		switch {
+		case s.wildcard:
+			out = append(out, val...)
+		case s.isIndex:
+			i := s.index
+			if i < 0 {
+				i += len(val)
+			}
+			if i >= 0 && i < len(val) {
+				out = append(out, val[i])
+			}
+		}
+	}
+	return out
+}
+
+// collectRecursive 递归查找所有名为 key 的字段（key 为空时返回所有值）
+func collectRecursive(v any, key string, out []any) []any {
+	switch val := v.(type) {
+	case map[string]any:
+		keys := make([]string, 0, len(val))
+		for k := range val {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			if key == "" || k == key {
+				out = append(out, val[k])
+			}
+			out = collectRecursive(val[k], key, out)
+		}
+	case []any:
+		for _, child := range val {
+			if key == "" {
+				out = append(out, child)
+			}
+			out = collectRecursive(child, key, out)
+		}
+	}
+	return out
+}
+
+// parseJSONPath 解析表达式
+func parseJSONPath(expr string) ([]jsonStep, error) {
+	s := strings.TrimSpace(expr)
+	s = strings.TrimPrefix(s, "$")
+
+	var steps []jsonStep
+	for len(s) > 0 {
+		switch {
+		case strings.HasPrefix(s, ".."):
+			s = s[2:]
+			name := readJSONName(s)
+			if name == "" {
+				return nil, fmt.Errorf("'..' 后缺少字段名")
+			}
+			s = s[len(name):]
+			if name == "*" {
+				name = ""
+			}
+			steps = append(steps, jsonStep{key: name, recursive: true})
+
+		case s[0] == '.':
+			s = s[1:]
+			name := readJSONName(s)
+			if name == "" {
+				return nil, fmt.Errorf("'.' 后缺少字段名")
+			}
+			s = s[len(name):]
+			if name == "*" {
+				steps = append(steps, jsonStep{wildcard: true})
+			} else {
+				steps = append(steps, jsonStep{key: name})
+			}
+
+		case s[0] == '[':
+			end := strings.IndexByte(s, ']')
+			if end < 0 {
+				return nil, fmt.Errorf("缺少 ']'")
+			}
+			inner := strings.TrimSpace(s[1:end])
+			s = s[end+1:]
+
+			switch {
+			case inner == "*":
+				steps = append(steps, jsonStep{wildcard: true})
+			case len(inner) >= 2 && (inner[0] == '\'' || inner[0] == '"') && inner[len(inner)-1] == inner[0]:
+				steps = append(steps, jsonStep{key: inner[1 : len(inner)-1]})
+			default:
+				i, err := strconv.Atoi(inner)
+				if err != nil {
+					return nil, fmt.Errorf("不支持的下标 [%s]", inner)
+				}
+				steps = append(steps, jsonStep{index: i, isIndex: true})
+			}
+
+		default:
+			// 省略开头的 $ 和 .（如 "data.items"）
+			if len(steps) > 0 {
+				return nil, fmt.Errorf("无法解析 %q", s)
+			}
+			s = "." + s
+		}
+	}
+	return steps, nil
+}
+
+// readJSONName 读取字段名（到下一个 . 或 [ 为止）
+func readJSONName(s string) string {
+	end := strings.IndexAny(s, ".[")
+	if end < 0 {
+		return s
+	}
+	return s[:end]
+}
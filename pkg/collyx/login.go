@@ -0,0 +1,195 @@
+package collyx
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// FormLogin 表单登录流程：打开登录页 → 提取表单字段和 CSRF token → 提交表单 → 检查是否登录成功
+//
+// 作为 SessionConfig.Form 使用，登录设置的 Cookie 保存在按域名隔离的 Cookie Jar 中：
+//
+//	cfg.Session = &collyx.SessionConfig{
+//	    Form: &collyx.FormLogin{
+//	        URL:             "https://example.com/login",
+//	        Fields:          map[string]string{"username": "tom", "password": "secret"},
+//	        CSRFSelector:    `meta[name="csrf-token"]`,
+//	        CSRFField:       "_token",
+//	        SuccessSelector: "a.logout",
+//	    },
+//	    LoginPath:         "/login",
+//	    LoggedOutSelector: "a.login",
+//	}
+type FormLogin struct {
+	URL          string            // 登录页 URL
+	FormSelector string            // 登录表单选择器，默认 form；提交地址和方法取表单的 action/method（默认 POST）
+	Fields       map[string]string // 提交的字段（用户名、密码等），覆盖表单中的同名字段
+	CSRFSelector string            // CSRF token 元素选择器，取 value 或 content 属性；为空时只提交表单中的隐藏字段
+	CSRFField    string            // CSRF token 提交时的字段名，默认取元素的 name 属性
+	CSRFHeader   string            // CSRF token 同时作为该请求头注入后续请求（如 X-CSRF-Token）
+
+	// 登录结果检查（都为空时只检查状态码）
+	SuccessSelector string // 提交后页面包含该元素视为成功
+	SuccessCookie   string // 提交后存在该 Cookie 视为成功
+	FailureSelector string // 提交后页面包含该元素视为失败（如错误提示）
+}
+
+// Login 执行登录流程，返回需要注入后续请求的请求头（可作为 SessionConfig.Login 使用）
+func (f *FormLogin) Login(ctx context.Context, client *http.Client) (http.Header, error) {
+	if f.URL == "" {
+		return nil, fmt.Errorf("表单登录缺少登录页 URL")
+	}
+
+	page, doc, err := f.fetch(ctx, client, http.MethodGet, f.URL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("打开登录页失败: %w", err)
+	}
+
+	selector := f.FormSelector
+	if selector == "" {
+		selector = "form"
+	}
+	form := doc.Find(selector).First()
+	if form.Length() == 0 {
+		return nil, fmt.Errorf("登录页中未找到表单 %s", selector)
+	}
+
+	values := formValues(form)
+	headers := http.Header{}
+	if f.CSRFSelector != "" {
+		el := doc.Find(f.CSRFSelector).First()
+		token, ok := el.Attr("value")
+		if !ok {
+			token, ok = el.Attr("content")
+		}
+		if !ok || token == "" {
+			return nil, fmt.Errorf("登录页中未找到 CSRF token %s", f.CSRFSelector)
+		}
+		field := f.CSRFField
+		if field == "" {
+			field = el.AttrOr("name", "")
+		}
+		if field != "" {
+			values.Set(field, token)
+		}
+		if f.CSRFHeader != "" {
+			headers.Set(f.CSRFHeader, token)
+		}
+	}
+	for k, v := range f.Fields {
+		values.Set(k, v)
+	}
+
+	action, err := page.Parse(form.AttrOr("action", ""))
+	if err != nil {
+		return nil, fmt.Errorf("表单提交地址无效: %w", err)
+	}
+	method := strings.ToUpper(form.AttrOr("method", http.MethodPost))
+
+	final, result, err := f.fetch(ctx, client, method, action.String(), values)
+	if err != nil {
+		return nil, fmt.Errorf("提交登录表单失败: %w", err)
+	}
+	if err := f.check(client, final, result); err != nil {
+		return nil, err
+	}
+	return headers, nil
+}
+
+// fetch 发送请求（跟随重定向）并解析响应，返回最终 URL；4xx/5xx 视为失败
+func (f *FormLogin) fetch(ctx context.Context, client *http.Client, method, rawURL string, values url.Values) (*url.URL, *goquery.Document, error) {
+	var body io.Reader
+	if values != nil && method != http.MethodGet {
+		body = strings.NewReader(values.Encode())
+	}
+	req, err := http.NewRequestWithContext(ctx, method, rawURL, body)
+	if err != nil {
+		return nil, nil, err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	} else if values != nil {
+		req.URL.RawQuery = values.Encode()
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, nil, err
+	}
+	if resp.StatusCode >= http.StatusBadRequest {
+		return nil, nil, fmt.Errorf("状态码 %d", resp.StatusCode)
+	}
+	doc, err := goquery.NewDocumentFromReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, nil, err
+	}
+	return resp.Request.URL, doc, nil
+}
+
+// check 检查登录结果
+func (f *FormLogin) check(client *http.Client, final *url.URL, doc *goquery.Document) error {
+	if f.FailureSelector != "" && doc.Find(f.FailureSelector).Length() > 0 {
+		msg := strings.Join(strings.Fields(doc.Find(f.FailureSelector).First().Text()), " ")
+		return fmt.Errorf("登录失败: %s", msg)
+	}
+	if f.SuccessSelector != "" && doc.Find(f.SuccessSelector).Length() == 0 {
+		return fmt.Errorf("登录失败: 页面中未找到 %s", f.SuccessSelector)
+	}
+	if f.SuccessCookie != "" {
+		if client.Jar == nil {
+			return fmt.Errorf("登录失败: 未设置 Cookie Jar")
+		}
+		for _, c := range client.Jar.Cookies(final) {
+			if c.Name == f.SuccessCookie {
+				return nil
+			}
+		}
+		return fmt.Errorf("登录失败: 未设置 Cookie %s", f.SuccessCookie)
+	}
+	return nil
+}
+
+// formValues 表单中默认提交的字段（输入框、选中的复选框/单选框、下拉框、文本域）
+func formValues(form *goquery.Selection) url.Values {
+	values := url.Values{}
+	form.Find("input[name]").Each(func(_ int, s *goquery.Selection) {
+		name := s.AttrOr("name", "")
+		switch strings.ToLower(s.AttrOr("type", "text")) {
+		case "submit", "button", "image", "reset", "file":
+			return
+		case "checkbox", "radio":
+			if _, checked := s.Attr("checked"); !checked {
+				return
+			}
+			values.Add(name, s.AttrOr("value", "on"))
+			return
+		}
+		values.Add(name, s.AttrOr("value", ""))
+	})
+	form.Find("select[name]").Each(func(_ int, s *goquery.Selection) {
+		option := s.Find("option[selected]").First()
+		if option.Length() == 0 {
+			option = s.Find("option").First()
+		}
+		if option.Length() > 0 {
+			values.Add(s.AttrOr("name", ""), option.AttrOr("value", option.Text()))
+		}
+	})
+	form.Find("textarea[name]").Each(func(_ int, s *goquery.Selection) {
+		values.Add(s.AttrOr("name", ""), s.Text())
+	})
+	return values
+}
@@ -0,0 +1,221 @@
+package collyx
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gocolly/colly/v2"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/tedwangl/go-util/pkg/collyx/storage"
+)
+
+// CrawlProgress 爬取进度
+type CrawlProgress struct {
+	StartTime      time.Time         `json:"start_time"`
+	Uptime         string            `json:"uptime"`
+	Requests       int64             `json:"requests"`
+	Responses      int64             `json:"responses"`
+	Errors         int64             `json:"errors"`
+	Retries        int64             `json:"retries"`
+	RequestsPerSec float64           `json:"requests_per_sec"`
+	StatusCodes    map[string]int64  `json:"status_codes"`
+	QueueSize      int               `json:"queue_size"`
+	Storage        *storage.Progress `json:"storage,omitempty"` // 存储中的任务进度（启用存储时）
+}
+
+// crawlMetrics 爬虫指标（每个 Client 独立的 Prometheus Registry）
+type crawlMetrics struct {
+	registry  *prometheus.Registry
+	requests  prometheus.Counter
+	responses *prometheus.CounterVec
+	errors    prometheus.Counter
+	retries   prometheus.Counter
+	duration  prometheus.Histogram
+
+	startTime   time.Time
+	numRequests atomic.Int64
+	numResponse atomic.Int64
+	numErrors   atomic.Int64
+	numRetries  atomic.Int64
+
+	codesMu sync.Mutex
+	codes   map[string]int64
+
+	server *http.Server
+	addr   string // 实际监听地址
+}
+
+// newCrawlMetrics 创建指标
+func newCrawlMetrics(queueSize func() int) *crawlMetrics {
+	m := &crawlMetrics{
+		registry: prometheus.NewRegistry(),
+		requests: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "collyx_requests_total",
+			Help: "Total number of requests sent.",
+		}),
+		responses: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "collyx_responses_total",
+			Help: "Total number of responses by HTTP status code.",
+		}, []string{"code"}),
+		errors: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "collyx_errors_total",
+			Help: "Total number of failed requests.",
+		}),
+		retries: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "collyx_retries_total",
+			Help: "Total number of scheduled retries.",
+		}),
+		duration: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Name:    "collyx_request_duration_seconds",
+			Help:    "Request duration in seconds.",
+			Buckets: prometheus.DefBuckets,
+		}),
+		startTime: time.Now(),
+		codes:     make(map[string]int64),
+	}
+
+	m.registry.MustRegister(m.requests, m.responses, m.errors, m.retries, m.duration,
+		prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+			Name: "collyx_queue_depth",
+			Help: "Number of requests waiting in the queue.",
+		}, func() float64 {
+			return float64(queueSize())
+		}),
+	)
+
+	return m
+}
+
+// HandleRequest 记录请求
+func (m *crawlMetrics) HandleRequest(r *colly.Request) {
+	m.requests.Inc()
+	m.numRequests.Add(1)
+	r.Ctx.Put("metricsStart", time.Now())
+}
+
+// HandleResponse 记录响应
+func (m *crawlMetrics) HandleResponse(r *colly.Response) {
+	m.observe(r)
+}
+
+// HandleError 记录错误（错误响应也计入状态码统计）
+func (m *crawlMetrics) HandleError(r *colly.Response, err error) {
+	m.errors.Inc()
+	m.numErrors.Add(1)
+	m.observe(r)
+}
+
+// observe 记录状态码和耗时
+func (m *crawlMetrics) observe(r *colly.Response) {
+	code := strconv.Itoa(r.StatusCode)
+	m.responses.WithLabelValues(code).Inc()
+	m.numResponse.Add(1)
+
+	m.codesMu.Lock()
+	m.codes[code]++
+	m.codesMu.Unlock()
+
+	if start, ok := r.Request.Ctx.GetAny("metricsStart").(time.Time); ok {
+		m.duration.Observe(time.Since(start).Seconds())
+	}
+}
+
+// retry 记录重试
+func (m *crawlMetrics) retry() {
+	if m == nil {
+		return
+	}
+	m.retries.Inc()
+	m.numRetries.Add(1)
+}
+
+// progress 当前进度
+func (m *crawlMetrics) progress() *CrawlProgress {
+	uptime := time.Since(m.startTime)
+	p := &CrawlProgress{
+		StartTime:   m.startTime,
+		Uptime:      uptime.Round(time.Second).String(),
+		Requests:    m.numRequests.Load(),
+		Responses:   m.numResponse.Load(),
+		Errors:      m.numErrors.Load(),
+		Retries:     m.numRetries.Load(),
+		StatusCodes: make(map[string]int64),
+	}
+	if secs := uptime.Seconds(); secs > 0 {
+		p.RequestsPerSec = float64(p.Requests) / secs
+	}
+
+	m.codesMu.Lock()
+	for code, n := range m.codes {
+		p.StatusCodes[code] = n
+	}
+	m.codesMu.Unlock()
+
+	return p
+}
+
+// serve 启动指标服务：/metrics（Prometheus）、/progress（JSON）
+func (m *crawlMetrics) serve(addr string, progress func() *CrawlProgress) error {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{}))
+	mux.HandleFunc("/progress", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		encoder.Encode(progress())
+	})
+
+	// 先监听，端口被占用时 NewClient 直接返回错误
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+
+	m.addr = ln.Addr().String()
+	m.server = &http.Server{Addr: addr, Handler: mux}
+	go func() {
+		if err := m.server.Serve(ln); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Printf("[指标服务异常] 错误: %v", err)
+		}
+	}()
+
+	log.Printf("[指标服务启动] 地址: http://%s/metrics, http://%s/progress", m.addr, m.addr)
+	return nil
+}
+
+// close 关闭指标服务
+func (m *crawlMetrics) close() error {
+	if m.server == nil {
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	return m.server.Shutdown(ctx)
+}
+
+// Progress 获取爬取进度（需要配置 MetricsAddr）
+func (c *Client) Progress() *CrawlProgress {
+	if c.metrics == nil {
+		return nil
+	}
+
+	p := c.metrics.progress()
+	if c.queue != nil {
+		p.QueueSize = c.queue.Size()
+	}
+	if c.storage != nil {
+		if sp, err := c.storage.GetProgress(); err == nil {
+			p.Storage = sp
+		}
+	}
+	return p
+}
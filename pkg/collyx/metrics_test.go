@@ -0,0 +1,64 @@
+package collyx
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestCrawlMetrics(t *testing.T) {
+	site := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/missing" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte("<html><body>ok</body></html>"))
+	}))
+	defer site.Close()
+
+	cfg := DefaultConfig()
+	cfg.Delay = 0
+	cfg.RandomDelay = 0
+	cfg.RespectCrawlDelay = false
+	cfg.MetricsAddr = "127.0.0.1:0"
+	client, err := NewClient(cfg)
+	if err != nil {
+		t.Fatalf("NewClient 失败: %v", err)
+	}
+	defer client.Close()
+
+	client.Visit(site.URL + "/")
+	client.Visit(site.URL + "/missing")
+	client.Wait()
+
+	p := client.Progress()
+	if p.Requests != 2 || p.StatusCodes["200"] != 1 || p.StatusCodes["404"] != 1 || p.Errors != 1 {
+		t.Fatalf("进度错误: %+v", p)
+	}
+
+	resp, err := http.Get("http://" + client.metrics.addr + "/progress")
+	if err != nil {
+		t.Fatalf("请求 /progress 失败: %v", err)
+	}
+	var got CrawlProgress
+	json.NewDecoder(resp.Body).Decode(&got)
+	resp.Body.Close()
+	if got.Responses != 2 {
+		t.Errorf("/progress 返回错误: %+v", got)
+	}
+
+	resp, err = http.Get("http://" + client.metrics.addr + "/metrics")
+	if err != nil {
+		t.Fatalf("请求 /metrics 失败: %v", err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	for _, want := range []string{`collyx_requests_total 2`, `collyx_responses_total{code="404"} 1`, `collyx_queue_depth 0`} {
+		if !strings.Contains(string(body), want) {
+			t.Errorf("/metrics 缺少 %s", want)
+		}
+	}
+}
@@ -0,0 +1,413 @@
+package collyx
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/http/cookiejar"
+	"net/url"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/gobwas/glob"
+	"github.com/gocolly/colly/v2"
+	"golang.org/x/net/publicsuffix"
+)
+
+// RequestMiddleware 请求中间件：发送前修改请求，返回错误时取消该请求
+type RequestMiddleware func(r *colly.Request) error
+
+// RotateHeader 按顺序轮换请求头的值
+func RotateHeader(name string, values ...string) RequestMiddleware {
+	var n atomic.Uint64
+	return func(r *colly.Request) error {
+		if len(values) == 0 {
+			return nil
+		}
+		i := n.Add(1) - 1
+		r.Headers.Set(name, values[i%uint64(len(values))])
+		return nil
+	}
+}
+
+// RotateUserAgent 按顺序轮换 User-Agent
+func RotateUserAgent(agents ...string) RequestMiddleware {
+	return RotateHeader("User-Agent", agents...)
+}
+
+// SetHeaders 为每个请求设置固定请求头
+func SetHeaders(headers map[string]string) RequestMiddleware {
+	return func(r *colly.Request) error {
+		for k, v := range headers {
+			r.Headers.Set(k, v)
+		}
+		return nil
+	}
+}
+
+// middlewareChain 请求中间件链
+type middlewareChain []RequestMiddleware
+
+// setupMiddlewares 设置 Cookie 和请求中间件（User-Agent 轮换 → 固定请求头 → 登录会话 → 自定义中间件）
+func (c *Client) setupMiddlewares() error {
+	c.jars = NewCookieJars()
+	c.collector.SetCookieJar(c.jars)
+	for domain, cookies := range c.config.Cookies {
+		c.jars.SetCookies(&url.URL{Scheme: "https", Host: domain}, cookies)
+	}
+
+	var chain middlewareChain
+	if len(c.config.UserAgents) > 0 {
+		chain = append(chain, RotateUserAgent(c.config.UserAgents...))
+	}
+	if len(c.config.Headers) > 0 {
+		chain = append(chain, SetHeaders(c.config.Headers))
+	}
+	if c.config.Session != nil {
+		s, err := newSession(c.ctx, c.config.Session, c.jars, c.config.RequestTimeout)
+		if err != nil {
+			return err
+		}
+		chain = append(chain, s.HandleRequest)
+		c.collector.OnResponseHeaders(s.HandleResponseHeaders)
+		if c.config.Session.LoggedOutSelector != "" {
+			c.collector.OnResponse(s.HandleResponse)
+		}
+		c.collector.OnError(s.HandleError)
+	}
+	chain = append(chain, c.config.Middlewares...)
+
+	if len(chain) > 0 {
+		c.collector.OnRequest(chain.HandleRequest)
+	}
+	return nil
+}
+
+// HandleRequest 依次执行中间件，出错时取消请求
+func (m middlewareChain) HandleRequest(r *colly.Request) {
+	for _, mw := range m {
+		if err := mw(r); err != nil {
+			log.Printf("[请求中间件失败] URL: %s, 错误: %v", r.URL.String(), err)
+			r.Abort()
+			return
+		}
+	}
+}
+
+// CookieJars 按域名（注册域名，如 example.com）隔离的 Cookie Jar
+type CookieJars struct {
+	mu   sync.Mutex
+	jars map[string]*cookiejar.Jar
+}
+
+// NewCookieJars 创建按域名隔离的 Cookie Jar
+func NewCookieJars() *CookieJars {
+	return &CookieJars{jars: make(map[string]*cookiejar.Jar)}
+}
+
+// jar 获取域名对应的 Jar（不存在时创建）
+func (j *CookieJars) jar(host string) *cookiejar.Jar {
+	domain := cookieDomain(host)
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	jar, ok := j.jars[domain]
+	if !ok {
+		jar, _ = cookiejar.New(&cookiejar.Options{PublicSuffixList: publicsuffix.List})
+		j.jars[domain] = jar
+	}
+	return jar
+}
+
+// SetCookies 实现 http.CookieJar
+func (j *CookieJars) SetCookies(u *url.URL, cookies []*http.Cookie) {
+	j.jar(u.Hostname()).SetCookies(u, cookies)
+}
+
+// Cookies 实现 http.CookieJar
+func (j *CookieJars) Cookies(u *url.URL) []*http.Cookie {
+	return j.jar(u.Hostname()).Cookies(u)
+}
+
+// Reset 清空域名下的所有 Cookie（如重新登录前清除失效会话）
+func (j *CookieJars) Reset(host string) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	delete(j.jars, cookieDomain(host))
+}
+
+// cookieDomain 注册域名（sub.example.com -> example.com），无法识别时返回 host
+func cookieDomain(host string) string {
+	host = strings.ToLower(host)
+	if domain, err := publicsuffix.EffectiveTLDPlusOne(host); err == nil {
+		return domain
+	}
+	return host
+}
+
+// SessionConfig 登录会话配置
+type SessionConfig struct {
+	// 需要登录的域名（支持通配符），为空表示所有域名
+	Domains []string
+	// 登录，返回需要注入的请求头；client 与爬虫共享 Cookie，表单登录设置的 Cookie 会自动带上
+	Login func(ctx context.Context, client *http.Client) (http.Header, error)
+	// 表单登录流程（Login 为空时使用）
+	Form *FormLogin
+	// 登录页路径（如 /login），被重定向到该路径视为会话失效
+	LoginPath string
+	// 视为会话失效的状态码，默认 401
+	StatusCodes []int
+	// 页面包含该元素视为会话失效（如“登录”按钮），重新登录后重新下载页面
+	LoggedOutSelector string
+}
+
+// session 登录会话：注入请求头，会话失效时重新登录并重试请求
+type session struct {
+	cfg     *SessionConfig
+	loginFn func(ctx context.Context, client *http.Client) (http.Header, error)
+	domains []glob.Glob
+	codes   []int
+	client  *http.Client
+	ctx     context.Context
+
+	mu      sync.Mutex
+	headers http.Header
+	gen     int // 登录次数，用于合并并发的重新登录
+}
+
+// newSession 创建登录会话
+func newSession(ctx context.Context, cfg *SessionConfig, jars *CookieJars, timeout time.Duration) (*session, error) {
+	loginFn := cfg.Login
+	if loginFn == nil && cfg.Form != nil {
+		loginFn = cfg.Form.Login
+	}
+	if loginFn == nil {
+		return nil, fmt.Errorf("登录会话缺少 Login 函数或登录表单")
+	}
+
+	s := &session{
+		cfg:     cfg,
+		loginFn: loginFn,
+		codes:   cfg.StatusCodes,
+		client:  &http.Client{Jar: jars, Timeout: timeout},
+		ctx:     ctx,
+	}
+	if len(s.codes) == 0 {
+		s.codes = []int{http.StatusUnauthorized}
+	}
+
+	for _, pattern := range cfg.Domains {
+		g, err := glob.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("会话域名 %s 无效: %w", pattern, err)
+		}
+		s.domains = append(s.domains, g)
+	}
+
+	return s, nil
+}
+
+// match 是否为需要登录的域名
+func (s *session) match(host string) bool {
+	if len(s.domains) == 0 {
+		return true
+	}
+	for _, g := range s.domains {
+		if g.Match(host) {
+			return true
+		}
+	}
+	return false
+}
+
+// login 登录（gen 与当前登录次数不同说明其他请求已重新登录，直接复用）
+func (s *session) login(gen int) (http.Header, int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.headers != nil && gen != s.gen {
+		return s.headers, s.gen, nil
+	}
+
+	headers, err := s.loginFn(s.ctx, s.client)
+	if err != nil {
+		return nil, s.gen, fmt.Errorf("登录失败: %w", err)
+	}
+	if headers == nil {
+		headers = http.Header{}
+	}
+
+	s.headers = headers
+	s.gen++
+	log.Printf("[登录成功] 第 %d 次登录", s.gen)
+	return s.headers, s.gen, nil
+}
+
+// current 当前会话（未登录时先登录）
+func (s *session) current() (http.Header, int, error) {
+	s.mu.Lock()
+	headers, gen := s.headers, s.gen
+	s.mu.Unlock()
+
+	if headers != nil {
+		return headers, gen, nil
+	}
+	return s.login(gen)
+}
+
+// HandleRequest 注入会话请求头（作为请求中间件）
+func (s *session) HandleRequest(r *colly.Request) error {
+	if !s.match(r.URL.Hostname()) || s.isLoginPage(r.URL) {
+		return nil
+	}
+
+	headers, gen, err := s.current()
+	if err != nil {
+		return err
+	}
+	for k, v := range headers {
+		(*r.Headers)[k] = v
+	}
+
+	// 子请求与父请求共享 Ctx，按请求 ID 区分
+	r.Ctx.Put(sessionKey("url", r.ID), r.URL.String())
+	r.Ctx.Put(sessionKey("gen", r.ID), gen)
+	return nil
+}
+
+// HandleResponseHeaders 被重定向到登录页时中止下载，交给 HandleError 重新登录
+func (s *session) HandleResponseHeaders(r *colly.Response) {
+	if r.Request.Ctx.Get(sessionKey("url", r.Request.ID)) != "" && s.isLoginPage(r.Request.URL) {
+		r.Request.Abort()
+	}
+}
+
+// HandleResponse 页面包含 LoggedOutSelector 时重新登录并重新下载，用登录后的响应替换（后续处理器使用新响应）
+func (s *session) HandleResponse(r *colly.Response) {
+	if r.Ctx.Get(sessionKey("url", r.Request.ID)) == "" || !s.loggedOut(r.Headers.Get("Content-Type"), r.Body) {
+		return
+	}
+
+	u := r.Request.URL.String()
+	if r.Request.Method != http.MethodGet {
+		log.Printf("[会话失效] URL: %s, 只支持重新下载 GET 请求", u)
+		return
+	}
+
+	gen, _ := r.Ctx.GetAny(sessionKey("gen", r.Request.ID)).(int)
+	headers, _, err := s.login(gen)
+	if err != nil {
+		log.Printf("[重新登录失败] URL: %s, 错误: %v", u, err)
+		return
+	}
+
+	req, err := http.NewRequestWithContext(s.ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return
+	}
+	for k, v := range *r.Request.Headers {
+		req.Header[k] = v
+	}
+	req.Header.Del("Cookie") // 使用 Cookie Jar 中重新登录后的 Cookie
+	for k, v := range headers {
+		req.Header[k] = v
+	}
+	resp, err := s.client.Do(req)
+	if err != nil {
+		log.Printf("[重新下载失败] URL: %s, 错误: %v", u, err)
+		return
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		log.Printf("[重新下载失败] URL: %s, 错误: %v", u, err)
+		return
+	}
+	if s.loggedOut(resp.Header.Get("Content-Type"), body) {
+		log.Printf("[会话失效] URL: %s, 重新登录后仍未登录", u)
+		return
+	}
+
+	r.StatusCode = resp.StatusCode
+	r.Body = body
+	*r.Headers = resp.Header
+	log.Printf("[会话失效] URL: %s, 已重新登录并重新下载", u)
+}
+
+// loggedOut 页面是否包含 LoggedOutSelector（只检查 HTML）
+func (s *session) loggedOut(contentType string, body []byte) bool {
+	if s.cfg.LoggedOutSelector == "" || !strings.Contains(strings.ToLower(contentType), "html") {
+		return false
+	}
+	doc, err := goquery.NewDocumentFromReader(bytes.NewReader(body))
+	if err != nil {
+		return false
+	}
+	return doc.Find(s.cfg.LoggedOutSelector).Length() > 0
+}
+
+// HandleError 会话失效时重新登录并重试（每个请求只重试一次）
+func (s *session) HandleError(r *colly.Response, err error) {
+	origURL := r.Ctx.Get(sessionKey("url", r.Request.ID))
+	if origURL == "" || !s.expired(r, err) {
+		return
+	}
+	if r.Ctx.GetAny(sessionKey("retried", origURL)) != nil {
+		log.Printf("[会话失效] URL: %s, 重新登录后仍未通过认证", origURL)
+		return
+	}
+
+	gen, _ := r.Ctx.GetAny(sessionKey("gen", r.Request.ID)).(int)
+	if _, _, err := s.login(gen); err != nil {
+		log.Printf("[重新登录失败] URL: %s, 错误: %v", origURL, err)
+		return
+	}
+
+	// 重定向后 Request.URL 为登录页，恢复为原始 URL
+	u, err := url.Parse(origURL)
+	if err != nil {
+		return
+	}
+	r.Request.URL = u
+	r.Ctx.Put(sessionKey("retried", origURL), true)
+
+	log.Printf("[会话失效] URL: %s, 已重新登录，重试请求", origURL)
+	if err := r.Request.Retry(); err != nil {
+		log.Printf("[重试失败] URL: %s, 错误: %v", origURL, err)
+	}
+}
+
+// expired 是否为会话失效（状态码、被重定向到登录页）
+func (s *session) expired(r *colly.Response, err error) bool {
+	for _, code := range s.codes {
+		if r.StatusCode == code {
+			return true
+		}
+	}
+
+	if s.isLoginPage(r.Request.URL) {
+		return true
+	}
+
+	// 登录页已访问过时，重定向会以 AlreadyVisitedError 失败
+	var visited *colly.AlreadyVisitedError
+	return errors.As(err, &visited) && s.isLoginPage(visited.Destination)
+}
+
+// sessionKey 会话在 Ctx 中的键
+func sessionKey(name string, id any) string {
+	return fmt.Sprintf("session:%s:%v", name, id)
+}
+
+// isLoginPage 是否为登录页
+func (s *session) isLoginPage(u *url.URL) bool {
+	return s.cfg.LoginPath != "" && u != nil && strings.HasPrefix(u.Path, s.cfg.LoginPath)
+}
@@ -0,0 +1,246 @@
+package collyx
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/gocolly/colly/v2"
+)
+
+func TestRotateUserAgent(t *testing.T) {
+	var mu sync.Mutex
+	var agents []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/robots.txt" {
+			return
+		}
+		mu.Lock()
+		agents = append(agents, r.UserAgent()+"|"+r.Header.Get("X-Token"))
+		mu.Unlock()
+	}))
+	defer srv.Close()
+
+	cfg := DefaultConfig()
+	cfg.Parallelism = 1
+	cfg.Delay = 0
+	cfg.RandomDelay = 0
+	cfg.UserAgents = []string{"ua-1", "ua-2"}
+	cfg.Headers = map[string]string{"X-Token": "abc"}
+	client, err := NewClient(cfg)
+	if err != nil {
+		t.Fatalf("NewClient 失败: %v", err)
+	}
+	defer client.Close()
+
+	for _, path := range []string{"/a", "/b", "/c"} {
+		client.Visit(srv.URL + path)
+	}
+	client.Wait()
+
+	want := []string{"ua-1|abc", "ua-2|abc", "ua-1|abc"}
+	if len(agents) != len(want) {
+		t.Fatalf("期望 %d 个请求，实际 %v", len(want), agents)
+	}
+	for i := range want {
+		if agents[i] != want[i] {
+			t.Errorf("第 %d 个请求期望 %s，实际 %s", i, want[i], agents[i])
+		}
+	}
+}
+
+func TestSessionRelogin(t *testing.T) {
+	// /api 需要最新的 token，/page 需要登录 Cookie，否则重定向到 /login
+	var token atomic.Int32
+	mux := http.NewServeMux()
+	mux.HandleFunc("/auth", func(w http.ResponseWriter, r *http.Request) {
+		http.SetCookie(w, &http.Cookie{Name: "sid", Value: "ok", Path: "/"})
+	})
+	mux.HandleFunc("/api", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer 2" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Write([]byte("api"))
+	})
+	mux.HandleFunc("/page", func(w http.ResponseWriter, r *http.Request) {
+		if c, err := r.Cookie("sid"); err != nil || c.Value != "ok" {
+			http.Redirect(w, r, "/login?next=/page", http.StatusFound)
+			return
+		}
+		w.Write([]byte("page"))
+	})
+	mux.HandleFunc("/login", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("login form"))
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	var logins atomic.Int32
+	cfg := DefaultConfig()
+	cfg.Parallelism = 1
+	cfg.Delay = 0
+	cfg.RandomDelay = 0
+	cfg.Session = &SessionConfig{
+		LoginPath: "/login",
+		Login: func(ctx context.Context, client *http.Client) (http.Header, error) {
+			logins.Add(1)
+			n := token.Add(1)
+			// 前两次登录只更新 token，第三次登录才获取 Cookie
+			if n > 2 {
+				resp, err := client.Get(srv.URL + "/auth")
+				if err != nil {
+					return nil, err
+				}
+				resp.Body.Close()
+			}
+			return http.Header{"Authorization": {"Bearer " + strconv.Itoa(int(n))}}, nil
+		},
+	}
+
+	var mu sync.Mutex
+	bodies := make(map[string]string)
+	cfg.OnResponse = []func(*colly.Response){
+		func(r *colly.Response) {
+			mu.Lock()
+			bodies[r.Request.URL.Path] = string(r.Body)
+			mu.Unlock()
+		},
+	}
+
+	client, err := NewClient(cfg)
+	if err != nil {
+		t.Fatalf("NewClient 失败: %v", err)
+	}
+	defer client.Close()
+
+	client.Visit(srv.URL + "/api")
+	client.Wait()
+
+	if bodies["/api"] != "api" {
+		t.Errorf("401 后应重新登录并重试成功，实际 %v", bodies)
+	}
+
+	client.Visit(srv.URL + "/page")
+	client.Wait()
+
+	if bodies["/page"] != "page" {
+		t.Errorf("重定向到登录页后应重新登录并重试成功，实际 %v", bodies)
+	}
+	if _, ok := bodies["/login"]; ok {
+		t.Errorf("登录页不应交给响应处理器")
+	}
+	if n := logins.Load(); n != 3 {
+		t.Errorf("期望登录 3 次，实际 %d", n)
+	}
+}
+
+func TestSessionFormLogin(t *testing.T) {
+	// 登录表单带隐藏的 CSRF 字段，/page 未登录时返回带“登录”链接的页面
+	var sid atomic.Int32
+	var posts atomic.Int32
+	loggedIn := func(r *http.Request) bool {
+		c, err := r.Cookie("sid")
+		return err == nil && c.Value == strconv.Itoa(int(sid.Load()))
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/login", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet {
+			w.Header().Set("Content-Type", "text/html")
+			w.Write([]byte(`<html><head><meta name="csrf-token" content="t0k"></head><body>
+<form action="/session" method="post"><input type="hidden" name="remember" value="1">
+<input name="username"><input type="password" name="password"><input type="submit" value="登录"></form></body></html>`))
+		}
+	})
+	mux.HandleFunc("/session", func(w http.ResponseWriter, r *http.Request) {
+		posts.Add(1)
+		r.ParseForm()
+		if r.PostForm.Get("_token") != "t0k" || r.PostForm.Get("remember") != "1" ||
+			r.PostForm.Get("username") != "tom" || r.PostForm.Get("password") != "secret" {
+			w.Header().Set("Content-Type", "text/html")
+			w.Write([]byte(`<p class="error">用户名或密码错误</p>`))
+			return
+		}
+		http.SetCookie(w, &http.Cookie{Name: "sid", Value: strconv.Itoa(int(sid.Load())), Path: "/"})
+		http.Redirect(w, r, "/home", http.StatusFound)
+	})
+	mux.HandleFunc("/home", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		if loggedIn(r) {
+			w.Write([]byte(`<a class="logout" href="/logout">退出</a>`))
+		}
+	})
+	mux.HandleFunc("/page/", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		if !loggedIn(r) {
+			w.Write([]byte(`<a class="login" href="/login">登录</a>`))
+			return
+		}
+		w.Write([]byte(`<p class="content">` + r.URL.Path + `</p>`))
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	cfg := DefaultConfig()
+	cfg.Parallelism = 1
+	cfg.Delay = 0
+	cfg.RandomDelay = 0
+	cfg.Session = &SessionConfig{
+		Form: &FormLogin{
+			URL:             srv.URL + "/login",
+			Fields:          map[string]string{"username": "tom", "password": "secret"},
+			CSRFSelector:    `meta[name="csrf-token"]`,
+			CSRFField:       "_token",
+			CSRFHeader:      "X-CSRF-Token",
+			SuccessSelector: "a.logout",
+			FailureSelector: ".error",
+		},
+		LoginPath:         "/login",
+		LoggedOutSelector: "a.login",
+	}
+
+	var mu sync.Mutex
+	var contents []string
+	cfg.OnHTML = map[string]func(*colly.HTMLElement){
+		"p.content": func(e *colly.HTMLElement) {
+			mu.Lock()
+			contents = append(contents, e.Text+"|"+e.Request.Headers.Get("X-CSRF-Token"))
+			mu.Unlock()
+		},
+	}
+
+	client, err := NewClient(cfg)
+	if err != nil {
+		t.Fatalf("NewClient 失败: %v", err)
+	}
+	defer client.Close()
+
+	client.Visit(srv.URL + "/page/1")
+	client.Wait()
+
+	// 服务端会话失效后，页面显示“登录”链接，应重新登录并重新下载
+	sid.Add(1)
+	client.Visit(srv.URL + "/page/2")
+	client.Wait()
+
+	want := []string{"/page/1|t0k", "/page/2|t0k"}
+	if len(contents) != len(want) || contents[0] != want[0] || contents[1] != want[1] {
+		t.Errorf("期望 %v，实际 %v", want, contents)
+	}
+	if n := posts.Load(); n != 2 {
+		t.Errorf("期望提交登录表单 2 次，实际 %d", n)
+	}
+
+	// 登录失败时返回页面中的错误信息
+	form := *cfg.Session.Form
+	form.Fields = map[string]string{"username": "tom", "password": "wrong"}
+	_, err = form.Login(context.Background(), &http.Client{Jar: NewCookieJars()})
+	if err == nil || !strings.Contains(err.Error(), "用户名或密码错误") {
+		t.Errorf("期望登录失败，实际 %v", err)
+	}
+}
@@ -0,0 +1,219 @@
+package collyx
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+	"net/url"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/gocolly/colly/v2"
+)
+
+// canonicalKey 上下文中记录 canonical 信息的 key（子请求共享上下文，按请求 URL 区分）
+func canonicalKey(name, url string) string {
+	return "collyx.canonical." + name + ":" + url
+}
+
+// DefaultStripParams 默认去除的跟踪参数
+var DefaultStripParams = []string{
+	"utm_*", "fbclid", "gclid", "dclid", "msclkid", "yclid",
+	"mc_cid", "mc_eid", "_ga", "_gl", "spm", "ref_src",
+}
+
+// URLNormalizer URL 规范化配置，在去重和入队前执行
+//
+//	cfg.Normalize = collyx.DefaultURLNormalizer()
+//	// https://Example.com:443/a/../b?utm_source=x&b=2&a=1#top → https://example.com/b?a=1&b=2
+type URLNormalizer struct {
+	StripParams  []string // 去除的查询参数（支持 * 通配，不区分大小写）
+	SortQuery    bool     // 按参数名排序查询参数
+	KeepFragment bool     // 保留 #fragment，默认去除
+	Canonical    bool     // 遵守 <link rel="canonical">：已抓取内容的其他 URL 不再抓取
+}
+
+// DefaultURLNormalizer 返回默认规范化配置（去除跟踪参数、排序查询参数、遵守 canonical）
+func DefaultURLNormalizer() *URLNormalizer {
+	return &URLNormalizer{
+		StripParams: DefaultStripParams,
+		SortQuery:   true,
+		Canonical:   true,
+	}
+}
+
+// Normalize 规范化 URL，base 不为 nil 时解析相对路径和协议相对 URL（//host/path）
+//
+// 规则：scheme 和 host 转小写、去除默认端口、解析 . 和 .. 路径、空路径补 /、
+// 去除跟踪参数、按配置排序查询参数和去除 fragment
+func (n *URLNormalizer) Normalize(raw string, base *url.URL) (string, error) {
+	ref, err := url.Parse(strings.TrimSpace(raw))
+	if err != nil {
+		return "", err
+	}
+	if base == nil {
+		base = &url.URL{}
+	}
+	u := base.ResolveReference(ref)
+	if u.Scheme == "" || u.Host == "" {
+		return "", fmt.Errorf("不是绝对 URL: %s", raw)
+	}
+
+	u.Scheme = strings.ToLower(u.Scheme)
+	u.Host = strings.ToLower(u.Host)
+	if port := u.Port(); (u.Scheme == "http" && port == "80") || (u.Scheme == "https" && port == "443") {
+		u.Host = strings.TrimSuffix(u.Host, ":"+port)
+	}
+	if u.Path == "" {
+		u.Path = "/"
+	}
+	if !n.KeepFragment {
+		u.Fragment = ""
+		u.RawFragment = ""
+	}
+	u.RawQuery = n.normalizeQuery(u.RawQuery)
+	u.ForceQuery = false
+	return u.String(), nil
+}
+
+// normalizeQuery 去除跟踪参数并排序（保留未改动参数的原始编码）
+func (n *URLNormalizer) normalizeQuery(rawQuery string) string {
+	if rawQuery == "" {
+		return ""
+	}
+
+	parts := strings.Split(rawQuery, "&")
+	kept := parts[:0]
+	for _, part := range parts {
+		if part == "" {
+			continue
+		}
+		name, _, _ := strings.Cut(part, "=")
+		if unescaped, err := url.QueryUnescape(name); err == nil {
+			name = unescaped
+		}
+		if !n.stripped(name) {
+			kept = append(kept, part)
+		}
+	}
+
+	if n.SortQuery {
+		// 只按参数名排序，同名参数保持原有顺序
+		sort.SliceStable(kept, func(i, j int) bool {
+			a, _, _ := strings.Cut(kept[i], "=")
+			b, _, _ := strings.Cut(kept[j], "=")
+			return a < b
+		})
+	}
+	return strings.Join(kept, "&")
+}
+
+// stripped 参数是否需要去除
+func (n *URLNormalizer) stripped(name string) bool {
+	name = strings.ToLower(name)
+	for _, pattern := range n.StripParams {
+		if ok, _ := path.Match(strings.ToLower(pattern), name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// normalizeURL 规范化 URL（未启用或解析失败时原样返回）
+func (c *Client) normalizeURL(raw string, base *url.URL) string {
+	if c.config.Normalize == nil {
+		return raw
+	}
+	normalized, err := c.config.Normalize.Normalize(raw, base)
+	if err != nil {
+		return raw
+	}
+	return normalized
+}
+
+// setupNormalizer 设置 URL 规范化（需在礼貌策略和请求中间件之前执行）
+func (c *Client) setupNormalizer() {
+	if c.config.Normalize == nil {
+		return
+	}
+	c.collector.OnRequest(c.handleNormalize)
+	if c.config.Normalize.Canonical {
+		c.collector.OnResponse(c.handleCanonical)
+	}
+}
+
+// handleNormalize 规范化请求 URL，规范化后已抓取过的请求取消
+func (c *Client) handleNormalize(r *colly.Request) {
+	normalized, err := c.config.Normalize.Normalize(r.URL.String(), nil)
+	if err != nil {
+		return
+	}
+	if normalized != r.URL.String() {
+		u, err := url.Parse(normalized)
+		if err != nil {
+			return
+		}
+		// colly 使用同一个 *url.URL 发送请求，需原地修改
+		*r.URL = *u
+	}
+
+	if c.config.AllowURLRevisit || r.Method != "GET" || isRetry(r) {
+		return
+	}
+	if _, loaded := c.fetched.LoadOrStore(normalized, struct{}{}); loaded {
+		log.Printf("[跳过请求] URL: %s, 原因: 规范化后已抓取", normalized)
+		r.Abort()
+	}
+}
+
+// handleCanonical 记录页面的 canonical URL，之后不再抓取该 URL；
+// canonical 已抓取过时标记为重复，流水线跳过该响应
+func (c *Client) handleCanonical(resp *colly.Response) {
+	if !strings.Contains(strings.ToLower(resp.Headers.Get("Content-Type")), "html") {
+		return
+	}
+
+	doc, err := goquery.NewDocumentFromReader(bytes.NewReader(resp.Body))
+	if err != nil {
+		return
+	}
+	href, ok := doc.Find(`link[rel~="canonical"]`).First().Attr("href")
+	if !ok || strings.TrimSpace(href) == "" {
+		return
+	}
+
+	canonical, err := c.config.Normalize.Normalize(href, resp.Request.URL)
+	if err != nil || canonical == resp.Request.URL.String() {
+		return
+	}
+	current := resp.Request.URL.String()
+	resp.Ctx.Put(canonicalKey("url", current), canonical)
+	if c.config.AllowURLRevisit {
+		return
+	}
+	if _, loaded := c.fetched.LoadOrStore(canonical, struct{}{}); loaded {
+		resp.Ctx.Put(canonicalKey("duplicate", current), "1")
+	}
+}
+
+// CanonicalURL 返回响应的 canonical URL（启用 Canonical 且页面声明时），否则返回请求 URL
+func CanonicalURL(resp *colly.Response) string {
+	current := resp.Request.URL.String()
+	if canonical := resp.Ctx.Get(canonicalKey("url", current)); canonical != "" {
+		return canonical
+	}
+	return current
+}
+
+// isCanonicalDuplicate 响应的 canonical 内容是否已抓取过
+func isCanonicalDuplicate(resp *colly.Response) bool {
+	return resp.Ctx.Get(canonicalKey("duplicate", resp.Request.URL.String())) != ""
+}
+
+// isRetry 是否为重试请求（重试沿用原请求的上下文）
+func isRetry(r *colly.Request) bool {
+	u := r.URL.String()
+	return r.Ctx.GetAny(retryKey("count", u)) != nil || r.Ctx.GetAny(sessionKey("retried", u)) != nil
+}
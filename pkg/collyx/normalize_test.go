@@ -0,0 +1,111 @@
+package collyx
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+
+	"github.com/gocolly/colly/v2"
+)
+
+func TestURLNormalizer(t *testing.T) {
+	n := DefaultURLNormalizer()
+	base, _ := url.Parse("https://example.com/list/page.html?p=1")
+
+	tests := []struct {
+		raw  string
+		want string
+	}{
+		{"https://Example.COM:443/a/../b?utm_source=x&b=2&a=1#top", "https://example.com/b?a=1&b=2"},
+		{"http://example.com:80", "http://example.com/"},
+		{"http://example.com:8080/x", "http://example.com:8080/x"},
+		{"https://example.com/?UTM_Medium=mail&fbclid=abc&q=go", "https://example.com/?q=go"},
+		{"https://example.com/s?tag=b&tag=a&id=1", "https://example.com/s?id=1&tag=b&tag=a"},
+		{"https://example.com/s?q=a%20b&utm_x=1", "https://example.com/s?q=a%20b"},
+		{"detail.html?id=3", "https://example.com/list/detail.html?id=3"},
+		{"../item/4", "https://example.com/item/4"},
+		{"//cdn.example.com/img.png", "https://cdn.example.com/img.png"},
+	}
+	for _, tt := range tests {
+		got, err := n.Normalize(tt.raw, base)
+		if err != nil {
+			t.Errorf("Normalize(%q) 失败: %v", tt.raw, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("Normalize(%q) = %q，期望 %q", tt.raw, got, tt.want)
+		}
+	}
+
+	keep := &URLNormalizer{KeepFragment: true}
+	if got, _ := keep.Normalize("https://example.com/a?b=1&a=2#x", nil); got != "https://example.com/a?b=1&a=2#x" {
+		t.Errorf("不排序且保留 fragment 时结果为 %q", got)
+	}
+
+	if _, err := n.Normalize("/relative", nil); err == nil {
+		t.Error("没有 base 的相对 URL 应返回错误")
+	}
+}
+
+func TestNormalizeDedupe(t *testing.T) {
+	var mu sync.Mutex
+	hits := make(map[string]int)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		hits[r.URL.RequestURI()]++
+		mu.Unlock()
+
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		switch r.URL.Path {
+		case "/alias":
+			fmt.Fprint(w, `<html><head><link rel="canonical" href="/page"></head><body><h1>page</h1></body></html>`)
+		default:
+			fmt.Fprint(w, `<html><body><h1>page</h1></body></html>`)
+		}
+	}))
+	defer srv.Close()
+
+	cfg := DefaultConfig()
+	cfg.Delay = 0
+	cfg.RandomDelay = 0
+	cfg.Normalize = DefaultURLNormalizer()
+
+	var canonical string
+	cfg.OnResponse = []func(*colly.Response){
+		func(r *colly.Response) {
+			if r.Request.URL.Path == "/alias" {
+				canonical = CanonicalURL(r)
+			}
+		},
+	}
+
+	client, err := NewClient(cfg)
+	if err != nil {
+		t.Fatalf("NewClient 失败: %v", err)
+	}
+	defer client.Close()
+
+	for _, u := range []string{
+		"/list?b=2&a=1",
+		"/list?a=1&b=2&utm_source=feed",
+		"/list?utm_campaign=x&a=1&b=2#comments",
+		"/alias",
+		"/page",
+	} {
+		client.Visit(srv.URL + u)
+	}
+	client.Wait()
+
+	if hits["/list?a=1&b=2"] != 1 || hits["/list?b=2&a=1"] != 0 {
+		t.Errorf("规范化后相同的 URL 只应抓取一次，实际 %v", hits)
+	}
+	if hits["/page"] != 0 {
+		t.Errorf("canonical 已抓取的 URL 不应再抓取，实际 %v", hits)
+	}
+	if canonical != srv.URL+"/page" {
+		t.Errorf("CanonicalURL = %q，期望 %q", canonical, srv.URL+"/page")
+	}
+}
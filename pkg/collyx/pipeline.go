@@ -0,0 +1,296 @@
+package collyx
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gocolly/colly/v2"
+	"github.com/tedwangl/go-util/pkg/collyx/storage"
+)
+
+// TransformFunc 记录转换，返回 nil 表示丢弃该记录
+type TransformFunc func(resp *colly.Response, record Record) (Record, error)
+
+// Pipeline 结构化抓取流水线：提取 → 转换 → 去重（精确/近似） → 存储
+//
+//	p := collyx.NewPipeline(collyx.NewExtractor(
+//	    collyx.Field("title", "h1", collyx.Required),
+//	    collyx.Field("price", ".price", collyx.AsFloat),
+//	)).Dedupe("title").OnItem(func(r *colly.Response, rec collyx.Record) {
+//	    fmt.Println(rec["title"], rec["price"])
+//	})
+//	cfg.Pipelines = []*collyx.Pipeline{p}
+type Pipeline struct {
+	extractor  Extractor
+	match      func(resp *colly.Response) bool
+	transforms []TransformFunc
+	dedupe     bool
+	dedupeKeys []string
+	simFields  []string
+	simDist    int // 近似去重海明距离，-1 表示不启用
+	storage    storage.Storage
+	handlers   []func(resp *colly.Response, record Record)
+
+	mu           sync.Mutex
+	seen         map[string]struct{}
+	fingerprints []uint64
+}
+
+// NewPipeline 创建流水线
+func NewPipeline(extractor Extractor) *Pipeline {
+	return &Pipeline{
+		extractor: extractor,
+		simDist:   -1,
+		seen:      make(map[string]struct{}),
+	}
+}
+
+// Match 只处理满足条件的响应（如按 URL 过滤详情页）
+func (p *Pipeline) Match(fn func(resp *colly.Response) bool) *Pipeline {
+	p.match = fn
+	return p
+}
+
+// Transform 添加转换步骤
+func (p *Pipeline) Transform(fn TransformFunc) *Pipeline {
+	p.transforms = append(p.transforms, fn)
+	return p
+}
+
+// Dedupe 按字段去重（不指定字段时按整条记录去重）
+func (p *Pipeline) Dedupe(fields ...string) *Pipeline {
+	p.dedupe = true
+	p.dedupeKeys = fields
+	return p
+}
+
+// NearDedupe 按 SimHash 近似去重：与已有内容的海明距离不超过 maxDistance 时跳过
+// 指纹基于 fields 的文本计算（不指定时使用所有字符串字段），maxDistance 通常取 3
+func (p *Pipeline) NearDedupe(maxDistance int, fields ...string) *Pipeline {
+	p.simDist = maxDistance
+	p.simFields = fields
+	return p
+}
+
+// Store 指定存储（不指定时使用客户端的存储）
+func (p *Pipeline) Store(s storage.Storage) *Pipeline {
+	p.storage = s
+	return p
+}
+
+// OnItem 添加记录处理器
+func (p *Pipeline) OnItem(fn func(resp *colly.Response, record Record)) *Pipeline {
+	p.handlers = append(p.handlers, fn)
+	return p
+}
+
+// Process 处理响应，返回保留下来的记录
+func (p *Pipeline) Process(resp *colly.Response) ([]Record, error) {
+	if p.match != nil && !p.match(resp) {
+		return nil, nil
+	}
+
+	records, err := p.extractor.Extract(resp)
+	if err != nil {
+		return nil, fmt.Errorf("提取失败: %w", err)
+	}
+
+	url := CanonicalURL(resp)
+	kept := make([]Record, 0, len(records))
+
+	for i, record := range records {
+		// 转换
+		for _, transform := range p.transforms {
+			if record, err = transform(resp, record); err != nil {
+				return kept, fmt.Errorf("转换失败: %w", err)
+			}
+			if record == nil {
+				break
+			}
+		}
+		if record == nil {
+			continue
+		}
+
+		data, err := json.Marshal(record)
+		if err != nil {
+			return kept, fmt.Errorf("序列化失败: %w", err)
+		}
+		contentHash := storage.HashContent(data)
+
+		// 去重（启用去重时 ContentHash 保存去重 key，重启后通过存储继续去重）
+		if p.dedupe {
+			if contentHash, err = p.dedupeKey(record, contentHash); err != nil {
+				return kept, err
+			}
+			if p.isDuplicate(contentHash) {
+				continue
+			}
+			if p.storage != nil {
+				if skip, _, _ := storage.ShouldSkipItem(p.storage, contentHash); skip {
+					continue
+				}
+			}
+		}
+
+		// 近似去重
+		var simHash uint64
+		if p.simDist >= 0 {
+			simHash = storage.SimHash(p.simText(record))
+			if p.isSimilar(simHash) {
+				continue
+			}
+			if p.storage != nil {
+				if skip, _, _ := storage.ShouldSkipSimilarItem(p.storage, simHash, p.simDist); skip {
+					continue
+				}
+			}
+		}
+
+		// 存储
+		if p.storage != nil {
+			item := &storage.Item{
+				ID:          fmt.Sprintf("%s-%d", storage.HashURL(url), i),
+				TaskID:      storage.HashURL(url),
+				URL:         url,
+				Type:        storage.ItemTypeData,
+				Status:      storage.ItemStatusSaved,
+				Content:     string(data),
+				ContentHash: contentHash,
+				Size:        int64(len(data)),
+				Metadata:    record,
+				CreatedAt:   time.Now(),
+			}
+			if title, ok := record["title"].(string); ok {
+				item.Title = title
+			}
+			if p.simDist >= 0 {
+				item.SetSimHash(simHash)
+			}
+			if err := p.storage.SaveItem(item); err != nil {
+				// 撤销记录的 key 和指纹，下次抓到相同内容时重新保存
+				p.forget(contentHash, simHash)
+				return kept, fmt.Errorf("保存失败: %w", err)
+			}
+		}
+
+		for _, handler := range p.handlers {
+			handler(resp, record)
+		}
+		kept = append(kept, record)
+	}
+
+	return kept, nil
+}
+
+// dedupeKey 计算去重 key
+func (p *Pipeline) dedupeKey(record Record, contentHash string) (string, error) {
+	if len(p.dedupeKeys) == 0 {
+		return contentHash, nil
+	}
+
+	keys := append([]string(nil), p.dedupeKeys...)
+	sort.Strings(keys)
+
+	parts := make([]string, 0, len(keys))
+	for _, k := range keys {
+		v, err := json.Marshal(record[k])
+		if err != nil {
+			return "", fmt.Errorf("序列化去重字段 %s 失败: %w", k, err)
+		}
+		parts = append(parts, k+"="+string(v))
+	}
+	return storage.HashContent([]byte(strings.Join(parts, "&"))), nil
+}
+
+// isDuplicate 是否重复（同时记录 key，保存失败时由 forget 撤销）
+func (p *Pipeline) isDuplicate(key string) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if _, ok := p.seen[key]; ok {
+		return true
+	}
+	p.seen[key] = struct{}{}
+	return false
+}
+
+// forget 撤销 isDuplicate/isSimilar 记录的 key 和指纹
+func (p *Pipeline) forget(key string, simHash uint64) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.dedupe {
+		delete(p.seen, key)
+	}
+	if p.simDist >= 0 && simHash != 0 {
+		for i := len(p.fingerprints) - 1; i >= 0; i-- {
+			if p.fingerprints[i] == simHash {
+				p.fingerprints = append(p.fingerprints[:i], p.fingerprints[i+1:]...)
+				break
+			}
+		}
+	}
+}
+
+// simText 计算指纹使用的文本
+func (p *Pipeline) simText(record Record) string {
+	fields := p.simFields
+	if len(fields) == 0 {
+		for k, v := range record {
+			if _, ok := v.(string); ok {
+				fields = append(fields, k)
+			}
+		}
+		sort.Strings(fields)
+	}
+
+	parts := make([]string, 0, len(fields))
+	for _, k := range fields {
+		if v, ok := record[k]; ok {
+			parts = append(parts, fmt.Sprint(v))
+		}
+	}
+	return strings.Join(parts, "\n")
+}
+
+// isSimilar 是否与本次运行中已保留的内容近似（同时记录指纹，保存失败时由 forget 撤销）
+func (p *Pipeline) isSimilar(simHash uint64) bool {
+	if simHash == 0 {
+		return false
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for _, fp := range p.fingerprints {
+		if storage.HammingDistance(fp, simHash) <= p.simDist {
+			return true
+		}
+	}
+	p.fingerprints = append(p.fingerprints, simHash)
+	return false
+}
+
+// handleResponse colly 响应处理器（JSONExtractor 只处理 JSON 响应，其他提取器只处理 HTML）
+// canonical 内容已抓取过的响应跳过
+func (p *Pipeline) handleResponse(resp *colly.Response) {
+	if isCanonicalDuplicate(resp) {
+		return
+	}
+	if _, ok := p.extractor.(*JSONExtractor); ok {
+		if !isJSONResponse(resp) {
+			return
+		}
+	} else if !strings.Contains(strings.ToLower(resp.Headers.Get("Content-Type")), "html") {
+		return
+	}
+	if _, err := p.Process(resp); err != nil {
+		log.Printf("[流水线处理失败] URL: %s, 错误: %v", resp.Request.URL.String(), err)
+	}
+}
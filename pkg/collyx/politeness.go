@@ -0,0 +1,261 @@
+package collyx
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gobwas/glob"
+	"github.com/gocolly/colly/v2"
+	"github.com/temoto/robotstxt"
+)
+
+// DomainPolicy 单个域名的抓取策略
+type DomainPolicy struct {
+	Parallelism  int           // 并发数，0 使用全局配置
+	Delay        time.Duration // 延迟，0 使用全局配置
+	RandomDelay  time.Duration // 随机延迟，0 使用全局配置
+	AllowedPaths []string      // 允许抓取的路径前缀，为空不限制
+	MaxPages     int           // 最多抓取页面数（匹配该规则的所有 host 合计），0 不限制
+}
+
+// domainRule 编译后的域名策略
+type domainRule struct {
+	pattern string
+	glob    glob.Glob
+	policy  DomainPolicy
+	pages   int // 已占用的页面配额（politeness.mu 保护）
+}
+
+// hostState 单个 host 的抓取状态
+type hostState struct {
+	robotsLoaded bool
+	crawlDelay   time.Duration
+	next         time.Time // 下一次允许请求的时间（crawl-delay）
+}
+
+// politeness 按域名限流、路径和页数限制，以及 robots.txt Crawl-delay
+type politeness struct {
+	ctx               context.Context // 爬虫停止时取消 Crawl-delay 等待
+	rules             []*domainRule
+	respectCrawlDelay bool
+	userAgent         string
+	httpClient        *http.Client
+
+	mu    sync.Mutex
+	hosts map[string]*hostState
+}
+
+// newPoliteness 根据配置创建策略（越具体的域名越优先匹配）
+func newPoliteness(ctx context.Context, cfg *Config) (*politeness, error) {
+	p := &politeness{
+		ctx:               ctx,
+		respectCrawlDelay: cfg.RespectCrawlDelay,
+		userAgent:         cfg.UserAgent,
+		httpClient:        &http.Client{Timeout: 10 * time.Second},
+		hosts:             make(map[string]*hostState),
+	}
+
+	for pattern, policy := range cfg.DomainPolicies {
+		g, err := glob.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("域名规则 %s 无效: %w", pattern, err)
+		}
+		p.rules = append(p.rules, &domainRule{pattern: pattern, glob: g, policy: policy})
+	}
+
+	// 通配符越少、越长的规则越具体
+	sort.Slice(p.rules, func(i, j int) bool {
+		wi, wj := strings.Count(p.rules[i].pattern, "*"), strings.Count(p.rules[j].pattern, "*")
+		if wi != wj {
+			return wi < wj
+		}
+		return len(p.rules[i].pattern) > len(p.rules[j].pattern)
+	})
+
+	return p, nil
+}
+
+// limitRules 按域名生成限流规则（需在全局规则之前注册，colly 使用第一个匹配的规则）
+func (p *politeness) limitRules(cfg *Config) []*colly.LimitRule {
+	rules := make([]*colly.LimitRule, 0, len(p.rules))
+	for _, r := range p.rules {
+		rule := &colly.LimitRule{
+			DomainGlob:  r.pattern,
+			Parallelism: cfg.Parallelism,
+			Delay:       cfg.Delay,
+			RandomDelay: cfg.RandomDelay,
+		}
+		if r.policy.Parallelism > 0 {
+			rule.Parallelism = r.policy.Parallelism
+		}
+		if r.policy.Delay > 0 {
+			rule.Delay = r.policy.Delay
+		}
+		if r.policy.RandomDelay > 0 {
+			rule.RandomDelay = r.policy.RandomDelay
+		}
+		rules = append(rules, rule)
+	}
+	return rules
+}
+
+// match 查找 host 对应的规则
+func (p *politeness) match(host string) *domainRule {
+	for _, r := range p.rules {
+		if r.glob.Match(host) {
+			return r
+		}
+	}
+	return nil
+}
+
+// HandleRequest 检查路径和页数限制，并按 Crawl-delay 等待（爬虫停止时取消请求）
+func (p *politeness) HandleRequest(r *colly.Request) {
+	host := r.URL.Host
+
+	if rule := p.match(host); rule != nil {
+		policy := &rule.policy
+		if !pathAllowed(r.URL.Path, policy.AllowedPaths) {
+			log.Printf("[跳过请求] URL: %s, 原因: 路径不在允许范围内", r.URL.String())
+			r.Abort()
+			return
+		}
+		if policy.MaxPages > 0 && !p.takePage(rule) {
+			log.Printf("[跳过请求] URL: %s, 原因: 已达到最大页面数 %d", r.URL.String(), policy.MaxPages)
+			r.Abort()
+			return
+		}
+	}
+
+	if p.respectCrawlDelay {
+		if wait := p.reserve(r.URL.Scheme, host); wait > 0 {
+			timer := time.NewTimer(wait)
+			defer timer.Stop()
+
+			select {
+			case <-timer.C:
+			case <-p.ctx.Done():
+				r.Abort()
+			}
+		}
+	}
+}
+
+// takePage 占用规则的一个页面配额
+func (p *politeness) takePage(rule *domainRule) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if rule.pages >= rule.policy.MaxPages {
+		return false
+	}
+	rule.pages++
+	return true
+}
+
+// reserve 预约下一次请求时间，返回需要等待的时长
+func (p *politeness) reserve(scheme, host string) time.Duration {
+	delay := p.crawlDelay(scheme, host)
+	if delay <= 0 {
+		return 0
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	state := p.state(host)
+	now := time.Now()
+	slot := state.next
+	if slot.Before(now) {
+		slot = now
+	}
+	state.next = slot.Add(delay)
+	return slot.Sub(now)
+}
+
+// crawlDelay 获取 host 的 Crawl-delay（每个 host 只请求一次 robots.txt）
+func (p *politeness) crawlDelay(scheme, host string) time.Duration {
+	p.mu.Lock()
+	state := p.state(host)
+	if state.robotsLoaded {
+		delay := state.crawlDelay
+		p.mu.Unlock()
+		return delay
+	}
+	p.mu.Unlock()
+
+	delay := p.fetchCrawlDelay(scheme, host)
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	state.robotsLoaded = true
+	state.crawlDelay = delay
+	return delay
+}
+
+// fetchCrawlDelay 请求 robots.txt 并解析 Crawl-delay（失败时返回 0）
+func (p *politeness) fetchCrawlDelay(scheme, host string) time.Duration {
+	if scheme == "" {
+		scheme = "http"
+	}
+
+	req, err := http.NewRequestWithContext(p.ctx, "GET", scheme+"://"+host+"/robots.txt", nil)
+	if err != nil {
+		return 0
+	}
+	req.Header.Set("User-Agent", p.userAgent)
+
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		log.Printf("[robots.txt 获取失败] Host: %s, 错误: %v", host, err)
+		return 0
+	}
+	defer resp.Body.Close()
+
+	robots, err := robotstxt.FromResponse(resp)
+	if err != nil {
+		log.Printf("[robots.txt 解析失败] Host: %s, 错误: %v", host, err)
+		return 0
+	}
+
+	group := robots.FindGroup(p.userAgent)
+	if group == nil {
+		return 0
+	}
+	if group.CrawlDelay > 0 {
+		log.Printf("[Crawl-delay] Host: %s, 延迟: %v", host, group.CrawlDelay)
+	}
+	return group.CrawlDelay
+}
+
+// state 获取 host 状态（调用方需持有锁）
+func (p *politeness) state(host string) *hostState {
+	state, ok := p.hosts[host]
+	if !ok {
+		state = &hostState{}
+		p.hosts[host] = state
+	}
+	return state
+}
+
+// pathAllowed 路径是否在允许的前缀内
+func pathAllowed(path string, allowed []string) bool {
+	if len(allowed) == 0 {
+		return true
+	}
+	if path == "" {
+		path = "/"
+	}
+	for _, prefix := range allowed {
+		if strings.HasPrefix(path, prefix) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,68 @@
+package collyx
+
+import (
+	"context"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/gocolly/colly/v2"
+)
+
+func TestPoliteness_CrawlDelayCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cfg := DefaultConfig()
+	cfg.RespectCrawlDelay = true
+	p, err := newPoliteness(ctx, cfg)
+	if err != nil {
+		t.Fatalf("newPoliteness 失败: %v", err)
+	}
+
+	// robots.txt 已加载，Crawl-delay 为 1 分钟
+	state := p.state("example.com")
+	state.robotsLoaded = true
+	state.crawlDelay = time.Minute
+
+	u, _ := url.Parse("https://example.com/a")
+	p.HandleRequest(&colly.Request{URL: u, Ctx: colly.NewContext()})
+
+	// 第二个请求需要等待，停止爬虫后立即返回
+	time.AfterFunc(50*time.Millisecond, cancel)
+	start := time.Now()
+	p.HandleRequest(&colly.Request{URL: u, Ctx: colly.NewContext()})
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("停止后应取消 Crawl-delay 等待，实际等待 %v", elapsed)
+	}
+}
+
+func TestPoliteness_MaxPagesPerPolicy(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.DomainPolicies = map[string]DomainPolicy{
+		"*.example.com": {MaxPages: 2},
+		"example.org":   {MaxPages: 1},
+	}
+	p, err := newPoliteness(context.Background(), cfg)
+	if err != nil {
+		t.Fatalf("newPoliteness 失败: %v", err)
+	}
+
+	allowed := func(rawURL string) bool {
+		u, _ := url.Parse(rawURL)
+		r := &colly.Request{URL: u, Ctx: colly.NewContext()}
+		p.HandleRequest(r)
+		return !r.IsAbort()
+	}
+
+	// 同一规则匹配的多个 host 共享页面配额
+	if !allowed("https://a.example.com/1") || !allowed("https://b.example.com/1") {
+		t.Fatal("配额内的请求应放行")
+	}
+	if allowed("https://c.example.com/1") {
+		t.Error("规则配额用完后其他 host 也应跳过")
+	}
+
+	// 不同规则的配额互不影响
+	if !allowed("https://example.org/1") || allowed("https://example.org/2") {
+		t.Error("example.org 应单独计数")
+	}
+}
@@ -11,8 +11,23 @@ import (
 	"time"
 )
 
+// QueueBackend 队列后端
+//
+// Pop 取出的请求处于租约中，处理完成后必须 Ack；Nack 将请求放回队列。
+// 分布式后端（如 Redis）中，租约超时未确认的请求会重新入队
+type QueueBackend interface {
+	Add(req *Request) error  // 添加请求
+	Pop() (*Request, error)  // 取出优先级最高的请求，队列为空时返回 nil
+	Ack(req *Request) error  // 确认请求已处理
+	Nack(req *Request) error // 放回队列
+	Size() int               // 待处理请求数
+	Clear() error            // 清空队列
+	Close() error            // 关闭队列
+}
+
 // Request 请求
 type Request struct {
+	ID        string            `json:"id,omitempty"` // 队列内部 ID（由队列后端生成）
 	URL       string            `json:"url"`
 	Method    string            `json:"method"`
 	Priority  int               `json:"priority"`  // 优先级，越小越高
@@ -20,6 +35,8 @@ type Request struct {
 	Timestamp time.Time         `json:"timestamp"` // 时间戳
 	Headers   *http.Header      `json:"headers,omitempty"`
 	Ctx       map[string]string `json:"ctx,omitempty"` // 上下文
+
+	lease string // 租约到期时间（RedisQueue 取出时设置，确认租约归属）
 }
 
 // Queue 请求队列
@@ -59,7 +76,7 @@ func (q *Queue) IsEnabled() bool {
 }
 
 // Add 添加请求
-func (q *Queue) Add(req *Request) {
+func (q *Queue) Add(req *Request) error {
 	if req.Method == "" {
 		req.Method = "GET"
 	}
@@ -82,6 +99,7 @@ func (q *Queue) Add(req *Request) {
 		}
 		return q.requests[i].Timestamp.Before(q.requests[j].Timestamp)
 	})
+	return nil
 }
 
 // AddBatch 批量添加请求
@@ -92,17 +110,27 @@ func (q *Queue) AddBatch(reqs []*Request) {
 }
 
 // Pop 弹出请求
-func (q *Queue) Pop() *Request {
+func (q *Queue) Pop() (*Request, error) {
 	q.mu.Lock()
 	defer q.mu.Unlock()
 
 	if len(q.requests) == 0 {
-		return nil
+		return nil, nil
 	}
 
 	req := q.requests[0]
 	q.requests = q.requests[1:]
-	return req
+	return req, nil
+}
+
+// Ack 确认请求（内存队列弹出即删除，无需确认）
+func (q *Queue) Ack(req *Request) error {
+	return nil
+}
+
+// Nack 放回队列
+func (q *Queue) Nack(req *Request) error {
+	return q.Add(req)
 }
 
 // Size 队列大小
@@ -113,11 +141,17 @@ func (q *Queue) Size() int {
 }
 
 // Clear 清空队列
-func (q *Queue) Clear() {
+func (q *Queue) Clear() error {
 	q.mu.Lock()
 	defer q.mu.Unlock()
 	q.requests = make([]*Request, 0)
 	log.Println("[队列清空] 已清空所有请求")
+	return nil
+}
+
+// Close 关闭队列
+func (q *Queue) Close() error {
+	return nil
 }
 
 // SaveToFile 保存到文件
@@ -0,0 +1,244 @@
+package collyx
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/tedwangl/go-util/pkg/redisx/client"
+	redisxconfig "github.com/tedwangl/go-util/pkg/redisx/config"
+)
+
+// Redis 队列脚本
+//
+// 数据结构（key 使用 {name} 哈希标签，保证集群模式下落在同一个 slot）：
+//   - {name}:pending     ZSET，score 为优先级，member 为请求 ID（ID 以纳秒时间戳开头，同优先级按时间先后）
+//   - {name}:processing  ZSET，score 为租约到期时间（毫秒），member 为请求 ID
+//   - {name}:data        HASH，请求 ID → 请求 JSON
+const (
+	// KEYS: pending, data; ARGV: id, priority, json
+	redisQueueAddScript = `
+redis.call('HSET', KEYS[2], ARGV[1], ARGV[3])
+redis.call('ZADD', KEYS[1], ARGV[2], ARGV[1])
+return 1`
+
+	// KEYS: pending, processing, data; ARGV: now(ms), lease(ms)
+	// 先回收租约已过期的请求，再取出优先级最高的请求
+	redisQueuePopScript = `
+local expired = redis.call('ZRANGEBYSCORE', KEYS[2], '-inf', ARGV[1], 'LIMIT', 0, 100)
+for _, id in ipairs(expired) do
+  redis.call('ZREM', KEYS[2], id)
+  local data = redis.call('HGET', KEYS[3], id)
+  if data then
+    redis.call('ZADD', KEYS[1], cjson.decode(data).priority or 0, id)
+  end
+end
+local ids = redis.call('ZRANGE', KEYS[1], 0, 0)
+if #ids == 0 then
+  return false
+end
+local id = ids[1]
+redis.call('ZREM', KEYS[1], id)
+local data = redis.call('HGET', KEYS[3], id)
+if not data then
+  return false
+end
+local lease = tonumber(ARGV[1]) + tonumber(ARGV[2])
+redis.call('ZADD', KEYS[2], lease, id)
+return {data, tostring(lease)}`
+
+	// KEYS: pending, processing, data; ARGV: id, lease
+	// 租约已被其他进程取走时忽略；租约过期放回队列但尚未被取走时从队列中删除
+	redisQueueAckScript = `
+local lease = redis.call('ZSCORE', KEYS[2], ARGV[1])
+if lease then
+  if tonumber(lease) ~= tonumber(ARGV[2]) then
+    return 0
+  end
+  redis.call('ZREM', KEYS[2], ARGV[1])
+else
+  redis.call('ZREM', KEYS[1], ARGV[1])
+end
+return redis.call('HDEL', KEYS[3], ARGV[1])`
+
+	// KEYS: pending, processing, data; ARGV: id, priority, lease
+	redisQueueNackScript = `
+if tonumber(redis.call('ZSCORE', KEYS[2], ARGV[1])) ~= tonumber(ARGV[3]) then
+  return 0
+end
+redis.call('ZREM', KEYS[2], ARGV[1])
+if redis.call('HEXISTS', KEYS[3], ARGV[1]) == 0 then
+  return 0
+end
+return redis.call('ZADD', KEYS[1], ARGV[2], ARGV[1])`
+
+	// KEYS: pending
+	redisQueueSizeScript = `return redis.call('ZCARD', KEYS[1])`
+)
+
+// RedisQueue Redis 分布式队列
+//
+// 多个爬虫进程使用相同的 name 即可共享同一个待抓取队列：
+// 优先级通过有序集合实现，Pop 后请求进入租约，Ack 后删除，
+// 进程崩溃导致租约超时的请求会在下次 Pop 时重新入队，原租约的 Ack、Nack 不再生效
+type RedisQueue struct {
+	client     client.Client
+	pending    string
+	processing string
+	data       string
+	lease      time.Duration
+	ownClient  bool
+}
+
+// NewRedisQueue 基于已有 redisx 客户端创建队列
+func NewRedisQueue(cli client.Client, name string, lease time.Duration) *RedisQueue {
+	if name == "" {
+		name = "collyx"
+	}
+	if lease <= 0 {
+		lease = 5 * time.Minute
+	}
+
+	prefix := "{" + name + "}"
+	return &RedisQueue{
+		client:     cli,
+		pending:    prefix + ":pending",
+		processing: prefix + ":processing",
+		data:       prefix + ":data",
+		lease:      lease,
+	}
+}
+
+// NewRedisQueueFromConfig 根据 redisx 配置创建队列（Close 时关闭 Redis 连接）
+func NewRedisQueueFromConfig(cfg *redisxconfig.Config, name string, lease time.Duration) (*RedisQueue, error) {
+	cli, err := client.NewClient(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("创建 Redis 客户端失败: %w", err)
+	}
+
+	q := NewRedisQueue(cli, name, lease)
+	q.ownClient = true
+	return q, nil
+}
+
+// Add 添加请求
+func (q *RedisQueue) Add(req *Request) error {
+	if req.Method == "" {
+		req.Method = "GET"
+	}
+	if req.Timestamp.IsZero() {
+		req.Timestamp = time.Now()
+	}
+	if req.Ctx == nil {
+		req.Ctx = make(map[string]string)
+	}
+	if req.ID == "" {
+		req.ID = newRequestID(req.Timestamp)
+	}
+
+	data, err := json.Marshal(req)
+	if err != nil {
+		return fmt.Errorf("序列化失败: %w", err)
+	}
+
+	ctx := context.Background()
+	if err := q.client.Eval(ctx, redisQueueAddScript, []string{q.pending, q.data},
+		req.ID, req.Priority, string(data)).Err(); err != nil {
+		return fmt.Errorf("添加请求失败: %w", err)
+	}
+	return nil
+}
+
+// Pop 取出请求（进入租约）
+func (q *RedisQueue) Pop() (*Request, error) {
+	ctx := context.Background()
+	now := time.Now().UnixMilli()
+
+	result, err := q.client.Eval(ctx, redisQueuePopScript, []string{q.pending, q.processing, q.data},
+		now, q.lease.Milliseconds()).StringSlice()
+	if err != nil {
+		if err == redis.Nil {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("取出请求失败: %w", err)
+	}
+	if len(result) != 2 {
+		return nil, fmt.Errorf("取出请求失败: 无效的返回值 %v", result)
+	}
+
+	var req Request
+	if err := json.Unmarshal([]byte(result[0]), &req); err != nil {
+		return nil, fmt.Errorf("反序列化失败: %w", err)
+	}
+	req.lease = result[1]
+	return &req, nil
+}
+
+// Ack 确认请求已处理（租约已过期被其他进程取走时忽略）
+func (q *RedisQueue) Ack(req *Request) error {
+	if req.ID == "" {
+		return nil
+	}
+
+	ctx := context.Background()
+	if err := q.client.Eval(ctx, redisQueueAckScript, []string{q.pending, q.processing, q.data},
+		req.ID, req.lease).Err(); err != nil {
+		return fmt.Errorf("确认请求失败: %w", err)
+	}
+	return nil
+}
+
+// Nack 放回队列（租约已过期被其他进程取走时忽略）
+func (q *RedisQueue) Nack(req *Request) error {
+	if req.ID == "" {
+		return q.Add(req)
+	}
+
+	ctx := context.Background()
+	if err := q.client.Eval(ctx, redisQueueNackScript, []string{q.pending, q.processing, q.data},
+		req.ID, req.Priority, req.lease).Err(); err != nil {
+		return fmt.Errorf("放回请求失败: %w", err)
+	}
+	return nil
+}
+
+// Size 待处理请求数（不含租约中的请求）
+func (q *RedisQueue) Size() int {
+	ctx := context.Background()
+	size, err := q.client.Eval(ctx, redisQueueSizeScript, []string{q.pending}).Int()
+	if err != nil {
+		log.Printf("[队列大小获取失败] 错误: %v", err)
+		return 0
+	}
+	return size
+}
+
+// Clear 清空队列（包括租约中的请求）
+func (q *RedisQueue) Clear() error {
+	ctx := context.Background()
+	if err := q.client.Del(ctx, q.pending, q.processing, q.data).Err(); err != nil {
+		return fmt.Errorf("清空队列失败: %w", err)
+	}
+	log.Println("[队列清空] 已清空所有请求")
+	return nil
+}
+
+// Close 关闭队列
+func (q *RedisQueue) Close() error {
+	if q.ownClient {
+		return q.client.Close()
+	}
+	return nil
+}
+
+// newRequestID 生成请求 ID（纳秒时间戳 + 随机数，同优先级按字典序即时间先后）
+func newRequestID(ts time.Time) string {
+	b := make([]byte, 4)
+	rand.Read(b)
+	return fmt.Sprintf("%019d-%s", ts.UnixNano(), hex.EncodeToString(b))
+}
@@ -0,0 +1,185 @@
+package collyx
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/gocolly/colly/v2"
+	redisxconfig "github.com/tedwangl/go-util/pkg/redisx/config"
+)
+
+func newTestRedisConfig(t *testing.T) (*miniredis.Miniredis, *redisxconfig.Config) {
+	mr := miniredis.RunT(t)
+	cfg := redisxconfig.DefaultConfig()
+	cfg.Single.Addr = mr.Addr()
+	return mr, cfg
+}
+
+func newTestRedisQueue(t *testing.T, lease time.Duration) (*miniredis.Miniredis, *RedisQueue) {
+	mr, cfg := newTestRedisConfig(t)
+	q, err := NewRedisQueueFromConfig(cfg, "test", lease)
+	if err != nil {
+		t.Fatalf("NewRedisQueueFromConfig 失败: %v", err)
+	}
+	t.Cleanup(func() { q.Close() })
+	return mr, q
+}
+
+func TestRedisQueuePriorityAndAck(t *testing.T) {
+	mr, q := newTestRedisQueue(t, time.Minute)
+
+	for i, p := range []int{5, 1, 5, 3} {
+		req := &Request{URL: "https://example.com/" + string(rune('a'+i)), Priority: p}
+		if err := q.Add(req); err != nil {
+			t.Fatalf("Add 失败: %v", err)
+		}
+	}
+	if size := q.Size(); size != 4 {
+		t.Fatalf("期望 4 个请求，实际 %d", size)
+	}
+
+	// 优先级小的先出，同优先级按添加顺序
+	want := []string{"b", "d", "a", "c"}
+	for _, w := range want {
+		req, err := q.Pop()
+		if err != nil || req == nil {
+			t.Fatalf("Pop 失败: %v", err)
+		}
+		if req.URL != "https://example.com/"+w || req.Method != "GET" {
+			t.Errorf("期望 %s，实际 %s %s", w, req.Method, req.URL)
+		}
+		if err := q.Ack(req); err != nil {
+			t.Fatalf("Ack 失败: %v", err)
+		}
+	}
+
+	if req, err := q.Pop(); err != nil || req != nil {
+		t.Errorf("队列应为空，实际 %v, %v", req, err)
+	}
+	for _, key := range []string{"{test}:pending", "{test}:processing", "{test}:data"} {
+		if mr.Exists(key) {
+			t.Errorf("Ack 后 %s 应为空", key)
+		}
+	}
+}
+
+func TestRedisQueueNack(t *testing.T) {
+	_, q := newTestRedisQueue(t, time.Minute)
+
+	q.Add(&Request{URL: "https://example.com/a"})
+	req, _ := q.Pop()
+	if req == nil || q.Size() != 0 {
+		t.Fatalf("Pop 后请求应处于租约中")
+	}
+
+	if err := q.Nack(req); err != nil {
+		t.Fatalf("Nack 失败: %v", err)
+	}
+	if q.Size() != 1 {
+		t.Fatalf("Nack 后请求应放回队列")
+	}
+	again, _ := q.Pop()
+	if again == nil || again.ID != req.ID {
+		t.Errorf("应重新取出同一个请求，实际 %+v", again)
+	}
+
+	// 租约已结束的请求不重复放回
+	q.Ack(again)
+	q.Nack(again)
+	if q.Size() != 0 {
+		t.Errorf("已确认的请求不应放回队列")
+	}
+}
+
+func TestRedisQueueExpiredLease(t *testing.T) {
+	_, q := newTestRedisQueue(t, 100*time.Millisecond)
+
+	q.Add(&Request{URL: "https://example.com/a"})
+	req, _ := q.Pop()
+	if req == nil {
+		t.Fatal("Pop 应返回请求")
+	}
+	if next, _ := q.Pop(); next != nil {
+		t.Fatal("租约中的请求不应再次取出")
+	}
+
+	// 租约超时未确认（如进程崩溃）的请求重新入队
+	time.Sleep(150 * time.Millisecond)
+	again, err := q.Pop()
+	if err != nil || again == nil || again.ID != req.ID {
+		t.Fatalf("租约过期的请求应重新取出，实际 %+v, %v", again, err)
+	}
+
+	// 原租约持有者的 Nack 不会重复入队
+	q.Nack(req)
+	q.Ack(again)
+	if q.Size() != 0 {
+		t.Errorf("期望队列为空，实际 %d", q.Size())
+	}
+}
+
+func TestProcessQueueAckAfterScraped(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/missing" {
+			http.NotFound(w, r)
+			return
+		}
+		time.Sleep(50 * time.Millisecond)
+		w.Write([]byte("<html><body>ok</body></html>"))
+	}))
+	defer srv.Close()
+
+	for _, async := range []bool{false, true} {
+		t.Run(fmt.Sprintf("async=%v", async), func(t *testing.T) {
+			mr, redisCfg := newTestRedisConfig(t)
+			cfg := DefaultConfig()
+			cfg.Delay = 0
+			cfg.RandomDelay = 0
+			cfg.IgnoreRobotsTxt = true
+			cfg.EnableQueue = true
+			cfg.QueueType = "redis"
+			cfg.QueueRedis = redisCfg
+			cfg.QueueName = "crawl"
+
+			// 响应处理时请求仍在租约中，处理完成后才确认
+			var leased atomic.Bool
+			cfg.OnResponse = []func(*colly.Response){
+				func(r *colly.Response) {
+					if lease := requestLease(r.Request); lease != nil && r.Request.URL.Path == "/page" {
+						_, err := mr.ZScore("{crawl}:processing", lease.req.ID)
+						leased.Store(err == nil)
+					}
+				},
+			}
+
+			client, err := NewClient(cfg)
+			if err != nil {
+				t.Fatalf("NewClient 失败: %v", err)
+			}
+			defer client.Close()
+			client.Collector().Async = async
+
+			client.Visit(srv.URL + "/page")
+			client.Visit(srv.URL + "/missing")
+			if err := client.ProcessQueue(true); err != nil {
+				t.Fatalf("ProcessQueue 失败: %v", err)
+			}
+			client.Wait()
+
+			if !leased.Load() {
+				t.Errorf("响应处理完成前请求应保持租约")
+			}
+			// 成功和不重试的失败请求都已确认
+			for _, key := range []string{"{crawl}:pending", "{crawl}:processing", "{crawl}:data"} {
+				if mr.Exists(key) {
+					t.Errorf("处理完成后 %s 应为空", key)
+				}
+			}
+		})
+	}
+}
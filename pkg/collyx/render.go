@@ -0,0 +1,215 @@
+package collyx
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/chromedp/chromedp"
+	"github.com/gocolly/colly/v2"
+)
+
+// Renderer 页面渲染器（执行 JavaScript 后返回 HTML）
+type Renderer interface {
+	Render(ctx context.Context, url string) (string, error)
+	Close() error
+}
+
+// RenderConfig 无头浏览器渲染配置
+type RenderConfig struct {
+	Patterns     []string      // 需要渲染的 URL 正则，匹配的 URL 总是渲染
+	AutoDetect   bool          // 是否自动检测依赖 JS 的页面（正文过少且包含脚本）
+	MinTextLen   int           // 自动检测时正文少于该长度视为需要渲染，默认 200
+	WaitSelector string        // 等待元素出现后再取 HTML，默认 body
+	WaitTime     time.Duration // 元素出现后额外等待的时间（等待异步请求）
+	Timeout      time.Duration // 单个页面渲染超时，默认 30s
+	ExecPath     string        // Chrome 可执行文件路径，为空自动查找
+	Renderer     Renderer      // 自定义渲染器，为空使用 Chrome
+}
+
+// ChromeRenderer 基于 chromedp 的渲染器（复用同一个浏览器进程，每个页面一个标签页）
+type ChromeRenderer struct {
+	cfg           *RenderConfig
+	allocCancel   context.CancelFunc
+	browserCtx    context.Context
+	browserCancel context.CancelFunc
+}
+
+// NewChromeRenderer 创建 Chrome 渲染器并启动浏览器，各页面在同一个浏览器中打开新标签页
+func NewChromeRenderer(cfg *RenderConfig, userAgent string) (*ChromeRenderer, error) {
+	opts := append(chromedp.DefaultExecAllocatorOptions[:],
+		chromedp.UserAgent(userAgent),
+	)
+	if cfg.ExecPath != "" {
+		opts = append(opts, chromedp.ExecPath(cfg.ExecPath))
+	}
+
+	allocCtx, allocCancel := chromedp.NewExecAllocator(context.Background(), opts...)
+	browserCtx, browserCancel := chromedp.NewContext(allocCtx)
+
+	// 未启动浏览器时，从 browserCtx 派生的每个标签页都会启动一个新的浏览器进程
+	if err := chromedp.Run(browserCtx); err != nil {
+		browserCancel()
+		allocCancel()
+		return nil, fmt.Errorf("启动浏览器失败: %w", err)
+	}
+
+	return &ChromeRenderer{
+		cfg:           cfg,
+		allocCancel:   allocCancel,
+		browserCtx:    browserCtx,
+		browserCancel: browserCancel,
+	}, nil
+}
+
+// Render 打开页面并返回渲染后的 HTML
+func (r *ChromeRenderer) Render(ctx context.Context, url string) (string, error) {
+	tabCtx, cancel := chromedp.NewContext(r.browserCtx)
+	defer cancel()
+
+	tabCtx, cancelTimeout := context.WithTimeout(tabCtx, r.cfg.Timeout)
+	defer cancelTimeout()
+
+	stop := context.AfterFunc(ctx, cancel)
+	defer stop()
+
+	var html string
+	actions := []chromedp.Action{
+		chromedp.Navigate(url),
+		chromedp.WaitReady(r.cfg.WaitSelector, chromedp.ByQuery),
+	}
+	if r.cfg.WaitTime > 0 {
+		actions = append(actions, chromedp.Sleep(r.cfg.WaitTime))
+	}
+	actions = append(actions, chromedp.OuterHTML("html", &html, chromedp.ByQuery))
+
+	if err := chromedp.Run(tabCtx, actions...); err != nil {
+		return "", fmt.Errorf("渲染失败: %w", err)
+	}
+	return html, nil
+}
+
+// Close 关闭浏览器
+func (r *ChromeRenderer) Close() error {
+	r.browserCancel()
+	r.allocCancel()
+	return nil
+}
+
+// renderFallback 渲染回退处理器
+type renderFallback struct {
+	cfg      *RenderConfig
+	patterns []*regexp.Regexp
+	ctx      context.Context
+
+	once     sync.Once
+	renderer Renderer
+	newErr   error
+	newFn    func() (Renderer, error)
+}
+
+// newRenderFallback 创建渲染回退处理器
+func newRenderFallback(ctx context.Context, cfg *RenderConfig, userAgent string) (*renderFallback, error) {
+	if cfg.MinTextLen <= 0 {
+		cfg.MinTextLen = 200
+	}
+	if cfg.WaitSelector == "" {
+		cfg.WaitSelector = "body"
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = 30 * time.Second
+	}
+
+	f := &renderFallback{cfg: cfg, ctx: ctx, renderer: cfg.Renderer}
+	for _, pattern := range cfg.Patterns {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("渲染规则 %s 无效: %w", pattern, err)
+		}
+		f.patterns = append(f.patterns, re)
+	}
+	f.newFn = func() (Renderer, error) {
+		r, err := NewChromeRenderer(cfg, userAgent)
+		if err != nil {
+			return nil, err
+		}
+		return r, nil
+	}
+
+	return f, nil
+}
+
+// HandleResponse 需要渲染时用渲染后的 HTML 替换响应体，后续 OnHTML/流水线使用渲染结果
+func (f *renderFallback) HandleResponse(r *colly.Response) {
+	url := r.Request.URL.String()
+	if !f.matchURL(url) && !(f.cfg.AutoDetect && f.needsJS(r)) {
+		return
+	}
+
+	// 首次需要渲染时才启动浏览器
+	f.once.Do(func() {
+		if f.renderer == nil {
+			f.renderer, f.newErr = f.newFn()
+		}
+	})
+	if f.newErr != nil {
+		log.Printf("[渲染失败] URL: %s, 错误: %v", url, f.newErr)
+		return
+	}
+
+	start := time.Now()
+	html, err := f.renderer.Render(f.ctx, url)
+	if err != nil {
+		log.Printf("[渲染失败] URL: %s, 错误: %v", url, err)
+		return
+	}
+
+	r.Body = []byte(html)
+	r.Headers.Set("Content-Type", "text/html; charset=utf-8")
+	r.Ctx.Put("rendered", true)
+	log.Printf("[渲染完成] URL: %s, 耗时: %v", url, time.Since(start))
+}
+
+// matchURL URL 是否匹配渲染规则
+func (f *renderFallback) matchURL(url string) bool {
+	for _, re := range f.patterns {
+		if re.MatchString(url) {
+			return true
+		}
+	}
+	return false
+}
+
+// needsJS 页面是否依赖 JavaScript 渲染（HTML 中正文过少但包含脚本）
+func (f *renderFallback) needsJS(r *colly.Response) bool {
+	if !strings.Contains(strings.ToLower(r.Headers.Get("Content-Type")), "html") {
+		return false
+	}
+
+	doc, err := goquery.NewDocumentFromReader(bytes.NewReader(r.Body))
+	if err != nil {
+		return false
+	}
+	if doc.Find("script").Length() == 0 {
+		return false
+	}
+
+	body := doc.Find("body").Clone()
+	body.Find("script, style, noscript, template").Remove()
+	text := strings.Join(strings.Fields(body.Text()), " ")
+	return len([]rune(text)) < f.cfg.MinTextLen
+}
+
+// Close 关闭渲染器
+func (f *renderFallback) Close() error {
+	if f.renderer != nil {
+		return f.renderer.Close()
+	}
+	return nil
+}
@@ -0,0 +1,83 @@
+package collyx
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+type fakeRenderer struct {
+	calls int
+}
+
+func (r *fakeRenderer) Render(ctx context.Context, url string) (string, error) {
+	r.calls++
+	return `<html><body><h2>Rendered</h2></body></html>`, nil
+}
+
+func (r *fakeRenderer) Close() error { return nil }
+
+func TestRenderFallback(t *testing.T) {
+	renderer := &fakeRenderer{}
+	f, err := newRenderFallback(context.Background(), &RenderConfig{
+		Patterns:   []string{`/spa/`},
+		AutoDetect: true,
+		Renderer:   renderer,
+	}, "test")
+	if err != nil {
+		t.Fatalf("newRenderFallback 失败: %v", err)
+	}
+
+	// 正文充足的静态页面不渲染
+	static := newTestResponse(productListHTML)
+	f.HandleResponse(static)
+	if renderer.calls != 0 {
+		t.Fatalf("静态页面不应渲染")
+	}
+
+	// SPA 空壳页面自动渲染
+	shell := newTestResponse(`<html><body><div id="app"></div><script src="/app.js"></script></body></html>`)
+	f.HandleResponse(shell)
+	if renderer.calls != 1 || string(shell.Body) != `<html><body><h2>Rendered</h2></body></html>` {
+		t.Fatalf("空壳页面应替换为渲染结果: %s", shell.Body)
+	}
+	if rendered, _ := shell.Ctx.GetAny("rendered").(bool); !rendered {
+		t.Errorf("应标记 rendered")
+	}
+
+	// 匹配规则的 URL 总是渲染
+	matched := newTestResponse(productListHTML)
+	matched.Request.URL.Path = "/spa/list"
+	f.HandleResponse(matched)
+	if renderer.calls != 2 {
+		t.Errorf("匹配规则的 URL 应渲染")
+	}
+}
+
+func TestRenderFallbackStartFailed(t *testing.T) {
+	f, err := newRenderFallback(context.Background(), &RenderConfig{Patterns: []string{`/spa/`}}, "test")
+	if err != nil {
+		t.Fatalf("newRenderFallback 失败: %v", err)
+	}
+	calls := 0
+	f.newFn = func() (Renderer, error) {
+		calls++
+		return nil, errors.New("chrome not found")
+	}
+
+	// 浏览器启动失败时保留原响应，且只尝试启动一次
+	for i := 0; i < 2; i++ {
+		resp := newTestResponse(productListHTML)
+		resp.Request.URL.Path = "/spa/list"
+		f.HandleResponse(resp)
+		if string(resp.Body) != productListHTML {
+			t.Fatalf("启动失败时不应替换响应体")
+		}
+	}
+	if calls != 1 {
+		t.Errorf("应只尝试启动一次浏览器，实际 %d 次", calls)
+	}
+	if err := f.Close(); err != nil {
+		t.Errorf("Close 失败: %v", err)
+	}
+}
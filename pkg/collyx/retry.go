@@ -0,0 +1,141 @@
+package collyx
+
+import (
+	"fmt"
+	"log"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gocolly/colly/v2"
+	"github.com/tedwangl/go-util/pkg/collyx/storage"
+)
+
+// retryKey 重试状态在 Ctx 中的键（子请求与父请求共享 Ctx，按 URL 区分）
+func retryKey(name, url string) string {
+	return "retry:" + name + ":" + url
+}
+
+// shouldRetry 是否需要重试（状态码、超时，404 不重试）
+func (c *Client) shouldRetry(r *colly.Response, err error) bool {
+	if r.StatusCode == http.StatusNotFound {
+		return false
+	}
+
+	for _, code := range c.config.RetryHTTPCodes {
+		if r.StatusCode == code {
+			return true
+		}
+	}
+
+	return c.config.RetryOnTimeout && (strings.Contains(err.Error(), "timeout") ||
+		strings.Contains(err.Error(), "context deadline exceeded"))
+}
+
+// retryDelay 重试延迟：优先使用 Retry-After（不超过 RetryMaxDelay），否则为 full jitter 指数退避
+func (c *Client) retryDelay(r *colly.Response, attempt int) time.Duration {
+	if r.Headers != nil {
+		if d, ok := parseRetryAfter(r.Headers.Get("Retry-After"), time.Now()); ok {
+			if max := c.config.RetryMaxDelay; max > 0 && d > max {
+				d = max
+			}
+			return d
+		}
+	}
+	return fullJitter(c.config.RetryBaseDelay, c.config.RetryMaxDelay, attempt)
+}
+
+// fullJitter 在 [0, min(max, base*2^attempt)) 内随机取值，避免大量请求同时重试
+func fullJitter(base, max time.Duration, attempt int) time.Duration {
+	if base <= 0 {
+		base = time.Second
+	}
+
+	ceiling := max
+	if attempt < 32 {
+		if d := base << uint(attempt); d > 0 && (max <= 0 || d < max) {
+			ceiling = d
+		}
+	}
+	if ceiling <= 0 {
+		return 0
+	}
+	return time.Duration(rand.Int63n(int64(ceiling)))
+}
+
+// parseRetryAfter 解析 Retry-After（秒数或 HTTP 日期）
+func parseRetryAfter(value string, now time.Time) (time.Duration, bool) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0, false
+	}
+
+	if secs, err := strconv.Atoi(value); err == nil {
+		if secs < 0 {
+			return 0, false
+		}
+		return time.Duration(secs) * time.Second, true
+	}
+
+	if t, err := http.ParseTime(value); err == nil {
+		if d := t.Sub(now); d > 0 {
+			return d, true
+		}
+		return 0, true
+	}
+	return 0, false
+}
+
+// deadLetter 重试耗尽的请求写入死信（需要启用存储）
+func (c *Client) deadLetter(r *colly.Response, err error, retries int, firstError time.Time) {
+	if c.storage == nil {
+		return
+	}
+
+	dl := &storage.DeadLetter{
+		URL:        r.Request.URL.String(),
+		Method:     r.Request.Method,
+		Depth:      r.Request.Depth,
+		StatusCode: r.StatusCode,
+		Error:      err.Error(),
+		Retries:    retries,
+		FirstError: firstError,
+		CreatedAt:  time.Now(),
+	}
+	if saveErr := c.storage.SaveDeadLetter(dl); saveErr != nil {
+		log.Printf("[保存死信失败] URL: %s, 错误: %v", dl.URL, saveErr)
+	}
+}
+
+// DeadLetters 列出重试耗尽的请求（需要启用存储）
+func (c *Client) DeadLetters(limit, offset int) ([]*storage.DeadLetter, error) {
+	if c.storage == nil {
+		return nil, fmt.Errorf("存储未启用")
+	}
+	return c.storage.ListDeadLetters(limit, offset)
+}
+
+// ReplayDeadLetters 重新访问死信中的请求（启用队列时加入队列），成功后删除死信，返回重放数量
+// 同一进程内已访问过的 URL 会被 colly 去重，通常在新进程中重放
+func (c *Client) ReplayDeadLetters(limit int) (int, error) {
+	letters, err := c.DeadLetters(limit, 0)
+	if err != nil {
+		return 0, err
+	}
+
+	replayed := 0
+	for _, dl := range letters {
+		if err := c.visit(dl.URL, 0); err != nil {
+			log.Printf("[重放失败] URL: %s, 错误: %v", dl.URL, err)
+			continue
+		}
+		if err := c.storage.DeleteDeadLetter(dl.ID); err != nil {
+			return replayed, fmt.Errorf("删除死信失败: %w", err)
+		}
+		replayed++
+	}
+
+	return replayed, nil
+}
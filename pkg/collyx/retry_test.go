@@ -0,0 +1,189 @@
+package collyx
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gocolly/colly/v2"
+)
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		value string
+		want  time.Duration
+		ok    bool
+	}{
+		{"120", 2 * time.Minute, true},
+		{" 0 ", 0, true},
+		{"Wed, 01 Jan 2025 00:00:30 GMT", 30 * time.Second, true},
+		{"Tue, 31 Dec 2024 23:59:00 GMT", 0, true}, // 已过去的时间立即重试
+		{"-5", 0, false},
+		{"soon", 0, false},
+		{"", 0, false},
+	}
+	for _, tt := range tests {
+		got, ok := parseRetryAfter(tt.value, now)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("parseRetryAfter(%q) = %v, %v，期望 %v, %v", tt.value, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestFullJitter(t *testing.T) {
+	for attempt := 0; attempt < 70; attempt++ {
+		ceiling := time.Second << uint(attempt)
+		if attempt >= 5 {
+			ceiling = 30 * time.Second
+		}
+		for i := 0; i < 20; i++ {
+			if d := fullJitter(time.Second, 30*time.Second, attempt); d < 0 || d >= ceiling {
+				t.Fatalf("第 %d 次重试延迟 %v 超出 [0, %v)", attempt, d, ceiling)
+			}
+		}
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	var hits atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/robots.txt" {
+			return
+		}
+		if hits.Add(1) <= 2 {
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.Write([]byte("ok"))
+	}))
+	defer srv.Close()
+
+	cfg := DefaultConfig()
+	cfg.Delay = 0
+	cfg.RandomDelay = 0
+	cfg.RetryBaseDelay = time.Hour // 使用 Retry-After 时不应按退避等待
+
+	var body atomic.Value
+	cfg.OnResponse = []func(*colly.Response){
+		func(r *colly.Response) { body.Store(string(r.Body)) },
+	}
+
+	client, err := NewClient(cfg)
+	if err != nil {
+		t.Fatalf("NewClient 失败: %v", err)
+	}
+	defer client.Close()
+
+	start := time.Now()
+	client.Visit(srv.URL + "/page")
+	client.Wait()
+
+	if body.Load() != "ok" || hits.Load() != 3 {
+		t.Errorf("Wait 应等待重试完成，实际请求 %d 次，响应 %v", hits.Load(), body.Load())
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("应按 Retry-After 立即重试，实际耗时 %v", elapsed)
+	}
+}
+
+func TestRetryAfterCapped(t *testing.T) {
+	var hits atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/robots.txt" {
+			return
+		}
+		if hits.Add(1) == 1 {
+			w.Header().Set("Retry-After", "3600")
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte("ok"))
+	}))
+	defer srv.Close()
+
+	cfg := DefaultConfig()
+	cfg.Delay = 0
+	cfg.RandomDelay = 0
+	cfg.RetryMaxDelay = 50 * time.Millisecond
+
+	client, err := NewClient(cfg)
+	if err != nil {
+		t.Fatalf("NewClient 失败: %v", err)
+	}
+	defer client.Close()
+
+	start := time.Now()
+	client.Visit(srv.URL + "/page")
+	client.Wait()
+
+	if hits.Load() != 2 {
+		t.Errorf("期望请求 2 次，实际 %d 次", hits.Load())
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("Retry-After 应受 RetryMaxDelay 限制，实际耗时 %v", elapsed)
+	}
+}
+
+func TestRetryScheduledWithoutBlocking(t *testing.T) {
+	var ok atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/fail":
+			w.WriteHeader(http.StatusServiceUnavailable)
+		case "/ok":
+			ok.Add(1)
+			w.Write([]byte("ok"))
+		}
+	}))
+	defer srv.Close()
+
+	cfg := DefaultConfig()
+	cfg.Delay = 0
+	cfg.RandomDelay = 0
+	cfg.RetryBaseDelay = time.Hour
+	cfg.RetryMaxDelay = time.Hour
+	cfg.RetryMaxElapsed = 0
+
+	client, err := NewClient(cfg)
+	if err != nil {
+		t.Fatalf("NewClient 失败: %v", err)
+	}
+	defer client.Close()
+
+	// 等待重试期间不占用 worker，后续请求照常执行
+	start := time.Now()
+	client.Visit(srv.URL + "/fail")
+	client.Visit(srv.URL + "/ok")
+	if elapsed := time.Since(start); elapsed > 5*time.Second || ok.Load() != 1 {
+		t.Fatalf("重试等待不应阻塞请求，耗时 %v，成功 %d 次", elapsed, ok.Load())
+	}
+	if client.retryPending.Load() != 1 {
+		t.Fatalf("期望 1 个等待中的重试，实际 %d", client.retryPending.Load())
+	}
+
+	// 停止后取消等待中的重试，Wait 立即返回
+	done := make(chan struct{})
+	go func() {
+		client.Wait()
+		close(done)
+	}()
+	client.Stop()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("停止后 Wait 应返回")
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for client.retryPending.Load() != 0 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if n := client.retryPending.Load(); n != 0 {
+		t.Errorf("停止后等待中的重试应被取消，剩余 %d", n)
+	}
+}
@@ -0,0 +1,299 @@
+package collyx
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+)
+
+// 种子 URL 优先级：lastmod 越新优先级越高（数值越小），缺少 lastmod 的排在最后
+const (
+	maxSeedPriority = 365
+	maxSitemapDepth = 3
+	maxSitemapBytes = 50 << 20 // sitemap 协议限制 50MB（解压后）
+)
+
+// SeedURL 从 sitemap / feed 发现的 URL
+type SeedURL struct {
+	URL     string
+	LastMod time.Time
+}
+
+// sitemapDoc sitemap.xml 或 sitemap 索引
+type sitemapDoc struct {
+	XMLName  xml.Name
+	URLs     []sitemapEntry `xml:"url"`
+	Sitemaps []sitemapEntry `xml:"sitemap"`
+}
+
+type sitemapEntry struct {
+	Loc     string `xml:"loc"`
+	LastMod string `xml:"lastmod"`
+}
+
+// feedDoc RSS 2.0 / Atom
+type feedDoc struct {
+	XMLName xml.Name
+	Items   []feedItem `xml:"channel>item"` // RSS
+	Entries []feedItem `xml:"entry"`        // Atom
+}
+
+type feedItem struct {
+	Links     []feedLink `xml:"link"`
+	PubDate   string     `xml:"pubDate"`
+	Updated   string     `xml:"updated"`
+	Published string     `xml:"published"`
+}
+
+// feedLink RSS 的 <link>URL</link> 与 Atom 的 <link href="URL"/>
+type feedLink struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr"`
+	Text string `xml:",chardata"`
+}
+
+// VisitSitemap 解析 sitemap（支持 sitemap 索引递归和 gzip），按 lastmod 排序后加入队列
+func (c *Client) VisitSitemap(sitemapURL string) error {
+	seeds, err := c.ParseSitemap(sitemapURL)
+	if err != nil {
+		return err
+	}
+	return c.visitSeeds(sitemapURL, seeds)
+}
+
+// VisitFeed 解析 RSS/Atom，按发布时间排序后加入队列
+func (c *Client) VisitFeed(feedURL string) error {
+	seeds, err := c.ParseFeed(feedURL)
+	if err != nil {
+		return err
+	}
+	return c.visitSeeds(feedURL, seeds)
+}
+
+// ParseSitemap 解析 sitemap，返回去重后的 URL
+func (c *Client) ParseSitemap(sitemapURL string) ([]SeedURL, error) {
+	seen := make(map[string]bool)
+	var seeds []SeedURL
+	if err := c.parseSitemap(sitemapURL, 0, seen, &seeds); err != nil {
+		return nil, err
+	}
+	return seeds, nil
+}
+
+// parseSitemap 递归解析 sitemap 索引
+func (c *Client) parseSitemap(sitemapURL string, depth int, seen map[string]bool, seeds *[]SeedURL) error {
+	if depth > maxSitemapDepth {
+		log.Printf("[sitemap 跳过] URL: %s, 原因: 超过最大嵌套层数 %d", sitemapURL, maxSitemapDepth)
+		return nil
+	}
+
+	data, err := c.fetchSeed(sitemapURL)
+	if err != nil {
+		return err
+	}
+
+	var doc sitemapDoc
+	if err := xml.Unmarshal(data, &doc); err != nil {
+		return fmt.Errorf("解析 sitemap 失败: %s: %w", sitemapURL, err)
+	}
+
+	switch doc.XMLName.Local {
+	case "sitemapindex":
+		for _, sm := range doc.Sitemaps {
+			loc := strings.TrimSpace(sm.Loc)
+			if loc == "" || seen["sitemap:"+loc] {
+				continue
+			}
+			seen["sitemap:"+loc] = true
+			// 子 sitemap 失败不影响其他 sitemap
+			if err := c.parseSitemap(loc, depth+1, seen, seeds); err != nil {
+				log.Printf("[sitemap 解析失败] URL: %s, 错误: %v", loc, err)
+			}
+		}
+	case "urlset":
+		for _, u := range doc.URLs {
+			loc := strings.TrimSpace(u.Loc)
+			if loc == "" || seen[loc] {
+				continue
+			}
+			seen[loc] = true
+			*seeds = append(*seeds, SeedURL{URL: loc, LastMod: parseSeedTime(u.LastMod)})
+		}
+	default:
+		return fmt.Errorf("不是有效的 sitemap: %s（根元素 %s）", sitemapURL, doc.XMLName.Local)
+	}
+
+	return nil
+}
+
+// ParseFeed 解析 RSS/Atom，返回去重后的 URL
+func (c *Client) ParseFeed(feedURL string) ([]SeedURL, error) {
+	data, err := c.fetchSeed(feedURL)
+	if err != nil {
+		return nil, err
+	}
+
+	var doc feedDoc
+	if err := xml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("解析 feed 失败: %s: %w", feedURL, err)
+	}
+
+	var items []feedItem
+	switch doc.XMLName.Local {
+	case "rss":
+		items = doc.Items
+	case "feed":
+		items = doc.Entries
+	default:
+		return nil, fmt.Errorf("不是有效的 RSS/Atom: %s（根元素 %s）", feedURL, doc.XMLName.Local)
+	}
+
+	seen := make(map[string]bool)
+	seeds := make([]SeedURL, 0, len(items))
+	for _, item := range items {
+		link := item.link()
+		if link == "" || seen[link] {
+			continue
+		}
+		seen[link] = true
+
+		ts := parseSeedTime(item.Updated)
+		if ts.IsZero() {
+			ts = parseSeedTime(item.Published)
+		}
+		if ts.IsZero() {
+			ts = parseSeedTime(item.PubDate)
+		}
+		seeds = append(seeds, SeedURL{URL: link, LastMod: ts})
+	}
+	return seeds, nil
+}
+
+// link 条目链接（RSS 取文本，Atom 取 rel=alternate 的 href）
+func (i *feedItem) link() string {
+	for _, l := range i.Links {
+		if l.Href == "" {
+			if text := strings.TrimSpace(l.Text); text != "" {
+				return text
+			}
+			continue
+		}
+		if l.Rel == "" || l.Rel == "alternate" {
+			return l.Href
+		}
+	}
+	return ""
+}
+
+// visitSeeds 按 lastmod 从新到旧加入队列
+func (c *Client) visitSeeds(source string, seeds []SeedURL) error {
+	sort.SliceStable(seeds, func(i, j int) bool {
+		return seeds[i].LastMod.After(seeds[j].LastMod)
+	})
+
+	now := time.Now()
+	added := 0
+	for _, seed := range seeds {
+		if err := c.visit(seed.URL, seedPriority(seed.LastMod, now)); err != nil {
+			if c.ctx.Err() != nil {
+				return err
+			}
+			log.Printf("[种子 URL 添加失败] URL: %s, 错误: %v", seed.URL, err)
+			continue
+		}
+		added++
+	}
+
+	log.Printf("[种子 URL 添加完成] 来源: %s, 发现: %d, 添加: %d", source, len(seeds), added)
+	return nil
+}
+
+// seedPriority lastmod 距今的天数作为优先级
+func seedPriority(lastMod, now time.Time) int {
+	if lastMod.IsZero() {
+		return maxSeedPriority
+	}
+	days := int(now.Sub(lastMod).Hours() / 24)
+	if days < 0 {
+		return 0
+	}
+	if days > maxSeedPriority {
+		return maxSeedPriority
+	}
+	return days
+}
+
+// seedTimeLayouts sitemap（W3C Datetime）、Atom（RFC 3339）、RSS（RFC 822/1123）时间格式
+var seedTimeLayouts = []string{
+	time.RFC3339Nano,
+	time.RFC3339,
+	"2006-01-02T15:04Z07:00",
+	"2006-01-02T15:04:05",
+	"2006-01-02",
+	time.RFC1123Z,
+	time.RFC1123,
+	time.RFC822Z,
+	time.RFC822,
+	"Mon, 2 Jan 2006 15:04:05 -0700",
+	"Mon, 2 Jan 2006 15:04:05 MST",
+}
+
+// parseSeedTime 解析时间，失败返回零值
+func parseSeedTime(s string) time.Time {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return time.Time{}
+	}
+	for _, layout := range seedTimeLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+// fetchSeed 下载 sitemap / feed（自动解压 gzip）
+func (c *Client) fetchSeed(url string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(c.ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("创建请求失败: %w", err)
+	}
+	req.Header.Set("User-Agent", c.config.UserAgent)
+
+	client := &http.Client{Timeout: c.config.RequestTimeout}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("请求失败: %s: %w", url, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("请求失败: %s: 状态码 %d", url, resp.StatusCode)
+	}
+
+	// 根据内容判断是否为 gzip（.xml.gz 或服务端未声明 Content-Encoding）
+	body := bufio.NewReader(io.LimitReader(resp.Body, maxSitemapBytes))
+	var reader io.Reader = body
+	if magic, _ := body.Peek(2); bytes.Equal(magic, []byte{0x1f, 0x8b}) {
+		gz, err := gzip.NewReader(body)
+		if err != nil {
+			return nil, fmt.Errorf("解压失败: %s: %w", url, err)
+		}
+		defer gz.Close()
+		reader = gz
+	}
+
+	data, err := io.ReadAll(io.LimitReader(reader, maxSitemapBytes))
+	if err != nil {
+		return nil, fmt.Errorf("读取失败: %s: %w", url, err)
+	}
+	return data, nil
+}
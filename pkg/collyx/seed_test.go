@@ -0,0 +1,108 @@
+package collyx
+
+import (
+	"bytes"
+	"compress/gzip"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func newSeedServer(t *testing.T) *httptest.Server {
+	mux := http.NewServeMux()
+	var srv *httptest.Server
+
+	mux.HandleFunc("/sitemap.xml", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`<?xml version="1.0" encoding="UTF-8"?>
+<sitemapindex xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">
+  <sitemap><loc>` + srv.URL + `/posts.xml.gz</loc></sitemap>
+  <sitemap><loc>` + srv.URL + `/pages.xml</loc></sitemap>
+</sitemapindex>`))
+	})
+	mux.HandleFunc("/posts.xml.gz", func(w http.ResponseWriter, r *http.Request) {
+		var buf bytes.Buffer
+		gz := gzip.NewWriter(&buf)
+		gz.Write([]byte(`<urlset xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">
+  <url><loc>https://example.com/old</loc><lastmod>2020-01-01</lastmod></url>
+  <url><loc>https://example.com/new</loc><lastmod>` + time.Now().Format(time.RFC3339) + `</lastmod></url>
+</urlset>`))
+		gz.Close()
+		w.Write(buf.Bytes())
+	})
+	mux.HandleFunc("/pages.xml", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`<urlset xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">
+  <url><loc>https://example.com/about</loc></url>
+  <url><loc>https://example.com/new</loc></url>
+</urlset>`))
+	})
+	mux.HandleFunc("/atom.xml", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`<feed xmlns="http://www.w3.org/2005/Atom">
+  <entry><link rel="edit" href="https://example.com/edit/1"/><link href="https://example.com/a/1"/><updated>2024-05-01T00:00:00Z</updated></entry>
+  <entry><link rel="alternate" href="https://example.com/a/2"/><updated>2024-06-01T00:00:00Z</updated></entry>
+</feed>`))
+	})
+	mux.HandleFunc("/rss.xml", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`<rss version="2.0"><channel>
+  <item><link>https://example.com/r/1</link><pubDate>Mon, 02 Jan 2006 15:04:05 +0000</pubDate></item>
+</channel></rss>`))
+	})
+
+	srv = httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestVisitSitemap(t *testing.T) {
+	srv := newSeedServer(t)
+
+	cfg := DefaultConfig()
+	cfg.EnableQueue = true
+	client, err := NewClient(cfg)
+	if err != nil {
+		t.Fatalf("NewClient 失败: %v", err)
+	}
+	defer client.Close()
+
+	if err := client.VisitSitemap(srv.URL + "/sitemap.xml"); err != nil {
+		t.Fatalf("VisitSitemap 失败: %v", err)
+	}
+
+	// lastmod 越新越先出队，重复 URL 只入队一次，缺少 lastmod 的排在最后
+	want := []string{"https://example.com/new", "https://example.com/old", "https://example.com/about"}
+	for _, url := range want {
+		req, _ := client.Queue().Pop()
+		if req == nil || req.URL != url {
+			t.Fatalf("期望 %s，实际 %+v", url, req)
+		}
+	}
+	if size := client.Queue().Size(); size != 0 {
+		t.Errorf("队列应为空，剩余 %d", size)
+	}
+}
+
+func TestParseFeed(t *testing.T) {
+	srv := newSeedServer(t)
+
+	client, err := NewClient(DefaultConfig())
+	if err != nil {
+		t.Fatalf("NewClient 失败: %v", err)
+	}
+	defer client.Close()
+
+	seeds, err := client.ParseFeed(srv.URL + "/atom.xml")
+	if err != nil {
+		t.Fatalf("ParseFeed(atom) 失败: %v", err)
+	}
+	if len(seeds) != 2 || seeds[0].URL != "https://example.com/a/1" || seeds[1].LastMod.Month() != time.June {
+		t.Errorf("atom 解析错误: %+v", seeds)
+	}
+
+	seeds, err = client.ParseFeed(srv.URL + "/rss.xml")
+	if err != nil {
+		t.Fatalf("ParseFeed(rss) 失败: %v", err)
+	}
+	if len(seeds) != 1 || seeds[0].URL != "https://example.com/r/1" || seeds[0].LastMod.Year() != 2006 {
+		t.Errorf("rss 解析错误: %+v", seeds)
+	}
+}
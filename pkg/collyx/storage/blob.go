@@ -0,0 +1,107 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path"
+	"sort"
+	"time"
+)
+
+// ErrBlobNotFound 内容不存在（或已被保留策略清理）
+var ErrBlobNotFound = errors.New("内容不存在")
+
+// BlobInfo 内容信息
+type BlobInfo struct {
+	Key     string    // 内容哈希
+	Size    int64     // 字节数
+	ModTime time.Time // 写入时间
+}
+
+// BlobStore 原始内容存储（网页正文、文件），元数据仍保存在 Storage 中
+// 按内容寻址：Key 为内容的 SHA256，相同内容只保存一份
+type BlobStore interface {
+	Put(ctx context.Context, data []byte, contentType string) (key string, err error) // 保存内容，返回 Key
+	Get(ctx context.Context, key string) ([]byte, error)                              // 读取内容，不存在返回 ErrBlobNotFound
+	Exists(ctx context.Context, key string) (bool, error)                             // 是否存在
+	Delete(ctx context.Context, key string) error                                     // 删除内容（不存在不报错）
+	List(ctx context.Context, fn func(BlobInfo) error) error                          // 遍历所有内容
+	Close() error                                                                     // 关闭
+}
+
+// BlobPath 内容的相对路径：ab/cd/abcd...（前两级目录避免单目录文件过多）
+func BlobPath(key string) string {
+	if len(key) < 4 {
+		return key
+	}
+	return path.Join(key[:2], key[2:4], key)
+}
+
+// validBlobKey 校验 Key 为 SHA256 十六进制字符串（防止路径穿越）
+func validBlobKey(key string) error {
+	if len(key) != 64 {
+		return fmt.Errorf("无效的内容 Key: %s", key)
+	}
+	for _, r := range key {
+		if !(r >= '0' && r <= '9' || r >= 'a' && r <= 'f') {
+			return fmt.Errorf("无效的内容 Key: %s", key)
+		}
+	}
+	return nil
+}
+
+// RetentionPolicy 内容保留策略（零值表示不限制）
+type RetentionPolicy struct {
+	MaxAge   time.Duration // 最长保留时间，超过的内容被删除
+	MaxBytes int64         // 总容量上限，超出时从最旧的内容开始删除
+	Interval time.Duration // 清理间隔（Client 后台清理使用），默认 1h
+}
+
+// Enabled 是否配置了保留策略
+func (p RetentionPolicy) Enabled() bool {
+	return p.MaxAge > 0 || p.MaxBytes > 0
+}
+
+// ApplyRetention 按保留策略清理内容，返回删除的数量和释放的字节数
+func ApplyRetention(ctx context.Context, store BlobStore, policy RetentionPolicy) (int, int64, error) {
+	if !policy.Enabled() {
+		return 0, 0, nil
+	}
+
+	var blobs []BlobInfo
+	var total int64
+	err := store.List(ctx, func(info BlobInfo) error {
+		blobs = append(blobs, info)
+		total += info.Size
+		return nil
+	})
+	if err != nil {
+		return 0, 0, fmt.Errorf("遍历内容失败: %w", err)
+	}
+
+	// 从最旧的开始删除
+	sort.Slice(blobs, func(i, j int) bool {
+		return blobs[i].ModTime.Before(blobs[j].ModTime)
+	})
+
+	var removed int
+	var freed int64
+	deadline := time.Now().Add(-policy.MaxAge)
+	for _, info := range blobs {
+		expired := policy.MaxAge > 0 && info.ModTime.Before(deadline)
+		overflow := policy.MaxBytes > 0 && total > policy.MaxBytes
+		if !expired && !overflow {
+			break
+		}
+
+		if err := store.Delete(ctx, info.Key); err != nil {
+			return removed, freed, fmt.Errorf("删除内容失败: %w", err)
+		}
+		removed++
+		freed += info.Size
+		total -= info.Size
+	}
+
+	return removed, freed, nil
+}
@@ -0,0 +1,126 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// FSBlobStore 本地文件系统内容存储
+type FSBlobStore struct {
+	root string
+}
+
+// NewFSBlobStore 创建本地文件系统内容存储
+func NewFSBlobStore(root string) (*FSBlobStore, error) {
+	if err := os.MkdirAll(root, 0755); err != nil {
+		return nil, fmt.Errorf("创建目录失败: %w", err)
+	}
+	return &FSBlobStore{root: root}, nil
+}
+
+// path 内容的完整路径
+func (s *FSBlobStore) path(key string) string {
+	return filepath.Join(s.root, filepath.FromSlash(BlobPath(key)))
+}
+
+// Put 保存内容（先写临时文件再重命名，避免读到不完整的内容）
+func (s *FSBlobStore) Put(ctx context.Context, data []byte, contentType string) (string, error) {
+	key := HashContent(data)
+	p := s.path(key)
+
+	if _, err := os.Stat(p); err == nil {
+		return key, nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+		return "", fmt.Errorf("创建目录失败: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(p), key+".tmp-*")
+	if err != nil {
+		return "", fmt.Errorf("创建临时文件失败: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return "", fmt.Errorf("写入文件失败: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return "", fmt.Errorf("写入文件失败: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), p); err != nil {
+		return "", fmt.Errorf("保存文件失败: %w", err)
+	}
+
+	return key, nil
+}
+
+// Get 读取内容
+func (s *FSBlobStore) Get(ctx context.Context, key string) ([]byte, error) {
+	if err := validBlobKey(key); err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(s.path(key))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("%w: %s", ErrBlobNotFound, key)
+	}
+	return data, err
+}
+
+// Exists 是否存在
+func (s *FSBlobStore) Exists(ctx context.Context, key string) (bool, error) {
+	if err := validBlobKey(key); err != nil {
+		return false, err
+	}
+
+	_, err := os.Stat(s.path(key))
+	if errors.Is(err, fs.ErrNotExist) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// Delete 删除内容
+func (s *FSBlobStore) Delete(ctx context.Context, key string) error {
+	if err := validBlobKey(key); err != nil {
+		return err
+	}
+
+	err := os.Remove(s.path(key))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	return err
+}
+
+// List 遍历所有内容（跳过写入中的临时文件）
+func (s *FSBlobStore) List(ctx context.Context, fn func(BlobInfo) error) error {
+	return filepath.WalkDir(s.root, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if d.IsDir() || validBlobKey(d.Name()) != nil {
+			return nil
+		}
+
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		return fn(BlobInfo{Key: d.Name(), Size: info.Size(), ModTime: info.ModTime()})
+	})
+}
+
+// Close 关闭（本地存储无需关闭）
+func (s *FSBlobStore) Close() error {
+	return nil
+}
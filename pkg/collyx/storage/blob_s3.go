@@ -0,0 +1,172 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/credentials"
+)
+
+// S3Config S3 兼容存储配置（AWS S3、MinIO、阿里云 OSS 等）
+type S3Config struct {
+	Endpoint  string // 服务地址（如 s3.amazonaws.com、127.0.0.1:9000）
+	AccessKey string
+	SecretKey string
+	Region    string
+	Bucket    string // 存储桶（不存在时自动创建）
+	Prefix    string // 对象前缀（如 crawler/bodies）
+	UseSSL    bool
+}
+
+// S3BlobStore S3 兼容内容存储
+type S3BlobStore struct {
+	client *minio.Client
+	bucket string
+	prefix string
+}
+
+// NewS3BlobStore 创建 S3 兼容内容存储
+func NewS3BlobStore(ctx context.Context, cfg *S3Config) (*S3BlobStore, error) {
+	if cfg == nil || cfg.Endpoint == "" || cfg.Bucket == "" {
+		return nil, fmt.Errorf("S3 配置缺少 Endpoint 或 Bucket")
+	}
+
+	client, err := minio.New(cfg.Endpoint, &minio.Options{
+		Creds:  credentials.NewStaticV4(cfg.AccessKey, cfg.SecretKey, ""),
+		Secure: cfg.UseSSL,
+		Region: cfg.Region,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("创建 S3 客户端失败: %w", err)
+	}
+
+	exists, err := client.BucketExists(ctx, cfg.Bucket)
+	if err != nil {
+		return nil, fmt.Errorf("检查存储桶失败: %w", err)
+	}
+	if !exists {
+		if err := client.MakeBucket(ctx, cfg.Bucket, minio.MakeBucketOptions{Region: cfg.Region}); err != nil {
+			return nil, fmt.Errorf("创建存储桶失败: %w", err)
+		}
+	}
+
+	return &S3BlobStore{
+		client: client,
+		bucket: cfg.Bucket,
+		prefix: strings.Trim(cfg.Prefix, "/"),
+	}, nil
+}
+
+// object 内容的对象名
+func (s *S3BlobStore) object(key string) string {
+	return path.Join(s.prefix, BlobPath(key))
+}
+
+// Put 保存内容（已存在时跳过上传）
+func (s *S3BlobStore) Put(ctx context.Context, data []byte, contentType string) (string, error) {
+	key := HashContent(data)
+
+	exists, err := s.Exists(ctx, key)
+	if err != nil {
+		return "", err
+	}
+	if exists {
+		return key, nil
+	}
+
+	_, err = s.client.PutObject(ctx, s.bucket, s.object(key), bytes.NewReader(data), int64(len(data)),
+		minio.PutObjectOptions{ContentType: contentType})
+	if err != nil {
+		return "", fmt.Errorf("上传内容失败: %w", err)
+	}
+
+	return key, nil
+}
+
+// Get 读取内容
+func (s *S3BlobStore) Get(ctx context.Context, key string) ([]byte, error) {
+	if err := validBlobKey(key); err != nil {
+		return nil, err
+	}
+
+	obj, err := s.client.GetObject(ctx, s.bucket, s.object(key), minio.GetObjectOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("读取内容失败: %w", err)
+	}
+	defer obj.Close()
+
+	data, err := io.ReadAll(obj)
+	if isNoSuchKey(err) {
+		return nil, fmt.Errorf("%w: %s", ErrBlobNotFound, key)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("读取内容失败: %w", err)
+	}
+	return data, nil
+}
+
+// Exists 是否存在
+func (s *S3BlobStore) Exists(ctx context.Context, key string) (bool, error) {
+	if err := validBlobKey(key); err != nil {
+		return false, err
+	}
+
+	_, err := s.client.StatObject(ctx, s.bucket, s.object(key), minio.StatObjectOptions{})
+	if isNoSuchKey(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("检查内容失败: %w", err)
+	}
+	return true, nil
+}
+
+// Delete 删除内容
+func (s *S3BlobStore) Delete(ctx context.Context, key string) error {
+	if err := validBlobKey(key); err != nil {
+		return err
+	}
+	return s.client.RemoveObject(ctx, s.bucket, s.object(key), minio.RemoveObjectOptions{})
+}
+
+// List 遍历所有内容
+func (s *S3BlobStore) List(ctx context.Context, fn func(BlobInfo) error) error {
+	prefix := s.prefix
+	if prefix != "" {
+		prefix += "/"
+	}
+
+	// 提前返回时取消，避免 ListObjects 的 goroutine 泄漏
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	for obj := range s.client.ListObjects(ctx, s.bucket, minio.ListObjectsOptions{Prefix: prefix, Recursive: true}) {
+		if obj.Err != nil {
+			return fmt.Errorf("列出内容失败: %w", obj.Err)
+		}
+
+		key := path.Base(obj.Key)
+		if validBlobKey(key) != nil {
+			continue
+		}
+		if err := fn(BlobInfo{Key: key, Size: obj.Size, ModTime: obj.LastModified}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Close 关闭（S3 客户端无需关闭）
+func (s *S3BlobStore) Close() error {
+	return nil
+}
+
+// isNoSuchKey 是否为对象不存在错误
+func isNoSuchKey(err error) bool {
+	return err != nil && minio.ToErrorResponse(err).Code == minio.NoSuchKey
+}
@@ -0,0 +1,88 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestFSBlobStore(t *testing.T) {
+	ctx := context.Background()
+	root := t.TempDir()
+	store, err := NewFSBlobStore(root)
+	if err != nil {
+		t.Fatalf("创建失败: %v", err)
+	}
+
+	key, err := store.Put(ctx, []byte("<html>hello</html>"), "text/html")
+	if err != nil {
+		t.Fatalf("Put 失败: %v", err)
+	}
+	if key != HashContent([]byte("<html>hello</html>")) {
+		t.Errorf("Key 应为内容哈希: %s", key)
+	}
+	if _, err := os.Stat(filepath.Join(root, key[:2], key[2:4], key)); err != nil {
+		t.Errorf("内容路径错误: %v", err)
+	}
+
+	// 相同内容只保存一份
+	again, _ := store.Put(ctx, []byte("<html>hello</html>"), "text/html")
+	if again != key {
+		t.Errorf("相同内容 Key 不一致: %s != %s", again, key)
+	}
+
+	data, err := store.Get(ctx, key)
+	if err != nil || string(data) != "<html>hello</html>" {
+		t.Fatalf("Get 失败: %q (%v)", data, err)
+	}
+
+	if err := store.Delete(ctx, key); err != nil {
+		t.Fatalf("Delete 失败: %v", err)
+	}
+	if _, err := store.Get(ctx, key); !errors.Is(err, ErrBlobNotFound) {
+		t.Errorf("删除后应返回 ErrBlobNotFound，实际 %v", err)
+	}
+	if _, err := store.Get(ctx, "../../etc/passwd"); err == nil {
+		t.Errorf("非法 Key 应返回错误")
+	}
+}
+
+func TestApplyRetention(t *testing.T) {
+	ctx := context.Background()
+	root := t.TempDir()
+	store, _ := NewFSBlobStore(root)
+
+	old, _ := store.Put(ctx, []byte("old page"), "")
+	mid, _ := store.Put(ctx, []byte("middle page"), "")
+	recent, _ := store.Put(ctx, []byte("recent page"), "")
+
+	setAge := func(key string, age time.Duration) {
+		mtime := time.Now().Add(-age)
+		os.Chtimes(filepath.Join(root, filepath.FromSlash(BlobPath(key))), mtime, mtime)
+	}
+	setAge(old, 48*time.Hour)
+	setAge(mid, 2*time.Hour)
+	setAge(recent, time.Minute)
+
+	// 超过 24 小时的被删除
+	removed, _, err := ApplyRetention(ctx, store, RetentionPolicy{MaxAge: 24 * time.Hour})
+	if err != nil || removed != 1 {
+		t.Fatalf("按时间清理应删除 1 个，实际 %d (%v)", removed, err)
+	}
+
+	// 超出容量时从最旧的开始删除
+	removed, freed, err := ApplyRetention(ctx, store, RetentionPolicy{MaxBytes: int64(len("recent page"))})
+	if err != nil || removed != 1 || freed != int64(len("middle page")) {
+		t.Fatalf("按容量清理应删除 middle，实际 %d/%d (%v)", removed, freed, err)
+	}
+
+	if ok, _ := store.Exists(ctx, recent); !ok {
+		t.Errorf("最新内容不应被删除")
+	}
+	if ok, _ := store.Exists(ctx, mid); ok {
+		t.Errorf("middle 应被删除")
+	}
+}
@@ -68,3 +68,16 @@ func ShouldSkipItem(storage Storage, contentHash string) (bool, *Item, error) {
 	}
 	return false, nil, nil
 }
+
+// ShouldSkipSimilarItem 判断是否存在近似重复的内容（海明距离不超过 maxDistance）
+func ShouldSkipSimilarItem(storage Storage, simHash uint64, maxDistance int) (bool, *Item, error) {
+	if simHash == 0 || maxDistance < 0 {
+		return false, nil, nil
+	}
+
+	item, err := storage.FindSimilarItem(simHash, maxDistance)
+	if err == nil && item.Status == ItemStatusSaved {
+		return true, item, nil
+	}
+	return false, nil, nil
+}
@@ -1,16 +1,18 @@
 package storage
 
 import (
+	"errors"
 	"fmt"
 	"time"
 
 	"gorm.io/driver/mysql"
+	"gorm.io/driver/postgres"
 	"gorm.io/driver/sqlite"
 	"gorm.io/gorm"
 	"gorm.io/gorm/logger"
 )
 
-// GormStorage GORM 存储（支持 SQLite 和 MySQL）
+// GormStorage GORM 存储（支持 SQLite、MySQL 和 PostgreSQL）
 type GormStorage struct {
 	db *gorm.DB
 }
@@ -49,9 +51,26 @@ func NewMySQLStorage(dsn string) (*GormStorage, error) {
 	return s, nil
 }
 
+// NewPostgresStorage 创建 PostgreSQL 存储
+func NewPostgresStorage(dsn string) (*GormStorage, error) {
+	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("打开数据库失败: %w", err)
+	}
+
+	s := &GormStorage{db: db}
+	if err := s.initTables(); err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
 // initTables 初始化表
 func (s *GormStorage) initTables() error {
-	return s.db.AutoMigrate(&Task{}, &Item{})
+	return s.db.AutoMigrate(&Task{}, &Item{}, &DeadLetter{}, &Job{}, &StopRecord{})
 }
 
 // SaveTask 保存任务
@@ -263,6 +282,52 @@ func (s *GormStorage) GetItemByContentHash(hash string) (*Item, error) {
 	return &item, err
 }
 
+// FindSimilarItem 查找海明距离不超过 maxDistance 的内容
+// maxDistance 小于 SimHashBands 时通过分段索引查找候选，否则扫描所有带指纹的内容
+func (s *GormStorage) FindSimilarItem(simHash uint64, maxDistance int) (*Item, error) {
+	query := s.db.Model(&Item{}).Select("id", "sim_hash").Where("sim_hash <> ''")
+	if maxDistance < SimHashBands {
+		query = query.Where(
+			s.db.Where("sim_band0 = ?", SimHashBand(simHash, 0)).
+				Or("sim_band1 = ?", SimHashBand(simHash, 1)).
+				Or("sim_band2 = ?", SimHashBand(simHash, 2)).
+				Or("sim_band3 = ?", SimHashBand(simHash, 3)),
+		)
+	}
+
+	var candidates []*Item
+	err := query.FindInBatches(&candidates, 1000, func(tx *gorm.DB, batch int) error {
+		for _, c := range candidates {
+			fp, err := ParseSimHash(c.SimHash)
+			if err != nil {
+				continue
+			}
+			if HammingDistance(fp, simHash) <= maxDistance {
+				return errSimilarFound{id: c.ID}
+			}
+		}
+		return nil
+	}).Error
+
+	var found errSimilarFound
+	if errors.As(err, &found) {
+		return s.GetItem(found.id)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return nil, fmt.Errorf("相似内容不存在: %s", FormatSimHash(simHash))
+}
+
+// errSimilarFound 找到相似内容时中断批量扫描
+type errSimilarFound struct {
+	id string
+}
+
+func (e errSimilarFound) Error() string {
+	return "similar item found: " + e.id
+}
+
 // ListItems 列出内容
 func (s *GormStorage) ListItems(filter *ItemFilter) ([]*Item, error) {
 	query := s.db.Model(&Item{}).Scopes(applyItemFilter(filter))
@@ -307,7 +372,86 @@ func (s *GormStorage) DeleteItem(id string) error {
 	return s.db.Where("id = ?", id).Delete(&Item{}).Error
 }
 
-// Clear 清空所有数据
+// SaveDeadLetter 保存死信
+func (s *GormStorage) SaveDeadLetter(dl *DeadLetter) error {
+	if dl.ID == "" {
+		dl.ID = HashURL(dl.URL)
+	}
+	if dl.CreatedAt.IsZero() {
+		dl.CreatedAt = time.Now()
+	}
+	return s.db.Save(dl).Error
+}
+
+// ListDeadLetters 列出死信
+func (s *GormStorage) ListDeadLetters(limit, offset int) ([]*DeadLetter, error) {
+	query := s.db.Model(&DeadLetter{}).Order("created_at DESC")
+	if limit > 0 {
+		query = query.Limit(limit).Offset(offset)
+	}
+
+	var letters []*DeadLetter
+	err := query.Find(&letters).Error
+	return letters, err
+}
+
+// DeleteDeadLetter 删除死信
+func (s *GormStorage) DeleteDeadLetter(id string) error {
+	return s.db.Where("id = ?", id).Delete(&DeadLetter{}).Error
+}
+
+// SaveJob 保存爬取任务
+func (s *GormStorage) SaveJob(job *Job) error {
+	now := time.Now()
+	if job.CreatedAt.IsZero() {
+		job.CreatedAt = now
+	}
+	job.UpdatedAt = now
+	return s.db.Save(job).Error
+}
+
+// GetJob 获取爬取任务
+func (s *GormStorage) GetJob(name string) (*Job, error) {
+	var job Job
+	err := s.db.Where("name = ?", name).First(&job).Error
+	if err == gorm.ErrRecordNotFound {
+		return nil, fmt.Errorf("爬取任务不存在: %s", name)
+	}
+	return &job, err
+}
+
+// ListJobs 列出爬取任务
+func (s *GormStorage) ListJobs() ([]*Job, error) {
+	var jobs []*Job
+	err := s.db.Order("name").Find(&jobs).Error
+	return jobs, err
+}
+
+// DeleteJob 删除爬取任务
+func (s *GormStorage) DeleteJob(name string) error {
+	return s.db.Where("name = ?", name).Delete(&Job{}).Error
+}
+
+// SaveStopRecord 保存停止记录
+func (s *GormStorage) SaveStopRecord(record *StopRecord) error {
+	if record.CreatedAt.IsZero() {
+		record.CreatedAt = time.Now()
+	}
+	return s.db.Create(record).Error
+}
+
+// ListStopRecords 列出停止记录（按时间倒序），limit 为 0 时返回全部
+func (s *GormStorage) ListStopRecords(limit int) ([]*StopRecord, error) {
+	var records []*StopRecord
+	query := s.db.Order("created_at DESC, id DESC")
+	if limit > 0 {
+		query = query.Limit(limit)
+	}
+	err := query.Find(&records).Error
+	return records, err
+}
+
+// Clear 清空所有爬取数据（不包括 Job）
 func (s *GormStorage) Clear() error {
 	return s.db.Transaction(func(tx *gorm.DB) error {
 		if err := tx.Where("1 = 1").Delete(&Task{}).Error; err != nil {
@@ -316,6 +460,12 @@ func (s *GormStorage) Clear() error {
 		if err := tx.Where("1 = 1").Delete(&Item{}).Error; err != nil {
 			return err
 		}
+		if err := tx.Where("1 = 1").Delete(&DeadLetter{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("1 = 1").Delete(&StopRecord{}).Error; err != nil {
+			return err
+		}
 		return nil
 	})
 }
@@ -0,0 +1,111 @@
+package storage
+
+import (
+	"fmt"
+	"hash/fnv"
+	"math/bits"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// SimHashBands 指纹分段数（每段 16 位）
+// 海明距离不超过 SimHashBands-1 的两个指纹至少有一段完全相同，可以通过分段索引查找候选
+const SimHashBands = 4
+
+// SimHash 计算文本的 64 位 SimHash 指纹
+// 英文按单词、中文按单字切分，以相邻两个词作为特征（保留语序）
+func SimHash(text string) uint64 {
+	tokens := tokenize(text)
+	if len(tokens) == 0 {
+		return 0
+	}
+
+	features := make(map[string]int)
+	if len(tokens) == 1 {
+		features[tokens[0]] = 1
+	}
+	for i := 0; i+1 < len(tokens); i++ {
+		features[tokens[i]+" "+tokens[i+1]]++
+	}
+
+	var v [64]int
+	for feature, weight := range features {
+		h := fnv.New64a()
+		h.Write([]byte(feature))
+		sum := h.Sum64()
+		for i := 0; i < 64; i++ {
+			if sum&(1<<uint(i)) != 0 {
+				v[i] += weight
+			} else {
+				v[i] -= weight
+			}
+		}
+	}
+
+	var fingerprint uint64
+	for i := 0; i < 64; i++ {
+		if v[i] > 0 {
+			fingerprint |= 1 << uint(i)
+		}
+	}
+	return fingerprint
+}
+
+// HammingDistance 两个指纹的海明距离
+func HammingDistance(a, b uint64) int {
+	return bits.OnesCount64(a ^ b)
+}
+
+// FormatSimHash 指纹转为 16 位十六进制字符串（用于存储）
+func FormatSimHash(fingerprint uint64) string {
+	return fmt.Sprintf("%016x", fingerprint)
+}
+
+// ParseSimHash 解析十六进制指纹
+func ParseSimHash(s string) (uint64, error) {
+	return strconv.ParseUint(s, 16, 64)
+}
+
+// SimHashBand 指纹的第 i 段（i 从 0 开始）
+func SimHashBand(fingerprint uint64, i int) int {
+	return int(fingerprint >> uint(16*i) & 0xffff)
+}
+
+// SetSimHash 为内容设置指纹和分段索引
+func (item *Item) SetSimHash(fingerprint uint64) {
+	item.SimHash = FormatSimHash(fingerprint)
+	item.SimBand0 = SimHashBand(fingerprint, 0)
+	item.SimBand1 = SimHashBand(fingerprint, 1)
+	item.SimBand2 = SimHashBand(fingerprint, 2)
+	item.SimBand3 = SimHashBand(fingerprint, 3)
+}
+
+// tokenize 切词：连续的字母/数字为一个词，中日韩字符单字成词
+func tokenize(text string) []string {
+	var tokens []string
+	var word strings.Builder
+
+	flush := func() {
+		if word.Len() > 0 {
+			tokens = append(tokens, word.String())
+			word.Reset()
+		}
+	}
+
+	for _, r := range strings.ToLower(text) {
+		switch {
+		case unicode.Is(unicode.Han, r) || unicode.Is(unicode.Hiragana, r) ||
+			unicode.Is(unicode.Katakana, r) || unicode.Is(unicode.Hangul, r):
+			flush()
+			tokens = append(tokens, string(r))
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			word.WriteRune(r)
+		default:
+			flush()
+		}
+	}
+	flush()
+
+	return tokens
+}
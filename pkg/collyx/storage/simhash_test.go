@@ -0,0 +1,39 @@
+package storage
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSimHash(t *testing.T) {
+	article := strings.Repeat("Go is an open source programming language that makes it simple to build secure, scalable systems. ", 5) +
+		"分布式爬虫需要对内容去重，模板变化不应影响判断结果。"
+	boilerplate := article + " Copyright 2025"
+	other := "The quick brown fox jumps over the lazy dog while the weather report predicts rain for the weekend."
+
+	a, b, c := SimHash(article), SimHash(boilerplate), SimHash(other)
+
+	if d := HammingDistance(a, b); d > 3 {
+		t.Errorf("仅页脚不同的内容海明距离应很小，实际 %d", d)
+	}
+	if d := HammingDistance(a, c); d <= 3 {
+		t.Errorf("不同内容海明距离应较大，实际 %d", d)
+	}
+	if SimHash("") != 0 {
+		t.Errorf("空文本指纹应为 0")
+	}
+}
+
+func TestSimHashFormat(t *testing.T) {
+	fp := SimHash("hello world")
+	parsed, err := ParseSimHash(FormatSimHash(fp))
+	if err != nil || parsed != fp {
+		t.Fatalf("格式化往返失败: %x != %x (%v)", parsed, fp, err)
+	}
+
+	var item Item
+	item.SetSimHash(0x1111222233334444)
+	if item.SimBand0 != 0x4444 || item.SimBand3 != 0x1111 || item.SimHash != "1111222233334444" {
+		t.Errorf("分段错误: %+v", item)
+	}
+}
@@ -70,11 +70,16 @@ type Item struct {
 	TaskID      string         `json:"task_id" gorm:"size:255;index:idx_task_id"`
 	URL         string         `json:"url" gorm:"type:text"`
 	Type        ItemType       `json:"type" gorm:"size:20;index:idx_type"`
-	Status      ItemStatus     `json:"status" gorm:"size:20;index:idx_status"`
+	Status      ItemStatus     `json:"status" gorm:"size:20;index:idx_item_status"` // PostgreSQL 索引名全库唯一，不能与 Task 重名
 	Title       string         `json:"title,omitempty" gorm:"type:text"`
 	Content     string         `json:"content,omitempty" gorm:"type:text"`
 	FilePath    string         `json:"file_path,omitempty" gorm:"type:text"`
 	ContentHash string         `json:"content_hash,omitempty" gorm:"size:64;index:idx_content_hash"`
+	SimHash     string         `json:"sim_hash,omitempty" gorm:"size:16"` // SimHash 指纹（近似去重）
+	SimBand0    int            `json:"-" gorm:"index:idx_sim_band0"`      // 指纹分段（用于查找相似内容）
+	SimBand1    int            `json:"-" gorm:"index:idx_sim_band1"`
+	SimBand2    int            `json:"-" gorm:"index:idx_sim_band2"`
+	SimBand3    int            `json:"-" gorm:"index:idx_sim_band3"`
 	Size        int64          `json:"size"`
 	Error       string         `json:"error,omitempty" gorm:"type:text"`
 	Metadata    map[string]any `json:"metadata,omitempty" gorm:"serializer:json;type:text"`
@@ -82,6 +87,65 @@ type Item struct {
 	UpdatedAt   time.Time      `json:"updated_at"`
 }
 
+// DeadLetter 重试耗尽的请求（死信），用于排查和重放
+type DeadLetter struct {
+	ID         string    `json:"id" gorm:"primaryKey;size:255"` // URL 哈希
+	URL        string    `json:"url" gorm:"type:text"`
+	Method     string    `json:"method" gorm:"size:10"`
+	Depth      int       `json:"depth"`
+	StatusCode int       `json:"status_code"`
+	Error      string    `json:"error,omitempty" gorm:"type:text"`
+	Retries    int       `json:"retries"`     // 已重试次数
+	FirstError time.Time `json:"first_error"` // 第一次失败时间
+	CreatedAt  time.Time `json:"created_at" gorm:"index"`
+}
+
+// JobStatus 爬取任务（Job）状态
+type JobStatus string
+
+const (
+	JobStatusPending   JobStatus = "pending"   // 未运行
+	JobStatusRunning   JobStatus = "running"   // 运行中
+	JobStatusPaused    JobStatus = "paused"    // 暂停
+	JobStatusCompleted JobStatus = "completed" // 已完成
+	JobStatusFailed    JobStatus = "failed"    // 失败
+	JobStatusCancelled JobStatus = "cancelled" // 已取消
+)
+
+// Job 爬取任务：一组种子 URL 和配置覆盖，可定时运行
+type Job struct {
+	Name       string         `json:"name" gorm:"primaryKey;size:255"`
+	Seeds      []string       `json:"seeds" gorm:"serializer:json;type:text"`
+	Config     map[string]any `json:"config,omitempty" gorm:"serializer:json;type:text"` // 配置覆盖
+	Schedule   string         `json:"schedule,omitempty" gorm:"size:100"`                // cron 表达式，为空只手动运行
+	Status     JobStatus      `json:"status" gorm:"size:20;index:idx_job_status"`
+	Error      string         `json:"error,omitempty" gorm:"type:text"`
+	StopReason string         `json:"stop_reason,omitempty" gorm:"type:text"`
+	Runs       int            `json:"runs"`      // 已运行次数
+	Requests   int64          `json:"requests"`  // 本次运行的请求数
+	Responses  int64          `json:"responses"` // 本次运行的响应数
+	Errors     int64          `json:"errors"`    // 本次运行的错误数
+	Queued     int64          `json:"queued"`    // 队列中待处理的请求数
+	StartedAt  *time.Time     `json:"started_at,omitempty"`
+	FinishedAt *time.Time     `json:"finished_at,omitempty"`
+	NextRunAt  *time.Time     `json:"next_run_at,omitempty" gorm:"index"`
+	CreatedAt  time.Time      `json:"created_at"`
+	UpdatedAt  time.Time      `json:"updated_at"`
+}
+
+// StopRecord 抓取达到预算（页面数、字节数、时长、错误数）提前结束的记录
+type StopRecord struct {
+	ID        int64         `json:"id" gorm:"primaryKey;autoIncrement"`
+	Job       string        `json:"job,omitempty" gorm:"size:255;index:idx_stop_job"` // 爬取任务名称，非任务运行时为空
+	Condition string        `json:"condition" gorm:"size:32"`                         // 触发的条件（max_pages/max_bytes/max_duration/max_errors）
+	Reason    string        `json:"reason" gorm:"type:text"`
+	Pages     int64         `json:"pages"`  // 已抓取页面数
+	Bytes     int64         `json:"bytes"`  // 已下载字节数
+	Errors    int64         `json:"errors"` // 错误数
+	Elapsed   time.Duration `json:"elapsed"`
+	CreatedAt time.Time     `json:"created_at" gorm:"index"`
+}
+
 // Progress 进度信息
 type Progress struct {
 	Total       int64      `json:"total"`
@@ -133,18 +197,34 @@ type Storage interface {
 	UpdateTaskStatus(id string, status TaskStatus) error // 更新状态
 
 	// 内容管理
-	SaveItem(item *Item) error                           // 保存内容
-	GetItem(id string) (*Item, error)                    // 获取内容
-	GetItemByContentHash(hash string) (*Item, error)     // 根据内容哈希获取（去重）
-	UpdateItemStatus(id string, status ItemStatus) error // 更新内容状态
-	ListItems(filter *ItemFilter) ([]*Item, error)       // 列出内容
-	CountItems(filter *ItemFilter) (int64, error)        // 统计内容数
-	DeleteItem(id string) error                          // 删除内容
+	SaveItem(item *Item) error                                      // 保存内容
+	GetItem(id string) (*Item, error)                               // 获取内容
+	GetItemByContentHash(hash string) (*Item, error)                // 根据内容哈希获取（去重）
+	FindSimilarItem(simHash uint64, maxDistance int) (*Item, error) // 查找指纹相近的内容（近似去重）
+	UpdateItemStatus(id string, status ItemStatus) error            // 更新内容状态
+	ListItems(filter *ItemFilter) ([]*Item, error)                  // 列出内容
+	CountItems(filter *ItemFilter) (int64, error)                   // 统计内容数
+	DeleteItem(id string) error                                     // 删除内容
+
+	// 死信（重试耗尽的请求）
+	SaveDeadLetter(dl *DeadLetter) error                      // 保存死信（相同 URL 覆盖）
+	ListDeadLetters(limit, offset int) ([]*DeadLetter, error) // 列出死信（按时间倒序）
+	DeleteDeadLetter(id string) error                         // 删除死信
+
+	// 爬取任务（Job）
+	SaveJob(job *Job) error           // 保存任务（相同名称覆盖）
+	GetJob(name string) (*Job, error) // 获取任务
+	ListJobs() ([]*Job, error)        // 列出任务（按名称排序）
+	DeleteJob(name string) error      // 删除任务
+
+	// 停止记录（抓取预算）
+	SaveStopRecord(record *StopRecord) error          // 保存停止记录
+	ListStopRecords(limit int) ([]*StopRecord, error) // 列出停止记录（按时间倒序）
 
 	// 进度管理（通过统计 Task 表得出）
 	GetProgress() (*Progress, error) // 获取进度
 
 	// 清理
-	Clear() error // 清空所有数据
+	Clear() error // 清空所有爬取数据（不包括 Job）
 	Close() error // 关闭连接
 }
@@ -11,54 +11,91 @@ import (
 )
 
 type (
-	// Environment conda 环境信息
-	Environment struct {
-		Name   string `json:"name"`
+	// Env conda 环境信息
+	Env struct {
+		Name   string `json:"name"` // 不在 envs 目录下的环境（按路径创建）没有名称
 		Path   string `json:"path"`
 		Active bool   `json:"active"`
+		Base   bool   `json:"base"` // base 环境
 	}
 
+	// Environment conda 环境信息
+	//
+	// Deprecated: 使用 Env
+	Environment = Env
+
 	// Package 包信息
 	Package struct {
 		Name    string `json:"name"`
 		Version string `json:"version"`
 		Channel string `json:"channel"`
 	}
+
+	// condaInfo conda info --json 的输出
+	condaInfo struct {
+		Envs         []string `json:"envs"`
+		EnvsDirs     []string `json:"envs_dirs"`
+		RootPrefix   string   `json:"root_prefix"`
+		ActivePrefix string   `json:"active_prefix"`
+	}
 )
 
 // ListEnvs 列出所有 conda 环境
-func ListEnvs() ([]Environment, error) {
-	cmd := exec.Command("conda", "env", "list", "--json")
-	output, err := cmd.Output()
+func ListEnvs() ([]Env, error) {
+	output, err := run("info", "--json")
 	if err != nil {
-		return nil, fmt.Errorf("执行 conda env list 失败: %w", err)
+		return nil, err
 	}
 
-	var result struct {
-		Envs []string `json:"envs"`
-	}
-	if err := json.Unmarshal(output, &result); err != nil {
+	var info condaInfo
+	if err := json.Unmarshal(output, &info); err != nil {
 		return nil, fmt.Errorf("解析 conda 输出失败: %w", err)
 	}
 
-	// 获取当前激活的环境
-	activeEnv := os.Getenv("CONDA_DEFAULT_ENV")
+	return info.envs(), nil
+}
 
-	envs := make([]Environment, 0, len(result.Envs))
-	for _, path := range result.Envs {
-		name := filepath.Base(path)
-		if name == "envs" {
-			// 跳过 envs 目录本身
-			continue
-		}
-		envs = append(envs, Environment{
-			Name:   name,
+// envs 按 conda info 的输出生成环境列表
+func (info condaInfo) envs() []Env {
+	envs := make([]Env, 0, len(info.Envs))
+	for _, path := range info.Envs {
+		env := Env{
 			Path:   path,
-			Active: name == activeEnv,
-		})
+			Active: info.ActivePrefix != "" && path == info.ActivePrefix,
+			Base:   path == info.RootPrefix,
+		}
+
+		if env.Base {
+			env.Name = "base"
+		} else {
+			for _, dir := range info.EnvsDirs {
+				if filepath.Dir(path) == filepath.Clean(dir) {
+					env.Name = filepath.Base(path)
+					break
+				}
+			}
+		}
+
+		envs = append(envs, env)
+	}
+
+	return envs
+}
+
+// FindEnv 按名称查找环境
+func FindEnv(name string) (*Env, error) {
+	envs, err := ListEnvs()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, env := range envs {
+		if env.Name == name {
+			return &env, nil
+		}
 	}
 
-	return envs, nil
+	return nil, fmt.Errorf("%w: %s", ErrEnvNotFound, name)
 }
 
 // GetCurrentEnv 获取当前激活的环境
@@ -102,24 +139,44 @@ func UninstallPackage(envName, packageName string) error {
 	return cmd.Run()
 }
 
-// CreateEnv 创建新环境
-func CreateEnv(envName, pythonVersion string) error {
-	args := []string{"create", "-n", envName, "-y"}
+// CreateEnv 创建新环境，可以同时安装 packages（如 numpy、pandas=2.2）
+func CreateEnv(envName, pythonVersion string, packages ...string) error {
+	args := []string{"create", "-n", envName, "-y", "--json"}
 	if pythonVersion != "" {
 		args = append(args, fmt.Sprintf("python=%s", pythonVersion))
 	}
-	cmd := exec.Command("conda", args...)
-	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
-	return cmd.Run()
+	args = append(args, packages...)
+
+	_, err := run(args...)
+	return err
+}
+
+// CloneEnv 复制环境 src 为新环境 dst
+func CloneEnv(src, dst string) error {
+	if _, err := FindEnv(src); err != nil {
+		return err
+	}
+
+	_, err := run("create", "--clone", src, "-n", dst, "-y", "--json")
+	return err
+}
+
+// ExportEnv 导出环境为 environment.yml 内容
+//
+// fromHistory 为 true 时只导出显式安装的包（不含依赖和 build 号），便于跨平台重建环境
+func ExportEnv(envName string, fromHistory bool) ([]byte, error) {
+	args := []string{"env", "export", "-n", envName}
+	if fromHistory {
+		args = append(args, "--from-history")
+	}
+
+	return run(args...)
 }
 
 // RemoveEnv 删除环境
 func RemoveEnv(envName string) error {
-	cmd := exec.Command("conda", "env", "remove", "-n", envName, "-y")
-	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
-	return cmd.Run()
+	_, err := run("env", "remove", "-n", envName, "-y", "--json")
+	return err
 }
 
 // GetPythonPath 获取指定环境的 python 路径
@@ -138,7 +195,7 @@ func GetPythonPath(envName string) (string, error) {
 		}
 	}
 
-	return "", fmt.Errorf("环境 %s 不存在", envName)
+	return "", fmt.Errorf("%w: %s", ErrEnvNotFound, envName)
 }
 
 // RunPython 在指定环境运行 Python 脚本
@@ -0,0 +1,107 @@
+package conda
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+var (
+	// ErrNotInstalled 未找到 conda 命令
+	ErrNotInstalled = errors.New("未找到 conda 命令，请先安装 conda 并加入 PATH")
+	// ErrEnvNotFound 环境不存在
+	ErrEnvNotFound = errors.New("环境不存在")
+	// ErrEnvExists 环境已存在
+	ErrEnvExists = errors.New("环境已存在")
+	// ErrPackageNotFound 找不到指定的包
+	ErrPackageNotFound = errors.New("找不到指定的包")
+)
+
+// Error conda 命令执行失败
+//
+// Exception 为 conda 的异常类型（如 EnvironmentLocationNotFound），能识别的异常
+// 可以用 errors.Is 判断：errors.Is(err, conda.ErrEnvNotFound)
+type Error struct {
+	Args      []string // conda 命令参数
+	Exception string   // conda 异常类型
+	Message   string   // conda 输出的错误信息
+	Err       error    // 识别出的错误（ErrEnvNotFound 等）或命令的执行错误
+}
+
+func (e *Error) Error() string {
+	msg := e.Message
+	if msg == "" {
+		msg = e.Err.Error()
+	}
+	return fmt.Sprintf("执行 conda %s 失败: %s", strings.Join(e.Args, " "), msg)
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// exceptionErrors conda 异常类型对应的错误
+var exceptionErrors = map[string]error{
+	"EnvironmentLocationNotFound":        ErrEnvNotFound,
+	"EnvironmentNameNotFound":            ErrEnvNotFound,
+	"DirectoryNotACondaEnvironmentError": ErrEnvNotFound,
+	"PackagesNotFoundError":              ErrPackageNotFound,
+}
+
+// run 执行 conda 命令，返回标准输出，失败时返回 *Error
+func run(args ...string) ([]byte, error) {
+	path, err := exec.LookPath("conda")
+	if err != nil {
+		return nil, ErrNotInstalled
+	}
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.Command(path, args...)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return nil, parseError(args, stdout.Bytes(), stderr.String(), err)
+	}
+
+	return stdout.Bytes(), nil
+}
+
+// parseError 解析 conda 的错误输出（--json 时错误以 JSON 输出到标准输出，否则为 "异常类型: 信息" 格式的文本）
+func parseError(args []string, stdout []byte, stderr string, err error) *Error {
+	e := &Error{Args: args, Err: err}
+
+	var result struct {
+		ExceptionName string `json:"exception_name"`
+		Message       string `json:"message"`
+		Error         string `json:"error"`
+	}
+	if json.Unmarshal(stdout, &result) == nil && (result.ExceptionName != "" || result.Error != "") {
+		e.Exception = result.ExceptionName
+		e.Message = strings.TrimSpace(result.Message)
+		if e.Message == "" {
+			e.Message = strings.TrimSpace(result.Error)
+		}
+	} else {
+		for _, line := range strings.Split(stderr, "\n") {
+			line = strings.TrimSpace(line)
+			name, msg, ok := strings.Cut(line, ": ")
+			if ok && !strings.Contains(name, " ") && (strings.HasSuffix(name, "Error") || exceptionErrors[name] != nil) {
+				e.Exception, e.Message = name, strings.TrimSpace(msg)
+				break
+			}
+		}
+		if e.Message == "" {
+			e.Message = strings.TrimSpace(stderr)
+		}
+	}
+
+	if known, ok := exceptionErrors[e.Exception]; ok {
+		e.Err = known
+	} else if e.Exception == "CondaValueError" && strings.Contains(e.Message, "already exists") {
+		e.Err = ErrEnvExists
+	}
+	return e
+}
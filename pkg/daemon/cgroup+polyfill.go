@@ -0,0 +1,21 @@
+//go:build !linux
+
+package daemon
+
+import (
+	"fmt"
+	"os/exec"
+)
+
+// cgroup 非 Linux 系统不支持 cgroup
+type cgroup struct{}
+
+func newCgroup(string, float64, int64) (*cgroup, error) {
+	return nil, fmt.Errorf("当前系统不支持 cgroup")
+}
+
+func (*cgroup) attach(*exec.Cmd) {}
+
+func (*cgroup) oomKilled() bool { return false }
+
+func (*cgroup) release() {}
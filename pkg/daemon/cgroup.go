@@ -0,0 +1,120 @@
+//go:build linux
+
+package daemon
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
+)
+
+const (
+	cgroupMount  = "/sys/fs/cgroup"
+	cgroupParent = "devtool" // 任务 cgroup 的父目录（/sys/fs/cgroup/devtool）
+	cgroupPeriod = 100000    // cpu.max 的周期（微秒）
+)
+
+// cgroup 单次执行的 cgroup v2
+type cgroup struct {
+	path string
+	dir  *os.File // 启动进程时直接放入 cgroup（clone3 CLONE_INTO_CGROUP）
+}
+
+// newCgroup 创建 cgroup 并设置 CPU、内存上限（需要 cgroup v2 和 root 权限）
+func newCgroup(name string, cpu float64, memory int64) (*cgroup, error) {
+	if _, err := os.Stat(filepath.Join(cgroupMount, "cgroup.controllers")); err != nil {
+		return nil, fmt.Errorf("系统未启用 cgroup v2")
+	}
+
+	parent := filepath.Join(cgroupMount, cgroupParent)
+	if err := os.MkdirAll(parent, 0755); err != nil {
+		return nil, fmt.Errorf("创建 cgroup 失败: %w", err)
+	}
+	// 上级启用 cpu、memory 控制器后，子 cgroup 才能设置限制
+	for _, dir := range []string{cgroupMount, parent} {
+		if err := writeCgroupFile(dir, "cgroup.subtree_control", "+cpu +memory"); err != nil {
+			return nil, fmt.Errorf("启用 cgroup 控制器失败: %w", err)
+		}
+	}
+
+	cg := &cgroup{path: filepath.Join(parent, name)}
+	if err := os.Mkdir(cg.path, 0755); err != nil {
+		return nil, fmt.Errorf("创建 cgroup 失败: %w", err)
+	}
+
+	err := func() error {
+		if cpu > 0 {
+			quota := int64(cpu * cgroupPeriod)
+			if err := writeCgroupFile(cg.path, "cpu.max", fmt.Sprintf("%d %d", quota, cgroupPeriod)); err != nil {
+				return fmt.Errorf("设置 CPU 上限失败: %w", err)
+			}
+		}
+		if memory > 0 {
+			if err := writeCgroupFile(cg.path, "memory.max", fmt.Sprint(memory)); err != nil {
+				return fmt.Errorf("设置内存上限失败: %w", err)
+			}
+			// 禁止使用 swap，超出内存上限时直接 OOM（未启用 swap 时没有该文件）
+			writeCgroupFile(cg.path, "memory.swap.max", "0")
+		}
+
+		dir, err := os.Open(cg.path)
+		if err != nil {
+			return fmt.Errorf("打开 cgroup 失败: %w", err)
+		}
+		cg.dir = dir
+		return nil
+	}()
+	if err != nil {
+		cg.release()
+		return nil, err
+	}
+	return cg, nil
+}
+
+// attach 进程启动时放入 cgroup
+func (cg *cgroup) attach(cmd *exec.Cmd) {
+	if cmd.SysProcAttr == nil {
+		cmd.SysProcAttr = &syscall.SysProcAttr{}
+	}
+	cmd.SysProcAttr.UseCgroupFD = true
+	cmd.SysProcAttr.CgroupFD = int(cg.dir.Fd())
+}
+
+// oomKilled 是否有进程因超出内存上限被终止
+func (cg *cgroup) oomKilled() bool {
+	data, err := os.ReadFile(filepath.Join(cg.path, "memory.events"))
+	if err != nil {
+		return false
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		if fields := strings.Fields(line); len(fields) == 2 && fields[0] == "oom_kill" {
+			return fields[1] != "0"
+		}
+	}
+	return false
+}
+
+// release 终止残留的后台进程并删除 cgroup
+func (cg *cgroup) release() {
+	if cg.dir != nil {
+		cg.dir.Close()
+	}
+	// cgroup.kill 需要内核 5.14+，旧内核上残留进程退出后才能删除
+	writeCgroupFile(cg.path, "cgroup.kill", "1")
+	for i := 0; i < 10; i++ {
+		if err := os.Remove(cg.path); err == nil || os.IsNotExist(err) {
+			return
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+	fmt.Printf("删除 cgroup %s 失败，仍有进程在运行\n", cg.path)
+}
+
+// writeCgroupFile 写入 cgroup 接口文件
+func writeCgroupFile(dir, name, value string) error {
+	return os.WriteFile(filepath.Join(dir, name), []byte(value), 0644)
+}
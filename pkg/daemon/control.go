@@ -0,0 +1,478 @@
+package daemon
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/tedwangl/go-util/pkg/scheduler"
+)
+
+type (
+	// AddTaskRequest 添加任务请求
+	AddTaskRequest struct {
+		Name     string     `json:"name"`
+		Command  string     `json:"command"`
+		Schedule string     `json:"schedule"`
+		RunAt    *time.Time `json:"run_at,omitempty"`
+
+		TaskOptions
+	}
+
+	// Status 守护进程状态
+	Status struct {
+		PID       int                 `json:"pid"`
+		StartedAt time.Time           `json:"started_at"`
+		Node      string              `json:"node,omitempty"` // 节点标识（启用选主时）
+		Leader    bool                `json:"leader"`         // 是否负责调度
+		Jobs      []scheduler.JobInfo `json:"jobs"`           // 调度器中的定时任务
+	}
+
+	// ControlServer 守护进程控制接口（unix socket 上的 HTTP）
+	ControlServer struct {
+		daemon     *Daemon
+		socketPath string
+		listener   net.Listener
+		server     *http.Server
+		shutdown   chan struct{}
+		closeOnce  sync.Once // 并发的关闭请求只关闭一次 shutdown
+	}
+
+	// ControlClient 控制接口客户端（供 CLI 使用）
+	ControlClient struct {
+		http *http.Client
+	}
+
+	// RunEvent 同步执行时的事件（NDJSON），输出之后以 Done 事件结束
+	RunEvent struct {
+		Stream string   `json:"stream,omitempty"` // 输出流：stdout、stderr
+		Data   string   `json:"data,omitempty"`   // 输出内容
+		Done   bool     `json:"done,omitempty"`   // 执行结束
+		Log    *TaskLog `json:"log,omitempty"`    // 最后一次执行的日志（未执行时为空）
+		Error  string   `json:"error,omitempty"`  // 执行失败或未执行的原因
+	}
+
+	// runEventWriter 将输出写为 RunEvent（调用方保证不会并发写入）
+	runEventWriter struct {
+		enc    *json.Encoder
+		rc     *http.ResponseController
+		stream string
+	}
+
+	// errorResponse 错误响应
+	errorResponse struct {
+		Error string `json:"error"`
+	}
+)
+
+// NewControlServer 创建控制接口并监听 socket（socket 已被其他守护进程占用时返回错误）
+func NewControlServer(d *Daemon, socketPath string) (*ControlServer, error) {
+	if c, err := DialControl(socketPath); err == nil {
+		if _, err := c.Status(); err == nil {
+			return nil, fmt.Errorf("守护进程已在运行: %s", socketPath)
+		}
+	}
+
+	// 清理上次异常退出残留的 socket 文件
+	os.Remove(socketPath)
+
+	ln, err := net.Listen("unix", socketPath)
+	if err != nil {
+		return nil, fmt.Errorf("监听控制接口失败: %w", err)
+	}
+	os.Chmod(socketPath, 0600)
+
+	s := &ControlServer{
+		daemon:     d,
+		socketPath: socketPath,
+		listener:   ln,
+		shutdown:   make(chan struct{}),
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /status", s.handleStatus)
+	mux.HandleFunc("GET /tasks", s.handleListTasks)
+	mux.HandleFunc("POST /tasks", s.handleAddTask)
+	mux.HandleFunc("DELETE /tasks/{name}", s.handleRemoveTask)
+	mux.HandleFunc("POST /tasks/{name}/run", s.handleRunTask)
+	mux.HandleFunc("POST /tasks/{name}/enable", s.handleEnableTask)
+	mux.HandleFunc("POST /tasks/{name}/disable", s.handleDisableTask)
+	mux.HandleFunc("POST /reload", s.handleReload)
+	mux.HandleFunc("POST /shutdown", s.handleShutdown)
+	s.server = &http.Server{Handler: mux}
+
+	return s, nil
+}
+
+// Serve 处理请求（阻塞，Close 后返回 nil）
+func (s *ControlServer) Serve() error {
+	if err := s.server.Serve(s.listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+// Done 收到关闭请求时关闭
+func (s *ControlServer) Done() <-chan struct{} {
+	return s.shutdown
+}
+
+// Close 关闭控制接口并删除 socket 文件
+func (s *ControlServer) Close() error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	err := s.server.Shutdown(ctx)
+	os.Remove(s.socketPath)
+	return err
+}
+
+func (s *ControlServer) handleStatus(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, &Status{
+		PID:       os.Getpid(),
+		StartedAt: s.daemon.StartedAt(),
+		Node:      s.daemon.NodeName(),
+		Leader:    s.daemon.IsLeader(),
+		Jobs:      s.daemon.GetScheduler().ListJobs(),
+	})
+}
+
+// handleListTasks 列出任务（敏感环境变量的值已隐藏）
+func (s *ControlServer) handleListTasks(w http.ResponseWriter, r *http.Request) {
+	tasks, err := s.daemon.ListTasks()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	for i := range tasks {
+		tasks[i].RedactSecrets()
+	}
+	writeJSON(w, http.StatusOK, tasks)
+}
+
+// handleAddTask 添加任务并立即加入调度，调度失败（如 cron 表达式无效）时回滚
+func (s *ControlServer) handleAddTask(w http.ResponseWriter, r *http.Request) {
+	var req AddTaskRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("请求格式错误: %w", err))
+		return
+	}
+	if req.Name == "" || req.Command == "" {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("任务名称和命令不能为空"))
+		return
+	}
+	if _, err := s.daemon.GetTask(req.Name); err == nil {
+		writeError(w, http.StatusConflict, fmt.Errorf("任务已存在: %s", req.Name))
+		return
+	}
+
+	if err := s.daemon.AddTaskWithOptions(req.Name, req.Command, req.Schedule, req.RunAt, req.TaskOptions); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	if err := s.daemon.ScheduleTask(req.Name); err != nil {
+		s.daemon.RemoveTask(req.Name)
+		writeError(w, http.StatusBadRequest, fmt.Errorf("注册任务失败: %w", err))
+		return
+	}
+
+	task, err := s.daemon.GetTask(req.Name)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, task)
+}
+
+func (s *ControlServer) handleRemoveTask(w http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("name")
+	if _, err := s.daemon.GetTask(name); err != nil {
+		writeError(w, http.StatusNotFound, err)
+		return
+	}
+
+	s.daemon.UnscheduleTask(name)
+	if err := s.daemon.RemoveTask(name); err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handleRunTask 立即执行任务，?wait=1 时等待结束并以 NDJSON 实时返回输出
+func (s *ControlServer) handleRunTask(w http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("name")
+	if r.URL.Query().Get("wait") == "" {
+		if err := s.daemon.RunNow(name); err != nil {
+			writeError(w, http.StatusNotFound, err)
+			return
+		}
+		w.WriteHeader(http.StatusAccepted)
+		return
+	}
+
+	if _, err := s.daemon.GetTask(name); err != nil {
+		writeError(w, http.StatusNotFound, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	w.WriteHeader(http.StatusOK)
+	enc := json.NewEncoder(w)
+	rc := http.NewResponseController(w)
+	rc.Flush()
+
+	log, err := s.daemon.RunAndWait(name,
+		&runEventWriter{enc: enc, rc: rc, stream: "stdout"},
+		&runEventWriter{enc: enc, rc: rc, stream: "stderr"})
+
+	done := &RunEvent{Done: true, Log: log}
+	switch {
+	case err != nil:
+		done.Error = err.Error()
+	case log == nil:
+		done.Error = "上次执行未结束，本次未执行"
+	}
+	enc.Encode(done)
+}
+
+// Write 写入一个输出事件并立即发送
+func (w *runEventWriter) Write(p []byte) (int, error) {
+	if err := w.enc.Encode(&RunEvent{Stream: w.stream, Data: string(p)}); err != nil {
+		return 0, err
+	}
+	w.rc.Flush()
+	return len(p), nil
+}
+
+// handleEnableTask 启用任务并加入调度
+func (s *ControlServer) handleEnableTask(w http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("name")
+	if _, err := s.daemon.GetTask(name); err != nil {
+		writeError(w, http.StatusNotFound, err)
+		return
+	}
+
+	if err := s.daemon.EnableTask(name); err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	if err := s.daemon.ScheduleTask(name); err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("注册任务失败: %w", err))
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handleDisableTask 禁用任务并移出调度（不影响正在执行的任务）
+func (s *ControlServer) handleDisableTask(w http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("name")
+	if _, err := s.daemon.GetTask(name); err != nil {
+		writeError(w, http.StatusNotFound, err)
+		return
+	}
+
+	if err := s.daemon.DisableTask(name); err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	s.daemon.UnscheduleTask(name)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *ControlServer) handleReload(w http.ResponseWriter, r *http.Request) {
+	if err := s.daemon.Reload(); err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *ControlServer) handleShutdown(w http.ResponseWriter, r *http.Request) {
+	w.WriteHeader(http.StatusAccepted)
+	s.closeOnce.Do(func() {
+		close(s.shutdown)
+	})
+}
+
+// writeJSON 写入 JSON 响应
+func writeJSON(w http.ResponseWriter, code int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(v)
+}
+
+// writeError 写入错误响应
+func writeError(w http.ResponseWriter, code int, err error) {
+	writeJSON(w, code, &errorResponse{Error: err.Error()})
+}
+
+// DialControl 创建控制接口客户端（不会立即连接）
+func DialControl(socketPath string) (*ControlClient, error) {
+	if _, err := os.Stat(socketPath); err != nil {
+		return nil, fmt.Errorf("守护进程未运行: %w", err)
+	}
+
+	var dialer net.Dialer
+	return &ControlClient{
+		http: &http.Client{
+			Timeout: 10 * time.Second,
+			Transport: &http.Transport{
+				DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+					return dialer.DialContext(ctx, "unix", socketPath)
+				},
+			},
+		},
+	}, nil
+}
+
+// Status 获取守护进程状态（可用于检查是否运行）
+func (c *ControlClient) Status() (*Status, error) {
+	var status Status
+	if err := c.do(http.MethodGet, "/status", nil, &status); err != nil {
+		return nil, err
+	}
+	return &status, nil
+}
+
+// ListTasks 列出所有任务
+func (c *ControlClient) ListTasks() ([]Task, error) {
+	var tasks []Task
+	err := c.do(http.MethodGet, "/tasks", nil, &tasks)
+	return tasks, err
+}
+
+// AddTask 添加任务（守护进程立即加入调度）
+func (c *ControlClient) AddTask(req *AddTaskRequest) (*Task, error) {
+	var task Task
+	if err := c.do(http.MethodPost, "/tasks", req, &task); err != nil {
+		return nil, err
+	}
+	return &task, nil
+}
+
+// RemoveTask 删除任务
+func (c *ControlClient) RemoveTask(name string) error {
+	return c.do(http.MethodDelete, "/tasks/"+url.PathEscape(name), nil, nil)
+}
+
+// RunNow 立即执行一次任务（不等待结束）
+func (c *ControlClient) RunNow(name string) error {
+	return c.do(http.MethodPost, "/tasks/"+url.PathEscape(name)+"/run", nil, nil)
+}
+
+// RunAndWait 立即执行一次任务并等待结束，执行期间的输出实时写入 stdout、stderr（为 nil 时丢弃）
+// 返回最后一次执行的日志（未执行时为 nil），执行失败或未执行时返回错误
+func (c *ControlClient) RunAndWait(name string, stdout, stderr io.Writer) (*TaskLog, error) {
+	req, err := http.NewRequest(http.MethodPost, "http://daemon/tasks/"+url.PathEscape(name)+"/run?wait=1", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	// 任务执行时间不确定，不设置超时
+	client := *c.http
+	client.Timeout = 0
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("连接守护进程失败: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		var e errorResponse
+		if err := json.NewDecoder(resp.Body).Decode(&e); err != nil || e.Error == "" {
+			return nil, fmt.Errorf("守护进程返回错误: %s", resp.Status)
+		}
+		return nil, errors.New(e.Error)
+	}
+
+	dec := json.NewDecoder(resp.Body)
+	for {
+		var ev RunEvent
+		if err := dec.Decode(&ev); err != nil {
+			return nil, fmt.Errorf("读取执行结果失败（守护进程可能已停止）: %w", err)
+		}
+
+		if ev.Done {
+			if ev.Error != "" {
+				return ev.Log, errors.New(ev.Error)
+			}
+			return ev.Log, nil
+		}
+		out := stdout
+		if ev.Stream == "stderr" {
+			out = stderr
+		}
+		if out != nil {
+			io.WriteString(out, ev.Data)
+		}
+	}
+}
+
+// EnableTask 启用任务（守护进程立即加入调度）
+func (c *ControlClient) EnableTask(name string) error {
+	return c.do(http.MethodPost, "/tasks/"+url.PathEscape(name)+"/enable", nil, nil)
+}
+
+// DisableTask 禁用任务（守护进程立即移出调度）
+func (c *ControlClient) DisableTask(name string) error {
+	return c.do(http.MethodPost, "/tasks/"+url.PathEscape(name)+"/disable", nil, nil)
+}
+
+// Reload 重新加载所有任务
+func (c *ControlClient) Reload() error {
+	return c.do(http.MethodPost, "/reload", nil, nil)
+}
+
+// Shutdown 停止守护进程
+func (c *ControlClient) Shutdown() error {
+	return c.do(http.MethodPost, "/shutdown", nil, nil)
+}
+
+// do 发送请求，非 2xx 响应返回守护进程给出的错误
+func (c *ControlClient) do(method, path string, body, out any) error {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequest(method, "http://daemon"+path, reader)
+	if err != nil {
+		return err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return fmt.Errorf("连接守护进程失败: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		var e errorResponse
+		if err := json.NewDecoder(resp.Body).Decode(&e); err != nil || e.Error == "" {
+			return fmt.Errorf("守护进程返回错误: %s", resp.Status)
+		}
+		return errors.New(e.Error)
+	}
+
+	if out != nil {
+		return json.NewDecoder(resp.Body).Decode(out)
+	}
+	return nil
+}
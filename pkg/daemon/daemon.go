@@ -1,14 +1,22 @@
 package daemon
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"io"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/tedwangl/go-util/pkg/scheduler"
+	"github.com/tedwangl/go-util/pkg/utils/humanize"
+	"github.com/tedwangl/go-util/pkg/utils/retry"
 	genid "github.com/tedwangl/go-util/pkg/utils/snowflake"
+	"gorm.io/driver/mysql"
 	"gorm.io/driver/sqlite"
 	"gorm.io/gorm"
 	"gorm.io/gorm/logger"
@@ -20,27 +28,68 @@ type (
 
 	// Task 任务（通用）
 	Task struct {
-		ID          int64      `gorm:"primarykey" json:"id"`             // 雪花ID
-		Name        string     `gorm:"uniqueIndex;not null" json:"name"` // 任务名称
-		Command     string     `gorm:"not null" json:"command"`          // 执行命令
-		Schedule    string     `gorm:"default:''" json:"schedule"`       // cron 表达式或特殊标记（@once, @delay:5m）
-		Enabled     bool       `gorm:"default:true" json:"enabled"`      // 是否启用
-		Completed   bool       `gorm:"default:false" json:"completed"`   // 是否已完成（once/delay 任务用）
-		RunAt       *time.Time `json:"run_at,omitempty"`                 // 指定执行时间（用于延迟任务）
-		CompletedAt *time.Time `json:"completed_at,omitempty"`           // 完成时间
+		ID          int64      `gorm:"primarykey" json:"id"`                      // 雪花ID
+		Name        string     `gorm:"uniqueIndex;size:191;not null" json:"name"` // 任务名称
+		Command     string     `gorm:"not null" json:"command"`                   // 执行命令
+		Schedule    string     `gorm:"default:''" json:"schedule"`                // cron 表达式或特殊标记（@once, @delay:5m, @after）
+		Enabled     bool       `gorm:"default:true" json:"enabled"`               // 是否启用
+		Completed   bool       `gorm:"default:false" json:"completed"`            // 是否已完成（once/delay 任务用）
+		RunAt       *time.Time `json:"run_at,omitempty"`                          // 指定执行时间（用于延迟任务）
+		CompletedAt *time.Time `json:"completed_at,omitempty"`                    // 完成时间
 		CreatedAt   time.Time  `json:"created_at"`
 		UpdatedAt   time.Time  `json:"updated_at"`
+
+		TaskOptions `gorm:"embedded"`
+	}
+
+	// TaskOptions 任务执行选项（零值为不超时、不重试、上次未结束时跳过、无依赖）
+	TaskOptions struct {
+		Timeout         time.Duration `gorm:"default:0" json:"timeout,omitempty"`                    // 单次执行超时
+		MaxRetries      int           `gorm:"default:0" json:"max_retries,omitempty"`                // 失败后最大重试次数
+		RetryBackoff    time.Duration `gorm:"default:0" json:"retry_backoff,omitempty"`              // 首次重试等待时间（之后每次翻倍）
+		AllowConcurrent bool          `gorm:"default:false" json:"allow_concurrent,omitempty"`       // 允许与上次执行同时运行
+		Overlap         string        `gorm:"default:'skip'" json:"overlap,omitempty"`               // 上次未结束时：skip 跳过、queue 排队
+		DependsOn       []string      `gorm:"serializer:json;type:text" json:"depends_on,omitempty"` // 依赖的任务（最近一次执行成功后才执行）
+		DependsWindow   time.Duration `gorm:"default:0" json:"depends_window,omitempty"`             // 依赖成功的有效期（默认 24h）
+		Notify          *NotifyConfig `gorm:"serializer:json;type:text" json:"notify,omitempty"`     // 任务通知（与全局通知同时生效）
+
+		Env       map[string]string `gorm:"serializer:json;type:text" json:"env,omitempty"`        // 环境变量（覆盖守护进程的环境变量）
+		SecretEnv map[string]string `gorm:"serializer:json;type:text" json:"secret_env,omitempty"` // 敏感环境变量（接口、列表和执行输出中隐藏）
+		WorkDir   string            `gorm:"default:''" json:"work_dir,omitempty"`                  // 工作目录（默认为执行用户的 HOME）
+		RunAsUser string            `gorm:"default:''" json:"run_as_user,omitempty"`               // 执行用户（用户名或 UID，需要 root 权限）
+
+		CPULimit    float64       `gorm:"default:0" json:"cpu_limit,omitempty"`    // CPU 上限（核数，如 0.5，需要 cgroup v2）
+		MemoryLimit int64         `gorm:"default:0" json:"memory_limit,omitempty"` // 内存上限（字节，cgroup v2，不可用时使用 RLIMIT_AS）
+		CPUTime     time.Duration `gorm:"default:0" json:"cpu_time,omitempty"`     // CPU 时间上限（RLIMIT_CPU，超出后进程被终止）
+
+		Timezone     string   `gorm:"default:''" json:"timezone,omitempty"`                  // cron 表达式的时区（如 Asia/Shanghai，默认本地时区）
+		SkipWeekends bool     `gorm:"default:false" json:"skip_weekends,omitempty"`          // 周末不执行
+		SkipDates    []string `gorm:"serializer:json;type:text" json:"skip_dates,omitempty"` // 不执行的日期（如节假日，格式 2006-01-02）
 	}
 
-	// TaskLog 任务执行日志（只记录状态）
+	// TaskLog 任务执行日志（输出只保留末尾 maxOutputSize 字节）
 	TaskLog struct {
 		ID        int64      `gorm:"primarykey" json:"id"`          // 雪花ID
 		TaskID    int64      `gorm:"index;not null" json:"task_id"` // 任务ID
 		TaskName  string     `gorm:"index" json:"task_name"`        // 任务名称
-		PID       int        `gorm:"default:0" json:"pid"`          // 进程ID（运行中时有效）
+		PID       int        `gorm:"default:0" json:"pid"`          // 进程ID
 		StartTime time.Time  `json:"start_time"`                    // 开始时间
 		EndTime   *time.Time `json:"end_time"`                      // 结束时间
-		Status    string     `json:"status"`                        // success, failed, running, killed
+		Status    string     `json:"status"`                        // success, failed, running, timeout, skipped
+		Attempt   int        `gorm:"default:0" json:"attempt"`      // 重试次数（首次执行为 0）
+		ExitCode  int        `gorm:"default:0" json:"exit_code"`    // 退出码（无法启动时为 -1）
+		Stdout    string     `gorm:"type:text" json:"stdout"`       // 标准输出
+		Stderr    string     `gorm:"type:text" json:"stderr"`       // 标准错误
+		Limits    string     `gorm:"default:''" json:"limits"`      // 生效的资源限制（如 cpu=0.5(cgroup) memory=512MiB(cgroup) timeout=10m0s）
+		Killed    string     `gorm:"default:''" json:"killed"`      // 进程被终止的原因：timeout、oom、cpu_time、signal: <信号>
+	}
+
+	// tailBuffer 只保留最后 max 字节的输出
+	tailBuffer struct {
+		mu        sync.Mutex
+		buf       []byte
+		max       int
+		truncated bool
 	}
 
 	// Daemon 任务守护进程
@@ -49,7 +98,19 @@ type (
 		scheduler *scheduler.Scheduler
 		dbPath    string
 		idGen     *genid.SnowflakeID
-		started   bool // 标记 scheduler 是否已启动
+		started   bool          // 标记 scheduler 是否已启动
+		startedAt time.Time     // 启动时间
+		notifyCfg *NotifyConfig // 全局通知配置
+		leader    *leader       // 多节点选主（未启用时为 nil）
+
+		mu      sync.Mutex
+		active  map[string]bool // 已加入调度的任务（包括等待执行的一次性/延迟任务）
+		running map[string]int  // 正在执行的实例数
+		queued  map[string]bool // 上次未结束时排队的执行（多次排队合并为一次）
+		term    int64           // 调度任期，失去 Leader 时递增，使等待中的一次性任务失效
+
+		followMu  sync.Mutex
+		followers map[string]map[*outputFollower]bool // 实时输出订阅（按任务名）
 	}
 )
 
@@ -57,8 +118,22 @@ const (
 	TaskStatusSuccess = "success"
 	TaskStatusFailed  = "failed"
 	TaskStatusRunning = "running"
+	TaskStatusTimeout = "timeout"
+	TaskStatusSkipped = "skipped"
 )
 
+// 重叠策略：上次执行未结束时的处理方式
+const (
+	OverlapSkip  = "skip"
+	OverlapQueue = "queue"
+)
+
+// maxRetryBackoff 重试等待时间上限
+const maxRetryBackoff = time.Hour
+
+// maxOutputSize 每个输出流保存的最大字节数
+const maxOutputSize = 64 << 10
+
 // NewDaemon 创建守护进程
 func NewDaemon(dbPath string) (*Daemon, error) {
 	// 确保目录存在
@@ -67,8 +142,22 @@ func NewDaemon(dbPath string) (*Daemon, error) {
 		return nil, fmt.Errorf("创建目录失败: %w", err)
 	}
 
-	// 打开数据库
-	db, err := gorm.Open(sqlite.Open(dbPath), &gorm.Config{
+	d, err := newDaemon(sqlite.Open(dbPath))
+	if err != nil {
+		return nil, err
+	}
+	d.dbPath = dbPath
+	return d, nil
+}
+
+// NewMySQLDaemon 创建使用 MySQL 存储任务的守护进程（多节点共享同一个数据库，配合 EnableLeader 使用）
+func NewMySQLDaemon(dsn string) (*Daemon, error) {
+	return newDaemon(mysql.Open(dsn))
+}
+
+// newDaemon 打开数据库并创建守护进程
+func newDaemon(dialector gorm.Dialector) (*Daemon, error) {
+	db, err := gorm.Open(dialector, &gorm.Config{
 		Logger: logger.Default.LogMode(logger.Silent),
 	})
 	if err != nil {
@@ -89,64 +178,155 @@ func NewDaemon(dbPath string) (*Daemon, error) {
 	return &Daemon{
 		DB:        db,
 		scheduler: scheduler.NewScheduler(scheduler.WithSeconds()),
-		dbPath:    dbPath,
 		idGen:     idGen,
+		active:    make(map[string]bool),
+		running:   make(map[string]int),
+		queued:    make(map[string]bool),
+		followers: make(map[string]map[*outputFollower]bool),
 	}, nil
 }
 
 // Start 启动守护进程（只启动有调度的任务）
+// 个别任务注册失败时仍会启动调度器，返回的错误包含失败的任务
+// 启用选主时在后台竞选，成为 Leader 后才加载任务
 func (d *Daemon) Start() error {
-	if err := d.loadTasks(); err != nil {
-		return err
+	var err error
+	if d.leader == nil {
+		err = d.Sync()
 	}
 
 	// 启动调度器
 	d.scheduler.Start()
 	d.started = true
-	return nil
+	d.startedAt = time.Now()
+
+	if d.leader != nil {
+		go d.campaignLoop()
+	} else {
+		go d.checkMissed(d.startedAt)
+	}
+	return err
 }
 
-// loadTasks 加载所有任务到调度器（只加载未完成的调度任务）
-func (d *Daemon) loadTasks() error {
-	// 加载所有启用的、未完成的调度任务
+// Sync 同步数据库中的任务到调度器
+// 新增的任务加入调度（一次性/延迟任务在后台等待执行），已删除、禁用或完成的任务从调度器移除
+// 非 Leader 节点不调度任务
+func (d *Daemon) Sync() error {
+	if !d.IsLeader() {
+		return nil
+	}
+
 	var tasks []Task
 	if err := d.DB.Where("enabled = ? AND completed = ? AND schedule != ''", true, false).Find(&tasks).Error; err != nil {
 		return fmt.Errorf("加载任务失败: %w", err)
 	}
 
-	// 注册任务到调度器
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	dbTasks := make(map[string]bool, len(tasks))
+	var errs []string
 	for i := range tasks {
 		task := &tasks[i]
-		taskID := task.ID // 捕获 ID，避免闭包问题
-		taskName := task.Name
-
-		if err := d.scheduler.AddFunc(task.Schedule, task.Name, func() error {
-			// 每次执行时从数据库加载最新任务配置
-			var currentTask Task
-			if err := d.DB.Where("id = ?", taskID).First(&currentTask).Error; err != nil {
-				fmt.Printf("加载任务 %s 失败: %v\n", taskName, err)
-				return err
-			}
-			d.executeTask(&currentTask)
-			return nil
-		}); err != nil {
-			return fmt.Errorf("注册任务 %s 失败: %w", task.Name, err)
+		dbTasks[task.Name] = true
+		if d.active[task.Name] {
+			continue
+		}
+		if err := d.schedule(task); err != nil {
+			errs = append(errs, fmt.Sprintf("%s: %v", task.Name, err))
 		}
 	}
 
+	for name := range d.active {
+		if !dbTasks[name] {
+			d.unschedule(name)
+		}
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("注册任务失败: %s", strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+// schedule 将任务加入调度（调用方持有 d.mu）
+func (d *Daemon) schedule(task *Task) error {
+	if IsOnceSchedule(task.Schedule) {
+		// 一次性任务或延迟任务：后台等待执行
+		d.active[task.Name] = true
+		go d.executeOnceTask(task, d.term)
+		return nil
+	}
+	if task.Schedule == ScheduleAfter {
+		// 依赖触发的任务不加入调度器
+		d.active[task.Name] = true
+		return nil
+	}
+
+	if err := d.AddJobToScheduler(task); err != nil {
+		return err
+	}
+	d.active[task.Name] = true
+	return nil
+}
+
+// unschedule 从调度器移除任务（调用方持有 d.mu），一次性任务不在调度器中，只清除标记
+func (d *Daemon) unschedule(name string) {
+	d.scheduler.RemoveJob(name)
+	delete(d.active, name)
+}
+
+// ScheduleTask 将数据库中的任务立即加入调度（守护进程内使用）
+func (d *Daemon) ScheduleTask(name string) error {
+	task, err := d.GetTask(name)
+	if err != nil {
+		return err
+	}
+	if !task.Enabled || task.Completed || !d.IsLeader() {
+		return nil
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.active[name] {
+		d.unschedule(name)
+	}
+	return d.schedule(task)
+}
+
+// UnscheduleTask 从调度器移除任务（不影响正在执行的任务）
+func (d *Daemon) UnscheduleTask(name string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.unschedule(name)
+}
+
+// RunNow 立即执行一次任务（不影响定时调度）
+func (d *Daemon) RunNow(name string) error {
+	task, err := d.GetTask(name)
+	if err != nil {
+		return err
+	}
+	go d.executeTask(task)
 	return nil
 }
 
-// Reload 重新加载任务（删除所有旧任务，重新加载）
+// Reload 重新加载任务（删除所有定时任务，重新从数据库加载）
 func (d *Daemon) Reload() error {
-	// 获取当前所有任务
-	jobs := d.scheduler.ListJobs()
-	for _, job := range jobs {
+	d.mu.Lock()
+	for _, job := range d.scheduler.ListJobs() {
 		d.scheduler.RemoveJob(job.Name)
+		delete(d.active, job.Name)
 	}
+	d.mu.Unlock()
+
+	return d.Sync()
+}
 
-	// 重新加载
-	return d.loadTasks()
+// IsOnceSchedule 是否为一次性/延迟任务（@once、@delay:5m）
+func IsOnceSchedule(schedule string) bool {
+	return schedule == "@once" || strings.HasPrefix(schedule, "@delay:")
 }
 
 // RemoveJobFromScheduler 从调度器中移除任务（不影响正在执行的任务）
@@ -169,21 +349,149 @@ func (d *Daemon) AddJobToScheduler(task *Task) error {
 			fmt.Printf("加载任务 %s 失败: %v\n", t.Name, err)
 			return err
 		}
-		d.executeTask(&currentTask)
-		return nil
-	})
+		return d.executeTask(&currentTask)
+	}, t.jobOptions()...)
+}
+
+// jobOptions 时区和日历排除对应的调度选项
+func (o *TaskOptions) jobOptions() []scheduler.JobOption {
+	var opts []scheduler.JobOption
+	if o.Timezone != "" {
+		opts = append(opts, scheduler.InTimezone(o.Timezone))
+	}
+	if o.SkipWeekends {
+		opts = append(opts, scheduler.SkipWeekends())
+	}
+	if len(o.SkipDates) > 0 {
+		opts = append(opts, scheduler.SkipDates(o.SkipDates...))
+	}
+	return opts
 }
 
 // Stop 停止守护进程
 func (d *Daemon) Stop() {
 	if d.started {
+		if d.leader != nil {
+			d.resign()
+		}
 		d.scheduler.Stop()
 		d.started = false
 	}
 }
 
-// executeTask 执行任务（只记录状态）
-func (d *Daemon) executeTask(task *Task) {
+// executeTask 依赖满足时按重叠策略执行任务，成功后触发下游任务，结束后执行排队的下一次
+// 返回最后一次执行的错误（依赖不满足时返回跳过原因）
+func (d *Daemon) executeTask(task *Task) error {
+	if ok, reason := d.dependenciesMet(task); !ok {
+		d.skip(task, reason)
+		return fmt.Errorf("跳过执行: %s", reason)
+	}
+	if !d.acquire(task) {
+		return nil
+	}
+
+	for {
+		err := d.runWithRetry(task)
+		if err == nil {
+			d.triggerDependents(task.Name)
+		}
+		if !d.release(task.Name) {
+			return err
+		}
+
+		// 排队的执行使用最新配置
+		current, loadErr := d.GetTaskByID(task.ID)
+		if loadErr != nil {
+			for d.release(task.Name) {
+			}
+			return loadErr
+		}
+		task = current
+	}
+}
+
+// acquire 占用执行名额，上次未结束且不允许并发时按 Overlap 跳过或排队
+func (d *Daemon) acquire(task *Task) bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.running[task.Name] > 0 && !task.AllowConcurrent {
+		if task.Overlap == OverlapQueue {
+			d.queued[task.Name] = true
+			fmt.Printf("任务 %s 上次执行未结束，排队等待\n", task.Name)
+		} else {
+			fmt.Printf("任务 %s 上次执行未结束，跳过本次执行\n", task.Name)
+			ev := newEvent(EventMissed, task, nil)
+			ev.Reason = "上次执行未结束"
+			d.notify(task, ev)
+		}
+		return false
+	}
+
+	d.running[task.Name]++
+	return true
+}
+
+// release 释放执行名额，有排队的执行时保留名额并返回 true
+func (d *Daemon) release(name string) bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.queued[name] {
+		delete(d.queued, name)
+		return true
+	}
+
+	if d.running[name]--; d.running[name] <= 0 {
+		delete(d.running, name)
+	}
+	return false
+}
+
+// runWithRetry 执行任务，失败时按 RetryBackoff 指数退避重试（不超过 maxRetryBackoff，每次尝试单独记录日志），返回最后一次的错误
+// 重试耗尽时发送 failure 通知，上次失败本次成功时发送 recovery 通知
+func (d *Daemon) runWithRetry(task *Task) error {
+	var prev TaskLog
+	d.DB.Where("task_name = ? AND status IN ?", task.Name,
+		[]string{TaskStatusSuccess, TaskStatusFailed, TaskStatusTimeout}).
+		Order("start_time DESC").First(&prev)
+
+	var log *TaskLog
+	var lastErr error
+	canceled := false
+	err := retry.Do(context.Background(), func(_ context.Context, attempt int) error {
+		// 等待期间任务可能已被删除或禁用
+		if attempt > 0 {
+			if current, loadErr := d.GetTaskByID(task.ID); loadErr != nil || !current.Enabled {
+				fmt.Printf("任务 %s 已删除或禁用，取消重试\n", task.Name)
+				canceled = true
+				return retry.Unrecoverable(lastErr)
+			}
+		}
+
+		log, lastErr = d.runOnce(task, attempt)
+		return lastErr
+	},
+		retry.Attempts(task.MaxRetries+1),
+		retry.ExponentialBackoff(task.RetryBackoff, maxRetryBackoff),
+		retry.OnRetry(func(attempt int, err error, wait time.Duration) {
+			fmt.Printf("任务 %s 执行失败，%s 后第 %d 次重试\n", task.Name, wait, attempt+1)
+		}),
+	)
+
+	switch {
+	case err == nil:
+		if prev.Status == TaskStatusFailed || prev.Status == TaskStatusTimeout {
+			d.notify(task, newEvent(EventRecovery, task, log))
+		}
+	case !canceled:
+		d.notify(task, newEvent(EventFailure, task, log))
+	}
+	return err
+}
+
+// runOnce 执行一次命令并记录日志（超时后终止进程），记录生效的资源限制和进程被终止的原因
+func (d *Daemon) runOnce(task *Task, attempt int) (*TaskLog, error) {
 	// 创建执行日志
 	log := &TaskLog{
 		ID:        d.idGen.NextID(),
@@ -191,32 +499,173 @@ func (d *Daemon) executeTask(task *Task) {
 		TaskName:  task.Name,
 		StartTime: time.Now(),
 		Status:    TaskStatusRunning,
+		Attempt:   attempt,
 	}
 	d.DB.Create(log)
 
-	// 执行命令
-	cmd := exec.Command("sh", "-c", task.Command)
-	err := cmd.Run()
+	ctx := context.Background()
+	if task.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, task.Timeout)
+		defer cancel()
+	}
+
+	// 执行命令，捕获输出
+	stdout := &tailBuffer{max: maxOutputSize}
+	stderr := &tailBuffer{max: maxOutputSize}
+	cmd := exec.CommandContext(ctx, "sh", "-c", task.Command)
+	cmd.Stdout = io.MultiWriter(stdout, &followWriter{d: d, task: task})
+	cmd.Stderr = io.MultiWriter(stderr, &followWriter{d: d, task: task, stderr: true})
+	cmd.WaitDelay = 5 * time.Second // 子进程继承输出管道时，超时后不无限等待，进程组在此之后被强制终止
+
+	limits := &taskLimits{}
+	err := task.setupCommand(cmd)
+	if err == nil {
+		setProcessGroup(cmd)
+		limits = task.applyLimits(cmd, fmt.Sprintf("task-%d", log.ID))
+		defer limits.release()
+		log.Limits = limits.String()
+		err = cmd.Start()
+	}
+	if err == nil {
+		log.PID = cmd.Process.Pid
+		d.DB.Save(log)
+		err = cmd.Wait()
+	}
 
 	// 更新日志状态
 	now := time.Now()
 	log.EndTime = &now
+	log.Stdout = task.MaskSecrets(stdout.String())
+	log.Stderr = task.MaskSecrets(stderr.String())
+	log.ExitCode = 0
 	if err != nil {
 		log.Status = TaskStatusFailed
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			log.ExitCode = exitErr.ExitCode()
+		} else {
+			log.ExitCode = -1
+			log.Stderr += err.Error()
+		}
+		switch {
+		case errors.Is(ctx.Err(), context.DeadlineExceeded):
+			log.Status = TaskStatusTimeout
+			log.Killed = KilledTimeout
+			err = fmt.Errorf("执行超时（%s）", task.Timeout)
+		case limits.oomKilled():
+			log.Killed = KilledOOM
+			err = fmt.Errorf("超出内存限制（%s），进程被终止", humanize.Bytes(uint64(task.MemoryLimit)))
+		case cmd.ProcessState != nil:
+			log.Killed = killSignal(cmd.ProcessState)
+			if log.Killed == KilledCPUTime {
+				err = fmt.Errorf("超出 CPU 时间限制（%s），进程被终止", task.CPUTime)
+			}
+		}
 	} else {
 		log.Status = TaskStatusSuccess
 	}
 
 	d.DB.Save(log)
+	return log, err
+}
+
+// setupCommand 设置执行用户、工作目录和环境变量
+func (o *TaskOptions) setupCommand(cmd *exec.Cmd) error {
+	env := os.Environ()
+	cmd.Dir = o.WorkDir
+
+	if o.RunAsUser != "" {
+		u, err := runAs(cmd, o.RunAsUser)
+		if err != nil {
+			return err
+		}
+		env = append(env, "HOME="+u.HomeDir, "USER="+u.Username, "LOGNAME="+u.Username)
+		if cmd.Dir == "" {
+			cmd.Dir = u.HomeDir
+		}
+	} else if cmd.Dir == "" {
+		cmd.Dir, _ = os.UserHomeDir()
+	}
+
+	// 后出现的同名变量生效
+	for k, v := range o.Env {
+		env = append(env, k+"="+v)
+	}
+	for k, v := range o.SecretEnv {
+		env = append(env, k+"="+v)
+	}
+	cmd.Env = env
+	return nil
+}
+
+// RedactSecrets 将敏感环境变量的值替换为 ******（通过接口返回任务前调用）
+func (o *TaskOptions) RedactSecrets() {
+	for k := range o.SecretEnv {
+		o.SecretEnv[k] = "******"
+	}
+}
+
+// MaskSecrets 将文本中的敏感环境变量值替换为 ******
+func (o *TaskOptions) MaskSecrets(s string) string {
+	for _, v := range o.SecretEnv {
+		if v != "" {
+			s = strings.ReplaceAll(s, v, "******")
+		}
+	}
+	return s
+}
+
+// Write 写入输出，超出上限时丢弃最早的部分
+func (b *tailBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	n := len(p)
+	if n >= b.max {
+		b.buf = append(b.buf[:0], p[n-b.max:]...)
+		b.truncated = true
+		return n, nil
+	}
+	if over := len(b.buf) + n - b.max; over > 0 {
+		b.buf = append(b.buf[:0], b.buf[over:]...)
+		b.truncated = true
+	}
+	b.buf = append(b.buf, p...)
+	return n, nil
+}
+
+// String 返回保留的输出（被截断时带前缀提示）
+func (b *tailBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.truncated {
+		return "...(已截断)\n" + string(b.buf)
+	}
+	return string(b.buf)
 }
 
 // ExecuteOnceTask 执行一次性/延迟任务（公开方法，供外部调用）
 func (d *Daemon) ExecuteOnceTask(task *Task) {
-	d.executeOnceTask(task)
+	d.mu.Lock()
+	term := d.term
+	d.mu.Unlock()
+	d.executeOnceTask(task, term)
 }
 
 // executeOnceTask 执行一次性/延迟任务（执行后标记为完成）
-func (d *Daemon) executeOnceTask(task *Task) {
+// term 为加入调度时的任期，等待期间失去 Leader 身份则不执行
+func (d *Daemon) executeOnceTask(task *Task, term int64) {
+	// 结束后移出调度（在标记完成之后，避免同步时重复执行），任期已变化时由新任期管理
+	defer func() {
+		d.mu.Lock()
+		if d.term == term {
+			delete(d.active, task.Name)
+		}
+		d.mu.Unlock()
+	}()
+
 	// 如果是延迟任务，等待到指定时间
 	if task.RunAt != nil {
 		waitDuration := time.Until(*task.RunAt)
@@ -224,6 +673,21 @@ func (d *Daemon) executeOnceTask(task *Task) {
 			fmt.Printf("任务 %s 将在 %s 后执行\n", task.Name, waitDuration.Round(time.Second))
 			time.Sleep(waitDuration)
 		}
+
+		// 等待期间任务可能已被删除或禁用
+		current, err := d.GetTaskByID(task.ID)
+		if err != nil || !current.Enabled {
+			fmt.Printf("任务 %s 已删除或禁用，取消执行\n", task.Name)
+			return
+		}
+	}
+
+	d.mu.Lock()
+	expired := d.term != term
+	d.mu.Unlock()
+	if expired {
+		fmt.Printf("任务 %s 的调度已失效（不再是 Leader），取消执行\n", task.Name)
+		return
 	}
 
 	fmt.Printf("开始执行一次性任务: %s\n", task.Name)
@@ -251,9 +715,20 @@ func (d *Daemon) AddTask(name, command, schedule string) error {
 
 // AddTaskWithRunAt 添加任务（支持指定执行时间）
 func (d *Daemon) AddTaskWithRunAt(name, command, schedule string, runAt *time.Time) error {
+	return d.AddTaskWithOptions(name, command, schedule, runAt, TaskOptions{})
+}
+
+// AddTaskWithOptions 添加任务（支持指定执行时间和执行选项）
+func (d *Daemon) AddTaskWithOptions(name, command, schedule string, runAt *time.Time, opts TaskOptions) error {
 	if schedule == "" {
 		return fmt.Errorf("调度表达式不能为空")
 	}
+	if err := opts.Validate(); err != nil {
+		return err
+	}
+	if schedule == ScheduleAfter && len(opts.DependsOn) == 0 {
+		return fmt.Errorf("%s 任务必须指定依赖", ScheduleAfter)
+	}
 
 	task := &Task{
 		ID:       d.idGen.NextID(),
@@ -262,10 +737,66 @@ func (d *Daemon) AddTaskWithRunAt(name, command, schedule string, runAt *time.Ti
 		Schedule: schedule,
 		Enabled:  true,
 		RunAt:    runAt,
+
+		TaskOptions: opts,
+	}
+	if err := d.checkDependencies(task); err != nil {
+		return err
 	}
 	return d.DB.Create(task).Error
 }
 
+// Validate 检查执行选项，Overlap 为空时使用 skip
+func (o *TaskOptions) Validate() error {
+	if o.Timeout < 0 || o.MaxRetries < 0 || o.RetryBackoff < 0 || o.DependsWindow < 0 {
+		return fmt.Errorf("超时、重试次数、重试间隔和依赖有效期不能为负数")
+	}
+	if o.CPULimit < 0 || o.MemoryLimit < 0 || o.CPUTime < 0 {
+		return fmt.Errorf("CPU、内存和 CPU 时间限制不能为负数")
+	}
+	if o.MemoryLimit > 0 && o.MemoryLimit < 1<<20 {
+		return fmt.Errorf("内存限制不能小于 1MiB")
+	}
+	switch o.Overlap {
+	case "":
+		o.Overlap = OverlapSkip
+	case OverlapSkip, OverlapQueue:
+	default:
+		return fmt.Errorf("无效的重叠策略: %s（可选: skip, queue）", o.Overlap)
+	}
+	for k := range o.Env {
+		if k == "" || strings.ContainsAny(k, "= ") {
+			return fmt.Errorf("无效的环境变量名: %q", k)
+		}
+	}
+	for k := range o.SecretEnv {
+		if k == "" || strings.ContainsAny(k, "= ") {
+			return fmt.Errorf("无效的环境变量名: %q", k)
+		}
+	}
+	if o.Timezone != "" {
+		if _, err := time.LoadLocation(o.Timezone); err != nil {
+			return fmt.Errorf("无效的时区: %s", o.Timezone)
+		}
+	}
+	for _, date := range o.SkipDates {
+		if _, err := time.Parse(time.DateOnly, date); err != nil {
+			return fmt.Errorf("无效的日期: %s（格式: 2006-01-02）", date)
+		}
+	}
+	if o.WorkDir != "" && !filepath.IsAbs(o.WorkDir) {
+		return fmt.Errorf("工作目录必须是绝对路径: %s", o.WorkDir)
+	}
+	if o.Notify != nil {
+		for _, event := range o.Notify.On {
+			if event != EventFailure && event != EventRecovery && event != EventMissed {
+				return fmt.Errorf("无效的通知事件: %s（可选: failure, recovery, missed）", event)
+			}
+		}
+	}
+	return nil
+}
+
 // RemoveTask 删除任务
 func (d *Daemon) RemoveTask(name string) error {
 	return d.DB.Where("name = ?", name).Delete(&Task{}).Error
@@ -333,7 +864,12 @@ func (d *Daemon) Close() error {
 	return sqlDB.Close()
 }
 
-// GetScheduler 获取调度器（用于信号处理）
+// StartedAt 启动时间（未启动时为零值）
+func (d *Daemon) StartedAt() time.Time {
+	return d.startedAt
+}
+
+// GetScheduler 获取调度器
 func (d *Daemon) GetScheduler() *scheduler.Scheduler {
 	return d.scheduler
 }
@@ -0,0 +1,191 @@
+package daemon
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+// ScheduleAfter 依赖触发：不单独调度，依赖任务成功后执行
+const ScheduleAfter = "@after"
+
+// defaultDependsWindow 依赖任务最近一次成功的有效期（DependsWindow 为 0 时使用）
+const defaultDependsWindow = 24 * time.Hour
+
+// TaskNode 依赖树节点（子节点为依赖该任务的任务）
+type TaskNode struct {
+	Task     *Task
+	Children []*TaskNode
+}
+
+// dependenciesMet 检查依赖任务最近一次执行是否在有效期内成功，不满足时返回原因
+func (d *Daemon) dependenciesMet(task *Task) (bool, string) {
+	window := task.DependsWindow
+	if window <= 0 {
+		window = defaultDependsWindow
+	}
+
+	for _, dep := range task.DependsOn {
+		var last TaskLog
+		err := d.DB.Where("task_name = ? AND status != ?", dep, TaskStatusRunning).
+			Order("start_time DESC").First(&last).Error
+		if err != nil {
+			return false, fmt.Sprintf("依赖 %s 没有执行记录", dep)
+		}
+		if last.Status != TaskStatusSuccess {
+			return false, fmt.Sprintf("依赖 %s 最近一次执行未成功（%s）", dep, last.Status)
+		}
+		if last.EndTime == nil || time.Since(*last.EndTime) > window {
+			return false, fmt.Sprintf("依赖 %s 最近一次成功已超过 %s", dep, window)
+		}
+	}
+	return true, ""
+}
+
+// skip 依赖不满足时记录跳过日志并发送 missed 通知
+func (d *Daemon) skip(task *Task, reason string) {
+	fmt.Printf("任务 %s 跳过执行: %s\n", task.Name, reason)
+
+	now := time.Now()
+	d.DB.Create(&TaskLog{
+		ID:        d.idGen.NextID(),
+		TaskID:    task.ID,
+		TaskName:  task.Name,
+		StartTime: now,
+		EndTime:   &now,
+		Status:    TaskStatusSkipped,
+		Stderr:    reason,
+	})
+
+	ev := newEvent(EventMissed, task, nil)
+	ev.Reason = reason
+	d.notify(task, ev)
+}
+
+// triggerDependents 任务成功后执行依赖它的 @after 任务（其余依赖仍需满足）
+func (d *Daemon) triggerDependents(name string) {
+	var tasks []Task
+	if err := d.DB.Where("schedule = ? AND enabled = ?", ScheduleAfter, true).Find(&tasks).Error; err != nil {
+		fmt.Printf("加载依赖任务失败: %v\n", err)
+		return
+	}
+
+	for i := range tasks {
+		if slices.Contains(tasks[i].DependsOn, name) {
+			go d.executeTask(&tasks[i])
+		}
+	}
+}
+
+// checkDependencies 检查加入新任务后依赖关系是否有环
+func (d *Daemon) checkDependencies(task *Task) error {
+	if slices.Contains(task.DependsOn, task.Name) {
+		return fmt.Errorf("任务不能依赖自身: %s", task.Name)
+	}
+	if len(task.DependsOn) == 0 {
+		return nil
+	}
+
+	tasks, err := d.ListTasks()
+	if err != nil {
+		return err
+	}
+	return checkCycle(append(tasks, *task))
+}
+
+// checkCycle 检测依赖环，返回环上的任务路径
+func checkCycle(tasks []Task) error {
+	deps := make(map[string][]string, len(tasks))
+	for _, t := range tasks {
+		deps[t.Name] = t.DependsOn
+	}
+
+	const (
+		visiting = 1
+		done     = 2
+	)
+	state := make(map[string]int, len(tasks))
+	var path []string
+
+	var visit func(name string) error
+	visit = func(name string) error {
+		switch state[name] {
+		case visiting:
+			start := slices.Index(path, name)
+			return fmt.Errorf("依赖存在环: %s", strings.Join(append(path[start:], name), " -> "))
+		case done:
+			return nil
+		}
+
+		state[name] = visiting
+		path = append(path, name)
+		for _, dep := range deps[name] {
+			if err := visit(dep); err != nil {
+				return err
+			}
+		}
+		path = path[:len(path)-1]
+		state[name] = done
+		return nil
+	}
+
+	for _, t := range tasks {
+		if err := visit(t.Name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// DependencyTree 构建依赖树：根节点为没有依赖（或依赖不存在）的任务
+// 依赖多个任务的任务会出现在每个依赖之下，环上的任务只展开一次
+func DependencyTree(tasks []Task) []*TaskNode {
+	byName := make(map[string]*Task, len(tasks))
+	for i := range tasks {
+		byName[tasks[i].Name] = &tasks[i]
+	}
+
+	dependents := make(map[string][]*Task)
+	var roots []*Task
+	for i := range tasks {
+		t := &tasks[i]
+		isRoot := true
+		for _, dep := range t.DependsOn {
+			if _, ok := byName[dep]; ok {
+				dependents[dep] = append(dependents[dep], t)
+				isRoot = false
+			}
+		}
+		if isRoot {
+			roots = append(roots, t)
+		}
+	}
+
+	visited := make(map[string]bool)
+	var build func(t *Task, path map[string]bool) *TaskNode
+	build = func(t *Task, path map[string]bool) *TaskNode {
+		visited[t.Name] = true
+		node := &TaskNode{Task: t}
+		path[t.Name] = true
+		for _, child := range dependents[t.Name] {
+			if !path[child.Name] {
+				node.Children = append(node.Children, build(child, path))
+			}
+		}
+		delete(path, t.Name)
+		return node
+	}
+
+	var nodes []*TaskNode
+	for _, t := range roots {
+		nodes = append(nodes, build(t, make(map[string]bool)))
+	}
+	// 只在环中、没有根的任务
+	for i := range tasks {
+		if !visited[tasks[i].Name] {
+			nodes = append(nodes, build(&tasks[i], make(map[string]bool)))
+		}
+	}
+	return nodes
+}
@@ -0,0 +1,107 @@
+package daemon
+
+import (
+	"io"
+	"sync"
+	"time"
+)
+
+// outputFollower 执行期间的实时输出订阅
+type outputFollower struct {
+	stdout io.Writer
+	stderr io.Writer
+
+	mu     sync.Mutex // 串行化写入（stdout、stderr 可能共用同一个底层输出）
+	closed bool       // 已取消订阅，不再写入
+}
+
+// followWriter 写入订阅者的输出流（写入时按任务名查找订阅者，敏感环境变量已隐藏）
+type followWriter struct {
+	d      *Daemon
+	task   *Task
+	stderr bool
+}
+
+// RunAndWait 立即执行任务并等待结束（不影响定时调度，按重叠策略处理上次未结束的执行）
+// 执行期间的输出（包括重试）实时写入 stdout、stderr，返回最后一次执行的日志，未执行时返回 nil
+func (d *Daemon) RunAndWait(name string, stdout, stderr io.Writer) (*TaskLog, error) {
+	task, err := d.GetTask(name)
+	if err != nil {
+		return nil, err
+	}
+
+	f := &outputFollower{stdout: stdout, stderr: stderr}
+	d.follow(name, f)
+	defer d.unfollow(name, f)
+
+	start := time.Now()
+	err = d.executeTask(task)
+
+	var log TaskLog
+	if d.DB.Where("task_name = ? AND start_time >= ?", name, start).Order("start_time DESC").Limit(1).Find(&log).Error != nil || log.ID == 0 {
+		return nil, err
+	}
+	return &log, err
+}
+
+// follow 订阅任务的输出
+func (d *Daemon) follow(name string, f *outputFollower) {
+	d.followMu.Lock()
+	defer d.followMu.Unlock()
+
+	if d.followers[name] == nil {
+		d.followers[name] = make(map[*outputFollower]bool)
+	}
+	d.followers[name][f] = true
+}
+
+// unfollow 取消订阅，返回后不会再写入该订阅者
+func (d *Daemon) unfollow(name string, f *outputFollower) {
+	d.followMu.Lock()
+	delete(d.followers[name], f)
+	if len(d.followers[name]) == 0 {
+		delete(d.followers, name)
+	}
+	d.followMu.Unlock()
+
+	// 等待正在进行的写入结束
+	f.mu.Lock()
+	f.closed = true
+	f.mu.Unlock()
+}
+
+// write 写入订阅者，已取消订阅时忽略
+func (f *outputFollower) write(p []byte, stderr bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	out := f.stdout
+	if stderr {
+		out = f.stderr
+	}
+	if !f.closed && out != nil {
+		out.Write(p)
+	}
+}
+
+// Write 写入所有订阅者（订阅者写入失败不影响任务执行）
+// 在锁外写入，慢速订阅者不会阻塞其他任务的输出和订阅；
+// 敏感值被拆分到两次写入时无法隐藏，TaskLog 中的输出不受影响
+func (w *followWriter) Write(p []byte) (int, error) {
+	w.d.followMu.Lock()
+	followers := make([]*outputFollower, 0, len(w.d.followers[w.task.Name]))
+	for f := range w.d.followers[w.task.Name] {
+		followers = append(followers, f)
+	}
+	w.d.followMu.Unlock()
+
+	if len(followers) == 0 {
+		return len(p), nil
+	}
+
+	data := []byte(w.task.MaskSecrets(string(p)))
+	for _, f := range followers {
+		f.write(data, w.stderr)
+	}
+	return len(p), nil
+}
@@ -0,0 +1,201 @@
+package daemon
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync/atomic"
+	"time"
+
+	"github.com/tedwangl/go-util/pkg/redisx/client"
+	redisxconfig "github.com/tedwangl/go-util/pkg/redisx/config"
+	genid "github.com/tedwangl/go-util/pkg/utils/snowflake"
+)
+
+const (
+	defaultLeaderKey = "devtool:schedule:leader"
+	defaultLeaderTTL = 15 * time.Second
+)
+
+// 续期和释放时校验锁仍属于当前节点
+const (
+	renewScript = `
+	if redis.call("get", KEYS[1]) == ARGV[1] then
+		return redis.call("pexpire", KEYS[1], ARGV[2])
+	else
+		return 0
+	end
+	`
+	releaseScript = `
+	if redis.call("get", KEYS[1]) == ARGV[1] then
+		return redis.call("del", KEYS[1])
+	else
+		return 0
+	end
+	`
+)
+
+type (
+	// LeaderConfig 多节点选主配置：各节点共享任务数据库，只有持有 Redis 锁的 Leader 触发调度
+	LeaderConfig struct {
+		Redis  *redisxconfig.Config
+		Key    string        // 锁的键（默认 devtool:schedule:leader）
+		TTL    time.Duration // 锁过期时间，Leader 异常退出后最多 TTL 内由其他节点接管（默认 15s）
+		NodeID int64         // 节点ID（1-1023，各节点必须不同，用于生成不冲突的任务/日志ID）
+	}
+
+	// leader 基于 Redis 锁的选主
+	leader struct {
+		client   client.Client
+		key      string
+		value    string // 当前节点标识（锁的值）
+		ttl      time.Duration
+		isLeader atomic.Bool
+		stop     chan struct{}
+		done     chan struct{}
+	}
+)
+
+// EnableLeader 启用多节点选主（在 Start 之前调用）
+// 未成为 Leader 时不调度任何任务，控制接口仍可添加/删除任务和手动执行
+func (d *Daemon) EnableLeader(cfg *LeaderConfig) error {
+	if d.started {
+		return fmt.Errorf("守护进程已启动")
+	}
+	if cfg.NodeID <= 0 || cfg.NodeID > 1023 {
+		return fmt.Errorf("无效的节点ID: %d（1-1023）", cfg.NodeID)
+	}
+
+	rdb, err := client.NewClient(cfg.Redis)
+	if err != nil {
+		return fmt.Errorf("连接 Redis 失败: %w", err)
+	}
+
+	idGen, err := genid.NewSnowflakeID(cfg.NodeID)
+	if err != nil {
+		rdb.Close()
+		return fmt.Errorf("创建ID生成器失败: %w", err)
+	}
+
+	host, _ := os.Hostname()
+	l := &leader{
+		client: rdb,
+		key:    cfg.Key,
+		value:  fmt.Sprintf("%s:%d:%d", host, os.Getpid(), cfg.NodeID),
+		ttl:    cfg.TTL,
+		stop:   make(chan struct{}),
+		done:   make(chan struct{}),
+	}
+	if l.key == "" {
+		l.key = defaultLeaderKey
+	}
+	if l.ttl <= 0 {
+		l.ttl = defaultLeaderTTL
+	}
+
+	d.idGen = idGen
+	d.leader = l
+	return nil
+}
+
+// IsLeader 当前节点是否负责调度（未启用选主时始终为 true）
+func (d *Daemon) IsLeader() bool {
+	return d.leader == nil || d.leader.isLeader.Load()
+}
+
+// NodeName 当前节点标识（未启用选主时为空）
+func (d *Daemon) NodeName() string {
+	if d.leader == nil {
+		return ""
+	}
+	return d.leader.value
+}
+
+// campaignLoop 定期竞选或续期，Leader 每次续期后同步其他节点写入的任务
+func (d *Daemon) campaignLoop() {
+	l := d.leader
+	defer close(l.done)
+
+	ticker := time.NewTicker(l.ttl / 3)
+	defer ticker.Stop()
+
+	for {
+		d.campaign()
+
+		select {
+		case <-l.stop:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// campaign 竞选或续期一次，续期失败（包括 Redis 不可用）时立即放弃调度
+func (d *Daemon) campaign() {
+	l := d.leader
+	ctx, cancel := context.WithTimeout(context.Background(), l.ttl/3)
+	defer cancel()
+
+	if l.isLeader.Load() {
+		n, err := l.client.Eval(ctx, renewScript, []string{l.key}, l.value, l.ttl.Milliseconds()).Int()
+		if err != nil || n == 0 {
+			fmt.Printf("节点 %s 失去 Leader 身份: %v\n", l.value, err)
+			d.stepDown()
+			return
+		}
+		if err := d.Sync(); err != nil {
+			fmt.Printf("同步任务失败: %v\n", err)
+		}
+		return
+	}
+
+	ok, err := l.client.SetNX(ctx, l.key, l.value, l.ttl).Result()
+	if err != nil || !ok {
+		return
+	}
+
+	fmt.Printf("节点 %s 成为 Leader\n", l.value)
+	d.stepUp()
+}
+
+// stepUp 成为 Leader：加载任务并补报停机期间错过的执行
+func (d *Daemon) stepUp() {
+	d.mu.Lock()
+	d.term++
+	d.mu.Unlock()
+
+	since := time.Now()
+	d.leader.isLeader.Store(true)
+	if err := d.Sync(); err != nil {
+		fmt.Printf("部分任务注册失败: %v\n", err)
+	}
+	go d.checkMissed(since)
+}
+
+// stepDown 放弃 Leader：移除所有调度（正在执行的任务继续完成，等待中的一次性任务不再执行）
+func (d *Daemon) stepDown() {
+	d.leader.isLeader.Store(false)
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.term++
+	for name := range d.active {
+		d.unschedule(name)
+	}
+}
+
+// resign 停止选主并释放锁，其他节点可以立即接管
+func (d *Daemon) resign() {
+	l := d.leader
+	close(l.stop)
+	<-l.done
+
+	if l.isLeader.Load() {
+		d.stepDown()
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		l.client.Eval(ctx, releaseScript, []string{l.key}, l.value)
+		cancel()
+	}
+	l.client.Close()
+}
@@ -0,0 +1,90 @@
+package daemon
+
+import (
+	"fmt"
+	"math"
+	"os/exec"
+	"strings"
+
+	"github.com/tedwangl/go-util/pkg/utils/humanize"
+)
+
+// 进程被终止的原因（TaskLog.Killed）
+const (
+	KilledTimeout = "timeout"  // 执行超时
+	KilledOOM     = "oom"      // 超出内存限制（cgroup）
+	KilledCPUTime = "cpu_time" // 超出 CPU 时间限制（RLIMIT_CPU）
+)
+
+// taskLimits 一次执行生效的资源限制
+type taskLimits struct {
+	applied []string // 生效的限制（记录到 TaskLog.Limits）
+	cgroup  *cgroup  // 任务的 cgroup（未限制 CPU、内存或 cgroup 不可用时为 nil）
+}
+
+// applyLimits 设置资源限制，name 为 cgroup 名称（每次执行唯一）
+//
+// CPU 和内存优先使用 cgroup v2（Linux，需要 root 权限），不可用时内存改用 RLIMIT_AS，CPU 上限不生效；
+// CPU 时间使用 RLIMIT_CPU（对每个进程单独计算）。rlimit 通过 shell 的 ulimit 设置，设置失败时不执行命令
+func (o *TaskOptions) applyLimits(cmd *exec.Cmd, name string) *taskLimits {
+	l := &taskLimits{}
+	if o.CPULimit > 0 || o.MemoryLimit > 0 {
+		cg, err := newCgroup(name, o.CPULimit, o.MemoryLimit)
+		if err != nil {
+			fmt.Printf("无法使用 cgroup 限制资源（%v），内存改用 rlimit 限制\n", err)
+		} else {
+			cg.attach(cmd)
+			l.cgroup = cg
+		}
+	}
+
+	var ulimits []string
+	if o.CPULimit > 0 {
+		if l.cgroup != nil {
+			l.applied = append(l.applied, fmt.Sprintf("cpu=%g(cgroup)", o.CPULimit))
+		} else {
+			l.applied = append(l.applied, fmt.Sprintf("cpu=%g(未生效)", o.CPULimit))
+		}
+	}
+	if o.MemoryLimit > 0 {
+		memory := humanize.Bytes(uint64(o.MemoryLimit))
+		if l.cgroup != nil {
+			l.applied = append(l.applied, fmt.Sprintf("memory=%s(cgroup)", memory))
+		} else {
+			ulimits = append(ulimits, fmt.Sprintf("ulimit -v %d", (o.MemoryLimit+1023)/1024))
+			l.applied = append(l.applied, fmt.Sprintf("memory=%s(rlimit)", memory))
+		}
+	}
+	if o.CPUTime > 0 {
+		// 软限制发送 SIGXCPU（据此判断终止原因），硬限制多 1 秒兜底发送 SIGKILL
+		seconds := int64(math.Ceil(o.CPUTime.Seconds()))
+		ulimits = append(ulimits, fmt.Sprintf("ulimit -S -t %d", seconds), fmt.Sprintf("ulimit -H -t %d", seconds+1))
+		l.applied = append(l.applied, fmt.Sprintf("cpu_time=%s(rlimit)", o.CPUTime))
+	}
+	if o.Timeout > 0 {
+		l.applied = append(l.applied, fmt.Sprintf("timeout=%s", o.Timeout))
+	}
+
+	if len(ulimits) > 0 {
+		// cmd 为 sh -c <命令>
+		cmd.Args[len(cmd.Args)-1] = strings.Join(ulimits, " && ") + " || exit 126\n" + cmd.Args[len(cmd.Args)-1]
+	}
+	return l
+}
+
+// String 生效的限制，如 cpu=0.5(cgroup) memory=512MiB(cgroup) timeout=10m0s
+func (l *taskLimits) String() string {
+	return strings.Join(l.applied, " ")
+}
+
+// oomKilled 是否有进程因超出内存限制被终止（只有使用 cgroup 时可以判断）
+func (l *taskLimits) oomKilled() bool {
+	return l.cgroup != nil && l.cgroup.oomKilled()
+}
+
+// release 清理 cgroup
+func (l *taskLimits) release() {
+	if l.cgroup != nil {
+		l.cgroup.release()
+	}
+}
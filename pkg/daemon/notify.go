@@ -0,0 +1,343 @@
+package daemon
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"mime"
+	"net"
+	"net/http"
+	"net/smtp"
+	"os"
+	"os/exec"
+	"slices"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// 通知事件
+const (
+	EventFailure  = "failure"  // 执行失败（重试耗尽或超时）
+	EventRecovery = "recovery" // 失败后恢复成功
+	EventMissed   = "missed"   // 错过执行（上次未结束被跳过、依赖不满足、守护进程未运行）
+)
+
+// notifyTimeout 单个通知的超时时间
+const notifyTimeout = 30 * time.Second
+
+type (
+	// NotifyConfig 通知配置（全局配置和任务配置都会触发）
+	NotifyConfig struct {
+		On      []string       `json:"on,omitempty" mapstructure:"on"` // 触发事件（默认 failure, recovery）
+		Webhook *WebhookConfig `json:"webhook,omitempty" mapstructure:"webhook"`
+		Email   *EmailConfig   `json:"email,omitempty" mapstructure:"email"`
+		Exec    *ExecConfig    `json:"exec,omitempty" mapstructure:"exec"`
+	}
+
+	// WebhookConfig Webhook 通知（默认 POST 事件 JSON）
+	WebhookConfig struct {
+		URL      string            `json:"url" mapstructure:"url"`
+		Method   string            `json:"method,omitempty" mapstructure:"method"` // 默认 POST
+		Headers  map[string]string `json:"headers,omitempty" mapstructure:"headers"`
+		Template string            `json:"template,omitempty" mapstructure:"template"` // 请求体模板（为空时发送事件 JSON）
+	}
+
+	// EmailConfig 邮件通知（SMTP，服务器支持时使用 STARTTLS）
+	// 任务配置只填写 To 时使用全局配置的服务器
+	EmailConfig struct {
+		Host     string   `json:"host,omitempty" mapstructure:"host"`
+		Port     int      `json:"port,omitempty" mapstructure:"port"` // 默认 587
+		Username string   `json:"username,omitempty" mapstructure:"username"`
+		Password string   `json:"password,omitempty" mapstructure:"password"`
+		From     string   `json:"from,omitempty" mapstructure:"from"`
+		To       []string `json:"to" mapstructure:"to"`
+		Subject  string   `json:"subject,omitempty" mapstructure:"subject"`   // 标题模板
+		Template string   `json:"template,omitempty" mapstructure:"template"` // 正文模板
+	}
+
+	// ExecConfig 命令通知：事件字段通过 TASK_* 环境变量传入，模板渲染结果写入标准输入
+	ExecConfig struct {
+		Command  string `json:"command" mapstructure:"command"`
+		Template string `json:"template,omitempty" mapstructure:"template"` // 标准输入模板（为空时写入事件 JSON）
+	}
+
+	// NotifyEvent 通知内容（模板数据）
+	NotifyEvent struct {
+		Event     string     `json:"event"` // failure, recovery, missed
+		Task      string     `json:"task"`
+		Command   string     `json:"command"`
+		Status    string     `json:"status,omitempty"`
+		ExitCode  int        `json:"exit_code"`
+		Attempt   int        `json:"attempt"`
+		Reason    string     `json:"reason,omitempty"` // 错过执行的原因
+		Stdout    string     `json:"stdout,omitempty"`
+		Stderr    string     `json:"stderr,omitempty"`
+		StartTime time.Time  `json:"start_time"`
+		EndTime   *time.Time `json:"end_time,omitempty"`
+		Host      string     `json:"host"`
+	}
+)
+
+const (
+	defaultSubject = `[devtool] 任务 {{.Task}} {{.Event}}`
+	defaultBody    = `任务: {{.Task}}
+事件: {{.Event}}
+命令: {{.Command}}
+{{- if .Reason}}
+原因: {{.Reason}}
+{{- else}}
+状态: {{.Status}}（退出码: {{.ExitCode}}，重试: {{.Attempt}}）
+{{- end}}
+主机: {{.Host}}
+时间: {{.StartTime.Format "2006-01-02 15:04:05"}}
+{{- if .Stdout}}
+
+stdout:
+{{tail .Stdout 4096}}
+{{- end}}
+{{- if .Stderr}}
+
+stderr:
+{{tail .Stderr 4096}}
+{{- end}}
+`
+)
+
+// templateFuncs 模板函数：json 编码为 JSON 字面量，tail 截取末尾 n 字节
+var templateFuncs = template.FuncMap{
+	"json": func(v any) (string, error) {
+		data, err := json.Marshal(v)
+		return string(data), err
+	},
+	"tail": func(s string, n int) string {
+		if len(s) <= n {
+			return s
+		}
+		return "..." + s[len(s)-n:]
+	},
+}
+
+// SetNotify 设置全局通知配置
+func (d *Daemon) SetNotify(cfg *NotifyConfig) {
+	d.notifyCfg = cfg
+}
+
+// wants 是否订阅事件
+func (c *NotifyConfig) wants(event string) bool {
+	if len(c.On) == 0 {
+		return event == EventFailure || event == EventRecovery
+	}
+	return slices.Contains(c.On, event)
+}
+
+// newEvent 根据执行日志创建通知
+func newEvent(event string, task *Task, log *TaskLog) *NotifyEvent {
+	host, _ := os.Hostname()
+	ev := &NotifyEvent{
+		Event:     event,
+		Task:      task.Name,
+		Command:   task.Command,
+		StartTime: time.Now(),
+		Host:      host,
+	}
+	if log != nil {
+		ev.Status = log.Status
+		ev.ExitCode = log.ExitCode
+		ev.Attempt = log.Attempt
+		ev.Stdout = log.Stdout
+		ev.Stderr = log.Stderr
+		ev.StartTime = log.StartTime
+		ev.EndTime = log.EndTime
+	}
+	return ev
+}
+
+// notify 异步发送通知（全局配置和任务配置），失败只打印日志
+func (d *Daemon) notify(task *Task, ev *NotifyEvent) {
+	global := d.notifyCfg
+	for _, cfg := range []*NotifyConfig{global, task.Notify} {
+		if cfg == nil || !cfg.wants(ev.Event) {
+			continue
+		}
+
+		if cfg.Webhook != nil {
+			go d.send("webhook", task.Name, func(ctx context.Context) error { return sendWebhook(ctx, cfg.Webhook, ev) })
+		}
+		if cfg.Email != nil {
+			email := *cfg.Email
+			if email.Host == "" && global != nil && global.Email != nil {
+				email.Host, email.Port = global.Email.Host, global.Email.Port
+				email.Username, email.Password = global.Email.Username, global.Email.Password
+				if email.From == "" {
+					email.From = global.Email.From
+				}
+			}
+			go d.send("email", task.Name, func(ctx context.Context) error { return sendEmail(&email, ev) })
+		}
+		if cfg.Exec != nil {
+			go d.send("exec", task.Name, func(ctx context.Context) error { return runExecHook(ctx, cfg.Exec, ev) })
+		}
+	}
+}
+
+// send 执行单个通知
+func (d *Daemon) send(kind, name string, fn func(ctx context.Context) error) {
+	ctx, cancel := context.WithTimeout(context.Background(), notifyTimeout)
+	defer cancel()
+
+	if err := fn(ctx); err != nil {
+		fmt.Printf("任务 %s 发送 %s 通知失败: %v\n", name, kind, err)
+	}
+}
+
+// render 渲染模板，模板为空时输出事件 JSON
+func render(name, tmpl string, ev *NotifyEvent) ([]byte, error) {
+	if tmpl == "" {
+		return json.Marshal(ev)
+	}
+
+	t, err := template.New(name).Funcs(templateFuncs).Parse(tmpl)
+	if err != nil {
+		return nil, fmt.Errorf("解析模板失败: %w", err)
+	}
+	var buf bytes.Buffer
+	if err := t.Execute(&buf, ev); err != nil {
+		return nil, fmt.Errorf("渲染模板失败: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// sendWebhook 发送 Webhook 通知，非 2xx 响应视为失败
+func sendWebhook(ctx context.Context, cfg *WebhookConfig, ev *NotifyEvent) error {
+	body, err := render("webhook", cfg.Template, ev)
+	if err != nil {
+		return err
+	}
+
+	method := cfg.Method
+	if method == "" {
+		method = http.MethodPost
+	}
+	req, err := http.NewRequestWithContext(ctx, method, cfg.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range cfg.Headers {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook 返回 %s", resp.Status)
+	}
+	return nil
+}
+
+// sendEmail 发送邮件通知
+func sendEmail(cfg *EmailConfig, ev *NotifyEvent) error {
+	if cfg.Host == "" || len(cfg.To) == 0 {
+		return fmt.Errorf("邮件通知缺少服务器或收件人")
+	}
+
+	subjectTmpl, bodyTmpl := cfg.Subject, cfg.Template
+	if subjectTmpl == "" {
+		subjectTmpl = defaultSubject
+	}
+	if bodyTmpl == "" {
+		bodyTmpl = defaultBody
+	}
+	subject, err := render("subject", subjectTmpl, ev)
+	if err != nil {
+		return err
+	}
+	body, err := render("email", bodyTmpl, ev)
+	if err != nil {
+		return err
+	}
+
+	from := cfg.From
+	if from == "" {
+		from = cfg.Username
+	}
+	port := cfg.Port
+	if port == 0 {
+		port = 587
+	}
+
+	var msg bytes.Buffer
+	fmt.Fprintf(&msg, "From: %s\r\n", from)
+	fmt.Fprintf(&msg, "To: %s\r\n", strings.Join(cfg.To, ", "))
+	fmt.Fprintf(&msg, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", strings.TrimSpace(string(subject))))
+	fmt.Fprintf(&msg, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	msg.WriteString("MIME-Version: 1.0\r\n")
+	msg.WriteString("Content-Type: text/plain; charset=UTF-8\r\n\r\n")
+	msg.Write(body)
+
+	var auth smtp.Auth
+	if cfg.Username != "" {
+		auth = smtp.PlainAuth("", cfg.Username, cfg.Password, cfg.Host)
+	}
+	addr := net.JoinHostPort(cfg.Host, strconv.Itoa(port))
+	return smtp.SendMail(addr, auth, from, cfg.To, msg.Bytes())
+}
+
+// runExecHook 执行通知命令
+func runExecHook(ctx context.Context, cfg *ExecConfig, ev *NotifyEvent) error {
+	input, err := render("exec", cfg.Template, ev)
+	if err != nil {
+		return err
+	}
+
+	cmd := exec.CommandContext(ctx, "sh", "-c", cfg.Command)
+	cmd.Stdin = bytes.NewReader(input)
+	cmd.Env = append(os.Environ(),
+		"TASK_EVENT="+ev.Event,
+		"TASK_NAME="+ev.Task,
+		"TASK_COMMAND="+ev.Command,
+		"TASK_STATUS="+ev.Status,
+		"TASK_EXIT_CODE="+strconv.Itoa(ev.ExitCode),
+		"TASK_REASON="+ev.Reason,
+	)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("%w: %s", err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+// checkMissed 检查 since 之前（守护进程未运行期间）错过的定时执行（按最近一次执行时间推算）
+func (d *Daemon) checkMissed(since time.Time) {
+	var tasks []Task
+	if err := d.DB.Where("enabled = ? AND completed = ? AND schedule != ''", true, false).Find(&tasks).Error; err != nil {
+		return
+	}
+
+	for i := range tasks {
+		task := &tasks[i]
+		if IsOnceSchedule(task.Schedule) || task.Schedule == ScheduleAfter {
+			continue
+		}
+		sched, err := d.scheduler.Parse(task.Schedule, task.jobOptions()...)
+		if err != nil {
+			continue
+		}
+
+		var last TaskLog
+		if err := d.DB.Where("task_name = ?", task.Name).Order("start_time DESC").First(&last).Error; err != nil {
+			continue
+		}
+		if next := sched.Next(last.StartTime); next.Before(since) {
+			ev := newEvent(EventMissed, task, nil)
+			ev.Reason = fmt.Sprintf("守护进程未运行，错过 %s 的执行", next.Format("2006-01-02 15:04:05"))
+			d.notify(task, ev)
+		}
+	}
+}
@@ -0,0 +1,8 @@
+//go:build windows
+
+package daemon
+
+import "os/exec"
+
+// setProcessGroup Windows 没有进程组信号，取消时只终止 sh 进程
+func setProcessGroup(*exec.Cmd) {}
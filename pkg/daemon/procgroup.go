@@ -0,0 +1,35 @@
+//go:build !windows
+
+package daemon
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"syscall"
+	"time"
+)
+
+// setProcessGroup 命令在独立的进程组中执行，超时或取消时终止整个进程组，
+// 避免 sh -c 启动的子进程成为孤儿继续运行。
+// 先发送 SIGTERM，WaitDelay 后仍有进程未退出时发送 SIGKILL（需要在 setupCommand 之后调用，保留已设置的 SysProcAttr）
+func setProcessGroup(cmd *exec.Cmd) {
+	if cmd.SysProcAttr == nil {
+		cmd.SysProcAttr = &syscall.SysProcAttr{}
+	}
+	cmd.SysProcAttr.Setpgid = true
+
+	cmd.Cancel = func() error {
+		pgid := cmd.Process.Pid
+		if cmd.WaitDelay > 0 {
+			time.AfterFunc(cmd.WaitDelay, func() {
+				syscall.Kill(-pgid, syscall.SIGKILL)
+			})
+		}
+		err := syscall.Kill(-pgid, syscall.SIGTERM)
+		if errors.Is(err, syscall.ESRCH) {
+			return os.ErrProcessDone
+		}
+		return err
+	}
+}
@@ -0,0 +1,59 @@
+//go:build linux
+
+package daemon
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRunOnceTimeoutKillsProcessGroup(t *testing.T) {
+	dir := t.TempDir()
+	d, err := NewDaemon(filepath.Join(dir, "daemon.db"))
+	if err != nil {
+		t.Fatalf("NewDaemon 失败: %v", err)
+	}
+	defer d.Close()
+
+	pidFile := filepath.Join(dir, "sleep.pid")
+	task := &Task{Name: "orphan", Command: fmt.Sprintf("sleep 30 & echo $! > %s; wait", pidFile)}
+	task.Timeout = 200 * time.Millisecond
+
+	log, err := d.runOnce(task, 1)
+	if err == nil || log.Status != TaskStatusTimeout {
+		t.Fatalf("期望执行超时，实际状态 %s，错误 %v", log.Status, err)
+	}
+
+	data, err := os.ReadFile(pidFile)
+	if err != nil {
+		t.Fatalf("读取子进程 PID 失败: %v", err)
+	}
+	pid, err := strconv.Atoi(strings.TrimSpace(string(data)))
+	if err != nil {
+		t.Fatalf("无效的 PID: %q", data)
+	}
+
+	// 超时后 sh 启动的 sleep 随进程组一起终止
+	deadline := time.Now().Add(2 * time.Second)
+	for processRunning(pid) {
+		if time.Now().After(deadline) {
+			t.Fatalf("超时后子进程 %d 仍在运行", pid)
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+}
+
+// processRunning 进程是否存在且不是僵尸进程（容器中孤儿进程可能不会被回收）
+func processRunning(pid int) bool {
+	data, err := os.ReadFile(fmt.Sprintf("/proc/%d/stat", pid))
+	if err != nil {
+		return false
+	}
+	i := strings.LastIndexByte(string(data), ')')
+	return i < 0 || !strings.HasPrefix(string(data[i+1:]), " Z")
+}
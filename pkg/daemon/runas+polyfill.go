@@ -0,0 +1,14 @@
+//go:build windows
+
+package daemon
+
+import (
+	"fmt"
+	"os/exec"
+	"os/user"
+)
+
+// runAs Windows 不支持切换用户执行
+func runAs(*exec.Cmd, string) (*user.User, error) {
+	return nil, fmt.Errorf("当前系统不支持指定执行用户")
+}
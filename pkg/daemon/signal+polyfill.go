@@ -0,0 +1,10 @@
+//go:build windows
+
+package daemon
+
+import "os"
+
+// killSignal Windows 没有信号，无法判断终止原因
+func killSignal(*os.ProcessState) string {
+	return ""
+}
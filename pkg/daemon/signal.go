@@ -0,0 +1,32 @@
+//go:build !windows
+
+package daemon
+
+import (
+	"os"
+	"syscall"
+)
+
+// killSignal 进程被信号终止的原因，未被终止时返回空字符串
+// sh -c 执行的命令被信号终止时，shell 以 128+信号值退出
+func killSignal(state *os.ProcessState) string {
+	ws, ok := state.Sys().(syscall.WaitStatus)
+	if !ok {
+		return ""
+	}
+
+	var sig syscall.Signal
+	switch {
+	case ws.Signaled():
+		sig = ws.Signal()
+	case ws.Exited() && ws.ExitStatus() > 128 && ws.ExitStatus() < 128+32:
+		sig = syscall.Signal(ws.ExitStatus() - 128)
+	default:
+		return ""
+	}
+
+	if sig == syscall.SIGXCPU {
+		return KilledCPUTime
+	}
+	return "signal: " + sig.String()
+}
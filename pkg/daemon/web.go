@@ -0,0 +1,245 @@
+package daemon
+
+import (
+	"context"
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// webAssets 管理页面静态文件
+//
+//go:embed web
+var webAssets embed.FS
+
+type (
+	// WebServer 任务管理页面和 JSON API
+	// 任务和日志直接查询数据库，立即执行、启用和禁用通过控制接口通知守护进程
+	WebServer struct {
+		daemon     *Daemon
+		socketPath string
+		server     *http.Server
+	}
+
+	// WebStatus 守护进程状态（未运行时只有 Running）
+	WebStatus struct {
+		Running bool `json:"running"`
+		*Status
+	}
+
+	// WebTask 页面展示的任务（敏感环境变量已隐藏）
+	WebTask struct {
+		Task
+		NextRun *time.Time `json:"next_run,omitempty"` // 下次执行时间（守护进程运行且已加入调度时）
+		LastLog *TaskLog   `json:"last_log,omitempty"` // 最近一次执行（不含输出）
+	}
+)
+
+// webMaxLogs 日志接口最多返回的条数
+const webMaxLogs = 500
+
+// NewWebServer 创建管理页面，socketPath 为守护进程控制接口
+func NewWebServer(d *Daemon, socketPath, addr string) *WebServer {
+	s := &WebServer{daemon: d, socketPath: socketPath}
+
+	static, _ := fs.Sub(webAssets, "web")
+	mux := http.NewServeMux()
+	mux.Handle("GET /", http.FileServerFS(static))
+	mux.HandleFunc("GET /api/status", s.handleStatus)
+	mux.HandleFunc("GET /api/tasks", s.handleListTasks)
+	mux.HandleFunc("GET /api/logs", s.handleListLogs)
+	mux.HandleFunc("POST /api/tasks/{name}/run", s.handleRunTask)
+	mux.HandleFunc("POST /api/tasks/{name}/enable", s.handleEnableTask)
+	mux.HandleFunc("POST /api/tasks/{name}/disable", s.handleDisableTask)
+
+	s.server = &http.Server{
+		Addr:              addr,
+		Handler:           requireHost(addr, requireFetch(mux)),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	return s
+}
+
+// Handler 返回页面和 API 的处理器
+func (s *WebServer) Handler() http.Handler {
+	return s.server.Handler
+}
+
+// ListenAndServe 监听并处理请求（阻塞，Close 后返回 nil）
+func (s *WebServer) ListenAndServe() error {
+	ln, err := net.Listen("tcp", s.server.Addr)
+	if err != nil {
+		return fmt.Errorf("监听 %s 失败: %w", s.server.Addr, err)
+	}
+	if err := s.server.Serve(ln); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+// Close 关闭管理页面
+func (s *WebServer) Close() error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	return s.server.Shutdown(ctx)
+}
+
+// requireHost 只接受 Host 为 localhost、回环地址或监听地址的请求，防止 DNS 重绑定攻击
+// （重绑定页面的 Host 是攻击者的域名）；监听所有网卡时接受任意 IP
+func requireHost(addr string, next http.Handler) http.Handler {
+	listenHost, _, _ := net.SplitHostPort(addr)
+	anyIP := listenHost == "" || net.ParseIP(listenHost).IsUnspecified()
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		host := r.Host
+		if h, _, err := net.SplitHostPort(host); err == nil {
+			host = h
+		}
+		host = strings.Trim(host, "[]")
+
+		allowed := strings.EqualFold(host, "localhost") || strings.EqualFold(host, listenHost)
+		if ip := net.ParseIP(host); ip != nil {
+			allowed = allowed || ip.IsLoopback() || anyIP
+		}
+		if !allowed {
+			writeError(w, http.StatusForbidden, fmt.Errorf("不允许的 Host: %s", r.Host))
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// requireFetch 修改操作要求 X-Requested-With 请求头（跨站表单无法设置，防止 CSRF）
+func requireFetch(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead && r.Header.Get("X-Requested-With") == "" {
+			writeError(w, http.StatusForbidden, fmt.Errorf("缺少 X-Requested-With 请求头"))
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// control 连接守护进程控制接口，未运行时返回 nil
+func (s *WebServer) control() (*ControlClient, *Status) {
+	client, err := DialControl(s.socketPath)
+	if err != nil {
+		return nil, nil
+	}
+	status, err := client.Status()
+	if err != nil {
+		return nil, nil
+	}
+	return client, status
+}
+
+func (s *WebServer) handleStatus(w http.ResponseWriter, r *http.Request) {
+	_, status := s.control()
+	writeJSON(w, http.StatusOK, &WebStatus{Running: status != nil, Status: status})
+}
+
+// handleListTasks 任务列表，附带下次执行时间和最近一次执行
+func (s *WebServer) handleListTasks(w http.ResponseWriter, r *http.Request) {
+	tasks, err := s.daemon.ListTasks()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	next := make(map[string]time.Time)
+	if _, status := s.control(); status != nil {
+		for _, job := range status.Jobs {
+			next[job.Name] = job.Next
+		}
+	}
+
+	result := make([]WebTask, len(tasks))
+	for i, task := range tasks {
+		task.RedactSecrets()
+		result[i].Task = task
+		if t, ok := next[task.Name]; ok && !t.IsZero() {
+			result[i].NextRun = &t
+		}
+
+		var last TaskLog
+		err := s.daemon.DB.Omit("stdout", "stderr").
+			Where("task_name = ?", task.Name).Order("start_time DESC").Limit(1).Find(&last).Error
+		if err == nil && last.ID != 0 {
+			result[i].LastLog = &last
+		}
+	}
+	writeJSON(w, http.StatusOK, result)
+}
+
+// handleListLogs 执行日志（包含输出），?task= 按任务过滤，?limit= 限制条数（默认 50）
+func (s *WebServer) handleListLogs(w http.ResponseWriter, r *http.Request) {
+	limit := 50
+	if v := r.URL.Query().Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 {
+			writeError(w, http.StatusBadRequest, fmt.Errorf("无效的 limit: %s", v))
+			return
+		}
+		limit = min(n, webMaxLogs)
+	}
+
+	logs, err := s.daemon.ListLogs(r.URL.Query().Get("task"), limit)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, logs)
+}
+
+// handleRunTask 立即执行（需要守护进程运行）
+func (s *WebServer) handleRunTask(w http.ResponseWriter, r *http.Request) {
+	client, _ := s.control()
+	if client == nil {
+		writeError(w, http.StatusServiceUnavailable, fmt.Errorf("调度器未运行，请先使用 'devtool start' 启动"))
+		return
+	}
+	if err := client.RunNow(r.PathValue("name")); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	w.WriteHeader(http.StatusAccepted)
+}
+
+func (s *WebServer) handleEnableTask(w http.ResponseWriter, r *http.Request) {
+	s.setEnabled(w, r.PathValue("name"), true)
+}
+
+func (s *WebServer) handleDisableTask(w http.ResponseWriter, r *http.Request) {
+	s.setEnabled(w, r.PathValue("name"), false)
+}
+
+// setEnabled 启用或禁用任务，守护进程运行时由其更新调度，否则只修改数据库（启动后生效）
+func (s *WebServer) setEnabled(w http.ResponseWriter, name string, enabled bool) {
+	if _, err := s.daemon.GetTask(name); err != nil {
+		writeError(w, http.StatusNotFound, err)
+		return
+	}
+
+	var err error
+	switch client, _ := s.control(); {
+	case client != nil && enabled:
+		err = client.EnableTask(name)
+	case client != nil:
+		err = client.DisableTask(name)
+	case enabled:
+		err = s.daemon.EnableTask(name)
+	default:
+		err = s.daemon.DisableTask(name)
+	}
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
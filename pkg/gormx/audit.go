@@ -0,0 +1,584 @@
+package gormx
+
+import (
+	"context"
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"path"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+	"gorm.io/plugin/dbresolver"
+)
+
+const (
+	auditPluginName = "gormx:audit"
+
+	// InstanceSet 中保存修改前数据的 key
+	auditBeforeKey = "gormx:audit:before"
+
+	// DefaultAuditTable 默认审计表名
+	DefaultAuditTable = "gormx_audit_logs"
+
+	defaultAuditMaxRows = 1000
+
+	// 加密字段在审计记录中的占位值
+	auditMaskedValue = "***"
+)
+
+// ErrAuditTooManyRows 单条 UPDATE/DELETE 影响的行数超过 AuditConfig.MaxRows
+var ErrAuditTooManyRows = errors.New("gormx: too many rows to audit")
+
+// AuditConfig 审计日志配置
+//
+// 记录通过模型执行的 INSERT/UPDATE/DELETE（Table/Raw/Exec 不记录）：
+// - create：插入的完整数据
+// - update：变化的列和修改前后的值（没有变化的行不记录）
+// - delete：删除前的完整数据（软删除同样记录为 delete）
+//
+// UPDATE/DELETE 执行前会按相同条件查询一次主库，执行后再按主键查询一次
+type AuditConfig struct {
+	// 需要审计的表（支持通配符，如 "orders_*"），为空时审计所有表
+	Tables []string `json:"tables" yaml:"tables"`
+
+	// 单条 UPDATE/DELETE 最多审计的行数，超过时返回 ErrAuditTooManyRows，默认 1000
+	MaxRows int `json:"max_rows" yaml:"max_rows"`
+
+	// 写入审计日志失败时只打印日志，不让原操作失败（默认返回错误，默认事务会回滚）
+	IgnoreErrors bool `json:"ignore_errors" yaml:"ignore_errors"`
+
+	// 审计记录的写入位置，默认写入 DefaultAuditTable（不存在时自动创建）
+	Sink AuditSink `json:"-" yaml:"-"`
+
+	// context 中没有 WithAuditActor 设置的操作人时，从 context 中获取操作人（如从登录信息中读取）
+	ActorFunc func(ctx context.Context) string `json:"-" yaml:"-"`
+}
+
+// WithAudit 开启审计日志
+func (c *Config) WithAudit(audit AuditConfig) *Config {
+	c.audit = &audit
+	return c
+}
+
+// AuditRecord 一行数据的变更记录
+type AuditRecord struct {
+	Table      string         `json:"table"`
+	Operation  string         `json:"operation"`   // create, update, delete
+	PrimaryKey string         `json:"primary_key"` // 联合主键用逗号分隔
+	Changed    []string       `json:"changed,omitempty"`
+	Before     map[string]any `json:"before,omitempty"`
+	After      map[string]any `json:"after,omitempty"`
+	Actor      string         `json:"actor,omitempty"`
+	Time       time.Time      `json:"time"`
+}
+
+// AuditSink 审计记录的写入位置
+type AuditSink interface {
+	// WriteAudit 写入一条语句产生的审计记录，tx 为原操作使用的连接（在事务中时为同一事务）
+	WriteAudit(tx *gorm.DB, records []AuditRecord) error
+}
+
+// AuditSinkFunc 函数形式的 AuditSink
+type AuditSinkFunc func(tx *gorm.DB, records []AuditRecord) error
+
+// WriteAudit 实现 AuditSink
+func (f AuditSinkFunc) WriteAudit(tx *gorm.DB, records []AuditRecord) error {
+	return f(tx, records)
+}
+
+// AuditOptOut 模型实现该接口并返回 true 时不记录审计日志
+type AuditOptOut interface {
+	SkipAudit() bool
+}
+
+// AuditLog 审计表中的一条记录（Changed/Before/After 为 JSON）
+type AuditLog struct {
+	ID         int64     `gorm:"primaryKey" json:"id"`
+	Table      string    `gorm:"column:table_name;size:128;index:idx_gormx_audit_target,priority:1" json:"table"`
+	PrimaryKey string    `gorm:"size:255;index:idx_gormx_audit_target,priority:2" json:"primary_key"`
+	Operation  string    `gorm:"size:16" json:"operation"`
+	Changed    string    `gorm:"type:text" json:"changed"`
+	Before     string    `gorm:"type:text" json:"before"`
+	After      string    `gorm:"type:text" json:"after"`
+	Actor      string    `gorm:"size:128;index" json:"actor"`
+	CreatedAt  time.Time `gorm:"index" json:"created_at"`
+}
+
+// SkipAudit 审计表本身不记录审计日志
+func (AuditLog) SkipAudit() bool {
+	return true
+}
+
+// TableAuditSink 将审计记录写入数据库表（与原操作在同一事务中）
+type TableAuditSink struct {
+	Table string // 默认 DefaultAuditTable
+}
+
+// NewTableAuditSink 创建写入指定表的 AuditSink
+func NewTableAuditSink(table string) *TableAuditSink {
+	return &TableAuditSink{Table: table}
+}
+
+// WriteAudit 实现 AuditSink
+func (s *TableAuditSink) WriteAudit(tx *gorm.DB, records []AuditRecord) error {
+	logs := make([]AuditLog, len(records))
+	for i, r := range records {
+		logs[i] = AuditLog{
+			Table:      r.Table,
+			PrimaryKey: r.PrimaryKey,
+			Operation:  r.Operation,
+			Changed:    auditJSON(r.Changed),
+			Before:     auditJSON(r.Before),
+			After:      auditJSON(r.After),
+			Actor:      r.Actor,
+			CreatedAt:  r.Time,
+		}
+	}
+	return tx.Session(&gorm.Session{NewDB: true, SkipHooks: true}).Table(s.table()).Create(&logs).Error
+}
+
+// Migrate 创建或更新审计表
+func (s *TableAuditSink) Migrate(db *gorm.DB) error {
+	return db.Table(s.table()).AutoMigrate(&AuditLog{})
+}
+
+func (s *TableAuditSink) table() string {
+	if s.Table == "" {
+		return DefaultAuditTable
+	}
+	return s.Table
+}
+
+// auditJSON 编码审计字段，空值保存为空字符串
+func auditJSON(v any) string {
+	if rv := reflect.ValueOf(v); !rv.IsValid() || rv.Len() == 0 {
+		return ""
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprintf("%q", err.Error())
+	}
+	return string(data)
+}
+
+type (
+	auditActorKey struct{}
+	auditSkipKey  struct{}
+)
+
+// WithAuditActor 设置审计记录中的操作人
+//
+//	db.WithContext(gormx.WithAuditActor(ctx, "user:42")).Model(&order).Update("status", "paid")
+func WithAuditActor(ctx context.Context, actor string) context.Context {
+	return context.WithValue(ctx, auditActorKey{}, actor)
+}
+
+// AuditActor 获取 WithAuditActor 设置的操作人
+func AuditActor(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	actor, _ := ctx.Value(auditActorKey{}).(string)
+	return actor
+}
+
+// WithoutAudit 使用该 context 执行的操作不记录审计日志（如数据修复脚本中的批量更新）
+func WithoutAudit(ctx context.Context) context.Context {
+	return context.WithValue(ctx, auditSkipKey{}, true)
+}
+
+// auditPlugin 审计日志插件
+type auditPlugin struct {
+	config AuditConfig
+}
+
+func newAuditPlugin(cfg AuditConfig) *auditPlugin {
+	if cfg.MaxRows <= 0 {
+		cfg.MaxRows = defaultAuditMaxRows
+	}
+	if cfg.Sink == nil {
+		cfg.Sink = NewTableAuditSink(DefaultAuditTable)
+	}
+	return &auditPlugin{config: cfg}
+}
+
+// Name 实现 gorm.Plugin
+func (p *auditPlugin) Name() string {
+	return auditPluginName
+}
+
+// Initialize 实现 gorm.Plugin（在默认事务提交前写入审计记录，写入失败时回滚）
+func (p *auditPlugin) Initialize(db *gorm.DB) error {
+	cb := db.Callback()
+
+	if err := cb.Create().After("gorm:create").Before("gorm:commit_or_rollback_transaction").Register(auditPluginName+":after_create", p.afterCreate); err != nil {
+		return err
+	}
+	if err := cb.Update().Before("gorm:update").Register(auditPluginName+":before_update", p.loadBefore); err != nil {
+		return err
+	}
+	if err := cb.Update().After("gorm:update").Before("gorm:commit_or_rollback_transaction").Register(auditPluginName+":after_update", p.afterUpdate); err != nil {
+		return err
+	}
+	if err := cb.Delete().Before("gorm:delete").Register(auditPluginName+":before_delete", p.loadBefore); err != nil {
+		return err
+	}
+	return cb.Delete().After("gorm:delete").Before("gorm:commit_or_rollback_transaction").Register(auditPluginName+":after_delete", p.afterDelete)
+}
+
+// enabled 当前语句是否需要审计
+func (p *auditPlugin) enabled(db *gorm.DB) bool {
+	stmt := db.Statement
+	if db.DryRun || stmt.Schema == nil {
+		return false
+	}
+	if skip, _ := stmt.Context.Value(auditSkipKey{}).(bool); skip {
+		return false
+	}
+	if optOut, ok := reflect.New(stmt.Schema.ModelType).Interface().(AuditOptOut); ok && optOut.SkipAudit() {
+		return false
+	}
+
+	if len(p.config.Tables) == 0 {
+		return true
+	}
+	for _, pattern := range p.config.Tables {
+		if ok, _ := path.Match(pattern, stmt.Table); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// afterCreate 记录插入的数据
+func (p *auditPlugin) afterCreate(db *gorm.DB) {
+	if db.Error != nil || db.RowsAffected == 0 || !p.enabled(db) {
+		return
+	}
+
+	stmt := db.Statement
+	var records []AuditRecord
+	addRow := func(row reflect.Value) {
+		after := make(map[string]any, len(stmt.Schema.DBNames))
+		for _, f := range stmt.Schema.Fields {
+			if f.DBName == "" {
+				continue
+			}
+			v, _ := f.ValueOf(stmt.Context, row)
+			after[f.DBName] = auditValue(v)
+		}
+		p.mask(stmt.Schema, after)
+		records = append(records, AuditRecord{
+			Operation:  "create",
+			PrimaryKey: auditPrimaryKey(stmt.Schema, after),
+			Changed:    sortedColumns(after),
+			After:      after,
+		})
+	}
+
+	switch rv := reflect.Indirect(stmt.ReflectValue); rv.Kind() {
+	case reflect.Struct:
+		addRow(rv)
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < rv.Len(); i++ {
+			addRow(reflect.Indirect(rv.Index(i)))
+		}
+	default:
+		// Create(map) 不经过模型，不记录
+		return
+	}
+	p.write(db, records)
+}
+
+// loadBefore 执行 UPDATE/DELETE 前按相同条件查询将要修改的行
+func (p *auditPlugin) loadBefore(db *gorm.DB) {
+	if db.Error != nil || !p.enabled(db) {
+		return
+	}
+
+	stmt := db.Statement
+	var conds []clause.Expression
+	if c, ok := stmt.Clauses["WHERE"]; ok {
+		if where, ok := c.Expression.(clause.Where); ok {
+			conds = append(conds, where.Exprs...)
+		}
+	}
+	conds = append(conds, modelPrimaryKeyConds(stmt)...)
+	if len(conds) == 0 && !db.AllowGlobalUpdate {
+		return // GORM 会拒绝没有条件的 UPDATE/DELETE
+	}
+
+	rows, err := p.query(db, stmt.Unscoped, conds)
+	if err != nil {
+		p.fail(db, err)
+		return
+	}
+	db.InstanceSet(auditBeforeKey, rows)
+}
+
+// afterUpdate 按主键查询修改后的行，记录变化的列
+func (p *auditPlugin) afterUpdate(db *gorm.DB) {
+	before := p.before(db)
+	if len(before) == 0 {
+		return
+	}
+
+	stmt := db.Statement
+	rows, err := p.query(db, true, []clause.Expression{primaryKeyIn(stmt.Schema, before)})
+	if err != nil {
+		p.fail(db, err)
+		return
+	}
+	after := make(map[string]map[string]any, len(rows))
+	for _, row := range rows {
+		after[auditPrimaryKey(stmt.Schema, row)] = row
+	}
+
+	var records []AuditRecord
+	for _, b := range before {
+		pk := auditPrimaryKey(stmt.Schema, b)
+		a, ok := after[pk]
+		if !ok {
+			continue // 主键被修改或行已被删除
+		}
+
+		var changed []string
+		for column, value := range a {
+			if !reflect.DeepEqual(b[column], value) {
+				changed = append(changed, column)
+			}
+		}
+		if len(changed) == 0 {
+			continue
+		}
+		sort.Strings(changed)
+
+		r := AuditRecord{
+			Operation:  "update",
+			PrimaryKey: pk,
+			Changed:    changed,
+			Before:     make(map[string]any, len(changed)),
+			After:      make(map[string]any, len(changed)),
+		}
+		for _, column := range changed {
+			r.Before[column], r.After[column] = b[column], a[column]
+		}
+		p.mask(stmt.Schema, r.Before)
+		p.mask(stmt.Schema, r.After)
+		records = append(records, r)
+	}
+	p.write(db, records)
+}
+
+// afterDelete 记录删除前的数据
+func (p *auditPlugin) afterDelete(db *gorm.DB) {
+	before := p.before(db)
+	if len(before) == 0 {
+		return
+	}
+
+	records := make([]AuditRecord, 0, len(before))
+	for _, b := range before {
+		p.mask(db.Statement.Schema, b)
+		records = append(records, AuditRecord{
+			Operation:  "delete",
+			PrimaryKey: auditPrimaryKey(db.Statement.Schema, b),
+			Before:     b,
+		})
+	}
+	p.write(db, records)
+}
+
+// before 获取 loadBefore 查询的行（语句执行失败或没有影响行时返回 nil）
+func (p *auditPlugin) before(db *gorm.DB) []map[string]any {
+	if db.Error != nil || db.RowsAffected == 0 {
+		return nil
+	}
+	value, ok := db.InstanceGet(auditBeforeKey)
+	if !ok {
+		return nil
+	}
+	rows, _ := value.([]map[string]any)
+	return rows
+}
+
+// query 在主库（事务中时为当前事务）按条件查询模型对应的行
+func (p *auditPlugin) query(db *gorm.DB, unscoped bool, conds []clause.Expression) ([]map[string]any, error) {
+	stmt := db.Statement
+	q := db.Session(&gorm.Session{NewDB: true, SkipHooks: true}).
+		Model(reflect.New(stmt.Schema.ModelType).Interface()).
+		Table(stmt.Table).
+		Clauses(dbresolver.Write)
+	if unscoped {
+		q = q.Unscoped()
+	}
+	if len(conds) > 0 {
+		q = q.Clauses(clause.Where{Exprs: conds})
+	}
+
+	var rows []map[string]any
+	if err := q.Limit(p.config.MaxRows + 1).Find(&rows).Error; err != nil {
+		return nil, fmt.Errorf("failed to load audit rows: %w", err)
+	}
+	if len(rows) > p.config.MaxRows {
+		return nil, fmt.Errorf("%w: more than %d rows in %s", ErrAuditTooManyRows, p.config.MaxRows, stmt.Table)
+	}
+	return rows, nil
+}
+
+// write 填充表名、操作人和时间后写入 Sink
+func (p *auditPlugin) write(db *gorm.DB, records []AuditRecord) {
+	if len(records) == 0 {
+		return
+	}
+
+	ctx := db.Statement.Context
+	actor := AuditActor(ctx)
+	if actor == "" && p.config.ActorFunc != nil {
+		actor = p.config.ActorFunc(ctx)
+	}
+	now := time.Now()
+	for i := range records {
+		records[i].Table = db.Statement.Table
+		records[i].Actor = actor
+		records[i].Time = now
+	}
+
+	if err := p.config.Sink.WriteAudit(db, records); err != nil {
+		p.fail(db, fmt.Errorf("failed to write audit log: %w", err))
+	}
+}
+
+// fail 审计失败时让原操作返回错误（IgnoreErrors 时只打印日志）
+func (p *auditPlugin) fail(db *gorm.DB, err error) {
+	if p.config.IgnoreErrors {
+		log.Printf("gormx: %v", err)
+		return
+	}
+	db.AddError(err)
+}
+
+// mask 将加密字段替换为占位值
+func (p *auditPlugin) mask(s *schema.Schema, row map[string]any) {
+	for _, f := range s.Fields {
+		if f.DBName == "" || f.TagSettings["SERIALIZER"] != EncryptedSerializerName {
+			continue
+		}
+		if _, ok := row[f.DBName]; ok {
+			row[f.DBName] = auditMaskedValue
+		}
+	}
+}
+
+// modelPrimaryKeyConds 模型（或模型切片）带主键时的主键条件，与 GORM 更新/删除时自动添加的条件一致
+func modelPrimaryKeyConds(stmt *gorm.Statement) []clause.Expression {
+	if len(stmt.Schema.PrimaryFields) == 0 {
+		return nil
+	}
+
+	var rows []map[string]any
+	addRow := func(rv reflect.Value) {
+		row := make(map[string]any, len(stmt.Schema.PrimaryFields))
+		for _, f := range stmt.Schema.PrimaryFields {
+			v, zero := f.ValueOf(stmt.Context, rv)
+			if zero {
+				return
+			}
+			row[f.DBName] = v
+		}
+		rows = append(rows, row)
+	}
+
+	switch rv := reflect.Indirect(stmt.ReflectValue); rv.Kind() {
+	case reflect.Struct:
+		addRow(rv)
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < rv.Len(); i++ {
+			addRow(reflect.Indirect(rv.Index(i)))
+		}
+	}
+	if len(rows) == 0 {
+		return nil
+	}
+	return []clause.Expression{primaryKeyIn(stmt.Schema, rows)}
+}
+
+// primaryKeyIn 按行的主键值构造查询条件
+func primaryKeyIn(s *schema.Schema, rows []map[string]any) clause.Expression {
+	if len(s.PrimaryFields) == 1 {
+		column := s.PrimaryFields[0].DBName
+		values := make([]any, len(rows))
+		for i, row := range rows {
+			values[i] = row[column]
+		}
+		return clause.IN{Column: clause.Column{Table: clause.CurrentTable, Name: column}, Values: values}
+	}
+
+	exprs := make([]clause.Expression, len(rows))
+	for i, row := range rows {
+		eqs := make([]clause.Expression, len(s.PrimaryFields))
+		for j, f := range s.PrimaryFields {
+			eqs[j] = clause.Eq{Column: clause.Column{Table: clause.CurrentTable, Name: f.DBName}, Value: row[f.DBName]}
+		}
+		exprs[i] = clause.And(eqs...)
+	}
+	return clause.Or(exprs...)
+}
+
+// auditPrimaryKey 行的主键值（联合主键用逗号分隔）
+func auditPrimaryKey(s *schema.Schema, row map[string]any) string {
+	values := make([]string, len(s.PrimaryFields))
+	for i, f := range s.PrimaryFields {
+		values[i] = fmt.Sprint(row[f.DBName])
+	}
+	return strings.Join(values, ",")
+}
+
+// auditValue 将模型字段值转换为数据库值（与查询结果的类型一致）
+func auditValue(v any) any {
+	if rv := reflect.ValueOf(v); rv.Kind() == reflect.Ptr && rv.IsNil() {
+		return nil
+	}
+	if valuer, ok := v.(driver.Valuer); ok {
+		if value, err := valuer.Value(); err == nil {
+			return value
+		}
+	}
+	return v
+}
+
+// sortedColumns 按列名排序的列
+func sortedColumns(row map[string]any) []string {
+	columns := make([]string, 0, len(row))
+	for column := range row {
+		columns = append(columns, column)
+	}
+	sort.Strings(columns)
+	return columns
+}
+
+// setupAudit 注册审计插件（配置了 WithAudit 时），使用默认的 TableAuditSink 时自动创建审计表
+func (c *Client) setupAudit(db *gorm.DB) error {
+	if c.config.audit == nil {
+		return nil
+	}
+	if c.audit == nil {
+		c.audit = newAuditPlugin(*c.config.audit)
+	}
+
+	if err := db.Use(c.audit); err != nil {
+		return err
+	}
+	if sink, ok := c.audit.config.Sink.(*TableAuditSink); ok {
+		return sink.Migrate(db)
+	}
+	return nil
+}
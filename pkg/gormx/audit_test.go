@@ -0,0 +1,141 @@
+package gormx
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"gorm.io/gorm"
+)
+
+type auditUser struct {
+	ID        int64
+	Name      string
+	Email     string
+	DeletedAt gorm.DeletedAt
+}
+
+type auditSession struct {
+	ID    int64
+	Token string
+}
+
+func (auditSession) SkipAudit() bool { return true }
+
+func newAuditClient(t *testing.T, audit AuditConfig) *Client {
+	t.Helper()
+	cfg := NewConfig("sqlite", fmt.Sprintf("file:%s?mode=memory&cache=shared", t.Name()))
+	cfg.LogLevel = "silent"
+	cfg.DisableMetrics = true
+	cfg.HealthCheckInterval = 0
+	cfg.WithAudit(audit)
+
+	client, err := NewClient(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { client.Close() })
+	if err := client.AutoMigrate(&auditUser{}, &auditSession{}); err != nil {
+		t.Fatal(err)
+	}
+	return client
+}
+
+func TestAudit_RecordsChanges(t *testing.T) {
+	client := newAuditClient(t, AuditConfig{})
+	db := client.WithContext(WithAuditActor(context.Background(), "admin"))
+
+	users := []auditUser{{Name: "tom", Email: "tom@a.com"}, {Name: "jerry", Email: "jerry@a.com"}}
+	if err := db.Create(&users).Error; err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Model(&auditUser{}).Where("name = ?", "tom").Update("email", "tom@b.com").Error; err != nil {
+		t.Fatal(err)
+	}
+	// 值没有变化的行不记录
+	if err := db.Model(&users[1]).Update("name", "jerry").Error; err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Delete(&users[1]).Error; err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Create(&auditSession{Token: "secret"}).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	var logs []AuditLog
+	if err := client.Table(DefaultAuditTable).Order("id").Find(&logs).Error; err != nil {
+		t.Fatal(err)
+	}
+	want := []struct{ op, pk, changed string }{
+		{"create", "1", `["deleted_at","email","id","name"]`},
+		{"create", "2", `["deleted_at","email","id","name"]`},
+		{"update", "1", `["email"]`},
+		{"delete", "2", ""},
+	}
+	if len(logs) != len(want) {
+		t.Fatalf("expected %d audit logs, got %d: %+v", len(want), len(logs), logs)
+	}
+	for i, w := range want {
+		l := logs[i]
+		if l.Operation != w.op || l.PrimaryKey != w.pk || l.Changed != w.changed || l.Table != "audit_users" || l.Actor != "admin" {
+			t.Errorf("log %d: unexpected %+v", i, l)
+		}
+	}
+	if logs[2].Before != `{"email":"tom@a.com"}` || logs[2].After != `{"email":"tom@b.com"}` {
+		t.Errorf("unexpected update values: before=%s after=%s", logs[2].Before, logs[2].After)
+	}
+	if logs[3].Before == "" || logs[3].After != "" {
+		t.Errorf("unexpected delete values: before=%s after=%s", logs[3].Before, logs[3].After)
+	}
+}
+
+func TestAudit_TablesAndSkip(t *testing.T) {
+	var records []AuditRecord
+	client := newAuditClient(t, AuditConfig{
+		Tables:    []string{"audit_u*"},
+		ActorFunc: func(context.Context) string { return "system" },
+		Sink: AuditSinkFunc(func(_ *gorm.DB, r []AuditRecord) error {
+			records = append(records, r...)
+			return nil
+		}),
+	})
+
+	if err := client.Create(&auditUser{Name: "tom"}).Error; err != nil {
+		t.Fatal(err)
+	}
+	if err := client.WithContext(WithoutAudit(context.Background())).Create(&auditUser{Name: "jerry"}).Error; err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 1 || records[0].Actor != "system" || records[0].After["name"] != "tom" {
+		t.Fatalf("unexpected records: %+v", records)
+	}
+	if client.Migrator().HasTable(DefaultAuditTable) {
+		t.Error("audit table should not be created for custom sink")
+	}
+}
+
+func TestAudit_SinkErrorRollsBack(t *testing.T) {
+	sinkErr := errors.New("sink down")
+	client := newAuditClient(t, AuditConfig{
+		MaxRows: 1,
+		Sink:    AuditSinkFunc(func(*gorm.DB, []AuditRecord) error { return sinkErr }),
+	})
+
+	if err := client.Create(&auditUser{Name: "tom"}).Error; !errors.Is(err, sinkErr) {
+		t.Fatalf("expected sink error, got %v", err)
+	}
+	var count int64
+	client.Model(&auditUser{}).Count(&count)
+	if count != 0 {
+		t.Fatalf("expected create to be rolled back, got %d rows", count)
+	}
+
+	// 超过 MaxRows 时拒绝执行
+	client.WithContext(WithoutAudit(context.Background())).Create(&[]auditUser{{Name: "a"}, {Name: "b"}})
+	err := client.Model(&auditUser{}).Where("id > 0").Update("email", "x").Error
+	if !errors.Is(err, ErrAuditTooManyRows) {
+		t.Fatalf("expected ErrAuditTooManyRows, got %v", err)
+	}
+}
@@ -21,6 +21,18 @@ type Client struct {
 
 	// 分片连接（如果启用了分片）
 	shardDBs []*gorm.DB
+
+	// 指标/追踪/慢查询插件（所有连接共享）
+	metrics *metricsPlugin
+
+	// 健康检查（主库/从库）
+	health *healthMonitor
+
+	// 加密字段密钥环（配置了 WithEncryption 时）
+	keyring *Keyring
+
+	// 审计日志插件（配置了 WithAudit 时，所有连接共享）
+	audit *auditPlugin
 }
 
 // NewClient 创建 GORM 客户端
@@ -32,6 +44,12 @@ func NewClient(cfg *Config) (*Client, error) {
 	client := &Client{
 		config:   cfg,
 		shardDBs: make([]*gorm.DB, 0),
+		metrics:  newMetricsPlugin(cfg),
+		health:   newHealthMonitor(cfg),
+	}
+
+	if err := client.setupEncryption(cfg); err != nil {
+		return nil, fmt.Errorf("failed to setup encryption: %w", err)
 	}
 
 	// 分片模式：直接初始化分片连接，不需要主连接
@@ -43,25 +61,29 @@ func NewClient(cfg *Config) (*Client, error) {
 		if len(client.shardDBs) > 0 {
 			client.DB = client.shardDBs[0]
 		}
+		client.health.start()
+		client.metrics.stmts.start()
 		return client, nil
 	}
 
 	// 非分片模式：创建主连接
 	// 确定主连接 DSN
 	var primaryDSN string
+	primaryName := "primary"
 	if cfg.HasMultiDatabase() {
 		// 多数据库模式：完全忽略 Config.DSN，使用第一个数据库的 DSN
 		if len(cfg.multiDB.Databases) == 0 {
 			return nil, fmt.Errorf("multi-database mode requires at least one database")
 		}
 		primaryDSN = cfg.multiDB.Databases[0].DSN
+		primaryName = cfg.multiDB.Databases[0].Name
 		if primaryDSN == "" {
 			return nil, fmt.Errorf("first database DSN cannot be empty in multi-database mode")
 		}
 	} else {
-		// 单库/主从模式：使用 Config.DSN
+		// 单库/主从模式：使用 Config.DSN（WithConn 时使用已有连接）
 		primaryDSN = cfg.DSN
-		if primaryDSN == "" {
+		if primaryDSN == "" && cfg.conn == nil {
 			return nil, fmt.Errorf("DSN cannot be empty")
 		}
 	}
@@ -89,9 +111,14 @@ func NewClient(cfg *Config) (*Client, error) {
 		DisableForeignKeyConstraintWhenMigrating: cfg.DisableForeignKeyCheck,
 	}
 
-	// 根据驱动类型创建主库连接
-	dialector, err := createPrimaryDialector(cfg.Driver, primaryDSN)
-	if err != nil {
+	// 根据驱动类型创建主库连接（WithConn 时基于已有连接池）
+	var (
+		dialector gorm.Dialector
+		err       error
+	)
+	if cfg.conn != nil && !cfg.HasMultiDatabase() {
+		dialector = dialectorFromConn(cfg.Driver, cfg.conn)
+	} else if dialector, err = createPrimaryDialector(cfg.Driver, primaryDSN); err != nil {
 		return nil, err
 	}
 
@@ -100,16 +127,21 @@ func NewClient(cfg *Config) (*Client, error) {
 		return nil, fmt.Errorf("failed to connect database: %w", err)
 	}
 
+	if err := db.Use(client.metrics); err != nil {
+		return nil, fmt.Errorf("failed to register metrics plugin: %w", err)
+	}
+	if err := client.setupAudit(db); err != nil {
+		return nil, fmt.Errorf("failed to setup audit: %w", err)
+	}
+
 	// 配置连接池
 	sqlDB, err := db.DB()
 	if err != nil {
 		return nil, fmt.Errorf("failed to get sql.DB: %w", err)
 	}
 
-	sqlDB.SetMaxOpenConns(cfg.MaxOpenConns)
-	sqlDB.SetMaxIdleConns(cfg.MaxIdleConns)
-	sqlDB.SetConnMaxLifetime(cfg.MaxLifetime)
-	sqlDB.SetConnMaxIdleTime(cfg.MaxIdleTime)
+	client.configurePool(sqlDB)
+	client.health.add(primaryName, RolePrimary, -1, sqlDB)
 
 	client.DB = db
 
@@ -118,6 +150,8 @@ func NewClient(cfg *Config) (*Client, error) {
 		return nil, fmt.Errorf("failed to setup dbresolver: %w", err)
 	}
 
+	client.health.start()
+	client.metrics.stmts.start()
 	return client, nil
 }
 
@@ -133,6 +167,13 @@ func (c *Client) GetSQLDB() (*sql.DB, error) {
 
 // Close 关闭数据库连接
 func (c *Client) Close() error {
+	// 停止健康检查，关闭由 Client 管理的从库/多数据库连接
+	c.health.close()
+	c.metrics.stmts.close()
+	for _, t := range c.health.targets {
+		t.db.Close()
+	}
+
 	// 关闭分片连接
 	for _, shardDB := range c.shardDBs {
 		if sqlDB, err := shardDB.DB(); err == nil {
@@ -281,30 +322,36 @@ func (c *Client) setupShardingConnections(cfg *Config) error {
 			return fmt.Errorf("failed to connect shard %d: %w", shard.ID, err)
 		}
 
+		if err := db.Use(c.metrics); err != nil {
+			return fmt.Errorf("failed to register shard %d metrics plugin: %w", shard.ID, err)
+		}
+		if err := c.setupAudit(db); err != nil {
+			return fmt.Errorf("failed to setup shard %d audit: %w", shard.ID, err)
+		}
+
 		// 配置连接池
 		sqlDB, err := db.DB()
 		if err != nil {
 			return fmt.Errorf("failed to get shard %d sql.DB: %w", shard.ID, err)
 		}
 
-		sqlDB.SetMaxOpenConns(cfg.MaxOpenConns)
-		sqlDB.SetMaxIdleConns(cfg.MaxIdleConns)
-		sqlDB.SetConnMaxLifetime(cfg.MaxLifetime)
-		sqlDB.SetConnMaxIdleTime(cfg.MaxIdleTime)
+		c.configurePool(sqlDB)
+		c.health.add(shard.Name, RolePrimary, shard.ID, sqlDB)
 
 		// 配置主从（如果有从库）
 		// 注意：每个分片的 DB 实例是独立的，可以单独配置主从
 		if shard.ReplicaDSN != "" {
-			replicaDialector, err := createPrimaryDialector(cfg.Driver, shard.ReplicaDSN)
+			_, replicaDialector, err := c.openConn(shard.Name, RoleReplica, shard.ID, shard.ReplicaDSN)
 			if err != nil {
 				return fmt.Errorf("failed to create shard %d replica dialector: %w", shard.ID, err)
 			}
 
-			// 为这个分片配置主从
-			resolver := dbresolver.Register(dbresolver.Config{
+			// 为这个分片配置主从（从库不可用时回退到分片主库）
+			resolverCfg := dbresolver.Config{
 				Replicas: []gorm.Dialector{replicaDialector},
-				Policy:   dbresolver.RandomPolicy{},
-			})
+			}
+			c.withHealthPolicy(&resolverCfg, sqlDB)
+			resolver := dbresolver.Register(resolverCfg)
 
 			if err := db.Use(resolver); err != nil {
 				return fmt.Errorf("failed to register shard %d replica: %w", shard.ID, err)
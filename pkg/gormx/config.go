@@ -1,7 +1,10 @@
 package gormx
 
 import (
+	"database/sql"
 	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
 )
 
 // Config GORM 配置
@@ -24,6 +27,21 @@ type Config struct {
 	IgnoreNotFound bool          `json:"ignore_not_found" yaml:"ignore_not_found"`
 	ColorfulLog    bool          `json:"colorful_log" yaml:"colorful_log"`
 
+	// 监控配置
+	SlowQueryLimit    int                   `json:"slow_query_limit" yaml:"slow_query_limit"` // 保留最慢的 N 条 SQL，0 表示不记录
+	DisableMetrics    bool                  `json:"disable_metrics" yaml:"disable_metrics"`   // 关闭 Prometheus 指标
+	DisableTracing    bool                  `json:"disable_tracing" yaml:"disable_tracing"`   // 关闭 OpenTelemetry span
+	MetricsRegisterer prometheus.Registerer `json:"-" yaml:"-"`                               // 默认 prometheus.DefaultRegisterer
+
+	// 健康检查配置
+	HealthCheckInterval time.Duration `json:"health_check_interval" yaml:"health_check_interval"` // 检查间隔，0 表示关闭
+	HealthCheckFailures int           `json:"health_check_failures" yaml:"health_check_failures"` // 连续失败多少次后剔除从库
+
+	// 复制延迟检测（随健康检查执行，HealthCheckInterval 为 0 时不检测）
+	MaxReplicaLag    time.Duration `json:"max_replica_lag" yaml:"max_replica_lag"`       // 从库延迟超过该值时读请求暂时路由到主库，0 表示不检测
+	ReplicaLagSource string        `json:"replica_lag_source" yaml:"replica_lag_source"` // status（默认，读取复制状态）或 heartbeat（心跳表）
+	HeartbeatTable   string        `json:"heartbeat_table" yaml:"heartbeat_table"`       // 心跳表名，默认 gormx_heartbeat
+
 	// 性能配置
 	PrepareStmt            bool `json:"prepare_stmt" yaml:"prepare_stmt"`
 	DisableNestedTx        bool `json:"disable_nested_tx" yaml:"disable_nested_tx"`
@@ -31,10 +49,17 @@ type Config struct {
 	DisableAutomaticPing   bool `json:"disable_automatic_ping" yaml:"disable_automatic_ping"`
 	DisableForeignKeyCheck bool `json:"disable_foreign_key_check" yaml:"disable_foreign_key_check"`
 
+	// 预编译语句诊断（PrepareStmt 开启时有效）
+	StmtLeakThreshold time.Duration `json:"stmt_leak_threshold" yaml:"stmt_leak_threshold"` // 语句闲置超过该时长仍在缓存中视为泄漏，0 表示不检测
+	StmtStatsInterval time.Duration `json:"stmt_stats_interval" yaml:"stmt_stats_interval"` // 定期打印缓存统计和泄漏的语句，0 表示不打印
+
 	// 高级配置（可选）
-	replica  *ReplicaConfig
-	multiDB  *MultiDatabaseConfig
-	sharding *ShardingConfig
+	replica    *ReplicaConfig
+	multiDB    *MultiDatabaseConfig
+	sharding   *ShardingConfig
+	encryption *EncryptionConfig
+	audit      *AuditConfig
+	conn       *sql.DB
 }
 
 // ReplicaConfig 主从配置
@@ -78,7 +103,11 @@ func NewConfig(driver, dsn string) *Config {
 		SlowThreshold:          200 * time.Millisecond,
 		IgnoreNotFound:         false,
 		ColorfulLog:            true,
+		SlowQueryLimit:         100,
+		HealthCheckInterval:    10 * time.Second,
+		HealthCheckFailures:    3,
 		PrepareStmt:            true,
+		StmtLeakThreshold:      10 * time.Minute,
 		DisableNestedTx:        false,
 		AllowGlobalUpdate:      false,
 		DisableAutomaticPing:   false,
@@ -99,7 +128,11 @@ func DefaultConfig() *Config {
 		SlowThreshold:          200 * time.Millisecond,
 		IgnoreNotFound:         false,
 		ColorfulLog:            true,
+		SlowQueryLimit:         100,
+		HealthCheckInterval:    10 * time.Second,
+		HealthCheckFailures:    3,
 		PrepareStmt:            true,
+		StmtLeakThreshold:      10 * time.Minute,
 		DisableNestedTx:        false,
 		AllowGlobalUpdate:      false,
 		DisableAutomaticPing:   false,
@@ -124,6 +157,13 @@ func (c *Config) WithMultiDatabase(databases []DatabaseConfig) *Config {
 	return c
 }
 
+// WithConn 使用已有的连接池作为主库（如测试中的 sqlmock），忽略 Config.DSN
+// 注意：多数据库和分片模式下不生效；Client.Close 会关闭该连接池
+func (c *Config) WithConn(conn *sql.DB) *Config {
+	c.conn = conn
+	return c
+}
+
 // HasReplica 是否配置了主从
 func (c *Config) HasReplica() bool {
 	return c.replica != nil && c.replica.ReplicaDSN != ""
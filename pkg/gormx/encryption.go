@@ -0,0 +1,306 @@
+package gormx
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+)
+
+// EncryptedSerializerName 加密字段序列化器名称
+//
+//	type User struct {
+//	    ID         int64
+//	    Phone      string `gorm:"serializer:encrypted"`
+//	    PhoneIndex string `gorm:"size:64;index"` // 盲索引，用于等值查询
+//	}
+const EncryptedSerializerName = "encrypted"
+
+// 密文格式：enc:v1:<keyID>:<base64(nonce|ciphertext)>
+const ciphertextPrefix = "enc:v1:"
+
+var (
+	// ErrUnknownKey 密文使用的密钥不在 Keyring 中
+	ErrUnknownKey = errors.New("gormx: unknown encryption key")
+	// ErrInvalidCiphertext 密文格式错误或校验失败
+	ErrInvalidCiphertext = errors.New("gormx: invalid ciphertext")
+)
+
+// EncryptionConfig 字段加密配置
+type EncryptionConfig struct {
+	// 密钥列表：keyID → base64 编码的 32 字节 AES-256 密钥
+	// 轮换密钥时新增一个 key 并修改 ActiveKey，旧 key 保留用于解密历史数据
+	Keys map[string]string `json:"keys" yaml:"keys"`
+
+	// 当前用于加密的 keyID
+	ActiveKey string `json:"active_key" yaml:"active_key"`
+
+	// 盲索引 HMAC 密钥（base64），不能与加密密钥相同
+	BlindIndexKey string `json:"blind_index_key" yaml:"blind_index_key"`
+}
+
+// WithEncryption 配置加密字段（注册 serializer:encrypted）
+func (c *Config) WithEncryption(encryption EncryptionConfig) *Config {
+	c.encryption = &encryption
+	return c
+}
+
+// Keyring AES-GCM 密钥环（支持密钥轮换）
+type Keyring struct {
+	aeads      map[string]cipher.AEAD
+	activeKey  string
+	blindIndex []byte
+}
+
+// NewKeyring 根据配置创建密钥环
+func NewKeyring(cfg EncryptionConfig) (*Keyring, error) {
+	if len(cfg.Keys) == 0 {
+		return nil, fmt.Errorf("encryption keys cannot be empty")
+	}
+	if _, ok := cfg.Keys[cfg.ActiveKey]; !ok {
+		return nil, fmt.Errorf("active key %q not found in keys", cfg.ActiveKey)
+	}
+
+	k := &Keyring{
+		aeads:     make(map[string]cipher.AEAD, len(cfg.Keys)),
+		activeKey: cfg.ActiveKey,
+	}
+	keys := make(map[string][]byte, len(cfg.Keys))
+
+	for id, encoded := range cfg.Keys {
+		if strings.Contains(id, ":") {
+			return nil, fmt.Errorf("key id %q cannot contain ':'", id)
+		}
+		key, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode key %s: %w", id, err)
+		}
+		if len(key) != 32 {
+			return nil, fmt.Errorf("key %s must be 32 bytes, got %d", id, len(key))
+		}
+		block, err := aes.NewCipher(key)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create cipher for key %s: %w", id, err)
+		}
+		aead, err := cipher.NewGCM(block)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create gcm for key %s: %w", id, err)
+		}
+		k.aeads[id] = aead
+		keys[id] = key
+	}
+
+	if cfg.BlindIndexKey != "" {
+		key, err := base64.StdEncoding.DecodeString(cfg.BlindIndexKey)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode blind index key: %w", err)
+		}
+		// 与加密密钥相同时，盲索引泄露会危及密文
+		for id, encKey := range keys {
+			if hmac.Equal(key, encKey) {
+				return nil, fmt.Errorf("blind index key must differ from encryption key %s", id)
+			}
+		}
+		k.blindIndex = key
+	}
+
+	return k, nil
+}
+
+// Encrypt 使用当前密钥加密
+func (k *Keyring) Encrypt(plaintext []byte) (string, error) {
+	aead := k.aeads[k.activeKey]
+
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return "", fmt.Errorf("failed to generate nonce: %w", err)
+	}
+
+	sealed := aead.Seal(nonce, nonce, plaintext, nil)
+	return ciphertextPrefix + k.activeKey + ":" + base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// Decrypt 解密（根据密文中的 keyID 选择密钥）
+func (k *Keyring) Decrypt(ciphertext string) ([]byte, error) {
+	keyID, payload, err := parseCiphertext(ciphertext)
+	if err != nil {
+		return nil, err
+	}
+
+	aead, ok := k.aeads[keyID]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownKey, keyID)
+	}
+
+	sealed, err := base64.StdEncoding.DecodeString(payload)
+	if err != nil || len(sealed) < aead.NonceSize() {
+		return nil, ErrInvalidCiphertext
+	}
+
+	nonce, data := sealed[:aead.NonceSize()], sealed[aead.NonceSize():]
+	plaintext, err := aead.Open(nil, nonce, data, nil)
+	if err != nil {
+		return nil, ErrInvalidCiphertext
+	}
+	return plaintext, nil
+}
+
+// NeedsRotation 密文是否使用的不是当前密钥（需要重新加密）
+func (k *Keyring) NeedsRotation(ciphertext string) bool {
+	keyID, _, err := parseCiphertext(ciphertext)
+	return err == nil && keyID != k.activeKey
+}
+
+// BlindIndex 计算盲索引（HMAC-SHA256），用于对加密字段做等值查询
+func (k *Keyring) BlindIndex(value string) (string, error) {
+	if len(k.blindIndex) == 0 {
+		return "", fmt.Errorf("blind index key not configured")
+	}
+	mac := hmac.New(sha256.New, k.blindIndex)
+	mac.Write([]byte(value))
+	return hex.EncodeToString(mac.Sum(nil)), nil
+}
+
+// parseCiphertext 解析密文，返回 keyID 和 base64 数据
+func parseCiphertext(ciphertext string) (string, string, error) {
+	if !strings.HasPrefix(ciphertext, ciphertextPrefix) {
+		return "", "", ErrInvalidCiphertext
+	}
+	keyID, payload, ok := strings.Cut(strings.TrimPrefix(ciphertext, ciphertextPrefix), ":")
+	if !ok || keyID == "" {
+		return "", "", ErrInvalidCiphertext
+	}
+	return keyID, payload, nil
+}
+
+// EncryptedSerializer AES-GCM 字段序列化器
+//
+// string / []byte 字段直接加密，其他类型先 JSON 编码再加密；数据库中保存为字符串
+type EncryptedSerializer struct {
+	Keyring *Keyring
+}
+
+// Scan 实现 schema.SerializerInterface
+func (s *EncryptedSerializer) Scan(ctx context.Context, field *schema.Field, dst reflect.Value, dbValue interface{}) error {
+	fieldValue := reflect.New(field.FieldType)
+
+	if dbValue != nil {
+		var ciphertext string
+		switch v := dbValue.(type) {
+		case string:
+			ciphertext = v
+		case []byte:
+			ciphertext = string(v)
+		default:
+			return fmt.Errorf("failed to decrypt %s: unsupported db value %T", field.Name, dbValue)
+		}
+
+		if ciphertext != "" {
+			plaintext, err := s.Keyring.Decrypt(ciphertext)
+			if err != nil {
+				return fmt.Errorf("failed to decrypt %s: %w", field.Name, err)
+			}
+			if err := decodePlaintext(plaintext, fieldValue); err != nil {
+				return fmt.Errorf("failed to decode %s: %w", field.Name, err)
+			}
+		}
+	}
+
+	field.ReflectValueOf(ctx, dst).Set(fieldValue.Elem())
+	return nil
+}
+
+// Value 实现 schema.SerializerValuerInterface
+func (s *EncryptedSerializer) Value(ctx context.Context, field *schema.Field, dst reflect.Value, fieldValue interface{}) (interface{}, error) {
+	rv := reflect.ValueOf(fieldValue)
+	if fieldValue == nil || (rv.Kind() == reflect.Ptr && rv.IsNil()) {
+		return nil, nil
+	}
+
+	var plaintext []byte
+	switch v := fieldValue.(type) {
+	case string:
+		plaintext = []byte(v)
+	case []byte:
+		plaintext = v
+	default:
+		data, err := json.Marshal(fieldValue)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode %s: %w", field.Name, err)
+		}
+		plaintext = data
+	}
+
+	return s.Keyring.Encrypt(plaintext)
+}
+
+// decodePlaintext 将明文写入字段（fieldValue 为指向字段类型的指针）
+func decodePlaintext(plaintext []byte, fieldValue reflect.Value) error {
+	elem := fieldValue.Elem()
+	switch {
+	case elem.Kind() == reflect.String:
+		elem.SetString(string(plaintext))
+		return nil
+	case elem.Kind() == reflect.Slice && elem.Type().Elem().Kind() == reflect.Uint8:
+		elem.SetBytes(plaintext)
+		return nil
+	default:
+		return json.Unmarshal(plaintext, fieldValue.Interface())
+	}
+}
+
+// setupEncryption 注册加密序列化器
+// 注意：GORM 的 serializer 是全局注册的，多个 Client 配置不同密钥时以最后一个为准
+func (c *Client) setupEncryption(cfg *Config) error {
+	if cfg.encryption == nil {
+		return nil
+	}
+
+	keyring, err := NewKeyring(*cfg.encryption)
+	if err != nil {
+		return err
+	}
+
+	c.keyring = keyring
+	schema.RegisterSerializer(EncryptedSerializerName, &EncryptedSerializer{Keyring: keyring})
+	return nil
+}
+
+// Keyring 获取字段加密密钥环（未配置加密时返回 nil）
+func (c *Client) Keyring() *Keyring {
+	return c.keyring
+}
+
+// BlindIndex 计算盲索引
+// 用法：写入时保存 PhoneIndex = client.BlindIndex(phone)，查询时 Where("phone_index = ?", client.BlindIndex(phone))
+func (c *Client) BlindIndex(value string) (string, error) {
+	if c.keyring == nil {
+		return "", fmt.Errorf("encryption not configured")
+	}
+	return c.keyring.BlindIndex(value)
+}
+
+// WhereBlindIndex 按盲索引等值查询的 Scope
+func (c *Client) WhereBlindIndex(column, value string) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		index, err := c.BlindIndex(value)
+		if err != nil {
+			db.AddError(err)
+			return db
+		}
+		return db.Where(column+" = ?", index)
+	}
+}
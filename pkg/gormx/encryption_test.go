@@ -0,0 +1,95 @@
+package gormx
+
+import (
+	"encoding/base64"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func testKey(b byte) string {
+	return base64.StdEncoding.EncodeToString([]byte(strings.Repeat(string(b), 32)))
+}
+
+func TestKeyring_EncryptDecrypt(t *testing.T) {
+	k, err := NewKeyring(EncryptionConfig{
+		Keys:          map[string]string{"k1": testKey('a')},
+		ActiveKey:     "k1",
+		BlindIndexKey: testKey('z'),
+	})
+	if err != nil {
+		t.Fatalf("NewKeyring failed: %v", err)
+	}
+
+	ciphertext, err := k.Encrypt([]byte("13800138000"))
+	if err != nil {
+		t.Fatalf("Encrypt failed: %v", err)
+	}
+	if !strings.HasPrefix(ciphertext, "enc:v1:k1:") {
+		t.Errorf("unexpected ciphertext format: %s", ciphertext)
+	}
+
+	plaintext, err := k.Decrypt(ciphertext)
+	if err != nil || string(plaintext) != "13800138000" {
+		t.Fatalf("Decrypt got %q, %v", plaintext, err)
+	}
+
+	// 相同明文每次加密结果不同，但盲索引相同
+	other, _ := k.Encrypt([]byte("13800138000"))
+	if other == ciphertext {
+		t.Error("expected random nonce")
+	}
+	i1, _ := k.BlindIndex("13800138000")
+	i2, _ := k.BlindIndex("13800138000")
+	if i1 == "" || i1 != i2 {
+		t.Errorf("blind index not deterministic: %s vs %s", i1, i2)
+	}
+
+	if _, err := k.Decrypt(ciphertext[:len(ciphertext)-4] + "AAAA"); !errors.Is(err, ErrInvalidCiphertext) {
+		t.Errorf("expected ErrInvalidCiphertext, got %v", err)
+	}
+}
+
+func TestKeyring_RejectsBlindIndexKeyReuse(t *testing.T) {
+	// 盲索引密钥与任一加密密钥（包括非当前密钥）相同时拒绝
+	_, err := NewKeyring(EncryptionConfig{
+		Keys:          map[string]string{"k1": testKey('a'), "k2": testKey('b')},
+		ActiveKey:     "k2",
+		BlindIndexKey: testKey('a'),
+	})
+	if err == nil || !strings.Contains(err.Error(), "k1") {
+		t.Errorf("expected blind index key reuse to be rejected, got %v", err)
+	}
+}
+
+func TestKeyring_Rotation(t *testing.T) {
+	old, _ := NewKeyring(EncryptionConfig{
+		Keys:      map[string]string{"k1": testKey('a')},
+		ActiveKey: "k1",
+	})
+	ciphertext, _ := old.Encrypt([]byte("secret"))
+
+	rotated, err := NewKeyring(EncryptionConfig{
+		Keys:      map[string]string{"k1": testKey('a'), "k2": testKey('b')},
+		ActiveKey: "k2",
+	})
+	if err != nil {
+		t.Fatalf("NewKeyring failed: %v", err)
+	}
+
+	plaintext, err := rotated.Decrypt(ciphertext)
+	if err != nil || string(plaintext) != "secret" {
+		t.Fatalf("failed to decrypt with old key: %q, %v", plaintext, err)
+	}
+	if !rotated.NeedsRotation(ciphertext) {
+		t.Error("expected old ciphertext to need rotation")
+	}
+
+	fresh, _ := rotated.Encrypt(plaintext)
+	if rotated.NeedsRotation(fresh) {
+		t.Error("expected new ciphertext to use active key")
+	}
+	if _, err := old.Decrypt(fresh); !errors.Is(err, ErrUnknownKey) {
+		t.Errorf("expected ErrUnknownKey, got %v", err)
+	}
+}
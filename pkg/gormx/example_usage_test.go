@@ -1,6 +1,7 @@
 package gormx_test
 
 import (
+	"errors"
 	"time"
 
 	"github.com/tedwangl/go-util/pkg/gormx"
@@ -159,12 +160,43 @@ func Example_customConfig() {
 	defer client.Close()
 }
 
+// Example_optimisticLock 场景 7：乐观锁并发更新
+func Example_optimisticLock() {
+	cfg := gormx.NewConfig(
+		"mysql",
+		"root:password@tcp(db.local:3306)/myapp?charset=utf8mb4",
+	)
+
+	client, _ := gormx.NewClient(cfg)
+	defer client.Close()
+
+	var account Account
+	client.DB.First(&account, 1)
+
+	// UPDATE accounts SET balance=?, version=version+1 WHERE id=1 AND version=?
+	err := gormx.UpdateWithVersion(client.DB, &account, map[string]any{"balance": account.Balance - 100})
+	if errors.Is(err, gormx.ErrStaleObject) {
+		// 冲突：自动重试，每次基于最新数据计算更新内容
+		_ = gormx.UpdateWithVersionRetry(client.DB, &account, 3, func(model any) (map[string]any, error) {
+			a := model.(*Account)
+			return map[string]any{"balance": a.Balance - 100}, nil
+		})
+	}
+}
+
 // User 示例模型
 type User struct {
 	ID   int64
 	Name string
 }
 
+// Account 示例模型（带乐观锁版本号）
+type Account struct {
+	ID      int64
+	Balance int64
+	gormx.VersionField
+}
+
 // Order 示例模型
 type Order struct {
 	ID     int64
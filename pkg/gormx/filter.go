@@ -0,0 +1,328 @@
+package gormx
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ErrInvalidFilter 过滤条件不合法（字段不允许、操作符不支持或值无法转换），HTTP 处理器可以据此返回 400
+var ErrInvalidFilter = errors.New("gormx: invalid filter")
+
+// 过滤操作符
+const (
+	OpEq      = "eq"
+	OpNe      = "ne"
+	OpGt      = "gt"
+	OpGte     = "gte"
+	OpLt      = "lt"
+	OpLte     = "lte"
+	OpLike    = "like"    // 包含（自动转义 % 和 _）
+	OpIn      = "in"      // 值为切片或逗号分隔的字符串
+	OpBetween = "between" // 值为两个元素的切片或 "a,b"（闭区间）
+)
+
+// 字段类型，过滤值会转换为对应的类型
+const (
+	FieldString = "string"
+	FieldInt    = "int"
+	FieldFloat  = "float"
+	FieldBool   = "bool"
+	FieldTime   = "time"
+)
+
+// maxFilterValues in 条件最多的值数量
+const maxFilterValues = 1000
+
+// likeEscape LIKE 的转义字符（mysql、postgres、sqlite 都支持 ESCAPE '!'）
+const likeEscape = "!"
+
+// Filter 过滤条件
+type Filter struct {
+	Field string `json:"field"`
+	Op    string `json:"op"`
+	Value any    `json:"value"`
+}
+
+// FilterField 允许过滤的字段
+type FilterField struct {
+	// 数据库列名（可以带表名，如 users.name），为空时使用字段名
+	Column string
+	// 字段类型，默认为 FieldString
+	Type string
+	// 允许的操作符，为空时允许所有操作符
+	Ops []string
+}
+
+// FilterFields 允许过滤的字段（白名单），key 为 API 中的字段名
+type FilterFields map[string]FilterField
+
+// ApplyFilters 将过滤条件转换为 WHERE 条件（AND），只允许 fields 中的字段
+//
+// 列名使用 clause.Column 引用，值全部作为参数传递，不拼接 SQL；
+// 条件不合法时返回 ErrInvalidFilter，用法：
+//
+//	fields := gormx.FilterFields{
+//	    "name":       {Ops: []string{gormx.OpEq, gormx.OpLike}},
+//	    "age":        {Type: gormx.FieldInt},
+//	    "created_at": {Type: gormx.FieldTime, Ops: []string{gormx.OpGte, gormx.OpLte, gormx.OpBetween}},
+//	}
+//	db, err := gormx.ApplyFilters(client.DB.Model(&User{}), gormx.ParseFilters(r.URL.Query()), fields)
+func ApplyFilters(db *gorm.DB, filters []Filter, fields FilterFields) (*gorm.DB, error) {
+	for _, f := range filters {
+		expr, err := fields.expression(f)
+		if err != nil {
+			return db, err
+		}
+		db = db.Where(expr)
+	}
+	return db, nil
+}
+
+// Filters 返回 ApplyFilters 的 Scope 版本，条件不合法时错误写入 db.Error
+func Filters(filters []Filter, fields FilterFields) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		db, err := ApplyFilters(db, filters, fields)
+		if err != nil {
+			db.AddError(err)
+		}
+		return db
+	}
+}
+
+// ParseFilters 从查询参数解析过滤条件：
+//
+//	?name=tom              → name eq tom
+//	?age[gte]=18           → age gte 18
+//	?status[in]=1,2,3      → status in (1, 2, 3)
+//	?created_at[between]=2024-01-01,2024-12-31
+//
+// 不校验字段和操作符，由 ApplyFilters 按白名单检查；分页、排序等参数需要调用方先移除或用白名单忽略
+func ParseFilters(values url.Values) []Filter {
+	var filters []Filter
+	for key, vals := range values {
+		field, op := key, OpEq
+		if i := strings.IndexByte(key, '['); i > 0 && strings.HasSuffix(key, "]") {
+			field, op = key[:i], strings.ToLower(key[i+1:len(key)-1])
+		}
+		for _, v := range vals {
+			filters = append(filters, Filter{Field: field, Op: op, Value: v})
+		}
+	}
+	// url.Values 是 map，排序后生成的 SQL 才稳定
+	sortFilters(filters)
+	return filters
+}
+
+// Allowed 从 filters 中去掉白名单以外的字段（忽略分页等参数时使用）
+func (fields FilterFields) Allowed(filters []Filter) []Filter {
+	allowed := make([]Filter, 0, len(filters))
+	for _, f := range filters {
+		if _, ok := fields[f.Field]; ok {
+			allowed = append(allowed, f)
+		}
+	}
+	return allowed
+}
+
+// expression 将过滤条件转换为 SQL 表达式
+func (fields FilterFields) expression(f Filter) (clause.Expression, error) {
+	def, ok := fields[f.Field]
+	if !ok {
+		return nil, fmt.Errorf("%w: field %q is not filterable", ErrInvalidFilter, f.Field)
+	}
+	op := f.Op
+	if op == "" {
+		op = OpEq
+	}
+	if len(def.Ops) > 0 && !slices.Contains(def.Ops, op) {
+		return nil, fmt.Errorf("%w: operator %q is not allowed on %q", ErrInvalidFilter, op, f.Field)
+	}
+
+	column := filterColumn(f.Field, def.Column)
+	switch op {
+	case OpEq, OpNe, OpGt, OpGte, OpLt, OpLte:
+		v, err := coerceFilterValue(f, def.Type, f.Value)
+		if err != nil {
+			return nil, err
+		}
+		switch op {
+		case OpEq:
+			return clause.Eq{Column: column, Value: v}, nil
+		case OpNe:
+			return clause.Neq{Column: column, Value: v}, nil
+		case OpGt:
+			return clause.Gt{Column: column, Value: v}, nil
+		case OpGte:
+			return clause.Gte{Column: column, Value: v}, nil
+		case OpLt:
+			return clause.Lt{Column: column, Value: v}, nil
+		default:
+			return clause.Lte{Column: column, Value: v}, nil
+		}
+
+	case OpLike:
+		if def.Type != "" && def.Type != FieldString {
+			return nil, fmt.Errorf("%w: operator like requires a string field, %q is %s", ErrInvalidFilter, f.Field, def.Type)
+		}
+		pattern := "%" + escapeLike(fmt.Sprint(f.Value)) + "%"
+		return clause.Expr{SQL: "? LIKE ? ESCAPE '" + likeEscape + "'", Vars: []any{column, pattern}}, nil
+
+	case OpIn:
+		raw := filterValues(f.Value)
+		if len(raw) == 0 {
+			return nil, fmt.Errorf("%w: operator in on %q requires at least one value", ErrInvalidFilter, f.Field)
+		}
+		if len(raw) > maxFilterValues {
+			return nil, fmt.Errorf("%w: operator in on %q has more than %d values", ErrInvalidFilter, f.Field, maxFilterValues)
+		}
+		values := make([]any, len(raw))
+		for i, r := range raw {
+			v, err := coerceFilterValue(f, def.Type, r)
+			if err != nil {
+				return nil, err
+			}
+			values[i] = v
+		}
+		return clause.IN{Column: column, Values: values}, nil
+
+	case OpBetween:
+		raw := filterValues(f.Value)
+		if len(raw) != 2 {
+			return nil, fmt.Errorf("%w: operator between on %q requires two values", ErrInvalidFilter, f.Field)
+		}
+		low, err := coerceFilterValue(f, def.Type, raw[0])
+		if err != nil {
+			return nil, err
+		}
+		high, err := coerceFilterValue(f, def.Type, raw[1])
+		if err != nil {
+			return nil, err
+		}
+		return clause.Expr{SQL: "? BETWEEN ? AND ?", Vars: []any{column, low, high}}, nil
+
+	default:
+		return nil, fmt.Errorf("%w: unknown operator %q", ErrInvalidFilter, op)
+	}
+}
+
+// filterColumn 返回列引用，列名中的表名单独引用
+func filterColumn(field, column string) clause.Column {
+	if column == "" {
+		column = field
+	}
+	if table, name, ok := strings.Cut(column, "."); ok {
+		return clause.Column{Table: table, Name: name}
+	}
+	return clause.Column{Name: column}
+}
+
+// filterValues 将 in/between 的值展开：切片按元素，字符串按逗号拆分
+func filterValues(value any) []any {
+	switch v := value.(type) {
+	case nil:
+		return nil
+	case string:
+		if v == "" {
+			return nil
+		}
+		parts := strings.Split(v, ",")
+		values := make([]any, len(parts))
+		for i, p := range parts {
+			values[i] = strings.TrimSpace(p)
+		}
+		return values
+	}
+
+	rv := reflect.ValueOf(value)
+	if rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array {
+		return []any{value}
+	}
+	values := make([]any, rv.Len())
+	for i := range values {
+		values[i] = rv.Index(i).Interface()
+	}
+	return values
+}
+
+// coerceFilterValue 将过滤值转换为字段类型（查询参数是字符串，JSON 中的数字是 float64）
+func coerceFilterValue(f Filter, typ string, value any) (any, error) {
+	if value == nil {
+		return nil, fmt.Errorf("%w: %q has no value", ErrInvalidFilter, f.Field)
+	}
+	s := strings.TrimSpace(fmt.Sprint(value))
+
+	var (
+		v   any
+		err error
+	)
+	switch typ {
+	case "", FieldString:
+		return fmt.Sprint(value), nil
+	case FieldInt:
+		if n, ok := value.(float64); ok && n == float64(int64(n)) {
+			return int64(n), nil
+		}
+		v, err = strconv.ParseInt(s, 10, 64)
+	case FieldFloat:
+		v, err = strconv.ParseFloat(s, 64)
+	case FieldBool:
+		v, err = strconv.ParseBool(s)
+	case FieldTime:
+		if t, ok := value.(time.Time); ok {
+			return t, nil
+		}
+		v, err = parseFilterTime(s)
+	default:
+		return nil, fmt.Errorf("%w: unknown field type %q for %q", ErrInvalidFilter, typ, f.Field)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: %q is not a valid %s for %q", ErrInvalidFilter, s, typ, f.Field)
+	}
+	return v, nil
+}
+
+// filterTimeLayouts 支持的时间格式
+var filterTimeLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02 15:04:05",
+	"2006-01-02T15:04:05",
+	"2006-01-02",
+}
+
+// parseFilterTime 解析时间（不带时区时使用本地时区），也支持 Unix 时间戳（秒）
+func parseFilterTime(s string) (time.Time, error) {
+	for _, layout := range filterTimeLayouts {
+		if t, err := time.ParseInLocation(layout, s, time.Local); err == nil {
+			return t, nil
+		}
+	}
+	if sec, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return time.Unix(sec, 0), nil
+	}
+	return time.Time{}, fmt.Errorf("invalid time %q", s)
+}
+
+// escapeLike 转义 LIKE 中的通配符
+func escapeLike(s string) string {
+	r := strings.NewReplacer(likeEscape, likeEscape+likeEscape, "%", likeEscape+"%", "_", likeEscape+"_")
+	return r.Replace(s)
+}
+
+// sortFilters 按字段、操作符排序
+func sortFilters(filters []Filter) {
+	slices.SortStableFunc(filters, func(a, b Filter) int {
+		if c := strings.Compare(a.Field, b.Field); c != 0 {
+			return c
+		}
+		return strings.Compare(a.Op, b.Op)
+	})
+}
@@ -0,0 +1,86 @@
+package gormx
+
+import (
+	"errors"
+	"net/url"
+	"reflect"
+	"testing"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/utils/tests"
+)
+
+type filterUser struct {
+	ID   int64
+	Name string
+	Age  int
+}
+
+func filterSQL(t *testing.T, filters []Filter, fields FilterFields) (string, []any) {
+	t.Helper()
+	db, err := gorm.Open(tests.DummyDialector{}, &gorm.Config{DryRun: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	q, err := ApplyFilters(db.Model(&filterUser{}), filters, fields)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stmt := q.Find(&[]filterUser{}).Statement
+	return stmt.SQL.String(), stmt.Vars
+}
+
+func TestApplyFilters_BuildsParameterizedSQL(t *testing.T) {
+	fields := FilterFields{
+		"name":   {Ops: []string{OpEq, OpLike}},
+		"age":    {Type: FieldInt},
+		"status": {Column: "users.status", Type: FieldInt},
+	}
+	filters := ParseFilters(url.Values{
+		"name[like]":   {"50%_off"},
+		"age[between]": {"18,30"},
+		"status[in]":   {"1,2"},
+	})
+
+	sql, vars := filterSQL(t, filters, fields)
+	want := "SELECT * FROM `filter_users` WHERE (`age` BETWEEN ? AND ?) AND `name` LIKE ? ESCAPE '!' AND `users`.`status` IN (?,?)"
+	if sql != want {
+		t.Errorf("sql =\n%s\nwant\n%s", sql, want)
+	}
+	if wantVars := []any{int64(18), int64(30), "%50!%!_off%", int64(1), int64(2)}; !reflect.DeepEqual(vars, wantVars) {
+		t.Errorf("vars = %#v, want %#v", vars, wantVars)
+	}
+}
+
+func TestApplyFilters_JSONValues(t *testing.T) {
+	fields := FilterFields{"age": {Type: FieldInt}}
+
+	// JSON 解码后数字为 float64，切片为 []any
+	_, vars := filterSQL(t, []Filter{{Field: "age", Op: OpIn, Value: []any{float64(1), "2"}}}, fields)
+	if want := []any{int64(1), int64(2)}; !reflect.DeepEqual(vars, want) {
+		t.Errorf("vars = %#v, want %#v", vars, want)
+	}
+}
+
+func TestApplyFilters_Rejects(t *testing.T) {
+	fields := FilterFields{
+		"name": {Ops: []string{OpEq}},
+		"age":  {Type: FieldInt},
+	}
+	cases := []Filter{
+		{Field: "password", Op: OpEq, Value: "x"},   // 不在白名单
+		{Field: "name", Op: OpLike, Value: "x"},     // 操作符不允许
+		{Field: "age", Op: OpGt, Value: "1 OR 1=1"}, // 类型转换失败
+		{Field: "age", Op: OpLike, Value: "1"},      // like 只用于字符串
+		{Field: "age", Op: OpBetween, Value: "1"},   // between 需要两个值
+		{Field: "age", Op: "regexp", Value: ".*"},   // 未知操作符
+		{Field: "name; DROP TABLE users", Op: OpEq, Value: "x"},
+	}
+
+	for _, f := range cases {
+		if _, err := ApplyFilters(&gorm.DB{}, []Filter{f}, fields); !errors.Is(err, ErrInvalidFilter) {
+			t.Errorf("%+v: expected ErrInvalidFilter, got %v", f, err)
+		}
+	}
+}
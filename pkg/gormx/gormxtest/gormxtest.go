@@ -0,0 +1,140 @@
+// Package gormxtest 提供 gormx 的单元测试工具，不依赖 MySQL 容器
+//
+// NewSQLiteClient 创建内存 SQLite 客户端并自动建表，适合验证真实的读写逻辑：
+//
+//	client := gormxtest.NewSQLiteClient(t, &User{}, &Order{})
+//	repo := NewUserRepo(client)
+//
+// NewMockClient 基于 sqlmock 创建 MySQL 方言的客户端，适合验证生成的 SQL：
+//
+//	client, mock := gormxtest.NewMockClient(t)
+//	mock.ExpectQueryFor(func(tx *gorm.DB) *gorm.DB {
+//	    return tx.Scopes(gormx.Paginate(2, 10)).Find(&[]User{})
+//	}).WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow(1, "tom"))
+package gormxtest
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"gorm.io/gorm"
+
+	"github.com/tedwangl/go-util/pkg/gormx"
+)
+
+// sqliteSeq 区分同一测试中的多个内存数据库
+var sqliteSeq atomic.Int64
+
+// NewSQLiteClient 创建内存 SQLite 客户端并对 models 执行 AutoMigrate，测试结束时自动关闭
+//
+// 每次调用都是独立的数据库，同一客户端的多个连接共享数据
+func NewSQLiteClient(t testing.TB, models ...any) *gormx.Client {
+	t.Helper()
+
+	name := strings.NewReplacer("/", "_", " ", "_").Replace(t.Name())
+	dsn := fmt.Sprintf("file:%s_%d?mode=memory&cache=shared&_foreign_keys=1", name, sqliteSeq.Add(1))
+
+	client, err := gormx.NewClient(testConfig("sqlite", dsn))
+	if err != nil {
+		t.Fatalf("gormxtest: create sqlite client: %v", err)
+	}
+	t.Cleanup(func() { client.Close() })
+
+	if len(models) > 0 {
+		if err := client.AutoMigrate(models...); err != nil {
+			t.Fatalf("gormxtest: auto migrate: %v", err)
+		}
+	}
+	return client
+}
+
+// Mock sqlmock 的封装，增加基于 gorm 查询生成期望的方法
+type Mock struct {
+	sqlmock.Sqlmock
+	db *gorm.DB
+}
+
+// NewMockClient 创建基于 sqlmock 的 MySQL 客户端，测试结束时检查所有期望都已满足
+//
+// SQL 使用精确匹配（sqlmock.QueryMatcherEqual），可以用 ExpectQueryFor/ExpectExecFor
+// 从 gorm 查询生成期望，避免手写 SQL
+func NewMockClient(t testing.TB) (*gormx.Client, *Mock) {
+	t.Helper()
+
+	conn, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	if err != nil {
+		t.Fatalf("gormxtest: create sqlmock: %v", err)
+	}
+	// gorm mysql 驱动初始化时查询版本号
+	mock.ExpectQuery("SELECT VERSION()").WillReturnRows(sqlmock.NewRows([]string{"VERSION()"}).AddRow("8.0.36"))
+
+	client, err := gormx.NewClient(testConfig("mysql", "").WithConn(conn))
+	if err != nil {
+		t.Fatalf("gormxtest: create mock client: %v", err)
+	}
+	t.Cleanup(func() {
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Errorf("gormxtest: %v", err)
+		}
+		client.Close()
+	})
+
+	return client, &Mock{Sqlmock: mock, db: client.DB}
+}
+
+// SQL 以 DryRun 模式执行 query，返回生成的 SQL 和参数（不访问数据库）
+func (m *Mock) SQL(query func(tx *gorm.DB) *gorm.DB) (string, []any) {
+	stmt := query(m.db.Session(&gorm.Session{DryRun: true, NewDB: true, SkipDefaultTransaction: true})).Statement
+	return stmt.SQL.String(), stmt.Vars
+}
+
+// ExpectQueryFor 期望执行 query 生成的查询（SQL 和参数都要一致），返回的期望需要设置 WillReturnRows
+func (m *Mock) ExpectQueryFor(query func(tx *gorm.DB) *gorm.DB) *sqlmock.ExpectedQuery {
+	sql, vars := m.SQL(query)
+	return m.ExpectQuery(sql).WithArgs(expectedArgs(vars)...)
+}
+
+// ExpectExecFor 期望在事务中执行 query 生成的写操作（gorm 默认为 Create/Update/Delete 开启事务）
+//
+// 依次添加 Begin、Exec、Commit 期望，Exec 默认影响 1 行，可以用 WillReturnResult 覆盖
+func (m *Mock) ExpectExecFor(query func(tx *gorm.DB) *gorm.DB) *sqlmock.ExpectedExec {
+	sql, vars := m.SQL(query)
+	m.ExpectBegin()
+	exec := m.ExpectExec(sql).WithArgs(expectedArgs(vars)...).WillReturnResult(sqlmock.NewResult(0, 1))
+	m.ExpectCommit()
+	return exec
+}
+
+// expectedArgs 将 gorm 参数转换为 sqlmock 参数，时间参数（如自动填充的创建时间）匹配任意值
+func expectedArgs(vars []any) []driver.Value {
+	args := make([]driver.Value, len(vars))
+	for i, v := range vars {
+		switch v.(type) {
+		case time.Time, *time.Time:
+			args[i] = sqlmock.AnyArg()
+		default:
+			args[i] = v
+		}
+	}
+	return args
+}
+
+// testConfig 测试用配置：关闭日志、指标、追踪、健康检查和预编译语句
+func testConfig(driver, dsn string) *gormx.Config {
+	cfg := gormx.NewConfig(driver, dsn)
+	cfg.LogLevel = "silent"
+	cfg.ColorfulLog = false
+	cfg.SlowQueryLimit = 0
+	cfg.DisableMetrics = true
+	cfg.DisableTracing = true
+	cfg.HealthCheckInterval = 0
+	cfg.PrepareStmt = false
+	cfg.MaxLifetime = 0
+	cfg.MaxIdleTime = 0
+	return cfg
+}
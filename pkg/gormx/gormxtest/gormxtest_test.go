@@ -0,0 +1,87 @@
+package gormxtest
+
+import (
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"gorm.io/gorm"
+
+	"github.com/tedwangl/go-util/pkg/gormx"
+)
+
+type testUser struct {
+	ID        int64
+	Name      string
+	CreatedAt time.Time
+	DeletedAt gorm.DeletedAt
+}
+
+func TestNewSQLiteClient(t *testing.T) {
+	client := NewSQLiteClient(t, &testUser{})
+
+	for _, name := range []string{"tom", "jerry", "spike"} {
+		if err := client.Create(&testUser{Name: name}).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := client.Delete(&testUser{}, "name = ?", "spike").Error; err != nil {
+		t.Fatal(err)
+	}
+
+	var users []testUser
+	err := client.Scopes(gormx.OrderByID(true), gormx.Paginate(1, 10)).Find(&users).Error
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(users) != 2 || users[0].Name != "jerry" || users[1].Name != "tom" {
+		t.Fatalf("unexpected users: %+v", users)
+	}
+
+	// 每个客户端都是独立的数据库
+	other := NewSQLiteClient(t, &testUser{})
+	var count int64
+	if err := other.Model(&testUser{}).Count(&count).Error; err != nil || count != 0 {
+		t.Fatalf("expected empty database, got count=%d err=%v", count, err)
+	}
+}
+
+func TestNewMockClient_ExpectQueryFor(t *testing.T) {
+	client, mock := NewMockClient(t)
+
+	mock.ExpectQueryFor(func(tx *gorm.DB) *gorm.DB {
+		return tx.Scopes(gormx.Paginate(2, 10), gormx.OrderByID(true)).Where("name = ?", "tom").Find(&[]testUser{})
+	}).WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow(11, "tom"))
+
+	var users []testUser
+	err := client.Scopes(gormx.Paginate(2, 10), gormx.OrderByID(true)).Where("name = ?", "tom").Find(&users).Error
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(users) != 1 || users[0].ID != 11 {
+		t.Fatalf("unexpected users: %+v", users)
+	}
+}
+
+func TestNewMockClient_ExpectExecFor(t *testing.T) {
+	client, mock := NewMockClient(t)
+
+	sql, vars := mock.SQL(func(tx *gorm.DB) *gorm.DB {
+		return tx.Model(&testUser{}).Where("id = ?", 1).Update("name", "tom")
+	})
+	if sql != "UPDATE `test_users` SET `name`=? WHERE id = ? AND `test_users`.`deleted_at` IS NULL" || len(vars) != 2 {
+		t.Fatalf("unexpected sql: %s %v", sql, vars)
+	}
+
+	mock.ExpectExecFor(func(tx *gorm.DB) *gorm.DB {
+		return tx.Create(&testUser{Name: "tom"})
+	}).WillReturnResult(sqlmock.NewResult(7, 1))
+
+	u := &testUser{Name: "tom"}
+	if err := client.Create(u).Error; err != nil {
+		t.Fatal(err)
+	}
+	if u.ID != 7 {
+		t.Fatalf("expected id 7, got %d", u.ID)
+	}
+}
@@ -0,0 +1,360 @@
+package gormx
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"math/rand"
+	"sync"
+	"time"
+
+	"gorm.io/driver/mysql"
+	"gorm.io/driver/postgres"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+	"gorm.io/plugin/dbresolver"
+)
+
+// 连接角色
+const (
+	RolePrimary = "primary"
+	RoleReplica = "replica"
+)
+
+// HealthStatus 单个连接的健康状态
+type HealthStatus struct {
+	Name      string        `json:"name"`
+	Role      string        `json:"role"`  // primary, replica
+	Shard     int           `json:"shard"` // 分片 ID，非分片模式为 -1
+	Healthy   bool          `json:"healthy"`
+	Failures  int           `json:"failures"` // 连续失败次数
+	LastError string        `json:"last_error,omitempty"`
+	LastCheck time.Time     `json:"last_check"`
+	Latency   time.Duration `json:"latency"`
+	Lag       time.Duration `json:"lag"`     // 从库复制延迟（配置了 MaxReplicaLag 时检测）
+	Lagging   bool          `json:"lagging"` // 延迟超过 MaxReplicaLag，读请求暂时不路由到该从库
+	LagError  string        `json:"lag_error,omitempty"`
+}
+
+// healthTarget 被检查的连接
+type healthTarget struct {
+	status HealthStatus
+	db     *sql.DB
+
+	heartbeatReady bool // 主库的心跳表已创建
+}
+
+// healthMonitor 后台健康检查
+//
+// 定期 Ping 所有主库/从库，从库连续失败 HealthCheckFailures 次或复制延迟超过 MaxReplicaLag
+// 后从 DBResolver 的读池中剔除（由 healthPolicy 过滤），恢复后自动加回
+type healthMonitor struct {
+	mu        sync.RWMutex
+	targets   []*healthTarget
+	byPool    map[gorm.ConnPool]*healthTarget
+	interval  time.Duration
+	threshold int
+
+	driver         string
+	maxLag         time.Duration
+	lagSource      string
+	heartbeatTable string
+
+	stopOnce sync.Once
+	stop     chan struct{}
+	done     chan struct{}
+}
+
+func newHealthMonitor(cfg *Config) *healthMonitor {
+	threshold := cfg.HealthCheckFailures
+	if threshold <= 0 {
+		threshold = 1
+	}
+	lagSource := cfg.ReplicaLagSource
+	if lagSource != LagSourceHeartbeat {
+		lagSource = LagSourceStatus
+	}
+	heartbeatTable := cfg.HeartbeatTable
+	if heartbeatTable == "" {
+		heartbeatTable = DefaultHeartbeatTable
+	}
+	return &healthMonitor{
+		byPool:         make(map[gorm.ConnPool]*healthTarget),
+		interval:       cfg.HealthCheckInterval,
+		threshold:      threshold,
+		driver:         cfg.Driver,
+		maxLag:         cfg.MaxReplicaLag,
+		lagSource:      lagSource,
+		heartbeatTable: heartbeatTable,
+		stop:           make(chan struct{}),
+		done:           make(chan struct{}),
+	}
+}
+
+// add 注册需要检查的连接
+func (m *healthMonitor) add(name, role string, shard int, db *sql.DB) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	t := &healthTarget{
+		status: HealthStatus{Name: name, Role: role, Shard: shard, Healthy: true},
+		db:     db,
+	}
+	m.targets = append(m.targets, t)
+	m.byPool[db] = t
+}
+
+// start 启动后台检查（interval <= 0 时不启动）
+func (m *healthMonitor) start() {
+	if m.interval <= 0 {
+		close(m.done)
+		return
+	}
+
+	go func() {
+		defer close(m.done)
+
+		ticker := time.NewTicker(m.interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-m.stop:
+				return
+			case <-ticker.C:
+				m.checkAll()
+			}
+		}
+	}()
+}
+
+// close 停止后台检查
+func (m *healthMonitor) close() {
+	m.stopOnce.Do(func() {
+		close(m.stop)
+	})
+	<-m.done
+}
+
+// checkAll 检查所有连接（先检查主库，心跳模式下从库读取的是本轮写入的心跳）
+func (m *healthMonitor) checkAll() {
+	m.mu.RLock()
+	targets := make([]*healthTarget, len(m.targets))
+	copy(targets, m.targets)
+	m.mu.RUnlock()
+
+	for _, role := range []string{RolePrimary, RoleReplica} {
+		for _, t := range targets {
+			if t.status.Role == role {
+				m.check(t)
+			}
+		}
+	}
+}
+
+// check 检查单个连接并更新状态
+func (m *healthMonitor) check(t *healthTarget) {
+	timeout := m.interval
+	if timeout <= 0 || timeout > 5*time.Second {
+		timeout = 5 * time.Second
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	start := time.Now()
+	err := t.db.PingContext(ctx)
+	latency := time.Since(start)
+
+	var (
+		lag    time.Duration
+		lagErr error
+	)
+	if err == nil {
+		lag, lagErr = m.checkLag(ctx, t)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	t.status.LastCheck = start
+	t.status.Latency = latency
+	if err != nil {
+		t.status.Failures++
+		t.status.LastError = err.Error()
+		if t.status.Healthy && t.status.Failures >= m.threshold {
+			t.status.Healthy = false
+			log.Printf("gormx: %s %s marked unhealthy: %v", t.status.Role, t.status.Name, err)
+		}
+		return
+	}
+
+	if !t.status.Healthy {
+		log.Printf("gormx: %s %s recovered", t.status.Role, t.status.Name)
+	}
+	t.status.Healthy = true
+	t.status.Failures = 0
+	t.status.LastError = ""
+	m.updateLag(t, lag, lagErr)
+}
+
+// checkLag 主库写入心跳，从库检测复制延迟（MaxReplicaLag 为 0 时不检测）
+func (m *healthMonitor) checkLag(ctx context.Context, t *healthTarget) (time.Duration, error) {
+	if m.maxLag <= 0 {
+		return 0, nil
+	}
+	if t.status.Role == RolePrimary {
+		if m.lagSource == LagSourceHeartbeat {
+			return 0, m.writeHeartbeat(ctx, t)
+		}
+		return 0, nil
+	}
+	return m.measureLag(ctx, t.db)
+}
+
+// updateLag 更新复制延迟状态（调用方持有写锁）
+// 从库停止复制时视为延迟过大；其他检测错误（如没有权限）只记录，不影响路由
+func (m *healthMonitor) updateLag(t *healthTarget, lag time.Duration, err error) {
+	if m.maxLag <= 0 {
+		return
+	}
+
+	lagging := t.status.Lagging
+	prevErr := t.status.LagError
+	stopped := errors.Is(err, errReplicationStopped)
+	switch {
+	case stopped:
+		t.status.LagError = err.Error()
+		lagging = true
+	case err != nil:
+		t.status.LagError = err.Error()
+	case t.status.Role == RoleReplica:
+		t.status.LagError = ""
+		t.status.Lag = lag
+		lagging = lag > m.maxLag
+	default:
+		t.status.LagError = ""
+	}
+
+	switch {
+	case stopped:
+		// 停止时上次的延迟已过时，记录停止原因
+		if t.status.LagError != prevErr {
+			log.Printf("gormx: replica %s %v, reads go to primary", t.status.Name, err)
+		}
+	case lagging == t.status.Lagging:
+	case lagging:
+		log.Printf("gormx: replica %s lagging (%s > %s), reads go to primary", t.status.Name, t.status.Lag, m.maxLag)
+	default:
+		log.Printf("gormx: replica %s caught up (%s)", t.status.Name, t.status.Lag)
+	}
+	t.status.Lagging = lagging
+}
+
+// isHealthy 连接池是否可用：健康且复制延迟未超过阈值（未注册的连接池视为健康）
+func (m *healthMonitor) isHealthy(pool gorm.ConnPool) bool {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	t, ok := m.byPool[pool]
+	return !ok || (t.status.Healthy && !t.status.Lagging)
+}
+
+// snapshot 返回所有连接的状态
+func (m *healthMonitor) snapshot() []HealthStatus {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	result := make([]HealthStatus, len(m.targets))
+	for i, t := range m.targets {
+		result[i] = t.status
+	}
+	return result
+}
+
+// healthPolicy 感知健康状态的负载均衡策略（实现 dbresolver.Policy）
+// 随机选择一个健康且没有延迟的从库；所有从库都不可用时回退到主库
+type healthPolicy struct {
+	monitor  *healthMonitor
+	fallback gorm.ConnPool
+}
+
+// Resolve 实现 dbresolver.Policy
+func (p *healthPolicy) Resolve(connPools []gorm.ConnPool) gorm.ConnPool {
+	healthy := make([]gorm.ConnPool, 0, len(connPools))
+	for _, pool := range connPools {
+		if pool != p.fallback && p.monitor.isHealthy(pool) {
+			healthy = append(healthy, pool)
+		}
+	}
+
+	if len(healthy) == 0 {
+		return p.fallback
+	}
+
+	return healthy[rand.Intn(len(healthy))]
+}
+
+// withHealthPolicy 为读写分离配置健康感知策略
+// 主库追加到从库列表末尾作为兜底（DBResolver 只有一个从库时不会调用 Policy，
+// 追加主库也保证了 Policy 总会被调用）
+func (c *Client) withHealthPolicy(cfg *dbresolver.Config, primary *sql.DB) {
+	cfg.Replicas = append(cfg.Replicas, dialectorFromConn(c.config.Driver, primary))
+	cfg.Policy = &healthPolicy{monitor: c.health, fallback: primary}
+}
+
+// Health 获取所有主库/从库的健康状态
+func (c *Client) Health() []HealthStatus {
+	if c.health == nil {
+		return nil
+	}
+	return c.health.snapshot()
+}
+
+// openConn 创建由 Client 管理的连接池，并注册到健康检查
+// 返回基于该连接池的 Dialector，交给 DBResolver 使用（DBResolver 不会再创建新连接）
+func (c *Client) openConn(name, role string, shard int, dsn string) (*sql.DB, gorm.Dialector, error) {
+	dialector, err := c.createDialector(c.config.Driver, dsn)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	db, err := gorm.Open(dialector, &gorm.Config{
+		Logger:               logger.Discard,
+		DisableAutomaticPing: c.config.DisableAutomaticPing,
+	})
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to connect %s %s: %w", role, name, err)
+	}
+
+	sqlDB, err := db.DB()
+	if err != nil {
+		return nil, nil, err
+	}
+	c.configurePool(sqlDB)
+	c.health.add(name, role, shard, sqlDB)
+
+	return sqlDB, dialectorFromConn(c.config.Driver, sqlDB), nil
+}
+
+// configurePool 应用连接池配置
+func (c *Client) configurePool(sqlDB *sql.DB) {
+	sqlDB.SetMaxOpenConns(c.config.MaxOpenConns)
+	sqlDB.SetMaxIdleConns(c.config.MaxIdleConns)
+	sqlDB.SetConnMaxLifetime(c.config.MaxLifetime)
+	sqlDB.SetConnMaxIdleTime(c.config.MaxIdleTime)
+}
+
+// dialectorFromConn 基于已有连接池创建 Dialector
+func dialectorFromConn(driver string, conn *sql.DB) gorm.Dialector {
+	switch driver {
+	case "postgres":
+		return postgres.New(postgres.Config{Conn: conn})
+	case "sqlite":
+		return &sqlite.Dialector{Conn: conn}
+	default:
+		return mysql.New(mysql.Config{Conn: conn})
+	}
+}
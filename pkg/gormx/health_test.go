@@ -0,0 +1,134 @@
+package gormx
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"gorm.io/gorm"
+)
+
+func TestHealthPolicy_EvictsUnhealthyReplica(t *testing.T) {
+	m := newHealthMonitor(&Config{HealthCheckFailures: 1})
+	primary, replica1, replica2 := &sql.DB{}, &sql.DB{}, &sql.DB{}
+	m.add("primary", RolePrimary, -1, primary)
+	m.add("replica1", RoleReplica, -1, replica1)
+	m.add("replica2", RoleReplica, -1, replica2)
+
+	policy := &healthPolicy{monitor: m, fallback: primary}
+	pools := []gorm.ConnPool{replica1, replica2, primary}
+
+	// replica1 下线：只会选到 replica2
+	m.byPool[replica1].status.Healthy = false
+	for i := 0; i < 50; i++ {
+		if got := policy.Resolve(pools); got != replica2 {
+			t.Fatalf("expected replica2, got %p", got)
+		}
+	}
+
+	// 所有从库下线：回退到主库
+	m.byPool[replica2].status.Healthy = false
+	if got := policy.Resolve(pools); got != primary {
+		t.Fatalf("expected fallback to primary, got %p", got)
+	}
+
+	// 恢复后重新参与负载均衡
+	m.byPool[replica1].status.Healthy = true
+	if got := policy.Resolve(pools); got != replica1 {
+		t.Fatalf("expected recovered replica1, got %p", got)
+	}
+
+	if n := len(m.snapshot()); n != 3 {
+		t.Errorf("expected 3 health targets, got %d", n)
+	}
+}
+
+func TestHealthPolicy_RoutesAroundLaggingReplica(t *testing.T) {
+	m := newHealthMonitor(&Config{MaxReplicaLag: 5 * time.Second})
+	primary, replica := &sql.DB{}, &sql.DB{}
+	m.add("primary", RolePrimary, -1, primary)
+	m.add("replica", RoleReplica, -1, replica)
+	policy := &healthPolicy{monitor: m, fallback: primary}
+	pools := []gorm.ConnPool{replica, primary}
+	target := m.byPool[replica]
+
+	m.updateLag(target, 10*time.Second, nil)
+	if got := policy.Resolve(pools); got != primary {
+		t.Fatalf("expected lagging replica to fall back to primary, got %p", got)
+	}
+	if s := m.snapshot()[1]; !s.Lagging || s.Lag != 10*time.Second {
+		t.Errorf("unexpected status: %+v", s)
+	}
+
+	// 检测失败（如没有权限）保持原状态
+	m.updateLag(target, 0, errors.New("access denied"))
+	if !target.status.Lagging || target.status.LagError == "" {
+		t.Errorf("expected lag error to keep lagging state: %+v", target.status)
+	}
+
+	m.updateLag(target, time.Second, nil)
+	if got := policy.Resolve(pools); got != replica {
+		t.Fatalf("expected caught-up replica, got %p", got)
+	}
+
+	// 复制停止视为延迟过大
+	m.updateLag(target, 0, errReplicationStopped)
+	if got := policy.Resolve(pools); got != primary {
+		t.Fatalf("expected stopped replica to fall back to primary, got %p", got)
+	}
+}
+
+func TestHealthPolicy_ReportsReplicationStopReason(t *testing.T) {
+	m := newHealthMonitor(&Config{MaxReplicaLag: 5 * time.Second})
+	m.add("replica", RoleReplica, -1, &sql.DB{})
+	target := m.targets[0]
+
+	m.updateLag(target, 10*time.Second, nil)
+	if !target.status.Lagging || target.status.LagError != "" {
+		t.Fatalf("expected lagging replica without error: %+v", target.status)
+	}
+
+	// 已延迟的从库停止复制：上报停止原因而不是上次的延迟
+	stopped := fmt.Errorf("%w (IO thread: No, SQL thread: Yes, last IO error: connection refused)", errReplicationStopped)
+	m.updateLag(target, 0, stopped)
+	s := m.snapshot()[0]
+	if !s.Lagging || !strings.Contains(s.LagError, "IO thread: No") || !strings.Contains(s.LagError, "connection refused") {
+		t.Errorf("expected stop reason in status: %+v", s)
+	}
+
+	// 恢复复制后清除停止原因
+	m.updateLag(target, time.Second, nil)
+	if s := m.snapshot()[0]; s.Lagging || s.LagError != "" || s.Lag != time.Second {
+		t.Errorf("expected caught-up replica: %+v", s)
+	}
+}
+
+func TestMySQLLag_ReplicationStopped(t *testing.T) {
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	if err != nil {
+		t.Fatalf("sqlmock: %v", err)
+	}
+	defer db.Close()
+
+	mock.ExpectQuery("SHOW REPLICA STATUS").WillReturnRows(sqlmock.NewRows([]string{
+		"Replica_IO_Running", "Replica_SQL_Running", "Last_IO_Error", "Last_SQL_Error", "Seconds_Behind_Source",
+	}).AddRow("Yes", "No", "", "Error 'Duplicate entry' on query", nil))
+
+	_, err = mysqlLag(context.Background(), db)
+	if !errors.Is(err, errReplicationStopped) {
+		t.Fatalf("expected errReplicationStopped, got %v", err)
+	}
+	for _, want := range []string{"IO thread: Yes", "SQL thread: No", "last SQL error: Error 'Duplicate entry'"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("expected %q in %q", want, err)
+		}
+	}
+	if strings.Contains(err.Error(), "last IO error") {
+		t.Errorf("empty IO error should be omitted: %q", err)
+	}
+}
@@ -0,0 +1,163 @@
+package gormx
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// 复制延迟检测方式
+const (
+	// LagSourceStatus 读取从库的复制状态（mysql: SHOW REPLICA STATUS，postgres: pg_last_xact_replay_timestamp）
+	LagSourceStatus = "status"
+	// LagSourceHeartbeat 定期向主库的心跳表写入时间戳，从库读取后计算延迟
+	// （不需要 REPLICATION CLIENT 权限，也适用于中间件/代理后的从库）
+	LagSourceHeartbeat = "heartbeat"
+)
+
+// DefaultHeartbeatTable 默认心跳表
+const DefaultHeartbeatTable = "gormx_heartbeat"
+
+// errReplicationStopped 从库没有在复制（Seconds_Behind_Source 为 NULL），返回的错误中包含停止原因
+var errReplicationStopped = errors.New("replication is not running")
+
+// writeHeartbeat 在主库写入心跳（首次写入时创建心跳表）
+func (m *healthMonitor) writeHeartbeat(ctx context.Context, t *healthTarget) error {
+	if !t.heartbeatReady {
+		create := fmt.Sprintf("CREATE TABLE IF NOT EXISTS %s (id INT PRIMARY KEY, ts BIGINT NOT NULL)", m.heartbeatTable)
+		if _, err := t.db.ExecContext(ctx, create); err != nil {
+			return fmt.Errorf("failed to create heartbeat table: %w", err)
+		}
+		t.heartbeatReady = true
+	}
+
+	upsert := fmt.Sprintf("INSERT INTO %s (id, ts) VALUES (1, ?) ON CONFLICT (id) DO UPDATE SET ts = excluded.ts", m.heartbeatTable)
+	if m.driver == "mysql" || m.driver == "" {
+		upsert = fmt.Sprintf("INSERT INTO %s (id, ts) VALUES (1, ?) ON DUPLICATE KEY UPDATE ts = VALUES(ts)", m.heartbeatTable)
+	} else if m.driver == "postgres" {
+		upsert = strings.Replace(upsert, "?", "$1", 1)
+	}
+	_, err := t.db.ExecContext(ctx, upsert, time.Now().UnixMilli())
+	return err
+}
+
+// measureLag 检测从库的复制延迟
+func (m *healthMonitor) measureLag(ctx context.Context, db *sql.DB) (time.Duration, error) {
+	if m.lagSource == LagSourceHeartbeat {
+		var ts int64
+		query := fmt.Sprintf("SELECT ts FROM %s WHERE id = 1", m.heartbeatTable)
+		if err := db.QueryRowContext(ctx, query).Scan(&ts); err != nil {
+			return 0, fmt.Errorf("failed to read heartbeat: %w", err)
+		}
+		lag := time.Since(time.UnixMilli(ts))
+		if lag < 0 {
+			lag = 0 // 应用实例之间的时钟误差
+		}
+		return lag, nil
+	}
+
+	switch m.driver {
+	case "postgres":
+		return postgresLag(ctx, db)
+	case "sqlite":
+		return 0, nil
+	default:
+		return mysqlLag(ctx, db)
+	}
+}
+
+// mysqlLag 读取 Seconds_Behind_Source（8.0.22 之前为 SHOW SLAVE STATUS / Seconds_Behind_Master）
+func mysqlLag(ctx context.Context, db *sql.DB) (time.Duration, error) {
+	rows, err := db.QueryContext(ctx, "SHOW REPLICA STATUS")
+	if err != nil {
+		rows, err = db.QueryContext(ctx, "SHOW SLAVE STATUS")
+		if err != nil {
+			return 0, fmt.Errorf("failed to read replica status: %w", err)
+		}
+	}
+	defer rows.Close()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return 0, err
+	}
+	if !rows.Next() {
+		if err := rows.Err(); err != nil {
+			return 0, err
+		}
+		return 0, fmt.Errorf("not a replica")
+	}
+
+	values := make([]sql.NullString, len(columns))
+	ptrs := make([]any, len(columns))
+	for i := range values {
+		ptrs[i] = &values[i]
+	}
+	if err := rows.Scan(ptrs...); err != nil {
+		return 0, err
+	}
+
+	status := make(map[string]sql.NullString, len(columns))
+	for i, col := range columns {
+		status[col] = values[i]
+	}
+
+	for _, col := range []string{"Seconds_Behind_Source", "Seconds_Behind_Master"} {
+		value, ok := status[col]
+		if !ok {
+			continue
+		}
+		if !value.Valid {
+			return 0, fmt.Errorf("%w (%s)", errReplicationStopped, mysqlStopReason(status))
+		}
+		seconds, err := strconv.ParseInt(value.String, 10, 64)
+		if err != nil {
+			return 0, fmt.Errorf("invalid %s: %q", col, value.String)
+		}
+		return time.Duration(seconds) * time.Second, nil
+	}
+	return 0, fmt.Errorf("replica status has no Seconds_Behind_Source column")
+}
+
+// mysqlStopReason 复制停止的原因：IO/SQL 线程状态和最后的错误
+func mysqlStopReason(status map[string]sql.NullString) string {
+	field := func(names ...string) string {
+		for _, name := range names {
+			if v, ok := status[name]; ok && v.Valid {
+				return v.String
+			}
+		}
+		return ""
+	}
+
+	reason := fmt.Sprintf("IO thread: %s, SQL thread: %s",
+		field("Replica_IO_Running", "Slave_IO_Running"), field("Replica_SQL_Running", "Slave_SQL_Running"))
+	if msg := field("Last_IO_Error"); msg != "" {
+		reason += ", last IO error: " + msg
+	}
+	if msg := field("Last_SQL_Error"); msg != "" {
+		reason += ", last SQL error: " + msg
+	}
+	return reason
+}
+
+// postgresLag 根据最后回放的事务时间计算延迟（WAL 已全部回放时为 0，避免主库空闲时误判）
+func postgresLag(ctx context.Context, db *sql.DB) (time.Duration, error) {
+	const query = `SELECT CASE
+		WHEN pg_last_wal_receive_lsn() = pg_last_wal_replay_lsn() THEN 0
+		ELSE EXTRACT(EPOCH FROM now() - pg_last_xact_replay_timestamp())
+	END`
+
+	var seconds sql.NullFloat64
+	if err := db.QueryRowContext(ctx, query).Scan(&seconds); err != nil {
+		return 0, fmt.Errorf("failed to read replica lag: %w", err)
+	}
+	if !seconds.Valid {
+		return 0, fmt.Errorf("%w (no transaction replayed, not in recovery or WAL receiver stopped)", errReplicationStopped)
+	}
+	return time.Duration(seconds.Float64 * float64(time.Second)), nil
+}
@@ -0,0 +1,211 @@
+package gormx
+
+import (
+	"context"
+	"crypto/sha1"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"math"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/plugin/dbresolver"
+)
+
+var (
+	// ErrLockNotAcquired TryAdvisoryLock 时锁已被其他会话持有
+	ErrLockNotAcquired = errors.New("gormx: advisory lock not acquired")
+	// ErrAdvisoryLockUnsupported 当前数据库不支持咨询锁
+	ErrAdvisoryLockUnsupported = errors.New("gormx: advisory lock not supported")
+)
+
+// LockForUpdate 行排他锁 Scope（SELECT ... FOR UPDATE）
+//
+// 需要在事务中使用，锁在事务结束时释放；读写分离时强制走主库。SQLite 不支持行锁，会忽略
+//
+//	client.Transaction(func(tx *gorm.DB) error {
+//	    tx.Scopes(gormx.LockForUpdate).First(&order, id)
+//	    ...
+//	})
+func LockForUpdate(db *gorm.DB) *gorm.DB {
+	return db.Clauses(dbresolver.Write, clause.Locking{Strength: clause.LockingStrengthUpdate})
+}
+
+// LockShare 行共享锁 Scope（SELECT ... FOR SHARE，MySQL 8.0+ / PostgreSQL）
+func LockShare(db *gorm.DB) *gorm.DB {
+	return db.Clauses(dbresolver.Write, clause.Locking{Strength: clause.LockingStrengthShare})
+}
+
+// LockSkipLocked 跳过已被锁定行的排他锁 Scope（FOR UPDATE SKIP LOCKED），用于多个消费者并发领取任务
+//
+//	client.Transaction(func(tx *gorm.DB) error {
+//	    if err := tx.Scopes(gormx.LockSkipLocked).Where("status = ?", "pending").
+//	        Limit(10).Find(&jobs).Error; err != nil {
+//	        return err
+//	    }
+//	    return tx.Model(&Job{}).Where("id IN ?", ids).Update("status", "running").Error
+//	})
+func LockSkipLocked(db *gorm.DB) *gorm.DB {
+	return db.Clauses(dbresolver.Write, clause.Locking{
+		Strength: clause.LockingStrengthUpdate,
+		Options:  clause.LockingOptionsSkipLocked,
+	})
+}
+
+// LockNoWait 行已被锁定时立即报错的排他锁 Scope（FOR UPDATE NOWAIT）
+func LockNoWait(db *gorm.DB) *gorm.DB {
+	return db.Clauses(dbresolver.Write, clause.Locking{
+		Strength: clause.LockingStrengthUpdate,
+		Options:  clause.LockingOptionsNoWait,
+	})
+}
+
+// WithAdvisoryLock 持有咨询锁执行 fn，fn 返回后释放锁
+//
+// 锁和 fn 在同一个事务（同一个连接）中执行，fn 返回错误时事务回滚。
+// 等待锁的时间受 ctx 控制，ctx 没有截止时间时一直等待：
+//   - MySQL：GET_LOCK/RELEASE_LOCK，超过 64 字符的 key 使用哈希值
+//   - PostgreSQL：pg_advisory_xact_lock，key 哈希为 bigint，事务结束时自动释放
+//   - SQLite：只在当前进程内互斥（SQLite 本身只允许单个写入者）
+func WithAdvisoryLock(ctx context.Context, db *gorm.DB, key string, fn func(tx *gorm.DB) error) error {
+	return advisoryLock(ctx, db, key, true, fn)
+}
+
+// TryAdvisoryLock 与 WithAdvisoryLock 相同，但锁已被持有时不等待，直接返回 ErrLockNotAcquired
+func TryAdvisoryLock(ctx context.Context, db *gorm.DB, key string, fn func(tx *gorm.DB) error) error {
+	return advisoryLock(ctx, db, key, false, fn)
+}
+
+func advisoryLock(ctx context.Context, db *gorm.DB, key string, wait bool, fn func(tx *gorm.DB) error) error {
+	if key == "" {
+		return errors.New("gormx: advisory lock key is empty")
+	}
+
+	switch db.Dialector.Name() {
+	case "mysql":
+		return db.WithContext(ctx).Transaction(func(tx *gorm.DB) (err error) {
+			name := mysqlLockName(key)
+			var acquired sql.NullInt64 // 出错时 GET_LOCK 返回 NULL
+			if err := tx.Raw("SELECT GET_LOCK(?, ?)", name, mysqlLockTimeout(ctx, wait)).Scan(&acquired).Error; err != nil {
+				return fmt.Errorf("failed to acquire advisory lock %s: %w", key, err)
+			}
+			if !acquired.Valid {
+				return fmt.Errorf("failed to acquire advisory lock %s: GET_LOCK returned NULL", key)
+			}
+			if acquired.Int64 != 1 {
+				if wait {
+					// 只有等待超时才会返回 0
+					return fmt.Errorf("failed to acquire advisory lock %s: %w", key, context.DeadlineExceeded)
+				}
+				return ErrLockNotAcquired
+			}
+			defer func() {
+				// GET_LOCK 是会话级锁，不随事务释放
+				if releaseErr := tx.Exec("SELECT RELEASE_LOCK(?)", name).Error; releaseErr != nil {
+					err = errors.Join(err, fmt.Errorf("failed to release advisory lock %s: %w", key, releaseErr))
+				}
+			}()
+			return fn(tx)
+		})
+
+	case "postgres":
+		return db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+			id := pgLockID(key)
+			if wait {
+				if err := tx.Exec("SELECT pg_advisory_xact_lock(?)", id).Error; err != nil {
+					return fmt.Errorf("failed to acquire advisory lock %s: %w", key, err)
+				}
+			} else {
+				var acquired bool
+				if err := tx.Raw("SELECT pg_try_advisory_xact_lock(?)", id).Scan(&acquired).Error; err != nil {
+					return fmt.Errorf("failed to acquire advisory lock %s: %w", key, err)
+				}
+				if !acquired {
+					return ErrLockNotAcquired
+				}
+			}
+			return fn(tx)
+		})
+
+	case "sqlite":
+		unlock, err := localLocks.lock(ctx, key, wait)
+		if err != nil {
+			return err
+		}
+		defer unlock()
+		return db.WithContext(ctx).Transaction(fn)
+
+	default:
+		return fmt.Errorf("%w: %s", ErrAdvisoryLockUnsupported, db.Dialector.Name())
+	}
+}
+
+// mysqlLockName MySQL 锁名最长 64 字符，过长时使用 SHA1
+func mysqlLockName(key string) string {
+	if len(key) <= 64 {
+		return key
+	}
+	sum := sha1.Sum([]byte(key))
+	return "gormx:" + hex.EncodeToString(sum[:])
+}
+
+// mysqlLockTimeout GET_LOCK 的等待秒数：不等待为 0，没有截止时间为 -1（一直等待）
+func mysqlLockTimeout(ctx context.Context, wait bool) int {
+	if !wait {
+		return 0
+	}
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		return -1
+	}
+	return int(math.Max(0, math.Ceil(time.Until(deadline).Seconds())))
+}
+
+// pgLockID 将 key 哈希为 PostgreSQL 咨询锁的 bigint
+func pgLockID(key string) int64 {
+	h := fnv.New64a()
+	h.Write([]byte(key))
+	return int64(h.Sum64())
+}
+
+// localLocks SQLite 使用的进程内咨询锁
+var localLocks = &keyedLocks{locks: make(map[string]chan struct{})}
+
+type keyedLocks struct {
+	mu    sync.Mutex
+	locks map[string]chan struct{}
+}
+
+// lock 获取 key 的锁，返回释放函数
+func (l *keyedLocks) lock(ctx context.Context, key string, wait bool) (func(), error) {
+	for {
+		l.mu.Lock()
+		held, ok := l.locks[key]
+		if !ok {
+			ch := make(chan struct{})
+			l.locks[key] = ch
+			l.mu.Unlock()
+			return func() {
+				l.mu.Lock()
+				delete(l.locks, key)
+				l.mu.Unlock()
+				close(ch)
+			}, nil
+		}
+		l.mu.Unlock()
+
+		if !wait {
+			return nil, ErrLockNotAcquired
+		}
+		select {
+		case <-held:
+		case <-ctx.Done():
+			return nil, fmt.Errorf("failed to acquire advisory lock %s: %w", key, ctx.Err())
+		}
+	}
+}
@@ -0,0 +1,183 @@
+package gormx
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"gorm.io/gorm"
+	"gorm.io/gorm/utils/tests"
+)
+
+type lockJob struct {
+	ID     int64
+	Status string
+}
+
+func TestLockScopes_SQL(t *testing.T) {
+	db, err := gorm.Open(tests.DummyDialector{}, &gorm.Config{DryRun: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		scope func(*gorm.DB) *gorm.DB
+		want  string
+	}{
+		{LockForUpdate, "SELECT * FROM `lock_jobs` WHERE status = ? FOR UPDATE"},
+		{LockShare, "SELECT * FROM `lock_jobs` WHERE status = ? FOR SHARE"},
+		{LockSkipLocked, "SELECT * FROM `lock_jobs` WHERE status = ? FOR UPDATE SKIP LOCKED"},
+		{LockNoWait, "SELECT * FROM `lock_jobs` WHERE status = ? FOR UPDATE NOWAIT"},
+	}
+	for _, c := range cases {
+		stmt := db.Scopes(c.scope).Where("status = ?", "pending").Find(&[]lockJob{}).Statement
+		if got := stmt.SQL.String(); got != c.want {
+			t.Errorf("unexpected sql: %s, want %s", got, c.want)
+		}
+	}
+}
+
+func TestWithAdvisoryLock_SQLite(t *testing.T) {
+	cfg := NewConfig("sqlite", fmt.Sprintf("file:%s?mode=memory&cache=shared", t.Name()))
+	cfg.LogLevel = "silent"
+	cfg.DisableMetrics = true
+	cfg.HealthCheckInterval = 0
+	client, err := NewClient(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { client.Close() })
+	if err := client.AutoMigrate(&lockJob{}); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := context.Background()
+	held := make(chan struct{})
+	release := make(chan struct{})
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		WithAdvisoryLock(ctx, client.DB, "claim", func(tx *gorm.DB) error {
+			close(held)
+			<-release
+			return tx.Create(&lockJob{Status: "running"}).Error
+		})
+	}()
+	<-held
+
+	// 锁被持有时 Try 直接返回，等待超时返回 ctx 错误，其他 key 不受影响
+	if err := TryAdvisoryLock(ctx, client.DB, "claim", func(*gorm.DB) error { return nil }); !errors.Is(err, ErrLockNotAcquired) {
+		t.Fatalf("expected ErrLockNotAcquired, got %v", err)
+	}
+	timeoutCtx, cancel := context.WithTimeout(ctx, 20*time.Millisecond)
+	defer cancel()
+	if err := WithAdvisoryLock(timeoutCtx, client.DB, "claim", func(*gorm.DB) error { return nil }); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected deadline exceeded, got %v", err)
+	}
+	if err := TryAdvisoryLock(ctx, client.DB, "other", func(*gorm.DB) error { return nil }); err != nil {
+		t.Fatal(err)
+	}
+
+	close(release)
+	err = WithAdvisoryLock(ctx, client.DB, "claim", func(tx *gorm.DB) error {
+		var count int64
+		if err := tx.Model(&lockJob{}).Count(&count).Error; err != nil {
+			return err
+		}
+		if count != 1 {
+			return fmt.Errorf("expected the first holder to finish, got %d jobs", count)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	wg.Wait()
+
+	// fn 返回错误时回滚
+	fnErr := errors.New("boom")
+	err = WithAdvisoryLock(ctx, client.DB, "claim", func(tx *gorm.DB) error {
+		tx.Create(&lockJob{Status: "pending"})
+		return fnErr
+	})
+	if !errors.Is(err, fnErr) {
+		t.Fatalf("expected fn error, got %v", err)
+	}
+	var count int64
+	client.Model(&lockJob{}).Count(&count)
+	if count != 1 {
+		t.Fatalf("expected rollback, got %d jobs", count)
+	}
+}
+
+func TestMySQLLockHelpers(t *testing.T) {
+	long := fmt.Sprintf("%070d", 1)
+	if name := mysqlLockName(long); len(name) > 64 || name == mysqlLockName(long+"x") {
+		t.Errorf("unexpected hashed lock name %s", name)
+	}
+	if mysqlLockName("job") != "job" {
+		t.Error("short key should be used as is")
+	}
+
+	if got := mysqlLockTimeout(context.Background(), false); got != 0 {
+		t.Errorf("expected 0 for try lock, got %d", got)
+	}
+	if got := mysqlLockTimeout(context.Background(), true); got != -1 {
+		t.Errorf("expected -1 without deadline, got %d", got)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 1500*time.Millisecond)
+	defer cancel()
+	if got := mysqlLockTimeout(ctx, true); got != 2 {
+		t.Errorf("expected 2 seconds, got %d", got)
+	}
+}
+
+func TestWithAdvisoryLock_MySQL(t *testing.T) {
+	conn, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	if err != nil {
+		t.Fatal(err)
+	}
+	mock.ExpectQuery("SELECT VERSION()").WillReturnRows(sqlmock.NewRows([]string{"VERSION()"}).AddRow("8.0.36"))
+	cfg := NewConfig("mysql", "").WithConn(conn)
+	cfg.LogLevel = "silent"
+	cfg.DisableMetrics = true
+	cfg.HealthCheckInterval = 0
+	cfg.PrepareStmt = false
+	client, err := NewClient(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { client.Close() })
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT GET_LOCK(?, ?)").WithArgs("claim", 0).
+		WillReturnRows(sqlmock.NewRows([]string{"lock"}).AddRow(1))
+	mock.ExpectExec("UPDATE jobs SET status = 'running'").WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("SELECT RELEASE_LOCK(?)").WithArgs("claim").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectCommit()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT GET_LOCK(?, ?)").WithArgs("claim", 0).
+		WillReturnRows(sqlmock.NewRows([]string{"lock"}).AddRow(0))
+	mock.ExpectRollback()
+
+	ctx := context.Background()
+	err = TryAdvisoryLock(ctx, client.DB, "claim", func(tx *gorm.DB) error {
+		return tx.Exec("UPDATE jobs SET status = 'running'").Error
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = TryAdvisoryLock(ctx, client.DB, "claim", func(*gorm.DB) error { return nil })
+	if !errors.Is(err, ErrLockNotAcquired) {
+		t.Fatalf("expected ErrLockNotAcquired, got %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+}
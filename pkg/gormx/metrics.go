@@ -0,0 +1,308 @@
+package gormx
+
+import (
+	"errors"
+	"log"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"gorm.io/gorm"
+)
+
+const (
+	metricsPluginName = "gormx:metrics"
+
+	// InstanceSet 中保存开始时间和 span 的 key
+	startTimeKey = "gormx:metrics:start"
+	spanKey      = "gormx:metrics:span"
+
+	tracerName = "github.com/tedwangl/go-util/pkg/gormx"
+)
+
+var (
+	queryDuration = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Namespace: "gormx",
+			Name:      "query_duration_seconds",
+			Help:      "SQL 执行耗时（按表名和操作类型统计）",
+			Buckets:   []float64{0.001, 0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5},
+		},
+		[]string{"table", "operation"},
+	)
+	queryErrors = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "gormx",
+			Name:      "query_errors_total",
+			Help:      "SQL 执行失败次数（不含 RecordNotFound）",
+		},
+		[]string{"table", "operation"},
+	)
+	preparedStmtSize = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Namespace: "gormx",
+			Name:      "prepared_stmts",
+			Help:      "预编译语句缓存中的语句数（统计或对账时更新）",
+		},
+	)
+	preparedStmtHits = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Namespace: "gormx",
+			Name:      "prepared_stmt_hits_total",
+			Help:      "复用缓存中预编译语句的执行次数",
+		},
+	)
+	preparedStmtMisses = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Namespace: "gormx",
+			Name:      "prepared_stmt_misses_total",
+			Help:      "重新预编译语句的次数",
+		},
+	)
+)
+
+// registerCollectors 注册 Prometheus 指标（多个 Client 共享同一组指标，每个 Registerer 只注册一次，重复注册忽略）
+func registerCollectors(registerer prometheus.Registerer) {
+	if registerer == nil {
+		registerer = prometheus.DefaultRegisterer
+	}
+	for _, c := range []prometheus.Collector{queryDuration, queryErrors, preparedStmtSize, preparedStmtHits, preparedStmtMisses} {
+		if err := registerer.Register(c); err != nil {
+			var are prometheus.AlreadyRegisteredError
+			if !errors.As(err, &are) {
+				log.Printf("gormx: failed to register metrics: %v", err)
+			}
+		}
+	}
+}
+
+// SlowQuery 慢查询记录
+type SlowQuery struct {
+	SQL          string        `json:"sql"` // 已绑定参数的 SQL
+	Table        string        `json:"table"`
+	Operation    string        `json:"operation"`
+	Duration     time.Duration `json:"duration"`
+	RowsAffected int64         `json:"rows_affected"`
+	Error        string        `json:"error,omitempty"`
+	Time         time.Time     `json:"time"`
+}
+
+// slowQueryRecorder 保留耗时最长的 N 条慢查询
+type slowQueryRecorder struct {
+	mu      sync.Mutex
+	limit   int
+	queries []SlowQuery // 按耗时降序
+}
+
+func newSlowQueryRecorder(limit int) *slowQueryRecorder {
+	return &slowQueryRecorder{limit: limit}
+}
+
+// record 记录一条慢查询，超出容量时淘汰最快的一条
+func (r *slowQueryRecorder) record(q SlowQuery) {
+	if r.limit <= 0 {
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if len(r.queries) >= r.limit && q.Duration <= r.queries[len(r.queries)-1].Duration {
+		return
+	}
+
+	i := sort.Search(len(r.queries), func(i int) bool {
+		return r.queries[i].Duration < q.Duration
+	})
+	r.queries = append(r.queries, SlowQuery{})
+	copy(r.queries[i+1:], r.queries[i:])
+	r.queries[i] = q
+
+	if len(r.queries) > r.limit {
+		r.queries = r.queries[:r.limit]
+	}
+}
+
+// snapshot 返回慢查询副本（按耗时降序）
+func (r *slowQueryRecorder) snapshot() []SlowQuery {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	result := make([]SlowQuery, len(r.queries))
+	copy(result, r.queries)
+	return result
+}
+
+// reset 清空慢查询记录
+func (r *slowQueryRecorder) reset() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.queries = nil
+}
+
+// metricsPlugin 查询指标、链路追踪、慢查询采集插件
+//
+// 在 GORM 每个操作的前后注册回调：
+// - Prometheus：按表名/操作类型记录耗时直方图和错误数
+// - OpenTelemetry：为每条 SQL 创建 span（父 span 取自 Statement.Context）
+// - 慢查询：耗时超过 SlowThreshold 的 SQL 进入 Top-N 记录
+// - 预编译语句：缓存命中/未命中和闲置的语句
+type metricsPlugin struct {
+	config   *Config
+	tracer   trace.Tracer
+	recorder *slowQueryRecorder
+	stmts    *stmtTracker
+}
+
+func newMetricsPlugin(cfg *Config) *metricsPlugin {
+	if !cfg.DisableMetrics {
+		registerCollectors(cfg.MetricsRegisterer)
+	}
+	return &metricsPlugin{
+		config:   cfg,
+		tracer:   otel.Tracer(tracerName),
+		recorder: newSlowQueryRecorder(cfg.SlowQueryLimit),
+		stmts:    newStmtTracker(cfg),
+	}
+}
+
+// Name 实现 gorm.Plugin
+func (p *metricsPlugin) Name() string {
+	return metricsPluginName
+}
+
+// Initialize 实现 gorm.Plugin
+func (p *metricsPlugin) Initialize(db *gorm.DB) error {
+	cb := db.Callback()
+
+	type registerFunc func(name string, fn func(*gorm.DB)) error
+
+	hooks := []struct {
+		operation string
+		before    registerFunc
+		after     registerFunc
+	}{
+		{"create", cb.Create().Before("gorm:create").Register, cb.Create().After("gorm:create").Register},
+		{"query", cb.Query().Before("gorm:query").Register, cb.Query().After("gorm:query").Register},
+		{"update", cb.Update().Before("gorm:update").Register, cb.Update().After("gorm:update").Register},
+		{"delete", cb.Delete().Before("gorm:delete").Register, cb.Delete().After("gorm:delete").Register},
+		{"row", cb.Row().Before("gorm:row").Register, cb.Row().After("gorm:row").Register},
+		{"raw", cb.Raw().Before("gorm:raw").Register, cb.Raw().After("gorm:raw").Register},
+	}
+
+	for _, h := range hooks {
+		if err := h.before(metricsPluginName+":before_"+h.operation, p.before(h.operation)); err != nil {
+			return err
+		}
+		if err := h.after(metricsPluginName+":after_"+h.operation, p.after(h.operation)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (p *metricsPlugin) before(operation string) func(*gorm.DB) {
+	return func(db *gorm.DB) {
+		db.InstanceSet(startTimeKey, time.Now())
+
+		if p.config.DisableTracing || db.Statement.Context == nil {
+			return
+		}
+
+		ctx, span := p.tracer.Start(db.Statement.Context, "gorm."+operation,
+			trace.WithSpanKind(trace.SpanKindClient),
+		)
+		db.Statement.Context = ctx
+		db.InstanceSet(spanKey, span)
+	}
+}
+
+func (p *metricsPlugin) after(operation string) func(*gorm.DB) {
+	return func(db *gorm.DB) {
+		value, ok := db.InstanceGet(startTimeKey)
+		if !ok {
+			return
+		}
+		start, ok := value.(time.Time)
+		if !ok {
+			return
+		}
+		elapsed := time.Since(start)
+
+		table := db.Statement.Table
+		if table == "" {
+			table = "unknown"
+		}
+
+		// RecordNotFound 不视为错误
+		var queryErr error
+		if db.Error != nil && !errors.Is(db.Error, gorm.ErrRecordNotFound) {
+			queryErr = db.Error
+		}
+
+		if !p.config.DisableMetrics {
+			queryDuration.WithLabelValues(table, operation).Observe(elapsed.Seconds())
+			if queryErr != nil {
+				queryErrors.WithLabelValues(table, operation).Inc()
+			}
+		}
+
+		sql := db.Statement.SQL.String()
+		p.stmts.observe(db, sql)
+
+		if spanValue, ok := db.InstanceGet(spanKey); ok {
+			if span, ok := spanValue.(trace.Span); ok {
+				span.SetAttributes(
+					attribute.String("db.system", p.config.Driver),
+					attribute.String("db.sql.table", table),
+					attribute.String("db.operation", operation),
+					attribute.String("db.statement", sql),
+					attribute.Int64("db.rows_affected", db.RowsAffected),
+				)
+				if queryErr != nil {
+					span.RecordError(queryErr)
+					span.SetStatus(codes.Error, queryErr.Error())
+				}
+				span.End()
+			}
+		}
+
+		if sql == "" || elapsed < p.config.SlowThreshold {
+			return
+		}
+
+		q := SlowQuery{
+			SQL:          db.Dialector.Explain(sql, db.Statement.Vars...),
+			Table:        table,
+			Operation:    operation,
+			Duration:     elapsed,
+			RowsAffected: db.RowsAffected,
+			Time:         start,
+		}
+		if queryErr != nil {
+			q.Error = queryErr.Error()
+		}
+		p.recorder.record(q)
+	}
+}
+
+// SlowQueries 获取耗时最长的慢查询（按耗时降序，最多 Config.SlowQueryLimit 条）
+func (c *Client) SlowQueries() []SlowQuery {
+	if c.metrics == nil {
+		return nil
+	}
+	return c.metrics.recorder.snapshot()
+}
+
+// ResetSlowQueries 清空慢查询记录
+func (c *Client) ResetSlowQueries() {
+	if c.metrics != nil {
+		c.metrics.recorder.reset()
+	}
+}
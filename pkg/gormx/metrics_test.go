@@ -0,0 +1,63 @@
+package gormx
+
+import (
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+func TestSlowQueryRecorder_KeepsSlowest(t *testing.T) {
+	r := newSlowQueryRecorder(3)
+
+	for _, ms := range []int{5, 50, 10, 200, 1, 80} {
+		r.record(SlowQuery{SQL: "SELECT 1", Duration: time.Duration(ms) * time.Millisecond})
+	}
+
+	got := r.snapshot()
+	want := []time.Duration{200 * time.Millisecond, 80 * time.Millisecond, 50 * time.Millisecond}
+	if len(got) != len(want) {
+		t.Fatalf("expected %d slow queries, got %d", len(want), len(got))
+	}
+	for i, d := range want {
+		if got[i].Duration != d {
+			t.Errorf("slow query %d: expected %v, got %v", i, d, got[i].Duration)
+		}
+	}
+
+	r.reset()
+	if len(r.snapshot()) != 0 {
+		t.Error("expected empty recorder after reset")
+	}
+}
+
+func TestSlowQueryRecorder_Disabled(t *testing.T) {
+	r := newSlowQueryRecorder(0)
+	r.record(SlowQuery{Duration: time.Second})
+
+	if len(r.snapshot()) != 0 {
+		t.Error("expected no slow queries when limit is 0")
+	}
+}
+
+func TestRegisterCollectors_PerRegisterer(t *testing.T) {
+	for i := 0; i < 2; i++ {
+		reg := prometheus.NewRegistry()
+		registerCollectors(reg)
+		registerCollectors(reg) // 重复注册忽略
+
+		families, err := reg.Gather()
+		if err != nil {
+			t.Fatal(err)
+		}
+		found := false
+		for _, f := range families {
+			if f.GetName() == "gormx_prepared_stmts" {
+				found = true
+			}
+		}
+		if !found {
+			t.Errorf("registry %d: expected gormx metrics to be registered", i)
+		}
+	}
+}
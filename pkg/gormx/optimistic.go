@@ -0,0 +1,124 @@
+package gormx
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+
+	"gorm.io/gorm"
+)
+
+// ErrStaleObject 乐观锁冲突：记录已被其他请求修改（version 不匹配）
+var ErrStaleObject = errors.New("gormx: stale object, version mismatch")
+
+// VersionField 乐观锁版本号字段（嵌入到模型中使用）
+//
+//	type Account struct {
+//	    ID      int64
+//	    Balance int64
+//	    gormx.VersionField
+//	}
+type VersionField struct {
+	Version int `gorm:"not null;default:0" json:"version"`
+}
+
+// UpdateWithVersion 基于版本号的乐观锁更新
+//
+// model 必须是带主键和 Version int 字段的结构体指针，生成的 SQL：
+//
+//	UPDATE ... SET ..., version = version + 1 WHERE id = ? AND version = ?
+//
+// 没有行被更新时返回 ErrStaleObject；成功后 model.Version 自增 1
+func UpdateWithVersion(db *gorm.DB, model any, updates map[string]any) error {
+	stmt := &gorm.Statement{DB: db}
+	if err := stmt.Parse(model); err != nil {
+		return fmt.Errorf("failed to parse model: %w", err)
+	}
+
+	rv := reflect.ValueOf(model)
+	if rv.Kind() != reflect.Ptr || rv.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("model must be a pointer to struct, got %T", model)
+	}
+	rv = rv.Elem()
+
+	pk := stmt.Schema.PrioritizedPrimaryField
+	if pk == nil {
+		return fmt.Errorf("model %s has no primary key", stmt.Schema.Name)
+	}
+	if _, zero := pk.ValueOf(db.Statement.Context, rv); zero {
+		return fmt.Errorf("model %s primary key is empty", stmt.Schema.Name)
+	}
+
+	versionField := stmt.Schema.LookUpField("Version")
+	if versionField == nil {
+		return fmt.Errorf("model %s has no Version field", stmt.Schema.Name)
+	}
+	value, _ := versionField.ValueOf(db.Statement.Context, rv)
+	version, ok := toInt64(value)
+	if !ok {
+		return fmt.Errorf("model %s Version field must be an integer", stmt.Schema.Name)
+	}
+
+	values := make(map[string]any, len(updates)+1)
+	for k, v := range updates {
+		values[k] = v
+	}
+	values[versionField.DBName] = gorm.Expr(versionField.DBName + " + 1")
+
+	result := db.Model(model).
+		Where(versionField.DBName+" = ?", version).
+		Updates(values)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrStaleObject
+	}
+
+	// Updates 会把 gorm.Expr 回写到模型，这里显式设置为新版本号
+	return versionField.Set(db.Statement.Context, rv, version+1)
+}
+
+// UpdateWithVersionRetry 乐观锁更新（冲突自动重试）
+//
+// 每次尝试前按主键重新加载 model，再调用 fn 基于最新数据计算更新内容，
+// 最多尝试 maxRetries+1 次，全部冲突时返回 ErrStaleObject
+func UpdateWithVersionRetry(db *gorm.DB, model any, maxRetries int, fn func(model any) (map[string]any, error)) error {
+	if maxRetries < 0 {
+		maxRetries = 0
+	}
+
+	var err error
+	for attempt := 0; attempt <= maxRetries; attempt++ {
+		if attempt > 0 {
+			if err := db.First(model).Error; err != nil {
+				return fmt.Errorf("failed to reload model: %w", err)
+			}
+		}
+
+		updates, fnErr := fn(model)
+		if fnErr != nil {
+			return fnErr
+		}
+
+		err = UpdateWithVersion(db, model, updates)
+		if !errors.Is(err, ErrStaleObject) {
+			return err
+		}
+	}
+
+	return err
+}
+
+// toInt64 将整型字段值转为 int64
+func toInt64(value any) (int64, bool) {
+	rv := reflect.ValueOf(value)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return rv.Int(), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return int64(rv.Uint()), true
+	default:
+		return 0, false
+	}
+}
@@ -0,0 +1,128 @@
+package gormx
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"gorm.io/gorm"
+)
+
+type optimisticAccount struct {
+	ID      int64
+	Balance int64
+	VersionField
+}
+
+func newOptimisticDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	cfg := NewConfig("sqlite", fmt.Sprintf("file:%s?mode=memory&cache=shared", t.Name()))
+	cfg.LogLevel = "silent"
+	cfg.DisableMetrics = true
+	cfg.HealthCheckInterval = 0
+
+	client, err := NewClient(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { client.Close() })
+
+	db := client.GetDB()
+	if err := db.AutoMigrate(&optimisticAccount{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Create(&optimisticAccount{ID: 1, Balance: 100}).Error; err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+// bumpVersion 模拟其他请求并发修改记录
+func bumpVersion(t *testing.T, db *gorm.DB) {
+	t.Helper()
+	err := db.Model(&optimisticAccount{}).Where("id = ?", 1).
+		Update("version", gorm.Expr("version + 1")).Error
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestUpdateWithVersion_StaleObject(t *testing.T) {
+	db := newOptimisticDB(t)
+
+	var a, b optimisticAccount
+	db.First(&a, 1)
+	db.First(&b, 1)
+
+	if err := UpdateWithVersion(db, &a, map[string]any{"balance": 90}); err != nil {
+		t.Fatalf("first update: %v", err)
+	}
+	if a.Version != 1 {
+		t.Errorf("expected version 1 after update, got %d", a.Version)
+	}
+
+	// b 持有旧版本号，更新应失败且不覆盖 a 的修改
+	if err := UpdateWithVersion(db, &b, map[string]any{"balance": 80}); !errors.Is(err, ErrStaleObject) {
+		t.Fatalf("expected ErrStaleObject, got %v", err)
+	}
+
+	var got optimisticAccount
+	db.First(&got, 1)
+	if got.Balance != 90 || got.Version != 1 {
+		t.Errorf("unexpected row after stale update: %+v", got)
+	}
+}
+
+func TestUpdateWithVersionRetry_SucceedsAfterConflict(t *testing.T) {
+	db := newOptimisticDB(t)
+
+	var account optimisticAccount
+	db.First(&account, 1)
+
+	calls := 0
+	err := UpdateWithVersionRetry(db, &account, 3, func(model any) (map[string]any, error) {
+		calls++
+		if calls == 1 {
+			bumpVersion(t, db)
+		}
+		return map[string]any{"balance": model.(*optimisticAccount).Balance - 10}, nil
+	})
+	if err != nil {
+		t.Fatalf("expected retry to succeed, got %v", err)
+	}
+	if calls != 2 {
+		t.Errorf("expected 2 attempts, got %d", calls)
+	}
+
+	var got optimisticAccount
+	db.First(&got, 1)
+	if got.Balance != 90 || got.Version != 2 || account.Version != 2 {
+		t.Errorf("unexpected state: row %+v, model %+v", got, account)
+	}
+}
+
+func TestUpdateWithVersionRetry_GivesUp(t *testing.T) {
+	db := newOptimisticDB(t)
+
+	var account optimisticAccount
+	db.First(&account, 1)
+
+	calls := 0
+	err := UpdateWithVersionRetry(db, &account, 2, func(model any) (map[string]any, error) {
+		calls++
+		bumpVersion(t, db)
+		return map[string]any{"balance": 0}, nil
+	})
+	if !errors.Is(err, ErrStaleObject) {
+		t.Fatalf("expected ErrStaleObject, got %v", err)
+	}
+	if calls != 3 {
+		t.Errorf("expected maxRetries+1 = 3 attempts, got %d", calls)
+	}
+
+	var got optimisticAccount
+	db.First(&got, 1)
+	if got.Balance != 100 || got.Version != 3 {
+		t.Errorf("row should only see concurrent bumps: %+v", got)
+	}
+}
@@ -0,0 +1,229 @@
+package gormx
+
+import (
+	"context"
+	"fmt"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// KeyMove 单个分片键的迁移
+type KeyMove struct {
+	Key  any `json:"key"`
+	From int `json:"from"`
+	To   int `json:"to"`
+}
+
+// ReshardPlan 分片数量变更的迁移计划
+//
+// 用法：
+//
+//	plan := gormx.NewReshardPlan(shardingCfg, 4, userIDs)
+//	fmt.Printf("需要迁移 %.1f%% 的 key\n", plan.MoveRatio()*100)
+type ReshardPlan struct {
+	Algorithm     string    `json:"algorithm"`
+	OldShardCount int       `json:"old_shard_count"`
+	NewShardCount int       `json:"new_shard_count"`
+	TotalKeys     int       `json:"total_keys"`
+	Moves         []KeyMove `json:"moves"`
+}
+
+// NewReshardPlan 计算分片数从当前配置变为 newShardCount 时需要迁移的 key
+func NewReshardPlan(sharding ShardingConfig, newShardCount int, keys []any) *ReshardPlan {
+	oldCfg := sharding
+	newCfg := sharding
+	newCfg.ShardCount = newShardCount
+
+	if sharding.Algorithm == ShardAlgorithmConsistentHash {
+		oldCfg.ring = oldCfg.buildRing(oldCfg.logicalShardCount())
+		newCfg.ring = newCfg.buildRing(newShardCount)
+	}
+
+	plan := &ReshardPlan{
+		Algorithm:     sharding.Algorithm,
+		OldShardCount: oldCfg.logicalShardCount(),
+		NewShardCount: newShardCount,
+		TotalKeys:     len(keys),
+	}
+
+	for _, key := range keys {
+		from := oldCfg.shardID(key)
+		to := newCfg.shardID(key)
+		if from != to {
+			plan.Moves = append(plan.Moves, KeyMove{Key: key, From: from, To: to})
+		}
+	}
+
+	return plan
+}
+
+// MoveRatio 需要迁移的 key 占比
+func (p *ReshardPlan) MoveRatio() float64 {
+	if p.TotalKeys == 0 {
+		return 0
+	}
+	return float64(len(p.Moves)) / float64(p.TotalKeys)
+}
+
+// routeKey 迁移路径（源分片 → 目标分片）
+type routeKey struct {
+	from, to int
+}
+
+// groupByRoute 按迁移路径分组
+func (p *ReshardPlan) groupByRoute() map[routeKey][]any {
+	groups := make(map[routeKey][]any)
+	for _, m := range p.Moves {
+		rk := routeKey{from: m.From, to: m.To}
+		groups[rk] = append(groups[rk], m.Key)
+	}
+	return groups
+}
+
+// ReshardTask 按迁移计划搬迁单张表的数据
+//
+// 流程：Copy（复制到新分片）→ Verify（校验行数）→ 切换应用配置 → Cutover（删除旧分片数据）
+type ReshardTask struct {
+	Plan      *ReshardPlan
+	Table     string
+	KeyColumn string // 分片键列名，如 user_id
+	BatchSize int    // 每批处理的 key 数量，默认 500
+
+	// Source 返回旧分片布局下的连接，Target 返回新分片布局下的连接
+	Source func(shardID int) *gorm.DB
+	Target func(shardID int) *gorm.DB
+}
+
+// VerifyResult 校验结果
+type VerifyResult struct {
+	SourceRows int64 `json:"source_rows"`
+	TargetRows int64 `json:"target_rows"`
+}
+
+// OK 源和目标行数一致
+func (r *VerifyResult) OK() bool {
+	return r.SourceRows == r.TargetRows
+}
+
+// Copy 将需要迁移的行复制到目标分片（目标已存在的行跳过，可重复执行）
+func (t *ReshardTask) Copy(ctx context.Context) error {
+	if err := t.validate(); err != nil {
+		return err
+	}
+
+	for rk, keys := range t.Plan.groupByRoute() {
+		err := t.eachBatch(keys, func(batch []any) error {
+			var rows []map[string]any
+			if err := t.Source(rk.from).WithContext(ctx).Table(t.Table).
+				Where(t.KeyColumn+" IN ?", batch).Find(&rows).Error; err != nil {
+				return fmt.Errorf("failed to read shard %d: %w", rk.from, err)
+			}
+			if len(rows) == 0 {
+				return nil
+			}
+
+			if err := t.Target(rk.to).WithContext(ctx).Table(t.Table).
+				Clauses(clause.OnConflict{DoNothing: true}).
+				Create(&rows).Error; err != nil {
+				return fmt.Errorf("failed to write shard %d: %w", rk.to, err)
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Verify 校验迁移 key 在源分片和目标分片的行数
+func (t *ReshardTask) Verify(ctx context.Context) (*VerifyResult, error) {
+	if err := t.validate(); err != nil {
+		return nil, err
+	}
+
+	result := &VerifyResult{}
+	for rk, keys := range t.Plan.groupByRoute() {
+		err := t.eachBatch(keys, func(batch []any) error {
+			var src, dst int64
+			if err := t.Source(rk.from).WithContext(ctx).Table(t.Table).
+				Where(t.KeyColumn+" IN ?", batch).Count(&src).Error; err != nil {
+				return fmt.Errorf("failed to count shard %d: %w", rk.from, err)
+			}
+			if err := t.Target(rk.to).WithContext(ctx).Table(t.Table).
+				Where(t.KeyColumn+" IN ?", batch).Count(&dst).Error; err != nil {
+				return fmt.Errorf("failed to count shard %d: %w", rk.to, err)
+			}
+			result.SourceRows += src
+			result.TargetRows += dst
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return result, nil
+}
+
+// Cutover 校验通过后删除源分片中已迁移的行
+// 必须在应用切换到新分片配置之后调用
+func (t *ReshardTask) Cutover(ctx context.Context) error {
+	result, err := t.Verify(ctx)
+	if err != nil {
+		return err
+	}
+	if !result.OK() {
+		return fmt.Errorf("verify failed: source rows %d, target rows %d", result.SourceRows, result.TargetRows)
+	}
+
+	for rk, keys := range t.Plan.groupByRoute() {
+		err := t.eachBatch(keys, func(batch []any) error {
+			if err := t.Source(rk.from).WithContext(ctx).Table(t.Table).
+				Where(t.KeyColumn+" IN ?", batch).Delete(map[string]any{}).Error; err != nil {
+				return fmt.Errorf("failed to delete from shard %d: %w", rk.from, err)
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// validate 检查任务参数
+func (t *ReshardTask) validate() error {
+	if t.Plan == nil {
+		return fmt.Errorf("reshard plan cannot be nil")
+	}
+	if t.Table == "" || t.KeyColumn == "" {
+		return fmt.Errorf("table and key column are required")
+	}
+	if t.Source == nil || t.Target == nil {
+		return fmt.Errorf("source and target shard resolvers are required")
+	}
+	return nil
+}
+
+// eachBatch 按 BatchSize 分批处理 key
+func (t *ReshardTask) eachBatch(keys []any, fn func(batch []any) error) error {
+	size := t.BatchSize
+	if size <= 0 {
+		size = 500
+	}
+
+	for start := 0; start < len(keys); start += size {
+		end := start + size
+		if end > len(keys) {
+			end = len(keys)
+		}
+		if err := fn(keys[start:end]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
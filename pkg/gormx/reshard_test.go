@@ -0,0 +1,46 @@
+package gormx
+
+import "testing"
+
+func TestReshardPlan_ConsistentHashMovesFewerKeys(t *testing.T) {
+	keys := make([]any, 0, 10000)
+	for i := 0; i < 10000; i++ {
+		keys = append(keys, int64(i))
+	}
+
+	modPlan := NewReshardPlan(ShardingConfig{Algorithm: ShardAlgorithmMod, ShardCount: 4}, 5, keys)
+	ringPlan := NewReshardPlan(ShardingConfig{Algorithm: ShardAlgorithmConsistentHash, ShardCount: 4}, 5, keys)
+
+	t.Logf("mod: %.2f%%, consistent-hash: %.2f%%", modPlan.MoveRatio()*100, ringPlan.MoveRatio()*100)
+
+	if ringPlan.MoveRatio() >= modPlan.MoveRatio() {
+		t.Errorf("expected consistent-hash to move fewer keys, got %.2f >= %.2f", ringPlan.MoveRatio(), modPlan.MoveRatio())
+	}
+	// 4 → 5 个分片，理论上约 1/5 的 key 需要迁移
+	if ringPlan.MoveRatio() > 0.35 {
+		t.Errorf("consistent-hash moved too many keys: %.2f", ringPlan.MoveRatio())
+	}
+
+	for _, m := range ringPlan.Moves {
+		if m.To != 4 {
+			t.Fatalf("key %v moved between existing shards: %d -> %d", m.Key, m.From, m.To)
+		}
+	}
+}
+
+func TestShardID_ConsistentHashInRange(t *testing.T) {
+	cfg := NewConfig("mysql", "").WithSharding(ShardingConfig{
+		Algorithm: ShardAlgorithmConsistentHash,
+		Shards:    []ShardNode{{ID: 0}, {ID: 1}, {ID: 2}},
+	})
+
+	for i := 0; i < 1000; i++ {
+		id := cfg.ShardID(i)
+		if id < 0 || id >= 3 {
+			t.Fatalf("shard id out of range: %d", id)
+		}
+		if id != cfg.ShardID(i) {
+			t.Fatalf("shard id not stable for key %d", i)
+		}
+	}
+}
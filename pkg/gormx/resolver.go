@@ -39,16 +39,21 @@ func (c *Client) setupDBResolver(cfg *Config) error {
 
 // setupReplica 配置主从读写分离
 func (c *Client) setupReplica(replica *ReplicaConfig) error {
-	resolverCfg := dbresolver.Config{
-		Policy: dbresolver.RandomPolicy{}, // 随机负载均衡
+	primary, err := c.DB.DB()
+	if err != nil {
+		return fmt.Errorf("failed to get primary sql.DB: %w", err)
 	}
 
-	// 创建从库连接池
-	replicaDialector, err := c.createDialector(c.config.Driver, replica.ReplicaDSN)
+	// 创建从库连接池（由 Client 管理，便于健康检查）
+	_, replicaDialector, err := c.openConn("replica", RoleReplica, -1, replica.ReplicaDSN)
 	if err != nil {
 		return fmt.Errorf("failed to create replica dialector: %w", err)
 	}
-	resolverCfg.Replicas = []gorm.Dialector{replicaDialector}
+
+	resolverCfg := dbresolver.Config{
+		Replicas: []gorm.Dialector{replicaDialector},
+	}
+	c.withHealthPolicy(&resolverCfg, primary) // 随机选择健康从库，全部不可用时回退主库
 
 	// 注册到 DBResolver
 	if err := c.DB.Use(dbresolver.Register(resolverCfg)); err != nil {
@@ -64,6 +69,11 @@ func (c *Client) setupMultiDatabase(multiDB *MultiDatabaseConfig) error {
 	// 构建链式 Register 调用
 	var plugin gorm.Plugin
 
+	primary, err := c.DB.DB()
+	if err != nil {
+		return fmt.Errorf("failed to get primary sql.DB: %w", err)
+	}
+
 	// 注册所有数据库配置（带表名路由）
 	for i, db := range multiDB.Databases {
 		if db.DSN == "" {
@@ -73,19 +83,18 @@ func (c *Client) setupMultiDatabase(multiDB *MultiDatabaseConfig) error {
 			return fmt.Errorf("database %s must specify tables", db.Name)
 		}
 
-		dbCfg := dbresolver.Config{
-			Policy: dbresolver.RandomPolicy{},
-		}
+		dbCfg := dbresolver.Config{}
 
 		// 第一个数据库的主库已作为主连接，不需要再注册 Sources
 		if i == 0 {
 			// 第一个数据库：只配置从库（如果有）
 			if db.ReplicaDSN != "" {
-				replica, err := c.createDialector(c.config.Driver, db.ReplicaDSN)
+				_, replica, err := c.openConn(db.Name, RoleReplica, -1, db.ReplicaDSN)
 				if err != nil {
 					return fmt.Errorf("failed to create database %s replica: %w", db.Name, err)
 				}
 				dbCfg.Replicas = []gorm.Dialector{replica}
+				c.withHealthPolicy(&dbCfg, primary)
 
 				// 构建表名列表
 				tables := make([]interface{}, len(db.Tables))
@@ -103,18 +112,19 @@ func (c *Client) setupMultiDatabase(multiDB *MultiDatabaseConfig) error {
 		}
 
 		// 其他数据库：正常注册主库和从库
-		source, err := c.createDialector(c.config.Driver, db.DSN)
+		sourceDB, source, err := c.openConn(db.Name, RolePrimary, -1, db.DSN)
 		if err != nil {
 			return fmt.Errorf("failed to create database %s source: %w", db.Name, err)
 		}
 		dbCfg.Sources = []gorm.Dialector{source}
 
 		if db.ReplicaDSN != "" {
-			replica, err := c.createDialector(c.config.Driver, db.ReplicaDSN)
+			_, replica, err := c.openConn(db.Name, RoleReplica, -1, db.ReplicaDSN)
 			if err != nil {
 				return fmt.Errorf("failed to create database %s replica: %w", db.Name, err)
 			}
 			dbCfg.Replicas = []gorm.Dialector{replica}
+			c.withHealthPolicy(&dbCfg, sourceDB)
 		}
 
 		// 构建表名列表
@@ -0,0 +1,431 @@
+package gormx
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"slices"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// DefaultSeedTable 记录已执行种子数据的表
+const DefaultSeedTable = "gormx_seeds"
+
+// ErrSeedNotFound 种子数据不存在
+var ErrSeedNotFound = errors.New("gormx: seed not found")
+
+// SeedFunc 种子数据函数，在事务中执行
+type SeedFunc func(ctx context.Context, tx *gorm.DB) error
+
+// Seed 一条种子数据
+type Seed struct {
+	Name string
+	// 依赖的种子数据，执行前先执行依赖
+	DependsOn []string
+	// 适用的环境，为空表示所有环境
+	Envs []string
+	Run  SeedFunc
+}
+
+// SeedRecord 已执行的种子数据
+type SeedRecord struct {
+	Name      string    `gorm:"primaryKey;size:191" json:"name"`
+	Env       string    `gorm:"size:64" json:"env"`
+	AppliedAt time.Time `json:"applied_at"`
+}
+
+// SeedStatus 种子数据的执行状态
+type SeedStatus struct {
+	Name      string     `json:"name"`
+	DependsOn []string   `json:"depends_on,omitempty"`
+	Envs      []string   `json:"envs,omitempty"`
+	Applied   bool       `json:"applied"`
+	AppliedAt *time.Time `json:"applied_at,omitempty"`
+}
+
+// Seeder 种子数据执行器
+//
+// 种子数据按依赖顺序执行，每条种子数据和它的执行记录在同一个事务中写入，
+// 已执行的种子数据不会重复执行，用法：
+//
+//	seeder := gormx.NewSeeder(client.DB)
+//	seeder.Register(gormx.Seed{Name: "roles", Run: seedRoles})
+//	seeder.Register(gormx.Seed{Name: "admin", DependsOn: []string{"roles"}, Run: seedAdmin})
+//	seeder.Register(gormx.Seed{Name: "demo_users", DependsOn: []string{"roles"}, Envs: []string{"dev", "test"}, Run: seedDemo})
+//	applied, err := seeder.Run(ctx, "test")
+type Seeder struct {
+	db    *gorm.DB
+	table string
+	seeds []*Seed
+	index map[string]*Seed
+}
+
+// NewSeeder 创建种子数据执行器
+func NewSeeder(db *gorm.DB) *Seeder {
+	return &Seeder{
+		db:    db,
+		table: DefaultSeedTable,
+		index: make(map[string]*Seed),
+	}
+}
+
+// WithTable 设置记录表名
+func (s *Seeder) WithTable(table string) *Seeder {
+	s.table = table
+	return s
+}
+
+// Register 注册种子数据，名称不能重复
+func (s *Seeder) Register(seeds ...Seed) error {
+	for _, seed := range seeds {
+		if seed.Name == "" {
+			return fmt.Errorf("seed name is empty")
+		}
+		if seed.Run == nil {
+			return fmt.Errorf("seed %s has no run function", seed.Name)
+		}
+		if _, ok := s.index[seed.Name]; ok {
+			return fmt.Errorf("seed %s already registered", seed.Name)
+		}
+		seed := seed
+		s.seeds = append(s.seeds, &seed)
+		s.index[seed.Name] = &seed
+	}
+	return nil
+}
+
+// Seeds 返回已注册的种子数据（注册顺序）
+func (s *Seeder) Seeds() []Seed {
+	seeds := make([]Seed, len(s.seeds))
+	for i, seed := range s.seeds {
+		seeds[i] = *seed
+	}
+	return seeds
+}
+
+// Plan 返回 env 环境下需要执行的种子数据（依赖顺序），names 为空时包含该环境的所有种子数据
+//
+// 依赖会被自动加入；依赖不适用于 env、依赖不存在或存在循环依赖时返回错误
+func (s *Seeder) Plan(env string, names ...string) ([]string, error) {
+	if len(names) == 0 {
+		for _, seed := range s.seeds {
+			if seed.appliesTo(env) {
+				names = append(names, seed.Name)
+			}
+		}
+	}
+
+	const (
+		visiting = 1
+		visited  = 2
+	)
+	state := make(map[string]int)
+	var (
+		order []string
+		visit func(name string, path []string) error
+	)
+	visit = func(name string, path []string) error {
+		seed, ok := s.index[name]
+		if !ok {
+			if len(path) > 0 {
+				return fmt.Errorf("%w: %s (required by %s)", ErrSeedNotFound, name, path[len(path)-1])
+			}
+			return fmt.Errorf("%w: %s", ErrSeedNotFound, name)
+		}
+		switch state[name] {
+		case visited:
+			return nil
+		case visiting:
+			return fmt.Errorf("seed dependency cycle: %s -> %s", strings.Join(path, " -> "), name)
+		}
+		if !seed.appliesTo(env) {
+			return fmt.Errorf("seed %s is not enabled for env %q (envs: %s)", name, env, strings.Join(seed.Envs, ", "))
+		}
+
+		state[name] = visiting
+		for _, dep := range seed.DependsOn {
+			if err := visit(dep, append(path[:len(path):len(path)], name)); err != nil {
+				return err
+			}
+		}
+		state[name] = visited
+		order = append(order, name)
+		return nil
+	}
+
+	for _, name := range names {
+		if err := visit(name, nil); err != nil {
+			return nil, err
+		}
+	}
+	return order, nil
+}
+
+// Run 执行 env 环境下尚未执行的种子数据，names 为空时执行该环境的所有种子数据，返回本次执行的名称
+//
+// 某条种子数据失败时停止执行，之前成功的种子数据已提交
+func (s *Seeder) Run(ctx context.Context, env string, names ...string) ([]string, error) {
+	order, err := s.Plan(env, names...)
+	if err != nil {
+		return nil, err
+	}
+
+	applied, err := s.applied(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var done []string
+	for _, name := range order {
+		if _, ok := applied[name]; ok {
+			continue
+		}
+
+		seed := s.index[name]
+		err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+			if err := seed.Run(ctx, tx); err != nil {
+				return err
+			}
+			record := &SeedRecord{Name: name, Env: env, AppliedAt: time.Now()}
+			return tx.Table(s.table).Create(record).Error
+		})
+		if err != nil {
+			return done, fmt.Errorf("seed %s failed: %w", name, err)
+		}
+		done = append(done, name)
+	}
+	return done, nil
+}
+
+// Status 返回 env 环境下种子数据的执行状态（注册顺序）
+func (s *Seeder) Status(ctx context.Context, env string) ([]SeedStatus, error) {
+	applied, err := s.applied(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var statuses []SeedStatus
+	for _, seed := range s.seeds {
+		if !seed.appliesTo(env) {
+			continue
+		}
+		status := SeedStatus{Name: seed.Name, DependsOn: seed.DependsOn, Envs: seed.Envs}
+		if record, ok := applied[seed.Name]; ok {
+			status.Applied = true
+			status.AppliedAt = &record.AppliedAt
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses, nil
+}
+
+// Forget 删除种子数据的执行记录（不删除数据），下次 Run 时会重新执行
+func (s *Seeder) Forget(ctx context.Context, names ...string) error {
+	if len(names) == 0 {
+		return nil
+	}
+	if err := s.ensureTable(ctx); err != nil {
+		return err
+	}
+	return s.db.WithContext(ctx).Table(s.table).Where("name IN ?", names).Delete(&SeedRecord{}).Error
+}
+
+// applied 读取已执行的种子数据
+func (s *Seeder) applied(ctx context.Context) (map[string]SeedRecord, error) {
+	if err := s.ensureTable(ctx); err != nil {
+		return nil, err
+	}
+
+	var records []SeedRecord
+	if err := s.db.WithContext(ctx).Table(s.table).Find(&records).Error; err != nil {
+		return nil, fmt.Errorf("failed to read seed records: %w", err)
+	}
+
+	applied := make(map[string]SeedRecord, len(records))
+	for _, r := range records {
+		applied[r.Name] = r
+	}
+	return applied, nil
+}
+
+// ensureTable 创建记录表
+func (s *Seeder) ensureTable(ctx context.Context) error {
+	if err := s.db.WithContext(ctx).Table(s.table).AutoMigrate(&SeedRecord{}); err != nil {
+		return fmt.Errorf("failed to create seed table %s: %w", s.table, err)
+	}
+	return nil
+}
+
+func (seed *Seed) appliesTo(env string) bool {
+	return len(seed.Envs) == 0 || slices.Contains(seed.Envs, env)
+}
+
+// LoadSQLSeeds 从目录加载 SQL 种子数据：
+//
+//	seeds/
+//	  roles.sql        # 所有环境
+//	  admin.sql
+//	  test/
+//	    demo_users.sql # 只在 test 环境
+//
+// 文件名（不含 .sql）为种子数据名称，文件开头的注释可以声明依赖：
+//
+//	-- depends: roles, admin
+//
+// 文件中的多条语句依次执行
+func LoadSQLSeeds(fsys fs.FS, dir string) ([]Seed, error) {
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read seed dir %s: %w", dir, err)
+	}
+
+	var seeds []Seed
+	for _, entry := range entries {
+		if entry.IsDir() {
+			env := entry.Name()
+			files, err := fs.ReadDir(fsys, path.Join(dir, env))
+			if err != nil {
+				return nil, fmt.Errorf("failed to read seed dir %s: %w", path.Join(dir, env), err)
+			}
+			for _, f := range files {
+				if f.IsDir() || path.Ext(f.Name()) != ".sql" {
+					continue
+				}
+				seed, err := loadSQLSeed(fsys, path.Join(dir, env, f.Name()))
+				if err != nil {
+					return nil, err
+				}
+				seed.Envs = []string{env}
+				seeds = append(seeds, seed)
+			}
+			continue
+		}
+
+		if path.Ext(entry.Name()) != ".sql" {
+			continue
+		}
+		seed, err := loadSQLSeed(fsys, path.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+		seeds = append(seeds, seed)
+	}
+	return seeds, nil
+}
+
+// loadSQLSeed 读取单个 SQL 种子文件
+func loadSQLSeed(fsys fs.FS, file string) (Seed, error) {
+	data, err := fs.ReadFile(fsys, file)
+	if err != nil {
+		return Seed{}, fmt.Errorf("failed to read seed file %s: %w", file, err)
+	}
+
+	content := string(data)
+	statements := splitSQL(content)
+	return Seed{
+		Name:      strings.TrimSuffix(path.Base(file), ".sql"),
+		DependsOn: parseSeedDepends(content),
+		Run: func(ctx context.Context, tx *gorm.DB) error {
+			for _, stmt := range statements {
+				if err := tx.Exec(stmt).Error; err != nil {
+					return fmt.Errorf("%s: %w", file, err)
+				}
+			}
+			return nil
+		},
+	}, nil
+}
+
+// parseSeedDepends 解析文件开头注释中的 "-- depends: a, b"
+func parseSeedDepends(content string) []string {
+	var deps []string
+	for _, line := range strings.Split(content, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		if !strings.HasPrefix(line, "--") {
+			break
+		}
+		comment := strings.TrimSpace(strings.TrimPrefix(line, "--"))
+		key, value, ok := strings.Cut(comment, ":")
+		if !ok || !strings.EqualFold(strings.TrimSpace(key), "depends") {
+			continue
+		}
+		for _, dep := range strings.Split(value, ",") {
+			if dep = strings.TrimSpace(dep); dep != "" {
+				deps = append(deps, dep)
+			}
+		}
+	}
+	return deps
+}
+
+// splitSQL 按分号拆分语句，忽略引号和注释中的分号
+func splitSQL(content string) []string {
+	var (
+		statements []string
+		current    strings.Builder
+		quote      byte
+	)
+	flush := func() {
+		if stmt := strings.TrimSpace(current.String()); stmt != "" && !isCommentOnly(stmt) {
+			statements = append(statements, stmt)
+		}
+		current.Reset()
+	}
+
+	for i := 0; i < len(content); i++ {
+		c := content[i]
+		switch {
+		case quote != 0:
+			current.WriteByte(c)
+			if c == '\\' && quote != '`' && i+1 < len(content) {
+				i++
+				current.WriteByte(content[i])
+			} else if c == quote {
+				quote = 0
+			}
+		case c == '\'' || c == '"' || c == '`':
+			quote = c
+			current.WriteByte(c)
+		case c == '-' && strings.HasPrefix(content[i:], "--"):
+			end := strings.IndexByte(content[i:], '\n')
+			if end < 0 {
+				end = len(content) - i
+			}
+			current.WriteString(content[i : i+end])
+			i += end - 1
+		case c == '/' && strings.HasPrefix(content[i:], "/*"):
+			end := strings.Index(content[i:], "*/")
+			if end < 0 {
+				end = len(content) - i
+			} else {
+				end += 2
+			}
+			current.WriteString(content[i : i+end])
+			i += end - 1
+		case c == ';':
+			flush()
+		default:
+			current.WriteByte(c)
+		}
+	}
+	flush()
+	return statements
+}
+
+// isCommentOnly 判断语句是否只包含行注释
+func isCommentOnly(stmt string) bool {
+	for _, line := range strings.Split(stmt, "\n") {
+		if line = strings.TrimSpace(line); line != "" && !strings.HasPrefix(line, "--") {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,109 @@
+package gormx
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"gorm.io/gorm"
+)
+
+func noopSeed(context.Context, *gorm.DB) error { return nil }
+
+func TestSeeder_PlanOrdersDependenciesAndFiltersEnv(t *testing.T) {
+	s := NewSeeder(nil)
+	err := s.Register(
+		Seed{Name: "admin", DependsOn: []string{"roles"}, Run: noopSeed},
+		Seed{Name: "roles", Run: noopSeed},
+		Seed{Name: "demo_users", DependsOn: []string{"admin"}, Envs: []string{"test"}, Run: noopSeed},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	order, err := s.Plan("prod")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"roles", "admin"}; !reflect.DeepEqual(order, want) {
+		t.Errorf("prod plan = %v, want %v", order, want)
+	}
+
+	order, err = s.Plan("test", "demo_users")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"roles", "admin", "demo_users"}; !reflect.DeepEqual(order, want) {
+		t.Errorf("test plan = %v, want %v", order, want)
+	}
+
+	// 指定其他环境的种子数据
+	if _, err := s.Plan("prod", "demo_users"); err == nil {
+		t.Error("expected error for seed not enabled in env")
+	}
+}
+
+func TestSeeder_PlanErrors(t *testing.T) {
+	s := NewSeeder(nil)
+	s.Register(
+		Seed{Name: "a", DependsOn: []string{"b"}, Run: noopSeed},
+		Seed{Name: "b", DependsOn: []string{"a"}, Run: noopSeed},
+		Seed{Name: "c", DependsOn: []string{"missing"}, Run: noopSeed},
+	)
+
+	if _, err := s.Plan("", "a"); err == nil || !strings.Contains(err.Error(), "a -> b -> a") {
+		t.Errorf("expected cycle error, got %v", err)
+	}
+	if _, err := s.Plan("", "c"); !errors.Is(err, ErrSeedNotFound) {
+		t.Errorf("expected ErrSeedNotFound, got %v", err)
+	}
+	if err := s.Register(Seed{Name: "a", Run: noopSeed}); err == nil {
+		t.Error("expected duplicate name error")
+	}
+}
+
+func TestLoadSQLSeeds(t *testing.T) {
+	fsys := fstest.MapFS{
+		"seeds/roles.sql":     {Data: []byte("INSERT INTO roles (name) VALUES ('admin');\nINSERT INTO roles (name) VALUES ('a;b');\n")},
+		"seeds/admin.sql":     {Data: []byte("-- 管理员账号\n-- depends: roles\nINSERT INTO users (name) VALUES ('root');")},
+		"seeds/test/demo.sql": {Data: []byte("-- depends: admin, roles\nINSERT INTO users (name) VALUES ('demo');")},
+		"seeds/README.md":     {Data: []byte("ignored")},
+	}
+
+	seeds, err := LoadSQLSeeds(fsys, "seeds")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	byName := make(map[string]Seed)
+	for _, seed := range seeds {
+		byName[seed.Name] = seed
+	}
+	if len(byName) != 3 {
+		t.Fatalf("expected 3 seeds, got %v", seeds)
+	}
+	if deps := byName["admin"].DependsOn; !reflect.DeepEqual(deps, []string{"roles"}) {
+		t.Errorf("admin depends = %v", deps)
+	}
+	if demo := byName["demo"]; !reflect.DeepEqual(demo.Envs, []string{"test"}) || len(demo.DependsOn) != 2 {
+		t.Errorf("demo = %+v", demo)
+	}
+}
+
+func TestSplitSQL(t *testing.T) {
+	got := splitSQL(`-- header; not a statement
+INSERT INTO t VALUES ('x;y', "a\"b;");
+/* block; comment */ UPDATE t SET v = 1;
+
+-- trailing comment`)
+	want := []string{
+		"-- header; not a statement\nINSERT INTO t VALUES ('x;y', \"a\\\"b;\")",
+		"/* block; comment */ UPDATE t SET v = 1",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("splitSQL = %q, want %q", got, want)
+	}
+}
@@ -3,18 +3,37 @@ package gormx
 import (
 	"fmt"
 	"hash/crc32"
+	"strconv"
+
+	"github.com/tedwangl/go-util/pkg/utils/consistenthash"
+)
+
+// 分片算法
+const (
+	ShardAlgorithmMod            = "mod"
+	ShardAlgorithmHash           = "hash"
+	ShardAlgorithmConsistentHash = "consistent-hash"
 )
 
+// defaultVirtualNodes 一致性哈希默认虚拟节点数（每个分片）
+const defaultVirtualNodes = 160
+
 // ShardingConfig 分片配置
 type ShardingConfig struct {
-	// 分片算法：hash, range, mod
+	// 分片算法：mod, hash, consistent-hash
 	Algorithm string `json:"algorithm" yaml:"algorithm"`
 
-	// 分片数量（用于 mod 算法）
+	// 分片数量（mod/hash 必填；consistent-hash 为空时使用 len(Shards)）
 	ShardCount int `json:"shard_count" yaml:"shard_count"`
 
+	// 每个分片的虚拟节点数（用于 consistent-hash 算法，默认 160）
+	VirtualNodes int `json:"virtual_nodes,omitempty" yaml:"virtual_nodes,omitempty"`
+
 	// 物理分片列表
 	Shards []ShardNode `json:"shards" yaml:"shards"`
+
+	// 一致性哈希环（WithSharding 时构建）
+	ring *consistenthash.ConsistentHash
 }
 
 // ShardNode 单个分片节点
@@ -37,6 +56,9 @@ type ShardNode struct {
 
 // WithSharding 配置分片
 func (c *Config) WithSharding(sharding ShardingConfig) *Config {
+	if sharding.Algorithm == ShardAlgorithmConsistentHash {
+		sharding.ring = sharding.buildRing(sharding.logicalShardCount())
+	}
 	c.sharding = &sharding
 	return c
 }
@@ -51,19 +73,29 @@ func (c *Config) ShardID(shardKey interface{}) int {
 	if c.sharding == nil {
 		return 0
 	}
+	return c.sharding.shardID(shardKey)
+}
 
-	switch c.sharding.Algorithm {
-	case "mod":
-		return c.shardIDByMod(shardKey)
-	case "hash":
-		return c.shardIDByHash(shardKey)
+// shardID 按配置的算法计算分片 ID
+func (s *ShardingConfig) shardID(shardKey interface{}) int {
+	switch s.Algorithm {
+	case ShardAlgorithmMod:
+		return s.shardIDByMod(shardKey)
+	case ShardAlgorithmHash:
+		return s.shardIDByHash(shardKey)
+	case ShardAlgorithmConsistentHash:
+		ring := s.ring
+		if ring == nil {
+			ring = s.buildRing(s.logicalShardCount())
+		}
+		return shardIDByRing(ring, shardKey)
 	default:
-		return c.shardIDByMod(shardKey)
+		return s.shardIDByMod(shardKey)
 	}
 }
 
 // shardIDByMod 取模算法
-func (c *Config) shardIDByMod(shardKey interface{}) int {
+func (s *ShardingConfig) shardIDByMod(shardKey interface{}) int {
 	var key int64
 	switch v := shardKey.(type) {
 	case int:
@@ -80,26 +112,64 @@ func (c *Config) shardIDByMod(shardKey interface{}) int {
 		key = int64(v)
 	default:
 		// 字符串或其他类型，使用哈希
-		return c.shardIDByHash(shardKey)
+		return s.shardIDByHash(shardKey)
 	}
 
-	if c.sharding.ShardCount <= 0 {
+	if s.ShardCount <= 0 {
 		return 0
 	}
 
-	return int(key % int64(c.sharding.ShardCount))
+	return int(key % int64(s.ShardCount))
 }
 
 // shardIDByHash 哈希算法（CRC32）
-func (c *Config) shardIDByHash(shardKey interface{}) int {
+func (s *ShardingConfig) shardIDByHash(shardKey interface{}) int {
 	str := fmt.Sprint(shardKey)
 	hash := crc32.ChecksumIEEE([]byte(str))
 
-	if c.sharding.ShardCount <= 0 {
+	if s.ShardCount <= 0 {
 		return 0
 	}
 
-	return int(hash % uint32(c.sharding.ShardCount))
+	return int(hash % uint32(s.ShardCount))
+}
+
+// logicalShardCount 一致性哈希的分片数（未配置 ShardCount 时使用物理分片数）
+func (s *ShardingConfig) logicalShardCount() int {
+	if s.ShardCount > 0 {
+		return s.ShardCount
+	}
+	return len(s.Shards)
+}
+
+// buildRing 构建包含 shardCount 个分片的一致性哈希环
+// 环上节点名为分片 ID，新增分片只会迁移约 1/N 的 key
+func (s *ShardingConfig) buildRing(shardCount int) *consistenthash.ConsistentHash {
+	replicas := s.VirtualNodes
+	if replicas <= 0 {
+		replicas = defaultVirtualNodes
+	}
+
+	ring := consistenthash.NewConsistentHashWithReplicas(replicas)
+	nodes := make([]string, shardCount)
+	for i := range nodes {
+		nodes[i] = strconv.Itoa(i)
+	}
+	ring.Set(nodes)
+	return ring
+}
+
+// shardIDByRing 一致性哈希算法
+func shardIDByRing(ring *consistenthash.ConsistentHash, shardKey interface{}) int {
+	node, err := ring.Get(fmt.Sprint(shardKey))
+	if err != nil {
+		return 0
+	}
+	id, err := strconv.Atoi(node)
+	if err != nil {
+		return 0
+	}
+	return id
 }
 
 // GetShardNode 获取分片节点信息
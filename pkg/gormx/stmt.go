@@ -0,0 +1,277 @@
+package gormx
+
+import (
+	"database/sql"
+	"log"
+	"sort"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// PreparedStmtStats 预编译语句缓存统计（PrepareStmt 开启时有效，所有连接合计）
+type PreparedStmtStats struct {
+	Size     int     `json:"size"`      // 缓存中的语句数
+	Hits     int64   `json:"hits"`      // 复用缓存语句的执行次数
+	Misses   int64   `json:"misses"`    // 重新预编译的次数
+	HitRatio float64 `json:"hit_ratio"` // Hits / (Hits + Misses)，没有执行时为 0
+	Leaked   int     `json:"leaked"`    // 闲置超过 StmtLeakThreshold 的语句数
+}
+
+// PreparedStmt 缓存中的预编译语句
+type PreparedStmt struct {
+	SQL        string    `json:"sql"` // 未绑定参数的 SQL
+	PreparedAt time.Time `json:"prepared_at"`
+	LastUsed   time.Time `json:"last_used"`
+	Uses       int64     `json:"uses"`
+}
+
+// stmtEntry 跟踪的语句
+type stmtEntry struct {
+	stmt *sql.Stmt // 用于识别被淘汰后重新预编译的语句
+	PreparedStmt
+}
+
+// stmtPool 一个 PreparedStmtDB 的语句缓存（Session 创建的 PreparedStmtDB 共享同一个缓存）
+type stmtPool struct {
+	db      *gorm.PreparedStmtDB
+	entries map[string]*stmtEntry
+}
+
+// stmtTracker 预编译语句跟踪
+//
+// 在指标插件的 after 回调中按语句所在的缓存记录命中/未命中和最后使用时间，
+// 闲置超过 leakThreshold 仍留在缓存中的语句视为泄漏（通常是 SQL 不固定，如 IN 列表长度变化，
+// 导致缓存不断增长，最终触发 "too many prepared statements"）
+type stmtTracker struct {
+	leakThreshold time.Duration
+	interval      time.Duration
+	metrics       bool
+
+	mu     sync.Mutex
+	pools  map[any]*stmtPool // key 为 PreparedStmtDB.Stmts
+	hits   int64
+	misses int64
+	size   int // 上次对账时的缓存大小
+	count  int // 跟踪的语句数
+
+	stopOnce sync.Once
+	stop     chan struct{}
+	done     chan struct{}
+}
+
+func newStmtTracker(cfg *Config) *stmtTracker {
+	return &stmtTracker{
+		leakThreshold: cfg.StmtLeakThreshold,
+		interval:      cfg.StmtStatsInterval,
+		metrics:       !cfg.DisableMetrics,
+		pools:         make(map[any]*stmtPool),
+		stop:          make(chan struct{}),
+		done:          make(chan struct{}),
+	}
+}
+
+// observe 记录一次执行（未使用预编译语句时忽略）
+func (t *stmtTracker) observe(db *gorm.DB, query string) {
+	var pdb *gorm.PreparedStmtDB
+	switch pool := db.Statement.ConnPool.(type) {
+	case *gorm.PreparedStmtDB:
+		pdb = pool
+	case *gorm.PreparedStmtTX:
+		pdb = pool.PreparedStmtDB
+	}
+	if pdb == nil || pdb.Stmts == nil || query == "" {
+		return
+	}
+
+	// 执行后语句一定在缓存中（预编译失败或 DryRun 时不在）
+	cached, ok := pdb.Stmts.Get(query)
+	if !ok || cached.Stmt == nil {
+		return
+	}
+
+	now := time.Now()
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	pool, ok := t.pools[pdb.Stmts]
+	if !ok {
+		pool = &stmtPool{db: pdb, entries: make(map[string]*stmtEntry)}
+		t.pools[pdb.Stmts] = pool
+	}
+
+	entry, ok := pool.entries[query]
+	if ok && entry.stmt == cached.Stmt {
+		t.hits++
+		if t.metrics {
+			preparedStmtHits.Inc()
+		}
+	} else {
+		if !ok {
+			entry = &stmtEntry{}
+			pool.entries[query] = entry
+			t.count++
+		}
+		entry.stmt = cached.Stmt
+		entry.PreparedStmt = PreparedStmt{SQL: query, PreparedAt: now}
+		t.misses++
+		if t.metrics {
+			preparedStmtMisses.Inc()
+		}
+
+		// 被淘汰的语句只在对账时清理，跟踪的语句数远多于缓存大小时对账一次
+		if t.count > 2*t.size+100 {
+			t.reconcile()
+		}
+	}
+	entry.LastUsed = now
+	entry.Uses++
+}
+
+// reconcile 按缓存中实际的语句清理已淘汰的记录，更新缓存大小（调用方持有锁）
+func (t *stmtTracker) reconcile() {
+	size, count := 0, 0
+	for _, pool := range t.pools {
+		keys := pool.db.Stmts.Keys()
+
+		cached := make(map[string]bool, len(keys))
+		for _, key := range keys {
+			cached[key] = true
+		}
+		for query := range pool.entries {
+			if !cached[query] {
+				delete(pool.entries, query)
+			}
+		}
+		size += len(keys)
+		count += len(pool.entries)
+	}
+
+	if t.metrics {
+		preparedStmtSize.Add(float64(size - t.size))
+	}
+	t.size, t.count = size, count
+}
+
+// stats 返回缓存统计
+func (t *stmtTracker) stats() PreparedStmtStats {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.reconcile()
+	s := PreparedStmtStats{
+		Size:   t.size,
+		Hits:   t.hits,
+		Misses: t.misses,
+		Leaked: len(t.leakedLocked(time.Now())),
+	}
+	if total := t.hits + t.misses; total > 0 {
+		s.HitRatio = float64(t.hits) / float64(total)
+	}
+	return s
+}
+
+// leaked 返回闲置超过 leakThreshold 的语句（按闲置时长降序）
+func (t *stmtTracker) leaked() []PreparedStmt {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.reconcile()
+	return t.leakedLocked(time.Now())
+}
+
+func (t *stmtTracker) leakedLocked(now time.Time) []PreparedStmt {
+	if t.leakThreshold <= 0 {
+		return nil
+	}
+
+	var result []PreparedStmt
+	for _, pool := range t.pools {
+		for _, entry := range pool.entries {
+			if now.Sub(entry.LastUsed) > t.leakThreshold {
+				result = append(result, entry.PreparedStmt)
+			}
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].LastUsed.Before(result[j].LastUsed)
+	})
+	return result
+}
+
+// start 启动定期打印（interval <= 0 时不启动）
+func (t *stmtTracker) start() {
+	if t.interval <= 0 {
+		close(t.done)
+		return
+	}
+
+	go func() {
+		defer close(t.done)
+
+		ticker := time.NewTicker(t.interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-t.stop:
+				return
+			case <-ticker.C:
+				t.report()
+			}
+		}
+	}()
+}
+
+// report 打印缓存统计和泄漏的语句
+func (t *stmtTracker) report() {
+	s := t.stats()
+	log.Printf("gormx: prepared statements: size=%d hits=%d misses=%d hit_ratio=%.2f leaked=%d",
+		s.Size, s.Hits, s.Misses, s.HitRatio, s.Leaked)
+	if s.Leaked == 0 {
+		return
+	}
+
+	const maxLogged = 10
+	for i, stmt := range t.leaked() {
+		if i == maxLogged {
+			break
+		}
+		log.Printf("gormx: prepared statement idle for %s (uses=%d): %s",
+			time.Since(stmt.LastUsed).Round(time.Second), stmt.Uses, stmt.SQL)
+	}
+}
+
+// close 停止定期打印，从缓存大小指标中移除本 Client 的语句
+func (t *stmtTracker) close() {
+	t.stopOnce.Do(func() {
+		close(t.stop)
+	})
+	<-t.done
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.metrics {
+		preparedStmtSize.Sub(float64(t.size))
+	}
+	t.size = 0
+}
+
+// PreparedStmtStats 获取预编译语句缓存统计（PrepareStmt 未开启时为零值）
+func (c *Client) PreparedStmtStats() PreparedStmtStats {
+	if c.metrics == nil {
+		return PreparedStmtStats{}
+	}
+	return c.metrics.stmts.stats()
+}
+
+// LeakedStmts 获取闲置超过 Config.StmtLeakThreshold 仍在缓存中的预编译语句（按闲置时长降序）
+//
+// 大量泄漏的语句通常说明 SQL 不固定（如 IN 列表长度变化），需要改为固定的 SQL 或关闭 PrepareStmt
+func (c *Client) LeakedStmts() []PreparedStmt {
+	if c.metrics == nil {
+		return nil
+	}
+	return c.metrics.stmts.leaked()
+}
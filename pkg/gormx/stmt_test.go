@@ -0,0 +1,105 @@
+package gormx
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type stmtUser struct {
+	ID   int64
+	Name string
+}
+
+func newStmtClient(t *testing.T, threshold time.Duration) *Client {
+	t.Helper()
+	cfg := NewConfig("sqlite", fmt.Sprintf("file:%s?mode=memory&cache=shared", t.Name()))
+	cfg.LogLevel = "silent"
+	cfg.DisableMetrics = true
+	cfg.HealthCheckInterval = 0
+	cfg.StmtLeakThreshold = threshold
+
+	client, err := NewClient(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { client.Close() })
+	if err := client.AutoMigrate(&stmtUser{}); err != nil {
+		t.Fatal(err)
+	}
+	return client
+}
+
+func TestPreparedStmtStats(t *testing.T) {
+	client := newStmtClient(t, 0)
+	base := client.PreparedStmtStats()
+
+	var users []stmtUser
+	for i := 0; i < 3; i++ {
+		if err := client.Where("name = ?", "tom").Find(&users).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+	// IN 列表长度不同，每次都是新的语句
+	for i := 1; i <= 4; i++ {
+		ids := make([]int64, i)
+		if err := client.Where("id IN ?", ids).Find(&users).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	s := client.PreparedStmtStats()
+	if hits, misses := s.Hits-base.Hits, s.Misses-base.Misses; hits != 2 || misses != 5 {
+		t.Errorf("expected 2 hits and 5 misses, got %d hits and %d misses", hits, misses)
+	}
+	if s.Size != base.Size+5 {
+		t.Errorf("expected %d cached statements, got %d", base.Size+5, s.Size)
+	}
+	if s.HitRatio <= 0 || s.HitRatio >= 1 {
+		t.Errorf("unexpected hit ratio %v", s.HitRatio)
+	}
+
+	// 被淘汰后重新预编译计为未命中
+	pdb := client.DB.ConnPool.(*gorm.PreparedStmtDB)
+	for _, key := range pdb.Stmts.Keys() {
+		pdb.Stmts.Delete(key)
+	}
+	if err := client.Where("name = ?", "tom").Find(&users).Error; err != nil {
+		t.Fatal(err)
+	}
+	after := client.PreparedStmtStats()
+	if after.Misses != s.Misses+1 || after.Size != 1 {
+		t.Errorf("expected a miss after eviction and 1 cached statement, got %+v", after)
+	}
+}
+
+func TestLeakedStmts(t *testing.T) {
+	client := newStmtClient(t, 50*time.Millisecond)
+
+	var users []stmtUser
+	if err := client.Where("id IN ?", []int64{1, 2, 3}).Find(&users).Error; err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(100 * time.Millisecond)
+	if err := client.Where("name = ?", "tom").Find(&users).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	var found bool
+	for _, stmt := range client.LeakedStmts() {
+		if stmt.SQL == "SELECT * FROM `stmt_users` WHERE name = ?" {
+			t.Errorf("recently used statement reported as leaked: %s", stmt.SQL)
+		}
+		if stmt.SQL == "SELECT * FROM `stmt_users` WHERE id IN (?,?,?)" {
+			found = stmt.Uses == 1 && !stmt.PreparedAt.IsZero()
+		}
+	}
+	if !found {
+		t.Errorf("expected the idle IN query to be leaked, got %+v", client.LeakedStmts())
+	}
+	if s := client.PreparedStmtStats(); s.Leaked == 0 {
+		t.Errorf("expected leaked statements in stats, got %+v", s)
+	}
+}
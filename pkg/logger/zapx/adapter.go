@@ -0,0 +1,126 @@
+package zapx
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"log"
+)
+
+const badKey = "!BADKEY"
+
+type (
+	// KeyvalLogger adapts the loggers taking a message and alternating keys and values,
+	// such as restyx.Logger and the temporal log.Logger.
+	KeyvalLogger struct {
+		logger Logger
+	}
+
+	// RestyLogger logs the restyx client and the resty client, set by resty.Client.SetLogger.
+	RestyLogger struct {
+		KeyvalLogger
+	}
+
+	stdLogWriter struct {
+		logger Logger
+	}
+)
+
+// NewKeyvalLogger returns a KeyvalLogger writing to logger.
+func NewKeyvalLogger(logger Logger) *KeyvalLogger {
+	return &KeyvalLogger{logger: logger.WithCallerSkip(1)}
+}
+
+// NewRestyLogger returns a RestyLogger of module restyx.
+func NewRestyLogger() *RestyLogger {
+	return &RestyLogger{KeyvalLogger: *NewKeyvalLogger(Named("restyx"))}
+}
+
+// NewTemporalLogger returns a logger of module temporal for the temporal client and worker.
+func NewTemporalLogger() *KeyvalLogger {
+	return NewKeyvalLogger(Named("temporal"))
+}
+
+// NewStdLogger returns a *log.Logger writing to zapx at info level,
+// for the libraries only accepting a standard logger.
+func NewStdLogger(module string) *log.Logger {
+	return log.New(newStdLogWriter(module), "", 0)
+}
+
+// RedirectStdLog redirects the output of the standard log package to zapx,
+// the returned function restores the previous output.
+func RedirectStdLog() func() {
+	flags, prefix, writer := log.Flags(), log.Prefix(), log.Writer()
+	log.SetFlags(0)
+	log.SetPrefix("")
+	log.SetOutput(newStdLogWriter(""))
+
+	return func() {
+		log.SetFlags(flags)
+		log.SetPrefix(prefix)
+		log.SetOutput(writer)
+	}
+}
+
+func (l *KeyvalLogger) Debug(msg string, keyvals ...any) {
+	l.logger.Debugw(msg, keyvalsToFields(keyvals)...)
+}
+
+func (l *KeyvalLogger) Info(msg string, keyvals ...any) {
+	l.logger.Infow(msg, keyvalsToFields(keyvals)...)
+}
+
+func (l *KeyvalLogger) Warn(msg string, keyvals ...any) {
+	l.logger.Sloww(msg, keyvalsToFields(keyvals)...)
+}
+
+func (l *KeyvalLogger) Error(msg string, keyvals ...any) {
+	l.logger.Errorw(msg, keyvalsToFields(keyvals)...)
+}
+
+func (l *RestyLogger) Debugf(format string, v ...any) {
+	l.logger.Debugf(format, v...)
+}
+
+func (l *RestyLogger) Warnf(format string, v ...any) {
+	l.logger.Slowf(format, v...)
+}
+
+func (l *RestyLogger) Errorf(format string, v ...any) {
+	l.logger.Errorf(format, v...)
+}
+
+func newStdLogWriter(module string) io.Writer {
+	var logger Logger
+	if len(module) > 0 {
+		logger = Named(module)
+	} else {
+		logger = newLogger(nil)
+	}
+
+	// skip the frames of log.(*Logger).output and the log function
+	return &stdLogWriter{logger: logger.WithCallerSkip(2)}
+}
+
+func (w *stdLogWriter) Write(p []byte) (int, error) {
+	w.logger.Info(string(bytes.TrimRight(p, "\n")))
+	return len(p), nil
+}
+
+func keyvalsToFields(keyvals []any) []LogField {
+	fields := make([]LogField, 0, (len(keyvals)+1)/2)
+	for i := 0; i < len(keyvals); i += 2 {
+		if i+1 == len(keyvals) {
+			fields = append(fields, Field(badKey, keyvals[i]))
+			break
+		}
+
+		key, ok := keyvals[i].(string)
+		if !ok {
+			key = fmt.Sprint(keyvals[i])
+		}
+		fields = append(fields, Field(key, keyvals[i+1]))
+	}
+
+	return fields
+}
@@ -0,0 +1,37 @@
+package zapx
+
+import (
+	"maps"
+	"slices"
+
+	"github.com/gocolly/colly/v2/debug"
+)
+
+// CollyDebugger implements colly debug.Debugger, logs the collector events at debug level
+// and the error events at error level.
+type CollyDebugger struct {
+	logger Logger
+}
+
+// NewCollyDebugger returns a CollyDebugger of module collyx, set by colly.Debugger.
+func NewCollyDebugger() *CollyDebugger {
+	return &CollyDebugger{logger: Named("collyx")}
+}
+
+func (d *CollyDebugger) Init() error {
+	return nil
+}
+
+func (d *CollyDebugger) Event(e *debug.Event) {
+	fields := make([]LogField, 0, len(e.Values)+2)
+	fields = append(fields, Field("collector_id", e.CollectorID), Field("request_id", e.RequestID))
+	for _, k := range slices.Sorted(maps.Keys(e.Values)) {
+		fields = append(fields, Field(k, e.Values[k]))
+	}
+
+	if e.Type == "error" {
+		d.logger.Errorw(e.Type, fields...)
+	} else {
+		d.logger.Debugw(e.Type, fields...)
+	}
+}
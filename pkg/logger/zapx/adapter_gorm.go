@@ -0,0 +1,112 @@
+package zapx
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	gormlogger "gorm.io/gorm/logger"
+	"gorm.io/gorm/utils"
+)
+
+const defaultGormSlowThreshold = 200 * time.Millisecond
+
+type (
+	// GormLogger implements gorm logger.Interface, logs the sql at debug level,
+	// the slow sql at slow level and the failed sql at error level.
+	GormLogger struct {
+		logger                    Logger
+		level                     gormlogger.LogLevel
+		slowThreshold             time.Duration
+		ignoreRecordNotFoundError bool
+	}
+
+	// GormOption customizes the GormLogger.
+	GormOption func(l *GormLogger)
+)
+
+// NewGormLogger returns a GormLogger of module gormx, the levels can be controlled
+// by LogConf.ModuleLevels, e.g. {"gormx":"warn"} to log the slow and failed sql only.
+func NewGormLogger(opts ...GormOption) *GormLogger {
+	l := &GormLogger{
+		logger:                    Named("gormx"),
+		level:                     gormlogger.Info,
+		slowThreshold:             defaultGormSlowThreshold,
+		ignoreRecordNotFoundError: true,
+	}
+	for _, opt := range opts {
+		opt(l)
+	}
+
+	return l
+}
+
+// WithGormSlowThreshold sets the threshold of the slow sql, 0 to disable.
+func WithGormSlowThreshold(threshold time.Duration) GormOption {
+	return func(l *GormLogger) {
+		l.slowThreshold = threshold
+	}
+}
+
+// WithGormRecordNotFound logs gorm.ErrRecordNotFound as error, ignored by default.
+func WithGormRecordNotFound() GormOption {
+	return func(l *GormLogger) {
+		l.ignoreRecordNotFoundError = false
+	}
+}
+
+func (l *GormLogger) LogMode(level gormlogger.LogLevel) gormlogger.Interface {
+	nl := *l
+	nl.level = level
+	return &nl
+}
+
+func (l *GormLogger) Info(ctx context.Context, msg string, data ...any) {
+	if l.level >= gormlogger.Info {
+		l.logger.WithContext(ctx).Infow(msg, l.withSource(Field("data", data))...)
+	}
+}
+
+func (l *GormLogger) Warn(ctx context.Context, msg string, data ...any) {
+	if l.level >= gormlogger.Warn {
+		l.logger.WithContext(ctx).Sloww(msg, l.withSource(Field("data", data))...)
+	}
+}
+
+func (l *GormLogger) Error(ctx context.Context, msg string, data ...any) {
+	if l.level >= gormlogger.Error {
+		l.logger.WithContext(ctx).Errorw(msg, l.withSource(Field("data", data))...)
+	}
+}
+
+func (l *GormLogger) Trace(ctx context.Context, begin time.Time, fc func() (sql string, rowsAffected int64), err error) {
+	if l.level <= gormlogger.Silent {
+		return
+	}
+
+	elapsed := time.Since(begin)
+	logger := l.logger.WithContext(ctx).WithDuration(elapsed)
+	switch {
+	case err != nil && l.level >= gormlogger.Error &&
+		(!errors.Is(err, gormlogger.ErrRecordNotFound) || !l.ignoreRecordNotFoundError):
+		logger.Errorw("sql failed", l.sqlFields(fc, Field("error", err.Error()))...)
+	case l.slowThreshold > 0 && elapsed > l.slowThreshold && l.level >= gormlogger.Warn:
+		logger.Sloww("slow sql", l.sqlFields(fc, Field("threshold", l.slowThreshold))...)
+	case l.level >= gormlogger.Info:
+		logger.Debugw("sql", l.sqlFields(fc)...)
+	}
+}
+
+func (l *GormLogger) sqlFields(fc func() (string, int64), fields ...LogField) []LogField {
+	sql, rows := fc()
+	fields = append(fields, Field("sql", sql))
+	if rows >= 0 {
+		fields = append(fields, Field("rows", rows))
+	}
+
+	return l.withSource(fields...)
+}
+
+func (l *GormLogger) withSource(fields ...LogField) []LogField {
+	return append(fields, Field("source", utils.FileWithLineNum()))
+}
@@ -0,0 +1,82 @@
+package zapx_test
+
+import (
+	"context"
+	"errors"
+	"log"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	gormlogger "gorm.io/gorm/logger"
+
+	"github.com/tedwangl/go-util/pkg/logger/zapx"
+	"github.com/tedwangl/go-util/pkg/logger/zapx/zaptest"
+)
+
+func TestKeyvalLogger(t *testing.T) {
+	w := zaptest.NewWriter(t)
+	l := zapx.NewTemporalLogger()
+	l.Info("started", "workflow", "wf-1", "attempt", 2)
+	l.Warn("odd", "key")
+	l.Error("failed", 1, "value")
+
+	entries := w.Entries()
+	if assert.Len(t, entries, 3) {
+		assert.Equal(t, zaptest.LevelInfo, entries[0].Level)
+		assert.Equal(t, "started", entries[0].Content)
+		assert.Equal(t, "temporal", entries[0].Fields["module"])
+		assert.Equal(t, "wf-1", entries[0].Fields["workflow"])
+		assert.Equal(t, 2, entries[0].Fields["attempt"])
+
+		assert.Equal(t, zaptest.LevelSlow, entries[1].Level)
+		assert.Equal(t, "key", entries[1].Fields["!BADKEY"])
+
+		assert.Equal(t, zaptest.LevelError, entries[2].Level)
+		assert.Equal(t, "value", entries[2].Fields["1"])
+	}
+}
+
+func TestStdLogger(t *testing.T) {
+	w := zaptest.NewWriter(t)
+	zapx.NewStdLogger("std").Printf("hello %s", "world")
+
+	restore := zapx.RedirectStdLog()
+	log.Println("redirected")
+	restore()
+
+	entries := w.Entries()
+	if assert.Len(t, entries, 2) {
+		assert.Equal(t, "hello world", entries[0].Content)
+		assert.Equal(t, "std", entries[0].Fields["module"])
+		assert.Equal(t, "redirected", entries[1].Content)
+		assert.NotContains(t, entries[1].Fields, "module")
+	}
+}
+
+func TestGormLogger(t *testing.T) {
+	w := zaptest.NewWriter(t)
+	l := zapx.NewGormLogger(zapx.WithGormSlowThreshold(time.Second))
+	ctx := context.Background()
+	query := func() (string, int64) {
+		return "SELECT 1", 1
+	}
+
+	l.Trace(ctx, time.Now(), query, nil)
+	l.Trace(ctx, time.Now().Add(-2*time.Second), query, nil)
+	l.Trace(ctx, time.Now(), query, errors.New("broken"))
+	l.Trace(ctx, time.Now(), query, gormlogger.ErrRecordNotFound)
+	l.LogMode(gormlogger.Silent).Trace(ctx, time.Now(), query, errors.New("silent"))
+
+	entries := w.Entries()
+	if assert.Len(t, entries, 4) {
+		assert.Equal(t, zaptest.LevelDebug, entries[0].Level)
+		assert.Equal(t, "SELECT 1", entries[0].Fields["sql"])
+		assert.Equal(t, "gormx", entries[0].Fields["module"])
+		assert.Equal(t, zaptest.LevelSlow, entries[1].Level)
+		assert.Equal(t, zaptest.LevelError, entries[2].Level)
+		assert.Equal(t, "broken", entries[2].Fields["error"])
+		// record not found is ignored by default
+		assert.Equal(t, zaptest.LevelDebug, entries[3].Level)
+	}
+}
@@ -1,26 +1,63 @@
 package zapx
 
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
 type (
 	LogConf struct {
-		ServiceName         string       `json:",optional"`
-		Mode                string       `json:",default=console,options=[console,file,volume]"`
-		Encoding            string       `json:",default=json,options=[json,console]"`
-		TimeFormat          string       `json:",optional"`
-		Path                string       `json:",default=logs"`
-		Level               string       `json:",default=info,options=[debug,info,error,severe]"`
-		MaxContentLength    uint32       `json:",optional"`
-		Compress            bool         `json:",optional"`
-		KeepDays            int          `json:",optional"`
-		StackCooldownMillis int          `json:",default=100"`
-		MaxBackups          int          `json:",default=0"`
-		MaxSize             int          `json:",default=0"`
-		Rotation            string       `json:",default=daily,options=[daily,size]"`
-		FileTimeFormat      string       `json:",optional"`
-		FieldKeys           fieldKeyConf `json:",optional"`
-		Development         bool         `json:",optional"`
-		CallerSkip          int          `json:",default=2"`
-		CollectSysLog       bool         `json:",optional"`
-		SysLogLevel         string       `json:",default=info,options=[debug,info,error,severe]"`
+		ServiceName         string                          `json:",optional"`
+		Mode                string                          `json:",default=console,options=[console,file,volume,kafka,loki,syslog]"`
+		Outputs             []string                        `json:",optional"`
+		Encoding            string                          `json:",default=json,options=[json,console]"`
+		TimeFormat          string                          `json:",optional"`
+		Path                string                          `json:",default=logs"`
+		Level               string                          `json:",default=info,options=[debug,info,error,severe]"`
+		MaxContentLength    uint32                          `json:",optional"`
+		Compress            bool                            `json:",optional"`
+		KeepDays            int                             `json:",optional"`
+		StackCooldownMillis int                             `json:",default=100"`
+		MaxBackups          int                             `json:",default=0"`
+		MaxSize             int                             `json:",default=0"`
+		Rotation            string                          `json:",default=daily,options=[daily,size]"`
+		FileTimeFormat      string                          `json:",optional"`
+		FieldKeys           fieldKeyConf                    `json:",optional"`
+		Development         bool                            `json:",optional"`
+		CallerSkip          int                             `json:",default=2"`
+		CollectSysLog       bool                            `json:",optional"`
+		SysLogLevel         string                          `json:",default=info,options=[debug,info,error,severe]"`
+		Sampling            map[string]SamplingRule         `json:",optional"`
+		SamplingTick        time.Duration                   `json:",default=1s"`
+		Kafka               KafkaConf                       `json:",optional"`
+		Loki                LokiConf                        `json:",optional"`
+		Syslog              SyslogConf                      `json:",optional"`
+		MaxTotalSize        int                             `json:",optional"`
+		Categories          map[string]RotateConf           `json:",optional"`
+		OnRotate            func(category, filename string) `json:"-"`
+		ModuleLevels        map[string]string               `json:",optional"`
+		Async               AsyncConf                       `json:",optional"`
+		Routes              []RouteConf                     `json:",optional"`
+	}
+
+	// RotateConf overrides the rotation settings of a log category:
+	// access, error, severe, slow or stat. Zero values inherit from LogConf.
+	RotateConf struct {
+		Rotation     string `json:",optional,options=[daily,size]"`
+		MaxSize      int    `json:",optional"`
+		MaxBackups   int    `json:",optional"`
+		KeepDays     int    `json:",optional"`
+		MaxTotalSize int    `json:",optional"`
+		Compress     *bool  `json:",optional"`
+	}
+
+	SyslogConf struct {
+		Network  string `json:",optional"` // empty to use the local syslog daemon
+		Address  string `json:",optional"`
+		Tag      string `json:",optional"` // defaults to ServiceName
+		Facility string `json:",default=local0"`
 	}
 
 	fieldKeyConf struct {
@@ -28,9 +65,47 @@ type (
 		ContentKey   string `json:",default=content"`
 		DurationKey  string `json:",default=duration"`
 		LevelKey     string `json:",default=level"`
+		RequestKey   string `json:",default=request_id"`
 		SpanKey      string `json:",default=span"`
 		TimestampKey string `json:",default=@timestamp"`
 		TraceKey     string `json:",default=trace"`
 		TruncatedKey string `json:",default=truncated"`
 	}
 )
+
+var logCategories = []string{"access", "error", "severe", "slow", "stat"}
+
+func (c LogConf) checkCategories() error {
+	for name := range c.Categories {
+		if !slices.Contains(logCategories, name) {
+			return fmt.Errorf("unknown log category: %q, must be one of %s", name, strings.Join(logCategories, ", "))
+		}
+	}
+
+	return nil
+}
+
+// rotateConf returns the rotation settings of the category.
+func (c LogConf) rotateConf(category string) RotateConf {
+	rc := c.Categories[category]
+	if len(rc.Rotation) == 0 {
+		rc.Rotation = c.Rotation
+	}
+	if rc.MaxSize == 0 {
+		rc.MaxSize = c.MaxSize
+	}
+	if rc.MaxBackups == 0 {
+		rc.MaxBackups = c.MaxBackups
+	}
+	if rc.KeepDays == 0 {
+		rc.KeepDays = c.KeepDays
+	}
+	if rc.MaxTotalSize == 0 {
+		rc.MaxTotalSize = c.MaxTotalSize
+	}
+	if rc.Compress == nil {
+		rc.Compress = &c.Compress
+	}
+
+	return rc
+}
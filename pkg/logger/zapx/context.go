@@ -0,0 +1,93 @@
+package zapx
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+
+	"github.com/tedwangl/go-util/pkg/trace"
+)
+
+type (
+	// ContextExtractor extracts log fields from the context, called on every log line
+	// written by a logger created with WithContext.
+	ContextExtractor func(ctx context.Context) []LogField
+
+	requestIDKey struct{}
+)
+
+var (
+	contextExtractors     atomic.Value
+	contextExtractorsLock sync.Mutex
+)
+
+// AddContextExtractors registers extractors for custom context values.
+// They run after the built-in trace, span and request id extraction.
+func AddContextExtractors(extractors ...ContextExtractor) {
+	contextExtractorsLock.Lock()
+	defer contextExtractorsLock.Unlock()
+
+	old := getContextExtractors()
+	all := make([]ContextExtractor, 0, len(old)+len(extractors))
+	all = append(all, old...)
+	all = append(all, extractors...)
+	contextExtractors.Store(all)
+}
+
+// ContextKeyExtractor returns a ContextExtractor that logs ctx.Value(key) as field name,
+// nothing is logged if the value is absent.
+func ContextKeyExtractor(key any, name string) ContextExtractor {
+	return func(ctx context.Context) []LogField {
+		if val := ctx.Value(key); val != nil {
+			return []LogField{Field(name, val)}
+		}
+		return nil
+	}
+}
+
+// ContextWithRequestID returns a copy of ctx carrying the request id,
+// which is logged under the request key.
+func ContextWithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, requestID)
+}
+
+// RequestIDFromContext returns the request id carried by ctx, or empty if none.
+func RequestIDFromContext(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+func getContextExtractors() []ContextExtractor {
+	extractors := contextExtractors.Load()
+	if extractors == nil {
+		return nil
+	}
+	return extractors.([]ContextExtractor)
+}
+
+// extractContextFields extracts the OpenTelemetry trace/span id, the request id
+// and the custom fields from ctx.
+func extractContextFields(ctx context.Context) []LogField {
+	if ctx == nil {
+		return nil
+	}
+
+	var fields []LogField
+	if traceID := trace.TraceIDFromContext(ctx); len(traceID) > 0 {
+		fields = append(fields, Field(traceKey, traceID))
+	}
+	if spanID := trace.SpanIDFromContext(ctx); len(spanID) > 0 {
+		fields = append(fields, Field(spanKey, spanID))
+	}
+	if requestID := RequestIDFromContext(ctx); len(requestID) > 0 {
+		fields = append(fields, Field(requestKey, requestID))
+	}
+	for _, extract := range getContextExtractors() {
+		fields = append(fields, extract(ctx)...)
+	}
+
+	return fields
+}
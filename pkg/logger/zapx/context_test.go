@@ -0,0 +1,41 @@
+package zapx_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/tedwangl/go-util/pkg/logger/zapx"
+	"github.com/tedwangl/go-util/pkg/logger/zapx/zaptest"
+)
+
+type tenantKey struct{}
+
+func TestContextRequestID(t *testing.T) {
+	assert.Empty(t, zapx.RequestIDFromContext(context.Background()))
+
+	ctx := zapx.ContextWithRequestID(context.Background(), "req-1")
+	assert.Equal(t, "req-1", zapx.RequestIDFromContext(ctx))
+
+	w := zaptest.NewWriter(t)
+	zapx.WithContext(ctx).Info("hello")
+	entries := w.Entries()
+	if assert.Len(t, entries, 1) {
+		assert.Equal(t, "req-1", entries[0].Fields["request_id"])
+	}
+}
+
+func TestContextExtractors(t *testing.T) {
+	zapx.AddContextExtractors(zapx.ContextKeyExtractor(tenantKey{}, "tenant"))
+
+	w := zaptest.NewWriter(t)
+	zapx.WithContext(context.Background()).Info("without tenant")
+	zapx.WithContext(context.WithValue(context.Background(), tenantKey{}, "acme")).Infow("with tenant")
+
+	entries := w.Entries()
+	if assert.Len(t, entries, 2) {
+		assert.NotContains(t, entries[0].Fields, "tenant")
+		assert.Equal(t, "acme", entries[1].Fields["tenant"])
+	}
+}
@@ -0,0 +1,168 @@
+package zapx
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"runtime"
+	"strings"
+)
+
+const (
+	maxStackDepth     = 32
+	fingerprintFrames = 8
+
+	errorKey       = "error"
+	causesKey      = "causes"
+	stackKey       = "stack"
+	fingerprintKey = "fingerprint"
+)
+
+// stackError carries the stack captured where the error was wrapped.
+type stackError struct {
+	err   error
+	stack []uintptr
+}
+
+func (e *stackError) Error() string {
+	return e.err.Error()
+}
+
+func (e *stackError) Unwrap() error {
+	return e.err
+}
+
+// Wrap records the current stack into err, so that ErrorE logs the stack of the
+// error's origin instead of the logging site. It returns nil if err is nil, and err
+// itself if a stack is already recorded in its chain.
+func Wrap(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	var se *stackError
+	if errors.As(err, &se) {
+		return err
+	}
+
+	pcs := make([]uintptr, maxStackDepth)
+	n := runtime.Callers(2, pcs)
+	return &stackError{
+		err:   err,
+		stack: pcs[:n],
+	}
+}
+
+// ErrorE logs err at error level with its cause chain, the stack recorded by Wrap
+// and a fingerprint that stays the same for the same kind of error from the same origin,
+// which can be used to aggregate the errors.
+func ErrorE(err error, msg string, fields ...LogField) {
+	if err == nil || !shallLog(ErrorLevel) {
+		return
+	}
+
+	errFields := errorFields(err)
+	allFields := make([]LogField, 0, len(errFields)+len(fields))
+	allFields = append(allFields, errFields...)
+	allFields = append(allFields, fields...)
+	getWriter().Error(callerDepth, msg, processSensitiveFields(allFields...)...)
+}
+
+// Fingerprint returns a stable hash of err, made of the root cause type and the
+// functions of the recorded stack, or the root cause message if no stack is recorded.
+func Fingerprint(err error) string {
+	if err == nil {
+		return ""
+	}
+
+	causes, stack := unwrapError(err)
+	root := causes[len(causes)-1]
+
+	h := sha1.New()
+	fmt.Fprintf(h, "%T", root)
+	if len(stack) == 0 {
+		h.Write([]byte(root.Error()))
+	} else {
+		frames := runtime.CallersFrames(stack)
+		for i := 0; i < fingerprintFrames; i++ {
+			frame, more := frames.Next()
+			h.Write([]byte(frame.Function))
+			if !more {
+				break
+			}
+		}
+	}
+
+	return hex.EncodeToString(h.Sum(nil))[:16]
+}
+
+func errorFields(err error) []LogField {
+	causes, stack := unwrapError(err)
+	fields := []LogField{
+		Field(errorKey, err.Error()),
+		Field(fingerprintKey, Fingerprint(err)),
+	}
+
+	if len(causes) > 1 {
+		chain := make([]string, 0, len(causes)-1)
+		for _, cause := range causes[1:] {
+			chain = append(chain, cause.Error())
+		}
+		fields = append(fields, Field(causesKey, chain))
+	}
+	if len(stack) > 0 {
+		fields = append(fields, Field(stackKey, formatStack(stack)))
+	}
+
+	return fields
+}
+
+// unwrapError returns the chain of errors from err to the root cause, and the
+// innermost recorded stack. Joined errors are followed through their first error.
+func unwrapError(err error) ([]error, []uintptr) {
+	var (
+		causes []error
+		stack  []uintptr
+	)
+
+	for err != nil {
+		if se, ok := err.(*stackError); ok {
+			stack = se.stack
+		} else {
+			causes = append(causes, err)
+		}
+
+		switch x := err.(type) {
+		case interface{ Unwrap() error }:
+			err = x.Unwrap()
+		case interface{ Unwrap() []error }:
+			if errs := x.Unwrap(); len(errs) > 0 {
+				err = errs[0]
+			} else {
+				err = nil
+			}
+		default:
+			err = nil
+		}
+	}
+
+	return causes, stack
+}
+
+func formatStack(stack []uintptr) string {
+	var sb strings.Builder
+	frames := runtime.CallersFrames(stack)
+	for {
+		frame, more := frames.Next()
+		sb.WriteString(frame.Function)
+		sb.WriteString("\n\t")
+		sb.WriteString(prettyCaller(frame.File, frame.Line))
+		if !more {
+			break
+		}
+		sb.WriteByte('\n')
+	}
+
+	return sb.String()
+}
@@ -0,0 +1,57 @@
+package zapx_test
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/tedwangl/go-util/pkg/logger/zapx"
+	"github.com/tedwangl/go-util/pkg/logger/zapx/zaptest"
+)
+
+var errNotFound = errors.New("not found")
+
+func findUser(id int) error {
+	return zapx.Wrap(fmt.Errorf("user %d: %w", id, errNotFound))
+}
+
+func TestWrap(t *testing.T) {
+	assert.NoError(t, zapx.Wrap(nil))
+
+	err := findUser(1)
+	assert.ErrorIs(t, err, errNotFound)
+	assert.Equal(t, "user 1: not found", err.Error())
+	assert.Same(t, err, zapx.Wrap(err))
+}
+
+func TestFingerprint(t *testing.T) {
+	assert.Empty(t, zapx.Fingerprint(nil))
+
+	// same origin, different messages
+	assert.Equal(t, zapx.Fingerprint(findUser(1)), zapx.Fingerprint(findUser(2)))
+	assert.NotEqual(t, zapx.Fingerprint(findUser(1)), zapx.Fingerprint(zapx.Wrap(errNotFound)))
+
+	// without stack, the root cause message is used
+	assert.Equal(t, zapx.Fingerprint(fmt.Errorf("a: %w", errNotFound)), zapx.Fingerprint(fmt.Errorf("b: %w", errNotFound)))
+	assert.NotEqual(t, zapx.Fingerprint(errNotFound), zapx.Fingerprint(errors.New("other")))
+}
+
+func TestErrorE(t *testing.T) {
+	w := zaptest.NewWriter(t)
+	zapx.ErrorE(nil, "ignored")
+	zapx.ErrorE(fmt.Errorf("load profile: %w", findUser(1)), "request failed", zapx.Field("path", "/users/1"))
+
+	entries := w.Entries()
+	if assert.Len(t, entries, 1) {
+		entry := entries[0]
+		assert.Equal(t, zaptest.LevelError, entry.Level)
+		assert.Equal(t, "request failed", entry.Content)
+		assert.Equal(t, "load profile: user 1: not found", entry.Fields["error"])
+		assert.Equal(t, []string{"user 1: not found", "not found"}, entry.Fields["causes"])
+		assert.Contains(t, entry.Fields["stack"], "zapx_test.findUser")
+		assert.Equal(t, zapx.Fingerprint(findUser(2)), entry.Fields["fingerprint"])
+		assert.Equal(t, "/users/1", entry.Fields["path"])
+	}
+}
@@ -72,27 +72,18 @@ func getContextFields(ctx context.Context) []LogField {
 
 func mergeFields(ctx context.Context, fields ...LogField) []LogField {
 	globals := getGlobalFields()
+	extracted := extractContextFields(ctx)
 	contextFields := getContextFields(ctx)
 
-	totalLen := len(fields)
-	if globals != nil {
-		totalLen += len(globals)
-	}
-	if contextFields != nil {
-		totalLen += len(contextFields)
-	}
-
-	if totalLen == 0 {
+	totalLen := len(fields) + len(globals) + len(extracted) + len(contextFields)
+	if totalLen == len(fields) {
 		return fields
 	}
 
 	result := make([]LogField, 0, totalLen)
-	if globals != nil {
-		result = append(result, globals...)
-	}
-	if contextFields != nil {
-		result = append(result, contextFields...)
-	}
+	result = append(result, globals...)
+	result = append(result, extracted...)
+	result = append(result, contextFields...)
 	result = append(result, fields...)
 
 	return result
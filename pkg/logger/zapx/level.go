@@ -0,0 +1,157 @@
+package zapx
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync/atomic"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// zapLevel is shared by all the cores, kept in sync with the lowest of logLevel
+// and the module levels, the levels are checked before writing.
+var zapLevel = zap.NewAtomicLevelAt(zapcore.DebugLevel)
+
+type levelPayload struct {
+	Module string `json:"module,omitempty"`
+	Level  string `json:"level"`
+}
+
+// SetLevel sets the log level, safe to call at runtime.
+func SetLevel(level uint32) {
+	atomic.StoreUint32(&logLevel, level)
+	updateZapLevel()
+}
+
+// GetLevel returns the current log level.
+func GetLevel() uint32 {
+	return atomic.LoadUint32(&logLevel)
+}
+
+// ParseLevel parses debug, info, error or severe into the log level.
+// warn is accepted as error, which also logs the slow logs written at warn level.
+func ParseLevel(level string) (uint32, error) {
+	switch level {
+	case levelDebug:
+		return DebugLevel, nil
+	case levelInfo:
+		return InfoLevel, nil
+	case levelError, levelWarn:
+		return ErrorLevel, nil
+	case levelSevere:
+		return SevereLevel, nil
+	default:
+		return 0, fmt.Errorf("unknown log level: %q", level)
+	}
+}
+
+// LevelHandler returns an http.Handler to get or change the log level at runtime,
+// compatible with zap.AtomicLevel:
+//
+//	GET  returns {"level":"info"}
+//	PUT  with {"level":"debug"} or form level=debug changes the level
+//
+// With the query parameter module, the level of the module is returned or changed.
+func LevelHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		enc := json.NewEncoder(w)
+		w.Header().Set("Content-Type", "application/json")
+		module := r.URL.Query().Get("module")
+
+		switch r.Method {
+		case http.MethodGet:
+		case http.MethodPut:
+			name, err := decodeLevel(r)
+			if err != nil {
+				w.WriteHeader(http.StatusBadRequest)
+				_ = enc.Encode(map[string]string{"error": err.Error()})
+				return
+			}
+
+			level, err := ParseLevel(name)
+			if err != nil {
+				w.WriteHeader(http.StatusBadRequest)
+				_ = enc.Encode(map[string]string{"error": err.Error()})
+				return
+			}
+			if len(module) > 0 {
+				SetModuleLevel(module, level)
+			} else {
+				SetLevel(level)
+			}
+		default:
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			_ = enc.Encode(map[string]string{"error": "only GET and PUT are supported"})
+			return
+		}
+
+		level := GetLevel()
+		if moduleLevel, ok := getModuleLevel(module); ok {
+			level = moduleLevel
+		}
+		_ = enc.Encode(levelPayload{Module: module, Level: levelName(level)})
+	})
+}
+
+func decodeLevel(r *http.Request) (string, error) {
+	if r.Header.Get("Content-Type") == "application/x-www-form-urlencoded" {
+		level := r.FormValue("level")
+		if len(level) == 0 {
+			return "", fmt.Errorf("must specify logging level")
+		}
+		return level, nil
+	}
+
+	var payload levelPayload
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		return "", fmt.Errorf("request body must be well-formed JSON: %w", err)
+	}
+	if len(payload.Level) == 0 {
+		return "", fmt.Errorf("must specify logging level")
+	}
+
+	return payload.Level, nil
+}
+
+func levelName(level uint32) string {
+	switch level {
+	case DebugLevel:
+		return levelDebug
+	case InfoLevel:
+		return levelInfo
+	case ErrorLevel:
+		return levelError
+	case SevereLevel:
+		return levelSevere
+	default:
+		return "disabled"
+	}
+}
+
+// updateZapLevel lets the cores write the entries of the lowest level in use.
+func updateZapLevel() {
+	level := GetLevel()
+	for _, moduleLevel := range loadModuleLevels() {
+		level = min(level, moduleLevel)
+	}
+	zapLevel.SetLevel(toZapLevel(level))
+}
+
+// toZapLevel maps the log level to the zap level of the cores.
+// Slow logs are written at warn level and severe logs at error level.
+func toZapLevel(level uint32) zapcore.Level {
+	switch level {
+	case DebugLevel:
+		return zapcore.DebugLevel
+	case InfoLevel:
+		return zapcore.InfoLevel
+	case ErrorLevel:
+		return zapcore.WarnLevel
+	case SevereLevel:
+		return zapcore.ErrorLevel
+	default:
+		return zapcore.FatalLevel + 1
+	}
+}
@@ -0,0 +1,84 @@
+package zapx_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/tedwangl/go-util/pkg/logger/zapx"
+	"github.com/tedwangl/go-util/pkg/logger/zapx/zaptest"
+)
+
+func TestParseLevel(t *testing.T) {
+	tests := []struct {
+		name  string
+		level uint32
+	}{
+		{"debug", zapx.DebugLevel},
+		{"info", zapx.InfoLevel},
+		{"warn", zapx.ErrorLevel},
+		{"error", zapx.ErrorLevel},
+		{"severe", zapx.SevereLevel},
+	}
+
+	for _, test := range tests {
+		level, err := zapx.ParseLevel(test.name)
+		assert.NoError(t, err)
+		assert.Equal(t, test.level, level, test.name)
+	}
+
+	_, err := zapx.ParseLevel("verbose")
+	assert.Error(t, err)
+}
+
+func TestSetLevel(t *testing.T) {
+	w := zaptest.NewWriter(t)
+	zapx.SetLevel(zapx.ErrorLevel)
+	zapx.Info("info")
+	zapx.Error("error")
+
+	w.AssertNotLogged(zaptest.LevelInfo, "info")
+	w.AssertLogged(zaptest.LevelError, "error")
+}
+
+func TestLevelHandler(t *testing.T) {
+	zaptest.NewWriter(t)
+	handler := zapx.LevelHandler()
+
+	serve := func(method, target, body, contentType string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(method, target, strings.NewReader(body))
+		if len(contentType) > 0 {
+			r.Header.Set("Content-Type", contentType)
+		}
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, r)
+		return w
+	}
+
+	resp := serve(http.MethodGet, "/", "", "")
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.JSONEq(t, `{"level":"debug"}`, resp.Body.String())
+
+	resp = serve(http.MethodPut, "/", `{"level":"error"}`, "application/json")
+	assert.JSONEq(t, `{"level":"error"}`, resp.Body.String())
+	assert.Equal(t, zapx.ErrorLevel, zapx.GetLevel())
+
+	resp = serve(http.MethodPut, "/", "level=info", "application/x-www-form-urlencoded")
+	assert.JSONEq(t, `{"level":"info"}`, resp.Body.String())
+	assert.Equal(t, zapx.InfoLevel, zapx.GetLevel())
+
+	resp = serve(http.MethodPut, "/?module=level-test", `{"level":"severe"}`, "")
+	assert.JSONEq(t, `{"module":"level-test","level":"severe"}`, resp.Body.String())
+	assert.Equal(t, zapx.InfoLevel, zapx.GetLevel())
+	zapx.ResetModuleLevel("level-test")
+
+	resp = serve(http.MethodPut, "/", `{"level":"verbose"}`, "")
+	assert.Equal(t, http.StatusBadRequest, resp.Code)
+	resp = serve(http.MethodPut, "/", `{}`, "")
+	assert.Equal(t, http.StatusBadRequest, resp.Code)
+	resp = serve(http.MethodPost, "/", "", "")
+	assert.Equal(t, http.StatusMethodNotAllowed, resp.Code)
+}
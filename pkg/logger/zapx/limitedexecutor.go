@@ -7,7 +7,7 @@ import (
 
 type limitedExecutor struct {
 	threshold time.Duration
-	lastTime  atomic.Value
+	lastTime  atomic.Int64 // 上次执行的时间（UnixNano）
 	discarded atomic.Uint32
 }
 
@@ -22,36 +22,43 @@ func (le *limitedExecutor) LogOrDiscard(execute func()) {
 }
 
 func newLimitedExecutor(milliseconds int) *limitedExecutor {
+	return newIntervalExecutor(time.Duration(milliseconds) * time.Millisecond)
+}
+
+// newIntervalExecutor 创建一个在 interval 内最多执行一次的执行器
+func newIntervalExecutor(interval time.Duration) *limitedExecutor {
 	le := &limitedExecutor{
-		threshold: time.Duration(milliseconds) * time.Millisecond,
-		lastTime:  atomic.Value{},
+		threshold: interval,
 	}
-	le.lastTime.Store(time.Now().Add(-24 * time.Hour))
+	le.lastTime.Store(time.Now().Add(-24 * time.Hour).UnixNano())
 	return le
 }
 
-func (le *limitedExecutor) logOrDiscard(execute func()) {
+// allow 判断本次是否可以执行，可以执行时返回上次执行后丢弃的次数
+// 并发调用时同一周期内只有一个调用方返回 true
+func (le *limitedExecutor) allow() (bool, uint32) {
 	if le == nil || le.threshold <= 0 {
-		execute()
-		return
+		return true, 0
 	}
 
-	now := time.Now()
-	lastTimeValue := le.lastTime.Load()
-	var lastTime time.Time
-	if lastTimeValue != nil {
-		lastTime, _ = lastTimeValue.(time.Time)
+	now := time.Now().UnixNano()
+	last := le.lastTime.Load()
+	if now-last <= int64(le.threshold) || !le.lastTime.CompareAndSwap(last, now) {
+		le.discarded.Add(1)
+		return false, 0
 	}
 
-	if !lastTime.IsZero() && now.Sub(lastTime) <= le.threshold {
-		le.discarded.Add(1)
-	} else {
-		le.lastTime.Store(now)
-		discarded := le.discarded.Swap(0)
-		if discarded > 0 {
-			Errorf("Discarded %d error messages", discarded)
-		}
-
-		execute()
+	return true, le.discarded.Swap(0)
+}
+
+func (le *limitedExecutor) logOrDiscard(execute func()) {
+	ok, discarded := le.allow()
+	if !ok {
+		return
+	}
+
+	if discarded > 0 {
+		Errorf("Discarded %d error messages", discarded)
 	}
+	execute()
 }
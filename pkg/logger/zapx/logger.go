@@ -34,10 +34,11 @@ type Logger interface {
 }
 
 type baseLogger struct {
-	writer Writer
+	writer Writer // nil to use the global writer at the time of logging
 	ctx    context.Context
 	fields []LogField
 	skip   int
+	module string
 }
 
 func newLogger(writer Writer) Logger {
@@ -48,143 +49,162 @@ func newLogger(writer Writer) Logger {
 }
 
 func (l *baseLogger) Debug(v ...any) {
-	if shallLog(DebugLevel) {
-		l.writer.Debug(l.skip, fmt.Sprint(v...), mergeFields(l.ctx, l.fields...)...)
+	if l.shallLog(DebugLevel) {
+		l.output().Debug(l.skip, fmt.Sprint(v...), mergeFields(l.ctx, l.fields...)...)
 	}
 }
 
 func (l *baseLogger) Debugf(format string, v ...any) {
-	if shallLog(DebugLevel) {
-		l.writer.Debug(l.skip, fmt.Sprintf(format, v...), mergeFields(l.ctx, l.fields...)...)
+	if l.shallLog(DebugLevel) {
+		l.output().Debug(l.skip, fmt.Sprintf(format, v...), mergeFields(l.ctx, l.fields...)...)
 	}
 }
 
 func (l *baseLogger) Debugfn(fn func() any) {
-	if shallLog(DebugLevel) {
-		l.writer.Debug(l.skip, fn(), mergeFields(l.ctx, l.fields...)...)
+	if l.shallLog(DebugLevel) {
+		l.output().Debug(l.skip, fn(), mergeFields(l.ctx, l.fields...)...)
 	}
 }
 
 func (l *baseLogger) Debugv(v any) {
-	if shallLog(DebugLevel) {
-		l.writer.Debug(l.skip, v, mergeFields(l.ctx, l.fields...)...)
+	if l.shallLog(DebugLevel) {
+		l.output().Debug(l.skip, v, mergeFields(l.ctx, l.fields...)...)
 	}
 }
 
 func (l *baseLogger) Debugw(msg string, fields ...LogField) {
-	if shallLog(DebugLevel) {
+	if l.shallLog(DebugLevel) {
 		allFields := mergeFields(l.ctx, l.fields...)
 		allFields = append(allFields, Field(contentKey, msg))
 		allFields = append(allFields, fields...)
-		l.writer.Debug(l.skip, "", allFields...)
+		l.output().Debug(l.skip, "", allFields...)
 	}
 }
 
 func (l *baseLogger) Error(v ...any) {
-	if shallLog(ErrorLevel) {
-		l.writer.Error(l.skip, fmt.Sprint(v...), mergeFields(l.ctx, l.fields...)...)
+	if l.shallLog(ErrorLevel) {
+		l.output().Error(l.skip, fmt.Sprint(v...), mergeFields(l.ctx, l.fields...)...)
 	}
 }
 
 func (l *baseLogger) Errorf(format string, v ...any) {
-	if shallLog(ErrorLevel) {
-		l.writer.Error(l.skip, fmt.Errorf(format, v...).Error(), mergeFields(l.ctx, l.fields...)...)
+	if l.shallLog(ErrorLevel) {
+		l.output().Error(l.skip, fmt.Errorf(format, v...).Error(), mergeFields(l.ctx, l.fields...)...)
 	}
 }
 
 func (l *baseLogger) Errorfn(fn func() any) {
-	if shallLog(ErrorLevel) {
-		l.writer.Error(l.skip, fn(), mergeFields(l.ctx, l.fields...)...)
+	if l.shallLog(ErrorLevel) {
+		l.output().Error(l.skip, fn(), mergeFields(l.ctx, l.fields...)...)
 	}
 }
 
 func (l *baseLogger) Errorv(v any) {
-	if shallLog(ErrorLevel) {
-		l.writer.Error(l.skip, v, mergeFields(l.ctx, l.fields...)...)
+	if l.shallLog(ErrorLevel) {
+		l.output().Error(l.skip, v, mergeFields(l.ctx, l.fields...)...)
 	}
 }
 
 func (l *baseLogger) Errorw(msg string, fields ...LogField) {
-	if shallLog(ErrorLevel) {
+	if l.shallLog(ErrorLevel) {
 		allFields := mergeFields(l.ctx, l.fields...)
 		allFields = append(allFields, Field(contentKey, msg))
 		allFields = append(allFields, fields...)
-		l.writer.Error(l.skip, "", allFields...)
+		l.output().Error(l.skip, "", allFields...)
 	}
 }
 
 func (l *baseLogger) Info(v ...any) {
-	if shallLog(InfoLevel) {
-		l.writer.Info(l.skip, fmt.Sprint(v...), mergeFields(l.ctx, l.fields...)...)
+	if l.shallLog(InfoLevel) {
+		l.output().Info(l.skip, fmt.Sprint(v...), mergeFields(l.ctx, l.fields...)...)
 	}
 }
 
 func (l *baseLogger) Infof(format string, v ...any) {
-	if shallLog(InfoLevel) {
-		l.writer.Info(l.skip, fmt.Sprintf(format, v...), mergeFields(l.ctx, l.fields...)...)
+	if l.shallLog(InfoLevel) {
+		l.output().Info(l.skip, fmt.Sprintf(format, v...), mergeFields(l.ctx, l.fields...)...)
 	}
 }
 
 func (l *baseLogger) Infofn(fn func() any) {
-	if shallLog(InfoLevel) {
-		l.writer.Info(l.skip, fn(), mergeFields(l.ctx, l.fields...)...)
+	if l.shallLog(InfoLevel) {
+		l.output().Info(l.skip, fn(), mergeFields(l.ctx, l.fields...)...)
 	}
 }
 
 func (l *baseLogger) Infov(v any) {
-	if shallLog(InfoLevel) {
-		l.writer.Info(l.skip, v, mergeFields(l.ctx, l.fields...)...)
+	if l.shallLog(InfoLevel) {
+		l.output().Info(l.skip, v, mergeFields(l.ctx, l.fields...)...)
 	}
 }
 
 func (l *baseLogger) Infow(msg string, fields ...LogField) {
-	if shallLog(InfoLevel) {
+	if l.shallLog(InfoLevel) {
 		allFields := mergeFields(l.ctx, l.fields...)
 		allFields = append(allFields, Field(contentKey, msg))
 		allFields = append(allFields, fields...)
-		l.writer.Info(l.skip, "", allFields...)
+		l.output().Info(l.skip, "", allFields...)
 	}
 }
 
 func (l *baseLogger) Slow(v ...any) {
-	if shallLog(ErrorLevel) {
-		l.writer.Slow(l.skip, fmt.Sprint(v...), mergeFields(l.ctx, l.fields...)...)
+	if l.shallLog(ErrorLevel) {
+		l.output().Slow(l.skip, fmt.Sprint(v...), mergeFields(l.ctx, l.fields...)...)
 	}
 }
 
 func (l *baseLogger) Slowf(format string, v ...any) {
-	if shallLog(ErrorLevel) {
-		l.writer.Slow(l.skip, fmt.Sprintf(format, v...), mergeFields(l.ctx, l.fields...)...)
+	if l.shallLog(ErrorLevel) {
+		l.output().Slow(l.skip, fmt.Sprintf(format, v...), mergeFields(l.ctx, l.fields...)...)
 	}
 }
 
 func (l *baseLogger) Slowfn(fn func() any) {
-	if shallLog(ErrorLevel) {
-		l.writer.Slow(l.skip, fn(), mergeFields(l.ctx, l.fields...)...)
+	if l.shallLog(ErrorLevel) {
+		l.output().Slow(l.skip, fn(), mergeFields(l.ctx, l.fields...)...)
 	}
 }
 
 func (l *baseLogger) Slowv(v any) {
-	if shallLog(ErrorLevel) {
-		l.writer.Slow(l.skip, v, mergeFields(l.ctx, l.fields...)...)
+	if l.shallLog(ErrorLevel) {
+		l.output().Slow(l.skip, v, mergeFields(l.ctx, l.fields...)...)
 	}
 }
 
 func (l *baseLogger) Sloww(msg string, fields ...LogField) {
-	if shallLog(ErrorLevel) {
+	if l.shallLog(ErrorLevel) {
 		allFields := mergeFields(l.ctx, l.fields...)
 		allFields = append(allFields, Field(contentKey, msg))
 		allFields = append(allFields, fields...)
-		l.writer.Slow(l.skip, "", allFields...)
+		l.output().Slow(l.skip, "", allFields...)
 	}
 }
 
+// output returns the writer of the logger, or the current global writer if none is bound.
+func (l *baseLogger) output() Writer {
+	if l.writer != nil {
+		return l.writer
+	}
+	return getWriter()
+}
+
+func (l *baseLogger) shallLog(level uint32) bool {
+	if len(l.module) > 0 {
+		if moduleLevel, ok := getModuleLevel(l.module); ok {
+			return moduleLevel <= level
+		}
+	}
+
+	return shallLog(level)
+}
+
 func (l *baseLogger) WithCallerSkip(skip int) Logger {
 	return &baseLogger{
 		writer: l.writer,
 		ctx:    l.ctx,
 		fields: l.fields,
 		skip:   l.skip + skip,
+		module: l.module,
 	}
 }
 
@@ -194,6 +214,7 @@ func (l *baseLogger) WithContext(ctx context.Context) Logger {
 		ctx:    ctx,
 		fields: l.fields,
 		skip:   l.skip,
+		module: l.module,
 	}
 }
 
@@ -206,6 +227,7 @@ func (l *baseLogger) WithDuration(d time.Duration) Logger {
 		ctx:    l.ctx,
 		fields: newFields,
 		skip:   l.skip,
+		module: l.module,
 	}
 }
 
@@ -218,5 +240,6 @@ func (l *baseLogger) WithFields(fields ...LogField) Logger {
 		ctx:    l.ctx,
 		fields: newFields,
 		skip:   l.skip,
+		module: l.module,
 	}
 }
@@ -376,6 +376,13 @@ func Statf(format string, v ...any) {
 func SetUp(c LogConf) error {
 	var err error
 	setupOnce.Do(func() {
+		if err = checkSampling(c.Sampling); err != nil {
+			return
+		}
+		if err = setupModuleLevels(c.ModuleLevels); err != nil {
+			return
+		}
+
 		setLogLevel(c.Level)
 		setupFieldKeys(c.FieldKeys)
 
@@ -387,15 +394,21 @@ func SetUp(c LogConf) error {
 			atomic.StoreUint32(&maxContentLength, c.MaxContentLength)
 		}
 
-		switch c.Mode {
-		case "file":
-			err = setupWithFiles(c)
-		case "volume":
-			err = setupWithVolume(c)
-		case "multi":
-			err = setupWithMulti(c)
-		default:
-			setupWithConsole(c)
+		if len(c.Outputs) > 0 {
+			err = setupWithOutputs(c, c.Outputs...)
+		} else {
+			switch c.Mode {
+			case "file":
+				err = setupWithFiles(c)
+			case "volume":
+				err = setupWithVolume(c)
+			case "multi":
+				err = setupWithMulti(c)
+			case "kafka", "loki", "syslog":
+				err = setupWithOutputs(c, c.Mode)
+			default:
+				setupWithConsole(c)
+			}
 		}
 
 		// 重定向系统日志
@@ -436,6 +449,9 @@ func setupFieldKeys(c fieldKeyConf) {
 	if len(c.LevelKey) > 0 {
 		levelKey = c.LevelKey
 	}
+	if len(c.RequestKey) > 0 {
+		requestKey = c.RequestKey
+	}
 	if len(c.SpanKey) > 0 {
 		spanKey = c.SpanKey
 	}
@@ -492,4 +508,50 @@ func setupWithMulti(c LogConf) error {
 	return nil
 }
 
+// setupWithOutputs 按 outputs 创建写入器，多个时通过 multiWriter 组合
+func setupWithOutputs(c LogConf, outputs ...string) error {
+	writers := make([]Writer, 0, len(outputs))
+	for _, output := range outputs {
+		w, err := newOutputWriter(c, output)
+		if err != nil {
+			for _, created := range writers {
+				_ = created.Close()
+			}
+			return fmt.Errorf("create %s writer: %w", output, err)
+		}
+		writers = append(writers, w)
+	}
+
+	if len(writers) == 1 {
+		SetWriter(writers[0])
+	} else {
+		SetWriter(NewMultiWriter(writers...))
+	}
+
+	return nil
+}
+
+func newOutputWriter(c LogConf, output string) (Writer, error) {
+	switch output {
+	case "console":
+		return newConsoleWriter(c), nil
+	case "file":
+		return newFileWriter(c)
+	case "volume":
+		if len(c.ServiceName) == 0 {
+			return nil, ErrLogServiceNameNotSet
+		}
+		c.Path = path.Join(c.Path, c.ServiceName)
+		return newFileWriter(c)
+	case "kafka":
+		return newKafkaWriter(c)
+	case "loki":
+		return newLokiWriter(c)
+	case "syslog":
+		return newSyslogWriter(c)
+	default:
+		return nil, fmt.Errorf("unknown log output: %q", output)
+	}
+}
+
 var ExitOnFatal = true
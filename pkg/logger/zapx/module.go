@@ -0,0 +1,74 @@
+package zapx
+
+import (
+	"fmt"
+	"maps"
+	"sync"
+	"sync/atomic"
+)
+
+const moduleKey = "module"
+
+var (
+	moduleLevels     atomic.Value // map[string]uint32
+	moduleLevelsLock sync.Mutex
+)
+
+// Named returns a logger of the module, which logs the module name as a field and
+// uses the module level set by SetModuleLevel or LogConf.ModuleLevels if any,
+// otherwise the global level. The logger writes to the global writer at the time of
+// logging, so it can be created before SetUp, e.g. as a package variable.
+func Named(module string) Logger {
+	return &baseLogger{
+		fields: []LogField{Field(moduleKey, module)},
+		skip:   2,
+		module: module,
+	}
+}
+
+// SetModuleLevel sets the log level of the module, safe to call at runtime.
+func SetModuleLevel(module string, level uint32) {
+	moduleLevelsLock.Lock()
+	defer moduleLevelsLock.Unlock()
+
+	levels := maps.Clone(loadModuleLevels())
+	if levels == nil {
+		levels = make(map[string]uint32)
+	}
+	levels[module] = level
+	moduleLevels.Store(levels)
+	updateZapLevel()
+}
+
+// ResetModuleLevel removes the level of the module, which then follows the global level.
+func ResetModuleLevel(module string) {
+	moduleLevelsLock.Lock()
+	defer moduleLevelsLock.Unlock()
+
+	levels := maps.Clone(loadModuleLevels())
+	delete(levels, module)
+	moduleLevels.Store(levels)
+	updateZapLevel()
+}
+
+func getModuleLevel(module string) (uint32, bool) {
+	level, ok := loadModuleLevels()[module]
+	return level, ok
+}
+
+func loadModuleLevels() map[string]uint32 {
+	levels, _ := moduleLevels.Load().(map[string]uint32)
+	return levels
+}
+
+func setupModuleLevels(levels map[string]string) error {
+	for module, name := range levels {
+		level, err := ParseLevel(name)
+		if err != nil {
+			return fmt.Errorf("module %s: %w", module, err)
+		}
+		SetModuleLevel(module, level)
+	}
+
+	return nil
+}
@@ -0,0 +1,45 @@
+package zapx_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/tedwangl/go-util/pkg/logger/zapx"
+	"github.com/tedwangl/go-util/pkg/logger/zapx/zaptest"
+)
+
+// created before the writer is set, like a package variable
+var moduleLogger = zapx.Named("module-test")
+
+func TestNamed(t *testing.T) {
+	w := zaptest.NewWriter(t)
+	moduleLogger.Infow("hello")
+	zapx.Named("module-test").WithFields(zapx.Field("key", "value")).Info("with fields")
+
+	entries := w.Entries()
+	if assert.Len(t, entries, 2) {
+		assert.Equal(t, "hello", entries[0].Content)
+		assert.Equal(t, "module-test", entries[0].Fields["module"])
+		assert.Equal(t, "value", entries[1].Fields["key"])
+		assert.Equal(t, "module-test", entries[1].Fields["module"])
+	}
+}
+
+func TestModuleLevel(t *testing.T) {
+	w := zaptest.NewWriter(t)
+	logger := zapx.Named("module-level-test")
+
+	zapx.SetModuleLevel("module-level-test", zapx.ErrorLevel)
+	logger.Info("filtered")
+	logger.Error("module error")
+	zapx.Info("global info")
+
+	zapx.ResetModuleLevel("module-level-test")
+	logger.Info("after reset")
+
+	w.AssertNotLogged(zaptest.LevelInfo, "filtered")
+	w.AssertLogged(zaptest.LevelError, "module error")
+	w.AssertLogged(zaptest.LevelInfo, "global info")
+	w.AssertLogged(zaptest.LevelInfo, "after reset")
+}
@@ -1,11 +1,28 @@
 package zapx
 
 import (
+	"errors"
+	"fmt"
 	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
 
 	"gopkg.in/natefinch/lumberjack.v2"
 )
 
+const (
+	// defaultRotateMaxSize is the max size in megabytes of size rotation, same as lumberjack
+	defaultRotateMaxSize = 100
+	megabyte             = 1024 * 1024
+	compressSuffix       = ".gz"
+	// noSizeLimit is the max size in megabytes given to lumberjack for daily rotation
+	noSizeLimit = 1024 * 1024
+)
+
 type RotateRule interface {
 	CurrentFileName() string
 	Gzip() bool
@@ -23,6 +40,9 @@ type sizeRotateRule struct {
 }
 
 func NewSizeRotateRule(filename string, maxSize, maxBackups, maxAge int, gzip bool) RotateRule {
+	if maxSize <= 0 {
+		maxSize = defaultRotateMaxSize
+	}
 	return &sizeRotateRule{
 		filename:   filename,
 		maxSize:    maxSize,
@@ -88,40 +108,193 @@ func (r *dailyRotateRule) MaxSize() int {
 	return 0
 }
 
-type RotateLogger struct {
-	*lumberjack.Logger
-	rule RotateRule
+type (
+	// RotateOption customizes a RotateLogger.
+	RotateOption func(l *RotateLogger)
+
+	RotateLogger struct {
+		*lumberjack.Logger
+		rule         RotateRule
+		maxTotalSize int64
+		onRotate     func(filename string)
+
+		lock sync.Mutex
+		size int64     // size of the current file
+		day  time.Time // day of the current file, for daily rotation
+	}
+)
+
+// WithMaxTotalSize removes the oldest backups when the current file and the backups
+// take more than megabytes of disk, 0 means unlimited.
+func WithMaxTotalSize(megabytes int) RotateOption {
+	return func(l *RotateLogger) {
+		l.maxTotalSize = int64(megabytes) * megabyte
+	}
+}
+
+// WithOnRotate sets a callback called with the log filename after each rotation.
+// The callback must not log into the same file.
+func WithOnRotate(fn func(filename string)) RotateOption {
+	return func(l *RotateLogger) {
+		l.onRotate = fn
+	}
 }
 
-func NewRotateLogger(filename string, rule RotateRule) *RotateLogger {
-	return &RotateLogger{
+func NewRotateLogger(filename string, rule RotateRule, opts ...RotateOption) *RotateLogger {
+	maxSize := rule.MaxSize()
+	if maxSize <= 0 {
+		// rotation is driven by the rule, keep lumberjack from rotating by its default size
+		maxSize = noSizeLimit
+	}
+
+	l := &RotateLogger{
 		Logger: &lumberjack.Logger{
 			Filename:   filename,
-			MaxSize:    rule.MaxSize(),
+			MaxSize:    maxSize,
 			MaxBackups: rule.MaxBackups(),
 			MaxAge:     rule.MaxAge(),
 			Compress:   rule.Gzip(),
 		},
 		rule: rule,
+		day:  today(time.Now()),
+	}
+	for _, opt := range opts {
+		opt(l)
+	}
+
+	if info, err := os.Stat(filename); err == nil {
+		l.size = info.Size()
+		l.day = today(info.ModTime())
 	}
+
+	return l
 }
 
 func (l *RotateLogger) Write(p []byte) (n int, err error) {
-	return l.Logger.Write(p)
+	rotated, err := l.write(p, &n)
+	if rotated {
+		l.afterRotate()
+	}
+
+	return n, err
 }
 
 func (l *RotateLogger) Close() error {
 	return l.Logger.Close()
 }
 
-func createRotateWriter(filename string, c LogConf) io.WriteCloser {
-	var rule RotateRule
+func (l *RotateLogger) write(p []byte, n *int) (rotated bool, err error) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	now := time.Now()
+	if l.size > 0 && l.shallRotate(int64(len(p)), now) {
+		if err = l.Logger.Rotate(); err != nil {
+			return false, err
+		}
+		l.size = 0
+		l.day = today(now)
+		rotated = true
+	}
+
+	*n, err = l.Logger.Write(p)
+	l.size += int64(*n)
+	return rotated, err
+}
 
-	if c.Rotation == "size" {
-		rule = NewSizeRotateRule(filename, c.MaxSize, c.MaxBackups, c.KeepDays, c.Compress)
+func (l *RotateLogger) shallRotate(writeLen int64, now time.Time) bool {
+	if l.rule.MaxSize() > 0 && l.size+writeLen > int64(l.rule.MaxSize())*megabyte {
+		return true
+	}
+
+	_, daily := l.rule.(*dailyRotateRule)
+	return daily && today(now).After(l.day)
+}
+
+func (l *RotateLogger) afterRotate() {
+	if l.maxTotalSize > 0 {
+		// can't log here, it would write back into this file
+		if err := l.removeOverTotalSize(); err != nil {
+			fmt.Fprintf(os.Stderr, "zapx: remove old log files: %v\n", err)
+		}
+	}
+	if l.onRotate != nil {
+		l.onRotate(l.Filename)
+	}
+}
+
+// removeOverTotalSize removes the oldest backups beyond maxTotalSize.
+func (l *RotateLogger) removeOverTotalSize() error {
+	dir := filepath.Dir(l.Filename)
+	base := filepath.Base(l.Filename)
+	ext := filepath.Ext(base)
+	prefix := strings.TrimSuffix(base, ext) + "-"
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+
+	// backups are named prefix-<timestamp>ext[.gz], sorted by name from the newest
+	var backups []os.DirEntry
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasPrefix(name, prefix) {
+			continue
+		}
+		if strings.HasSuffix(name, ext) || strings.HasSuffix(name, ext+compressSuffix) {
+			backups = append(backups, entry)
+		}
+	}
+	sort.Slice(backups, func(i, j int) bool {
+		return backups[i].Name() > backups[j].Name()
+	})
+
+	l.lock.Lock()
+	total := l.size
+	l.lock.Unlock()
+
+	var errs []error
+	for _, backup := range backups {
+		info, err := backup.Info()
+		if err != nil {
+			continue
+		}
+
+		total += info.Size()
+		if total > l.maxTotalSize {
+			if err := os.Remove(filepath.Join(dir, backup.Name())); err != nil && !os.IsNotExist(err) {
+				errs = append(errs, err)
+			}
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// createRotateWriter creates the rotating writer of the log category,
+// with its settings in c.Categories overriding the ones in c.
+func createRotateWriter(filename, category string, c LogConf) io.WriteCloser {
+	rc := c.rotateConf(category)
+
+	var rule RotateRule
+	if rc.Rotation == "size" {
+		rule = NewSizeRotateRule(filename, rc.MaxSize, rc.MaxBackups, rc.KeepDays, *rc.Compress)
 	} else {
-		rule = NewDailyRotateRule(filename, c.MaxBackups, c.KeepDays, c.Compress)
+		rule = NewDailyRotateRule(filename, rc.MaxBackups, rc.KeepDays, *rc.Compress)
+	}
+
+	opts := []RotateOption{WithMaxTotalSize(rc.MaxTotalSize)}
+	if c.OnRotate != nil {
+		opts = append(opts, WithOnRotate(func(filename string) {
+			c.OnRotate(category, filename)
+		}))
 	}
 
-	return NewRotateLogger(filename, rule)
+	return NewRotateLogger(filename, rule, opts...)
+}
+
+func today(t time.Time) time.Time {
+	year, month, day := t.Date()
+	return time.Date(year, month, day, 0, 0, 0, 0, t.Location())
 }
@@ -0,0 +1,73 @@
+package zapx_test
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/tedwangl/go-util/pkg/logger/zapx"
+)
+
+func TestRotateLoggerSize(t *testing.T) {
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "access.log")
+
+	var rotated []string
+	l := zapx.NewRotateLogger(filename, zapx.NewSizeRotateRule(filename, 1, 10, 0, false),
+		zapx.WithOnRotate(func(filename string) {
+			rotated = append(rotated, filename)
+		}))
+	defer l.Close()
+
+	chunk := bytes.Repeat([]byte("x"), 600*1024)
+	for i := 0; i < 2; i++ {
+		n, err := l.Write(chunk)
+		assert.NoError(t, err)
+		assert.Equal(t, len(chunk), n)
+	}
+
+	assert.Equal(t, []string{filename}, rotated)
+	assert.Len(t, logFiles(t, dir), 2)
+	info, err := os.Stat(filename)
+	assert.NoError(t, err)
+	assert.EqualValues(t, len(chunk), info.Size())
+}
+
+func TestRotateLoggerMaxTotalSize(t *testing.T) {
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "access.log")
+
+	l := zapx.NewRotateLogger(filename, zapx.NewSizeRotateRule(filename, 1, 10, 0, false),
+		zapx.WithMaxTotalSize(2))
+	defer l.Close()
+
+	chunk := bytes.Repeat([]byte("x"), 600*1024)
+	for i := 0; i < 5; i++ {
+		_, err := l.Write(chunk)
+		assert.NoError(t, err)
+	}
+
+	// the current file and the two newest backups fit in 2 megabytes
+	var total int64
+	for _, name := range logFiles(t, dir) {
+		info, err := os.Stat(filepath.Join(dir, name))
+		assert.NoError(t, err)
+		total += info.Size()
+	}
+	assert.LessOrEqual(t, total, int64(2*1024*1024))
+	assert.Len(t, logFiles(t, dir), 3)
+}
+
+func logFiles(t *testing.T, dir string) []string {
+	entries, err := os.ReadDir(dir)
+	assert.NoError(t, err)
+
+	var names []string
+	for _, entry := range entries {
+		names = append(names, entry.Name())
+	}
+	return names
+}
@@ -0,0 +1,232 @@
+package zapx
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"slices"
+
+	"go.uber.org/zap/zapcore"
+)
+
+type (
+	// RouteConf routes the entries with fields matching Match to their own files,
+	// Path/Name/<category>.log rotated like the category files, or to Writer if set.
+	// Match maps the field keys to the value patterns in path.Match syntax, all must match:
+	//
+	//	Routes:
+	//	  - Name: billing
+	//	    Match: {module: billing}
+	//	    Categories: [stat, slow]
+	//	  - Name: tenant-acme
+	//	    Match: {tenant: acme-*}
+	//
+	// The first matching route of an entry wins. Routes only apply to the file and volume modes.
+	RouteConf struct {
+		Name       string
+		Match      map[string]string
+		Categories []string  `json:",optional"` // empty to route all the categories
+		Copy       bool      `json:",optional"` // also write the entries to the category files
+		Writer     io.Writer `json:"-"`
+	}
+
+	route struct {
+		conf RouteConf
+		core zapcore.Core
+	}
+
+	// routeCore writes the entries to the core of the first matching route,
+	// or to the category core if none matches.
+	routeCore struct {
+		zapcore.Core
+		routes []route
+		keys   map[string]bool
+		fields []zapcore.Field
+	}
+
+	// router creates the route cores of the categories.
+	router struct {
+		conf    LogConf
+		writers []zapcore.WriteSyncer
+		closers *asyncClosers
+	}
+)
+
+func (c LogConf) checkRoutes() error {
+	names := make(map[string]bool, len(c.Routes))
+	for i, r := range c.Routes {
+		if len(r.Name) == 0 {
+			return fmt.Errorf("route %d: name not set", i)
+		}
+		if names[r.Name] {
+			return fmt.Errorf("route %s: duplicate name", r.Name)
+		}
+		names[r.Name] = true
+
+		if len(r.Match) == 0 {
+			return fmt.Errorf("route %s: match not set", r.Name)
+		}
+		for key, pattern := range r.Match {
+			if _, err := path.Match(pattern, ""); err != nil {
+				return fmt.Errorf("route %s: bad pattern of %s: %q", r.Name, key, pattern)
+			}
+		}
+		for _, category := range r.Categories {
+			if !slices.Contains(logCategories, category) {
+				return fmt.Errorf("route %s: unknown log category: %q", r.Name, category)
+			}
+		}
+	}
+
+	return nil
+}
+
+func newRouter(c LogConf, closers *asyncClosers) *router {
+	writers := make([]zapcore.WriteSyncer, len(c.Routes))
+	for i, r := range c.Routes {
+		// the writer is shared by the categories
+		if r.Writer != nil {
+			writers[i] = zapcore.Lock(zapcore.AddSync(r.Writer))
+		}
+	}
+
+	return &router{
+		conf:    c,
+		writers: writers,
+		closers: closers,
+	}
+}
+
+// wrap returns core routing the entries of the category by the routes, or core itself if no route applies.
+func (r *router) wrap(core zapcore.Core, encoderConfig zapcore.EncoderConfig, category, filename string) zapcore.Core {
+	var routes []route
+	keys := make(map[string]bool)
+	for i, rc := range r.conf.Routes {
+		if len(rc.Categories) > 0 && !slices.Contains(rc.Categories, category) {
+			continue
+		}
+
+		ws := r.writers[i]
+		if ws == nil {
+			w := createRotateWriter(path.Join(r.conf.Path, rc.Name, filename), category, r.conf)
+			ws = zapcore.AddSync(wrapAsync(w, rc.Name+"/"+category, r.conf, r.closers))
+		}
+		routes = append(routes, route{
+			conf: rc,
+			core: newCore(zapcore.NewJSONEncoder(encoderConfig), ws, r.conf),
+		})
+		for key := range rc.Match {
+			keys[key] = true
+		}
+	}
+	if len(routes) == 0 {
+		return core
+	}
+
+	return &routeCore{
+		Core:   core,
+		routes: routes,
+		keys:   keys,
+	}
+}
+
+func (c *routeCore) With(fields []zapcore.Field) zapcore.Core {
+	routes := make([]route, len(c.routes))
+	for i, r := range c.routes {
+		routes[i] = route{
+			conf: r.conf,
+			core: r.core.With(fields),
+		}
+	}
+
+	matched := slices.Clone(c.fields)
+	for _, f := range fields {
+		if c.keys[f.Key] {
+			matched = append(matched, f)
+		}
+	}
+
+	return &routeCore{
+		Core:   c.Core.With(fields),
+		routes: routes,
+		keys:   c.keys,
+		fields: matched,
+	}
+}
+
+func (c *routeCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+
+	return ce
+}
+
+// Write writes the entry through the Check of the target core, to keep its sampling.
+func (c *routeCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	values := c.values(fields)
+	for _, r := range c.routes {
+		if !r.match(values) {
+			continue
+		}
+
+		writeChecked(r.core, ent, fields)
+		if !r.conf.Copy {
+			return nil
+		}
+		break
+	}
+
+	writeChecked(c.Core, ent, fields)
+	return nil
+}
+
+func (c *routeCore) Sync() error {
+	errs := []error{c.Core.Sync()}
+	for _, r := range c.routes {
+		errs = append(errs, r.core.Sync())
+	}
+
+	return errors.Join(errs...)
+}
+
+// values returns the string values of the fields used by the routes, the later ones win.
+func (c *routeCore) values(fields []zapcore.Field) map[string]string {
+	values := make(map[string]string, len(c.keys))
+	for _, f := range slices.Concat(c.fields, fields) {
+		if !c.keys[f.Key] {
+			continue
+		}
+
+		if f.Type == zapcore.StringType {
+			values[f.Key] = f.String
+		} else {
+			enc := zapcore.NewMapObjectEncoder()
+			f.AddTo(enc)
+			values[f.Key] = fmt.Sprint(enc.Fields[f.Key])
+		}
+	}
+
+	return values
+}
+
+func (r route) match(values map[string]string) bool {
+	for key, pattern := range r.conf.Match {
+		value, ok := values[key]
+		if !ok {
+			return false
+		}
+		if matched, _ := path.Match(pattern, value); !matched {
+			return false
+		}
+	}
+
+	return true
+}
+
+func writeChecked(core zapcore.Core, ent zapcore.Entry, fields []zapcore.Field) {
+	if ce := core.Check(ent, nil); ce != nil {
+		ce.Write(fields...)
+	}
+}
@@ -0,0 +1,118 @@
+package zapx
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+const (
+	defaultSamplingTick = time.Second
+	discardedKey        = "discarded"
+)
+
+// samplingLevels maps the sampling config keys to the zap levels written by the writers,
+// slow logs are written at warn level, severe and alert logs at error level.
+var samplingLevels = map[string]zapcore.Level{
+	levelDebug: zapcore.DebugLevel,
+	levelInfo:  zapcore.InfoLevel,
+	levelSlow:  zapcore.WarnLevel,
+	levelError: zapcore.ErrorLevel,
+}
+
+var everyExecutors sync.Map
+
+type (
+	// SamplingRule logs the first Initial entries with the same level and message
+	// in each tick, then every Thereafter-th entry, like zap's sampler.
+	// Thereafter 0 drops all the entries after the first Initial ones.
+	SamplingRule struct {
+		Initial    int `json:",default=100"`
+		Thereafter int `json:",default=100"`
+	}
+
+	// exactLevel enables only one zap level, still respecting the runtime log level.
+	exactLevel zapcore.Level
+)
+
+func (l exactLevel) Enabled(lvl zapcore.Level) bool {
+	return lvl == zapcore.Level(l) && zapLevel.Enabled(lvl)
+}
+
+// Every returns a logger that logs at most once per interval for the given key,
+// other calls within the interval are discarded. The count of discarded calls is
+// logged as a field with the next logged entry.
+//
+//	zapx.Every("cache-miss", time.Minute).Infof("cache miss: %s", key)
+//
+// Keys are kept for the lifetime of the process, use a fixed set of keys.
+func Every(key string, interval time.Duration) Logger {
+	val, ok := everyExecutors.Load(key)
+	if !ok {
+		val, _ = everyExecutors.LoadOrStore(key, newIntervalExecutor(interval))
+	}
+
+	allowed, discarded := val.(*limitedExecutor).allow()
+	if !allowed {
+		return newLogger(nopWriter{})
+	}
+
+	logger := newLogger(getWriter())
+	if discarded > 0 {
+		logger = logger.WithFields(Field(discardedKey, discarded))
+	}
+	return logger
+}
+
+func checkSampling(sampling map[string]SamplingRule) error {
+	for name, rule := range sampling {
+		if _, ok := samplingLevels[name]; !ok {
+			return fmt.Errorf("unknown sampling level: %q, must be one of debug, info, slow, error", name)
+		}
+		if rule.Initial < 0 || rule.Thereafter < 0 {
+			return fmt.Errorf("sampling of %s must not be negative", name)
+		}
+	}
+
+	return nil
+}
+
+// newCore creates a core with the shared runtime level, sampled per level if configured.
+func newCore(enc zapcore.Encoder, ws zapcore.WriteSyncer, c LogConf) zapcore.Core {
+	return newSampledCore(c, func(enab zapcore.LevelEnabler) zapcore.Core {
+		return zapcore.NewCore(enc, ws, enab)
+	})
+}
+
+// newSampledCore creates the core with build, split into a tee of sampled cores
+// per level if sampling is configured.
+func newSampledCore(c LogConf, build func(zapcore.LevelEnabler) zapcore.Core) zapcore.Core {
+	if len(c.Sampling) == 0 {
+		return build(zapLevel)
+	}
+
+	tick := c.SamplingTick
+	if tick <= 0 {
+		tick = defaultSamplingTick
+	}
+
+	sampled := make(map[zapcore.Level]bool, len(c.Sampling))
+	cores := make([]zapcore.Core, 0, len(c.Sampling)+1)
+	for name, rule := range c.Sampling {
+		level, ok := samplingLevels[name]
+		if !ok {
+			continue
+		}
+
+		sampled[level] = true
+		cores = append(cores, zapcore.NewSamplerWithOptions(build(exactLevel(level)), tick, rule.Initial, rule.Thereafter))
+	}
+	cores = append(cores, build(zap.LevelEnablerFunc(func(lvl zapcore.Level) bool {
+		return !sampled[lvl] && zapLevel.Enabled(lvl)
+	})))
+
+	return zapcore.NewTee(cores...)
+}
@@ -0,0 +1,33 @@
+package zapx_test
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/tedwangl/go-util/pkg/logger/zapx"
+	"github.com/tedwangl/go-util/pkg/logger/zapx/zaptest"
+)
+
+func TestEvery(t *testing.T) {
+	w := zaptest.NewWriter(t)
+	const interval = 50 * time.Millisecond
+	// keys are kept for the process lifetime, use a new one for each run of -count
+	key := fmt.Sprintf("sampling-test-%d", time.Now().UnixNano())
+
+	for i := 0; i < 3; i++ {
+		zapx.Every(key, interval).Infof("call %d", i)
+	}
+	time.Sleep(interval * 2)
+	zapx.Every(key, interval).Info("after interval")
+
+	entries := w.Entries()
+	if assert.Len(t, entries, 2) {
+		assert.Equal(t, "call 0", entries[0].Content)
+		assert.NotContains(t, entries[0].Fields, "discarded")
+		assert.Equal(t, "after interval", entries[1].Content)
+		assert.EqualValues(t, 2, entries[1].Fields["discarded"])
+	}
+}
@@ -0,0 +1,114 @@
+package zapx
+
+import (
+	"bytes"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+type (
+	// sink receives encoded log entries, one entry per Write without the trailing newline.
+	// p is owned by the sink and may be retained.
+	sink interface {
+		Write(ent zapcore.Entry, p []byte) error
+		Sync() error
+		Close() error
+	}
+
+	// sinkCore is a zapcore.Core writing to a sink, keeping the entry level
+	// for the sinks that map it to labels or priorities.
+	sinkCore struct {
+		zapcore.LevelEnabler
+		enc  zapcore.Encoder
+		sink sink
+	}
+)
+
+func (c *sinkCore) With(fields []zapcore.Field) zapcore.Core {
+	enc := c.enc.Clone()
+	for _, f := range fields {
+		f.AddTo(enc)
+	}
+
+	return &sinkCore{
+		LevelEnabler: c.LevelEnabler,
+		enc:          enc,
+		sink:         c.sink,
+	}
+}
+
+func (c *sinkCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+	return ce
+}
+
+func (c *sinkCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	buf, err := c.enc.EncodeEntry(ent, fields)
+	if err != nil {
+		return err
+	}
+	defer buf.Free()
+
+	line := bytes.TrimSuffix(buf.Bytes(), []byte(zapcore.DefaultLineEnding))
+	return c.sink.Write(ent, append([]byte(nil), line...))
+}
+
+func (c *sinkCore) Sync() error {
+	return c.sink.Sync()
+}
+
+// newSinkWriter creates a Writer encoding all the logs as JSON into s.
+func newSinkWriter(c LogConf, s sink) Writer {
+	encoderConfig := zapcore.EncoderConfig{
+		TimeKey:        timestampKey,
+		LevelKey:       levelKey,
+		NameKey:        "logger",
+		CallerKey:      callerKey,
+		MessageKey:     contentKey,
+		StacktraceKey:  "stacktrace",
+		LineEnding:     zapcore.DefaultLineEnding,
+		EncodeLevel:    zapcore.LowercaseLevelEncoder,
+		EncodeTime:     zapcore.ISO8601TimeEncoder,
+		EncodeDuration: zapcore.StringDurationEncoder,
+		EncodeCaller:   zapcore.ShortCallerEncoder,
+	}
+	enc := zapcore.NewJSONEncoder(encoderConfig)
+
+	core := newSampledCore(c, func(enab zapcore.LevelEnabler) zapcore.Core {
+		return &sinkCore{
+			LevelEnabler: enab,
+			enc:          enc,
+			sink:         s,
+		}
+	})
+	zapLogger := zap.New(core, zap.AddCaller(), zap.AddCallerSkip(c.CallerSkip))
+	stackLogger := zapLogger.WithOptions(zap.AddStacktrace(zapcore.ErrorLevel))
+
+	var stackLimiter *limitedExecutor
+	if c.StackCooldownMillis > 0 {
+		stackLimiter = NewLimitedExecutor(c.StackCooldownMillis)
+	}
+
+	return &zapWriter{
+		infoLogger:   zapLogger,
+		errorLogger:  zapLogger,
+		severeLogger: zapLogger,
+		slowLogger:   zapLogger,
+		statLogger:   zapLogger,
+		stackLogger:  stackLogger,
+		alertLogger:  zapLogger,
+		sugarInfo:    zapLogger.Sugar(),
+		sugarError:   zapLogger.Sugar(),
+		sugarSevere:  zapLogger.Sugar(),
+		sugarSlow:    zapLogger.Sugar(),
+		sugarStat:    zapLogger.Sugar(),
+		sugarStack:   stackLogger.Sugar(),
+		sugarAlert:   zapLogger.Sugar(),
+		config:       c,
+		stackLimiter: stackLimiter,
+		closer:       s,
+	}
+}
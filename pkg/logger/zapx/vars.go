@@ -26,6 +26,7 @@ const (
 	levelInfo   = "info"
 	levelError  = "error"
 	levelSevere = "severe"
+	levelWarn   = "warn"
 	levelFatal  = "fatal"
 	levelSlow   = "slow"
 	levelStat   = "stat"
@@ -37,6 +38,7 @@ const (
 	defaultContentKey   = "content"
 	defaultDurationKey  = "duration"
 	defaultLevelKey     = "level"
+	defaultRequestKey   = "request_id"
 	defaultSpanKey      = "span"
 	defaultTimestampKey = "@timestamp"
 	defaultTraceKey     = "trace"
@@ -53,6 +55,7 @@ var (
 	contentKey   = defaultContentKey
 	durationKey  = defaultDurationKey
 	levelKey     = defaultLevelKey
+	requestKey   = defaultRequestKey
 	spanKey      = defaultSpanKey
 	timestampKey = defaultTimestampKey
 	traceKey     = defaultTraceKey
@@ -65,22 +68,11 @@ var (
 )
 
 func setLogLevel(level string) {
-	switch level {
-	case "debug":
-		atomic.StoreUint32(&logLevel, DebugLevel)
-	case "info":
-		atomic.StoreUint32(&logLevel, InfoLevel)
-	case "error":
-		atomic.StoreUint32(&logLevel, ErrorLevel)
-	case "severe":
-		atomic.StoreUint32(&logLevel, SevereLevel)
+	if lv, err := ParseLevel(level); err == nil {
+		SetLevel(lv)
 	}
 }
 
 func shallLog(level uint32) bool {
 	return atomic.LoadUint32(&logLevel) <= level
 }
-
-func SetLevel(level uint32) {
-	atomic.StoreUint32(&logLevel, level)
-}
@@ -2,6 +2,7 @@ package zapx
 
 import (
 	"fmt"
+	"io"
 	"os"
 	"path"
 	"sync"
@@ -42,6 +43,7 @@ type (
 		sugarAlert   *zap.SugaredLogger
 		config       LogConf
 		stackLimiter *limitedExecutor
+		closer       io.Closer
 	}
 
 	atomicWriter struct {
@@ -158,6 +160,8 @@ func getWriter() Writer {
 	return w
 }
 
+// SetWriter sets the global writer.
+// To capture the logs in tests, use zaptest.NewWriter from the zapx/zaptest package.
 func SetWriter(w Writer) {
 	if atomic.LoadUint32(&logLevel) != disableLevel {
 		awriter.Store(w)
@@ -190,11 +194,7 @@ func newConsoleWriter(c LogConf) Writer {
 		EncodeCaller:   zapcore.ShortCallerEncoder,
 	}
 
-	core := zapcore.NewCore(
-		zapcore.NewConsoleEncoder(encoderConfig),
-		zapcore.AddSync(os.Stdout),
-		zapcore.DebugLevel,
-	)
+	core := newCore(zapcore.NewConsoleEncoder(encoderConfig), zapcore.AddSync(os.Stdout), c)
 
 	zapLogger := zap.New(core, zap.AddCaller(), zap.AddCallerSkip(2))
 
@@ -250,47 +250,37 @@ func newFileWriter(c LogConf) (Writer, error) {
 		encoderConfig.EncodeLevel = zapcore.CapitalLevelEncoder
 	}
 
+	if err := c.checkCategories(); err != nil {
+		return nil, err
+	}
+	if err := c.checkRoutes(); err != nil {
+		return nil, err
+	}
+
 	accessFile := path.Join(c.Path, accessFilename)
 	errorFile := path.Join(c.Path, errorFilename)
 	severeFile := path.Join(c.Path, severeFilename)
 	slowFile := path.Join(c.Path, slowFilename)
 	statFile := path.Join(c.Path, statFilename)
 
-	accessWriter := createRotateWriter(accessFile, c)
-	errorWriter := createRotateWriter(errorFile, c)
-	severeWriter := createRotateWriter(severeFile, c)
-	slowWriter := createRotateWriter(slowFile, c)
-	statWriter := createRotateWriter(statFile, c)
-
-	infoCore := zapcore.NewCore(
-		zapcore.NewJSONEncoder(encoderConfig),
-		zapcore.AddSync(accessWriter),
-		zapcore.DebugLevel,
-	)
-
-	errorCore := zapcore.NewCore(
-		zapcore.NewJSONEncoder(encoderConfig),
-		zapcore.AddSync(errorWriter),
-		zapcore.DebugLevel,
-	)
-
-	severeCore := zapcore.NewCore(
-		zapcore.NewJSONEncoder(encoderConfig),
-		zapcore.AddSync(severeWriter),
-		zapcore.DebugLevel,
-	)
-
-	slowCore := zapcore.NewCore(
-		zapcore.NewJSONEncoder(encoderConfig),
-		zapcore.AddSync(slowWriter),
-		zapcore.DebugLevel,
-	)
-
-	statCore := zapcore.NewCore(
-		zapcore.NewJSONEncoder(encoderConfig),
-		zapcore.AddSync(statWriter),
-		zapcore.DebugLevel,
-	)
+	var asyncs asyncClosers
+	accessWriter := wrapAsync(createRotateWriter(accessFile, "access", c), "access", c, &asyncs)
+	errorWriter := wrapAsync(createRotateWriter(errorFile, "error", c), "error", c, &asyncs)
+	severeWriter := wrapAsync(createRotateWriter(severeFile, "severe", c), "severe", c, &asyncs)
+	slowWriter := wrapAsync(createRotateWriter(slowFile, "slow", c), "slow", c, &asyncs)
+	statWriter := wrapAsync(createRotateWriter(statFile, "stat", c), "stat", c, &asyncs)
+
+	routes := newRouter(c, &asyncs)
+
+	infoCore := routes.wrap(newCore(zapcore.NewJSONEncoder(encoderConfig), zapcore.AddSync(accessWriter), c), encoderConfig, "access", accessFilename)
+
+	errorCore := routes.wrap(newCore(zapcore.NewJSONEncoder(encoderConfig), zapcore.AddSync(errorWriter), c), encoderConfig, "error", errorFilename)
+
+	severeCore := routes.wrap(newCore(zapcore.NewJSONEncoder(encoderConfig), zapcore.AddSync(severeWriter), c), encoderConfig, "severe", severeFilename)
+
+	slowCore := routes.wrap(newCore(zapcore.NewJSONEncoder(encoderConfig), zapcore.AddSync(slowWriter), c), encoderConfig, "slow", slowFilename)
+
+	statCore := routes.wrap(newCore(zapcore.NewJSONEncoder(encoderConfig), zapcore.AddSync(statWriter), c), encoderConfig, "stat", statFilename)
 
 	infoLogger := zap.New(infoCore, zap.AddCaller(), zap.AddCallerSkip(c.CallerSkip))
 	errorLogger := zap.New(errorCore, zap.AddCaller(), zap.AddCallerSkip(c.CallerSkip))
@@ -307,6 +297,11 @@ func newFileWriter(c LogConf) (Writer, error) {
 
 	alertLogger := errorLogger
 
+	var closer io.Closer
+	if len(asyncs) > 0 {
+		closer = asyncs
+	}
+
 	return &zapWriter{
 		infoLogger:   infoLogger,
 		errorLogger:  errorLogger,
@@ -324,6 +319,7 @@ func newFileWriter(c LogConf) (Writer, error) {
 		sugarAlert:   alertLogger.Sugar(),
 		config:       c,
 		stackLimiter: stackLimiter,
+		closer:       closer,
 	}, nil
 }
 
@@ -344,6 +340,11 @@ func (w *zapWriter) Close() error {
 	if err := w.statLogger.Sync(); err != nil {
 		errs = append(errs, err)
 	}
+	if w.closer != nil {
+		if err := w.closer.Close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
 	if len(errs) > 0 {
 		return fmt.Errorf("close errors: %v", errs)
 	}
@@ -519,6 +520,6 @@ func (n nopWriter) Stat(_ int, _ any, _ ...LogField) {}
 func (n nopWriter) Alert(_ any) {}
 
 func Disable() {
-	atomic.StoreUint32(&logLevel, disableLevel)
+	SetLevel(disableLevel)
 	awriter.Store(nopWriter{})
 }
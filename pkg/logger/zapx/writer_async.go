@@ -0,0 +1,323 @@
+package zapx
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	// AsyncPolicyBlock blocks the callers while the buffer is full.
+	AsyncPolicyBlock = "block"
+	// AsyncPolicyDropOldest drops the oldest buffered entry to make room for the new one.
+	AsyncPolicyDropOldest = "drop_oldest"
+
+	defaultAsyncBatchSize     = 100
+	defaultAsyncFlushInterval = time.Second
+)
+
+var (
+	asyncWritten = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "zapx",
+		Name:      "async_written_total",
+		Help:      "Log entries written by the async writers.",
+	}, []string{"writer"})
+	asyncDropped = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "zapx",
+		Name:      "async_dropped_total",
+		Help:      "Log entries dropped by the async writers because the buffer was full.",
+	}, []string{"writer"})
+	asyncErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "zapx",
+		Name:      "async_flush_errors_total",
+		Help:      "Failed flushes of the async writers.",
+	}, []string{"writer"})
+	asyncBuffered = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "zapx",
+		Name:      "async_buffered",
+		Help:      "Log entries buffered by the async writers.",
+	}, []string{"writer"})
+)
+
+type (
+	// AsyncConf enables buffered asynchronous writing of the log files, BufferSize 0 disables it.
+	// Entries are written in batches of BatchSize or every FlushInterval, whichever comes first.
+	AsyncConf struct {
+		BufferSize    int           `json:",optional"`
+		BatchSize     int           `json:",default=100"`
+		FlushInterval time.Duration `json:",default=1s"`
+		Policy        string        `json:",default=block,options=[block,drop_oldest]"`
+	}
+
+	// AsyncStats is the counters of an AsyncWriter.
+	AsyncStats struct {
+		Buffered int
+		Written  uint64
+		Dropped  uint64
+		Errors   uint64
+	}
+
+	// AsyncWriter buffers the writes in a bounded ring buffer and writes them
+	// to the underlying writer in batches from a background goroutine.
+	// Each Write is kept as one entry, so a write is never split or partially dropped.
+	AsyncWriter struct {
+		name string
+		w    io.Writer
+		conf AsyncConf
+
+		lock    sync.Mutex
+		notFull *sync.Cond
+		ring    [][]byte
+		head    int
+		count   int
+		closed  bool
+
+		flushLock sync.Mutex
+		flushCh   chan struct{}
+		done      chan struct{}
+		wg        sync.WaitGroup
+		once      sync.Once
+
+		written atomic.Uint64
+		dropped atomic.Uint64
+		errors  atomic.Uint64
+	}
+)
+
+// RegisterAsyncMetrics registers the async writer metrics, labeled by the writer name,
+// to registerer, or prometheus.DefaultRegisterer if nil.
+func RegisterAsyncMetrics(registerer prometheus.Registerer) error {
+	if registerer == nil {
+		registerer = prometheus.DefaultRegisterer
+	}
+
+	for _, c := range []prometheus.Collector{asyncWritten, asyncDropped, asyncErrors, asyncBuffered} {
+		if err := registerer.Register(c); err != nil {
+			var are prometheus.AlreadyRegisteredError
+			if !errors.As(err, &are) {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// NewAsyncWriter returns an AsyncWriter writing to w, name is used as the metrics label.
+func NewAsyncWriter(name string, w io.Writer, c AsyncConf) *AsyncWriter {
+	if c.BufferSize <= 0 {
+		c.BufferSize = defaultAsyncBatchSize * 10
+	}
+	if c.BatchSize <= 0 {
+		c.BatchSize = defaultAsyncBatchSize
+	}
+	if c.BatchSize > c.BufferSize {
+		c.BatchSize = c.BufferSize
+	}
+	if c.FlushInterval <= 0 {
+		c.FlushInterval = defaultAsyncFlushInterval
+	}
+	if c.Policy != AsyncPolicyDropOldest {
+		c.Policy = AsyncPolicyBlock
+	}
+
+	aw := &AsyncWriter{
+		name:    name,
+		w:       w,
+		conf:    c,
+		ring:    make([][]byte, c.BufferSize),
+		flushCh: make(chan struct{}, 1),
+		done:    make(chan struct{}),
+	}
+	aw.notFull = sync.NewCond(&aw.lock)
+	aw.wg.Add(1)
+	go aw.loop()
+
+	return aw
+}
+
+// Write buffers a copy of p, it writes p directly after the writer is closed.
+func (w *AsyncWriter) Write(p []byte) (int, error) {
+	entry := append([]byte(nil), p...)
+
+	w.lock.Lock()
+	for w.count == len(w.ring) && w.conf.Policy == AsyncPolicyBlock && !w.closed {
+		w.signalFlush()
+		w.notFull.Wait()
+	}
+	if w.closed {
+		w.lock.Unlock()
+		return w.w.Write(p)
+	}
+
+	if w.count == len(w.ring) {
+		w.ring[w.head] = nil
+		w.head = (w.head + 1) % len(w.ring)
+		w.count--
+		w.dropped.Add(1)
+		asyncDropped.WithLabelValues(w.name).Inc()
+	}
+	w.ring[(w.head+w.count)%len(w.ring)] = entry
+	w.count++
+	full := w.count >= w.conf.BatchSize
+	asyncBuffered.WithLabelValues(w.name).Set(float64(w.count))
+	w.lock.Unlock()
+
+	if full {
+		w.signalFlush()
+	}
+
+	return len(p), nil
+}
+
+// Sync writes the buffered entries and syncs the underlying writer if it supports.
+func (w *AsyncWriter) Sync() error {
+	if err := w.flush(); err != nil {
+		return err
+	}
+
+	if s, ok := w.w.(interface{ Sync() error }); ok {
+		return s.Sync()
+	}
+
+	return nil
+}
+
+// Close writes the buffered entries, stops the background goroutine
+// and closes the underlying writer if it's an io.Closer.
+func (w *AsyncWriter) Close() error {
+	var err error
+	w.once.Do(func() {
+		// mark closed first, the writes after it go to w.w directly,
+		// so nothing is buffered after the drain below
+		w.lock.Lock()
+		w.closed = true
+		w.notFull.Broadcast()
+		w.lock.Unlock()
+		err = w.flush()
+
+		close(w.done)
+		w.wg.Wait()
+
+		if c, ok := w.w.(io.Closer); ok {
+			if cerr := c.Close(); cerr != nil && err == nil {
+				err = cerr
+			}
+		}
+	})
+
+	return err
+}
+
+// Stats returns the counters of the writer.
+func (w *AsyncWriter) Stats() AsyncStats {
+	w.lock.Lock()
+	buffered := w.count
+	w.lock.Unlock()
+
+	return AsyncStats{
+		Buffered: buffered,
+		Written:  w.written.Load(),
+		Dropped:  w.dropped.Load(),
+		Errors:   w.errors.Load(),
+	}
+}
+
+func (w *AsyncWriter) signalFlush() {
+	select {
+	case w.flushCh <- struct{}{}:
+	default:
+	}
+}
+
+func (w *AsyncWriter) loop() {
+	defer w.wg.Done()
+
+	ticker := time.NewTicker(w.conf.FlushInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-w.done:
+			return
+		case <-ticker.C:
+		case <-w.flushCh:
+		}
+
+		// can't log with zapx here, it would write back into this writer
+		if err := w.flush(); err != nil {
+			fmt.Fprintf(os.Stderr, "zapx: async write %s: %v\n", w.name, err)
+		}
+	}
+}
+
+// flush writes the buffered entries in batches of BatchSize, the entries of a failed batch are dropped.
+func (w *AsyncWriter) flush() error {
+	w.flushLock.Lock()
+	defer w.flushLock.Unlock()
+
+	var (
+		buf bytes.Buffer
+		err error
+	)
+	for {
+		w.lock.Lock()
+		n := min(w.count, w.conf.BatchSize)
+		if n == 0 {
+			w.lock.Unlock()
+			return err
+		}
+		buf.Reset()
+		for i := 0; i < n; i++ {
+			idx := (w.head + i) % len(w.ring)
+			buf.Write(w.ring[idx])
+			w.ring[idx] = nil
+		}
+		w.head = (w.head + n) % len(w.ring)
+		w.count -= n
+		asyncBuffered.WithLabelValues(w.name).Set(float64(w.count))
+		w.notFull.Broadcast()
+		w.lock.Unlock()
+
+		if _, werr := w.w.Write(buf.Bytes()); werr != nil {
+			w.errors.Add(1)
+			asyncErrors.WithLabelValues(w.name).Inc()
+			err = werr
+			continue
+		}
+		w.written.Add(uint64(n))
+		asyncWritten.WithLabelValues(w.name).Add(float64(n))
+	}
+}
+
+// asyncClosers closes the async writers of the file writer.
+type asyncClosers []*AsyncWriter
+
+func (a asyncClosers) Close() error {
+	var errs []error
+	for _, w := range a {
+		if err := w.Close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// wrapAsync wraps w with an AsyncWriter if enabled in c, and keeps it to be closed with the file writer.
+func wrapAsync(w io.WriteCloser, category string, c LogConf, closers *asyncClosers) io.WriteCloser {
+	if c.Async.BufferSize <= 0 {
+		return w
+	}
+
+	aw := NewAsyncWriter(category, w, c.Async)
+	*closers = append(*closers, aw)
+	return aw
+}
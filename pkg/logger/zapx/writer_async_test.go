@@ -0,0 +1,78 @@
+package zapx
+
+import (
+	"bytes"
+	"sync"
+	"testing"
+	"time"
+)
+
+// lockedBuffer is a bytes.Buffer safe for concurrent writes.
+type lockedBuffer struct {
+	lock sync.Mutex
+	buf  bytes.Buffer
+}
+
+func (b *lockedBuffer) Write(p []byte) (int, error) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *lockedBuffer) String() string {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	return b.buf.String()
+}
+
+func TestAsyncWriterCloseDrains(t *testing.T) {
+	var buf lockedBuffer
+	w := NewAsyncWriter("test", &buf, AsyncConf{BufferSize: 10, FlushInterval: time.Hour})
+
+	w.Write([]byte("a\n"))
+	w.Write([]byte("b\n"))
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if got := buf.String(); got != "a\nb\n" {
+		t.Errorf("expected buffered entries written on close, got %q", got)
+	}
+
+	// the writes after closed go to the underlying writer directly
+	w.Write([]byte("c\n"))
+	if got := buf.String(); got != "a\nb\nc\n" || w.Stats().Buffered != 0 {
+		t.Errorf("expected write after close not buffered, got %q, %+v", got, w.Stats())
+	}
+}
+
+func TestAsyncWriterCloseUnblocksWriters(t *testing.T) {
+	var buf lockedBuffer
+	w := NewAsyncWriter("test", &buf, AsyncConf{BufferSize: 1, FlushInterval: time.Hour})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			w.Write([]byte("x"))
+		}()
+	}
+
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("blocked writers should return after close")
+	}
+	if got := buf.String(); got != "xxxxxxxxxx" {
+		t.Errorf("expected all writes kept, got %q", got)
+	}
+}
@@ -0,0 +1,100 @@
+package zapx
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"sync/atomic"
+
+	"github.com/IBM/sarama"
+	"go.uber.org/zap/zapcore"
+)
+
+var ErrKafkaNotConfigured = errors.New("kafka brokers and topic must be set")
+
+type (
+	KafkaConf struct {
+		Brokers      []string `json:",optional"`
+		Topic        string   `json:",optional"`
+		Key          string   `json:",optional"`  // partition key, all logs go to one partition if set
+		RequiredAcks int      `json:",default=1"` // 0 no response, 1 leader, -1 all replicas
+	}
+
+	// kafkaSink sends each entry as a message with an async producer.
+	// Entries are dropped instead of blocking the caller when the producer is full.
+	kafkaSink struct {
+		topic    string
+		key      sarama.Encoder
+		producer sarama.AsyncProducer
+		dropped  atomic.Uint64
+	}
+)
+
+// NewKafkaWriter creates a Writer sending the logs as JSON messages to a Kafka topic.
+func NewKafkaWriter(c KafkaConf) (Writer, error) {
+	return newKafkaWriter(LogConf{Kafka: c})
+}
+
+func newKafkaWriter(c LogConf) (Writer, error) {
+	s, err := newKafkaSink(c.Kafka)
+	if err != nil {
+		return nil, err
+	}
+
+	return newSinkWriter(c, s), nil
+}
+
+func newKafkaSink(c KafkaConf) (*kafkaSink, error) {
+	if len(c.Brokers) == 0 || len(c.Topic) == 0 {
+		return nil, ErrKafkaNotConfigured
+	}
+
+	conf := sarama.NewConfig()
+	conf.Producer.RequiredAcks = sarama.RequiredAcks(c.RequiredAcks)
+	conf.Producer.Return.Successes = false
+	conf.Producer.Return.Errors = false
+
+	producer, err := sarama.NewAsyncProducer(c.Brokers, conf)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &kafkaSink{
+		topic:    c.Topic,
+		producer: producer,
+	}
+	if len(c.Key) > 0 {
+		s.key = sarama.StringEncoder(c.Key)
+	}
+
+	return s, nil
+}
+
+func (s *kafkaSink) Write(ent zapcore.Entry, p []byte) error {
+	msg := &sarama.ProducerMessage{
+		Topic:     s.topic,
+		Key:       s.key,
+		Value:     sarama.ByteEncoder(p),
+		Timestamp: ent.Time,
+	}
+
+	select {
+	case s.producer.Input() <- msg:
+	default:
+		s.dropped.Add(1)
+	}
+
+	return nil
+}
+
+func (s *kafkaSink) Sync() error {
+	return nil
+}
+
+// Close flushes the buffered messages and closes the producer.
+func (s *kafkaSink) Close() error {
+	if n := s.dropped.Load(); n > 0 {
+		fmt.Fprintf(os.Stderr, "zapx: dropped %d log messages to kafka\n", n)
+	}
+	return s.producer.Close()
+}
@@ -0,0 +1,216 @@
+package zapx
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"net/http"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"go.uber.org/zap/zapcore"
+)
+
+const (
+	defaultLokiBatchSize = 1000
+	defaultLokiBatchWait = time.Second
+	defaultLokiTimeout   = 5 * time.Second
+	lokiLevelLabel       = "level"
+)
+
+var ErrLokiURLNotSet = errors.New("loki url must be set")
+
+type (
+	LokiConf struct {
+		URL       string            `json:",optional"` // push api, e.g. http://localhost:3100/loki/api/v1/push
+		TenantID  string            `json:",optional"`
+		Labels    map[string]string `json:",optional"`
+		BatchSize int               `json:",default=1000"`
+		BatchWait time.Duration     `json:",default=1s"`
+		Timeout   time.Duration     `json:",default=5s"`
+	}
+
+	// lokiSink batches the entries by level and pushes them to Loki,
+	// when BatchSize entries are buffered or every BatchWait.
+	lokiSink struct {
+		conf    LokiConf
+		labels  map[string]string
+		client  *http.Client
+		lock    sync.Mutex
+		streams map[zapcore.Level][][2]string
+		size    int
+		flushCh chan struct{}
+		done    chan struct{}
+		wg      sync.WaitGroup
+		once    sync.Once
+	}
+
+	lokiStream struct {
+		Stream map[string]string `json:"stream"`
+		Values [][2]string       `json:"values"`
+	}
+
+	lokiPush struct {
+		Streams []lokiStream `json:"streams"`
+	}
+)
+
+// NewLokiWriter creates a Writer pushing the logs to Loki in batches,
+// labeled with c.Labels and the log level.
+func NewLokiWriter(c LokiConf) (Writer, error) {
+	return newLokiWriter(LogConf{Loki: c})
+}
+
+func newLokiWriter(c LogConf) (Writer, error) {
+	s, err := newLokiSink(c.Loki, c.ServiceName)
+	if err != nil {
+		return nil, err
+	}
+
+	return newSinkWriter(c, s), nil
+}
+
+func newLokiSink(c LokiConf, serviceName string) (*lokiSink, error) {
+	if len(c.URL) == 0 {
+		return nil, ErrLokiURLNotSet
+	}
+	if c.BatchSize <= 0 {
+		c.BatchSize = defaultLokiBatchSize
+	}
+	if c.BatchWait <= 0 {
+		c.BatchWait = defaultLokiBatchWait
+	}
+	if c.Timeout <= 0 {
+		c.Timeout = defaultLokiTimeout
+	}
+
+	labels := make(map[string]string, len(c.Labels)+1)
+	if len(serviceName) > 0 {
+		labels["service"] = serviceName
+	}
+	maps.Copy(labels, c.Labels)
+
+	s := &lokiSink{
+		conf:    c,
+		labels:  labels,
+		client:  &http.Client{Timeout: c.Timeout},
+		streams: make(map[zapcore.Level][][2]string),
+		flushCh: make(chan struct{}, 1),
+		done:    make(chan struct{}),
+	}
+	s.wg.Add(1)
+	go s.loop()
+
+	return s, nil
+}
+
+func (s *lokiSink) Write(ent zapcore.Entry, p []byte) error {
+	ts := strconv.FormatInt(ent.Time.UnixNano(), 10)
+
+	s.lock.Lock()
+	s.streams[ent.Level] = append(s.streams[ent.Level], [2]string{ts, string(p)})
+	s.size++
+	full := s.size >= s.conf.BatchSize
+	s.lock.Unlock()
+
+	if full {
+		select {
+		case s.flushCh <- struct{}{}:
+		default:
+		}
+	}
+
+	return nil
+}
+
+func (s *lokiSink) Sync() error {
+	return s.flush()
+}
+
+func (s *lokiSink) Close() error {
+	s.once.Do(func() {
+		close(s.done)
+	})
+	s.wg.Wait()
+	return s.flush()
+}
+
+func (s *lokiSink) loop() {
+	defer s.wg.Done()
+
+	ticker := time.NewTicker(s.conf.BatchWait)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.done:
+			return
+		case <-ticker.C:
+		case <-s.flushCh:
+		}
+
+		// can't log with zapx here, it would write back into this sink
+		if err := s.flush(); err != nil {
+			fmt.Fprintf(os.Stderr, "zapx: push logs to loki: %v\n", err)
+		}
+	}
+}
+
+// flush pushes the buffered entries, which are dropped if the push fails.
+func (s *lokiSink) flush() error {
+	s.lock.Lock()
+	if s.size == 0 {
+		s.lock.Unlock()
+		return nil
+	}
+	streams := s.streams
+	s.streams = make(map[zapcore.Level][][2]string, len(streams))
+	s.size = 0
+	s.lock.Unlock()
+
+	var push lokiPush
+	for level, values := range streams {
+		labels := maps.Clone(s.labels)
+		labels[lokiLevelLabel] = level.String()
+		push.Streams = append(push.Streams, lokiStream{
+			Stream: labels,
+			Values: values,
+		})
+	}
+
+	body, err := json.Marshal(push)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), s.conf.Timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.conf.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if len(s.conf.TenantID) > 0 {
+		req.Header.Set("X-Scope-OrgID", s.conf.TenantID)
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode/100 != 2 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("loki responded %s: %s", resp.Status, bytes.TrimSpace(msg))
+	}
+
+	return nil
+}
@@ -0,0 +1,73 @@
+package zapx_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/tedwangl/go-util/pkg/logger/zapx"
+)
+
+type lokiPush struct {
+	Streams []struct {
+		Stream map[string]string `json:"stream"`
+		Values [][2]string       `json:"values"`
+	} `json:"streams"`
+}
+
+func TestLokiWriter(t *testing.T) {
+	var (
+		lock   sync.Mutex
+		pushes []lokiPush
+		tenant string
+	)
+	svr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var push lokiPush
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&push))
+
+		lock.Lock()
+		defer lock.Unlock()
+		pushes = append(pushes, push)
+		tenant = r.Header.Get("X-Scope-OrgID")
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer svr.Close()
+
+	w, err := zapx.NewLokiWriter(zapx.LokiConf{
+		URL:       svr.URL,
+		TenantID:  "tenant-1",
+		Labels:    map[string]string{"app": "test"},
+		BatchWait: time.Hour,
+	})
+	assert.NoError(t, err)
+
+	w.Info(1, "hello")
+	w.Error(1, "failed")
+	assert.NoError(t, w.Close())
+
+	lock.Lock()
+	defer lock.Unlock()
+	assert.Equal(t, "tenant-1", tenant)
+	if assert.Len(t, pushes, 1) {
+		lines := make(map[string]string)
+		for _, stream := range pushes[0].Streams {
+			assert.Equal(t, "test", stream.Stream["app"])
+			for _, value := range stream.Values {
+				var entry map[string]any
+				assert.NoError(t, json.Unmarshal([]byte(value[1]), &entry))
+				lines[stream.Stream["level"]] = entry["content"].(string)
+			}
+		}
+		assert.Equal(t, map[string]string{"info": "hello", "error": "failed"}, lines)
+	}
+}
+
+func TestLokiWriterURLNotSet(t *testing.T) {
+	_, err := zapx.NewLokiWriter(zapx.LokiConf{})
+	assert.ErrorIs(t, err, zapx.ErrLokiURLNotSet)
+}
@@ -0,0 +1,16 @@
+//go:build windows
+
+package zapx
+
+import "errors"
+
+var errSyslogNotSupported = errors.New("syslog is not supported on windows")
+
+// NewSyslogWriter is not supported on windows.
+func NewSyslogWriter(_ SyslogConf) (Writer, error) {
+	return nil, errSyslogNotSupported
+}
+
+func newSyslogWriter(_ LogConf) (Writer, error) {
+	return nil, errSyslogNotSupported
+}
@@ -0,0 +1,89 @@
+//go:build !windows
+
+package zapx
+
+import (
+	"fmt"
+	"log/syslog"
+
+	"go.uber.org/zap/zapcore"
+)
+
+var syslogFacilities = map[string]syslog.Priority{
+	"kern":     syslog.LOG_KERN,
+	"user":     syslog.LOG_USER,
+	"mail":     syslog.LOG_MAIL,
+	"daemon":   syslog.LOG_DAEMON,
+	"auth":     syslog.LOG_AUTH,
+	"syslog":   syslog.LOG_SYSLOG,
+	"lpr":      syslog.LOG_LPR,
+	"news":     syslog.LOG_NEWS,
+	"uucp":     syslog.LOG_UUCP,
+	"cron":     syslog.LOG_CRON,
+	"authpriv": syslog.LOG_AUTHPRIV,
+	"ftp":      syslog.LOG_FTP,
+	"local0":   syslog.LOG_LOCAL0,
+	"local1":   syslog.LOG_LOCAL1,
+	"local2":   syslog.LOG_LOCAL2,
+	"local3":   syslog.LOG_LOCAL3,
+	"local4":   syslog.LOG_LOCAL4,
+	"local5":   syslog.LOG_LOCAL5,
+	"local6":   syslog.LOG_LOCAL6,
+	"local7":   syslog.LOG_LOCAL7,
+}
+
+// syslogSink writes each entry with the syslog severity of its level.
+type syslogSink struct {
+	writer *syslog.Writer
+}
+
+// NewSyslogWriter creates a Writer sending the logs as JSON to syslog.
+func NewSyslogWriter(c SyslogConf) (Writer, error) {
+	return newSyslogWriter(LogConf{Syslog: c})
+}
+
+func newSyslogWriter(c LogConf) (Writer, error) {
+	facility, ok := syslogFacilities[c.Syslog.Facility]
+	if !ok {
+		if len(c.Syslog.Facility) > 0 {
+			return nil, fmt.Errorf("unknown syslog facility: %q", c.Syslog.Facility)
+		}
+		facility = syslog.LOG_LOCAL0
+	}
+
+	tag := c.Syslog.Tag
+	if len(tag) == 0 {
+		tag = c.ServiceName
+	}
+
+	w, err := syslog.Dial(c.Syslog.Network, c.Syslog.Address, facility|syslog.LOG_INFO, tag)
+	if err != nil {
+		return nil, err
+	}
+
+	return newSinkWriter(c, &syslogSink{writer: w}), nil
+}
+
+func (s *syslogSink) Write(ent zapcore.Entry, p []byte) error {
+	msg := string(p)
+	switch ent.Level {
+	case zapcore.DebugLevel:
+		return s.writer.Debug(msg)
+	case zapcore.InfoLevel:
+		return s.writer.Info(msg)
+	case zapcore.WarnLevel:
+		return s.writer.Warning(msg)
+	case zapcore.ErrorLevel:
+		return s.writer.Err(msg)
+	default:
+		return s.writer.Crit(msg)
+	}
+}
+
+func (s *syslogSink) Sync() error {
+	return nil
+}
+
+func (s *syslogSink) Close() error {
+	return s.writer.Close()
+}
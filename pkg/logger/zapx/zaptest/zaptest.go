@@ -0,0 +1,196 @@
+// Package zaptest captures the logs written through zapx in tests.
+//
+//	func TestHandler(t *testing.T) {
+//		w := zaptest.NewWriter(t)
+//		handle()
+//		w.AssertLogged(zaptest.LevelError, "failed to handle")
+//	}
+//
+// It lives outside zapx, so the zapx package itself can be tested with it,
+// and zapx does not import the testing package.
+package zaptest
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/tedwangl/go-util/pkg/logger/zapx"
+)
+
+// Levels of the captured entries, stack logs are captured at LevelError.
+const (
+	LevelDebug  = "debug"
+	LevelInfo   = "info"
+	LevelError  = "error"
+	LevelSevere = "severe"
+	LevelSlow   = "slow"
+	LevelStat   = "stat"
+	LevelAlert  = "alert"
+)
+
+// contentKey is the default content key, the messages logged by the w methods
+// are passed to the writer as this field.
+const contentKey = "content"
+
+type (
+	// Entry is a log entry captured by Writer.
+	Entry struct {
+		Level   string
+		Content string
+		Fields  map[string]any
+	}
+
+	// Writer captures the log entries in memory, to test the logging behavior
+	// of the packages using the global writer.
+	Writer struct {
+		t       testing.TB
+		lock    sync.Mutex
+		entries []Entry
+	}
+)
+
+var _ zapx.Writer = (*Writer)(nil)
+
+// NewWriter returns a Writer set as the global writer at debug level,
+// the previous writer and level are restored when the test finishes.
+// The tests using it must not run in parallel.
+func NewWriter(t testing.TB) *Writer {
+	w := &Writer{t: t}
+	level := zapx.GetLevel()
+	zapx.SetLevel(zapx.DebugLevel)
+	prev := zapx.Reset()
+	zapx.SetWriter(w)
+	t.Cleanup(func() {
+		zapx.SetWriter(prev)
+		zapx.SetLevel(level)
+	})
+
+	return w
+}
+
+// AssertLogged reports an error if no entry of level contains substr in its content.
+func (w *Writer) AssertLogged(level, substr string) bool {
+	w.t.Helper()
+
+	if w.logged(level, substr) {
+		return true
+	}
+
+	w.t.Errorf("no %s log contains %q, got:\n%s", level, substr, w)
+	return false
+}
+
+// AssertNotLogged reports an error if any entry of level contains substr in its content.
+func (w *Writer) AssertNotLogged(level, substr string) bool {
+	w.t.Helper()
+
+	if !w.logged(level, substr) {
+		return true
+	}
+
+	w.t.Errorf("unexpected %s log contains %q, got:\n%s", level, substr, w)
+	return false
+}
+
+// Clear removes the captured entries.
+func (w *Writer) Clear() {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	w.entries = nil
+}
+
+// Entries returns the captured entries in order.
+func (w *Writer) Entries() []Entry {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	return append([]Entry(nil), w.entries...)
+}
+
+// String returns the captured entries, one per line.
+func (w *Writer) String() string {
+	var b strings.Builder
+	for _, e := range w.Entries() {
+		b.WriteString(e.String())
+		b.WriteByte('\n')
+	}
+	return b.String()
+}
+
+func (w *Writer) Close() error {
+	return nil
+}
+
+func (w *Writer) Debug(_ int, v any, fields ...zapx.LogField) {
+	w.add(LevelDebug, v, fields)
+}
+
+func (w *Writer) Error(_ int, v any, fields ...zapx.LogField) {
+	w.add(LevelError, v, fields)
+}
+
+func (w *Writer) Info(_ int, v any, fields ...zapx.LogField) {
+	w.add(LevelInfo, v, fields)
+}
+
+func (w *Writer) Slow(_ int, v any, fields ...zapx.LogField) {
+	w.add(LevelSlow, v, fields)
+}
+
+func (w *Writer) Severe(_ int, v any) {
+	w.add(LevelSevere, v, nil)
+}
+
+func (w *Writer) Stack(_ int, v any) {
+	w.add(LevelError, v, nil)
+}
+
+func (w *Writer) Stat(_ int, v any, fields ...zapx.LogField) {
+	w.add(LevelStat, v, fields)
+}
+
+func (w *Writer) Alert(v any) {
+	w.add(LevelAlert, v, nil)
+}
+
+func (w *Writer) add(level string, v any, fields []zapx.LogField) {
+	entry := Entry{
+		Level:   level,
+		Content: fmt.Sprint(v),
+		Fields:  make(map[string]any, len(fields)),
+	}
+	for _, field := range fields {
+		// the w methods log the message as the content field
+		if field.Key == contentKey && len(entry.Content) == 0 {
+			entry.Content = fmt.Sprint(field.Value)
+			continue
+		}
+		entry.Fields[field.Key] = field.Value
+	}
+
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	w.entries = append(w.entries, entry)
+}
+
+func (w *Writer) logged(level, substr string) bool {
+	for _, e := range w.Entries() {
+		if e.Level == level && strings.Contains(e.Content, substr) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (e Entry) String() string {
+	var b strings.Builder
+	b.WriteString(e.Level)
+	b.WriteByte(' ')
+	b.WriteString(e.Content)
+	for k, v := range e.Fields {
+		fmt.Fprintf(&b, " %s=%v", k, v)
+	}
+	return b.String()
+}
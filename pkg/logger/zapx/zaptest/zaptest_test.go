@@ -0,0 +1,61 @@
+package zaptest
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/tedwangl/go-util/pkg/logger/zapx"
+)
+
+func TestWriter(t *testing.T) {
+	w := NewWriter(t)
+	zapx.Info("hello")
+	zapx.Debugf("debug %d", 1)
+	zapx.Errorw("failed", zapx.Field("key", "value"))
+
+	entries := w.Entries()
+	if assert.Len(t, entries, 3) {
+		assert.Equal(t, Entry{Level: LevelInfo, Content: "hello", Fields: map[string]any{}}, entries[0])
+		assert.Equal(t, LevelDebug, entries[1].Level)
+		assert.Equal(t, "debug 1", entries[1].Content)
+		assert.Equal(t, "failed", entries[2].Content)
+		assert.Equal(t, "value", entries[2].Fields["key"])
+	}
+
+	assert.True(t, w.AssertLogged(LevelError, "fail"))
+	assert.True(t, w.AssertNotLogged(LevelInfo, "fail"))
+	assert.Contains(t, w.String(), "error failed key=value")
+
+	w.Clear()
+	assert.Empty(t, w.Entries())
+}
+
+func TestWriterAssertFailed(t *testing.T) {
+	w := NewWriter(t)
+	zapx.Info("hello")
+
+	mock := new(testing.T)
+	w.t = mock
+	assert.False(t, w.AssertLogged(LevelError, "hello"))
+	assert.False(t, w.AssertNotLogged(LevelInfo, "hello"))
+	assert.True(t, mock.Failed())
+}
+
+func TestWriterRestore(t *testing.T) {
+	level := zapx.GetLevel()
+	zapx.SetLevel(zapx.ErrorLevel)
+	defer zapx.SetLevel(level)
+
+	var outer *Writer
+	t.Run("inner", func(t *testing.T) {
+		outer = NewWriter(t)
+		zapx.WithContext(context.Background()).Info("inner")
+		assert.Equal(t, zapx.DebugLevel, zapx.GetLevel())
+	})
+
+	zapx.Info("outer")
+	assert.Equal(t, zapx.ErrorLevel, zapx.GetLevel())
+	assert.Len(t, outer.Entries(), 1)
+}
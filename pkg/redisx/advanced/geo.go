@@ -0,0 +1,183 @@
+package advanced
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/tedwangl/go-util/pkg/redisx/client"
+)
+
+// 距离单位
+const (
+	GeoMeters     = "m"
+	GeoKilometers = "km"
+	GeoMiles      = "mi"
+	GeoFeet       = "ft"
+)
+
+// GeoPoint 地理位置
+type GeoPoint struct {
+	Name      string
+	Longitude float64
+	Latitude  float64
+}
+
+// GeoResult 查询结果，Distance 的单位与查询的单位相同
+type GeoResult struct {
+	Name      string
+	Longitude float64
+	Latitude  float64
+	Distance  float64
+}
+
+// GeoCenter 查询中心，Member 不为空时以该成员的位置为中心
+type GeoCenter struct {
+	Member    string
+	Longitude float64
+	Latitude  float64
+}
+
+// GeoSearchOptions 查询选项
+type GeoSearchOptions struct {
+	Unit  string // 距离单位，默认为米
+	Count int    // 最多返回的数量，0 表示不限制
+	Desc  bool   // 按距离从远到近排序，默认从近到远
+}
+
+// GeoIndex GEO 索引（一个有序集合）
+type GeoIndex struct {
+	client client.Client
+	key    string
+}
+
+func NewGeoIndex(cli client.Client, key string) *GeoIndex {
+	return &GeoIndex{
+		client: cli,
+		key:    key,
+	}
+}
+
+// AtMember 以成员的位置为中心
+func AtMember(member string) GeoCenter {
+	return GeoCenter{Member: member}
+}
+
+// AtCoord 以经纬度为中心
+func AtCoord(longitude, latitude float64) GeoCenter {
+	return GeoCenter{Longitude: longitude, Latitude: latitude}
+}
+
+// Add 添加或更新位置，返回新增的数量
+func (g *GeoIndex) Add(ctx context.Context, points ...GeoPoint) (int64, error) {
+	rdb, err := universalClient(g.client)
+	if err != nil {
+		return 0, err
+	}
+
+	locations := make([]*redis.GeoLocation, len(points))
+	for i, p := range points {
+		locations[i] = &redis.GeoLocation{Name: p.Name, Longitude: p.Longitude, Latitude: p.Latitude}
+	}
+	n, err := rdb.GeoAdd(ctx, g.key, locations...).Result()
+	if err != nil {
+		return 0, fmt.Errorf("geoadd failed: %w", err)
+	}
+	return n, nil
+}
+
+// Remove 删除位置
+func (g *GeoIndex) Remove(ctx context.Context, names ...string) (int64, error) {
+	members := make([]interface{}, len(names))
+	for i, name := range names {
+		members[i] = name
+	}
+	return g.client.ZRem(ctx, g.key, members...).Result()
+}
+
+// Distance 计算两个成员之间的距离，任一成员不存在时返回 redis.Nil
+func (g *GeoIndex) Distance(ctx context.Context, a, b, unit string) (float64, error) {
+	rdb, err := universalClient(g.client)
+	if err != nil {
+		return 0, err
+	}
+	if unit == "" {
+		unit = GeoMeters
+	}
+	return rdb.GeoDist(ctx, g.key, a, b, unit).Result()
+}
+
+// SearchRadius 查询圆形范围内的位置
+func (g *GeoIndex) SearchRadius(ctx context.Context, center GeoCenter, radius float64, opts *GeoSearchOptions) ([]GeoResult, error) {
+	q, unit := g.query(center, opts)
+	q.Radius = radius
+	q.RadiusUnit = unit
+	return g.search(ctx, q)
+}
+
+// SearchBox 查询矩形范围内的位置，width 为东西方向的宽度，height 为南北方向的高度
+func (g *GeoIndex) SearchBox(ctx context.Context, center GeoCenter, width, height float64, opts *GeoSearchOptions) ([]GeoResult, error) {
+	q, unit := g.query(center, opts)
+	q.BoxWidth = width
+	q.BoxHeight = height
+	q.BoxUnit = unit
+	return g.search(ctx, q)
+}
+
+func (g *GeoIndex) query(center GeoCenter, opts *GeoSearchOptions) (*redis.GeoSearchLocationQuery, string) {
+	if opts == nil {
+		opts = &GeoSearchOptions{}
+	}
+	unit := opts.Unit
+	if unit == "" {
+		unit = GeoMeters
+	}
+	sort := "ASC"
+	if opts.Desc {
+		sort = "DESC"
+	}
+
+	return &redis.GeoSearchLocationQuery{
+		GeoSearchQuery: redis.GeoSearchQuery{
+			Member:    center.Member,
+			Longitude: center.Longitude,
+			Latitude:  center.Latitude,
+			Sort:      sort,
+			Count:     opts.Count,
+		},
+		WithCoord: true,
+		WithDist:  true,
+	}, unit
+}
+
+func (g *GeoIndex) search(ctx context.Context, q *redis.GeoSearchLocationQuery) ([]GeoResult, error) {
+	rdb, err := universalClient(g.client)
+	if err != nil {
+		return nil, err
+	}
+
+	locations, err := rdb.GeoSearchLocation(ctx, g.key, q).Result()
+	if err != nil {
+		return nil, fmt.Errorf("geosearch failed: %w", err)
+	}
+
+	results := make([]GeoResult, len(locations))
+	for i, loc := range locations {
+		results[i] = GeoResult{
+			Name:      loc.Name,
+			Longitude: loc.Longitude,
+			Latitude:  loc.Latitude,
+			Distance:  loc.Dist,
+		}
+	}
+	return results, nil
+}
+
+// universalClient 获取底层的 go-redis 客户端（多主多从模式不支持）
+func universalClient(cli client.Client) (redis.UniversalClient, error) {
+	rdb, ok := cli.GetClient().(redis.UniversalClient)
+	if !ok {
+		return nil, fmt.Errorf("unsupported client type: %T", cli.GetClient())
+	}
+	return rdb, nil
+}
@@ -0,0 +1,345 @@
+package advanced
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/tedwangl/go-util/pkg/redisx/client"
+)
+
+// ErrSearchNotSupported 服务端没有 RediSearch 模块（Redis 8 以下需要安装 Redis Stack）
+var ErrSearchNotSupported = errors.New("redisx: search module is not available")
+
+// 索引字段类型
+const (
+	SearchText    = "TEXT"
+	SearchTag     = "TAG"
+	SearchNumeric = "NUMERIC"
+	SearchGeo     = "GEO"
+)
+
+// SearchField 索引字段
+type SearchField struct {
+	Name      string
+	Type      string
+	Sortable  bool
+	Weight    float64 // TEXT 字段的权重，0 表示默认
+	Separator string  // TAG 字段的分隔符，默认为逗号
+}
+
+// SearchDoc 查询到的文档
+type SearchDoc struct {
+	ID     string
+	Fields map[string]string
+}
+
+// SearchResult 查询结果
+type SearchResult struct {
+	Total int64
+	Docs  []SearchDoc
+}
+
+// SearchIndex 基于哈希的 RediSearch 索引
+type SearchIndex struct {
+	client client.Client
+	name   string
+
+	mu        sync.Mutex
+	supported *bool
+}
+
+func NewSearchIndex(cli client.Client, name string) *SearchIndex {
+	return &SearchIndex{
+		client: cli,
+		name:   name,
+	}
+}
+
+// SearchSupported 检测服务端是否支持 RediSearch
+func SearchSupported(ctx context.Context, cli client.Client) (bool, error) {
+	rdb, err := universalClient(cli)
+	if err != nil {
+		return false, err
+	}
+
+	err = rdb.Do(ctx, "FT._LIST").Err()
+	switch {
+	case err == nil:
+		return true, nil
+	case isUnknownCommand(err):
+		return false, nil
+	default:
+		return false, fmt.Errorf("detect search module failed: %w", err)
+	}
+}
+
+// Create 创建索引，prefixes 为索引的键前缀，索引已存在时不做处理
+func (s *SearchIndex) Create(ctx context.Context, prefixes []string, fields ...SearchField) error {
+	if len(fields) == 0 {
+		return fmt.Errorf("search index %s: no fields", s.name)
+	}
+
+	args := []interface{}{"FT.CREATE", s.name, "ON", "HASH"}
+	if len(prefixes) > 0 {
+		args = append(args, "PREFIX", len(prefixes))
+		for _, prefix := range prefixes {
+			args = append(args, prefix)
+		}
+	}
+	args = append(args, "SCHEMA")
+	for _, f := range fields {
+		args = append(args, f.Name, f.Type)
+		if f.Type == SearchText && f.Weight > 0 {
+			args = append(args, "WEIGHT", f.Weight)
+		}
+		if f.Type == SearchTag && f.Separator != "" {
+			args = append(args, "SEPARATOR", f.Separator)
+		}
+		if f.Sortable {
+			args = append(args, "SORTABLE")
+		}
+	}
+
+	_, err := s.do(ctx, args...)
+	if err != nil && !strings.Contains(strings.ToLower(err.Error()), "index already exists") {
+		return fmt.Errorf("create search index %s failed: %w", s.name, err)
+	}
+	return nil
+}
+
+// Drop 删除索引，deleteDocs 为 true 时同时删除索引的哈希
+func (s *SearchIndex) Drop(ctx context.Context, deleteDocs bool) error {
+	args := []interface{}{"FT.DROPINDEX", s.name}
+	if deleteDocs {
+		args = append(args, "DD")
+	}
+	if _, err := s.do(ctx, args...); err != nil {
+		return fmt.Errorf("drop search index %s failed: %w", s.name, err)
+	}
+	return nil
+}
+
+// Search 执行查询
+func (s *SearchIndex) Search(ctx context.Context, q *SearchQuery) (*SearchResult, error) {
+	res, err := s.do(ctx, q.args(s.name)...)
+	if err != nil {
+		return nil, fmt.Errorf("search %s failed: %w", s.name, err)
+	}
+	return parseSearchResult(res)
+}
+
+// do 检测服务端能力后执行命令，检测结果会被缓存
+func (s *SearchIndex) do(ctx context.Context, args ...interface{}) (interface{}, error) {
+	rdb, err := universalClient(s.client)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	if s.supported == nil {
+		supported, err := SearchSupported(ctx, s.client)
+		if err != nil {
+			s.mu.Unlock()
+			return nil, err
+		}
+		s.supported = &supported
+	}
+	supported := *s.supported
+	s.mu.Unlock()
+
+	if !supported {
+		return nil, ErrSearchNotSupported
+	}
+	return rdb.Do(ctx, args...).Result()
+}
+
+// SearchQuery 查询构建器，多个条件之间为 AND 关系
+//
+//	q := NewSearchQuery().Match("title", "redis").Tag("city", "beijing", "shanghai").
+//		Range("price", 10, 100).SortBy("price", false).Limit(0, 20)
+type SearchQuery struct {
+	terms   []string
+	sortBy  string
+	desc    bool
+	offset  int
+	limit   int
+	returns []string
+}
+
+func NewSearchQuery() *SearchQuery {
+	return &SearchQuery{limit: 10}
+}
+
+// Text 全文匹配（原样作为查询语法）
+func (q *SearchQuery) Text(text string) *SearchQuery {
+	q.terms = append(q.terms, text)
+	return q
+}
+
+// Match 在 TEXT 字段中全文匹配
+func (q *SearchQuery) Match(field, text string) *SearchQuery {
+	q.terms = append(q.terms, fmt.Sprintf("@%s:(%s)", field, text))
+	return q
+}
+
+// Tag TAG 字段匹配任一值
+func (q *SearchQuery) Tag(field string, values ...string) *SearchQuery {
+	escaped := make([]string, len(values))
+	for i, v := range values {
+		escaped[i] = EscapeTag(v)
+	}
+	q.terms = append(q.terms, fmt.Sprintf("@%s:{%s}", field, strings.Join(escaped, " | ")))
+	return q
+}
+
+// Range NUMERIC 字段范围（闭区间），可以使用 math.Inf 表示不限制
+func (q *SearchQuery) Range(field string, min, max float64) *SearchQuery {
+	q.terms = append(q.terms, fmt.Sprintf("@%s:[%s %s]", field, formatBound(min), formatBound(max)))
+	return q
+}
+
+// Near GEO 字段在圆形范围内
+func (q *SearchQuery) Near(field string, longitude, latitude, radius float64, unit string) *SearchQuery {
+	if unit == "" {
+		unit = GeoMeters
+	}
+	q.terms = append(q.terms, fmt.Sprintf("@%s:[%s %s %s %s]", field,
+		formatBound(longitude), formatBound(latitude), formatBound(radius), unit))
+	return q
+}
+
+// SortBy 按字段排序（字段需要设置 Sortable）
+func (q *SearchQuery) SortBy(field string, desc bool) *SearchQuery {
+	q.sortBy = field
+	q.desc = desc
+	return q
+}
+
+// Limit 分页，默认返回前 10 条
+func (q *SearchQuery) Limit(offset, num int) *SearchQuery {
+	q.offset = offset
+	q.limit = num
+	return q
+}
+
+// Return 只返回指定的字段
+func (q *SearchQuery) Return(fields ...string) *SearchQuery {
+	q.returns = append(q.returns, fields...)
+	return q
+}
+
+// String 返回查询语句
+func (q *SearchQuery) String() string {
+	if len(q.terms) == 0 {
+		return "*"
+	}
+	return strings.Join(q.terms, " ")
+}
+
+func (q *SearchQuery) args(index string) []interface{} {
+	args := []interface{}{"FT.SEARCH", index, q.String()}
+	if len(q.returns) > 0 {
+		args = append(args, "RETURN", len(q.returns))
+		for _, f := range q.returns {
+			args = append(args, f)
+		}
+	}
+	if q.sortBy != "" {
+		order := "ASC"
+		if q.desc {
+			order = "DESC"
+		}
+		args = append(args, "SORTBY", q.sortBy, order)
+	}
+	args = append(args, "LIMIT", q.offset, q.limit)
+	return args
+}
+
+// EscapeTag 转义 TAG 值中的特殊字符
+func EscapeTag(value string) string {
+	var b strings.Builder
+	for _, r := range value {
+		if strings.ContainsRune(",.<>{}[]\"':;!@#$%^&*()-+=~|/\\ ", r) {
+			b.WriteByte('\\')
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+func formatBound(v float64) string {
+	switch {
+	case math.IsInf(v, 1):
+		return "+inf"
+	case math.IsInf(v, -1):
+		return "-inf"
+	}
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
+
+// parseSearchResult 解析 FT.SEARCH 的结果，兼容 RESP2（数组）和 RESP3（map）
+func parseSearchResult(res interface{}) (*SearchResult, error) {
+	switch v := res.(type) {
+	case []interface{}:
+		if len(v) == 0 {
+			return nil, fmt.Errorf("unexpected search result: empty reply")
+		}
+		total, ok := v[0].(int64)
+		if !ok {
+			return nil, fmt.Errorf("unexpected search result total: %T", v[0])
+		}
+		result := &SearchResult{Total: total}
+		for i := 1; i < len(v); i++ {
+			doc := SearchDoc{ID: fmt.Sprint(v[i])}
+			if i+1 < len(v) {
+				if fields, ok := v[i+1].([]interface{}); ok {
+					doc.Fields = pairsToMap(fields)
+					i++
+				}
+			}
+			result.Docs = append(result.Docs, doc)
+		}
+		return result, nil
+
+	case map[interface{}]interface{}:
+		result := &SearchResult{}
+		if total, ok := v["total_results"].(int64); ok {
+			result.Total = total
+		}
+		docs, _ := v["results"].([]interface{})
+		for _, d := range docs {
+			m, ok := d.(map[interface{}]interface{})
+			if !ok {
+				continue
+			}
+			doc := SearchDoc{ID: fmt.Sprint(m["id"]), Fields: make(map[string]string)}
+			if attrs, ok := m["extra_attributes"].(map[interface{}]interface{}); ok {
+				for k, val := range attrs {
+					doc.Fields[fmt.Sprint(k)] = fmt.Sprint(val)
+				}
+			}
+			result.Docs = append(result.Docs, doc)
+		}
+		return result, nil
+
+	default:
+		return nil, fmt.Errorf("unexpected search result type: %T", res)
+	}
+}
+
+func pairsToMap(pairs []interface{}) map[string]string {
+	m := make(map[string]string, len(pairs)/2)
+	for i := 0; i+1 < len(pairs); i += 2 {
+		m[fmt.Sprint(pairs[i])] = fmt.Sprint(pairs[i+1])
+	}
+	return m
+}
+
+func isUnknownCommand(err error) bool {
+	return strings.Contains(strings.ToLower(err.Error()), "unknown command")
+}
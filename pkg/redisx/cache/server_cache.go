@@ -213,6 +213,31 @@ func (c *ServerCache) SetSystemStatus(ctx context.Context, status map[string]int
 	return c.SetMulti(ctx, statusItems, expiration)
 }
 
+// SetWithTags 设置缓存值并打上标签，之后可以通过 InvalidateTag 批量删除
+func (c *ServerCache) SetWithTags(ctx context.Context, key string, value interface{}, expiration time.Duration, tags ...string) error {
+	val, err := c.marshalValue(value)
+	if err != nil {
+		return err
+	}
+
+	return setWithTags(ctx, c.client, c.prefix, c.key(key), val, expiration, tags)
+}
+
+// InvalidateTag 删除标签下的所有缓存，返回删除的缓存数
+func (c *ServerCache) InvalidateTag(ctx context.Context, tag string) (int64, error) {
+	return invalidateTags(ctx, c.client, c.prefix, []string{tag})
+}
+
+// InvalidateTags 删除多个标签下的所有缓存，返回删除的缓存数
+func (c *ServerCache) InvalidateTags(ctx context.Context, tags ...string) (int64, error) {
+	return invalidateTags(ctx, c.client, c.prefix, tags)
+}
+
+// TagKeys 获取标签下的缓存键（可能包含已过期的键）
+func (c *ServerCache) TagKeys(ctx context.Context, tag string) ([]string, error) {
+	return tagMembers(ctx, c.client, c.prefix, tag)
+}
+
 // marshalValue 序列化值
 func (c *ServerCache) marshalValue(value interface{}) (interface{}, error) {
 	switch v := value.(type) {
@@ -0,0 +1,122 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/tedwangl/go-util/pkg/redisx/client"
+)
+
+// TagCache 支持按标签批量失效的缓存
+//
+// 每个标签对应一个集合（<prefix>:tags:<tag>），记录打了该标签的缓存键。
+// 写入和失效都通过 Lua 脚本原子执行，失效时删除标签下的所有缓存键和标签集合。
+// 脚本会访问未在 KEYS 中声明的缓存键，集群模式下缓存键和标签需要使用相同的 hash tag（如 {user:1}）
+type TagCache interface {
+	Cache
+
+	// SetWithTags 设置缓存值并打上标签，expiration 为 0 时不过期
+	SetWithTags(ctx context.Context, key string, value interface{}, expiration time.Duration, tags ...string) error
+	// InvalidateTag 删除标签下的所有缓存，返回删除的缓存数
+	InvalidateTag(ctx context.Context, tag string) (int64, error)
+	// InvalidateTags 删除多个标签下的所有缓存，返回删除的缓存数
+	InvalidateTags(ctx context.Context, tags ...string) (int64, error)
+	// TagKeys 获取标签下的缓存键（可能包含已过期的键）
+	TagKeys(ctx context.Context, tag string) ([]string, error)
+}
+
+var (
+	_ TagCache = (*ServerCache)(nil)
+	_ TagCache = (*UserCache)(nil)
+)
+
+// setWithTagsScript 写入缓存并加入标签集合
+// KEYS[1] 缓存键，KEYS[2..] 标签集合；ARGV[1] 值，ARGV[2] 过期毫秒数（0 为不过期）
+// 标签集合的过期时间不短于其中缓存的过期时间，有不过期的缓存时标签集合也不过期
+const setWithTagsScript = `
+local ttl = tonumber(ARGV[2])
+if ttl > 0 then
+	redis.call("SET", KEYS[1], ARGV[1], "PX", ttl)
+else
+	redis.call("SET", KEYS[1], ARGV[1])
+end
+for i = 2, #KEYS do
+	local existed = redis.call("EXISTS", KEYS[i]) == 1
+	redis.call("SADD", KEYS[i], KEYS[1])
+	if ttl == 0 then
+		redis.call("PERSIST", KEYS[i])
+	elseif not existed then
+		redis.call("PEXPIRE", KEYS[i], ttl)
+	else
+		local current = redis.call("PTTL", KEYS[i])
+		if current >= 0 and current < ttl then
+			redis.call("PEXPIRE", KEYS[i], ttl)
+		end
+	end
+end
+return 1
+`
+
+// invalidateTagsScript 删除标签集合中的缓存键和标签集合
+// KEYS 为标签集合，返回删除的缓存数
+const invalidateTagsScript = `
+local deleted = 0
+for _, tag in ipairs(KEYS) do
+	local keys = redis.call("SMEMBERS", tag)
+	for i = 1, #keys, 500 do
+		deleted = deleted + redis.call("DEL", unpack(keys, i, math.min(i + 499, #keys)))
+	end
+	redis.call("DEL", tag)
+end
+return deleted
+`
+
+// tagKey 生成标签集合的键
+func tagKey(prefix, tag string) string {
+	return fmt.Sprintf("%s:tags:%s", prefix, tag)
+}
+
+// setWithTags 原子写入缓存和标签，cacheKey 为带前缀的缓存键
+func setWithTags(ctx context.Context, cli client.Client, prefix, cacheKey string, value interface{}, expiration time.Duration, tags []string) error {
+	keys := make([]string, 0, len(tags)+1)
+	keys = append(keys, cacheKey)
+	for _, tag := range tags {
+		keys = append(keys, tagKey(prefix, tag))
+	}
+
+	ttl := expiration.Milliseconds()
+	if expiration > 0 && ttl == 0 {
+		ttl = 1
+	}
+	return cli.Eval(ctx, setWithTagsScript, keys, value, ttl).Err()
+}
+
+// invalidateTags 原子删除标签下的缓存
+func invalidateTags(ctx context.Context, cli client.Client, prefix string, tags []string) (int64, error) {
+	if len(tags) == 0 {
+		return 0, nil
+	}
+
+	keys := make([]string, len(tags))
+	for i, tag := range tags {
+		keys[i] = tagKey(prefix, tag)
+	}
+
+	return cli.Eval(ctx, invalidateTagsScript, keys).Int64()
+}
+
+// tagMembers 获取标签下的缓存键（去掉前缀）
+func tagMembers(ctx context.Context, cli client.Client, prefix, tag string) ([]string, error) {
+	members, err := cli.SMembers(ctx, tagKey(prefix, tag)).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	keys := make([]string, len(members))
+	for i, member := range members {
+		keys[i] = strings.TrimPrefix(member, prefix+":")
+	}
+	return keys, nil
+}
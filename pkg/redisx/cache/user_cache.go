@@ -216,6 +216,8 @@ func (c *UserCache) SetUserInfo(ctx context.Context, userID string, info map[str
 }
 
 // GetUserSession 获取用户会话
+//
+// Deprecated: 使用 session.Store，支持滚动过期、设备记录和并发会话数限制
 func (c *UserCache) GetUserSession(ctx context.Context, sessionID string) (map[string]interface{}, error) {
 	val, err := c.Get(ctx, fmt.Sprintf("session:%s", sessionID))
 	if err != nil {
@@ -242,6 +244,8 @@ func (c *UserCache) GetUserSession(ctx context.Context, sessionID string) (map[s
 }
 
 // SetUserSession 设置用户会话
+//
+// Deprecated: 使用 session.Store，支持滚动过期、设备记录和并发会话数限制
 func (c *UserCache) SetUserSession(ctx context.Context, sessionID string, session map[string]interface{}, expiration time.Duration) error {
 	return c.Set(ctx, fmt.Sprintf("session:%s", sessionID), session, expiration)
 }
@@ -252,10 +256,37 @@ func (c *UserCache) DeleteUserInfo(ctx context.Context, userID string) error {
 }
 
 // DeleteUserSession 删除用户会话
+//
+// Deprecated: 使用 session.Store，支持滚动过期、设备记录和并发会话数限制
 func (c *UserCache) DeleteUserSession(ctx context.Context, sessionID string) error {
 	return c.Delete(ctx, fmt.Sprintf("session:%s", sessionID))
 }
 
+// SetWithTags 设置缓存值并打上标签，之后可以通过 InvalidateTag 批量删除
+func (c *UserCache) SetWithTags(ctx context.Context, key string, value interface{}, expiration time.Duration, tags ...string) error {
+	val, err := c.marshalValue(value)
+	if err != nil {
+		return err
+	}
+
+	return setWithTags(ctx, c.client, c.prefix, c.key(key), val, expiration, tags)
+}
+
+// InvalidateTag 删除标签下的所有缓存，返回删除的缓存数
+func (c *UserCache) InvalidateTag(ctx context.Context, tag string) (int64, error) {
+	return invalidateTags(ctx, c.client, c.prefix, []string{tag})
+}
+
+// InvalidateTags 删除多个标签下的所有缓存，返回删除的缓存数
+func (c *UserCache) InvalidateTags(ctx context.Context, tags ...string) (int64, error) {
+	return invalidateTags(ctx, c.client, c.prefix, tags)
+}
+
+// TagKeys 获取标签下的缓存键（可能包含已过期的键）
+func (c *UserCache) TagKeys(ctx context.Context, tag string) ([]string, error) {
+	return tagMembers(ctx, c.client, c.prefix, tag)
+}
+
 // marshalValue 序列化值
 func (c *UserCache) marshalValue(value interface{}) (string, error) {
 	switch v := value.(type) {
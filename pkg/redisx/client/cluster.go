@@ -27,19 +27,21 @@ func NewClusterClient(cfg *config.ClusterConfig, opts *config.Config) (*ClusterC
 	}
 
 	redisOpts := &redis.ClusterOptions{
-		Addrs:        cfg.Addrs,
-		Username:     opts.Username,
-		Password:     opts.Password,
-		PoolSize:     opts.PoolSize,
-		MinIdleConns: opts.MinIdleConns,
-		MaxRetries:   opts.MaxRetries,
-		DialTimeout:  opts.DialTimeout,
-		ReadTimeout:  opts.ReadTimeout,
-		WriteTimeout: opts.WriteTimeout,
-		PoolTimeout:  opts.PoolTimeout,
+		Addrs:                 cfg.Addrs,
+		Username:              opts.Username,
+		Password:              opts.Password,
+		PoolSize:              opts.PoolSize,
+		MinIdleConns:          opts.MinIdleConns,
+		MaxRetries:            opts.MaxRetries,
+		DialTimeout:           opts.DialTimeout,
+		ReadTimeout:           opts.ReadTimeout,
+		WriteTimeout:          opts.WriteTimeout,
+		PoolTimeout:           opts.PoolTimeout,
+		ContextTimeoutEnabled: opts.CommandTimeout > 0,
 	}
 
 	client := redis.NewClusterClient(redisOpts)
+	addDeadlineHook(opts, client)
 
 	return &ClusterClient{
 		client: client,
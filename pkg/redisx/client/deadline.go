@@ -0,0 +1,154 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"github.com/tedwangl/go-util/pkg/redisx/config"
+	redisxerrors "github.com/tedwangl/go-util/pkg/redisx/errors"
+)
+
+// blockingCommands 阻塞命令，等待时间由参数指定，不受 CommandTimeout 限制
+var blockingCommands = map[string]bool{
+	"blpop": true, "brpop": true, "brpoplpush": true, "blmove": true, "blmpop": true,
+	"bzpopmin": true, "bzpopmax": true, "bzmpop": true,
+	"xread": true, "xreadgroup": true, "wait": true, "waitaof": true,
+	"subscribe": true, "psubscribe": true, "ssubscribe": true,
+}
+
+// deadlineHook 命令级别的超时和 context 检查
+type deadlineHook struct {
+	timeout         time.Duration
+	requireDeadline bool
+}
+
+// hookable 支持添加 hook 的 go-redis 客户端
+type hookable interface {
+	AddHook(hook redis.Hook)
+}
+
+// addDeadlineHook 按配置为客户端添加命令超时和 context 检查（未配置时不添加）
+func addDeadlineHook(opts *config.Config, clients ...hookable) {
+	if opts.CommandTimeout <= 0 && !opts.RequireContextDeadline {
+		return
+	}
+	hook := &deadlineHook{timeout: opts.CommandTimeout, requireDeadline: opts.RequireContextDeadline}
+	for _, c := range clients {
+		c.AddHook(hook)
+	}
+}
+
+// DialHook 实现 redis.Hook
+func (h *deadlineHook) DialHook(next redis.DialHook) redis.DialHook {
+	return next
+}
+
+// ProcessHook 实现 redis.Hook
+func (h *deadlineHook) ProcessHook(next redis.ProcessHook) redis.ProcessHook {
+	return func(ctx context.Context, cmd redis.Cmder) error {
+		name, key := cmd.Name(), cmdKey(cmd)
+		ctx, cancel, err := h.begin(ctx, name, key, !blockingCommands[name])
+		if err != nil {
+			cmd.SetErr(err)
+			return err
+		}
+		defer cancel()
+
+		if err := h.annotate(ctx, name, key, next(ctx, cmd)); err != nil {
+			cmd.SetErr(err)
+			return err
+		}
+		return nil
+	}
+}
+
+// ProcessPipelineHook 实现 redis.Hook，整个管道共用一个超时
+func (h *deadlineHook) ProcessPipelineHook(next redis.ProcessPipelineHook) redis.ProcessPipelineHook {
+	return func(ctx context.Context, cmds []redis.Cmder) error {
+		ctx, cancel, err := h.begin(ctx, "pipeline", "", true)
+		if err != nil {
+			setCmdsErr(cmds, err)
+			return err
+		}
+		defer cancel()
+
+		if err := h.annotate(ctx, "pipeline", "", next(ctx, cmds)); err != nil {
+			if errors.Is(err, redisxerrors.ErrCommandTimeout) {
+				setTimeoutErr(cmds, err)
+			}
+			return err
+		}
+		return nil
+	}
+}
+
+// begin 检查 context 并设置超时
+func (h *deadlineHook) begin(ctx context.Context, op, key string, withTimeout bool) (context.Context, context.CancelFunc, error) {
+	if _, ok := ctx.Deadline(); !ok && h.requireDeadline {
+		return nil, nil, redisxerrors.NewOperationError(op, key, "context without deadline is not allowed", redisxerrors.ErrContextNoDeadline)
+	}
+	if h.timeout <= 0 || !withTimeout {
+		return ctx, func() {}, nil
+	}
+	ctx, cancel := context.WithTimeout(ctx, h.timeout)
+	return ctx, cancel, nil
+}
+
+// annotate 命令因超时失败时包装为 OperationError（超时时 go-redis 可能返回网络超时错误而不是 context 错误）
+func (h *deadlineHook) annotate(ctx context.Context, op, key string, err error) error {
+	if err == nil || errors.Is(err, redis.Nil) || !errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return err
+	}
+	if !isTimeoutErr(err) {
+		return err
+	}
+
+	message := "context deadline exceeded"
+	if h.timeout > 0 {
+		message = fmt.Sprintf("timeout after %s", h.timeout)
+	}
+	// 连接初始化（HELLO 等）超时时错误已经包装过
+	if !errors.Is(err, redisxerrors.ErrCommandTimeout) {
+		err = fmt.Errorf("%w: %w", redisxerrors.ErrCommandTimeout, err)
+	}
+	return redisxerrors.NewOperationError(op, key, message, err)
+}
+
+// cmdKey 返回命令的第一个参数作为键（用于错误信息）
+func cmdKey(cmd redis.Cmder) string {
+	args := cmd.Args()
+	if len(args) < 2 {
+		return ""
+	}
+	if key, ok := args[1].(string); ok {
+		return key
+	}
+	return ""
+}
+
+// isTimeoutErr 判断是否为 context 或网络超时错误
+func isTimeoutErr(err error) bool {
+	var netErr net.Error
+	return errors.Is(err, context.DeadlineExceeded) || (errors.As(err, &netErr) && netErr.Timeout())
+}
+
+// setCmdsErr 为管道中的所有命令设置错误
+func setCmdsErr(cmds []redis.Cmder, err error) {
+	for _, cmd := range cmds {
+		cmd.SetErr(err)
+	}
+}
+
+// setTimeoutErr 将管道中因超时失败的命令的错误替换为 err（保留成功的结果和其他错误）
+func setTimeoutErr(cmds []redis.Cmder, err error) {
+	for _, cmd := range cmds {
+		if isTimeoutErr(cmd.Err()) {
+			cmd.SetErr(err)
+		}
+	}
+}
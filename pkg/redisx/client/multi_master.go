@@ -54,39 +54,43 @@ func NewMultiMasterClient(cfg *config.MultiMasterConfig, opts *config.Config) (*
 	for _, master := range cfg.Masters {
 		// 创建主节点客户端
 		masterOpts := &redis.Options{
-			Addr:         master.Addr,
-			Username:     opts.Username,
-			Password:     opts.Password,
-			DB:           opts.DB,
-			PoolSize:     opts.PoolSize / len(cfg.Masters),
-			MinIdleConns: opts.MinIdleConns / len(cfg.Masters),
-			MaxRetries:   opts.MaxRetries,
-			DialTimeout:  opts.DialTimeout,
-			ReadTimeout:  opts.ReadTimeout,
-			WriteTimeout: opts.WriteTimeout,
-			PoolTimeout:  opts.PoolTimeout,
+			Addr:                  master.Addr,
+			Username:              opts.Username,
+			Password:              opts.Password,
+			DB:                    opts.DB,
+			PoolSize:              opts.PoolSize / len(cfg.Masters),
+			MinIdleConns:          opts.MinIdleConns / len(cfg.Masters),
+			MaxRetries:            opts.MaxRetries,
+			DialTimeout:           opts.DialTimeout,
+			ReadTimeout:           opts.ReadTimeout,
+			WriteTimeout:          opts.WriteTimeout,
+			PoolTimeout:           opts.PoolTimeout,
+			ContextTimeoutEnabled: opts.CommandTimeout > 0,
 		}
 
 		masterClient := redis.NewClient(masterOpts)
+		addDeadlineHook(opts, masterClient)
 		masters = append(masters, masterClient)
 
 		// 创建从节点客户端
 		for _, slaveAddr := range master.Slaves {
 			slaveOpts := &redis.Options{
-				Addr:         slaveAddr,
-				Username:     opts.Username,
-				Password:     opts.Password,
-				DB:           opts.DB,
-				PoolSize:     opts.PoolSize / len(master.Slaves) / len(cfg.Masters),
-				MinIdleConns: opts.MinIdleConns / len(master.Slaves) / len(cfg.Masters),
-				MaxRetries:   opts.MaxRetries,
-				DialTimeout:  opts.DialTimeout,
-				ReadTimeout:  opts.ReadTimeout,
-				WriteTimeout: opts.WriteTimeout,
-				PoolTimeout:  opts.PoolTimeout,
+				Addr:                  slaveAddr,
+				Username:              opts.Username,
+				Password:              opts.Password,
+				DB:                    opts.DB,
+				PoolSize:              opts.PoolSize / len(master.Slaves) / len(cfg.Masters),
+				MinIdleConns:          opts.MinIdleConns / len(master.Slaves) / len(cfg.Masters),
+				MaxRetries:            opts.MaxRetries,
+				DialTimeout:           opts.DialTimeout,
+				ReadTimeout:           opts.ReadTimeout,
+				WriteTimeout:          opts.WriteTimeout,
+				PoolTimeout:           opts.PoolTimeout,
+				ContextTimeoutEnabled: opts.CommandTimeout > 0,
 			}
 
 			slaveClient := redis.NewClient(slaveOpts)
+			addDeadlineHook(opts, slaveClient)
 			slaves = append(slaves, slaveClient)
 		}
 	}
@@ -27,22 +27,24 @@ func NewSentinelClient(cfg *config.SentinelConfig, opts *config.Config) (*Sentin
 	}
 
 	redisOpts := &redis.FailoverOptions{
-		MasterName:       cfg.MasterName,
-		SentinelAddrs:    cfg.SentinelAddrs,
-		SentinelPassword: cfg.SentinelPassword,
-		Username:         opts.Username,
-		Password:         opts.Password,
-		DB:               opts.DB,
-		PoolSize:         opts.PoolSize,
-		MinIdleConns:     opts.MinIdleConns,
-		MaxRetries:       opts.MaxRetries,
-		DialTimeout:      opts.DialTimeout,
-		ReadTimeout:      opts.ReadTimeout,
-		WriteTimeout:     opts.WriteTimeout,
-		PoolTimeout:      opts.PoolTimeout,
+		MasterName:            cfg.MasterName,
+		SentinelAddrs:         cfg.SentinelAddrs,
+		SentinelPassword:      cfg.SentinelPassword,
+		Username:              opts.Username,
+		Password:              opts.Password,
+		DB:                    opts.DB,
+		PoolSize:              opts.PoolSize,
+		MinIdleConns:          opts.MinIdleConns,
+		MaxRetries:            opts.MaxRetries,
+		DialTimeout:           opts.DialTimeout,
+		ReadTimeout:           opts.ReadTimeout,
+		WriteTimeout:          opts.WriteTimeout,
+		PoolTimeout:           opts.PoolTimeout,
+		ContextTimeoutEnabled: opts.CommandTimeout > 0,
 	}
 
 	client := redis.NewFailoverClient(redisOpts)
+	addDeadlineHook(opts, client)
 
 	return &SentinelClient{
 		client: client,
@@ -27,20 +27,22 @@ func NewSingleClient(cfg *config.SingleConfig, opts *config.Config) (*SingleClie
 	}
 
 	redisOpts := &redis.Options{
-		Addr:         cfg.Addr,
-		Username:     opts.Username,
-		Password:     opts.Password,
-		DB:           opts.DB,
-		PoolSize:     opts.PoolSize,
-		MinIdleConns: opts.MinIdleConns,
-		MaxRetries:   opts.MaxRetries,
-		DialTimeout:  opts.DialTimeout,
-		ReadTimeout:  opts.ReadTimeout,
-		WriteTimeout: opts.WriteTimeout,
-		PoolTimeout:  opts.PoolTimeout,
+		Addr:                  cfg.Addr,
+		Username:              opts.Username,
+		Password:              opts.Password,
+		DB:                    opts.DB,
+		PoolSize:              opts.PoolSize,
+		MinIdleConns:          opts.MinIdleConns,
+		MaxRetries:            opts.MaxRetries,
+		DialTimeout:           opts.DialTimeout,
+		ReadTimeout:           opts.ReadTimeout,
+		WriteTimeout:          opts.WriteTimeout,
+		PoolTimeout:           opts.PoolTimeout,
+		ContextTimeoutEnabled: opts.CommandTimeout > 0,
 	}
 
 	client := redis.NewClient(redisOpts)
+	addDeadlineHook(opts, client)
 
 	return &SingleClient{
 		client: client,
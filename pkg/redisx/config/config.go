@@ -8,8 +8,8 @@ import (
 
 // Config 是RedisX的主配置结构
 type Config struct {
-	Mode  string // 部署模式: single, sentinel, cluster, multi-master
-	Debug bool   // 是否开启调试模式
+	Mode  string `json:"mode" yaml:"mode"`   // 部署模式: single, sentinel, cluster, multi-master
+	Debug bool   `json:"debug" yaml:"debug"` // 是否开启调试模式
 
 	// 单节点配置
 	Single *SingleConfig `json:"single,omitempty" yaml:"single,omitempty"`
@@ -34,6 +34,10 @@ type Config struct {
 	ReadTimeout  time.Duration `json:"read_timeout" yaml:"read_timeout"`
 	WriteTimeout time.Duration `json:"write_timeout" yaml:"write_timeout"`
 	PoolTimeout  time.Duration `json:"pool_timeout" yaml:"pool_timeout"`
+
+	// 命令级别的超时和 context 检查（与连接、读写超时不同，覆盖排队、重试的整个过程）
+	CommandTimeout         time.Duration `json:"command_timeout,omitempty" yaml:"command_timeout,omitempty"`                   // 单个命令或管道的超时，0 表示不限制（阻塞命令除外）
+	RequireContextDeadline bool          `json:"require_context_deadline,omitempty" yaml:"require_context_deadline,omitempty"` // 拒绝没有截止时间的 context，用于发现可能无限阻塞的调用
 }
 
 // SingleConfig 单节点配置
@@ -120,5 +124,39 @@ func (c *Config) Validate() error {
 		return redisxerrors.ErrConfigMode
 	}
 
+	return c.validateOptions()
+}
+
+// validateOptions 验证通用配置
+func (c *Config) validateOptions() error {
+	switch {
+	case c.DB < 0:
+		return redisxerrors.NewConfigError("db", "must not be negative", nil)
+	case c.PoolSize < 0:
+		return redisxerrors.NewConfigError("pool_size", "must not be negative", nil)
+	case c.MinIdleConns < 0:
+		return redisxerrors.NewConfigError("min_idle_conns", "must not be negative", nil)
+	case c.PoolSize > 0 && c.MinIdleConns > c.PoolSize:
+		return redisxerrors.NewConfigError("min_idle_conns", "must not exceed pool_size", nil)
+	case c.MaxRetries < -1:
+		return redisxerrors.NewConfigError("max_retries", "must be -1 (disabled) or greater", nil)
+	}
+
+	timeouts := []struct {
+		field   string
+		timeout time.Duration
+	}{
+		{"dial_timeout", c.DialTimeout},
+		{"read_timeout", c.ReadTimeout},
+		{"write_timeout", c.WriteTimeout},
+		{"pool_timeout", c.PoolTimeout},
+		{"command_timeout", c.CommandTimeout},
+	}
+	for _, t := range timeouts {
+		if t.timeout < 0 {
+			return redisxerrors.NewConfigError(t.field, "must not be negative", nil)
+		}
+	}
+
 	return nil
 }
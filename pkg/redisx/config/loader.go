@@ -1,17 +1,20 @@
 package config
 
 import (
-	"encoding/json"
-	"errors"
+	"bytes"
 	"fmt"
 	"os"
 	"path/filepath"
 	"strings"
 
+	"github.com/go-viper/mapstructure/v2"
+	"github.com/spf13/viper"
 	redisxerrors "github.com/tedwangl/go-util/pkg/redisx/errors"
-	"gopkg.in/yaml.v3"
 )
 
+// defaultEnvPrefix 环境变量默认前缀
+const defaultEnvPrefix = "REDISX"
+
 // ErrConfigMultiMasterAddr 多主多从地址为空
 func ErrConfigMultiMasterAddr(index int) error {
 	return redisxerrors.NewConfigError(fmt.Sprintf("masters[%d]", index), "address is empty", nil)
@@ -22,7 +25,7 @@ type Loader interface {
 	Load() (*Config, error)
 }
 
-// FileLoader 文件加载器
+// FileLoader 文件加载器（支持 YAML、JSON，未设置的项使用默认值）
 type FileLoader struct {
 	Path string
 }
@@ -35,38 +38,21 @@ func NewFileLoader(path string) *FileLoader {
 // Load 从文件加载配置
 func (l *FileLoader) Load() (*Config, error) {
 	if l.Path == "" {
-		return nil, errors.New("file path is empty")
+		return nil, redisxerrors.NewConfigError("file", "path is empty", nil)
 	}
 
 	content, err := os.ReadFile(l.Path)
 	if err != nil {
-		return nil, fmt.Errorf("read file error: %w", err)
-	}
-
-	ext := strings.ToLower(filepath.Ext(l.Path))
-	cfg := &Config{}
-
-	switch ext {
-	case ".json":
-		err = json.Unmarshal(content, cfg)
-	case ".yaml", ".yml":
-		err = yaml.Unmarshal(content, cfg)
-	default:
-		return nil, fmt.Errorf("unsupported file extension: %s", ext)
-	}
-
-	if err != nil {
-		return nil, fmt.Errorf("unmarshal error: %w", err)
-	}
-
-	if err := cfg.Validate(); err != nil {
-		return nil, err
+		return nil, redisxerrors.NewConfigError("file", "read "+l.Path+" failed", err)
 	}
 
-	return cfg, nil
+	return LoadFromBytes(content, strings.TrimPrefix(filepath.Ext(l.Path), "."))
 }
 
 // EnvLoader 环境变量加载器
+// 变量名为 <PREFIX>_<配置项>，嵌套项用下划线连接，如 REDISX_POOL_SIZE、REDISX_SINGLE_ADDR、
+// REDISX_SENTINEL_MASTER_NAME、REDISX_DIAL_TIMEOUT=5s，列表用逗号分隔，
+// 多主多从只支持 REDISX_MULTI_MASTER_ADDRS（逗号分隔的主节点地址）
 type EnvLoader struct {
 	Prefix string
 }
@@ -78,60 +64,27 @@ func NewEnvLoader(prefix string) *EnvLoader {
 
 // Load 从环境变量加载配置
 func (l *EnvLoader) Load() (*Config, error) {
-	cfg := DefaultConfig()
-
 	prefix := l.Prefix
 	if prefix == "" {
-		prefix = "REDISX"
+		prefix = defaultEnvPrefix
 	}
 
-	if mode := os.Getenv(fmt.Sprintf("%s_MODE", prefix)); mode != "" {
-		cfg.Mode = mode
-	}
-
-	if debug := os.Getenv(fmt.Sprintf("%s_DEBUG", prefix)); debug == "true" {
-		cfg.Debug = true
-	}
+	v := viper.New()
+	v.SetEnvPrefix(prefix)
+	v.SetEnvKeyReplacer(strings.NewReplacer(".", "_", "-", "_"))
+	v.AutomaticEnv()
+	setDefaults(v, "")
 
-	if password := os.Getenv(fmt.Sprintf("%s_PASSWORD", prefix)); password != "" {
-		cfg.Password = password
+	cfg, err := decode(v)
+	if err != nil {
+		return nil, err
 	}
 
-	switch cfg.Mode {
-	case "single":
-		if cfg.Single == nil {
-			cfg.Single = &SingleConfig{}
-		}
-		if addr := os.Getenv(fmt.Sprintf("%s_SINGLE_ADDR", prefix)); addr != "" {
-			cfg.Single.Addr = addr
-		}
-	case "sentinel":
-		if cfg.Sentinel == nil {
-			cfg.Sentinel = &SentinelConfig{}
-		}
-		if masterName := os.Getenv(fmt.Sprintf("%s_SENTINEL_MASTER_NAME", prefix)); masterName != "" {
-			cfg.Sentinel.MasterName = masterName
-		}
-		if addrs := os.Getenv(fmt.Sprintf("%s_SENTINEL_ADDRS", prefix)); addrs != "" {
-			cfg.Sentinel.SentinelAddrs = strings.Split(addrs, ",")
-		}
-	case "cluster":
-		if cfg.Cluster == nil {
-			cfg.Cluster = &ClusterConfig{}
-		}
-		if addrs := os.Getenv(fmt.Sprintf("%s_CLUSTER_ADDRS", prefix)); addrs != "" {
-			cfg.Cluster.Addrs = strings.Split(addrs, ",")
-		}
-	case "multi-master":
-		if cfg.MultiMaster == nil {
-			cfg.MultiMaster = &MultiMasterConfig{}
-		}
-		if addrs := os.Getenv(fmt.Sprintf("%s_MULTI_MASTER_ADDRS", prefix)); addrs != "" {
-			addrList := strings.Split(addrs, ",")
-			for _, addr := range addrList {
-				if addr != "" {
-					cfg.MultiMaster.Masters = append(cfg.MultiMaster.Masters, MasterConfig{Addr: addr})
-				}
+	if addrs := os.Getenv(prefix + "_MULTI_MASTER_ADDRS"); addrs != "" {
+		cfg.MultiMaster = &MultiMasterConfig{}
+		for _, addr := range strings.Split(addrs, ",") {
+			if addr = strings.TrimSpace(addr); addr != "" {
+				cfg.MultiMaster.Masters = append(cfg.MultiMaster.Masters, MasterConfig{Addr: addr})
 			}
 		}
 	}
@@ -155,23 +108,24 @@ func LoadFromEnv(prefix string) (*Config, error) {
 	return loader.Load()
 }
 
-// LoadFromBytes 从字节数组加载配置
+// LoadFromBytes 从字节数组加载配置，format 为 yaml、yml 或 json
 func LoadFromBytes(data []byte, format string) (*Config, error) {
-	cfg := &Config{}
-
-	switch strings.ToLower(format) {
-	case "json":
-		err := json.Unmarshal(data, cfg)
-		if err != nil {
-			return nil, err
-		}
-	case "yaml", "yml":
-		err := yaml.Unmarshal(data, cfg)
-		if err != nil {
-			return nil, err
-		}
+	format = strings.ToLower(format)
+	switch format {
+	case "json", "yaml", "yml":
 	default:
-		return nil, fmt.Errorf("unsupported format: %s", format)
+		return nil, redisxerrors.NewConfigError("format", "unsupported format "+format, nil)
+	}
+
+	v := viper.New()
+	v.SetConfigType(format)
+	if err := v.ReadConfig(bytes.NewReader(data)); err != nil {
+		return nil, redisxerrors.NewConfigError("format", "parse "+format+" failed", err)
+	}
+
+	cfg, err := decode(v)
+	if err != nil {
+		return nil, err
 	}
 
 	if err := cfg.Validate(); err != nil {
@@ -180,3 +134,45 @@ func LoadFromBytes(data []byte, format string) (*Config, error) {
 
 	return cfg, nil
 }
+
+// setDefaults 注册默认配置项
+func setDefaults(v *viper.Viper, prefix string) {
+	d := DefaultConfig()
+	defaults := map[string]any{
+		"mode":                       d.Mode,
+		"debug":                      d.Debug,
+		"username":                   d.Username,
+		"password":                   d.Password,
+		"db":                         d.DB,
+		"pool_size":                  d.PoolSize,
+		"min_idle_conns":             d.MinIdleConns,
+		"max_retries":                d.MaxRetries,
+		"dial_timeout":               d.DialTimeout,
+		"read_timeout":               d.ReadTimeout,
+		"write_timeout":              d.WriteTimeout,
+		"pool_timeout":               d.PoolTimeout,
+		"command_timeout":            d.CommandTimeout,
+		"require_context_deadline":   d.RequireContextDeadline,
+		"single.addr":                d.Single.Addr,
+		"sentinel.master_name":       "",
+		"sentinel.sentinel_addrs":    []string{},
+		"sentinel.sentinel_password": "",
+		"cluster.addrs":              []string{},
+	}
+	for key, value := range defaults {
+		v.SetDefault(prefix+key, value)
+	}
+}
+
+// decode 将 viper 中的配置解码到默认配置上，时长支持 "5s" 形式，列表支持逗号分隔的字符串
+func decode(v *viper.Viper) (*Config, error) {
+	cfg := DefaultConfig()
+	err := v.Unmarshal(cfg, func(dc *mapstructure.DecoderConfig) {
+		dc.TagName = "yaml"
+	})
+	if err != nil {
+		return nil, redisxerrors.NewConfigError("config", "decode failed", err)
+	}
+
+	return cfg, nil
+}
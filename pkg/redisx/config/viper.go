@@ -0,0 +1,47 @@
+package config
+
+import (
+	"os"
+	"strings"
+
+	"github.com/spf13/viper"
+	redisxerrors "github.com/tedwangl/go-util/pkg/redisx/errors"
+)
+
+// LoadFromViper 从 viper 的 key 下加载配置，cobrax.Tool 使用全局 viper（viper.GetViper()）
+// key 的值为字符串时作为配置文件路径（支持环境变量展开），否则按内联配置解析，
+// 使用 BindViper 注册默认值后，内联配置的每一项都可以被环境变量覆盖
+func LoadFromViper(v *viper.Viper, key string) (*Config, error) {
+	if path, ok := v.Get(key).(string); ok {
+		return LoadFromFile(os.ExpandEnv(path))
+	}
+
+	// 逐项读取，环境变量的覆盖才会生效
+	sub := viper.New()
+	prefix := key + "."
+	for _, k := range v.AllKeys() {
+		if name, ok := strings.CutPrefix(k, prefix); ok {
+			sub.Set(name, v.Get(k))
+		}
+	}
+	if len(sub.AllKeys()) == 0 {
+		return nil, redisxerrors.NewConfigError(key, "not set", nil)
+	}
+
+	cfg, err := decode(sub)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+
+	return cfg, nil
+}
+
+// BindViper 在 viper 的 key 下注册默认配置，使 <PREFIX>_<KEY>_POOL_SIZE 等环境变量生效，
+// 并在配置输出中显示默认值
+func BindViper(v *viper.Viper, key string) {
+	setDefaults(v, key+".")
+}
@@ -23,6 +23,8 @@ var (
 	ErrClientNotReady = errors.New("redisx: client is not ready")
 	ErrNoAvailableNode = errors.New("redisx: no available redis node")
 	ErrRetryExhausted = errors.New("redisx: retry exhausted")
+	ErrCommandTimeout    = errors.New("redisx: command timeout")
+	ErrContextNoDeadline = errors.New("redisx: context has no deadline")
 )
 
 type ConfigError struct {
@@ -0,0 +1,262 @@
+package hashmap
+
+import (
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// tagName 结构体标签名，与 go-redis 的 HSet/Scan 保持一致
+const tagName = "redis"
+
+// field 结构体字段与哈希字段的映射
+type field struct {
+	name      string // 哈希字段名
+	index     []int
+	omitempty bool
+	version   bool // 版本号字段
+}
+
+// structInfo 结构体的字段映射
+type structInfo struct {
+	fields  []field
+	byName  map[string]*field
+	version *field
+}
+
+var structCache sync.Map // map[reflect.Type]*structInfo
+
+var (
+	textMarshalerType   = reflect.TypeFor[encoding.TextMarshaler]()
+	textUnmarshalerType = reflect.TypeFor[encoding.TextUnmarshaler]()
+	durationType        = reflect.TypeFor[time.Duration]()
+)
+
+// structValue 检查 v 为结构体指针，返回结构体值和字段映射
+func structValue(v any) (reflect.Value, *structInfo, error) {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return reflect.Value{}, nil, fmt.Errorf("hashmap: expected a non-nil pointer to struct, got %T", v)
+	}
+
+	rv = rv.Elem()
+	info, err := getStructInfo(rv.Type())
+	if err != nil {
+		return reflect.Value{}, nil, err
+	}
+	return rv, info, nil
+}
+
+// getStructInfo 解析结构体的字段映射并缓存
+func getStructInfo(t reflect.Type) (*structInfo, error) {
+	if info, ok := structCache.Load(t); ok {
+		return info.(*structInfo), nil
+	}
+
+	info := &structInfo{byName: make(map[string]*field)}
+	if err := collectFields(t, nil, info); err != nil {
+		return nil, err
+	}
+	for i := range info.fields {
+		f := &info.fields[i]
+		info.byName[f.name] = f
+		if f.version {
+			info.version = f
+		}
+	}
+
+	structCache.Store(t, info)
+	return info, nil
+}
+
+// collectFields 收集导出字段，未加标签的匿名结构体字段展开到外层
+func collectFields(t reflect.Type, index []int, info *structInfo) error {
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		tag, hasTag := sf.Tag.Lookup(tagName)
+		if tag == "-" {
+			continue
+		}
+
+		fieldIndex := append(append([]int(nil), index...), i)
+		if sf.Anonymous && !hasTag && sf.Type.Kind() == reflect.Struct {
+			if err := collectFields(sf.Type, fieldIndex, info); err != nil {
+				return err
+			}
+			continue
+		}
+		if !sf.IsExported() {
+			continue
+		}
+
+		name, opts, _ := strings.Cut(tag, ",")
+		if name == "" {
+			name = sf.Name
+		}
+		f := field{name: name, index: fieldIndex}
+		for _, opt := range strings.Split(opts, ",") {
+			switch opt {
+			case "omitempty":
+				f.omitempty = true
+			case "version":
+				f.version = true
+			}
+		}
+
+		if f.version {
+			if hasVersion(info) {
+				return fmt.Errorf("hashmap: %s has more than one version field", t)
+			}
+			switch sf.Type.Kind() {
+			case reflect.Int, reflect.Int32, reflect.Int64:
+			default:
+				return fmt.Errorf("hashmap: version field %s.%s must be an int, int32 or int64", t, sf.Name)
+			}
+		}
+		for _, existing := range info.fields {
+			if existing.name == name {
+				return fmt.Errorf("hashmap: duplicate hash field %q in %s", name, t)
+			}
+		}
+		info.fields = append(info.fields, f)
+	}
+	return nil
+}
+
+// hasVersion 判断已收集的字段中是否有版本号字段
+func hasVersion(info *structInfo) bool {
+	for _, f := range info.fields {
+		if f.version {
+			return true
+		}
+	}
+	return false
+}
+
+// isEmpty 判断字段是否为空值（omitempty 时不写入，nil 指针总是不写入）
+func isEmpty(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		return v.IsNil()
+	case reflect.Slice, reflect.Map:
+		return v.Len() == 0
+	}
+	return v.IsZero()
+}
+
+// encodeValue 将字段值编码为哈希字段的字符串值
+// 基本类型按 go-redis 的格式编码，实现 encoding.TextMarshaler 的类型（如 time.Time）使用文本格式，
+// 其他类型（切片、map、结构体）编码为 JSON
+func encodeValue(v reflect.Value) (string, error) {
+	if v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return "", nil
+		}
+		v = v.Elem()
+	}
+
+	if v.Type().Implements(textMarshalerType) {
+		b, err := v.Interface().(encoding.TextMarshaler).MarshalText()
+		return string(b), err
+	}
+	if v.CanAddr() && v.Addr().Type().Implements(textMarshalerType) {
+		b, err := v.Addr().Interface().(encoding.TextMarshaler).MarshalText()
+		return string(b), err
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		return v.String(), nil
+	case reflect.Bool:
+		if v.Bool() {
+			return "1", nil
+		}
+		return "0", nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(v.Int(), 10), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(v.Uint(), 10), nil
+	case reflect.Float32:
+		return strconv.FormatFloat(v.Float(), 'f', -1, 32), nil
+	case reflect.Float64:
+		return strconv.FormatFloat(v.Float(), 'f', -1, 64), nil
+	case reflect.Slice:
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			return string(v.Bytes()), nil
+		}
+	}
+
+	b, err := json.Marshal(v.Interface())
+	return string(b), err
+}
+
+// decodeValue 将哈希字段的字符串值解码到字段，指针字段会自动分配
+// 非字符串指针字段的空值解码为 nil（兼容之前将 nil 指针保存为空字符串的数据）
+func decodeValue(v reflect.Value, s string) error {
+	if v.Kind() == reflect.Pointer {
+		if s == "" && !isStringKind(v.Type().Elem()) {
+			v.SetZero()
+			return nil
+		}
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		v = v.Elem()
+	}
+
+	if v.Addr().Type().Implements(textUnmarshalerType) {
+		return v.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(s))
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(s)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return err
+		}
+		v.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if v.Type() == durationType {
+			if d, err := time.ParseDuration(s); err == nil {
+				v.SetInt(int64(d))
+				return nil
+			}
+		}
+		n, err := strconv.ParseInt(s, 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(s, 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(s, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetFloat(f)
+	default:
+		if v.Kind() == reflect.Slice && v.Type().Elem().Kind() == reflect.Uint8 {
+			v.SetBytes([]byte(s))
+			return nil
+		}
+		return json.Unmarshal([]byte(s), v.Addr().Interface())
+	}
+	return nil
+}
+
+// isStringKind 判断类型的空字符串是否为有效值（string、[]byte）
+func isStringKind(t reflect.Type) bool {
+	return t.Kind() == reflect.String || (t.Kind() == reflect.Slice && t.Elem().Kind() == reflect.Uint8)
+}
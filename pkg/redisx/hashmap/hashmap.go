@@ -0,0 +1,205 @@
+// Package hashmap 在结构体和 Redis 哈希之间映射，替代手动拼装 HSet 参数
+//
+// 字段映射使用 redis 标签（与 go-redis 一致），未设置标签时使用字段名：
+//
+//	type User struct {
+//	    Name    string    `redis:"name"`
+//	    Email   string    `redis:"email,omitempty"` // 为空时从哈希中删除
+//	    Tags    []string  `redis:"tags"`            // 复合类型编码为 JSON
+//	    Created time.Time `redis:"created"`
+//	    Version int64     `redis:"version,version"` // 乐观锁版本号（可选）
+//	    cache   string    // 未导出字段忽略
+//	}
+package hashmap
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"github.com/tedwangl/go-util/pkg/redisx/client"
+	redisxerrors "github.com/tedwangl/go-util/pkg/redisx/errors"
+)
+
+// ErrVersionConflict 版本号与 Redis 中的不一致（数据已被其他客户端修改）
+var ErrVersionConflict = errors.New("redisx: hash version conflict")
+
+// Option 保存选项
+type Option func(*options)
+
+type options struct {
+	ttl    time.Duration
+	fields []string
+}
+
+// WithTTL 保存后设置过期时间
+func WithTTL(ttl time.Duration) Option {
+	return func(o *options) {
+		o.ttl = ttl
+	}
+}
+
+// WithFields 只保存指定的哈希字段（部分更新）
+func WithFields(fields ...string) Option {
+	return func(o *options) {
+		o.fields = append(o.fields, fields...)
+	}
+}
+
+// Save 将结构体保存到哈希 key，v 必须是结构体指针
+//
+// 带 omitempty 的空字段和值为 nil 的指针字段会从哈希中删除（Load 时保持原值）；结构体有版本号字段时使用 WATCH 检查 Redis 中的版本号
+// 与 v 中的一致，不一致返回 ErrVersionConflict，成功后版本号加一并写回 v
+func Save(ctx context.Context, cli client.Client, key string, v any, opts ...Option) error {
+	o := &options{}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	rv, info, err := structValue(v)
+	if err != nil {
+		return err
+	}
+
+	fields, err := selectFields(info, o.fields)
+	if err != nil {
+		return err
+	}
+
+	var (
+		values []interface{}
+		empty  []string
+	)
+	for _, f := range fields {
+		if f.version {
+			continue
+		}
+		fv, err := rv.FieldByIndexErr(f.index)
+		if err != nil {
+			continue
+		}
+		if (f.omitempty || fv.Kind() == reflect.Pointer) && isEmpty(fv) {
+			empty = append(empty, f.name)
+			continue
+		}
+		s, err := encodeValue(fv)
+		if err != nil {
+			return fmt.Errorf("hashmap: encode field %s failed: %w", f.name, err)
+		}
+		values = append(values, f.name, s)
+	}
+
+	write := func(pipe redis.Pipeliner, version int64) {
+		args := values
+		if info.version != nil {
+			args = append(values[:len(values):len(values)], info.version.name, version)
+		}
+		if len(args) > 0 {
+			pipe.HSet(ctx, key, args...)
+		}
+		if len(empty) > 0 {
+			pipe.HDel(ctx, key, empty...)
+		}
+		if o.ttl > 0 {
+			pipe.Expire(ctx, key, o.ttl)
+		}
+	}
+
+	if info.version == nil {
+		pipe := cli.TxPipeline()
+		write(pipe, 0)
+		if _, err := pipe.Exec(ctx); err != nil {
+			return fmt.Errorf("hashmap: save %s failed: %w", key, err)
+		}
+		return nil
+	}
+	return saveVersioned(ctx, cli, key, rv.FieldByIndex(info.version.index), info.version.name, write)
+}
+
+// saveVersioned 在 WATCH 中检查版本号并写入
+func saveVersioned(ctx context.Context, cli client.Client, key string, versionField reflect.Value, versionName string, write func(pipe redis.Pipeliner, version int64)) error {
+	rdb, ok := cli.GetClient().(redis.UniversalClient)
+	if !ok {
+		return fmt.Errorf("hashmap: versioned save is not supported by %T", cli.GetClient())
+	}
+
+	current := versionField.Int()
+	err := rdb.Watch(ctx, func(tx *redis.Tx) error {
+		stored, err := tx.HGet(ctx, key, versionName).Int64()
+		if err != nil && !errors.Is(err, redis.Nil) {
+			return err
+		}
+		if stored != current {
+			return ErrVersionConflict
+		}
+
+		_, err = tx.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+			write(pipe, current+1)
+			return nil
+		})
+		return err
+	}, key)
+
+	switch {
+	case err == nil:
+		versionField.SetInt(current + 1)
+		return nil
+	case errors.Is(err, ErrVersionConflict), errors.Is(err, redis.TxFailedErr):
+		return fmt.Errorf("%w: %s", ErrVersionConflict, key)
+	default:
+		return fmt.Errorf("hashmap: save %s failed: %w", key, err)
+	}
+}
+
+// Load 从哈希 key 加载到结构体，v 必须是结构体指针，哈希中不存在的字段保持原值
+// key 不存在时返回 redisxerrors.ErrKeyNotFound
+func Load(ctx context.Context, cli client.Client, key string, v any) error {
+	rv, info, err := structValue(v)
+	if err != nil {
+		return err
+	}
+
+	values, err := cli.HGetAll(ctx, key)
+	if err != nil {
+		return fmt.Errorf("hashmap: load %s failed: %w", key, err)
+	}
+	if len(values) == 0 {
+		return redisxerrors.ErrKeyNotFound
+	}
+
+	for _, f := range info.fields {
+		s, ok := values[f.name]
+		if !ok {
+			continue
+		}
+		fv, err := rv.FieldByIndexErr(f.index)
+		if err != nil {
+			continue
+		}
+		if err := decodeValue(fv, s); err != nil {
+			return fmt.Errorf("hashmap: decode field %s failed: %w", f.name, err)
+		}
+	}
+	return nil
+}
+
+// selectFields 返回需要保存的字段，names 为空时返回全部字段
+func selectFields(info *structInfo, names []string) ([]field, error) {
+	if len(names) == 0 {
+		return info.fields, nil
+	}
+
+	fields := make([]field, 0, len(names))
+	for _, name := range names {
+		f, ok := info.byName[name]
+		if !ok {
+			return nil, fmt.Errorf("hashmap: unknown field %q", name)
+		}
+		fields = append(fields, *f)
+	}
+	return fields, nil
+}
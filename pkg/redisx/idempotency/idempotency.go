@@ -0,0 +1,360 @@
+// Package idempotency 基于 redisx 客户端的幂等键存储，用于对重试的请求去重
+//
+// 每个幂等键对应一条记录，首次请求原子地记录为处理中（带租约），完成后保存响应，
+// 重试的请求直接返回保存的响应。处理失败时释放记录，允许重新处理；处理者崩溃时租约到期后自动释放。
+//
+//	resp, replayed, err := store.Do(ctx, key, fingerprint, func(ctx context.Context) ([]byte, error) {
+//	    return chargeCard(ctx, req)
+//	})
+//
+// Temporal Activity 可以使用工作流 ID 和 Activity ID 作为幂等键
+package idempotency
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/tedwangl/go-util/pkg/redisx/client"
+)
+
+var (
+	// ErrInProgress 同一幂等键的请求正在处理
+	ErrInProgress = errors.New("redisx: idempotent request in progress")
+	// ErrFingerprintMismatch 幂等键被用于不同的请求（请求指纹不一致）
+	ErrFingerprintMismatch = errors.New("redisx: idempotency key reused with different request")
+	// ErrLeaseLost 处理中的记录已过期或被其他请求接管
+	ErrLeaseLost = errors.New("redisx: idempotency lease lost")
+)
+
+// State 记录状态
+type State string
+
+const (
+	// StateInProgress 处理中
+	StateInProgress State = "in_progress"
+	// StateCompleted 已完成，保存了响应
+	StateCompleted State = "completed"
+)
+
+// Record 幂等记录
+type Record struct {
+	Key         string
+	State       State
+	Fingerprint string // 请求指纹，用于发现幂等键被用于不同的请求
+	Response    []byte // 已完成时保存的响应
+	CreatedAt   time.Time
+	CompletedAt time.Time
+
+	token string // 处理者的租约标识，Begin 获取记录时生成
+}
+
+// Options 幂等存储选项
+type Options struct {
+	// 键前缀
+	Prefix string
+
+	// 完成后记录的保留时间，期间重试的请求返回保存的响应
+	TTL time.Duration
+
+	// 处理中记录的租约时间，超过后视为处理者已崩溃，其他请求可以重新处理；Do 和 Middleware 会在处理期间自动续期
+	LeaseTTL time.Duration
+}
+
+// DefaultOptions 返回默认幂等存储选项
+func DefaultOptions() *Options {
+	return &Options{
+		Prefix:   "idempotency",
+		TTL:      time.Hour * 24,
+		LeaseTTL: time.Minute,
+	}
+}
+
+// Store 幂等存储
+//
+// 记录保存为哈希 <prefix>:<key>，字段为 state、token、fingerprint、response、created_at、completed_at，
+// 所有状态变更通过 Lua 脚本原子执行
+type Store struct {
+	client client.Client
+	opts   *Options
+}
+
+// beginScript 记录不存在时创建处理中的记录，否则返回已有记录
+// KEYS[1] 记录；ARGV[1] token，ARGV[2] 请求指纹，ARGV[3] 租约毫秒数，ARGV[4] 当前时间（毫秒）
+const beginScript = `
+if redis.call("EXISTS", KEYS[1]) == 0 then
+	redis.call("HSET", KEYS[1], "state", "in_progress", "token", ARGV[1], "fingerprint", ARGV[2], "created_at", ARGV[4])
+	redis.call("PEXPIRE", KEYS[1], ARGV[3])
+	return {1}
+end
+local r = redis.call("HMGET", KEYS[1], "state", "fingerprint", "response", "created_at", "completed_at")
+return {0, r[1], r[2], r[3], r[4], r[5]}
+`
+
+// completeScript 租约有效时保存响应并标记为已完成
+// KEYS[1] 记录；ARGV[1] token，ARGV[2] 响应，ARGV[3] 保留毫秒数，ARGV[4] 当前时间（毫秒）
+const completeScript = `
+local r = redis.call("HMGET", KEYS[1], "state", "token")
+if r[1] ~= "in_progress" or r[2] ~= ARGV[1] then
+	return 0
+end
+redis.call("HSET", KEYS[1], "state", "completed", "response", ARGV[2], "completed_at", ARGV[4])
+redis.call("HDEL", KEYS[1], "token")
+redis.call("PEXPIRE", KEYS[1], ARGV[3])
+return 1
+`
+
+// releaseScript 租约有效时删除处理中的记录
+// KEYS[1] 记录；ARGV[1] token
+const releaseScript = `
+local r = redis.call("HMGET", KEYS[1], "state", "token")
+if r[1] ~= "in_progress" or r[2] ~= ARGV[1] then
+	return 0
+end
+return redis.call("DEL", KEYS[1])
+`
+
+// extendScript 租约有效时续期
+// KEYS[1] 记录；ARGV[1] token，ARGV[2] 租约毫秒数
+const extendScript = `
+local r = redis.call("HMGET", KEYS[1], "state", "token")
+if r[1] ~= "in_progress" or r[2] ~= ARGV[1] then
+	return 0
+end
+return redis.call("PEXPIRE", KEYS[1], ARGV[2])
+`
+
+// NewStore 创建幂等存储，opts 为 nil 时使用默认选项
+func NewStore(cli client.Client, opts *Options) *Store {
+	if opts == nil {
+		opts = DefaultOptions()
+	}
+	if opts.Prefix == "" {
+		opts.Prefix = "idempotency"
+	}
+	if opts.TTL <= 0 {
+		opts.TTL = DefaultOptions().TTL
+	}
+	if opts.LeaseTTL <= 0 {
+		opts.LeaseTTL = DefaultOptions().LeaseTTL
+	}
+
+	return &Store{
+		client: cli,
+		opts:   opts,
+	}
+}
+
+// Begin 开始处理幂等键 key 对应的请求
+//
+// 首次请求时记录为处理中并返回 acquired=true，处理完成后调用 Complete，失败时调用 Release；
+// 请求已完成时返回保存的记录（acquired=false），用 Record.Response 重放响应；
+// 其他请求正在处理时返回 ErrInProgress。fingerprint 不为空且与首次请求不一致时返回 ErrFingerprintMismatch
+func (s *Store) Begin(ctx context.Context, key, fingerprint string) (rec *Record, acquired bool, err error) {
+	token, err := newToken()
+	if err != nil {
+		return nil, false, err
+	}
+
+	now := time.Now()
+	result, err := s.client.Eval(ctx, beginScript, []string{s.key(key)},
+		token, fingerprint, s.opts.LeaseTTL.Milliseconds(), now.UnixMilli()).Slice()
+	if err != nil {
+		return nil, false, fmt.Errorf("begin idempotent request failed: %w", err)
+	}
+
+	if created, _ := result[0].(int64); created == 1 {
+		return &Record{
+			Key:         key,
+			State:       StateInProgress,
+			Fingerprint: fingerprint,
+			CreatedAt:   now,
+			token:       token,
+		}, true, nil
+	}
+
+	fields := make([]string, 5)
+	for i := range fields {
+		if i+1 < len(result) {
+			fields[i], _ = result[i+1].(string)
+		}
+	}
+	rec = &Record{
+		Key:         key,
+		State:       State(fields[0]),
+		Fingerprint: fields[1],
+		Response:    []byte(fields[2]),
+		CreatedAt:   parseMillis(fields[3]),
+		CompletedAt: parseMillis(fields[4]),
+	}
+
+	switch {
+	case fingerprint != "" && rec.Fingerprint != "" && fingerprint != rec.Fingerprint:
+		return rec, false, ErrFingerprintMismatch
+	case rec.State != StateCompleted:
+		return rec, false, ErrInProgress
+	}
+	return rec, false, nil
+}
+
+// Complete 保存响应并标记为已完成，记录保留 Options.TTL；租约已失效时返回 ErrLeaseLost
+func (s *Store) Complete(ctx context.Context, rec *Record, response []byte) error {
+	now := time.Now()
+	ok, err := s.client.Eval(ctx, completeScript, []string{s.key(rec.Key)},
+		rec.token, response, s.opts.TTL.Milliseconds(), now.UnixMilli()).Int64()
+	if err != nil {
+		return fmt.Errorf("complete idempotent request failed: %w", err)
+	}
+	if ok == 0 {
+		return ErrLeaseLost
+	}
+
+	rec.State = StateCompleted
+	rec.Response = response
+	rec.CompletedAt = now
+	return nil
+}
+
+// Release 删除处理中的记录（处理失败时调用），之后相同幂等键的请求可以重新处理；租约已失效时返回 ErrLeaseLost
+func (s *Store) Release(ctx context.Context, rec *Record) error {
+	ok, err := s.client.Eval(ctx, releaseScript, []string{s.key(rec.Key)}, rec.token).Int64()
+	if err != nil {
+		return fmt.Errorf("release idempotent request failed: %w", err)
+	}
+	if ok == 0 {
+		return ErrLeaseLost
+	}
+	return nil
+}
+
+// Extend 将处理中记录的租约延长为 Options.LeaseTTL；租约已失效时返回 ErrLeaseLost
+func (s *Store) Extend(ctx context.Context, rec *Record) error {
+	ok, err := s.client.Eval(ctx, extendScript, []string{s.key(rec.Key)},
+		rec.token, s.opts.LeaseTTL.Milliseconds()).Int64()
+	if err != nil {
+		return fmt.Errorf("extend idempotent request failed: %w", err)
+	}
+	if ok == 0 {
+		return ErrLeaseLost
+	}
+	return nil
+}
+
+// Get 获取幂等记录，不存在时返回 nil
+func (s *Store) Get(ctx context.Context, key string) (*Record, error) {
+	values, err := s.client.HGetAll(ctx, s.key(key))
+	if err != nil {
+		return nil, fmt.Errorf("get idempotent record failed: %w", err)
+	}
+	if len(values) == 0 {
+		return nil, nil
+	}
+
+	return &Record{
+		Key:         key,
+		State:       State(values["state"]),
+		Fingerprint: values["fingerprint"],
+		Response:    []byte(values["response"]),
+		CreatedAt:   parseMillis(values["created_at"]),
+		CompletedAt: parseMillis(values["completed_at"]),
+	}, nil
+}
+
+// Delete 删除幂等记录（不检查状态）
+func (s *Store) Delete(ctx context.Context, key string) error {
+	return s.client.Del(ctx, s.key(key)).Err()
+}
+
+// Do 按幂等键执行 fn，返回 fn 的响应或之前保存的响应（replayed=true）
+//
+// fn 执行期间每 LeaseTTL/3 续期一次，fn 返回错误时释放记录并返回该错误，允许重试；
+// 其他请求正在处理时返回 ErrInProgress
+func (s *Store) Do(ctx context.Context, key, fingerprint string, fn func(ctx context.Context) ([]byte, error)) (response []byte, replayed bool, err error) {
+	rec, acquired, err := s.Begin(ctx, key, fingerprint)
+	if err != nil {
+		return nil, false, err
+	}
+	if !acquired {
+		return rec.Response, true, nil
+	}
+
+	response, err = s.run(ctx, rec, fn)
+	if err != nil {
+		// 释放失败时等待租约到期
+		s.Release(context.WithoutCancel(ctx), rec)
+		return nil, false, err
+	}
+
+	if err := s.Complete(context.WithoutCancel(ctx), rec, response); err != nil {
+		return response, false, err
+	}
+	return response, false, nil
+}
+
+// run 执行 fn 并定期续期租约，续期失败（租约丢失）时取消 fn 的 context
+func (s *Store) run(ctx context.Context, rec *Record, fn func(ctx context.Context) ([]byte, error)) ([]byte, error) {
+	ctx, stop := s.keepAlive(ctx, rec)
+	defer stop()
+
+	response, err := fn(ctx)
+	if err == nil && errors.Is(context.Cause(ctx), ErrLeaseLost) {
+		err = ErrLeaseLost
+	}
+	return response, err
+}
+
+// keepAlive 每 LeaseTTL/3 续期一次租约直到调用 stop，租约丢失时以 ErrLeaseLost 取消返回的 context
+func (s *Store) keepAlive(ctx context.Context, rec *Record) (context.Context, func()) {
+	ctx, cancel := context.WithCancelCause(ctx)
+	// 请求被取消后处理可能仍在进行，续期不受其影响
+	extendCtx := context.WithoutCancel(ctx)
+
+	done := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(s.opts.LeaseTTL / 3)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				if err := s.Extend(extendCtx, rec); errors.Is(err, ErrLeaseLost) {
+					cancel(err)
+					return
+				}
+			}
+		}
+	}()
+
+	return ctx, func() {
+		close(done)
+		cancel(nil)
+	}
+}
+
+// key 生成记录的键
+func (s *Store) key(key string) string {
+	return fmt.Sprintf("%s:%s", s.opts.Prefix, key)
+}
+
+// newToken 生成租约标识
+func newToken() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("generate idempotency token failed: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// parseMillis 解析毫秒时间戳，为空时返回零值
+func parseMillis(s string) time.Time {
+	ms, err := strconv.ParseInt(s, 10, 64)
+	if err != nil || ms == 0 {
+		return time.Time{}
+	}
+	return time.UnixMilli(ms)
+}
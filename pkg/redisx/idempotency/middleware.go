@@ -0,0 +1,146 @@
+package idempotency
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+)
+
+// HeaderIdempotencyKey 默认的幂等键请求头
+const HeaderIdempotencyKey = "Idempotency-Key"
+
+// storedResponse 保存的 HTTP 响应
+type storedResponse struct {
+	Status int         `json:"status"`
+	Header http.Header `json:"header,omitempty"`
+	Body   []byte      `json:"body,omitempty"`
+}
+
+// Middleware 按请求头 header（为空时使用 Idempotency-Key）中的幂等键对请求去重
+//
+// 没有幂等键的请求直接处理；首次请求的响应被保存，重试的请求重放保存的响应并设置 Idempotent-Replayed: true。
+// 处理期间自动续期租约，同一幂等键的请求正在处理时返回 409，幂等键被用于不同的请求（方法、路径或请求体不同）时返回 422；
+// 处理器返回 5xx 时不保存响应，允许重试
+func (s *Store) Middleware(header string) func(http.Handler) http.Handler {
+	if header == "" {
+		header = HeaderIdempotencyKey
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			key := r.Header.Get(header)
+			if key == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			body, err := io.ReadAll(r.Body)
+			if err != nil {
+				http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+				return
+			}
+			r.Body = io.NopCloser(bytes.NewReader(body))
+
+			rec, acquired, err := s.Begin(r.Context(), key, fingerprint(r, body))
+			switch {
+			case errors.Is(err, ErrInProgress):
+				http.Error(w, "request with the same idempotency key is in progress", http.StatusConflict)
+				return
+			case errors.Is(err, ErrFingerprintMismatch):
+				http.Error(w, "idempotency key reused with different request", http.StatusUnprocessableEntity)
+				return
+			case err != nil:
+				http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+				return
+			}
+
+			if !acquired {
+				replay(w, rec.Response)
+				return
+			}
+
+			// 处理期间续期租约，避免处理较慢时被重试的请求重复执行
+			leaseCtx, stop := s.keepAlive(r.Context(), rec)
+			rw := &recorder{ResponseWriter: w, status: http.StatusOK}
+			defer func() {
+				stop()
+
+				// 客户端断开连接（通常随后会重试）时仍需保存响应或释放记录
+				ctx := context.WithoutCancel(r.Context())
+
+				// 处理器 panic 或返回 5xx 时释放记录，允许重试
+				if p := recover(); p != nil {
+					s.Release(ctx, rec)
+					panic(p)
+				}
+				if rw.status >= http.StatusInternalServerError {
+					s.Release(ctx, rec)
+					return
+				}
+
+				data, err := json.Marshal(storedResponse{Status: rw.status, Header: w.Header().Clone(), Body: rw.body.Bytes()})
+				if err != nil {
+					s.Release(ctx, rec)
+					return
+				}
+				// 保存失败时等待租约到期
+				s.Complete(ctx, rec, data)
+			}()
+			next.ServeHTTP(rw, r.WithContext(leaseCtx))
+		})
+	}
+}
+
+// fingerprint 计算请求指纹（方法、路径和请求体的 SHA-256）
+func fingerprint(r *http.Request, body []byte) string {
+	h := sha256.New()
+	h.Write([]byte(r.Method))
+	h.Write([]byte{0})
+	h.Write([]byte(r.URL.RequestURI()))
+	h.Write([]byte{0})
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// replay 重放保存的响应
+func replay(w http.ResponseWriter, data []byte) {
+	var resp storedResponse
+	if err := json.Unmarshal(data, &resp); err != nil {
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	for k, v := range resp.Header {
+		w.Header()[k] = v
+	}
+	w.Header().Set("Idempotent-Replayed", "true")
+	w.WriteHeader(resp.Status)
+	w.Write(resp.Body)
+}
+
+// recorder 记录响应状态码和响应体，同时写入原始 ResponseWriter
+type recorder struct {
+	http.ResponseWriter
+	status      int
+	body        bytes.Buffer
+	wroteHeader bool
+}
+
+func (r *recorder) WriteHeader(status int) {
+	if !r.wroteHeader {
+		r.status = status
+		r.wroteHeader = true
+	}
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *recorder) Write(b []byte) (int, error) {
+	r.wroteHeader = true
+	r.body.Write(b)
+	return r.ResponseWriter.Write(b)
+}
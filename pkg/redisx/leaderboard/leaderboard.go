@@ -0,0 +1,361 @@
+// Package leaderboard 基于有序集合的排行榜，支持累加分数、排名查询、TopN（附带成员数据）、
+// 查询成员前后的排名和按天、按周自动重置
+//
+//	board := leaderboard.New(cli, "game", &leaderboard.Options{Reset: leaderboard.ResetDaily})
+//	board.IncrScore(ctx, "user:1", 10)
+//	board.SetPayload(ctx, "user:1", map[string]string{"name": "张三"})
+//	top, _ := board.TopN(ctx, 10)
+//	around, _ := board.Around(ctx, "user:1", 5)
+//	yesterday, _ := board.At(time.Now().AddDate(0, 0, -1)).TopN(ctx, 10)
+package leaderboard
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"github.com/tedwangl/go-util/pkg/redisx/client"
+)
+
+// ErrMemberNotFound 成员不在排行榜中
+var ErrMemberNotFound = errors.New("redisx: leaderboard member not found")
+
+// ResetPolicy 排行榜重置周期
+type ResetPolicy int
+
+const (
+	// ResetNever 不重置
+	ResetNever ResetPolicy = iota
+	// ResetDaily 每天 0 点重置
+	ResetDaily
+	// ResetWeekly 每周一 0 点重置（WeekStartsSunday 时为周日）
+	ResetWeekly
+)
+
+// Options 排行榜选项
+type Options struct {
+	// 键前缀
+	Prefix string
+
+	// 重置周期，每个周期使用独立的有序集合
+	Reset ResetPolicy
+
+	// 计算周期使用的时区，默认 time.Local
+	Location *time.Location
+
+	// 每周从周日开始（ResetWeekly 时使用），默认周一
+	WeekStartsSunday bool
+
+	// 周期结束后保留的时间，用于查询往期排行（At），默认保留一个周期
+	Retention time.Duration
+
+	// 分数低的排名靠前（如用时），默认分数高的靠前
+	Ascending bool
+}
+
+// Entry 排行榜条目
+type Entry struct {
+	Member  string          `json:"member"`
+	Score   float64         `json:"score"`
+	Rank    int64           `json:"rank"`              // 排名，从 1 开始
+	Payload json.RawMessage `json:"payload,omitempty"` // SetPayload 保存的成员数据
+}
+
+// Board 排行榜
+//
+// 分数保存在有序集合 <prefix>:<name>（按周期重置时为 <prefix>:<name>:<周期开始日期>），
+// 成员数据保存在哈希 <prefix>:<name>:payload 中，所有周期共用。分数相同时按成员名排序
+type Board struct {
+	client client.Client
+	name   string
+	opts   *Options
+	at     time.Time // 查询的时间点，为零时使用当前周期
+}
+
+// DefaultOptions 返回默认排行榜选项
+func DefaultOptions() *Options {
+	return &Options{
+		Prefix: "leaderboard",
+	}
+}
+
+// New 创建排行榜，opts 为 nil 时使用默认选项
+func New(cli client.Client, name string, opts *Options) *Board {
+	if opts == nil {
+		opts = DefaultOptions()
+	}
+	if opts.Prefix == "" {
+		opts.Prefix = "leaderboard"
+	}
+	if opts.Location == nil {
+		opts.Location = time.Local
+	}
+
+	return &Board{
+		client: cli,
+		name:   name,
+		opts:   opts,
+	}
+}
+
+// At 返回时间 t 所在周期的排行榜，用于查询往期排行（不重置的排行榜返回自身）
+func (b *Board) At(t time.Time) *Board {
+	if b.opts.Reset == ResetNever {
+		return b
+	}
+
+	board := *b
+	board.at = t
+	return &board
+}
+
+// AddScore 设置成员的分数（覆盖原有分数）
+func (b *Board) AddScore(ctx context.Context, member string, score float64) error {
+	key, expireAt := b.period()
+	pipe := b.client.TxPipeline()
+	pipe.ZAdd(ctx, key, redis.Z{Score: score, Member: member})
+	b.expire(ctx, pipe, key, expireAt)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("leaderboard: add score failed: %w", err)
+	}
+	return nil
+}
+
+// IncrScore 累加成员的分数（成员不存在时从 0 开始），返回新的分数
+func (b *Board) IncrScore(ctx context.Context, member string, delta float64) (float64, error) {
+	key, expireAt := b.period()
+	pipe := b.client.TxPipeline()
+	cmd := pipe.ZIncrBy(ctx, key, delta, member)
+	b.expire(ctx, pipe, key, expireAt)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return 0, fmt.Errorf("leaderboard: incr score failed: %w", err)
+	}
+	return cmd.Val(), nil
+}
+
+// Remove 从当前周期的排行榜中删除成员（成员数据保留）
+func (b *Board) Remove(ctx context.Context, members ...string) error {
+	if len(members) == 0 {
+		return nil
+	}
+
+	key, _ := b.period()
+	args := make([]interface{}, len(members))
+	for i, m := range members {
+		args[i] = m
+	}
+	return b.client.ZRem(ctx, key, args...).Err()
+}
+
+// Reset 清空当前周期的排行榜
+func (b *Board) Reset(ctx context.Context) error {
+	key, _ := b.period()
+	return b.client.Del(ctx, key).Err()
+}
+
+// SetPayload 保存成员数据（编码为 JSON），TopN、Around 等查询时一起返回
+func (b *Board) SetPayload(ctx context.Context, member string, payload any) error {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("leaderboard: encode payload failed: %w", err)
+	}
+	return b.client.HSet(ctx, b.payloadKey(), member, data).Err()
+}
+
+// DeletePayload 删除成员数据
+func (b *Board) DeletePayload(ctx context.Context, members ...string) error {
+	if len(members) == 0 {
+		return nil
+	}
+	return b.client.HDel(ctx, b.payloadKey(), members...).Err()
+}
+
+// Score 获取成员的分数，成员不存在时返回 ErrMemberNotFound
+func (b *Board) Score(ctx context.Context, member string) (float64, error) {
+	key, _ := b.period()
+	score, err := b.client.ZScore(ctx, key, member).Result()
+	if errors.Is(err, redis.Nil) {
+		return 0, ErrMemberNotFound
+	}
+	return score, err
+}
+
+// Rank 获取成员的排名（从 1 开始），成员不存在时返回 ErrMemberNotFound
+func (b *Board) Rank(ctx context.Context, member string) (int64, error) {
+	key, _ := b.period()
+	pipe := b.client.Pipeline()
+	cmd := b.rank(ctx, pipe, key, member)
+	if _, err := pipe.Exec(ctx); err != nil {
+		if errors.Is(err, redis.Nil) {
+			return 0, ErrMemberNotFound
+		}
+		return 0, err
+	}
+	return cmd.Val() + 1, nil
+}
+
+// Get 获取成员的分数、排名和成员数据，成员不存在时返回 ErrMemberNotFound
+func (b *Board) Get(ctx context.Context, member string) (*Entry, error) {
+	key, _ := b.period()
+	pipe := b.client.Pipeline()
+	rank := b.rank(ctx, pipe, key, member)
+	score := pipe.ZScore(ctx, key, member)
+	payload := pipe.HGet(ctx, b.payloadKey(), member)
+	if _, err := pipe.Exec(ctx); err != nil && !errors.Is(err, redis.Nil) {
+		return nil, err
+	}
+	if rank.Err() != nil {
+		return nil, ErrMemberNotFound
+	}
+
+	entry := &Entry{Member: member, Score: score.Val(), Rank: rank.Val() + 1}
+	if data := payload.Val(); data != "" {
+		entry.Payload = json.RawMessage(data)
+	}
+	return entry, nil
+}
+
+// Count 获取排行榜的成员数
+func (b *Board) Count(ctx context.Context) (int64, error) {
+	key, _ := b.period()
+	pipe := b.client.Pipeline()
+	cmd := pipe.ZCard(ctx, key)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return 0, err
+	}
+	return cmd.Val(), nil
+}
+
+// TopN 获取前 n 名
+func (b *Board) TopN(ctx context.Context, n int64) ([]Entry, error) {
+	if n <= 0 {
+		return nil, nil
+	}
+	return b.Range(ctx, 0, n)
+}
+
+// Range 分页获取排行，offset 从 0 开始
+func (b *Board) Range(ctx context.Context, offset, limit int64) ([]Entry, error) {
+	if offset < 0 || limit <= 0 {
+		return nil, nil
+	}
+
+	key, _ := b.period()
+	return b.fetch(ctx, key, offset, offset+limit-1)
+}
+
+// Around 获取成员及其前后各 n 名（靠近榜首或榜尾时另一侧不补足），成员不存在时返回 ErrMemberNotFound
+func (b *Board) Around(ctx context.Context, member string, n int64) ([]Entry, error) {
+	if n < 0 {
+		n = 0
+	}
+
+	key, _ := b.period()
+	pipe := b.client.Pipeline()
+	cmd := b.rank(ctx, pipe, key, member)
+	if _, err := pipe.Exec(ctx); err != nil {
+		if errors.Is(err, redis.Nil) {
+			return nil, ErrMemberNotFound
+		}
+		return nil, err
+	}
+
+	rank := cmd.Val()
+	return b.fetch(ctx, key, max(rank-n, 0), rank+n)
+}
+
+// fetch 获取排名 [start, stop]（从 0 开始）的条目并加载成员数据
+func (b *Board) fetch(ctx context.Context, key string, start, stop int64) ([]Entry, error) {
+	pipe := b.client.Pipeline()
+	var cmd *redis.ZSliceCmd
+	if b.opts.Ascending {
+		cmd = pipe.ZRangeWithScores(ctx, key, start, stop)
+	} else {
+		cmd = pipe.ZRevRangeWithScores(ctx, key, start, stop)
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		return nil, fmt.Errorf("leaderboard: range failed: %w", err)
+	}
+
+	zs := cmd.Val()
+	if len(zs) == 0 {
+		return nil, nil
+	}
+	entries := make([]Entry, len(zs))
+	members := make([]string, len(zs))
+	for i, z := range zs {
+		member, _ := z.Member.(string)
+		members[i] = member
+		entries[i] = Entry{Member: member, Score: z.Score, Rank: start + int64(i) + 1}
+	}
+
+	pipe = b.client.Pipeline()
+	payloads := pipe.HMGet(ctx, b.payloadKey(), members...)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return nil, fmt.Errorf("leaderboard: load payload failed: %w", err)
+	}
+	for i, v := range payloads.Val() {
+		if data, ok := v.(string); ok {
+			entries[i].Payload = json.RawMessage(data)
+		}
+	}
+	return entries, nil
+}
+
+// rank 在管道中查询成员排名（从 0 开始）
+func (b *Board) rank(ctx context.Context, pipe redis.Pipeliner, key, member string) *redis.IntCmd {
+	if b.opts.Ascending {
+		return pipe.ZRank(ctx, key, member)
+	}
+	return pipe.ZRevRank(ctx, key, member)
+}
+
+// expire 为周期排行榜设置过期时间
+func (b *Board) expire(ctx context.Context, pipe redis.Pipeliner, key string, expireAt time.Time) {
+	if !expireAt.IsZero() {
+		pipe.ExpireAt(ctx, key, expireAt)
+	}
+}
+
+// period 返回当前（或 At 指定时间）周期的键和过期时间，不重置时过期时间为零
+func (b *Board) period() (string, time.Time) {
+	base := fmt.Sprintf("%s:%s", b.opts.Prefix, b.name)
+	if b.opts.Reset == ResetNever {
+		return base, time.Time{}
+	}
+
+	t := b.at
+	if t.IsZero() {
+		t = time.Now()
+	}
+	t = t.In(b.opts.Location)
+	start := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, b.opts.Location)
+
+	var end time.Time
+	switch b.opts.Reset {
+	case ResetWeekly:
+		weekStart := time.Monday
+		if b.opts.WeekStartsSunday {
+			weekStart = time.Sunday
+		}
+		start = start.AddDate(0, 0, -((int(start.Weekday()) - int(weekStart) + 7) % 7))
+		end = start.AddDate(0, 0, 7)
+	default:
+		end = start.AddDate(0, 0, 1)
+	}
+
+	retention := b.opts.Retention
+	if retention <= 0 {
+		retention = end.Sub(start)
+	}
+	return fmt.Sprintf("%s:%s", base, start.Format("20060102")), end.Add(retention)
+}
+
+// payloadKey 成员数据的键
+func (b *Board) payloadKey() string {
+	return fmt.Sprintf("%s:%s:payload", b.opts.Prefix, b.name)
+}
@@ -0,0 +1,517 @@
+// Package migrate 在 Redis 实例或集群之间复制键
+//
+// 按模式 SCAN 源端的键（集群模式遍历所有主节点），用 DUMP/RESTORE 或按类型读写复制到目标端，
+// 保留过期时间，支持限速和进度回调；Verify 只比较两端的数据，不写入：
+//
+//	stats, err := migrate.Copy(ctx, src, dst,
+//	    migrate.WithPattern("user:*"),
+//	    migrate.WithRate(2000),
+//	    migrate.WithProgress(func(s migrate.Stats) { log.Printf("%d/%d", s.Copied, s.Scanned) }, time.Second),
+//	)
+package migrate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"github.com/tedwangl/go-util/pkg/redisx/client"
+	"github.com/tedwangl/go-util/pkg/utils/limitx"
+)
+
+// Method 复制方式
+type Method string
+
+const (
+	// MethodDump 使用 DUMP/RESTORE 复制（默认，要求目标端能识别源端的 RDB 格式）
+	MethodDump Method = "dump"
+	// MethodCommand 按类型读取后写入（跨版本迁移时使用，大键会一次读入内存）
+	MethodCommand Method = "command"
+)
+
+const (
+	defaultBatchSize = 500
+	// maxFailures Stats.Failures 保留的最大失败记录数
+	maxFailures = 100
+)
+
+// ErrUnsupportedClient 客户端没有统一的底层客户端（multi-master 模式）
+var ErrUnsupportedClient = errors.New("migrate: unsupported client")
+
+type (
+	// Stats 迁移或校验的统计
+	Stats struct {
+		Scanned    int64         `json:"scanned"`
+		Copied     int64         `json:"copied"`
+		Skipped    int64         `json:"skipped"`              // 目标端已存在（未设置 WithReplace）或扫描后已过期
+		Failed     int64         `json:"failed"`               // 复制失败
+		Matched    int64         `json:"matched,omitempty"`    // 校验一致
+		Missing    int64         `json:"missing,omitempty"`    // 校验时目标端不存在
+		Mismatched int64         `json:"mismatched,omitempty"` // 校验时类型、值或过期时间不一致
+		Elapsed    time.Duration `json:"elapsed"`
+		Failures   []Failure     `json:"failures,omitempty"` // 失败和不一致的键（最多 100 个）
+	}
+
+	// Failure 失败或不一致的键
+	Failure struct {
+		Key   string `json:"key"`
+		Error string `json:"error"`
+	}
+
+	// Option 迁移选项
+	Option func(*options)
+
+	options struct {
+		pattern          string
+		keyType          string
+		method           Method
+		replace          bool
+		rate             float64
+		batchSize        int
+		progress         func(Stats)
+		progressInterval time.Duration
+	}
+)
+
+// WithPattern 设置 SCAN 的匹配模式（默认 *）
+func WithPattern(pattern string) Option {
+	return func(o *options) {
+		o.pattern = pattern
+	}
+}
+
+// WithType 只迁移指定类型的键（string/hash/list/set/zset/stream）
+func WithType(keyType string) Option {
+	return func(o *options) {
+		o.keyType = keyType
+	}
+}
+
+// WithMethod 设置复制方式（默认 MethodDump）
+func WithMethod(method Method) Option {
+	return func(o *options) {
+		o.method = method
+	}
+}
+
+// WithReplace 覆盖目标端已存在的键（默认跳过）
+func WithReplace() Option {
+	return func(o *options) {
+		o.replace = true
+	}
+}
+
+// WithRate 限制每秒迁移（或校验）的键数量（0 为不限制）
+func WithRate(keysPerSecond float64) Option {
+	return func(o *options) {
+		o.rate = keysPerSecond
+	}
+}
+
+// WithBatchSize 设置每次 SCAN 的数量和管道大小（默认 500）
+func WithBatchSize(size int) Option {
+	return func(o *options) {
+		o.batchSize = size
+	}
+}
+
+// WithProgress 每隔 interval 回调一次当前统计（结束时再回调一次），interval 为 0 时每秒一次
+func WithProgress(fn func(Stats), interval time.Duration) Option {
+	return func(o *options) {
+		o.progress = fn
+		o.progressInterval = interval
+	}
+}
+
+// Copy 将 src 中匹配的键复制到 dst，保留过期时间
+//
+// 单个键复制失败不会中断迁移（记录在 Stats 中），SCAN 失败或 ctx 取消时返回错误和已完成部分的统计
+func Copy(ctx context.Context, src, dst client.Client, opts ...Option) (*Stats, error) {
+	return run(ctx, src, dst, false, opts)
+}
+
+// Verify 校验 src 中匹配的键在 dst 中存在且类型、值一致（源端有过期时间时要求目标端也有），不写入数据
+func Verify(ctx context.Context, src, dst client.Client, opts ...Option) (*Stats, error) {
+	return run(ctx, src, dst, true, opts)
+}
+
+// migrator 一次迁移或校验
+type migrator struct {
+	opts    *options
+	dst     redis.UniversalClient
+	limiter limitx.Limiter
+	verify  bool
+
+	scanned, copied, skipped, failed atomic.Int64
+	matched, missing, mismatched     atomic.Int64
+	failuresMu                       sync.Mutex
+	failures                         []Failure
+	start                            time.Time
+}
+
+func run(ctx context.Context, src, dst client.Client, verify bool, opts []Option) (*Stats, error) {
+	o := &options{pattern: "*", method: MethodDump, batchSize: defaultBatchSize}
+	for _, opt := range opts {
+		opt(o)
+	}
+	if o.method != MethodDump && o.method != MethodCommand {
+		return nil, fmt.Errorf("migrate: unsupported method: %s", o.method)
+	}
+	if o.batchSize <= 0 {
+		o.batchSize = defaultBatchSize
+	}
+
+	srcRdb, err := universal(src)
+	if err != nil {
+		return nil, err
+	}
+	dstRdb, err := universal(dst)
+	if err != nil {
+		return nil, err
+	}
+
+	m := &migrator{opts: o, dst: dstRdb, verify: verify, start: time.Now()}
+	if o.rate > 0 {
+		m.limiter = limitx.NewTokenBucketLimiter(limitx.Config{Rate: o.rate, Burst: max(int(o.rate), 1)})
+	}
+
+	if o.progress != nil {
+		interval := o.progressInterval
+		if interval <= 0 {
+			interval = time.Second
+		}
+		done := make(chan struct{})
+		defer close(done)
+		go func() {
+			ticker := time.NewTicker(interval)
+			defer ticker.Stop()
+			for {
+				select {
+				case <-done:
+					return
+				case <-ticker.C:
+					o.progress(*m.stats())
+				}
+			}
+		}()
+	}
+
+	err = forEachNode(ctx, srcRdb, m.migrateNode)
+	stats := m.stats()
+	if o.progress != nil {
+		o.progress(*stats)
+	}
+	return stats, err
+}
+
+// universal 返回客户端的底层 go-redis 客户端
+func universal(c client.Client) (redis.UniversalClient, error) {
+	rdb, ok := c.GetClient().(redis.UniversalClient)
+	if !ok {
+		return nil, fmt.Errorf("%w: %T", ErrUnsupportedClient, c.GetClient())
+	}
+	return rdb, nil
+}
+
+// forEachNode 在每个节点上执行（集群模式为所有主节点）
+func forEachNode(ctx context.Context, rdb redis.UniversalClient, fn func(ctx context.Context, node *redis.Client) error) error {
+	switch c := rdb.(type) {
+	case *redis.ClusterClient:
+		return c.ForEachMaster(ctx, fn)
+	case *redis.Client:
+		return fn(ctx, c)
+	default:
+		return fmt.Errorf("%w: %T", ErrUnsupportedClient, rdb)
+	}
+}
+
+// migrateNode 扫描一个节点并逐批处理
+func (m *migrator) migrateNode(ctx context.Context, node *redis.Client) error {
+	var cursor uint64
+	for {
+		var (
+			keys []string
+			err  error
+		)
+		if m.opts.keyType != "" {
+			keys, cursor, err = node.ScanType(ctx, cursor, m.opts.pattern, int64(m.opts.batchSize), m.opts.keyType).Result()
+		} else {
+			keys, cursor, err = node.Scan(ctx, cursor, m.opts.pattern, int64(m.opts.batchSize)).Result()
+		}
+		if err != nil {
+			return fmt.Errorf("migrate: %s: scan failed: %w", node.Options().Addr, err)
+		}
+
+		if len(keys) > 0 {
+			if err := m.wait(ctx, len(keys)); err != nil {
+				return err
+			}
+			m.scanned.Add(int64(len(keys)))
+
+			switch {
+			case m.verify:
+				err = m.verifyBatch(ctx, node, keys)
+			case m.opts.method == MethodDump:
+				err = m.dumpBatch(ctx, node, keys)
+			default:
+				err = m.commandBatch(ctx, node, keys)
+			}
+			if err != nil {
+				return err
+			}
+		}
+
+		if cursor == 0 {
+			return nil
+		}
+	}
+}
+
+// wait 按限速等待 n 个键的配额
+func (m *migrator) wait(ctx context.Context, n int) error {
+	if m.limiter == nil {
+		return nil
+	}
+	for i := 0; i < n; i++ {
+		if !m.limiter.Wait(ctx) {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			return errors.New("migrate: rate limiter wait failed")
+		}
+	}
+	return nil
+}
+
+// dumpBatch 用 DUMP/RESTORE 复制一批键
+func (m *migrator) dumpBatch(ctx context.Context, node *redis.Client, keys []string) error {
+	pipe := node.Pipeline()
+	ttlCmds := make([]*redis.DurationCmd, len(keys))
+	dumpCmds := make([]*redis.StringCmd, len(keys))
+	for i, key := range keys {
+		ttlCmds[i] = pipe.PTTL(ctx, key)
+		dumpCmds[i] = pipe.Dump(ctx, key)
+	}
+	if _, err := pipe.Exec(ctx); err != nil && !errors.Is(err, redis.Nil) {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		// 单个命令的错误在下面逐个处理
+	}
+
+	dstPipe := m.dst.Pipeline()
+	restoreCmds := make([]*redis.StatusCmd, len(keys))
+	for i, key := range keys {
+		data, err := dumpCmds[i].Result()
+		if errors.Is(err, redis.Nil) {
+			m.skipped.Add(1) // 扫描后已删除或过期
+			continue
+		}
+		if err != nil {
+			m.fail(key, fmt.Errorf("dump: %w", err))
+			continue
+		}
+		ttl, ok := restoreTTL(ttlCmds[i].Val())
+		if !ok {
+			m.skipped.Add(1)
+			continue
+		}
+		if m.opts.replace {
+			restoreCmds[i] = dstPipe.RestoreReplace(ctx, key, ttl, data)
+		} else {
+			restoreCmds[i] = dstPipe.Restore(ctx, key, ttl, data)
+		}
+	}
+	if _, err := dstPipe.Exec(ctx); err != nil && ctx.Err() != nil {
+		return ctx.Err()
+	}
+
+	for i, cmd := range restoreCmds {
+		if cmd == nil {
+			continue
+		}
+		switch err := cmd.Err(); {
+		case err == nil:
+			m.copied.Add(1)
+		case strings.HasPrefix(err.Error(), "BUSYKEY"):
+			m.skipped.Add(1)
+		default:
+			m.fail(keys[i], fmt.Errorf("restore: %w", err))
+		}
+	}
+	return nil
+}
+
+// commandBatch 按类型读取后写入一批键
+func (m *migrator) commandBatch(ctx context.Context, node *redis.Client, keys []string) error {
+	types, ttls, err := readMeta(ctx, node, keys)
+	if err != nil {
+		return err
+	}
+
+	var exists []*redis.IntCmd
+	if !m.opts.replace {
+		pipe := m.dst.Pipeline()
+		exists = make([]*redis.IntCmd, len(keys))
+		for i, key := range keys {
+			exists[i] = pipe.Exists(ctx, key)
+		}
+		if _, err := pipe.Exec(ctx); err != nil && ctx.Err() != nil {
+			return ctx.Err()
+		}
+	}
+
+	for i, key := range keys {
+		if types[i] == "none" {
+			m.skipped.Add(1)
+			continue
+		}
+		if exists != nil {
+			if n, err := exists[i].Result(); err != nil {
+				m.fail(key, fmt.Errorf("exists: %w", err))
+				continue
+			} else if n > 0 {
+				m.skipped.Add(1)
+				continue
+			}
+		}
+
+		value, err := readValue(ctx, node, key, types[i])
+		if err != nil {
+			if errors.Is(err, redis.Nil) {
+				m.skipped.Add(1)
+				continue
+			}
+			m.fail(key, err)
+			continue
+		}
+		ttl, ok := restoreTTL(ttls[i])
+		if !ok {
+			m.skipped.Add(1)
+			continue
+		}
+
+		if err := writeValue(ctx, m.dst, key, types[i], value, ttl); err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			m.fail(key, err)
+			continue
+		}
+		m.copied.Add(1)
+	}
+	return nil
+}
+
+// verifyBatch 比较一批键在两端的类型、值和过期时间
+func (m *migrator) verifyBatch(ctx context.Context, node *redis.Client, keys []string) error {
+	types, ttls, err := readMeta(ctx, node, keys)
+	if err != nil {
+		return err
+	}
+	dstTypes, dstTTLs, err := readMeta(ctx, m.dst, keys)
+	if err != nil {
+		return err
+	}
+
+	for i, key := range keys {
+		switch {
+		case types[i] == "none":
+			m.skipped.Add(1)
+			continue
+		case dstTypes[i] == "none":
+			m.missing.Add(1)
+			m.record(key, errors.New("missing in destination"))
+			continue
+		case dstTypes[i] != types[i]:
+			m.mismatch(key, fmt.Sprintf("type %s != %s", dstTypes[i], types[i]))
+			continue
+		case ttls[i] > 0 && dstTTLs[i] <= 0:
+			m.mismatch(key, "ttl not set in destination")
+			continue
+		case ttls[i] < 0 && dstTTLs[i] > 0:
+			m.mismatch(key, fmt.Sprintf("unexpected ttl %s in destination", dstTTLs[i]))
+			continue
+		}
+
+		srcValue, err := readValue(ctx, node, key, types[i])
+		if errors.Is(err, redis.Nil) {
+			m.skipped.Add(1)
+			continue
+		}
+		if err == nil {
+			var dstValue any
+			if dstValue, err = readValue(ctx, m.dst, key, types[i]); err == nil {
+				if equalValue(srcValue, dstValue) {
+					m.matched.Add(1)
+				} else {
+					m.mismatch(key, "value differs")
+				}
+				continue
+			}
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		m.fail(key, err)
+	}
+	return nil
+}
+
+// restoreTTL 将 PTTL 的结果转换为 RESTORE 的过期时间（0 为不过期），键已过期或不存在时返回 false
+func restoreTTL(pttl time.Duration) (time.Duration, bool) {
+	switch {
+	case pttl > 0:
+		return pttl, true
+	case pttl == -1: // 没有过期时间（go-redis 对 -1、-2 不做单位换算）
+		return 0, true
+	default:
+		return 0, false
+	}
+}
+
+// fail 记录复制失败
+func (m *migrator) fail(key string, err error) {
+	m.failed.Add(1)
+	m.record(key, err)
+}
+
+// mismatch 记录校验不一致
+func (m *migrator) mismatch(key, reason string) {
+	m.mismatched.Add(1)
+	m.record(key, errors.New(reason))
+}
+
+// record 保存失败的键（最多 maxFailures 个）
+func (m *migrator) record(key string, err error) {
+	m.failuresMu.Lock()
+	defer m.failuresMu.Unlock()
+	if len(m.failures) < maxFailures {
+		m.failures = append(m.failures, Failure{Key: key, Error: err.Error()})
+	}
+}
+
+// stats 返回当前统计
+func (m *migrator) stats() *Stats {
+	m.failuresMu.Lock()
+	failures := append([]Failure(nil), m.failures...)
+	m.failuresMu.Unlock()
+
+	return &Stats{
+		Scanned:    m.scanned.Load(),
+		Copied:     m.copied.Load(),
+		Skipped:    m.skipped.Load(),
+		Failed:     m.failed.Load(),
+		Matched:    m.matched.Load(),
+		Missing:    m.missing.Load(),
+		Mismatched: m.mismatched.Load(),
+		Elapsed:    time.Since(m.start),
+		Failures:   failures,
+	}
+}
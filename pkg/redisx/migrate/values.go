@@ -0,0 +1,122 @@
+package migrate
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// readMeta 通过管道批量读取键的类型和 PTTL（不存在的键类型为 none）
+func readMeta(ctx context.Context, rdb redis.Cmdable, keys []string) ([]string, []time.Duration, error) {
+	pipe := rdb.Pipeline()
+	typeCmds := make([]*redis.StatusCmd, len(keys))
+	ttlCmds := make([]*redis.DurationCmd, len(keys))
+	for i, key := range keys {
+		typeCmds[i] = pipe.Type(ctx, key)
+		ttlCmds[i] = pipe.PTTL(ctx, key)
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		return nil, nil, fmt.Errorf("migrate: read key types failed: %w", err)
+	}
+
+	types := make([]string, len(keys))
+	ttls := make([]time.Duration, len(keys))
+	for i := range keys {
+		types[i] = typeCmds[i].Val()
+		ttls[i] = ttlCmds[i].Val()
+	}
+	return types, ttls, nil
+}
+
+// readValue 按类型读取键的值，键不存在时返回 redis.Nil
+//
+// 集合按成员排序，stream 只读取消息（不包括消费者组）
+func readValue(ctx context.Context, rdb redis.Cmdable, key, keyType string) (any, error) {
+	var (
+		value any
+		size  int
+		err   error
+	)
+	switch keyType {
+	case "string":
+		return rdb.Get(ctx, key).Result()
+	case "hash":
+		var v map[string]string
+		v, err = rdb.HGetAll(ctx, key).Result()
+		value, size = v, len(v)
+	case "list":
+		var v []string
+		v, err = rdb.LRange(ctx, key, 0, -1).Result()
+		value, size = v, len(v)
+	case "set":
+		var v []string
+		v, err = rdb.SMembers(ctx, key).Result()
+		sort.Strings(v)
+		value, size = v, len(v)
+	case "zset":
+		var v []redis.Z
+		v, err = rdb.ZRangeWithScores(ctx, key, 0, -1).Result()
+		value, size = v, len(v)
+	case "stream":
+		var v []redis.XMessage
+		v, err = rdb.XRange(ctx, key, "-", "+").Result()
+		value, size = v, len(v)
+	default:
+		return nil, fmt.Errorf("migrate: unsupported type %s", keyType)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("migrate: read %s failed: %w", keyType, err)
+	}
+	if size == 0 && keyType != "stream" {
+		return nil, redis.Nil // 读取前已删除（空 stream 可以存在）
+	}
+	return value, nil
+}
+
+// writeValue 在事务中删除目标键后按类型写入 readValue 读取的值，ttl 为 0 时不过期
+func writeValue(ctx context.Context, rdb redis.Cmdable, key, keyType string, value any, ttl time.Duration) error {
+	pipe := rdb.TxPipeline()
+	pipe.Del(ctx, key)
+
+	switch v := value.(type) {
+	case string:
+		pipe.Set(ctx, key, v, 0)
+	case map[string]string:
+		pipe.HSet(ctx, key, v)
+	case []string:
+		members := make([]interface{}, len(v))
+		for i, item := range v {
+			members[i] = item
+		}
+		if keyType == "set" {
+			pipe.SAdd(ctx, key, members...)
+		} else {
+			pipe.RPush(ctx, key, members...)
+		}
+	case []redis.Z:
+		pipe.ZAdd(ctx, key, v...)
+	case []redis.XMessage:
+		for _, msg := range v {
+			pipe.XAdd(ctx, &redis.XAddArgs{Stream: key, ID: msg.ID, Values: msg.Values})
+		}
+	default:
+		return fmt.Errorf("migrate: unsupported value %T", value)
+	}
+
+	if ttl > 0 {
+		pipe.PExpire(ctx, key, ttl)
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("migrate: write %s failed: %w", keyType, err)
+	}
+	return nil
+}
+
+// equalValue 比较 readValue 读取的两个值
+func equalValue(a, b any) bool {
+	return reflect.DeepEqual(a, b)
+}
@@ -0,0 +1,575 @@
+// Package resilient 为 redisx 客户端提供降级能力：Redis 连接异常时从进程内 LRU 读取，
+// 写操作排队等待 Redis 恢复后重放，适用于缓存场景，避免 Redis 故障导致服务不可用
+package resilient
+
+import (
+	"context"
+	"errors"
+	"io"
+	"maps"
+	"net"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"github.com/tedwangl/go-util/pkg/logger/zapx"
+	"github.com/tedwangl/go-util/pkg/redisx/client"
+)
+
+// Options 降级选项
+type Options struct {
+	// 本地缓存最大条目数
+	LocalSize int
+
+	// 本地副本最长保留时间，0 表示只按 Redis 的过期时间
+	LocalTTL time.Duration
+
+	// 写队列容量
+	QueueSize int
+
+	// 写队列满时的丢弃策略
+	DropPolicy DropPolicy
+
+	// 降级期间探测 Redis 的间隔
+	CheckInterval time.Duration
+
+	// 重放单个写操作的超时
+	ReplayTimeout time.Duration
+
+	// 日志，默认为 zapx.Named("redisx")
+	Logger zapx.Logger
+}
+
+// DefaultOptions 返回默认降级选项
+func DefaultOptions() *Options {
+	return &Options{
+		LocalSize:     10000,
+		LocalTTL:      time.Minute * 5,
+		QueueSize:     1000,
+		DropPolicy:    DropOldest,
+		CheckInterval: time.Second,
+		ReplayTimeout: time.Second * 3,
+	}
+}
+
+// Stats 降级状态统计
+type Stats struct {
+	Degraded      bool      // 是否处于降级状态
+	DegradedSince time.Time // 进入降级的时间
+	LocalKeys     int       // 本地缓存条目数
+	Queued        int       // 等待重放的写操作数
+	Dropped       int64     // 累计丢弃的写操作数
+	Replayed      int64     // 累计重放的写操作数
+}
+
+// Client 带降级能力的客户端
+//
+// 降级时 Get、MGet、Exists、HGet、HGetAll 从本地副本读取（未命中返回 redis.Nil），
+// Set、MSet、Del、Expire、HSet、HDel 更新本地副本并排队，返回的结果按本地副本计算；
+// 其他命令直接访问 Redis。本地副本来自正常时的读写，可能不完整或过期
+type Client struct {
+	client.Client
+
+	opts   *Options
+	logger zapx.Logger
+	local  *localCache
+	queue  *writeQueue
+
+	mu            sync.Mutex
+	degraded      atomic.Bool
+	degradedSince time.Time
+	dropAlerted   bool // 本次降级是否已发送过丢弃告警
+	replayed      atomic.Int64
+
+	stop      chan struct{}
+	closeOnce sync.Once
+	wg        sync.WaitGroup
+}
+
+var _ client.Client = (*Client)(nil)
+
+// NewClient 创建带降级能力的客户端，opts 为 nil 时使用默认选项
+func NewClient(c client.Client, opts *Options) *Client {
+	if opts == nil {
+		opts = DefaultOptions()
+	}
+
+	logger := opts.Logger
+	if logger == nil {
+		logger = zapx.Named("redisx")
+	}
+
+	return &Client{
+		Client: c,
+		opts:   opts,
+		logger: logger,
+		local:  newLocalCache(opts.LocalSize, opts.LocalTTL),
+		queue:  newWriteQueue(opts.QueueSize, opts.DropPolicy),
+		stop:   make(chan struct{}),
+	}
+}
+
+// Degraded 返回是否处于降级状态
+func (c *Client) Degraded() bool {
+	return c.degraded.Load()
+}
+
+// Stats 返回降级状态统计
+func (c *Client) Stats() Stats {
+	c.mu.Lock()
+	since := c.degradedSince
+	c.mu.Unlock()
+
+	queued, dropped := c.queue.stats()
+	return Stats{
+		Degraded:      c.degraded.Load(),
+		DegradedSince: since,
+		LocalKeys:     c.local.len(),
+		Queued:        queued,
+		Dropped:       dropped,
+		Replayed:      c.replayed.Load(),
+	}
+}
+
+// Get 获取键值
+func (c *Client) Get(ctx context.Context, key string) (*redis.StringCmd, error) {
+	if !c.degraded.Load() {
+		cmd, err := c.Client.Get(ctx, key)
+		if err == nil {
+			err = cmd.Err()
+		}
+		if c.fromRedis(err) {
+			switch {
+			case err == nil:
+				c.local.set(key, cmd.Val(), 0)
+			case errors.Is(err, redis.Nil):
+				c.local.del(key)
+			}
+			return cmd, nil
+		}
+	}
+
+	if value, ok := c.local.getString(key); ok {
+		return redis.NewStringResult(value, nil), nil
+	}
+	return redis.NewStringResult("", redis.Nil), nil
+}
+
+// Set 设置键值
+func (c *Client) Set(ctx context.Context, key string, value interface{}, expiration time.Duration) *redis.StatusCmd {
+	if !c.degraded.Load() {
+		cmd := c.Client.Set(ctx, key, value, expiration)
+		if c.fromRedis(cmd.Err()) {
+			if cmd.Err() == nil {
+				c.local.set(key, formatValue(value), expiration)
+			}
+			return cmd
+		}
+	}
+
+	c.local.set(key, formatValue(value), expiration)
+	expireAt := deadline(expiration)
+	c.enqueue(ctx, "set", key, func(ctx context.Context, rc client.Client) error {
+		ttl, ok := remaining(expiration, expireAt)
+		if !ok {
+			return nil
+		}
+		return rc.Set(ctx, key, value, ttl).Err()
+	})
+	return redis.NewStatusResult("OK", nil)
+}
+
+// Del 删除键
+func (c *Client) Del(ctx context.Context, keys ...string) *redis.IntCmd {
+	if !c.degraded.Load() {
+		cmd := c.Client.Del(ctx, keys...)
+		if c.fromRedis(cmd.Err()) {
+			if cmd.Err() == nil {
+				c.local.del(keys...)
+			}
+			return cmd
+		}
+	}
+
+	n := c.local.del(keys...)
+	c.enqueue(ctx, "del", firstKey(keys), func(ctx context.Context, rc client.Client) error {
+		return rc.Del(ctx, keys...).Err()
+	})
+	return redis.NewIntResult(n, nil)
+}
+
+// Exists 检查键是否存在
+func (c *Client) Exists(ctx context.Context, keys ...string) *redis.IntCmd {
+	if !c.degraded.Load() {
+		cmd := c.Client.Exists(ctx, keys...)
+		if c.fromRedis(cmd.Err()) {
+			return cmd
+		}
+	}
+
+	var n int64
+	for _, key := range keys {
+		if c.local.has(key) {
+			n++
+		}
+	}
+	return redis.NewIntResult(n, nil)
+}
+
+// Expire 设置键过期时间
+func (c *Client) Expire(ctx context.Context, key string, expiration time.Duration) *redis.BoolCmd {
+	if !c.degraded.Load() {
+		cmd := c.Client.Expire(ctx, key, expiration)
+		if c.fromRedis(cmd.Err()) {
+			if cmd.Err() == nil {
+				c.local.expire(key, expiration)
+			}
+			return cmd
+		}
+	}
+
+	ok := c.local.expire(key, expiration)
+	expireAt := deadline(expiration)
+	c.enqueue(ctx, "expire", key, func(ctx context.Context, rc client.Client) error {
+		ttl, ok := remaining(expiration, expireAt)
+		if !ok {
+			return rc.Del(ctx, key).Err()
+		}
+		return rc.Expire(ctx, key, ttl).Err()
+	})
+	return redis.NewBoolResult(ok, nil)
+}
+
+// MGet 批量获取键值
+func (c *Client) MGet(ctx context.Context, keys ...string) *redis.SliceCmd {
+	if !c.degraded.Load() {
+		cmd := c.Client.MGet(ctx, keys...)
+		if c.fromRedis(cmd.Err()) {
+			if cmd.Err() == nil {
+				for i, value := range cmd.Val() {
+					if s, ok := value.(string); ok && i < len(keys) {
+						c.local.set(keys[i], s, 0)
+					}
+				}
+			}
+			return cmd
+		}
+	}
+
+	values := make([]interface{}, len(keys))
+	for i, key := range keys {
+		if value, ok := c.local.getString(key); ok {
+			values[i] = value
+		}
+	}
+	return redis.NewSliceResult(values, nil)
+}
+
+// MSet 批量设置键值
+func (c *Client) MSet(ctx context.Context, values ...interface{}) *redis.StatusCmd {
+	pairs, parsed := parsePairs(values)
+	if !c.degraded.Load() {
+		cmd := c.Client.MSet(ctx, values...)
+		if c.fromRedis(cmd.Err()) {
+			if cmd.Err() == nil {
+				for key, value := range pairs {
+					c.local.set(key, value, 0)
+				}
+			}
+			return cmd
+		}
+	}
+
+	key := ""
+	for k, value := range pairs {
+		c.local.set(k, value, 0)
+		key = k
+	}
+	if !parsed {
+		c.logger.Errorw("Unsupported MSet arguments, local fallback not updated")
+	}
+	c.enqueue(ctx, "mset", key, func(ctx context.Context, rc client.Client) error {
+		return rc.MSet(ctx, values...).Err()
+	})
+	return redis.NewStatusResult("OK", nil)
+}
+
+// HGet 获取哈希字段
+func (c *Client) HGet(ctx context.Context, key, field string) *redis.StringCmd {
+	if !c.degraded.Load() {
+		cmd := c.Client.HGet(ctx, key, field)
+		if c.fromRedis(cmd.Err()) {
+			return cmd
+		}
+	}
+
+	if hash, ok := c.local.getHash(key); ok {
+		if value, ok := hash[field]; ok {
+			return redis.NewStringResult(value, nil)
+		}
+	}
+	return redis.NewStringResult("", redis.Nil)
+}
+
+// HSet 设置哈希字段
+func (c *Client) HSet(ctx context.Context, key string, values ...interface{}) *redis.IntCmd {
+	fields, parsed := parsePairs(values)
+	if !c.degraded.Load() {
+		cmd := c.Client.HSet(ctx, key, values...)
+		if c.fromRedis(cmd.Err()) {
+			// 只更新已缓存的完整哈希，避免降级时读到不完整的哈希
+			if cmd.Err() == nil {
+				if _, ok := c.local.getHash(key); ok {
+					c.local.setHash(key, fields)
+				}
+			}
+			return cmd
+		}
+	}
+
+	if !parsed {
+		c.logger.Errorw("Unsupported HSet arguments, local fallback not updated", zapx.Field("key", key))
+	} else {
+		c.local.setHash(key, fields)
+	}
+	c.enqueue(ctx, "hset", key, func(ctx context.Context, rc client.Client) error {
+		return rc.HSet(ctx, key, values...).Err()
+	})
+	return redis.NewIntResult(int64(len(fields)), nil)
+}
+
+// HDel 删除哈希字段
+func (c *Client) HDel(ctx context.Context, key string, fields ...string) *redis.IntCmd {
+	if !c.degraded.Load() {
+		cmd := c.Client.HDel(ctx, key, fields...)
+		if c.fromRedis(cmd.Err()) {
+			if cmd.Err() == nil {
+				c.local.delHash(key, fields...)
+			}
+			return cmd
+		}
+	}
+
+	n := c.local.delHash(key, fields...)
+	c.enqueue(ctx, "hdel", key, func(ctx context.Context, rc client.Client) error {
+		return rc.HDel(ctx, key, fields...).Err()
+	})
+	return redis.NewIntResult(n, nil)
+}
+
+// HGetAll 获取哈希表所有字段和值
+func (c *Client) HGetAll(ctx context.Context, key string) (map[string]string, error) {
+	if !c.degraded.Load() {
+		hash, err := c.Client.HGetAll(ctx, key)
+		if c.fromRedis(err) {
+			if err == nil {
+				if len(hash) == 0 {
+					c.local.del(key)
+				} else {
+					c.local.set(key, maps.Clone(hash), 0)
+				}
+			}
+			return hash, err
+		}
+	}
+
+	hash, _ := c.local.getHash(key)
+	if hash == nil {
+		return map[string]string{}, nil
+	}
+	return hash, nil
+}
+
+// Close 停止探测并关闭底层客户端，未重放的写操作会被丢弃
+func (c *Client) Close() error {
+	c.closeOnce.Do(func() { close(c.stop) })
+	c.wg.Wait()
+
+	if queued, _ := c.queue.stats(); queued > 0 {
+		c.logger.Errorw("Redis client closed with pending writes", zapx.Field("queued", queued))
+	}
+	return c.Client.Close()
+}
+
+// fromRedis 判断 Redis 的结果是否可用，连接异常时进入降级状态并返回 false
+func (c *Client) fromRedis(err error) bool {
+	if !isConnError(err) {
+		return true
+	}
+	c.markDegraded(err)
+	return false
+}
+
+// markDegraded 进入降级状态，发送告警并开始探测 Redis
+func (c *Client) markDegraded(err error) {
+	c.mu.Lock()
+	if c.degraded.Load() {
+		c.mu.Unlock()
+		return
+	}
+	c.degraded.Store(true)
+	c.degradedSince = time.Now()
+	c.dropAlerted = false
+	c.mu.Unlock()
+
+	c.logger.Errorw("Redis unavailable, serving from local fallback", zapx.Field("error", err.Error()))
+	zapx.Alert("redisx: Redis unavailable, degraded to local fallback: " + err.Error())
+
+	c.wg.Add(1)
+	go c.probe()
+}
+
+// probe 定期探测 Redis，恢复后重放写队列并退出降级状态
+func (c *Client) probe() {
+	defer c.wg.Done()
+
+	ticker := time.NewTicker(c.opts.CheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-c.stop:
+			return
+		case <-ticker.C:
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), c.opts.ReplayTimeout)
+		err := c.Client.Ping(ctx).Err()
+		cancel()
+		if err != nil {
+			continue
+		}
+
+		since, ok := c.replay()
+		if !ok {
+			continue
+		}
+
+		queued, dropped := c.queue.stats()
+		c.logger.Infow("Redis recovered, local fallback disabled",
+			zapx.Field("degraded", time.Since(since).String()),
+			zapx.Field("replayed", c.replayed.Load()),
+			zapx.Field("dropped", dropped),
+			zapx.Field("queued", queued))
+		zapx.Alert("redisx: Redis recovered after " + time.Since(since).Round(time.Second).String())
+		return
+	}
+}
+
+// replay 按顺序重放写队列，清空后退出降级状态并返回进入降级的时间，
+// 重放时连接异常返回 false
+func (c *Client) replay() (time.Time, bool) {
+	for {
+		w, ok := c.queue.peek()
+		if !ok {
+			// 持有 mu 检查并退出降级，避免遗漏并发加入的写操作
+			c.mu.Lock()
+			if _, ok := c.queue.peek(); ok {
+				c.mu.Unlock()
+				continue
+			}
+			since := c.degradedSince
+			c.degraded.Store(false)
+			c.degradedSince = time.Time{}
+			c.mu.Unlock()
+			return since, true
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), c.opts.ReplayTimeout)
+		err := w.apply(ctx, c.Client)
+		cancel()
+		if isConnError(err) {
+			return time.Time{}, false
+		}
+		if err != nil {
+			c.logger.Errorw("Replay Redis write failed",
+				zapx.Field("command", w.name),
+				zapx.Field("key", w.key),
+				zapx.Field("error", err.Error()))
+		} else {
+			c.replayed.Add(1)
+		}
+		c.queue.pop(w)
+	}
+}
+
+// enqueue 降级期间将写操作加入队列，已恢复时直接写入 Redis
+func (c *Client) enqueue(ctx context.Context, name, key string, apply func(ctx context.Context, rc client.Client) error) {
+	c.mu.Lock()
+	if !c.degraded.Load() {
+		c.mu.Unlock()
+		if err := apply(ctx, c.Client); err != nil {
+			c.logger.Errorw("Redis write failed", zapx.Field("command", name), zapx.Field("key", key), zapx.Field("error", err.Error()))
+		}
+		return
+	}
+
+	dropped := c.queue.push(&pendingWrite{name: name, key: key, queuedAt: time.Now(), apply: apply})
+	alert := dropped != nil && !c.dropAlerted
+	if alert {
+		c.dropAlerted = true
+	}
+	c.mu.Unlock()
+
+	if dropped == nil {
+		return
+	}
+	c.logger.Errorw("Redis write queue full, write dropped",
+		zapx.Field("command", dropped.name),
+		zapx.Field("key", dropped.key),
+		zapx.Field("queued_at", dropped.queuedAt))
+	if alert {
+		zapx.Alert("redisx: write queue full while Redis is unavailable, writes are being dropped")
+	}
+}
+
+// isConnError 判断是否为连接异常（网络错误、连接池超时等），redis.Nil、命令错误和调用方的 context 取消或超时不算
+// （context.DeadlineExceeded 实现了 net.Error，需要先排除）
+func isConnError(err error) bool {
+	if err == nil || errors.Is(err, redis.Nil) || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		return true
+	}
+	return errors.Is(err, io.EOF) ||
+		errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.Is(err, syscall.ECONNREFUSED) ||
+		errors.Is(err, syscall.ECONNRESET) ||
+		errors.Is(err, redis.ErrPoolTimeout) ||
+		errors.Is(err, redis.ErrPoolExhausted)
+}
+
+// deadline 计算过期时间点，expiration <= 0 时返回零值
+func deadline(expiration time.Duration) time.Time {
+	if expiration <= 0 {
+		return time.Time{}
+	}
+	return time.Now().Add(expiration)
+}
+
+// remaining 计算重放时剩余的过期时间，已过期时返回 false
+func remaining(expiration time.Duration, expireAt time.Time) (time.Duration, bool) {
+	if expireAt.IsZero() {
+		return expiration, true
+	}
+	ttl := time.Until(expireAt)
+	if ttl <= 0 {
+		return 0, false
+	}
+	// Redis 过期时间最小精度为毫秒
+	return max(ttl, time.Millisecond), true
+}
+
+// firstKey 返回第一个键，用于日志
+func firstKey(keys []string) string {
+	if len(keys) == 0 {
+		return ""
+	}
+	return keys[0]
+}
@@ -0,0 +1,207 @@
+package resilient
+
+import (
+	"container/list"
+	"maps"
+	"sync"
+	"time"
+)
+
+// localEntry 本地副本中的一项，value 为 string 或 map[string]string（哈希）
+type localEntry struct {
+	key      string
+	value    any
+	expireAt time.Time // 零值表示不过期
+}
+
+// localCache 进程内 LRU 缓存，Redis 不可用时提供读取
+type localCache struct {
+	mu    sync.Mutex
+	size  int
+	ttl   time.Duration
+	ll    *list.List
+	items map[string]*list.Element
+}
+
+// newLocalCache 创建本地缓存，size 为最大条目数，ttl 为本地副本的最长保留时间（0 表示不限制）
+func newLocalCache(size int, ttl time.Duration) *localCache {
+	return &localCache{
+		size:  size,
+		ttl:   ttl,
+		ll:    list.New(),
+		items: make(map[string]*list.Element),
+	}
+}
+
+// has 判断是否存在未过期的条目
+func (c *localCache) has(key string) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	_, ok := c.getLocked(key)
+	return ok
+}
+
+// getString 获取字符串条目
+func (c *localCache) getString(key string) (string, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.getLocked(key)
+	if !ok {
+		return "", false
+	}
+	value, ok := entry.value.(string)
+	return value, ok
+}
+
+// getHash 获取哈希条目的拷贝
+func (c *localCache) getHash(key string) (map[string]string, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.getLocked(key)
+	if !ok {
+		return nil, false
+	}
+	hash, ok := entry.value.(map[string]string)
+	return maps.Clone(hash), ok
+}
+
+// getLocked 获取未过期的条目并标记为最近使用，需持有锁
+func (c *localCache) getLocked(key string) (*localEntry, bool) {
+	elem, ok := c.items[key]
+	if !ok {
+		return nil, false
+	}
+	entry := elem.Value.(*localEntry)
+	if !entry.expireAt.IsZero() && time.Now().After(entry.expireAt) {
+		c.removeElement(elem)
+		return nil, false
+	}
+	c.ll.MoveToFront(elem)
+	return entry, true
+}
+
+// set 写入条目，expiration 为 Redis 中的过期时间，本地保留时间不超过 ttl
+func (c *localCache) set(key string, value any, expiration time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.setLocked(key, value, c.expireAt(expiration))
+}
+
+// setHash 更新哈希字段，条目不存在时创建
+func (c *localCache) setHash(key string, fields map[string]string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if elem, ok := c.items[key]; ok {
+		entry := elem.Value.(*localEntry)
+		if hash, ok := entry.value.(map[string]string); ok {
+			for field, value := range fields {
+				hash[field] = value
+			}
+			c.ll.MoveToFront(elem)
+			return
+		}
+	}
+	c.setLocked(key, fields, c.expireAt(0))
+}
+
+// delHash 删除哈希字段，返回删除的字段数
+func (c *localCache) delHash(key string, fields ...string) int64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	elem, ok := c.items[key]
+	if !ok {
+		return 0
+	}
+	hash, ok := elem.Value.(*localEntry).value.(map[string]string)
+	if !ok {
+		return 0
+	}
+
+	var n int64
+	for _, field := range fields {
+		if _, ok := hash[field]; ok {
+			delete(hash, field)
+			n++
+		}
+	}
+	if len(hash) == 0 {
+		c.removeElement(elem)
+	}
+	return n
+}
+
+// expire 更新条目的过期时间，返回条目是否存在
+func (c *localCache) expire(key string, expiration time.Duration) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	elem, ok := c.items[key]
+	if !ok {
+		return false
+	}
+	elem.Value.(*localEntry).expireAt = c.expireAt(expiration)
+	return true
+}
+
+// del 删除条目，返回删除的条目数
+func (c *localCache) del(keys ...string) int64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var n int64
+	for _, key := range keys {
+		if elem, ok := c.items[key]; ok {
+			c.removeElement(elem)
+			n++
+		}
+	}
+	return n
+}
+
+// len 返回条目数（包括已过期未清理的条目）
+func (c *localCache) len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.ll.Len()
+}
+
+// setLocked 写入条目并淘汰最久未使用的条目，需持有锁
+func (c *localCache) setLocked(key string, value any, expireAt time.Time) {
+	if elem, ok := c.items[key]; ok {
+		entry := elem.Value.(*localEntry)
+		entry.value = value
+		entry.expireAt = expireAt
+		c.ll.MoveToFront(elem)
+		return
+	}
+
+	c.items[key] = c.ll.PushFront(&localEntry{key: key, value: value, expireAt: expireAt})
+	for c.size > 0 && c.ll.Len() > c.size {
+		c.removeElement(c.ll.Back())
+	}
+}
+
+// removeElement 删除条目，需持有锁
+func (c *localCache) removeElement(elem *list.Element) {
+	c.ll.Remove(elem)
+	delete(c.items, elem.Value.(*localEntry).key)
+}
+
+// expireAt 计算本地副本的过期时间，取 Redis 过期时间和 ttl 中较短的一个
+func (c *localCache) expireAt(expiration time.Duration) time.Time {
+	ttl := c.ttl
+	if expiration > 0 && (ttl <= 0 || expiration < ttl) {
+		ttl = expiration
+	}
+	if ttl <= 0 {
+		return time.Time{}
+	}
+	return time.Now().Add(ttl)
+}
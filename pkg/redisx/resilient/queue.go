@@ -0,0 +1,94 @@
+package resilient
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/tedwangl/go-util/pkg/redisx/client"
+)
+
+// DropPolicy 写队列满时的丢弃策略
+type DropPolicy int
+
+const (
+	// DropOldest 丢弃最早的写操作（默认）
+	DropOldest DropPolicy = iota
+	// DropNewest 丢弃新的写操作
+	DropNewest
+)
+
+// pendingWrite 降级期间排队的写操作
+type pendingWrite struct {
+	name     string
+	key      string
+	queuedAt time.Time
+	apply    func(ctx context.Context, c client.Client) error
+}
+
+// writeQueue 有界写队列，Redis 恢复后按顺序重放
+type writeQueue struct {
+	mu      sync.Mutex
+	size    int
+	policy  DropPolicy
+	writes  []*pendingWrite
+	dropped int64
+}
+
+// newWriteQueue 创建写队列
+func newWriteQueue(size int, policy DropPolicy) *writeQueue {
+	return &writeQueue{size: size, policy: policy}
+}
+
+// push 加入写操作，队列满时按策略丢弃，返回被丢弃的操作
+func (q *writeQueue) push(w *pendingWrite) *pendingWrite {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if q.size <= 0 {
+		q.dropped++
+		return w
+	}
+	if len(q.writes) < q.size {
+		q.writes = append(q.writes, w)
+		return nil
+	}
+
+	q.dropped++
+	if q.policy == DropNewest {
+		return w
+	}
+	oldest := q.writes[0]
+	q.writes = append(q.writes[1:], w)
+	return oldest
+}
+
+// peek 返回队首的写操作
+func (q *writeQueue) peek() (*pendingWrite, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if len(q.writes) == 0 {
+		return nil, false
+	}
+	return q.writes[0], true
+}
+
+// pop 移除队首的写操作（w 已被丢弃时不做处理）
+func (q *writeQueue) pop(w *pendingWrite) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if len(q.writes) > 0 && q.writes[0] == w {
+		q.writes[0] = nil
+		q.writes = q.writes[1:]
+	}
+}
+
+// stats 返回排队和累计丢弃的写操作数
+func (q *writeQueue) stats() (queued int, dropped int64) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	return len(q.writes), q.dropped
+}
@@ -0,0 +1,101 @@
+package resilient
+
+import (
+	"encoding"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// formatValue 按 go-redis 的规则将参数转为 Redis 中保存的字符串
+func formatValue(value any) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case []byte:
+		return string(v)
+	case int:
+		return strconv.Itoa(v)
+	case int8:
+		return strconv.FormatInt(int64(v), 10)
+	case int16:
+		return strconv.FormatInt(int64(v), 10)
+	case int32:
+		return strconv.FormatInt(int64(v), 10)
+	case int64:
+		return strconv.FormatInt(v, 10)
+	case uint:
+		return strconv.FormatUint(uint64(v), 10)
+	case uint8:
+		return strconv.FormatUint(uint64(v), 10)
+	case uint16:
+		return strconv.FormatUint(uint64(v), 10)
+	case uint32:
+		return strconv.FormatUint(uint64(v), 10)
+	case uint64:
+		return strconv.FormatUint(v, 10)
+	case float32:
+		return strconv.FormatFloat(float64(v), 'f', -1, 32)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case bool:
+		if v {
+			return "1"
+		}
+		return "0"
+	case time.Time:
+		return v.Format(time.RFC3339Nano)
+	case time.Duration:
+		return strconv.FormatInt(v.Nanoseconds(), 10)
+	case encoding.BinaryMarshaler:
+		if b, err := v.MarshalBinary(); err == nil {
+			return string(b)
+		}
+	}
+	return fmt.Sprint(value)
+}
+
+// parsePairs 解析 MSet、HSet 的参数（键值对、map 或切片），不支持的形式（如结构体）返回 false
+func parsePairs(values []any) (map[string]string, bool) {
+	if len(values) == 1 {
+		switch v := values[0].(type) {
+		case map[string]any:
+			pairs := make(map[string]string, len(v))
+			for key, value := range v {
+				pairs[key] = formatValue(value)
+			}
+			return pairs, true
+		case map[string]string:
+			pairs := make(map[string]string, len(v))
+			for key, value := range v {
+				pairs[key] = value
+			}
+			return pairs, true
+		case []string:
+			args := make([]any, len(v))
+			for i, s := range v {
+				args[i] = s
+			}
+			return parsePairs(args)
+		case []any:
+			return parsePairs(v)
+		default:
+			return map[string]string{}, false
+		}
+	}
+
+	if len(values)%2 != 0 {
+		return map[string]string{}, false
+	}
+	pairs := make(map[string]string, len(values)/2)
+	for i := 0; i < len(values); i += 2 {
+		key, ok := values[i].(string)
+		if !ok {
+			return map[string]string{}, false
+		}
+		pairs[key] = formatValue(values[i+1])
+	}
+	return pairs, true
+}
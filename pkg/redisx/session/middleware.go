@@ -0,0 +1,62 @@
+package session
+
+import (
+	"context"
+	"errors"
+	"net/http"
+)
+
+type contextKey struct{}
+
+// NewContext 将会话保存到 context
+func NewContext(ctx context.Context, sess *Session) context.Context {
+	return context.WithValue(ctx, contextKey{}, sess)
+}
+
+// FromContext 从 context 获取会话
+func FromContext(ctx context.Context) (*Session, bool) {
+	sess, ok := ctx.Value(contextKey{}).(*Session)
+	return sess, ok
+}
+
+// Middleware 从 Cookie 读取会话 ID 并刷新会话，有效的会话通过 FromContext 获取
+//
+// 会话不存在时清除 Cookie 并继续处理请求，是否要求登录由业务处理器决定；
+// 读取会话出错时返回 500
+func (s *Store) Middleware(cookieName string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			cookie, err := r.Cookie(cookieName)
+			if err != nil || cookie.Value == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			sess, err := s.Refresh(r.Context(), cookie.Value)
+			switch {
+			case errors.Is(err, ErrSessionNotFound):
+				http.SetCookie(w, &http.Cookie{Name: cookieName, Path: "/", MaxAge: -1})
+				next.ServeHTTP(w, r)
+				return
+			case err != nil:
+				http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+				return
+			}
+
+			s.SetCookie(w, cookieName, sess)
+			next.ServeHTTP(w, r.WithContext(NewContext(r.Context(), sess)))
+		})
+	}
+}
+
+// SetCookie 写入会话 Cookie（HttpOnly、SameSite=Lax），Max-Age 与会话的剩余有效期一致
+func (s *Store) SetCookie(w http.ResponseWriter, cookieName string, sess *Session) {
+	http.SetCookie(w, &http.Cookie{
+		Name:     cookieName,
+		Value:    sess.ID,
+		Path:     "/",
+		MaxAge:   sess.remaining(),
+		HttpOnly: true,
+		SameSite: http.SameSiteLaxMode,
+	})
+}
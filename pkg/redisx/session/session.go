@@ -0,0 +1,378 @@
+// Package session 基于 redisx 客户端的会话存储，支持滚动过期、设备记录和同一用户的并发会话数限制
+package session
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"github.com/tedwangl/go-util/pkg/redisx/client"
+)
+
+var (
+	// ErrSessionNotFound 会话不存在或已过期
+	ErrSessionNotFound = errors.New("redisx: session not found")
+	// ErrTooManySessions 用户的会话数已达到上限
+	ErrTooManySessions = errors.New("redisx: too many sessions")
+)
+
+// updateScript 会话仍存在时覆盖会话数据并设置过期时间，避免恢复并发删除（退出登录）的会话
+// KEYS[1] 会话；ARGV[1] 会话数据，ARGV[2] 过期毫秒数
+const updateScript = `
+if redis.call("EXISTS", KEYS[1]) == 0 then
+	return 0
+end
+redis.call("SET", KEYS[1], ARGV[1], "PX", ARGV[2])
+return 1
+`
+
+// Device 创建会话的设备
+type Device struct {
+	ID        string `json:"id,omitempty"` // 客户端生成的设备标识，同一设备重复登录时替换旧会话
+	Name      string `json:"name,omitempty"`
+	UserAgent string `json:"user_agent,omitempty"`
+	IP        string `json:"ip,omitempty"`
+}
+
+// Session 会话
+type Session struct {
+	ID        string            `json:"id"`
+	UserID    string            `json:"user_id"`
+	Device    Device            `json:"device"`
+	Data      map[string]string `json:"data,omitempty"`
+	CreatedAt time.Time         `json:"created_at"`
+	LastSeen  time.Time         `json:"last_seen"`
+	ExpiresAt time.Time         `json:"expires_at"`
+}
+
+// Options 会话选项
+type Options struct {
+	// 键前缀
+	Prefix string
+
+	// 空闲过期时间，每次 Refresh 后重新计算
+	TTL time.Duration
+
+	// 会话最长有效期（从创建开始计算），0 表示不限制
+	MaxLifetime time.Duration
+
+	// 同一用户的最大会话数，0 表示不限制
+	MaxSessions int
+
+	// 超出 MaxSessions 时淘汰最早创建的会话，为 false 时返回 ErrTooManySessions
+	EvictOldest bool
+}
+
+// DefaultOptions 返回默认会话选项
+func DefaultOptions() *Options {
+	return &Options{
+		Prefix:      "session",
+		TTL:         time.Minute * 30,
+		MaxLifetime: time.Hour * 24 * 7,
+		EvictOldest: true,
+	}
+}
+
+// Store 会话存储
+//
+// 会话保存为 <prefix>:<id>（JSON），用户的会话列表保存在有序集合 <prefix>:user:<user_id> 中；
+// 并发创建时会话数可能短暂超过 MaxSessions
+type Store struct {
+	client client.Client
+	opts   *Options
+}
+
+// NewStore 创建会话存储，opts 为 nil 时使用默认选项
+func NewStore(cli client.Client, opts *Options) *Store {
+	if opts == nil {
+		opts = DefaultOptions()
+	}
+	if opts.Prefix == "" {
+		opts.Prefix = "session"
+	}
+	if opts.TTL <= 0 {
+		opts.TTL = DefaultOptions().TTL
+	}
+
+	return &Store{
+		client: cli,
+		opts:   opts,
+	}
+}
+
+// Create 为用户创建会话，同一设备（Device.ID 相同）的旧会话会被替换
+func (s *Store) Create(ctx context.Context, userID string, device Device, data map[string]string) (*Session, error) {
+	sessions, err := s.List(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	// 同一设备重新登录时替换旧会话
+	var stale []string
+	live := sessions[:0]
+	for _, sess := range sessions {
+		if device.ID != "" && sess.Device.ID == device.ID {
+			stale = append(stale, sess.ID)
+			continue
+		}
+		live = append(live, sess)
+	}
+
+	if s.opts.MaxSessions > 0 && len(live) >= s.opts.MaxSessions {
+		if !s.opts.EvictOldest {
+			return nil, ErrTooManySessions
+		}
+		// List 按创建时间升序返回
+		for _, sess := range live[:len(live)-s.opts.MaxSessions+1] {
+			stale = append(stale, sess.ID)
+		}
+	}
+	if err := s.destroy(ctx, userID, stale...); err != nil {
+		return nil, err
+	}
+
+	id, err := newID()
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	sess := &Session{
+		ID:        id,
+		UserID:    userID,
+		Device:    device,
+		Data:      data,
+		CreatedAt: now,
+		LastSeen:  now,
+	}
+	sess.ExpiresAt = s.expiresAt(sess, now)
+
+	if err := s.save(ctx, sess, false); err != nil {
+		return nil, err
+	}
+
+	index := s.userKey(userID)
+	if err := s.client.ZAdd(ctx, index, &redis.Z{Score: float64(now.UnixNano()), Member: id}).Err(); err != nil {
+		return nil, fmt.Errorf("add session to user index failed: %w", err)
+	}
+	s.client.Expire(ctx, index, s.indexTTL())
+
+	return sess, nil
+}
+
+// Get 获取会话（不延长过期时间）
+func (s *Store) Get(ctx context.Context, id string) (*Session, error) {
+	cmd, err := s.client.Get(ctx, s.key(id))
+	if err != nil {
+		return nil, err
+	}
+
+	val, err := cmd.Result()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return nil, ErrSessionNotFound
+		}
+		return nil, fmt.Errorf("get session failed: %w", err)
+	}
+
+	var sess Session
+	if err := json.Unmarshal([]byte(val), &sess); err != nil {
+		return nil, fmt.Errorf("invalid session format: %w", err)
+	}
+	return &sess, nil
+}
+
+// Refresh 获取会话并延长过期时间（滚动过期），超过 MaxLifetime 的会话会被删除
+func (s *Store) Refresh(ctx context.Context, id string) (*Session, error) {
+	sess, err := s.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	if s.opts.MaxLifetime > 0 && now.Sub(sess.CreatedAt) >= s.opts.MaxLifetime {
+		_ = s.Destroy(ctx, id)
+		return nil, ErrSessionNotFound
+	}
+
+	sess.LastSeen = now
+	sess.ExpiresAt = s.expiresAt(sess, now)
+	if err := s.save(ctx, sess, true); err != nil {
+		return nil, err
+	}
+	s.client.Expire(ctx, s.userKey(sess.UserID), s.indexTTL())
+	return sess, nil
+}
+
+// Update 保存会话数据的修改，不改变过期时间；会话已过期或已删除时返回 ErrSessionNotFound
+func (s *Store) Update(ctx context.Context, sess *Session) error {
+	return s.save(ctx, sess, true)
+}
+
+// Destroy 删除会话
+func (s *Store) Destroy(ctx context.Context, id string) error {
+	sess, err := s.Get(ctx, id)
+	if errors.Is(err, ErrSessionNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	return s.destroy(ctx, sess.UserID, id)
+}
+
+// List 返回用户的有效会话（按创建时间升序），同时清理已过期会话的索引
+func (s *Store) List(ctx context.Context, userID string) ([]*Session, error) {
+	index := s.userKey(userID)
+	ids, err := s.client.ZRange(ctx, index, 0, -1).Result()
+	if err != nil {
+		return nil, fmt.Errorf("list sessions failed: %w", err)
+	}
+	if len(ids) == 0 {
+		return nil, nil
+	}
+
+	// 会话可能分布在集群的不同节点上，使用管道逐个读取
+	pipe := s.client.Pipeline()
+	cmds := make([]*redis.StringCmd, len(ids))
+	for i, id := range ids {
+		cmds[i] = pipe.Get(ctx, s.key(id))
+	}
+	if _, err := pipe.Exec(ctx); err != nil && !errors.Is(err, redis.Nil) {
+		return nil, fmt.Errorf("list sessions failed: %w", err)
+	}
+
+	var (
+		sessions []*Session
+		expired  []interface{}
+	)
+	for i, cmd := range cmds {
+		var sess Session
+		val, err := cmd.Result()
+		if err != nil || json.Unmarshal([]byte(val), &sess) != nil {
+			expired = append(expired, ids[i])
+			continue
+		}
+		sessions = append(sessions, &sess)
+	}
+	if len(expired) > 0 {
+		s.client.ZRem(ctx, index, expired...)
+	}
+	return sessions, nil
+}
+
+// DestroyAll 删除用户的所有会话（except 中的会话除外，如退出其他设备时保留当前会话），返回删除的数量
+func (s *Store) DestroyAll(ctx context.Context, userID string, except ...string) (int, error) {
+	sessions, err := s.List(ctx, userID)
+	if err != nil {
+		return 0, err
+	}
+
+	keep := make(map[string]bool, len(except))
+	for _, id := range except {
+		keep[id] = true
+	}
+	var ids []string
+	for _, sess := range sessions {
+		if !keep[sess.ID] {
+			ids = append(ids, sess.ID)
+		}
+	}
+	return len(ids), s.destroy(ctx, userID, ids...)
+}
+
+// destroy 删除会话并从用户索引中移除
+func (s *Store) destroy(ctx context.Context, userID string, ids ...string) error {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	members := make([]interface{}, len(ids))
+	for i, id := range ids {
+		// 逐个删除，避免集群模式下跨槽
+		if err := s.client.Del(ctx, s.key(id)).Err(); err != nil {
+			return fmt.Errorf("destroy session failed: %w", err)
+		}
+		members[i] = id
+	}
+	if err := s.client.ZRem(ctx, s.userKey(userID), members...).Err(); err != nil {
+		return fmt.Errorf("remove session from user index failed: %w", err)
+	}
+	return nil
+}
+
+// save 保存会话，过期时间为 ExpiresAt，已过期时返回 ErrSessionNotFound
+// update 为 true 时只更新仍存在的会话，会话已被删除时返回 ErrSessionNotFound
+func (s *Store) save(ctx context.Context, sess *Session, update bool) error {
+	ttl := time.Until(sess.ExpiresAt).Milliseconds()
+	if ttl <= 0 {
+		return ErrSessionNotFound
+	}
+
+	data, err := json.Marshal(sess)
+	if err != nil {
+		return fmt.Errorf("marshal session failed: %w", err)
+	}
+
+	if !update {
+		if err := s.client.Set(ctx, s.key(sess.ID), data, time.Duration(ttl)*time.Millisecond).Err(); err != nil {
+			return fmt.Errorf("save session failed: %w", err)
+		}
+		return nil
+	}
+
+	ok, err := s.client.Eval(ctx, updateScript, []string{s.key(sess.ID)}, data, ttl).Int64()
+	if err != nil {
+		return fmt.Errorf("save session failed: %w", err)
+	}
+	if ok == 0 {
+		return ErrSessionNotFound
+	}
+	return nil
+}
+
+// expiresAt 计算过期时间：now + TTL，不超过 CreatedAt + MaxLifetime
+func (s *Store) expiresAt(sess *Session, now time.Time) time.Time {
+	expiresAt := now.Add(s.opts.TTL)
+	if s.opts.MaxLifetime > 0 {
+		if limit := sess.CreatedAt.Add(s.opts.MaxLifetime); limit.Before(expiresAt) {
+			expiresAt = limit
+		}
+	}
+	return expiresAt
+}
+
+// indexTTL 用户索引的过期时间，不短于任何会话的剩余有效期
+func (s *Store) indexTTL() time.Duration {
+	if s.opts.MaxLifetime > 0 {
+		return s.opts.MaxLifetime
+	}
+	return s.opts.TTL
+}
+
+func (s *Store) key(id string) string {
+	return s.opts.Prefix + ":" + id
+}
+
+func (s *Store) userKey(userID string) string {
+	return s.opts.Prefix + ":user:" + userID
+}
+
+// newID 生成 256 位随机会话 ID
+func newID() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("generate session id failed: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// remaining 返回会话的剩余有效期（秒），用于设置 Cookie 的 Max-Age
+func (sess *Session) remaining() int {
+	return int(time.Until(sess.ExpiresAt) / time.Second)
+}
@@ -2,10 +2,13 @@ package utils
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"math"
 	"time"
 
 	redisxerrors "github.com/tedwangl/go-util/pkg/redisx/errors"
+	"github.com/tedwangl/go-util/pkg/utils/retry"
 )
 
 type RetryConfig struct {
@@ -33,35 +36,42 @@ func Retry(ctx context.Context, config *RetryConfig, fn RetryFunc) error {
 		config = DefaultRetryConfig()
 	}
 
-	var lastErr error
-	delay := config.InitialDelay
-
-	for attempt := 1; attempt <= config.MaxAttempts; attempt++ {
-		err := fn(attempt)
-		if err == nil {
-			return nil
-		}
-
-		lastErr = err
-
-		if config.RetryableChecker != nil && !config.RetryableChecker(err) {
-			return fmt.Errorf("non-retryable error on attempt %d: %w", attempt, err)
-		}
-
-		if attempt < config.MaxAttempts {
-			select {
-			case <-ctx.Done():
-				return fmt.Errorf("retry canceled: %w", ctx.Err())
-			case <-time.After(delay):
-				delay = time.Duration(float64(delay) * config.BackoffFactor)
-				if delay > config.MaxDelay {
-					delay = config.MaxDelay
-				}
-			}
-		}
+	var attempts int
+	nonRetryable := false
+	err := retry.Do(ctx, func(ctx context.Context, attempt int) error {
+		attempts = attempt + 1
+		return fn(attempts)
+	},
+		retry.Attempts(config.MaxAttempts),
+		retry.Backoff(config.backoff),
+		retry.RetryIf(func(err error) bool {
+			nonRetryable = config.RetryableChecker != nil && !config.RetryableChecker(err)
+			return !nonRetryable
+		}),
+	)
+
+	switch {
+	case err == nil:
+		return nil
+	case nonRetryable:
+		return fmt.Errorf("non-retryable error on attempt %d: %w", attempts, err)
+	case ctx.Err() != nil && errors.Is(err, ctx.Err()):
+		return fmt.Errorf("retry canceled: %w", ctx.Err())
+	default:
+		return redisxerrors.NewRetryError(config.MaxAttempts, "all retry attempts failed", err)
 	}
+}
 
-	return redisxerrors.NewRetryError(config.MaxAttempts, "all retry attempts failed", lastErr)
+// backoff 第 attempt 次失败后的等待时间：InitialDelay * BackoffFactor^attempt，不超过 MaxDelay
+func (c *RetryConfig) backoff(attempt int) time.Duration {
+	if attempt == 0 {
+		return c.InitialDelay
+	}
+	delay := float64(c.InitialDelay) * math.Pow(c.BackoffFactor, float64(attempt))
+	if delay > float64(c.MaxDelay) {
+		return c.MaxDelay
+	}
+	return time.Duration(delay)
 }
 
 func RetryWithBackoff(ctx context.Context, maxAttempts int, initialDelay, maxDelay time.Duration, fn RetryFunc) error {
@@ -140,12 +150,10 @@ const (
 	StateHalfOpen
 )
 
+// CircuitBreaker 熔断器，基于 utils/retry.Breaker（并发安全，半开状态只放行一次试探调用）
 type CircuitBreaker struct {
-	config      *CircuitBreakerConfig
-	state       CircuitBreakerState
-	failures    int
-	lastFailure time.Time
-	lastAttempt time.Time
+	config  *CircuitBreakerConfig
+	breaker *retry.Breaker
 }
 
 func NewCircuitBreaker(config *CircuitBreakerConfig) *CircuitBreaker {
@@ -157,45 +165,26 @@ func NewCircuitBreaker(config *CircuitBreakerConfig) *CircuitBreaker {
 		}
 	}
 	return &CircuitBreaker{
-		config: config,
-		state:  StateClosed,
+		config:  config,
+		breaker: retry.NewBreaker(config.MaxFailures, config.ResetTimeout),
 	}
 }
 
 func (cb *CircuitBreaker) Execute(fn func() error) error {
-	if cb.state == StateOpen {
-		if time.Since(cb.lastFailure) > cb.config.ResetTimeout {
-			cb.state = StateHalfOpen
-			cb.lastAttempt = time.Now()
-		} else {
-			return fmt.Errorf("circuit breaker is open")
-		}
-	}
-
-	err := fn()
-
-	if err != nil {
-		cb.failures++
-		cb.lastFailure = time.Now()
-
-		if cb.failures >= cb.config.MaxFailures {
-			cb.state = StateOpen
-		}
-
-		return err
-	}
-
-	cb.failures = 0
-	cb.state = StateClosed
-	return nil
+	return cb.breaker.Execute(fn)
 }
 
 func (cb *CircuitBreaker) GetState() CircuitBreakerState {
-	return cb.state
+	switch cb.breaker.State() {
+	case retry.StateOpen:
+		return StateOpen
+	case retry.StateHalfOpen:
+		return StateHalfOpen
+	default:
+		return StateClosed
+	}
 }
 
 func (cb *CircuitBreaker) Reset() {
-	cb.state = StateClosed
-	cb.failures = 0
-	cb.lastFailure = time.Time{}
+	cb.breaker.Reset()
 }
@@ -0,0 +1,284 @@
+package restyx
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/getkin/kin-openapi/routers"
+	"github.com/getkin/kin-openapi/routers/gorillamux"
+)
+
+// ContractMode 契约校验不通过时的处理方式
+type ContractMode int
+
+const (
+	// ContractLog 记录 Warn 日志，请求照常进行（默认）
+	ContractLog ContractMode = iota
+	// ContractFail 请求不符合时不发送，响应不符合时返回错误（不重试）
+	ContractFail
+)
+
+// 校验阶段
+const (
+	ContractPhaseRoute    = "route"    // 文档中没有对应的接口
+	ContractPhaseRequest  = "request"  // 请求的路径、参数或请求体不符合
+	ContractPhaseResponse = "response" // 响应的状态码、响应头或响应体不符合
+)
+
+// ErrContractViolation 请求或响应不符合 OpenAPI 文档，可用 errors.Is 判断
+var ErrContractViolation = errors.New("API contract violation")
+
+type (
+	// ContractConfig OpenAPI 契约校验配置
+	ContractConfig struct {
+		Spec          []byte       // OpenAPI 3 文档内容（JSON 或 YAML），与 SpecFile 二选一
+		SpecFile      string       // OpenAPI 3 文档路径或 http(s) 地址
+		Mode          ContractMode // 不符合时的处理方式
+		SkipRequests  bool         // 不校验请求
+		SkipResponses bool         // 不校验响应
+		AllowUnknown  bool         // 不报告文档中没有的接口（只校验文档覆盖的部分）
+		StrictStatus  bool         // 响应状态码未在文档中定义时视为不符合（文档有 default 响应时不生效）
+	}
+
+	// ContractError 契约校验失败
+	ContractError struct {
+		Phase      string // ContractPhaseRoute、ContractPhaseRequest 或 ContractPhaseResponse
+		Method     string
+		URL        string
+		Operation  string // 文档中的 operationId（Phase 为 route 时为空）
+		StatusCode int    // 响应状态码（Phase 为 response 时）
+		Err        error  // kin-openapi 的校验错误
+	}
+
+	// contractTransport 按 OpenAPI 文档校验请求和响应
+	contractTransport struct {
+		base   http.RoundTripper
+		router routers.Router
+		config ContractConfig
+		logger Logger
+	}
+)
+
+func (e *ContractError) Error() string {
+	msg := fmt.Sprintf("%s: %s %s %s", ErrContractViolation, e.Phase, e.Method, e.URL)
+	if e.Operation != "" {
+		msg += " (" + e.Operation + ")"
+	}
+	return msg + ": " + e.Err.Error()
+}
+
+func (e *ContractError) Unwrap() error {
+	return e.Err
+}
+
+// Is 所有 ContractError 都匹配 ErrContractViolation
+func (e *ContractError) Is(target error) bool {
+	return target == ErrContractViolation
+}
+
+// EnableContract 开启 OpenAPI 契约校验，在请求发出前校验路径、参数和请求体，收到响应后校验状态码和响应体
+//
+// 校验只按路径匹配接口，忽略文档 servers 中的协议和主机（保留路径前缀），便于在各个环境使用同一份文档。
+// 校验需要缓存请求体和响应体，建议只在开发、测试环境开启
+func (c *Client) EnableContract(config ContractConfig) error {
+	doc, err := loadContract(config)
+	if err != nil {
+		return err
+	}
+	router, err := gorillamux.NewRouter(doc)
+	if err != nil {
+		return fmt.Errorf("create OpenAPI router failed: %w", err)
+	}
+
+	c.client.SetTransport(&contractTransport{
+		base:   c.client.GetClient().Transport,
+		router: router,
+		config: config,
+		logger: c.logger,
+	})
+	return nil
+}
+
+// loadContract 加载并校验 OpenAPI 文档，servers 只保留路径
+func loadContract(config ContractConfig) (*openapi3.T, error) {
+	loader := openapi3.NewLoader()
+	loader.IsExternalRefsAllowed = true
+
+	var (
+		doc *openapi3.T
+		err error
+	)
+	switch {
+	case len(config.Spec) > 0:
+		doc, err = loader.LoadFromData(config.Spec)
+	case strings.HasPrefix(config.SpecFile, "http://") || strings.HasPrefix(config.SpecFile, "https://"):
+		var u *url.URL
+		if u, err = url.Parse(config.SpecFile); err == nil {
+			doc, err = loader.LoadFromURI(u)
+		}
+	case config.SpecFile != "":
+		doc, err = loader.LoadFromFile(config.SpecFile)
+	default:
+		return nil, errors.New("OpenAPI spec not set")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("load OpenAPI spec failed: %w", err)
+	}
+	if err := doc.Validate(loader.Context); err != nil {
+		return nil, fmt.Errorf("invalid OpenAPI spec: %w", err)
+	}
+
+	var servers openapi3.Servers
+	seen := make(map[string]bool)
+	for _, s := range doc.Servers {
+		base := s.URL
+		if u, err := url.Parse(s.URL); err == nil && u.Host != "" {
+			base = u.Path
+		}
+		base = strings.TrimSuffix(base, "/")
+		if !seen[base] {
+			seen[base] = true
+			servers = append(servers, &openapi3.Server{URL: base, Variables: s.Variables})
+		}
+	}
+	doc.Servers = servers
+
+	return doc, nil
+}
+
+// RoundTrip 实现 http.RoundTripper
+func (t *contractTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	route, pathParams, err := t.router.FindRoute(req)
+	if err != nil {
+		if t.config.AllowUnknown {
+			return t.base.RoundTrip(req)
+		}
+		if err := t.report(&ContractError{Phase: ContractPhaseRoute, Method: req.Method, URL: req.URL.String(), Err: err}); err != nil {
+			return nil, err
+		}
+		return t.base.RoundTrip(req)
+	}
+
+	// 请求体要读两次（校验和发送），缓存后替换
+	body, err := readBody(req.Body)
+	if err != nil {
+		return nil, err
+	}
+	if req.Body != nil {
+		req.Body.Close()
+	}
+	send := req
+	if req.Body != nil {
+		send = req.Clone(req.Context())
+		send.Body = io.NopCloser(bytes.NewReader(body))
+	}
+
+	options := &openapi3filter.Options{
+		AuthenticationFunc:    openapi3filter.NoopAuthenticationFunc,
+		MultiError:            true,
+		SkipSettingDefaults:   true,
+		IncludeResponseStatus: t.config.StrictStatus,
+	}
+	options.WithCustomSchemaErrorFunc(schemaErrorMessage)
+
+	checked := req.Clone(req.Context())
+	checked.Body = io.NopCloser(bytes.NewReader(body))
+	input := &openapi3filter.RequestValidationInput{
+		Request:    checked,
+		PathParams: pathParams,
+		Route:      route,
+		Options:    options,
+	}
+	newError := func(phase string, statusCode int, err error) *ContractError {
+		return &ContractError{
+			Phase:      phase,
+			Method:     req.Method,
+			URL:        req.URL.String(),
+			Operation:  route.Operation.OperationID,
+			StatusCode: statusCode,
+			Err:        err,
+		}
+	}
+
+	if !t.config.SkipRequests {
+		if err := openapi3filter.ValidateRequest(checked.Context(), input); err != nil {
+			if err := t.report(newError(ContractPhaseRequest, 0, err)); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	resp, err := t.base.RoundTrip(send)
+	if err != nil || t.config.SkipResponses {
+		return resp, err
+	}
+
+	respBody, err := readBody(resp.Body)
+	if resp.Body != nil {
+		resp.Body.Close()
+	}
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(respBody))
+
+	checked.Body = io.NopCloser(bytes.NewReader(body))
+	respInput := &openapi3filter.ResponseValidationInput{
+		RequestValidationInput: input,
+		Status:                 resp.StatusCode,
+		Header:                 resp.Header,
+		Body:                   io.NopCloser(bytes.NewReader(respBody)),
+		Options:                options,
+	}
+	if err := openapi3filter.ValidateResponse(checked.Context(), respInput); err != nil {
+		if err := t.report(newError(ContractPhaseResponse, resp.StatusCode, err)); err != nil {
+			return nil, err
+		}
+	}
+	return resp, nil
+}
+
+// report 记录校验失败，ContractFail 模式下返回错误
+func (t *contractTransport) report(e *ContractError) error {
+	fields := []any{
+		"phase", e.Phase,
+		"method", e.Method,
+		"url", e.URL,
+		"error", e.Err.Error(),
+	}
+	if e.Operation != "" {
+		fields = append(fields, "operation", e.Operation)
+	}
+	if e.StatusCode != 0 {
+		fields = append(fields, "status_code", e.StatusCode)
+	}
+	t.logger.Warn("API contract violation", fields...)
+
+	if t.config.Mode == ContractFail {
+		return e
+	}
+	return nil
+}
+
+// schemaErrorMessage 简化 schema 错误信息（默认会附带完整的 schema 和值）
+func schemaErrorMessage(err *openapi3.SchemaError) string {
+	if pointer := err.JSONPointer(); len(pointer) > 0 {
+		return fmt.Sprintf("at %q: %s", "/"+strings.Join(pointer, "/"), err.Reason)
+	}
+	return err.Reason
+}
+
+// readBody 读取请求体或响应体（nil 时返回 nil）
+func readBody(body io.ReadCloser) ([]byte, error) {
+	if body == nil || body == http.NoBody {
+		return nil, nil
+	}
+	return io.ReadAll(body)
+}
@@ -0,0 +1,205 @@
+package restyx
+
+import (
+	"bufio"
+	"compress/gzip"
+	"compress/zlib"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+
+	"github.com/andybalholm/brotli"
+	"github.com/klauspost/compress/zstd"
+	"golang.org/x/net/html/charset"
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/transform"
+)
+
+// acceptEncoding 启用解压时发送的 Accept-Encoding
+const acceptEncoding = "gzip, deflate, br, zstd"
+
+// charsetSniffLen 检测 HTML 编码时读取的字节数（与 charset.DetermineEncoding 一致）
+const charsetSniffLen = 1024
+
+type (
+	// decodingTransport 解压响应体并将文本响应转换为 UTF-8
+	decodingTransport struct {
+		base           http.RoundTripper
+		decompress     bool
+		convertCharset bool
+	}
+
+	// decodedBody 解码后的响应体，关闭时同时关闭解码器和原始响应体
+	decodedBody struct {
+		io.Reader
+		closers []io.Closer
+	}
+
+	// zstdCloser 适配 zstd.Decoder 的 Close（无返回值）
+	zstdCloser struct {
+		*zstd.Decoder
+	}
+
+	// keepCharsetKey 请求上下文中存在时不转换编码（文件下载需要保持原始字节）
+	keepCharsetKey struct{}
+)
+
+// RoundTrip 实现 http.RoundTripper
+// 请求已设置 Accept-Encoding 或 Range 时不解压（与 Go 默认的 gzip 处理一致），Range 请求和文件下载不转换编码
+func (t *decodingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	decompress := t.decompress && req.Method != http.MethodHead &&
+		req.Header.Get("Accept-Encoding") == "" && req.Header.Get("Range") == ""
+	if decompress {
+		req = req.Clone(req.Context())
+		req.Header.Set("Accept-Encoding", acceptEncoding)
+	}
+
+	resp, err := t.base.RoundTrip(req)
+	if err != nil || resp.Body == nil || resp.Body == http.NoBody {
+		return resp, err
+	}
+
+	if decompress {
+		if err := decompressBody(resp); err != nil {
+			resp.Body.Close()
+			return nil, err
+		}
+	}
+	if t.convertCharset && req.Header.Get("Range") == "" && req.Context().Value(keepCharsetKey{}) == nil {
+		if err := convertBody(resp); err != nil {
+			resp.Body.Close()
+			return nil, err
+		}
+	}
+	return resp, nil
+}
+
+// decompressBody 按 Content-Encoding 解压响应体（多个编码按相反顺序解压），包含不支持的编码时保持原样
+func decompressBody(resp *http.Response) error {
+	var encodings []string
+	for _, v := range resp.Header.Values("Content-Encoding") {
+		for _, enc := range strings.Split(v, ",") {
+			if enc = strings.ToLower(strings.TrimSpace(enc)); enc != "" && enc != "identity" {
+				encodings = append(encodings, enc)
+			}
+		}
+	}
+	if len(encodings) == 0 {
+		return nil
+	}
+	for _, enc := range encodings {
+		switch enc {
+		case "gzip", "x-gzip", "deflate", "br", "zstd":
+		default:
+			return nil
+		}
+	}
+
+	body := &decodedBody{Reader: resp.Body, closers: []io.Closer{resp.Body}}
+	for i := len(encodings) - 1; i >= 0; i-- {
+		var err error
+		switch encodings[i] {
+		case "gzip", "x-gzip":
+			var r *gzip.Reader
+			if r, err = gzip.NewReader(body.Reader); err == nil {
+				body.Reader = r
+				body.closers = append(body.closers, r)
+			}
+		case "deflate":
+			var r io.ReadCloser
+			if r, err = zlib.NewReader(body.Reader); err == nil {
+				body.Reader = r
+				body.closers = append(body.closers, r)
+			}
+		case "br":
+			body.Reader = brotli.NewReader(body.Reader)
+		case "zstd":
+			var r *zstd.Decoder
+			if r, err = zstd.NewReader(body.Reader, zstd.WithDecoderConcurrency(1)); err == nil {
+				body.Reader = r
+				body.closers = append(body.closers, zstdCloser{r})
+			}
+		}
+		if err != nil {
+			body.Close()
+			return fmt.Errorf("decompress %s response failed: %w", encodings[i], err)
+		}
+	}
+
+	resp.Body = body
+	resp.Header.Del("Content-Encoding")
+	resp.Header.Del("Content-Length")
+	resp.ContentLength = -1
+	resp.Uncompressed = true
+	return nil
+}
+
+// convertBody 将非 UTF-8 的文本响应转换为 UTF-8 并更新 Content-Type 的 charset
+// 优先使用 Content-Type 声明的 charset；HTML 未声明时按 BOM 和 <meta> 检测；其他文本类型未声明时视为 UTF-8
+func convertBody(resp *http.Response) error {
+	contentType := resp.Header.Get("Content-Type")
+	mediaType, params, err := mime.ParseMediaType(contentType)
+	if err != nil || !isTextMediaType(mediaType) {
+		return nil
+	}
+
+	var enc encoding.Encoding
+	var name string
+	if label := params["charset"]; label != "" {
+		enc, name = charset.Lookup(label)
+	} else if mediaType == "text/html" || mediaType == "application/xhtml+xml" {
+		br := bufio.NewReaderSize(resp.Body, charsetSniffLen)
+		preview, err := br.Peek(charsetSniffLen)
+		if err != nil && !errors.Is(err, io.EOF) {
+			return fmt.Errorf("read response failed: %w", err)
+		}
+		enc, name, _ = charset.DetermineEncoding(preview, contentType)
+		resp.Body = &decodedBody{Reader: br, closers: []io.Closer{resp.Body}}
+	}
+	if enc == nil || enc == encoding.Nop || name == "utf-8" {
+		return nil
+	}
+
+	resp.Body = &decodedBody{
+		Reader:  transform.NewReader(resp.Body, enc.NewDecoder()),
+		closers: []io.Closer{resp.Body},
+	}
+	params["charset"] = "utf-8"
+	resp.Header.Set("Content-Type", mime.FormatMediaType(mediaType, params))
+	resp.Header.Del("Content-Length")
+	resp.ContentLength = -1
+	return nil
+}
+
+// isTextMediaType 判断是否为需要转换编码的文本类型
+func isTextMediaType(mediaType string) bool {
+	switch {
+	case strings.HasPrefix(mediaType, "text/"),
+		strings.HasSuffix(mediaType, "+json"),
+		strings.HasSuffix(mediaType, "+xml"):
+		return true
+	}
+	switch mediaType {
+	case "application/json", "application/xml", "application/javascript", "application/x-www-form-urlencoded":
+		return true
+	}
+	return false
+}
+
+// Close 按相反顺序关闭解码器和原始响应体
+func (b *decodedBody) Close() error {
+	var errs []error
+	for i := len(b.closers) - 1; i >= 0; i-- {
+		errs = append(errs, b.closers[i].Close())
+	}
+	return errors.Join(errs...)
+}
+
+// Close 实现 io.Closer
+func (z zstdCloser) Close() error {
+	z.Decoder.Close()
+	return nil
+}
@@ -0,0 +1,511 @@
+package restyx
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/go-resty/resty/v2"
+	"github.com/tedwangl/go-util/pkg/utils/pool"
+)
+
+const (
+	// minChunkSize 分块下载时每块的最小字节数（文件较小时减少分块数）
+	minChunkSize = 1 << 20
+	// downloadStateInterval 保存断点状态的间隔
+	downloadStateInterval = time.Second
+	// downloadBufferSize 写入文件的缓冲区大小
+	downloadBufferSize = 32 << 10
+)
+
+type (
+	// DownloadOption 大文件下载选项
+	DownloadOption func(*downloadOptions)
+
+	// DownloadProgress 下载进度
+	DownloadProgress struct {
+		Total      int64         // 文件大小（未知时为 -1）
+		Downloaded int64         // 已下载字节数（包括断点续传前已下载的部分）
+		Resumed    int64         // 断点续传前已下载的字节数
+		Elapsed    time.Duration // 本次下载耗时
+		Done       bool          // 是否已完成（最后一次回调）
+	}
+
+	downloadOptions struct {
+		ctx              context.Context
+		chunks           int
+		newHash          func() hash.Hash
+		checksum         string
+		progress         func(DownloadProgress)
+		progressInterval time.Duration
+		requestOptions   []RequestOption
+	}
+
+	// downloadState 断点续传状态，保存在 <path>.part.json
+	downloadState struct {
+		URL          string           `json:"url"`
+		Size         int64            `json:"size"`
+		ETag         string           `json:"etag,omitempty"`
+		LastModified string           `json:"last_modified,omitempty"`
+		Chunks       []*downloadChunk `json:"chunks"`
+	}
+
+	// downloadChunk 文件分块，下载 [Start, End] 区间，Done 为已写入的字节数
+	downloadChunk struct {
+		Start int64 `json:"start"`
+		End   int64 `json:"end"`
+		Done  int64 `json:"done"`
+	}
+
+	// downloadTarget 探测到的下载目标信息
+	downloadTarget struct {
+		size         int64
+		ranged       bool // 是否支持 Range 请求
+		etag         string
+		lastModified string
+	}
+
+	// countingReader 统计读取的字节数
+	countingReader struct {
+		r io.Reader
+		n *atomic.Int64
+	}
+)
+
+// ErrChecksumMismatch 下载文件的摘要与期望值不一致
+var ErrChecksumMismatch = errors.New("checksum mismatch")
+
+// WithParallelChunks 设置并行下载的分块数（默认 4，服务端不支持 Range 时单连接下载）
+func WithParallelChunks(n int) DownloadOption {
+	return func(o *downloadOptions) {
+		o.chunks = max(n, 1)
+	}
+}
+
+// WithChecksum 下载完成后校验文件摘要，expected 为十六进制字符串
+//
+//	client.DownloadLarge(url, path, WithChecksum(sha256.New, "9f86d0..."))
+func WithChecksum(newHash func() hash.Hash, expected string) DownloadOption {
+	return func(o *downloadOptions) {
+		o.newHash = newHash
+		o.checksum = strings.ToLower(strings.TrimSpace(expected))
+	}
+}
+
+// WithProgress 设置进度回调（按 interval 调用，完成时再调用一次，默认间隔 500ms）
+func WithProgress(fn func(DownloadProgress), interval time.Duration) DownloadOption {
+	return func(o *downloadOptions) {
+		o.progress = fn
+		if interval > 0 {
+			o.progressInterval = interval
+		}
+	}
+}
+
+// WithDownloadContext 设置下载的上下文，取消后保留已下载的部分用于续传
+func WithDownloadContext(ctx context.Context) DownloadOption {
+	return func(o *downloadOptions) {
+		o.ctx = ctx
+	}
+}
+
+// WithDownloadRequestOptions 设置每个分块请求的选项（如请求头、认证）
+func WithDownloadRequestOptions(options ...RequestOption) DownloadOption {
+	return func(o *downloadOptions) {
+		o.requestOptions = append(o.requestOptions, options...)
+	}
+}
+
+// DownloadLarge 使用多个 Range 请求并行下载大文件
+// 下载中的数据写入 <path>.part，断点状态保存在 <path>.part.json，中断后再次调用会从断点继续
+// （服务端文件的大小、ETag 或 Last-Modified 变化时重新下载）；完成并通过校验后重命名为 path
+// 分块读取失败时从已下载的位置重试，最多 RetryCount 次
+func (c *Client) DownloadLarge(url, filePath string, options ...DownloadOption) error {
+	opts := &downloadOptions{
+		ctx:              context.Background(),
+		chunks:           4,
+		progressInterval: 500 * time.Millisecond,
+	}
+	for _, option := range options {
+		option(opts)
+	}
+
+	startTime := time.Now()
+	logFields := []any{
+		"method", "GET",
+		"url", url,
+		"file_path", filePath,
+	}
+
+	err := c.downloadLarge(url, filePath, opts)
+	logFields = append(logFields, "duration_ms", time.Since(startTime).Milliseconds())
+	if err != nil {
+		c.logger.Error("File download failed", append(logFields, "error", err)...)
+		return err
+	}
+
+	c.logger.Info("File downloaded successfully", logFields...)
+	return nil
+}
+
+// downloadLarge 探测、下载、校验并重命名
+func (c *Client) downloadLarge(url, filePath string, opts *downloadOptions) error {
+	partPath := filePath + ".part"
+	statePath := partPath + ".json"
+
+	target, err := c.probeDownload(url, opts)
+	if err != nil {
+		return err
+	}
+
+	if target.ranged && target.size > 0 {
+		err = c.downloadRanged(url, partPath, statePath, target, opts)
+	} else {
+		os.Remove(statePath)
+		err = c.downloadStream(url, partPath, target, opts)
+	}
+	if err != nil {
+		return err
+	}
+
+	if opts.newHash != nil {
+		if err := verifyChecksum(partPath, opts.newHash(), opts.checksum); err != nil {
+			os.Remove(partPath)
+			os.Remove(statePath)
+			return err
+		}
+	}
+
+	if err := os.Rename(partPath, filePath); err != nil {
+		return fmt.Errorf("rename downloaded file failed: %w", err)
+	}
+	os.Remove(statePath)
+	return nil
+}
+
+// newDownloadRequest 创建分块请求（不解析响应体）
+func (c *Client) newDownloadRequest(ctx context.Context, opts *downloadOptions) *resty.Request {
+	req := c.client.R()
+	for _, option := range opts.requestOptions {
+		option(req)
+	}
+	WithContext(ctx)(req)
+	req.SetContext(context.WithValue(req.Context(), keepCharsetKey{}, true))
+	return req.SetDoNotParseResponse(true)
+}
+
+// probeDownload 请求第一个字节，获取文件大小和是否支持 Range
+func (c *Client) probeDownload(url string, opts *downloadOptions) (*downloadTarget, error) {
+	defer c.conns.begin()()
+
+	resp, err := c.newDownloadRequest(opts.ctx, opts).SetHeader("Range", "bytes=0-0").Get(url)
+	if err != nil {
+		return nil, fmt.Errorf("file download failed: %w", err)
+	}
+	resp.RawResponse.Body.Close()
+
+	target := &downloadTarget{
+		size:         resp.RawResponse.ContentLength,
+		etag:         resp.Header().Get("ETag"),
+		lastModified: resp.Header().Get("Last-Modified"),
+	}
+	switch resp.StatusCode() {
+	case http.StatusPartialContent:
+		// Content-Range: bytes 0-0/12345
+		if _, total, ok := strings.Cut(resp.Header().Get("Content-Range"), "/"); ok {
+			if size, err := strconv.ParseInt(total, 10, 64); err == nil {
+				target.size, target.ranged = size, true
+			}
+		}
+	case http.StatusOK:
+	default:
+		return nil, fmt.Errorf("file download failed with status code: %d", resp.StatusCode())
+	}
+	return target, nil
+}
+
+// downloadRanged 分块并行下载，已有匹配的断点状态时继续下载
+func (c *Client) downloadRanged(url, partPath, statePath string, target *downloadTarget, opts *downloadOptions) error {
+	state := loadDownloadState(statePath, partPath, url, target)
+	if state == nil {
+		state = newDownloadState(url, target, opts.chunks)
+	}
+
+	f, err := os.OpenFile(partPath, os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		return fmt.Errorf("open file failed: %w", err)
+	}
+	defer f.Close()
+	if err := f.Truncate(target.size); err != nil {
+		return fmt.Errorf("allocate file failed: %w", err)
+	}
+
+	var mu sync.Mutex // 保护 state 中各分块的 Done
+	var downloaded atomic.Int64
+	var pending []*downloadChunk
+	for _, chunk := range state.Chunks {
+		downloaded.Add(chunk.Done)
+		if chunk.Start+chunk.Done <= chunk.End {
+			pending = append(pending, chunk)
+		}
+	}
+	resumed := downloaded.Load()
+
+	// 定期保存断点状态和回调进度，结束时再保存一次
+	save := func() error {
+		mu.Lock()
+		defer mu.Unlock()
+		return state.save(statePath)
+	}
+	stop := c.trackDownload(opts, target.size, resumed, &downloaded, save)
+
+	_, err = pool.Collect(opts.ctx, pending, len(pending), func(ctx context.Context, chunk *downloadChunk) (struct{}, error) {
+		return struct{}{}, c.downloadChunkWithRetry(ctx, url, f, chunk, &mu, &downloaded, opts)
+	})
+	stop(err == nil)
+	if saveErr := save(); err == nil && saveErr != nil {
+		err = fmt.Errorf("save download state failed: %w", saveErr)
+	}
+	if err != nil {
+		return err
+	}
+	return f.Sync()
+}
+
+// downloadChunkWithRetry 下载分块，失败时从已下载的位置重试
+func (c *Client) downloadChunkWithRetry(ctx context.Context, url string, f *os.File, chunk *downloadChunk, mu *sync.Mutex, downloaded *atomic.Int64, opts *downloadOptions) error {
+	for attempt := 0; ; attempt++ {
+		err := c.downloadChunk(ctx, url, f, chunk, mu, downloaded, opts)
+		if err == nil || ctx.Err() != nil || attempt >= c.retryCount {
+			return err
+		}
+		c.logger.Warn("Chunk download failed, retrying", "url", url, "start", chunk.Start, "end", chunk.End, "attempt", attempt+1, "error", err)
+
+		select {
+		case <-time.After(c.client.RetryWaitTime):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// downloadChunk 请求分块剩余的区间并写入文件
+func (c *Client) downloadChunk(ctx context.Context, url string, f *os.File, chunk *downloadChunk, mu *sync.Mutex, downloaded *atomic.Int64, opts *downloadOptions) error {
+	defer c.conns.begin()()
+
+	mu.Lock()
+	offset := chunk.Start + chunk.Done
+	mu.Unlock()
+
+	resp, err := c.newDownloadRequest(ctx, opts).
+		SetHeader("Range", fmt.Sprintf("bytes=%d-%d", offset, chunk.End)).
+		Get(url)
+	if err != nil {
+		return fmt.Errorf("chunk download failed: %w", err)
+	}
+	body := resp.RawResponse.Body
+	defer body.Close()
+
+	if resp.StatusCode() != http.StatusPartialContent {
+		return fmt.Errorf("chunk download failed with status code: %d", resp.StatusCode())
+	}
+	if !strings.HasPrefix(resp.Header().Get("Content-Range"), fmt.Sprintf("bytes %d-", offset)) {
+		return fmt.Errorf("chunk download failed: unexpected Content-Range: %s", resp.Header().Get("Content-Range"))
+	}
+
+	buf := make([]byte, downloadBufferSize)
+	for offset <= chunk.End {
+		n, err := body.Read(buf[:min(int64(len(buf)), chunk.End-offset+1)])
+		if n > 0 {
+			if _, werr := f.WriteAt(buf[:n], offset); werr != nil {
+				return fmt.Errorf("write file failed: %w", werr)
+			}
+			offset += int64(n)
+			downloaded.Add(int64(n))
+			mu.Lock()
+			chunk.Done += int64(n)
+			mu.Unlock()
+		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("chunk download failed: %w", err)
+		}
+	}
+	if offset <= chunk.End {
+		return fmt.Errorf("chunk download failed: %w", io.ErrUnexpectedEOF)
+	}
+	return nil
+}
+
+// downloadStream 服务端不支持 Range 时单连接下载（不支持断点续传）
+func (c *Client) downloadStream(url, partPath string, target *downloadTarget, opts *downloadOptions) error {
+	defer c.conns.begin()()
+
+	resp, err := c.newDownloadRequest(opts.ctx, opts).Get(url)
+	if err != nil {
+		return fmt.Errorf("file download failed: %w", err)
+	}
+	body := resp.RawResponse.Body
+	defer body.Close()
+
+	if resp.StatusCode() < 200 || resp.StatusCode() >= 300 {
+		return fmt.Errorf("file download failed with status code: %d", resp.StatusCode())
+	}
+
+	f, err := os.Create(partPath)
+	if err != nil {
+		return fmt.Errorf("create file failed: %w", err)
+	}
+	defer f.Close()
+
+	var downloaded atomic.Int64
+	stop := c.trackDownload(opts, target.size, 0, &downloaded, nil)
+	_, err = io.Copy(f, &countingReader{r: body, n: &downloaded})
+	stop(err == nil)
+	if err != nil {
+		return fmt.Errorf("file download failed: %w", err)
+	}
+	return f.Sync()
+}
+
+// trackDownload 启动定期保存状态和回调进度，返回的函数停止并回调最后一次进度
+func (c *Client) trackDownload(opts *downloadOptions, total, resumed int64, downloaded *atomic.Int64, save func() error) func(done bool) {
+	if opts.progress == nil && save == nil {
+		return func(bool) {}
+	}
+
+	start := time.Now()
+	report := func(done bool) {
+		if opts.progress != nil {
+			opts.progress(DownloadProgress{
+				Total:      total,
+				Downloaded: downloaded.Load(),
+				Resumed:    resumed,
+				Elapsed:    time.Since(start),
+				Done:       done,
+			})
+		}
+	}
+
+	stopCh := make(chan struct{})
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		progressTicker := time.NewTicker(opts.progressInterval)
+		defer progressTicker.Stop()
+		saveTicker := time.NewTicker(downloadStateInterval)
+		defer saveTicker.Stop()
+
+		for {
+			select {
+			case <-progressTicker.C:
+				report(false)
+			case <-saveTicker.C:
+				if save != nil {
+					if err := save(); err != nil {
+						c.logger.Warn("Save download state failed", "error", err)
+					}
+				}
+			case <-stopCh:
+				return
+			}
+		}
+	}()
+
+	return func(done bool) {
+		close(stopCh)
+		<-stopped
+		report(done)
+	}
+}
+
+// newDownloadState 按分块数划分文件（每块不小于 minChunkSize）
+func newDownloadState(url string, target *downloadTarget, chunks int) *downloadState {
+	chunks = int(min(int64(chunks), max((target.size+minChunkSize-1)/minChunkSize, 1)))
+	size := (target.size + int64(chunks) - 1) / int64(chunks)
+
+	state := &downloadState{
+		URL:          url,
+		Size:         target.size,
+		ETag:         target.etag,
+		LastModified: target.lastModified,
+	}
+	for start := int64(0); start < target.size; start += size {
+		state.Chunks = append(state.Chunks, &downloadChunk{Start: start, End: min(start+size, target.size) - 1})
+	}
+	return state
+}
+
+// loadDownloadState 读取断点状态，与服务端文件不一致或 .part 文件不存在时返回 nil
+func loadDownloadState(statePath, partPath, url string, target *downloadTarget) *downloadState {
+	data, err := os.ReadFile(statePath)
+	if err != nil {
+		return nil
+	}
+	var state downloadState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil
+	}
+	if state.URL != url || state.Size != target.size || state.ETag != target.etag || state.LastModified != target.lastModified {
+		return nil
+	}
+	if info, err := os.Stat(partPath); err != nil || info.Size() != target.size {
+		return nil
+	}
+	for _, chunk := range state.Chunks {
+		if chunk.Done < 0 || chunk.Start+chunk.Done > chunk.End+1 {
+			return nil
+		}
+	}
+	return &state
+}
+
+// save 写入断点状态（先写临时文件再重命名）
+func (s *downloadState) save(path string) error {
+	data, err := json.Marshal(s)
+	if err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// verifyChecksum 计算文件摘要并与期望值比较
+func verifyChecksum(path string, h hash.Hash, expected string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("open file failed: %w", err)
+	}
+	defer f.Close()
+
+	if _, err := io.Copy(h, f); err != nil {
+		return fmt.Errorf("read file failed: %w", err)
+	}
+	if actual := hex.EncodeToString(h.Sum(nil)); actual != expected {
+		return fmt.Errorf("%w: expected %s, got %s", ErrChecksumMismatch, expected, actual)
+	}
+	return nil
+}
+
+// Read 实现 io.Reader
+func (r *countingReader) Read(p []byte) (int, error) {
+	n, err := r.r.Read(p)
+	r.n.Add(int64(n))
+	return n, err
+}
@@ -0,0 +1,105 @@
+package restyx
+
+import (
+	"context"
+	"net"
+	"net/http/httptrace"
+	"sync"
+	"sync/atomic"
+
+	"github.com/go-resty/resty/v2"
+)
+
+type (
+	// PoolStats 连接池统计
+	PoolStats struct {
+		Open       int64 `json:"open"`        // 当前打开的连接数
+		Active     int64 `json:"active"`      // 正在处理请求的连接数（按进行中的请求计算）
+		Idle       int64 `json:"idle"`        // 空闲连接数
+		Dials      int64 `json:"dials"`       // 累计新建连接数
+		DialErrors int64 `json:"dial_errors"` // 累计建立连接失败次数
+		Reused     int64 `json:"reused"`      // 累计复用空闲连接的次数
+	}
+
+	// connTracker 统计连接的建立、关闭和复用
+	connTracker struct {
+		open       atomic.Int64
+		inflight   atomic.Int64
+		dials      atomic.Int64
+		dialErrors atomic.Int64
+		reused     atomic.Int64
+	}
+
+	// trackedConn 关闭时更新打开连接数
+	trackedConn struct {
+		net.Conn
+		tracker *connTracker
+		once    sync.Once
+	}
+
+	// dialFunc 建立连接的函数
+	dialFunc func(ctx context.Context, network, addr string) (net.Conn, error)
+
+	// reuseTraceKey 标记请求上下文已添加复用统计（重试时不重复添加）
+	reuseTraceKey struct{}
+)
+
+// PoolStats 返回连接池统计，用于区分慢请求是网络问题还是服务端问题
+// Active 只统计通过 Client 方法发出的请求（不包括 NewRequest 创建的原始请求）
+func (c *Client) PoolStats() PoolStats {
+	t := c.conns
+	stats := PoolStats{
+		Open:       t.open.Load(),
+		Active:     t.inflight.Load(),
+		Dials:      t.dials.Load(),
+		DialErrors: t.dialErrors.Load(),
+		Reused:     t.reused.Load(),
+	}
+	stats.Active = min(stats.Active, stats.Open)
+	stats.Idle = stats.Open - stats.Active
+	return stats
+}
+
+// wrapDial 包装连接函数，统计新建和关闭的连接
+func (t *connTracker) wrapDial(dial dialFunc) dialFunc {
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		conn, err := dial(ctx, network, addr)
+		if err != nil {
+			t.dialErrors.Add(1)
+			return nil, err
+		}
+		t.dials.Add(1)
+		t.open.Add(1)
+		return &trackedConn{Conn: conn, tracker: t}, nil
+	}
+}
+
+// traceReuse 请求中间件，统计复用的连接
+func (t *connTracker) traceReuse(_ *resty.Client, req *resty.Request) error {
+	ctx := req.Context()
+	if ctx.Value(reuseTraceKey{}) != nil {
+		return nil
+	}
+
+	trace := &httptrace.ClientTrace{
+		GotConn: func(info httptrace.GotConnInfo) {
+			if info.Reused {
+				t.reused.Add(1)
+			}
+		},
+	}
+	req.SetContext(httptrace.WithClientTrace(context.WithValue(ctx, reuseTraceKey{}, true), trace))
+	return nil
+}
+
+// begin 记录一个进行中的请求，返回结束函数
+func (t *connTracker) begin() func() {
+	t.inflight.Add(1)
+	return func() { t.inflight.Add(-1) }
+}
+
+// Close 关闭连接并更新打开连接数
+func (c *trackedConn) Close() error {
+	c.once.Do(func() { c.tracker.open.Add(-1) })
+	return c.Conn.Close()
+}
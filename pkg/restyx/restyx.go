@@ -5,8 +5,10 @@ import (
 	"crypto/tls"
 	"crypto/x509"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"net"
 	"net/http"
 	"net/url"
 	"os"
@@ -15,6 +17,7 @@ import (
 	"time"
 
 	"github.com/go-resty/resty/v2"
+	"github.com/tedwangl/go-util/pkg/utils/pool"
 )
 
 // Logger 日志接口
@@ -43,6 +46,12 @@ type (
 		returnErrorOnNon2xx  bool
 		reqInterceptors      []RequestInterceptor
 		respInterceptors     []ResponseInterceptor
+		conns                *connTracker
+		transport            *http.Transport
+		retryCount           int    // 默认重试次数（WithRetryCount 可按请求减少）
+		routePrefix          string // 路由路径前缀
+		routes               map[string]*route
+		routesMu             sync.RWMutex
 	}
 
 	// Response 响应封装
@@ -51,6 +60,20 @@ type (
 		Body       []byte        // 响应体
 		Headers    http.Header   // 响应头
 		Time       time.Duration // 请求耗时
+		Trace      *TraceInfo    // 耗时分解（使用 WithTrace 时）
+	}
+
+	// TraceInfo 请求耗时分解
+	TraceInfo struct {
+		DNSLookup    time.Duration // DNS 解析
+		TCPConnect   time.Duration // TCP 连接
+		TLSHandshake time.Duration // TLS 握手
+		ServerTime   time.Duration // 连接建立到收到首字节（TTFB）
+		ResponseTime time.Duration // 首字节到读取完响应
+		TotalTime    time.Duration // 总耗时
+		ConnReused   bool          // 是否复用连接
+		Attempt      int           // 第几次尝试（重试时大于 1）
+		RemoteAddr   string        // 服务端地址
 	}
 	// RequestOption 请求选项
 	RequestOption func(*resty.Request)
@@ -73,6 +96,10 @@ type (
 		TLSCACert            string            // TLS CA 证书路径
 		InsecureSkipVerify   bool              // 跳过 TLS 验证
 		EnableCookieJar      bool              // 启用 Cookie 管理
+		EnableTrace          bool              // 记录所有请求的耗时分解（日志中输出 DNS、连接、TLS、首字节耗时）
+		DecompressResponse   bool              // 自动解压 gzip、deflate、br、zstd 响应（请求自行设置 Accept-Encoding 时不处理）
+		ConvertCharset       bool              // 将非 UTF-8 的文本响应转换为 UTF-8（按 Content-Type 的 charset，HTML 还会检测 <meta>）
+		RoutePrefix          string            // 命名路由的公共路径前缀（如 /api/v1）
 	}
 )
 
@@ -92,6 +119,8 @@ func DefaultConfig() Config {
 		MaxIdleConns:         100,
 		MaxConnsPerHost:      100,
 		IdleConnTimeout:      90 * time.Second,
+		DecompressResponse:   true,
+		ConvertCharset:       true,
 	}
 }
 
@@ -103,17 +132,22 @@ func New(config Config, logger Logger) *Client {
 
 	client := resty.New()
 	client.SetTimeout(config.Timeout)
+	// RetryCount 为所有请求的重试上限，重试条件按 WithRetryCount 减少单个请求的重试次数
 	client.SetRetryCount(config.RetryCount)
 	client.SetRetryWaitTime(config.RetryWaitTime)
 	client.SetRetryMaxWaitTime(config.RetryMaxWaitTime)
 
-	// 配置连接池和 TLS
+	// 配置连接池和 TLS，统计连接的建立和复用
+	conns := &connTracker{}
+	dialer := &net.Dialer{Timeout: 30 * time.Second, KeepAlive: 30 * time.Second}
 	transport := &http.Transport{
+		DialContext:         conns.wrapDial(dialer.DialContext),
 		MaxIdleConns:        config.MaxIdleConns,
 		MaxConnsPerHost:     config.MaxConnsPerHost,
 		IdleConnTimeout:     config.IdleConnTimeout,
 		MaxIdleConnsPerHost: config.MaxConnsPerHost,
 	}
+	client.OnBeforeRequest(conns.traceReuse)
 
 	// 配置代理
 	if config.ProxyURL != "" {
@@ -147,7 +181,15 @@ func New(config Config, logger Logger) *Client {
 	}
 
 	transport.TLSClientConfig = tlsConfig
-	client.SetTransport(transport)
+	if config.DecompressResponse || config.ConvertCharset {
+		client.SetTransport(&decodingTransport{
+			base:           transport,
+			decompress:     config.DecompressResponse,
+			convertCharset: config.ConvertCharset,
+		})
+	} else {
+		client.SetTransport(transport)
+	}
 
 	// 启用 Cookie Jar
 	if config.EnableCookieJar {
@@ -164,20 +206,33 @@ func New(config Config, logger Logger) *Client {
 		client.SetBaseURL(config.BaseURL)
 	}
 
-	// 重试条件：网络错误或 5xx
+	if config.EnableTrace {
+		client.EnableTrace()
+	}
+
+	c := &Client{
+		client:               client,
+		logger:               logger,
+		slowRequestThreshold: config.SlowRequestThreshold,
+		returnErrorOnNon2xx:  config.ReturnErrorOnNon2xx,
+		conns:                conns,
+		transport:            transport,
+		retryCount:           config.RetryCount,
+		routePrefix:          config.RoutePrefix,
+	}
+
+	// 重试条件：网络错误或 5xx，且未超过重试次数（契约校验失败不重试）
 	client.AddRetryCondition(func(r *resty.Response, err error) bool {
+		if !c.retryAllowed(r) || errors.Is(err, ErrContractViolation) {
+			return false
+		}
 		if err != nil {
 			return true
 		}
 		return r.StatusCode() >= 500
 	})
 
-	return &Client{
-		client:               client,
-		logger:               logger,
-		slowRequestThreshold: config.SlowRequestThreshold,
-		returnErrorOnNon2xx:  config.ReturnErrorOnNon2xx,
-	}
+	return c
 }
 
 // WithHeader 设置请求头
@@ -289,9 +344,20 @@ func WithAuthToken(headerName, token string) RequestOption {
 	}
 }
 
-// WithContext 设置请求上下文
+// WithTrace 记录请求耗时分解（DNS、连接、TLS、首字节）
+func WithTrace() RequestOption {
+	return func(r *resty.Request) {
+		r.EnableTrace()
+	}
+}
+
+// WithContext 设置请求上下文（保留之前的 WithTimeout、WithRetryCount 设置）
 func WithContext(ctx context.Context) RequestOption {
 	return func(r *resty.Request) {
+		if s, ok := r.Context().Value(requestSettingsKey{}).(*requestSettings); ok {
+			r.SetContext(context.WithValue(ctx, requestSettingsKey{}, s))
+			return
+		}
 		r.SetContext(ctx)
 	}
 }
@@ -316,12 +382,14 @@ func (r *Response) String() string {
 
 // doRequest 执行 HTTP 请求
 func (c *Client) doRequest(method, url string, options ...RequestOption) (*Response, error) {
+	defer c.conns.begin()()
 	startTime := time.Now()
 
 	req := c.client.R()
 	for _, option := range options {
 		option(req)
 	}
+	defer applyTimeout(req)()
 
 	// 执行请求拦截器
 	for _, interceptor := range c.reqInterceptors {
@@ -366,6 +434,7 @@ func (c *Client) doRequest(method, url string, options ...RequestOption) (*Respo
 		Body:       resp.Body(),
 		Headers:    resp.Header(),
 		Time:       duration,
+		Trace:      traceInfo(req),
 	}
 
 	// 执行响应拦截器
@@ -427,12 +496,15 @@ func (c *Client) Options(url string, options ...RequestOption) (*Response, error
 
 // DownloadFile 下载文件
 func (c *Client) DownloadFile(url, filePath string, options ...RequestOption) error {
+	defer c.conns.begin()()
 	startTime := time.Now()
 
 	req := c.client.R()
 	for _, option := range options {
 		option(req)
 	}
+	defer applyTimeout(req)()
+	req.SetContext(context.WithValue(req.Context(), keepCharsetKey{}, true))
 
 	rsp, err := req.SetOutput(filePath).Get(url)
 	duration := time.Since(startTime)
@@ -467,12 +539,14 @@ func (c *Client) UploadFile(url, paramName, filePath string, options ...RequestO
 
 // Stream 流式处理响应
 func (c *Client) Stream(method, url string, callback func(io.Reader) error, options ...RequestOption) error {
+	defer c.conns.begin()()
 	startTime := time.Now()
 
 	req := c.client.R()
 	for _, option := range options {
 		option(req)
 	}
+	defer applyTimeout(req)()
 
 	req.SetDoNotParseResponse(true)
 
@@ -608,45 +682,48 @@ func (c *Client) Batch(ctx context.Context, requests []BatchRequest, concurrency
 	}
 
 	resultChan := make(chan BatchResponse, len(requests))
-	sem := make(chan struct{}, concurrency) // 信号量控制并发
-	var wg sync.WaitGroup
-
-	for i, req := range requests {
-		wg.Add(1)
-		go func(idx int, r BatchRequest) {
-			defer wg.Done()
-
-			// 获取信号量
-			select {
-			case sem <- struct{}{}:
-				defer func() { <-sem }()
-			case <-ctx.Done():
-				resultChan <- BatchResponse{
-					Index: idx,
-					Error: ctx.Err(),
-				}
-				return
-			}
+	results := pool.Run(ctx, requests, concurrency, func(_ context.Context, r BatchRequest) (*Response, error) {
+		return c.doRequest(r.Method, r.URL, r.Options...)
+	})
 
-			// 执行请求
-			resp, err := c.doRequest(r.Method, r.URL, r.Options...)
+	// 全部请求完成后关闭 channel
+	go func() {
+		defer close(resultChan)
+		for r := range results {
 			resultChan <- BatchResponse{
-				Index:    idx,
-				Response: resp,
-				Error:    err,
+				Index:    r.Index,
+				Response: r.Value,
+				Error:    r.Err,
 			}
-		}(i, req)
-	}
-
-	// 等待所有请求完成后关闭 channel
-	go func() {
-		wg.Wait()
-		close(resultChan)
+		}
 	}()
 
 	return resultChan
 }
 
+// traceInfo 转换请求的 trace 信息（未启用 trace 时返回 nil）
+func traceInfo(req *resty.Request) *TraceInfo {
+	ti := req.TraceInfo()
+	if ti.TotalTime == 0 {
+		return nil
+	}
+
+	trace := &TraceInfo{
+		DNSLookup:    ti.DNSLookup,
+		TCPConnect:   ti.TCPConnTime,
+		TLSHandshake: ti.TLSHandshake,
+		ServerTime:   ti.ServerTime,
+		ResponseTime: ti.ResponseTime,
+		TotalTime:    ti.TotalTime,
+		ConnReused:   ti.IsConnReused,
+		Attempt:      ti.RequestAttempt,
+	}
+	if ti.RemoteAddr != nil {
+		trace.RemoteAddr = ti.RemoteAddr.String()
+	}
+	return trace
+}
+
 // logRequest 记录请求日志
 func (c *Client) logRequest(method, url string, reqID any, resp *Response, duration time.Duration) {
 	fields := []any{
@@ -660,6 +737,19 @@ func (c *Client) logRequest(method, url string, reqID any, resp *Response, durat
 		fields = append(fields, "request_id", fmt.Sprintf("%v", reqID))
 	}
 
+	if t := resp.Trace; t != nil {
+		fields = append(fields,
+			"dns_ms", t.DNSLookup.Milliseconds(),
+			"connect_ms", t.TCPConnect.Milliseconds(),
+			"tls_ms", t.TLSHandshake.Milliseconds(),
+			"ttfb_ms", t.ServerTime.Milliseconds(),
+			"conn_reused", t.ConnReused,
+		)
+		if t.RemoteAddr != "" {
+			fields = append(fields, "remote_addr", t.RemoteAddr)
+		}
+	}
+
 	if duration > c.slowRequestThreshold {
 		c.logger.Warn("Slow HTTP request", fields...)
 	} else if resp.StatusCode >= 400 {
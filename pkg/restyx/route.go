@@ -0,0 +1,220 @@
+package restyx
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/go-resty/resty/v2"
+)
+
+type (
+	// route 已注册的路由
+	route struct {
+		name     string
+		method   string
+		path     string   // 含 RoutePrefix 的路径模板
+		params   []string // 路径参数名（按出现顺序）
+		defaults []RequestOption
+	}
+
+	// requestSettings 请求级别的超时和重试设置，保存在请求上下文中
+	requestSettings struct {
+		timeout    time.Duration
+		retryCount int // 小于 0 时使用客户端的 RetryCount
+	}
+
+	// requestSettingsKey 请求上下文中 requestSettings 的键
+	requestSettingsKey struct{}
+)
+
+// WithTimeout 设置单个请求的超时（包括重试，在客户端超时之内生效）
+func WithTimeout(timeout time.Duration) RequestOption {
+	return func(r *resty.Request) {
+		settingsOf(r).timeout = timeout
+	}
+}
+
+// WithRetryCount 设置单个请求的重试次数（0 为不重试），只能减少，不超过客户端的 RetryCount
+func WithRetryCount(count int) RequestOption {
+	return func(r *resty.Request) {
+		settingsOf(r).retryCount = max(count, 0)
+	}
+}
+
+// settingsOf 返回请求的设置，不存在时创建
+func settingsOf(r *resty.Request) *requestSettings {
+	if s, ok := r.Context().Value(requestSettingsKey{}).(*requestSettings); ok {
+		return s
+	}
+	s := &requestSettings{retryCount: -1}
+	r.SetContext(context.WithValue(r.Context(), requestSettingsKey{}, s))
+	return s
+}
+
+// applyTimeout 按 WithTimeout 为请求设置截止时间，返回的函数在请求结束后调用
+func applyTimeout(r *resty.Request) context.CancelFunc {
+	s, ok := r.Context().Value(requestSettingsKey{}).(*requestSettings)
+	if !ok || s.timeout <= 0 {
+		return func() {}
+	}
+	ctx, cancel := context.WithTimeout(r.Context(), s.timeout)
+	r.SetContext(ctx)
+	return cancel
+}
+
+// retryAllowed 判断请求是否还能重试（WithRetryCount 优先，否则使用客户端的 RetryCount）
+// 没有响应时无法确定重试次数，不重试
+func (c *Client) retryAllowed(r *resty.Response) bool {
+	if r == nil || r.Request == nil {
+		return false
+	}
+	limit := c.retryCount
+	if s, ok := r.Request.Context().Value(requestSettingsKey{}).(*requestSettings); ok && s.retryCount >= 0 {
+		limit = s.retryCount
+	}
+	return r.Request.Attempt <= limit
+}
+
+// Route 注册命名路由，template 为 "[METHOD] /path/{param}"（省略方法时为 GET），路径前会加上 RoutePrefix
+// defaults 为该路由的默认请求选项（如 WithTimeout、WithRetryCount、WithHeader），调用时传入的选项在其后生效
+//
+//	client.Route("getUser", "GET /users/{id}", WithTimeout(5*time.Second), WithRetryCount(1))
+//	resp, err := client.Call("getUser", map[string]string{"id": "42"})
+func (c *Client) Route(name, template string, defaults ...RequestOption) error {
+	if name == "" {
+		return fmt.Errorf("route name is empty")
+	}
+
+	method, path := http.MethodGet, strings.TrimSpace(template)
+	if i := strings.IndexByte(path, ' '); i > 0 {
+		method, path = strings.ToUpper(path[:i]), strings.TrimSpace(path[i+1:])
+	}
+	if !isSupportedMethod(method) {
+		return fmt.Errorf("route %s: unsupported HTTP method: %s", name, method)
+	}
+	params, err := parseRouteParams(path)
+	if err != nil {
+		return fmt.Errorf("route %s: %w", name, err)
+	}
+
+	c.routesMu.Lock()
+	defer c.routesMu.Unlock()
+
+	if _, ok := c.routes[name]; ok {
+		return fmt.Errorf("route %s already registered", name)
+	}
+	if c.routes == nil {
+		c.routes = make(map[string]*route)
+	}
+	c.routes[name] = &route{
+		name:     name,
+		method:   method,
+		path:     joinPath(c.routePrefix, path),
+		params:   params,
+		defaults: defaults,
+	}
+	return nil
+}
+
+// Call 调用命名路由，params 必须与模板中的路径参数完全一致（缺少或多余都会返回错误）
+func (c *Client) Call(name string, params map[string]string, options ...RequestOption) (*Response, error) {
+	c.routesMu.RLock()
+	rt, ok := c.routes[name]
+	c.routesMu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("route %s not registered", name)
+	}
+
+	var missing, extra []string
+	for _, p := range rt.params {
+		if _, ok := params[p]; !ok {
+			missing = append(missing, p)
+		}
+	}
+	for p := range params {
+		if !slices.Contains(rt.params, p) {
+			extra = append(extra, p)
+		}
+	}
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("route %s: missing path params: %s", name, strings.Join(missing, ", "))
+	}
+	if len(extra) > 0 {
+		sort.Strings(extra)
+		return nil, fmt.Errorf("route %s: unknown path params: %s", name, strings.Join(extra, ", "))
+	}
+
+	opts := make([]RequestOption, 0, len(rt.defaults)+len(options)+1)
+	opts = append(opts, rt.defaults...)
+	opts = append(opts, options...)
+	if len(params) > 0 {
+		opts = append(opts, WithPathParams(params))
+	}
+	return c.doRequest(rt.method, rt.path, opts...)
+}
+
+// Routes 返回已注册的路由名（按名称排序）
+func (c *Client) Routes() []string {
+	c.routesMu.RLock()
+	defer c.routesMu.RUnlock()
+
+	names := make([]string, 0, len(c.routes))
+	for name := range c.routes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// parseRouteParams 解析路径模板中的 {param}，参数名为空、重复或括号不匹配时返回错误
+func parseRouteParams(path string) ([]string, error) {
+	var params []string
+	for rest := path; ; {
+		open := strings.IndexAny(rest, "{}")
+		if open < 0 {
+			return params, nil
+		}
+		if rest[open] == '}' {
+			return nil, fmt.Errorf("unexpected '}' in template: %s", path)
+		}
+		end := strings.IndexAny(rest[open+1:], "{}")
+		if end < 0 || rest[open+1+end] != '}' {
+			return nil, fmt.Errorf("unclosed '{' in template: %s", path)
+		}
+		param := rest[open+1 : open+1+end]
+		if param == "" {
+			return nil, fmt.Errorf("empty path param in template: %s", path)
+		}
+		if slices.Contains(params, param) {
+			return nil, fmt.Errorf("duplicate path param %s in template: %s", param, path)
+		}
+		params = append(params, param)
+		rest = rest[open+end+2:]
+	}
+}
+
+// joinPath 拼接路径前缀和路径，避免重复或缺少 "/"
+func joinPath(prefix, path string) string {
+	if prefix == "" {
+		return path
+	}
+	if strings.HasPrefix(path, "http://") || strings.HasPrefix(path, "https://") {
+		return path
+	}
+	return strings.TrimRight(prefix, "/") + "/" + strings.TrimLeft(path, "/")
+}
+
+// isSupportedMethod 判断是否为 doRequest 支持的方法
+func isSupportedMethod(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodPost, http.MethodPut, http.MethodDelete,
+		http.MethodPatch, http.MethodHead, http.MethodOptions:
+		return true
+	}
+	return false
+}
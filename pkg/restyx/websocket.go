@@ -0,0 +1,440 @@
+package restyx
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/tedwangl/go-util/pkg/utils/retry"
+)
+
+// WebSocket 消息类型
+const (
+	TextMessage   = websocket.TextMessage
+	BinaryMessage = websocket.BinaryMessage
+)
+
+const (
+	defaultWSPingInterval  = 30 * time.Second
+	defaultWSWriteTimeout  = 10 * time.Second
+	defaultWSMinBackoff    = 500 * time.Millisecond
+	defaultWSMaxBackoff    = 30 * time.Second
+	defaultWSHandshakeTime = 30 * time.Second
+)
+
+// ErrWSClosed 连接已关闭
+var ErrWSClosed = errors.New("websocket connection closed")
+
+type (
+	// WSOption WebSocket 连接选项
+	WSOption func(*wsOptions)
+
+	wsOptions struct {
+		header       http.Header
+		subprotocols []string
+		pingInterval time.Duration // 0 表示不发送 ping
+		writeTimeout time.Duration
+		reconnect    bool
+		maxRetries   int // 每次断线的最大重连次数，0 表示不限
+		minBackoff   time.Duration
+		maxBackoff   time.Duration
+		onConnect    func(*WSConn) error
+	}
+
+	// WSConn WebSocket 连接，共享 Client 的 TLS、代理、请求头和认证配置
+	// 读写方法可以在不同 goroutine 中调用，但同一时间只能有一个读和一个写
+	WSConn struct {
+		client *Client
+		url    string
+		dialer *websocket.Dialer
+		header http.Header
+		opts   wsOptions
+
+		mu      sync.Mutex // 保护 conn 和 version
+		conn    *websocket.Conn
+		version int // 每次重连加 1，用于避免并发读写时重复重连
+		writeMu sync.Mutex
+
+		ctx    context.Context
+		cancel context.CancelFunc
+	}
+)
+
+// WithWSHeader 设置握手请求头
+func WithWSHeader(key, value string) WSOption {
+	return func(o *wsOptions) {
+		o.header.Set(key, value)
+	}
+}
+
+// WithWSSubprotocols 设置子协议
+func WithWSSubprotocols(protocols ...string) WSOption {
+	return func(o *wsOptions) {
+		o.subprotocols = protocols
+	}
+}
+
+// WithWSPingInterval 设置 ping 间隔（默认 30s），超过两个间隔未收到 pong 时认为连接断开，0 表示不发送 ping
+// 需要有 goroutine 持续读取消息，pong 才会被处理
+func WithWSPingInterval(d time.Duration) WSOption {
+	return func(o *wsOptions) {
+		o.pingInterval = d
+	}
+}
+
+// WithWSWriteTimeout 设置写超时（默认 10s，0 表示不超时）
+func WithWSWriteTimeout(d time.Duration) WSOption {
+	return func(o *wsOptions) {
+		o.writeTimeout = d
+	}
+}
+
+// WithWSReconnect 断线后自动重连，maxRetries 为每次断线的最大重连次数（0 表示不限）
+// 重连间隔从 minBackoff 开始指数增长到 maxBackoff，为 0 时使用默认值 500ms、30s
+func WithWSReconnect(maxRetries int, minBackoff, maxBackoff time.Duration) WSOption {
+	return func(o *wsOptions) {
+		o.reconnect = true
+		o.maxRetries = maxRetries
+		if minBackoff > 0 {
+			o.minBackoff = minBackoff
+		}
+		if maxBackoff > 0 {
+			o.maxBackoff = maxBackoff
+		}
+	}
+}
+
+// WithWSOnConnect 连接（包括重连）成功后调用，常用于认证或重新订阅
+func WithWSOnConnect(fn func(*WSConn) error) WSOption {
+	return func(o *wsOptions) {
+		o.onConnect = fn
+	}
+}
+
+// Dial 建立 WebSocket 连接，相对地址基于 BaseURL，http/https 自动转换为 ws/wss
+func (c *Client) Dial(rawURL string, opts ...WSOption) (*WSConn, error) {
+	o := wsOptions{
+		header:       http.Header{},
+		pingInterval: defaultWSPingInterval,
+		writeTimeout: defaultWSWriteTimeout,
+		minBackoff:   defaultWSMinBackoff,
+		maxBackoff:   defaultWSMaxBackoff,
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	wsURL, err := c.wsURL(rawURL)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	ws := &WSConn{
+		client: c,
+		url:    wsURL,
+		dialer: c.wsDialer(o.subprotocols),
+		header: c.wsHeader(o.header),
+		opts:   o,
+		ctx:    ctx,
+		cancel: cancel,
+	}
+
+	conn, err := ws.connect()
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	ws.conn = conn
+
+	if err := ws.onConnect(); err != nil {
+		ws.Close()
+		return nil, err
+	}
+
+	if o.pingInterval > 0 {
+		go ws.keepalive()
+	}
+	return ws, nil
+}
+
+// wsURL 拼接 BaseURL 并转换为 ws/wss 地址
+func (c *Client) wsURL(rawURL string) (string, error) {
+	if !strings.Contains(rawURL, "://") && c.client.BaseURL != "" {
+		rawURL = strings.TrimRight(c.client.BaseURL, "/") + "/" + strings.TrimLeft(rawURL, "/")
+	}
+
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return "", fmt.Errorf("invalid websocket url: %w", err)
+	}
+	switch u.Scheme {
+	case "http":
+		u.Scheme = "ws"
+	case "https":
+		u.Scheme = "wss"
+	case "ws", "wss":
+	default:
+		return "", fmt.Errorf("unsupported websocket scheme: %s", u.Scheme)
+	}
+	return u.String(), nil
+}
+
+// wsDialer 使用 Client 的代理、TLS、Cookie 和超时配置创建 dialer
+func (c *Client) wsDialer(subprotocols []string) *websocket.Dialer {
+	dialer := &websocket.Dialer{
+		Proxy:            http.ProxyFromEnvironment,
+		HandshakeTimeout: defaultWSHandshakeTime,
+		Subprotocols:     subprotocols,
+		Jar:              c.client.GetClient().Jar,
+	}
+	if timeout := c.client.GetClient().Timeout; timeout > 0 {
+		dialer.HandshakeTimeout = timeout
+	}
+	if transport := c.transport; transport != nil {
+		dialer.Proxy = transport.Proxy
+		if transport.TLSClientConfig != nil {
+			dialer.TLSClientConfig = transport.TLSClientConfig.Clone()
+		}
+	}
+	return dialer
+}
+
+// wsHeader 合并 Client 的默认请求头、认证信息和连接选项中的请求头
+func (c *Client) wsHeader(extra http.Header) http.Header {
+	header := http.Header{}
+	for key, values := range c.client.Header {
+		// 握手请求没有请求体
+		if http.CanonicalHeaderKey(key) == "Content-Type" {
+			continue
+		}
+		header[key] = append([]string(nil), values...)
+	}
+
+	switch {
+	case c.client.Token != "":
+		scheme := c.client.AuthScheme
+		if scheme == "" {
+			scheme = "Bearer"
+		}
+		header.Set("Authorization", scheme+" "+c.client.Token)
+	case c.client.UserInfo != nil:
+		auth := c.client.UserInfo.Username + ":" + c.client.UserInfo.Password
+		header.Set("Authorization", "Basic "+base64.StdEncoding.EncodeToString([]byte(auth)))
+	}
+
+	for key, values := range extra {
+		header[key] = values
+	}
+	return header
+}
+
+// connect 建立连接并设置 pong 处理
+func (ws *WSConn) connect() (*websocket.Conn, error) {
+	conn, resp, err := ws.dialer.DialContext(ws.ctx, ws.url, ws.header)
+	if err != nil {
+		if resp != nil {
+			return nil, fmt.Errorf("websocket dial failed with status code %d: %w", resp.StatusCode, err)
+		}
+		return nil, fmt.Errorf("websocket dial failed: %w", err)
+	}
+
+	if ws.opts.pingInterval > 0 {
+		pongWait := ws.opts.pingInterval * 2
+		_ = conn.SetReadDeadline(time.Now().Add(pongWait))
+		conn.SetPongHandler(func(string) error {
+			return conn.SetReadDeadline(time.Now().Add(pongWait))
+		})
+	}
+
+	ws.client.logger.Debug("WebSocket connected", "url", ws.url)
+	return conn, nil
+}
+
+// onConnect 调用连接成功回调
+func (ws *WSConn) onConnect() error {
+	if ws.opts.onConnect == nil {
+		return nil
+	}
+	if err := ws.opts.onConnect(ws); err != nil {
+		return fmt.Errorf("websocket on connect failed: %w", err)
+	}
+	return nil
+}
+
+// current 返回当前连接和版本
+func (ws *WSConn) current() (*websocket.Conn, int, error) {
+	ws.mu.Lock()
+	defer ws.mu.Unlock()
+
+	if ws.ctx.Err() != nil {
+		return nil, 0, ErrWSClosed
+	}
+	return ws.conn, ws.version, nil
+}
+
+// reconnect 断线重连，version 为出错时的连接版本，已被其他 goroutine 重连时直接返回
+func (ws *WSConn) reconnect(version int, cause error) error {
+	if ws.ctx.Err() != nil {
+		return ErrWSClosed
+	}
+	if !ws.opts.reconnect {
+		return cause
+	}
+
+	ws.mu.Lock()
+	if ws.version != version {
+		ws.mu.Unlock()
+		return nil
+	}
+	_ = ws.conn.Close()
+
+	attempts := ws.opts.maxRetries
+	if attempts <= 0 {
+		attempts = math.MaxInt
+	}
+	ws.client.logger.Warn("WebSocket disconnected, reconnecting", "url", ws.url, "error", cause)
+
+	conn, err := retry.DoValue(ws.ctx, func(ctx context.Context, attempt int) (*websocket.Conn, error) {
+		return ws.connect()
+	},
+		retry.Attempts(attempts),
+		retry.ExponentialBackoff(ws.opts.minBackoff, ws.opts.maxBackoff),
+		retry.Jitter(),
+		retry.OnRetry(func(attempt int, err error, wait time.Duration) {
+			ws.client.logger.Warn("WebSocket reconnect failed",
+				"url", ws.url, "attempt", attempt+1, "wait_ms", wait.Milliseconds(), "error", err)
+		}),
+	)
+	if err != nil {
+		ws.mu.Unlock()
+		ws.client.logger.Error("WebSocket reconnect gave up", "url", ws.url, "error", err)
+		if ws.ctx.Err() != nil {
+			return ErrWSClosed
+		}
+		return fmt.Errorf("websocket reconnect failed: %w", err)
+	}
+
+	ws.conn = conn
+	ws.version++
+	ws.mu.Unlock()
+
+	ws.client.logger.Info("WebSocket reconnected", "url", ws.url)
+	return ws.onConnect()
+}
+
+// ReadMessage 读取一条消息，启用重连时断线后自动重连并继续读取（断线期间的消息会丢失）
+func (ws *WSConn) ReadMessage() (int, []byte, error) {
+	for {
+		conn, version, err := ws.current()
+		if err != nil {
+			return 0, nil, err
+		}
+
+		msgType, data, err := conn.ReadMessage()
+		if err == nil {
+			return msgType, data, nil
+		}
+		if websocket.IsCloseError(err, websocket.CloseNormalClosure) && !ws.opts.reconnect {
+			return 0, nil, ErrWSClosed
+		}
+		if err := ws.reconnect(version, err); err != nil {
+			return 0, nil, err
+		}
+	}
+}
+
+// ReadJSON 读取一条消息并解析为 JSON
+func (ws *WSConn) ReadJSON(v any) error {
+	_, data, err := ws.ReadMessage()
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+// WriteMessage 发送一条消息，启用重连时断线后重连并重发一次
+func (ws *WSConn) WriteMessage(msgType int, data []byte) error {
+	version, err := ws.write(msgType, data)
+	if err == nil {
+		return nil
+	}
+
+	if err := ws.reconnect(version, err); err != nil {
+		return err
+	}
+	_, err = ws.write(msgType, data)
+	return err
+}
+
+// WriteJSON 将 v 编码为 JSON 并以文本消息发送
+func (ws *WSConn) WriteJSON(v any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return ws.WriteMessage(TextMessage, data)
+}
+
+// write 带超时写入消息，返回写入时的连接版本
+func (ws *WSConn) write(msgType int, data []byte) (int, error) {
+	conn, version, err := ws.current()
+	if err != nil {
+		return 0, err
+	}
+
+	ws.writeMu.Lock()
+	defer ws.writeMu.Unlock()
+
+	if ws.opts.writeTimeout > 0 {
+		_ = conn.SetWriteDeadline(time.Now().Add(ws.opts.writeTimeout))
+	}
+	return version, conn.WriteMessage(msgType, data)
+}
+
+// keepalive 定时发送 ping
+func (ws *WSConn) keepalive() {
+	ticker := time.NewTicker(ws.opts.pingInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ws.ctx.Done():
+			return
+		case <-ticker.C:
+			conn, _, err := ws.current()
+			if err != nil {
+				return
+			}
+			// 失败时由读写方法发现断线并重连，零值 deadline 表示不超时
+			var deadline time.Time
+			if ws.opts.writeTimeout > 0 {
+				deadline = time.Now().Add(ws.opts.writeTimeout)
+			}
+			if err := conn.WriteControl(websocket.PingMessage, nil, deadline); err != nil {
+				ws.client.logger.Debug("WebSocket ping failed", "url", ws.url, "error", err)
+			}
+		}
+	}
+}
+
+// Close 发送关闭帧并关闭连接，停止重连和 ping
+func (ws *WSConn) Close() error {
+	ws.cancel()
+
+	ws.mu.Lock()
+	defer ws.mu.Unlock()
+
+	msg := websocket.FormatCloseMessage(websocket.CloseNormalClosure, "")
+	_ = ws.conn.WriteControl(websocket.CloseMessage, msg, time.Now().Add(time.Second))
+	return ws.conn.Close()
+}
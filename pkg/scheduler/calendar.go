@@ -0,0 +1,131 @@
+package scheduler
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/robfig/cron/v3"
+)
+
+// maxSkips 连续排除的天数上限，超过后认为不会再执行（避免排除规则覆盖所有时间时死循环）
+const maxSkips = 10000
+
+type (
+	// Schedule 调度计划，返回 t 之后的下一次执行时间（零值表示不再执行）
+	Schedule = cron.Schedule
+
+	// JobOption 任务选项
+	JobOption func(*jobOptions) error
+
+	// jobOptions 任务的时区和日历排除
+	jobOptions struct {
+		location     *time.Location
+		skipWeekends bool
+		skipDates    map[string]bool // 2006-01-02
+	}
+
+	// calendarSchedule 排除指定日期的调度计划
+	calendarSchedule struct {
+		schedule Schedule
+		opts     *jobOptions
+	}
+)
+
+// InLocation 按指定时区解析 cron 表达式（等价于 CRON_TZ= 前缀，表达式中已指定时以表达式为准）
+func InLocation(loc *time.Location) JobOption {
+	return func(o *jobOptions) error {
+		if loc == nil {
+			return fmt.Errorf("location is nil")
+		}
+		o.location = loc
+		return nil
+	}
+}
+
+// InTimezone 按时区名称解析 cron 表达式，如 Asia/Shanghai
+func InTimezone(name string) JobOption {
+	return func(o *jobOptions) error {
+		loc, err := time.LoadLocation(name)
+		if err != nil {
+			return fmt.Errorf("invalid timezone %s: %w", name, err)
+		}
+		o.location = loc
+		return nil
+	}
+}
+
+// SkipWeekends 周六、周日不执行（按任务时区判断）
+func SkipWeekends() JobOption {
+	return func(o *jobOptions) error {
+		o.skipWeekends = true
+		return nil
+	}
+}
+
+// SkipDates 指定日期不执行（如节假日），格式 2006-01-02，按任务时区判断
+func SkipDates(dates ...string) JobOption {
+	return func(o *jobOptions) error {
+		if o.skipDates == nil {
+			o.skipDates = make(map[string]bool, len(dates))
+		}
+		for _, date := range dates {
+			if _, err := time.Parse(time.DateOnly, date); err != nil {
+				return fmt.Errorf("invalid date %s (expected 2006-01-02)", date)
+			}
+			o.skipDates[date] = true
+		}
+		return nil
+	}
+}
+
+// Parse 解析 cron 表达式（支持 @every、CRON_TZ= 前缀）并应用任务选项
+func (s *Scheduler) Parse(spec string, opts ...JobOption) (Schedule, error) {
+	o := &jobOptions{}
+	for _, opt := range opts {
+		if err := opt(o); err != nil {
+			return nil, err
+		}
+	}
+
+	if o.location != nil && !strings.HasPrefix(spec, "CRON_TZ=") && !strings.HasPrefix(spec, "TZ=") {
+		spec = "CRON_TZ=" + o.location.String() + " " + spec
+	}
+
+	schedule, err := s.parser.Parse(spec)
+	if err != nil {
+		return nil, fmt.Errorf("invalid spec %q: %w", spec, err)
+	}
+
+	if !o.skipWeekends && len(o.skipDates) == 0 {
+		return schedule, nil
+	}
+	if o.location == nil {
+		o.location = time.Local
+	}
+	return &calendarSchedule{schedule: schedule, opts: o}, nil
+}
+
+// Next 下一次不在排除日期内的执行时间
+// 执行时间在排除日期内时直接从第二天 0 点继续查找，避免间隔很短时逐次跳过
+func (c *calendarSchedule) Next(t time.Time) time.Time {
+	for i := 0; i < maxSkips; i++ {
+		t = c.schedule.Next(t)
+		if t.IsZero() || !c.opts.excluded(t) {
+			return t
+		}
+
+		y, m, d := t.In(c.opts.location).Date()
+		t = time.Date(y, m, d+1, 0, 0, 0, 0, c.opts.location).Add(-time.Nanosecond)
+	}
+	return time.Time{}
+}
+
+// excluded 是否为排除的日期
+func (o *jobOptions) excluded(t time.Time) bool {
+	t = t.In(o.location)
+	if o.skipWeekends && (t.Weekday() == time.Saturday || t.Weekday() == time.Sunday) {
+		return true
+	}
+	return o.skipDates[t.Format(time.DateOnly)]
+}
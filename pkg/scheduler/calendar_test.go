@@ -0,0 +1,36 @@
+package scheduler
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCalendarSkipWeekendsShortInterval(t *testing.T) {
+	loc := time.UTC
+	s := NewScheduler()
+	schedule, err := s.Parse("@every 10s", InLocation(loc), SkipWeekends())
+	assert.NoError(t, err)
+
+	// 2026-10-17 是周六，周末的执行次数远超 maxSkips
+	next := schedule.Next(time.Date(2026, 10, 17, 0, 0, 5, 0, loc))
+	assert.Equal(t, time.Monday, next.Weekday())
+	assert.Equal(t, time.Date(2026, 10, 19, 0, 0, 0, 0, loc), next.Truncate(time.Minute))
+}
+
+func TestCalendarSkipDates(t *testing.T) {
+	loc, err := time.LoadLocation("Asia/Shanghai")
+	assert.NoError(t, err)
+
+	s := NewScheduler()
+	schedule, err := s.Parse("*/5 * * * *", InLocation(loc), SkipWeekends(), SkipDates("2026-10-19"))
+	assert.NoError(t, err)
+
+	next := schedule.Next(time.Date(2026, 10, 16, 23, 58, 0, 0, loc))
+	assert.Equal(t, time.Date(2026, 10, 20, 0, 0, 0, 0, loc), next.In(loc))
+
+	// 不在排除日期内时不受影响
+	next = schedule.Next(time.Date(2026, 10, 20, 9, 1, 0, 0, loc))
+	assert.Equal(t, time.Date(2026, 10, 20, 9, 5, 0, 0, loc), next.In(loc))
+}
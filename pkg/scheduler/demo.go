@@ -7,7 +7,7 @@ import (
 )
 
 func main() {
-	fmt.Println("=== 简化版演示 ===\n")
+	fmt.Print("=== 简化版演示 ===\n\n")
 
 	s := NewScheduler()
 
@@ -32,7 +32,7 @@ func main() {
 }
 
 func main1() {
-	fmt.Println("=== 实际业务场景演示 ===\n")
+	fmt.Print("=== 实际业务场景演示 ===\n\n")
 
 	s := NewScheduler()
 
@@ -99,7 +99,7 @@ func main1() {
 }
 
 func main2() {
-	fmt.Println("=== 动态添加任务演示 ===\n")
+	fmt.Print("=== 动态添加任务演示 ===\n\n")
 
 	// 1. 标准格式（5 字段）
 	s1 := NewScheduler()
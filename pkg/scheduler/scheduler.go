@@ -3,6 +3,7 @@ package scheduler
 import (
 	"context"
 	"fmt"
+	"runtime/debug"
 	"sync"
 	"time"
 
@@ -13,9 +14,35 @@ type (
 	// Scheduler 定时任务调度器
 	Scheduler struct {
 		cron   *cron.Cron
-		jobs   map[string]cron.EntryID
+		parser cron.ScheduleParser
+		jobs   map[string]*jobEntry
 		mu     sync.RWMutex
 		logger Logger
+		hooks  Hooks
+	}
+
+	// Hooks 任务执行钩子（在任务所在的 goroutine 中调用）
+	Hooks struct {
+		BeforeRun func(name string)
+		AfterRun  func(name string, err error, duration time.Duration) // panic 时 err 为 *PanicError
+		OnPanic   func(name string, recovered any, stack []byte)
+	}
+
+	// PanicError 任务 panic 转换的错误
+	PanicError struct {
+		Value any
+		Stack []byte
+	}
+
+	// jobEntry 已注册的任务及最近一次执行结果
+	jobEntry struct {
+		id           cron.EntryID
+		spec         string
+		lastRun      time.Time
+		lastDuration time.Duration
+		lastErr      error
+		runs         int
+		failures     int
 	}
 
 	// Job 任务函数（带 context，用于需要取消的场景）
@@ -38,10 +65,15 @@ type (
 
 	// JobInfo 任务信息
 	JobInfo struct {
-		Name     string    // 任务名称
-		Next     time.Time // 下次执行时间
-		Prev     time.Time // 上次执行时间
-		Schedule string    // Cron 表达式
+		Name         string        // 任务名称
+		Next         time.Time     // 下次执行时间
+		Prev         time.Time     // 上次执行时间
+		Schedule     string        // Cron 表达式
+		LastRun      time.Time     // 最近一次执行开始时间（包括 RunOnce）
+		LastDuration time.Duration // 最近一次执行耗时
+		LastError    string        // 最近一次执行的错误（成功时为空）
+		Runs         int           // 执行次数
+		Failures     int           // 失败次数（包括 panic）
 	}
 )
 
@@ -60,11 +92,19 @@ func WithLogger(logger Logger) Option {
 	}
 }
 
+// WithHooks 设置任务执行钩子
+func WithHooks(hooks Hooks) Option {
+	return func(s *Scheduler) {
+		s.hooks = hooks
+	}
+}
+
 // WithSeconds 支持秒级精度（默认分钟级）
 // 注意：启用后所有 cron 表达式必须是 6 字段格式（秒 分 时 日 月 周）
 func WithSeconds() Option {
 	return func(s *Scheduler) {
 		s.cron = cron.New(cron.WithSeconds())
+		s.parser = cron.NewParser(cron.Second | cron.Minute | cron.Hour | cron.Dom | cron.Month | cron.Dow | cron.Descriptor)
 	}
 }
 
@@ -72,7 +112,8 @@ func WithSeconds() Option {
 func NewScheduler(opts ...Option) *Scheduler {
 	s := &Scheduler{
 		cron:   cron.New(),
-		jobs:   make(map[string]cron.EntryID),
+		parser: cron.NewParser(cron.Minute | cron.Hour | cron.Dom | cron.Month | cron.Dow | cron.Descriptor),
+		jobs:   make(map[string]*jobEntry),
 		logger: &defaultLogger{},
 	}
 
@@ -84,12 +125,12 @@ func NewScheduler(opts ...Option) *Scheduler {
 }
 
 // AddFunc 添加简单任务（推荐，无需处理 context）
-func (s *Scheduler) AddFunc(spec, name string, job SimpleJob) error {
+func (s *Scheduler) AddFunc(spec, name string, job SimpleJob, opts ...JobOption) error {
 	// 包装为 Job 类型
 	wrappedJob := func(ctx context.Context) error {
 		return job()
 	}
-	return s.AddJob(spec, name, wrappedJob)
+	return s.AddJob(spec, name, wrappedJob, opts...)
 }
 
 // AddJob 添加定时任务（需要 context 的场景）
@@ -100,6 +141,9 @@ func (s *Scheduler) AddFunc(spec, name string, job SimpleJob) error {
 //     例如: "*/5 * * * * *" (每 5 秒)
 //   - 预定义（通用）: @every 1h, @daily, @hourly, @weekly, @monthly, @yearly
 //     例如: "@every 10s" (每 10 秒)
+//   - 时区前缀: "CRON_TZ=Asia/Shanghai 0 9 * * *"（也可使用 InTimezone 选项）
+//
+// opts: 时区（InLocation、InTimezone）和日历排除（SkipWeekends、SkipDates）
 //
 // name: 任务名称（唯一标识）
 // job: 任务函数
@@ -108,7 +152,7 @@ func (s *Scheduler) AddFunc(spec, name string, job SimpleJob) error {
 // - Start() 后仍可动态添加任务
 // - 预定义表达式（@every 等）在任何模式下都有效
 // - 标准 cron 和秒级 cron 不能混用，由创建时的 WithSeconds 决定
-func (s *Scheduler) AddJob(spec, name string, job Job) error {
+func (s *Scheduler) AddJob(spec, name string, job Job, opts ...JobOption) error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
@@ -117,16 +161,19 @@ func (s *Scheduler) AddJob(spec, name string, job Job) error {
 		return fmt.Errorf("job %s already exists", name)
 	}
 
-	// 包装任务函数
-	wrappedJob := s.wrapJob(name, job)
-
-	// 添加到 cron
-	entryID, err := s.cron.AddFunc(spec, wrappedJob)
+	schedule, err := s.Parse(spec, opts...)
 	if err != nil {
 		return fmt.Errorf("failed to add job %s: %w", name, err)
 	}
 
-	s.jobs[name] = entryID
+	// 包装任务函数
+	entry := &jobEntry{spec: spec}
+	wrappedJob := s.wrapJob(name, entry, job)
+
+	// 添加到 cron
+	entry.id = s.cron.Schedule(schedule, cron.FuncJob(wrappedJob))
+
+	s.jobs[name] = entry
 	s.logger.Info("job added", "name", name, "spec", spec)
 
 	return nil
@@ -137,12 +184,12 @@ func (s *Scheduler) RemoveJob(name string) error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	entryID, exists := s.jobs[name]
+	entry, exists := s.jobs[name]
 	if !exists {
 		return fmt.Errorf("job %s not found", name)
 	}
 
-	s.cron.Remove(entryID)
+	s.cron.Remove(entry.id)
 	delete(s.jobs, name)
 	s.logger.Info("job removed", "name", name)
 
@@ -168,47 +215,105 @@ func (s *Scheduler) ListJobs() []JobInfo {
 	defer s.mu.RUnlock()
 
 	jobs := make([]JobInfo, 0, len(s.jobs))
-	for name, entryID := range s.jobs {
-		entry := s.cron.Entry(entryID)
-		jobs = append(jobs, JobInfo{
-			Name:     name,
-			Next:     entry.Next,
-			Prev:     entry.Prev,
-			Schedule: fmt.Sprintf("%v", entry.Schedule),
-		})
+	for name, job := range s.jobs {
+		entry := s.cron.Entry(job.id)
+		info := JobInfo{
+			Name:         name,
+			Next:         entry.Next,
+			Prev:         entry.Prev,
+			Schedule:     job.spec,
+			LastRun:      job.lastRun,
+			LastDuration: job.lastDuration,
+			Runs:         job.runs,
+			Failures:     job.failures,
+		}
+		if job.lastErr != nil {
+			info.LastError = job.lastErr.Error()
+		}
+		jobs = append(jobs, info)
 	}
 
 	return jobs
 }
 
-// wrapJob 包装任务函数，添加日志和错误处理
-func (s *Scheduler) wrapJob(name string, job Job) func() {
+// wrapJob 包装任务函数，添加日志、错误处理和执行记录
+func (s *Scheduler) wrapJob(name string, entry *jobEntry, job Job) func() {
 	return func() {
-		ctx := context.Background()
 		start := time.Now()
 
 		s.logger.Info("job started", "name", name)
 
 		// 执行任务
-		if err := job(ctx); err != nil {
-			s.logger.Error("job failed", err, "name", name, "duration", time.Since(start))
+		err := s.runJob(name, job)
+		duration := time.Since(start)
+
+		s.mu.Lock()
+		entry.lastRun = start
+		entry.lastDuration = duration
+		entry.lastErr = err
+		entry.runs++
+		if err != nil {
+			entry.failures++
+		}
+		s.mu.Unlock()
+
+		if err != nil {
+			s.logger.Error("job failed", err, "name", name, "duration", duration)
 		} else {
-			s.logger.Info("job completed", "name", name, "duration", time.Since(start))
+			s.logger.Info("job completed", "name", name, "duration", duration)
 		}
 	}
 }
 
+// runJob 执行任务并调用钩子，panic 被恢复并转换为 *PanicError，不影响调度器和其他任务
+func (s *Scheduler) runJob(name string, job Job) (err error) {
+	start := time.Now()
+	defer func() {
+		if r := recover(); r != nil {
+			stack := debug.Stack()
+			err = &PanicError{Value: r, Stack: stack}
+			s.logger.Error("job panicked", err, "name", name, "stack", string(stack))
+			if s.hooks.OnPanic != nil {
+				s.callHook(name, func() { s.hooks.OnPanic(name, r, stack) })
+			}
+		}
+		if s.hooks.AfterRun != nil {
+			s.callHook(name, func() { s.hooks.AfterRun(name, err, time.Since(start)) })
+		}
+	}()
+
+	if s.hooks.BeforeRun != nil {
+		s.callHook(name, func() { s.hooks.BeforeRun(name) })
+	}
+	return job(context.Background())
+}
+
+// callHook 调用钩子，钩子本身 panic 时只记录日志
+func (s *Scheduler) callHook(name string, hook func()) {
+	defer func() {
+		if r := recover(); r != nil {
+			s.logger.Error("hook panicked", fmt.Errorf("%v", r), "name", name)
+		}
+	}()
+	hook()
+}
+
+// Error 实现 error 接口
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("panic: %v", e.Value)
+}
+
 // RunOnce 立即执行一次任务（不影响定时调度）
 func (s *Scheduler) RunOnce(name string) error {
 	s.mu.RLock()
-	entryID, exists := s.jobs[name]
+	job, exists := s.jobs[name]
 	s.mu.RUnlock()
 
 	if !exists {
 		return fmt.Errorf("job %s not found", name)
 	}
 
-	entry := s.cron.Entry(entryID)
+	entry := s.cron.Entry(job.id)
 	go entry.Job.Run()
 
 	return nil
@@ -221,7 +326,7 @@ func (s *Scheduler) Delay(duration time.Duration, name string, job SimpleJob) er
 		start := time.Now()
 		s.logger.Info("delayed job started", "name", name, "delay", duration)
 
-		if err := job(); err != nil {
+		if err := s.runJob(name, func(ctx context.Context) error { return job() }); err != nil {
 			s.logger.Error("delayed job failed", err, "name", name, "duration", time.Since(start))
 		} else {
 			s.logger.Info("delayed job completed", "name", name, "duration", time.Since(start))
@@ -16,6 +16,18 @@ func NewConsistentHash() *ConsistentHash {
 	}
 }
 
+// NewConsistentHashWithReplicas 创建指定虚拟节点数的一致性哈希实例
+// 虚拟节点越多，key 分布越均匀（默认 20）
+func NewConsistentHashWithReplicas(replicas int) *ConsistentHash {
+	c := consistent.New()
+	if replicas > 0 {
+		c.NumberOfReplicas = replicas
+	}
+	return &ConsistentHash{
+		c: c,
+	}
+}
+
 // Add 添加节点
 func (ch *ConsistentHash) Add(node string) {
 	ch.c.Add(node)
@@ -0,0 +1,129 @@
+package humanize
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// ByteSize is a number of bytes that formats in binary units.
+type ByteSize uint64
+
+// IEC (binary) units.
+const (
+	B   ByteSize = 1
+	KiB          = B << 10
+	MiB          = KiB << 10
+	GiB          = MiB << 10
+	TiB          = GiB << 10
+	PiB          = TiB << 10
+	EiB          = PiB << 10
+)
+
+// SI (decimal) units.
+const (
+	KB ByteSize = 1000
+	MB          = KB * 1000
+	GB          = MB * 1000
+	TB          = GB * 1000
+	PB          = TB * 1000
+	EB          = PB * 1000
+)
+
+var (
+	binaryUnits = []string{"B", "KiB", "MiB", "GiB", "TiB", "PiB", "EiB"}
+
+	byteUnits = map[string]ByteSize{
+		"":  B,
+		"b": B,
+
+		"k": KiB, "ki": KiB, "kib": KiB, "kb": KB,
+		"m": MiB, "mi": MiB, "mib": MiB, "mb": MB,
+		"g": GiB, "gi": GiB, "gib": GiB, "gb": GB,
+		"t": TiB, "ti": TiB, "tib": TiB, "tb": TB,
+		"p": PiB, "pi": PiB, "pib": PiB, "pb": PB,
+		"e": EiB, "ei": EiB, "eib": EiB, "eb": EB,
+	}
+)
+
+// String returns b in the largest binary unit, e.g. 512B, 1.5KiB, 2GiB.
+func (b ByteSize) String() string {
+	if b < KiB {
+		return strconv.FormatUint(uint64(b), 10) + "B"
+	}
+
+	val, exp := float64(b), 0
+	for val >= 1024 && exp < len(binaryUnits)-1 {
+		val /= 1024
+		exp++
+	}
+	num := strconv.FormatFloat(val, 'f', 1, 64)
+	return strings.TrimSuffix(num, ".0") + binaryUnits[exp]
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (b ByteSize) MarshalText() ([]byte, error) {
+	return []byte(b.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (b *ByteSize) UnmarshalText(text []byte) error {
+	v, err := ParseBytes(string(text))
+	if err != nil {
+		return err
+	}
+
+	*b = v
+	return nil
+}
+
+// Bytes formats n bytes, shorthand for ByteSize(n).String().
+func Bytes(n uint64) string {
+	return ByteSize(n).String()
+}
+
+// ParseBytes parses a human-readable size like "1.5GiB", "10MB" or "512".
+// Units are case-insensitive. IEC units (KiB) and bare letters (K, M, G) are
+// binary, SI units (KB, MB, GB) are decimal. A number without unit is bytes.
+func ParseBytes(s string) (ByteSize, error) {
+	s = strings.TrimSpace(s)
+	i := strings.IndexFunc(s, func(r rune) bool {
+		return !unicode.IsDigit(r) && r != '.'
+	})
+	if i < 0 {
+		i = len(s)
+	}
+
+	num, unit := s[:i], strings.ToLower(strings.TrimSpace(s[i:]))
+	if num == "" {
+		return 0, fmt.Errorf("humanize: invalid size %q", s)
+	}
+
+	mul, ok := byteUnits[unit]
+	if !ok {
+		return 0, fmt.Errorf("humanize: unknown unit %q in size %q", s[i:], s)
+	}
+
+	if !strings.Contains(num, ".") {
+		n, err := strconv.ParseUint(num, 10, 64)
+		if err != nil {
+			return 0, fmt.Errorf("humanize: invalid size %q", s)
+		}
+		if n > uint64(^ByteSize(0)/mul) {
+			return 0, fmt.Errorf("humanize: size %q overflows", s)
+		}
+		return ByteSize(n) * mul, nil
+	}
+
+	f, err := strconv.ParseFloat(num, 64)
+	if err != nil {
+		return 0, fmt.Errorf("humanize: invalid size %q", s)
+	}
+	v := f * float64(mul)
+	if v >= float64(^uint64(0)) {
+		return 0, fmt.Errorf("humanize: size %q overflows", s)
+	}
+
+	return ByteSize(v), nil
+}
@@ -0,0 +1,81 @@
+package humanize
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestByteSizeString(t *testing.T) {
+	cases := []struct {
+		input  ByteSize
+		expect string
+	}{
+		{0, "0B"},
+		{512, "512B"},
+		{KiB, "1KiB"},
+		{1536, "1.5KiB"},
+		{10 * MiB, "10MiB"},
+		{GiB + 512*MiB, "1.5GiB"},
+		{5 * TiB, "5TiB"},
+		{8 * EiB, "8EiB"},
+	}
+
+	for _, each := range cases {
+		t.Run(each.expect, func(t *testing.T) {
+			assert.Equal(t, each.expect, each.input.String())
+		})
+	}
+}
+
+func TestParseBytes(t *testing.T) {
+	cases := []struct {
+		input  string
+		expect ByteSize
+		err    bool
+	}{
+		{"0", 0, false},
+		{"512", 512, false},
+		{"512B", 512, false},
+		{"1K", KiB, false},
+		{"1KiB", KiB, false},
+		{"1kb", KB, false},
+		{"1.5GiB", GiB + 512*MiB, false},
+		{"1.5 GiB", GiB + 512*MiB, false},
+		{"10MB", 10 * MB, false},
+		{"2Ti", 2 * TiB, false},
+		{"", 0, true},
+		{"GiB", 0, true},
+		{"-1K", 0, true},
+		{"1.2.3K", 0, true},
+		{"1XB", 0, true},
+		{"20EiB", 0, true},
+	}
+
+	for _, each := range cases {
+		t.Run(each.input, func(t *testing.T) {
+			v, err := ParseBytes(each.input)
+			if each.err {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, each.expect, v)
+		})
+	}
+}
+
+func TestByteSizeText(t *testing.T) {
+	var v struct {
+		Size ByteSize `json:"size"`
+	}
+	assert.NoError(t, json.Unmarshal([]byte(`{"size":"64MiB"}`), &v))
+	assert.Equal(t, 64*MiB, v.Size)
+
+	data, err := json.Marshal(v)
+	assert.NoError(t, err)
+	assert.Equal(t, `{"size":"64MiB"}`, string(data))
+
+	assert.Error(t, json.Unmarshal([]byte(`{"size":"abc"}`), &v))
+}
@@ -0,0 +1,108 @@
+package humanize
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// Day is 24 hours, daylight saving time is not taken into account.
+	Day = 24 * time.Hour
+	// Week is 7 days.
+	Week = 7 * Day
+)
+
+// ParseDuration parses a duration like time.ParseDuration, with extra units
+// d (day) and w (week), e.g. "1w2d", "1d12h", "-2d".
+func ParseDuration(s string) (time.Duration, error) {
+	orig := s
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return 0, fmt.Errorf("humanize: invalid duration %q", orig)
+	}
+
+	neg := false
+	if s[0] == '-' || s[0] == '+' {
+		neg = s[0] == '-'
+		s = s[1:]
+	}
+	if s == "0" {
+		return 0, nil
+	}
+
+	var d time.Duration
+	for s != "" {
+		// split the leading number and unit
+		i := strings.IndexFunc(s, func(r rune) bool {
+			return (r < '0' || r > '9') && r != '.'
+		})
+		if i <= 0 {
+			return 0, fmt.Errorf("humanize: invalid duration %q", orig)
+		}
+		j := strings.IndexFunc(s[i:], func(r rune) bool {
+			return (r >= '0' && r <= '9') || r == '.'
+		})
+		if j < 0 {
+			j = len(s) - i
+		}
+		num, unit := s[:i], s[i:i+j]
+		s = s[i+j:]
+
+		var part time.Duration
+		switch unit {
+		case "d", "w":
+			f, err := strconv.ParseFloat(num, 64)
+			if err != nil {
+				return 0, fmt.Errorf("humanize: invalid duration %q", orig)
+			}
+			mul := Day
+			if unit == "w" {
+				mul = Week
+			}
+			part = time.Duration(f * float64(mul))
+		default:
+			var err error
+			if part, err = time.ParseDuration(num + unit); err != nil {
+				return 0, fmt.Errorf("humanize: invalid duration %q", orig)
+			}
+		}
+		d += part
+	}
+
+	if neg {
+		return -d, nil
+	}
+	return d, nil
+}
+
+// FormatDuration formats d with days, e.g. "2d3h4m", "1h30m", "1.5s".
+// Durations of one minute or longer are truncated to seconds.
+func FormatDuration(d time.Duration) string {
+	if d < 0 {
+		return "-" + FormatDuration(-d)
+	}
+	if d < time.Minute {
+		return d.String()
+	}
+
+	var sb strings.Builder
+	for _, u := range []struct {
+		unit time.Duration
+		name string
+	}{
+		{Day, "d"},
+		{time.Hour, "h"},
+		{time.Minute, "m"},
+		{time.Second, "s"},
+	} {
+		if n := d / u.unit; n > 0 {
+			sb.WriteString(strconv.FormatInt(int64(n), 10))
+			sb.WriteString(u.name)
+			d -= n * u.unit
+		}
+	}
+
+	return sb.String()
+}
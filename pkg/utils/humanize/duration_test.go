@@ -0,0 +1,64 @@
+package humanize
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseDuration(t *testing.T) {
+	cases := []struct {
+		input  string
+		expect time.Duration
+		err    bool
+	}{
+		{"0", 0, false},
+		{"30s", 30 * time.Second, false},
+		{"1h30m", 90 * time.Minute, false},
+		{"1d", Day, false},
+		{"1.5d", 36 * time.Hour, false},
+		{"2w", 2 * Week, false},
+		{"1w2d3h", Week + 2*Day + 3*time.Hour, false},
+		{"1d500ms", Day + 500*time.Millisecond, false},
+		{"-2d", -2 * Day, false},
+		{" 5m ", 5 * time.Minute, false},
+		{"", 0, true},
+		{"d", 0, true},
+		{"5", 0, true},
+		{"1y", 0, true},
+		{"1..5d", 0, true},
+	}
+
+	for _, each := range cases {
+		t.Run(each.input, func(t *testing.T) {
+			d, err := ParseDuration(each.input)
+			if each.err {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, each.expect, d)
+		})
+	}
+}
+
+func TestFormatDuration(t *testing.T) {
+	cases := []struct {
+		input  time.Duration
+		expect string
+	}{
+		{0, "0s"},
+		{1500 * time.Millisecond, "1.5s"},
+		{90 * time.Minute, "1h30m"},
+		{2*Day + 3*time.Hour + 4*time.Minute, "2d3h4m"},
+		{Week + time.Second + time.Millisecond, "7d1s"},
+		{-36 * time.Hour, "-1d12h"},
+	}
+
+	for _, each := range cases {
+		t.Run(each.expect, func(t *testing.T) {
+			assert.Equal(t, each.expect, FormatDuration(each.input))
+		})
+	}
+}
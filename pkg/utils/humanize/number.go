@@ -0,0 +1,54 @@
+package humanize
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Comma formats n with comma-separated thousands, e.g. 1234567 -> 1,234,567.
+func Comma(n int64) string {
+	s := strconv.FormatInt(n, 10)
+	if n < 0 {
+		return "-" + groupDigits(s[1:])
+	}
+
+	return groupDigits(s)
+}
+
+// CommaFloat formats f with comma-separated thousands, keeping at most prec
+// decimal places with trailing zeros removed, e.g. 1234.5 -> 1,234.5.
+func CommaFloat(f float64, prec int) string {
+	s := strconv.FormatFloat(f, 'f', prec, 64)
+	sign := ""
+	if strings.HasPrefix(s, "-") {
+		sign, s = "-", s[1:]
+	}
+
+	intPart, frac, _ := strings.Cut(s, ".")
+	frac = strings.TrimRight(frac, "0")
+	if frac != "" {
+		frac = "." + frac
+	}
+
+	return sign + groupDigits(intPart) + frac
+}
+
+func groupDigits(s string) string {
+	if len(s) <= 3 {
+		return s
+	}
+
+	var sb strings.Builder
+	head := len(s) % 3
+	if head > 0 {
+		sb.WriteString(s[:head])
+	}
+	for i := head; i < len(s); i += 3 {
+		if sb.Len() > 0 {
+			sb.WriteByte(',')
+		}
+		sb.WriteString(s[i : i+3])
+	}
+
+	return sb.String()
+}
@@ -0,0 +1,36 @@
+package humanize
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestComma(t *testing.T) {
+	cases := []struct {
+		input  int64
+		expect string
+	}{
+		{0, "0"},
+		{999, "999"},
+		{1000, "1,000"},
+		{123456, "123,456"},
+		{1234567, "1,234,567"},
+		{-1234567, "-1,234,567"},
+		{math.MinInt64, "-9,223,372,036,854,775,808"},
+	}
+
+	for _, each := range cases {
+		t.Run(each.expect, func(t *testing.T) {
+			assert.Equal(t, each.expect, Comma(each.input))
+		})
+	}
+}
+
+func TestCommaFloat(t *testing.T) {
+	assert.Equal(t, "1,234.5", CommaFloat(1234.5, 2))
+	assert.Equal(t, "1,234", CommaFloat(1234.001, 2))
+	assert.Equal(t, "-12,345.68", CommaFloat(-12345.678, 2))
+	assert.Equal(t, "0.5", CommaFloat(0.5, 1))
+}
@@ -0,0 +1,48 @@
+package humanize
+
+import (
+	"strconv"
+	"time"
+)
+
+// Ago returns the relative time between t and now, e.g. "3h ago", "in 5m".
+func Ago(t time.Time) string {
+	return RelTime(t, time.Now())
+}
+
+// RelTime returns the relative time of t to now, in the largest whole unit.
+// Past times end with " ago", future times start with "in ", and differences
+// under a second are "just now".
+func RelTime(t, now time.Time) string {
+	d := now.Sub(t)
+	future := d < 0
+	if future {
+		d = -d
+	}
+	if d < time.Second {
+		return "just now"
+	}
+
+	var s string
+	switch {
+	case d < time.Minute:
+		s = strconv.Itoa(int(d/time.Second)) + "s"
+	case d < time.Hour:
+		s = strconv.Itoa(int(d/time.Minute)) + "m"
+	case d < Day:
+		s = strconv.Itoa(int(d/time.Hour)) + "h"
+	case d < Week:
+		s = strconv.Itoa(int(d/Day)) + "d"
+	case d < 30*Day:
+		s = strconv.Itoa(int(d/Week)) + "w"
+	case d < 365*Day:
+		s = strconv.Itoa(int(d/(30*Day))) + "mo"
+	default:
+		s = strconv.Itoa(int(d/(365*Day))) + "y"
+	}
+
+	if future {
+		return "in " + s
+	}
+	return s + " ago"
+}
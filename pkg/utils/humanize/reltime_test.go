@@ -0,0 +1,38 @@
+package humanize
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRelTime(t *testing.T) {
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	cases := []struct {
+		offset time.Duration
+		expect string
+	}{
+		{0, "just now"},
+		{-500 * time.Millisecond, "just now"},
+		{-30 * time.Second, "30s ago"},
+		{-5 * time.Minute, "5m ago"},
+		{-3*time.Hour - 59*time.Minute, "3h ago"},
+		{-2 * Day, "2d ago"},
+		{-15 * Day, "2w ago"},
+		{-90 * Day, "3mo ago"},
+		{-800 * Day, "2y ago"},
+		{5 * time.Minute, "in 5m"},
+		{26 * time.Hour, "in 1d"},
+	}
+
+	for _, each := range cases {
+		t.Run(each.expect, func(t *testing.T) {
+			assert.Equal(t, each.expect, RelTime(now.Add(each.offset), now))
+		})
+	}
+}
+
+func TestAgo(t *testing.T) {
+	assert.Equal(t, "2h ago", Ago(time.Now().Add(-2*time.Hour-time.Minute)))
+}
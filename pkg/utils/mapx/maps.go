@@ -0,0 +1,73 @@
+package mapx
+
+import (
+	"cmp"
+	"slices"
+)
+
+// Keys returns the keys of m in unspecified order.
+func Keys[K comparable, V any](m map[K]V) []K {
+	keys := make([]K, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+
+	return keys
+}
+
+// SortedKeys returns the keys of m in ascending order.
+func SortedKeys[K cmp.Ordered, V any](m map[K]V) []K {
+	keys := Keys(m)
+	slices.Sort(keys)
+	return keys
+}
+
+// Values returns the values of m in unspecified order.
+func Values[K comparable, V any](m map[K]V) []V {
+	values := make([]V, 0, len(m))
+	for _, v := range m {
+		values = append(values, v)
+	}
+
+	return values
+}
+
+// Merge merges maps into a new map, later maps override earlier ones on duplicate keys.
+func Merge[K comparable, V any](maps ...map[K]V) map[K]V {
+	var size int
+	for _, m := range maps {
+		size += len(m)
+	}
+
+	merged := make(map[K]V, size)
+	for _, m := range maps {
+		for k, v := range m {
+			merged[k] = v
+		}
+	}
+
+	return merged
+}
+
+// Invert returns a map with keys and values swapped.
+// If multiple keys share the same value, which key wins is unspecified.
+func Invert[K, V comparable](m map[K]V) map[V]K {
+	inverted := make(map[V]K, len(m))
+	for k, v := range m {
+		inverted[v] = k
+	}
+
+	return inverted
+}
+
+// Filter returns a new map with the entries for which keep returns true.
+func Filter[K comparable, V any](m map[K]V, keep func(K, V) bool) map[K]V {
+	filtered := make(map[K]V)
+	for k, v := range m {
+		if keep(k, v) {
+			filtered[k] = v
+		}
+	}
+
+	return filtered
+}
@@ -0,0 +1,35 @@
+package mapx
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestKeysValues(t *testing.T) {
+	m := map[string]int{"a": 1, "b": 2, "c": 3}
+	assert.ElementsMatch(t, []string{"a", "b", "c"}, Keys(m))
+	assert.ElementsMatch(t, []int{1, 2, 3}, Values(m))
+	assert.Equal(t, []string{"a", "b", "c"}, SortedKeys(m))
+	assert.Empty(t, Keys(map[string]int(nil)))
+}
+
+func TestMerge(t *testing.T) {
+	a := map[string]int{"a": 1, "b": 2}
+	b := map[string]int{"b": 20, "c": 30}
+
+	assert.Equal(t, map[string]int{"a": 1, "b": 20, "c": 30}, Merge(a, b))
+	// inputs are untouched
+	assert.Equal(t, map[string]int{"a": 1, "b": 2}, a)
+	assert.Equal(t, map[string]int{}, Merge[string, int]())
+}
+
+func TestInvert(t *testing.T) {
+	assert.Equal(t, map[int]string{1: "a", 2: "b"}, Invert(map[string]int{"a": 1, "b": 2}))
+}
+
+func TestFilter(t *testing.T) {
+	m := map[string]int{"a": 1, "b": 2, "c": 3}
+	odd := Filter(m, func(_ string, v int) bool { return v%2 == 1 })
+	assert.Equal(t, map[string]int{"a": 1, "c": 3}, odd)
+}
@@ -0,0 +1,207 @@
+package pool
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// Result is the result of processing the item at Index.
+type Result[T any] struct {
+	Index int
+	Value T
+	Err   error
+}
+
+// Run processes items with at most workers goroutines, and streams the results in completion order.
+// Panics in fn are returned as *PanicError. Once ctx is done, the remaining items are not processed
+// and their results carry ctx.Err(). The channel is closed after all items are reported.
+func Run[T, R any](ctx context.Context, items []T, workers int,
+	fn func(ctx context.Context, item T) (R, error)) <-chan Result[R] {
+	results := make(chan Result[R], len(items))
+	indexes := make(chan int)
+
+	if workers <= 0 || workers > len(items) {
+		workers = max(len(items), 1)
+	}
+
+	var wg sync.WaitGroup
+	wg.Add(workers)
+	for i := 0; i < workers; i++ {
+		go func() {
+			defer wg.Done()
+			for idx := range indexes {
+				results <- call(ctx, idx, items[idx], fn)
+			}
+		}()
+	}
+
+	go func() {
+		defer func() {
+			close(indexes)
+			wg.Wait()
+			close(results)
+		}()
+
+		for idx := range items {
+			// select picks randomly when both are ready, so check ctx first to stop dispatching once cancelled
+			if ctx.Err() == nil {
+				select {
+				case indexes <- idx:
+					continue
+				case <-ctx.Done():
+				}
+			}
+
+			for ; idx < len(items); idx++ {
+				results <- Result[R]{Index: idx, Err: ctx.Err()}
+			}
+			return
+		}
+	}()
+
+	return results
+}
+
+// Collect processes items like Run, and returns the values in the order of items.
+// It cancels the remaining items and returns the first error if any item fails.
+func Collect[T, R any](ctx context.Context, items []T, workers int,
+	fn func(ctx context.Context, item T) (R, error)) ([]R, error) {
+	ctx, cancel := context.WithCancelCause(ctx)
+	defer cancel(nil)
+
+	// cancel as soon as an item fails, so the items not yet started are skipped
+	run := func(ctx context.Context, item T) (R, error) {
+		v, err := fn(ctx, item)
+		if err != nil {
+			cancel(err)
+		}
+		return v, err
+	}
+
+	values := make([]R, len(items))
+	var firstErr error
+	for r := range Run(ctx, items, workers, run) {
+		if r.Err != nil {
+			if firstErr == nil {
+				firstErr = r.Err
+				cancel(r.Err)
+			}
+			continue
+		}
+		values[r.Index] = r.Value
+	}
+	if firstErr != nil {
+		// items skipped after a failure may be received before the failure itself
+		if errors.Is(firstErr, context.Canceled) {
+			firstErr = context.Cause(ctx)
+		}
+		return nil, firstErr
+	}
+
+	return values, nil
+}
+
+// Source emits items into a channel, which is closed after all items are sent or ctx is done.
+func Source[T any](ctx context.Context, items ...T) <-chan T {
+	out := make(chan T)
+	go func() {
+		defer close(out)
+		for _, item := range items {
+			select {
+			case out <- item:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return out
+}
+
+// FanOut reads from in with workers goroutines, and fans in the outputs of fn into one channel.
+// The output channel is closed after in is closed and drained, or ctx is done.
+// Stages can be chained to build a pipeline:
+//
+//	urls := pool.Source(ctx, list...)
+//	pages := pool.FanOut(ctx, urls, 8, fetch)
+//	items := pool.FanOut(ctx, pages, 2, parse)
+func FanOut[In, Out any](ctx context.Context, in <-chan In, workers int, fn func(ctx context.Context, v In) Out) <-chan Out {
+	if workers <= 0 {
+		workers = 1
+	}
+
+	out := make(chan Out)
+	var wg sync.WaitGroup
+	wg.Add(workers)
+	for i := 0; i < workers; i++ {
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case v, ok := <-in:
+					if !ok {
+						return
+					}
+					select {
+					case out <- fn(ctx, v):
+					case <-ctx.Done():
+						return
+					}
+				}
+			}
+		}()
+	}
+
+	go func() {
+		wg.Wait()
+		close(out)
+	}()
+
+	return out
+}
+
+// Merge fans in multiple channels into one, which is closed after all inputs are closed or ctx is done.
+func Merge[T any](ctx context.Context, chans ...<-chan T) <-chan T {
+	out := make(chan T)
+	var wg sync.WaitGroup
+	wg.Add(len(chans))
+	for _, ch := range chans {
+		go func(ch <-chan T) {
+			defer wg.Done()
+			for v := range ch {
+				select {
+				case out <- v:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}(ch)
+	}
+
+	go func() {
+		wg.Wait()
+		close(out)
+	}()
+
+	return out
+}
+
+func call[T, R any](ctx context.Context, idx int, item T, fn func(ctx context.Context, item T) (R, error)) (result Result[R]) {
+	result.Index = idx
+	if err := ctx.Err(); err != nil {
+		result.Err = err
+		return result
+	}
+
+	defer func() {
+		if v := recover(); v != nil {
+			result.Err = &PanicError{Value: v, Stack: stack()}
+		}
+	}()
+
+	result.Value, result.Err = fn(ctx, item)
+	return result
+}
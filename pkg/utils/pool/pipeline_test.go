@@ -0,0 +1,136 @@
+package pool
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRun(t *testing.T) {
+	items := []int{1, 2, 3, 4, 5}
+	var indexes []int
+	var sum int
+	for r := range Run(context.Background(), items, 2, func(ctx context.Context, v int) (int, error) {
+		return v * v, nil
+	}) {
+		assert.NoError(t, r.Err)
+		indexes = append(indexes, r.Index)
+		sum += r.Value
+	}
+
+	sort.Ints(indexes)
+	assert.Equal(t, []int{0, 1, 2, 3, 4}, indexes)
+	assert.Equal(t, 55, sum)
+}
+
+func TestRunPanic(t *testing.T) {
+	for r := range Run(context.Background(), []int{1}, 1, func(ctx context.Context, v int) (int, error) {
+		panic("boom")
+	}) {
+		var pe *PanicError
+		assert.ErrorAs(t, r.Err, &pe)
+		assert.Equal(t, "boom", pe.Value)
+	}
+}
+
+func TestRunCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	var count, canceled int
+	for r := range Run(ctx, make([]int, 10), 2, func(ctx context.Context, v int) (int, error) {
+		return v, nil
+	}) {
+		count++
+		if errors.Is(r.Err, context.Canceled) {
+			canceled++
+		}
+	}
+
+	assert.Equal(t, 10, count)
+	assert.Equal(t, 10, canceled)
+}
+
+func TestRunEmpty(t *testing.T) {
+	var count int
+	for range Run(context.Background(), []int(nil), 4, func(ctx context.Context, v int) (int, error) {
+		return v, nil
+	}) {
+		count++
+	}
+	assert.Equal(t, 0, count)
+}
+
+func TestCollect(t *testing.T) {
+	values, err := Collect(context.Background(), []int{3, 1, 2}, 3, func(ctx context.Context, v int) (string, error) {
+		time.Sleep(time.Duration(v) * time.Millisecond)
+		return strconv.Itoa(v), nil
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"3", "1", "2"}, values)
+}
+
+func TestCollectError(t *testing.T) {
+	errTest := errors.New("test")
+	var calls atomic.Int32
+	_, err := Collect(context.Background(), make([]int, 100), 1, func(ctx context.Context, v int) (int, error) {
+		if calls.Add(1) == 3 {
+			return 0, errTest
+		}
+		return v, nil
+	})
+
+	assert.ErrorIs(t, err, errTest)
+	assert.Less(t, calls.Load(), int32(100))
+}
+
+func TestCollectErrorWorkers(t *testing.T) {
+	errTest := errors.New("test")
+	_, err := Collect(context.Background(), make([]int, 100), 8, func(ctx context.Context, v int) (int, error) {
+		return 0, errTest
+	})
+
+	assert.ErrorIs(t, err, errTest)
+}
+
+func TestPipeline(t *testing.T) {
+	ctx := context.Background()
+	nums := Source(ctx, 1, 2, 3, 4)
+	squares := FanOut(ctx, nums, 3, func(ctx context.Context, v int) int { return v * v })
+	strs := FanOut(ctx, squares, 2, func(ctx context.Context, v int) string { return strconv.Itoa(v) })
+
+	var got []string
+	for s := range strs {
+		got = append(got, s)
+	}
+	sort.Strings(got)
+	assert.Equal(t, []string{"1", "16", "4", "9"}, got)
+}
+
+func TestPipelineCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	out := FanOut(ctx, Source(ctx, make([]int, 1000)...), 2, func(ctx context.Context, v int) int { return v })
+
+	<-out
+	cancel()
+	// drains and closes after cancel
+	for range out {
+	}
+}
+
+func TestMerge(t *testing.T) {
+	ctx := context.Background()
+	var got []int
+	for v := range Merge(ctx, Source(ctx, 1, 2), Source(ctx, 3), Source[int](ctx)) {
+		got = append(got, v)
+	}
+	sort.Ints(got)
+	assert.Equal(t, []int{1, 2, 3}, got)
+}
@@ -0,0 +1,163 @@
+package pool
+
+import (
+	"errors"
+	"fmt"
+	"runtime/debug"
+	"sync"
+	"sync/atomic"
+)
+
+var (
+	// ErrPoolClosed is returned when submitting to a closed pool.
+	ErrPoolClosed = errors.New("worker pool is closed")
+	// ErrPoolBusy is returned by TrySubmit when the queue is full.
+	ErrPoolBusy = errors.New("worker pool is busy")
+)
+
+type (
+	// Option customizes a WorkerPool.
+	Option func(*WorkerPool)
+
+	// PanicError is the error converted from a panic in a task.
+	PanicError struct {
+		Value any
+		Stack []byte
+	}
+
+	// A WorkerPool runs tasks with a bounded number of goroutines.
+	// Panics in tasks are recovered and reported to the panic handler,
+	// so one bad task doesn't take down the pool.
+	WorkerPool struct {
+		tasks        chan func()
+		wg           sync.WaitGroup
+		lock         sync.RWMutex
+		closed       bool
+		closeOnce    sync.Once
+		running      atomic.Int32
+		panicHandler func(*PanicError)
+	}
+)
+
+// NewWorkerPool returns a WorkerPool with the given number of workers.
+// The queue size defaults to the number of workers.
+func NewWorkerPool(workers int, opts ...Option) *WorkerPool {
+	if workers <= 0 {
+		workers = 1
+	}
+
+	p := &WorkerPool{
+		tasks: make(chan func(), workers),
+	}
+	for _, opt := range opts {
+		opt(p)
+	}
+
+	p.wg.Add(workers)
+	for i := 0; i < workers; i++ {
+		go p.worker()
+	}
+
+	return p
+}
+
+// WithQueueSize customizes the number of tasks that can wait for a free worker.
+func WithQueueSize(size int) Option {
+	return func(p *WorkerPool) {
+		if size >= 0 {
+			p.tasks = make(chan func(), size)
+		}
+	}
+}
+
+// WithPanicHandler customizes the handler of panics in tasks, panics are ignored by default.
+func WithPanicHandler(fn func(*PanicError)) Option {
+	return func(p *WorkerPool) {
+		p.panicHandler = fn
+	}
+}
+
+// Submit submits a task, blocks if the queue is full.
+func (p *WorkerPool) Submit(task func()) error {
+	p.lock.RLock()
+	defer p.lock.RUnlock()
+
+	if p.closed {
+		return ErrPoolClosed
+	}
+
+	p.tasks <- task
+	return nil
+}
+
+// TrySubmit submits a task without blocking, it returns ErrPoolBusy if the queue is full.
+func (p *WorkerPool) TrySubmit(task func()) error {
+	p.lock.RLock()
+	defer p.lock.RUnlock()
+
+	if p.closed {
+		return ErrPoolClosed
+	}
+
+	select {
+	case p.tasks <- task:
+		return nil
+	default:
+		return ErrPoolBusy
+	}
+}
+
+// Running returns the number of tasks being executed.
+func (p *WorkerPool) Running() int {
+	return int(p.running.Load())
+}
+
+// Close stops accepting new tasks, and waits for the queued and running tasks to finish.
+func (p *WorkerPool) Close() {
+	p.closeOnce.Do(func() {
+		// wait for the blocked Submit calls to enqueue their tasks
+		p.lock.Lock()
+		p.closed = true
+		close(p.tasks)
+		p.lock.Unlock()
+	})
+
+	p.wg.Wait()
+}
+
+func (p *WorkerPool) worker() {
+	defer p.wg.Done()
+
+	for task := range p.tasks {
+		p.run(task)
+	}
+}
+
+func (p *WorkerPool) run(task func()) {
+	p.running.Add(1)
+	defer p.running.Add(-1)
+
+	if err := safeCall(task); err != nil && p.panicHandler != nil {
+		p.panicHandler(err)
+	}
+}
+
+// Error implements the error interface.
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("panic: %v", e.Value)
+}
+
+func safeCall(fn func()) (err *PanicError) {
+	defer func() {
+		if v := recover(); v != nil {
+			err = &PanicError{Value: v, Stack: stack()}
+		}
+	}()
+
+	fn()
+	return nil
+}
+
+func stack() []byte {
+	return debug.Stack()
+}
@@ -0,0 +1,86 @@
+package pool
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWorkerPool(t *testing.T) {
+	p := NewWorkerPool(4, WithQueueSize(16))
+
+	var count atomic.Int32
+	for i := 0; i < 100; i++ {
+		assert.NoError(t, p.Submit(func() {
+			count.Add(1)
+		}))
+	}
+	p.Close()
+
+	assert.Equal(t, int32(100), count.Load())
+	assert.ErrorIs(t, p.Submit(func() {}), ErrPoolClosed)
+	assert.ErrorIs(t, p.TrySubmit(func() {}), ErrPoolClosed)
+	// close twice is fine
+	p.Close()
+}
+
+func TestWorkerPoolBounded(t *testing.T) {
+	p := NewWorkerPool(3)
+
+	var running, peak atomic.Int32
+	for i := 0; i < 20; i++ {
+		p.Submit(func() {
+			n := running.Add(1)
+			for {
+				old := peak.Load()
+				if n <= old || peak.CompareAndSwap(old, n) {
+					break
+				}
+			}
+			time.Sleep(time.Millisecond)
+			running.Add(-1)
+		})
+	}
+	p.Close()
+
+	assert.LessOrEqual(t, peak.Load(), int32(3))
+}
+
+func TestWorkerPoolPanic(t *testing.T) {
+	var lock sync.Mutex
+	var panics []any
+	p := NewWorkerPool(1, WithPanicHandler(func(err *PanicError) {
+		lock.Lock()
+		panics = append(panics, err.Value)
+		lock.Unlock()
+	}))
+
+	var done atomic.Bool
+	p.Submit(func() { panic("boom") })
+	p.Submit(func() { done.Store(true) })
+	p.Close()
+
+	assert.Equal(t, []any{"boom"}, panics)
+	assert.True(t, done.Load())
+}
+
+func TestWorkerPoolTrySubmit(t *testing.T) {
+	p := NewWorkerPool(1, WithQueueSize(0))
+	block := make(chan struct{})
+	started := make(chan struct{})
+
+	assert.NoError(t, p.Submit(func() {
+		close(started)
+		<-block
+	}))
+	<-started
+	assert.Equal(t, 1, p.Running())
+	assert.ErrorIs(t, p.TrySubmit(func() {}), ErrPoolBusy)
+
+	close(block)
+	p.Close()
+	assert.Equal(t, 0, p.Running())
+}
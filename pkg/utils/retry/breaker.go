@@ -0,0 +1,165 @@
+package retry
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrBreakerOpen is returned when the circuit breaker rejects a call.
+var ErrBreakerOpen = errors.New("circuit breaker is open")
+
+const (
+	// StateClosed lets all calls through.
+	StateClosed State = iota
+	// StateOpen rejects all calls until the reset timeout elapses.
+	StateOpen
+	// StateHalfOpen lets one trial call through to probe the dependency.
+	StateHalfOpen
+)
+
+type (
+	// State is the state of a Breaker.
+	State int
+
+	// BreakerOption customizes a Breaker.
+	BreakerOption func(*Breaker)
+
+	// A Breaker opens after maxFailures consecutive failures, and lets a trial call
+	// through after resetTimeout. It is safe for concurrent use.
+	Breaker struct {
+		maxFailures   int
+		resetTimeout  time.Duration
+		onStateChange func(from, to State)
+
+		lock     sync.Mutex
+		state    State
+		failures int
+		openedAt time.Time
+		probing  bool
+	}
+)
+
+// NewBreaker returns a Breaker.
+func NewBreaker(maxFailures int, resetTimeout time.Duration, opts ...BreakerOption) *Breaker {
+	if maxFailures <= 0 {
+		maxFailures = 1
+	}
+
+	b := &Breaker{
+		maxFailures:  maxFailures,
+		resetTimeout: resetTimeout,
+	}
+	for _, opt := range opts {
+		opt(b)
+	}
+
+	return b
+}
+
+// WithStateChange is called when the breaker changes state, can be used for metrics and alerts.
+// It is called with the breaker lock held, so it must not call the breaker.
+func WithStateChange(fn func(from, to State)) BreakerOption {
+	return func(b *Breaker) {
+		b.onStateChange = fn
+	}
+}
+
+// Allow checks if a call is allowed, it returns ErrBreakerOpen if not.
+// Each allowed call must be followed by a Record call.
+func (b *Breaker) Allow() error {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	switch b.state {
+	case StateOpen:
+		if time.Since(b.openedAt) < b.resetTimeout {
+			return ErrBreakerOpen
+		}
+		b.setState(StateHalfOpen)
+		b.probing = true
+		return nil
+	case StateHalfOpen:
+		if b.probing {
+			return ErrBreakerOpen
+		}
+		b.probing = true
+		return nil
+	default:
+		return nil
+	}
+}
+
+// Record records the result of an allowed call.
+func (b *Breaker) Record(err error) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	b.probing = false
+	if err == nil {
+		b.failures = 0
+		b.setState(StateClosed)
+		return
+	}
+
+	b.failures++
+	if b.state == StateHalfOpen || b.failures >= b.maxFailures {
+		b.openedAt = time.Now()
+		b.setState(StateOpen)
+	}
+}
+
+// Execute runs fn if the breaker allows it and records the result.
+func (b *Breaker) Execute(fn func() error) error {
+	if err := b.Allow(); err != nil {
+		return err
+	}
+
+	err := fn()
+	b.Record(err)
+	return err
+}
+
+// State returns the current state.
+// An open breaker whose reset timeout elapsed is still reported as open until the next call.
+func (b *Breaker) State() State {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	return b.state
+}
+
+// Reset closes the breaker and clears the failures.
+func (b *Breaker) Reset() {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	b.failures = 0
+	b.probing = false
+	b.setState(StateClosed)
+}
+
+func (b *Breaker) setState(state State) {
+	if b.state == state {
+		return
+	}
+
+	from := b.state
+	b.state = state
+	if b.onStateChange != nil {
+		b.onStateChange(from, state)
+	}
+}
+
+// String returns the name of the state.
+func (s State) String() string {
+	switch s {
+	case StateClosed:
+		return "closed"
+	case StateOpen:
+		return "open"
+	case StateHalfOpen:
+		return "half-open"
+	default:
+		return "unknown"
+	}
+}
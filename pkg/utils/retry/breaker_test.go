@@ -0,0 +1,63 @@
+package retry
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBreaker(t *testing.T) {
+	var changes []string
+	b := NewBreaker(2, 20*time.Millisecond, WithStateChange(func(from, to State) {
+		changes = append(changes, from.String()+"->"+to.String())
+	}))
+	fail := func() error { return errTest }
+	ok := func() error { return nil }
+
+	assert.ErrorIs(t, b.Execute(fail), errTest)
+	assert.Equal(t, StateClosed, b.State())
+	assert.ErrorIs(t, b.Execute(fail), errTest)
+	assert.Equal(t, StateOpen, b.State())
+	assert.ErrorIs(t, b.Execute(ok), ErrBreakerOpen)
+
+	// trial call fails, open again
+	time.Sleep(30 * time.Millisecond)
+	assert.ErrorIs(t, b.Execute(fail), errTest)
+	assert.Equal(t, StateOpen, b.State())
+
+	// trial call succeeds, closed
+	time.Sleep(30 * time.Millisecond)
+	assert.NoError(t, b.Execute(ok))
+	assert.Equal(t, StateClosed, b.State())
+
+	assert.Equal(t, []string{
+		"closed->open",
+		"open->half-open",
+		"half-open->open",
+		"open->half-open",
+		"half-open->closed",
+	}, changes)
+}
+
+func TestBreakerHalfOpenSingleTrial(t *testing.T) {
+	b := NewBreaker(1, time.Millisecond)
+	b.Execute(func() error { return errTest })
+	time.Sleep(5 * time.Millisecond)
+
+	assert.NoError(t, b.Allow())
+	assert.Equal(t, StateHalfOpen, b.State())
+	assert.ErrorIs(t, b.Allow(), ErrBreakerOpen)
+	b.Record(nil)
+	assert.NoError(t, b.Allow())
+}
+
+func TestBreakerReset(t *testing.T) {
+	b := NewBreaker(1, time.Hour)
+	b.Execute(func() error { return errTest })
+	assert.Equal(t, StateOpen, b.State())
+
+	b.Reset()
+	assert.Equal(t, StateClosed, b.State())
+	assert.NoError(t, b.Allow())
+}
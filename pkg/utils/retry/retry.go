@@ -0,0 +1,255 @@
+package retry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"time"
+)
+
+const (
+	defaultAttempts     = 3
+	defaultInitialDelay = 100 * time.Millisecond
+	defaultMaxDelay     = 5 * time.Second
+)
+
+type (
+	// Func is the function to retry, attempt starts from 0.
+	Func func(ctx context.Context, attempt int) error
+
+	// BackoffFunc returns the wait duration after the given failed attempt, attempt starts from 0.
+	BackoffFunc func(attempt int) time.Duration
+
+	// Option customizes a Do call.
+	Option func(*options)
+
+	options struct {
+		attempts  int
+		backoff   BackoffFunc
+		jitter    bool
+		retryIf   func(error) bool
+		breaker   *Breaker
+		onAttempt func(attempt int, err error, elapsed time.Duration)
+		onRetry   func(attempt int, err error, wait time.Duration)
+	}
+
+	unrecoverableError struct {
+		err error
+	}
+)
+
+// Do runs fn until it succeeds, the attempts are used up, or ctx is done.
+// Default to 3 attempts with exponential backoff from 100ms up to 5s.
+// It returns the last error of fn if all attempts failed.
+func Do(ctx context.Context, fn Func, opts ...Option) error {
+	o := newOptions(opts...)
+
+	var lastErr error
+	for attempt := 0; attempt < o.attempts; attempt++ {
+		if err := ctx.Err(); err != nil {
+			return withLastErr(err, lastErr)
+		}
+		if o.breaker != nil {
+			if err := o.breaker.Allow(); err != nil {
+				return withLastErr(err, lastErr)
+			}
+		}
+
+		start := time.Now()
+		err := fn(ctx, attempt)
+		if o.onAttempt != nil {
+			o.onAttempt(attempt, err, time.Since(start))
+		}
+
+		var unrecoverable *unrecoverableError
+		if errors.As(err, &unrecoverable) {
+			// breaker only counts failures of the dependency, not rejected input
+			if o.breaker != nil {
+				o.breaker.Record(nil)
+			}
+			return unrecoverable.err
+		}
+
+		retryable := err != nil && (o.retryIf == nil || o.retryIf(err))
+		if o.breaker != nil {
+			if retryable {
+				o.breaker.Record(err)
+			} else {
+				o.breaker.Record(nil)
+			}
+		}
+		if !retryable {
+			return err
+		}
+
+		lastErr = err
+		if attempt == o.attempts-1 {
+			break
+		}
+
+		wait := o.wait(attempt)
+		if o.onRetry != nil {
+			o.onRetry(attempt, err, wait)
+		}
+		if wait <= 0 {
+			continue
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return withLastErr(ctx.Err(), lastErr)
+		case <-timer.C:
+		}
+	}
+
+	return lastErr
+}
+
+// DoValue is like Do, but returns the value of the successful call.
+func DoValue[T any](ctx context.Context, fn func(ctx context.Context, attempt int) (T, error),
+	opts ...Option) (T, error) {
+	var result T
+	err := Do(ctx, func(ctx context.Context, attempt int) error {
+		var err error
+		result, err = fn(ctx, attempt)
+		return err
+	}, opts...)
+
+	return result, err
+}
+
+// Unrecoverable wraps err to stop retrying immediately, Do returns err itself.
+func Unrecoverable(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	return &unrecoverableError{err: err}
+}
+
+// Attempts customizes the max number of attempts, including the first call.
+func Attempts(n int) Option {
+	return func(o *options) {
+		o.attempts = n
+	}
+}
+
+// Delay waits a fixed duration between attempts.
+func Delay(d time.Duration) Option {
+	return func(o *options) {
+		o.backoff = func(int) time.Duration {
+			return d
+		}
+	}
+}
+
+// ExponentialBackoff doubles the wait after each failed attempt, starting from initial and capped by max.
+// A non-positive initial means no wait.
+func ExponentialBackoff(initial, max time.Duration) Option {
+	return func(o *options) {
+		o.backoff = exponential(initial, max)
+	}
+}
+
+// Backoff customizes the wait between attempts.
+func Backoff(fn BackoffFunc) Option {
+	return func(o *options) {
+		o.backoff = fn
+	}
+}
+
+// Jitter randomizes each wait into [wait/2, wait) to avoid retry storms.
+func Jitter() Option {
+	return func(o *options) {
+		o.jitter = true
+	}
+}
+
+// RetryIf only retries the errors that fn returns true, other errors are returned immediately.
+func RetryIf(fn func(error) bool) Option {
+	return func(o *options) {
+		o.retryIf = fn
+	}
+}
+
+// WithBreaker guards each attempt with the circuit breaker b.
+// Do stops retrying and returns ErrBreakerOpen when b rejects the call.
+func WithBreaker(b *Breaker) Option {
+	return func(o *options) {
+		o.breaker = b
+	}
+}
+
+// OnAttempt is called after each attempt with its error and elapsed time, can be used for metrics.
+func OnAttempt(fn func(attempt int, err error, elapsed time.Duration)) Option {
+	return func(o *options) {
+		o.onAttempt = fn
+	}
+}
+
+// OnRetry is called before waiting for the next attempt.
+func OnRetry(fn func(attempt int, err error, wait time.Duration)) Option {
+	return func(o *options) {
+		o.onRetry = fn
+	}
+}
+
+func (e *unrecoverableError) Error() string {
+	return e.err.Error()
+}
+
+func (e *unrecoverableError) Unwrap() error {
+	return e.err
+}
+
+func (o *options) wait(attempt int) time.Duration {
+	wait := o.backoff(attempt)
+	if o.jitter && wait > 1 {
+		half := wait / 2
+		wait = half + time.Duration(rand.Int63n(int64(wait-half)))
+	}
+
+	return wait
+}
+
+func exponential(initial, max time.Duration) BackoffFunc {
+	return func(attempt int) time.Duration {
+		if initial <= 0 {
+			return 0
+		}
+		if attempt >= 62 {
+			return max
+		}
+		if d := initial << uint(attempt); d > 0 && d < max {
+			return d
+		}
+
+		return max
+	}
+}
+
+func newOptions(opts ...Option) *options {
+	o := &options{
+		attempts: defaultAttempts,
+		backoff:  exponential(defaultInitialDelay, defaultMaxDelay),
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+	if o.attempts <= 0 {
+		o.attempts = 1
+	}
+
+	return o
+}
+
+func withLastErr(err, lastErr error) error {
+	if lastErr == nil {
+		return err
+	}
+
+	return fmt.Errorf("%w, last error: %w", err, lastErr)
+}
@@ -0,0 +1,145 @@
+package retry
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var errTest = errors.New("test error")
+
+func TestDoSuccessAfterRetries(t *testing.T) {
+	var calls int
+	err := Do(context.Background(), func(ctx context.Context, attempt int) error {
+		assert.Equal(t, calls, attempt)
+		calls++
+		if calls < 3 {
+			return errTest
+		}
+		return nil
+	}, Attempts(5), Delay(0))
+
+	assert.NoError(t, err)
+	assert.Equal(t, 3, calls)
+}
+
+func TestDoReturnsLastError(t *testing.T) {
+	var calls int
+	err := Do(context.Background(), func(ctx context.Context, attempt int) error {
+		calls++
+		return errTest
+	}, Attempts(4), Delay(time.Millisecond))
+
+	assert.ErrorIs(t, err, errTest)
+	assert.Equal(t, 4, calls)
+}
+
+func TestDoRetryIf(t *testing.T) {
+	errFatal := errors.New("fatal")
+	var calls int
+	err := Do(context.Background(), func(ctx context.Context, attempt int) error {
+		calls++
+		if attempt == 1 {
+			return errFatal
+		}
+		return errTest
+	}, Attempts(5), Delay(0), RetryIf(func(err error) bool {
+		return errors.Is(err, errTest)
+	}))
+
+	assert.ErrorIs(t, err, errFatal)
+	assert.Equal(t, 2, calls)
+}
+
+func TestDoUnrecoverable(t *testing.T) {
+	var calls int
+	err := Do(context.Background(), func(ctx context.Context, attempt int) error {
+		calls++
+		return Unrecoverable(errTest)
+	}, Attempts(5), Delay(0))
+
+	assert.Equal(t, errTest, err)
+	assert.Equal(t, 1, calls)
+	assert.Nil(t, Unrecoverable(nil))
+}
+
+func TestDoContextCanceled(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	err := Do(ctx, func(ctx context.Context, attempt int) error {
+		return errTest
+	}, Attempts(10), Delay(time.Second))
+
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.ErrorIs(t, err, errTest)
+	assert.Less(t, time.Since(start), time.Second)
+}
+
+func TestDoValue(t *testing.T) {
+	v, err := DoValue(context.Background(), func(ctx context.Context, attempt int) (int, error) {
+		if attempt == 0 {
+			return 0, errTest
+		}
+		return 42, nil
+	}, Delay(0))
+
+	assert.NoError(t, err)
+	assert.Equal(t, 42, v)
+}
+
+func TestDoHooks(t *testing.T) {
+	var attempts, retries []int
+	var waits []time.Duration
+	_ = Do(context.Background(), func(ctx context.Context, attempt int) error {
+		return errTest
+	}, Attempts(3), ExponentialBackoff(time.Millisecond, time.Second),
+		OnAttempt(func(attempt int, err error, elapsed time.Duration) {
+			attempts = append(attempts, attempt)
+		}),
+		OnRetry(func(attempt int, err error, wait time.Duration) {
+			retries = append(retries, attempt)
+			waits = append(waits, wait)
+		}))
+
+	assert.Equal(t, []int{0, 1, 2}, attempts)
+	assert.Equal(t, []int{0, 1}, retries)
+	assert.Equal(t, []time.Duration{time.Millisecond, 2 * time.Millisecond}, waits)
+}
+
+func TestExponentialBackoff(t *testing.T) {
+	backoff := exponential(100*time.Millisecond, time.Second)
+	assert.Equal(t, 100*time.Millisecond, backoff(0))
+	assert.Equal(t, 200*time.Millisecond, backoff(1))
+	assert.Equal(t, 800*time.Millisecond, backoff(3))
+	assert.Equal(t, time.Second, backoff(4))
+	assert.Equal(t, time.Second, backoff(100))
+	assert.Equal(t, time.Duration(0), exponential(0, time.Second)(3))
+}
+
+func TestJitter(t *testing.T) {
+	o := newOptions(Delay(100*time.Millisecond), Jitter())
+	for i := 0; i < 100; i++ {
+		wait := o.wait(i)
+		assert.GreaterOrEqual(t, wait, 50*time.Millisecond)
+		assert.Less(t, wait, 100*time.Millisecond)
+	}
+}
+
+func TestDoWithBreaker(t *testing.T) {
+	b := NewBreaker(2, time.Hour)
+	var calls int
+	err := Do(context.Background(), func(ctx context.Context, attempt int) error {
+		calls++
+		return errTest
+	}, Attempts(5), Delay(0), WithBreaker(b))
+
+	assert.ErrorIs(t, err, ErrBreakerOpen)
+	assert.ErrorIs(t, err, errTest)
+	assert.Equal(t, 2, calls)
+	assert.Equal(t, StateOpen, b.State())
+}
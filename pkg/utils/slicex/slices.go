@@ -0,0 +1,123 @@
+package slicex
+
+import "github.com/tedwangl/go-util/pkg/base/lang"
+
+// Map returns a new slice with fn applied to each element of s.
+func Map[T, R any](s []T, fn func(T) R) []R {
+	if s == nil {
+		return nil
+	}
+
+	out := make([]R, len(s))
+	for i, v := range s {
+		out[i] = fn(v)
+	}
+
+	return out
+}
+
+// Filter returns the elements of s for which keep returns true.
+func Filter[T any](s []T, keep func(T) bool) []T {
+	var out []T
+	for _, v := range s {
+		if keep(v) {
+			out = append(out, v)
+		}
+	}
+
+	return out
+}
+
+// Reduce folds s into a single value, starting from init.
+func Reduce[T, R any](s []T, init R, fn func(R, T) R) R {
+	acc := init
+	for _, v := range s {
+		acc = fn(acc, v)
+	}
+
+	return acc
+}
+
+// Chunk splits s into consecutive chunks of the given size.
+// The last chunk may be shorter. Chunks share the underlying array with s.
+// It returns nil if size is not positive.
+func Chunk[T any](s []T, size int) [][]T {
+	if size <= 0 || len(s) == 0 {
+		return nil
+	}
+
+	chunks := make([][]T, 0, (len(s)+size-1)/size)
+	for size < len(s) {
+		chunks = append(chunks, s[:size:size])
+		s = s[size:]
+	}
+
+	return append(chunks, s)
+}
+
+// Unique returns the elements of s with duplicates removed, keeping the first occurrence order.
+func Unique[T comparable](s []T) []T {
+	if s == nil {
+		return nil
+	}
+
+	seen := make(map[T]lang.PlaceholderType, len(s))
+	out := make([]T, 0, len(s))
+	for _, v := range s {
+		if _, ok := seen[v]; ok {
+			continue
+		}
+		seen[v] = lang.Placeholder
+		out = append(out, v)
+	}
+
+	return out
+}
+
+// Diff returns the elements of a that are not in b, keeping the order of a.
+func Diff[T comparable](a, b []T) []T {
+	exclude := toSet(b)
+
+	var out []T
+	for _, v := range a {
+		if _, ok := exclude[v]; !ok {
+			out = append(out, v)
+		}
+	}
+
+	return out
+}
+
+// Intersect returns the unique elements that are in both a and b, keeping the order of a.
+func Intersect[T comparable](a, b []T) []T {
+	include := toSet(b)
+
+	var out []T
+	for _, v := range Unique(a) {
+		if _, ok := include[v]; ok {
+			out = append(out, v)
+		}
+	}
+
+	return out
+}
+
+// GroupBy groups the elements of s by the key returned from fn.
+func GroupBy[T any, K comparable](s []T, fn func(T) K) map[K][]T {
+	groups := make(map[K][]T)
+	for _, v := range s {
+		k := fn(v)
+		groups[k] = append(groups[k], v)
+	}
+
+	return groups
+}
+
+func toSet[T comparable](s []T) map[T]lang.PlaceholderType {
+	set := make(map[T]lang.PlaceholderType, len(s))
+	for _, v := range s {
+		set[v] = lang.Placeholder
+	}
+
+	return set
+}
@@ -0,0 +1,75 @@
+package slicex
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMap(t *testing.T) {
+	assert.Equal(t, []string{"1", "2", "3"}, Map([]int{1, 2, 3}, strconv.Itoa))
+	assert.Nil(t, Map[int, string](nil, strconv.Itoa))
+}
+
+func TestFilter(t *testing.T) {
+	even := func(v int) bool { return v%2 == 0 }
+	assert.Equal(t, []int{2, 4}, Filter([]int{1, 2, 3, 4}, even))
+	assert.Nil(t, Filter([]int{1, 3}, even))
+}
+
+func TestReduce(t *testing.T) {
+	sum := Reduce([]int{1, 2, 3, 4}, 0, func(acc, v int) int { return acc + v })
+	assert.Equal(t, 10, sum)
+}
+
+func TestChunk(t *testing.T) {
+	cases := []struct {
+		name   string
+		input  []int
+		size   int
+		expect [][]int
+	}{
+		{"even", []int{1, 2, 3, 4}, 2, [][]int{{1, 2}, {3, 4}}},
+		{"remainder", []int{1, 2, 3, 4, 5}, 2, [][]int{{1, 2}, {3, 4}, {5}}},
+		{"larger size", []int{1, 2}, 5, [][]int{{1, 2}}},
+		{"empty", nil, 2, nil},
+		{"zero size", []int{1, 2}, 0, nil},
+	}
+
+	for _, each := range cases {
+		t.Run(each.name, func(t *testing.T) {
+			assert.Equal(t, each.expect, Chunk(each.input, each.size))
+		})
+	}
+}
+
+func TestChunkNoOverwrite(t *testing.T) {
+	s := []int{1, 2, 3, 4}
+	chunks := Chunk(s, 2)
+	chunks[0] = append(chunks[0], 100)
+	assert.Equal(t, []int{1, 2, 3, 4}, s)
+}
+
+func TestUnique(t *testing.T) {
+	assert.Equal(t, []string{"b", "a", "c"}, Unique([]string{"b", "a", "b", "c", "a"}))
+	assert.Nil(t, Unique[int](nil))
+}
+
+func TestDiff(t *testing.T) {
+	assert.Equal(t, []int{1, 3}, Diff([]int{1, 2, 3, 4}, []int{2, 4, 5}))
+	assert.Equal(t, []int{1, 2}, Diff([]int{1, 2}, nil))
+	assert.Nil(t, Diff([]int{1}, []int{1}))
+}
+
+func TestIntersect(t *testing.T) {
+	assert.Equal(t, []int{2, 4}, Intersect([]int{1, 2, 2, 3, 4}, []int{4, 2, 5}))
+}
+
+func TestGroupBy(t *testing.T) {
+	groups := GroupBy([]string{"apple", "avocado", "banana"}, func(s string) byte { return s[0] })
+	assert.Equal(t, map[byte][]string{
+		'a': {"apple", "avocado"},
+		'b': {"banana"},
+	}, groups)
+}
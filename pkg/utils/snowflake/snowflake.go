@@ -1,39 +1,197 @@
 package genid
 
 import (
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
 	"github.com/bwmarrin/snowflake"
 )
 
-// SnowflakeID 生成器结构体
-type SnowflakeID struct {
-	node *snowflake.Node
-}
+// 默认布局与 bwmarrin/snowflake 一致：41 位时间戳 + 10 位节点 + 12 位序列号
+const (
+	DefaultNodeBits = 10
+	DefaultStepBits = 12
+
+	// maxNodeStepBits 节点位和序列号位之和的上限（保证时间戳至少 41 位，约 69 年）
+	maxNodeStepBits = 22
+	// defaultMaxDriftWait 回拨等待的默认上限
+	defaultMaxDriftWait = time.Second
+)
+
+// DefaultEpoch 默认起始时间（Twitter 纪元，2010-11-04 01:42:54.657 UTC）
+var DefaultEpoch = time.UnixMilli(1288834974657)
+
+// ErrClockMovedBackwards 时钟回拨超出容忍范围
+var ErrClockMovedBackwards = errors.New("clock moved backwards")
+
+// 时钟回拨处理策略
+const (
+	DriftWait  DriftPolicy = iota // 等待时钟追上上次生成的时间（不超过 MaxDriftWait）
+	DriftError                    // 立即返回 ErrClockMovedBackwards
+)
+
+type (
+	// DriftPolicy 时钟回拨处理策略
+	DriftPolicy int
+
+	// Config 生成器配置，零值使用默认布局
+	Config struct {
+		Epoch        time.Time     // 起始时间，默认 DefaultEpoch
+		NodeBits     uint8         // 节点位数，默认 10
+		StepBits     uint8         // 序列号位数，默认 12
+		DriftPolicy  DriftPolicy   // 时钟回拨处理策略，默认等待
+		MaxDriftWait time.Duration // 等待回拨的最长时间，超过时返回错误，默认 1s
+	}
 
-// NewSnowflakeID 创建一个新的雪花ID生成器
+	// IDParts ID 的组成部分
+	IDParts struct {
+		Time     time.Time `json:"time"`
+		Node     int64     `json:"node"`
+		Sequence int64     `json:"sequence"`
+	}
+
+	// SnowflakeID 生成器结构体
+	SnowflakeID struct {
+		mu       sync.Mutex
+		epoch    int64 // 毫秒
+		node     int64
+		nodeBits uint8
+		stepBits uint8
+		stepMask int64
+		policy   DriftPolicy
+		maxWait  time.Duration
+
+		lastTime int64 // 上次生成 ID 的时间（相对 epoch 的毫秒）
+		step     int64
+
+		now   func() int64 // 当前 Unix 毫秒（墙上时钟，用于检测回拨）
+		sleep func(time.Duration)
+	}
+)
+
+// NewSnowflakeID 创建一个新的雪花ID生成器（默认布局）
 func NewSnowflakeID(nodeID int64) (*SnowflakeID, error) {
-	node, err := snowflake.NewNode(nodeID)
-	if err != nil {
-		return nil, err
+	return NewSnowflakeIDWithConfig(nodeID, Config{})
+}
+
+// NewSnowflakeIDWithConfig 按配置创建雪花ID生成器
+func NewSnowflakeIDWithConfig(nodeID int64, cfg Config) (*SnowflakeID, error) {
+	if cfg.Epoch.IsZero() {
+		cfg.Epoch = DefaultEpoch
+	}
+	if cfg.NodeBits == 0 && cfg.StepBits == 0 {
+		cfg.NodeBits, cfg.StepBits = DefaultNodeBits, DefaultStepBits
+	}
+	if cfg.StepBits == 0 {
+		return nil, errors.New("step bits must be greater than 0")
 	}
-	return &SnowflakeID{node: node}, nil
+	if cfg.NodeBits+cfg.StepBits > maxNodeStepBits {
+		return nil, fmt.Errorf("node bits + step bits must be <= %d, got %d", maxNodeStepBits, cfg.NodeBits+cfg.StepBits)
+	}
+	if cfg.MaxDriftWait <= 0 {
+		cfg.MaxDriftWait = defaultMaxDriftWait
+	}
+	if cfg.Epoch.After(time.Now()) {
+		return nil, errors.New("epoch cannot be in the future")
+	}
+
+	nodeMax := int64(-1) ^ (int64(-1) << cfg.NodeBits)
+	if nodeID < 0 || nodeID > nodeMax {
+		return nil, fmt.Errorf("node number must be between 0 and %d", nodeMax)
+	}
+
+	return &SnowflakeID{
+		epoch:    cfg.Epoch.UnixMilli(),
+		node:     nodeID,
+		nodeBits: cfg.NodeBits,
+		stepBits: cfg.StepBits,
+		stepMask: int64(-1) ^ (int64(-1) << cfg.StepBits),
+		policy:   cfg.DriftPolicy,
+		maxWait:  cfg.MaxDriftWait,
+		now:      func() int64 { return time.Now().UnixMilli() },
+		sleep:    time.Sleep,
+	}, nil
+}
+
+// Next 生成下一个ID，时钟回拨超出容忍范围时返回 ErrClockMovedBackwards
+func (s *SnowflakeID) Next() (int64, error) {
+	return s.next(false)
 }
 
 // NextID 生成下一个ID
+// 时钟回拨超出容忍范围时沿用上次的时间戳继续递增（保证不重复，不报错），需要感知回拨时使用 Next
 func (s *SnowflakeID) NextID() int64 {
-	return s.node.Generate().Int64()
+	id, _ := s.next(true)
+	return id
+}
+
+// next 生成ID，borrow 为 true 时回拨超出容忍范围后借用上次的时间戳
+func (s *SnowflakeID) next(borrow bool) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now() - s.epoch
+	borrowing := false
+	if now < s.lastTime {
+		drift := time.Duration(s.lastTime-now) * time.Millisecond
+		switch {
+		case s.policy == DriftWait && drift <= s.maxWait:
+			// 等待时钟追上，期间不生成 ID，避免与回拨前的 ID 重复
+			for now < s.lastTime {
+				s.sleep(time.Duration(s.lastTime-now) * time.Millisecond)
+				now = s.now() - s.epoch
+			}
+		case borrow:
+			now, borrowing = s.lastTime, true
+		default:
+			return 0, fmt.Errorf("%w: by %s", ErrClockMovedBackwards, drift)
+		}
+	}
+
+	if now == s.lastTime {
+		s.step = (s.step + 1) & s.stepMask
+		// 当前毫秒序列号用完，等待下一毫秒（借用时直接使用下一毫秒）
+		if s.step == 0 {
+			if borrowing {
+				now++
+			}
+			for now <= s.lastTime {
+				s.sleep(time.Millisecond / 10)
+				now = s.now() - s.epoch
+			}
+		}
+	} else {
+		s.step = 0
+	}
+	s.lastTime = now
+
+	return now<<(s.nodeBits+s.stepBits) | s.node<<s.stepBits | s.step, nil
 }
 
 // NextStringID 生成下一个ID（字符串格式）
 func (s *SnowflakeID) NextStringID() string {
-	return s.node.Generate().String()
+	return strconv.FormatInt(s.NextID(), 10)
+}
+
+// Decode 按生成器的布局解析ID
+func (s *SnowflakeID) Decode(id int64) IDParts {
+	return decode(id, s.epoch, s.nodeBits, s.stepBits)
 }
 
-// ParseID 解析ID为snowflake结构体
+// ParseID 解析ID为snowflake结构体（仅适用于默认布局）
 func (s *SnowflakeID) ParseID(id int64) *snowflake.ID {
 	sfID := snowflake.ParseInt64(id)
 	return &sfID
 }
 
+// DecodeID 按默认布局解析ID，用于排查问题
+func DecodeID(id int64) IDParts {
+	return decode(id, DefaultEpoch.UnixMilli(), DefaultNodeBits, DefaultStepBits)
+}
+
 // GetTimestampFromID 从ID中提取时间戳
 func GetTimestampFromID(id int64) int64 {
 	sfID := snowflake.ParseInt64(id)
@@ -57,3 +215,12 @@ func GetStepFromID(id int64) int64 {
 	sfID := snowflake.ParseInt64(id)
 	return sfID.Step()
 }
+
+// decode 按指定布局拆分ID
+func decode(id, epoch int64, nodeBits, stepBits uint8) IDParts {
+	return IDParts{
+		Time:     time.UnixMilli(id>>(nodeBits+stepBits) + epoch),
+		Node:     id >> stepBits & (int64(-1) ^ (int64(-1) << nodeBits)),
+		Sequence: id & (int64(-1) ^ (int64(-1) << stepBits)),
+	}
+}
@@ -1,7 +1,9 @@
 package genid
 
 import (
+	"errors"
 	"testing"
+	"time"
 )
 
 func TestSnowflakeID(t *testing.T) {
@@ -78,4 +80,129 @@ func TestStringFormat(t *testing.T) {
 	}
 	
 	t.Logf("Numeric ID: %d, String ID: %s", numID, stringNumID)
-}
\ No newline at end of file
+}
+func TestCustomLayout(t *testing.T) {
+	epoch := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	gen, err := NewSnowflakeIDWithConfig(5, Config{Epoch: epoch, NodeBits: 4, StepBits: 8})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	id := gen.NextID()
+	parts := gen.Decode(id)
+	if parts.Node != 5 {
+		t.Errorf("Expected node 5, got %d", parts.Node)
+	}
+	if parts.Time.Before(epoch) || time.Since(parts.Time) > time.Minute {
+		t.Errorf("Decoded time %v out of range", parts.Time)
+	}
+
+	// 节点号超出位宽
+	if _, err := NewSnowflakeIDWithConfig(16, Config{NodeBits: 4, StepBits: 8}); err == nil {
+		t.Error("Expected error for node out of range")
+	}
+}
+
+func TestInvalidConfig(t *testing.T) {
+	cases := []Config{
+		{NodeBits: 10},
+		{NodeBits: 12, StepBits: 12},
+		{Epoch: time.Now().Add(time.Hour)},
+	}
+	for _, cfg := range cases {
+		if _, err := NewSnowflakeIDWithConfig(0, cfg); err == nil {
+			t.Errorf("Expected error for config %+v", cfg)
+		}
+	}
+}
+
+func TestDecodeID(t *testing.T) {
+	gen, err := NewSnowflakeID(3)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	id := gen.NextID()
+	parts := DecodeID(id)
+	if parts.Node != GetNodeIDFromID(id) || parts.Sequence != GetStepFromID(id) {
+		t.Errorf("DecodeID mismatch: %+v", parts)
+	}
+	if parts.Time.UnixMilli() != GetTimeFromID(id) {
+		t.Errorf("Expected time %d, got %d", GetTimeFromID(id), parts.Time.UnixMilli())
+	}
+}
+
+// fakeClock 可手动调整的时钟，sleep 时前进相应时间
+type fakeClock struct {
+	ms int64
+}
+
+func (c *fakeClock) now() int64 { return c.ms }
+
+func (c *fakeClock) sleep(d time.Duration) {
+	if d < time.Millisecond {
+		d = time.Millisecond
+	}
+	c.ms += d.Milliseconds()
+}
+
+func newFakeGen(t *testing.T, cfg Config) (*SnowflakeID, *fakeClock) {
+	gen, err := NewSnowflakeIDWithConfig(1, cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	clock := &fakeClock{ms: time.Now().UnixMilli()}
+	gen.now, gen.sleep = clock.now, clock.sleep
+	return gen, clock
+}
+
+func TestClockDriftError(t *testing.T) {
+	gen, clock := newFakeGen(t, Config{DriftPolicy: DriftError})
+
+	if _, err := gen.Next(); err != nil {
+		t.Fatal(err)
+	}
+	clock.ms -= 10
+	if _, err := gen.Next(); !errors.Is(err, ErrClockMovedBackwards) {
+		t.Fatalf("Expected ErrClockMovedBackwards, got %v", err)
+	}
+}
+
+func TestClockDriftWait(t *testing.T) {
+	gen, clock := newFakeGen(t, Config{MaxDriftWait: 50 * time.Millisecond})
+
+	first, err := gen.Next()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 回拨在容忍范围内，等待后继续生成
+	clock.ms -= 20
+	second, err := gen.Next()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if second <= first {
+		t.Errorf("Expected %d > %d", second, first)
+	}
+
+	// 超出容忍范围时返回错误
+	clock.ms -= 100
+	if _, err := gen.Next(); !errors.Is(err, ErrClockMovedBackwards) {
+		t.Fatalf("Expected ErrClockMovedBackwards, got %v", err)
+	}
+}
+
+func TestClockDriftBorrow(t *testing.T) {
+	gen, clock := newFakeGen(t, Config{DriftPolicy: DriftError, NodeBits: 10, StepBits: 2})
+
+	last := gen.NextID()
+	clock.ms -= 1000
+	// NextID 不报错，借用上次时间戳继续递增，序列号用完后进入下一毫秒
+	for i := 0; i < 10; i++ {
+		id := gen.NextID()
+		if id <= last {
+			t.Fatalf("Expected increasing IDs, got %d after %d", id, last)
+		}
+		last = id
+	}
+}
@@ -0,0 +1,155 @@
+package stringx
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Truncate truncates s to at most maxRunes runes, including the ellipsis.
+// It never splits a multi-byte character.
+func Truncate(s string, maxRunes int, ellipsis string) string {
+	if maxRunes <= 0 {
+		return ""
+	}
+	if utf8.RuneCountInString(s) <= maxRunes {
+		return s
+	}
+
+	keep := maxRunes - utf8.RuneCountInString(ellipsis)
+	if keep <= 0 {
+		return FirstN(ellipsis, maxRunes)
+	}
+
+	return FirstN(s, keep) + ellipsis
+}
+
+// Words splits s into words on non-alphanumeric characters and case changes,
+// e.g. "HTTPServer_url-v2" -> ["HTTP", "Server", "url", "v2"].
+func Words(s string) []string {
+	var words []string
+	runes := []rune(s)
+	start := -1
+
+	for i, r := range runes {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			if start >= 0 {
+				words = append(words, string(runes[start:i]))
+				start = -1
+			}
+			continue
+		}
+
+		if start >= 0 && unicode.IsUpper(r) {
+			prev := runes[i-1]
+			// fooBar, v2Beta
+			lowerToUpper := !unicode.IsUpper(prev)
+			// HTTPServer: split before the last upper of an acronym
+			acronymEnd := unicode.IsUpper(prev) && i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			if lowerToUpper || acronymEnd {
+				words = append(words, string(runes[start:i]))
+				start = i
+			}
+		}
+		if start < 0 {
+			start = i
+		}
+	}
+	if start >= 0 {
+		words = append(words, string(runes[start:]))
+	}
+
+	return words
+}
+
+// CamelCase converts s to lower camel case, e.g. "user_id" -> "userId".
+func CamelCase(s string) string {
+	return joinWords(Words(s), false)
+}
+
+// PascalCase converts s to upper camel case, e.g. "user_id" -> "UserId".
+func PascalCase(s string) string {
+	return joinWords(Words(s), true)
+}
+
+// SnakeCase converts s to snake case, e.g. "UserID" -> "user_id".
+func SnakeCase(s string) string {
+	return strings.ToLower(strings.Join(Words(s), "_"))
+}
+
+// KebabCase converts s to kebab case, e.g. "UserID" -> "user-id".
+func KebabCase(s string) string {
+	return strings.ToLower(strings.Join(Words(s), "-"))
+}
+
+// Slugify converts s into a URL friendly slug, e.g. "Hello, World!" -> "hello-world".
+// Unlike KebabCase, it doesn't split on case changes. Non-ASCII letters are kept.
+func Slugify(s string) string {
+	var b strings.Builder
+	b.Grow(len(s))
+
+	pendingDash := false
+	for _, r := range strings.ToLower(s) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			if pendingDash && b.Len() > 0 {
+				b.WriteByte('-')
+			}
+			pendingDash = false
+			b.WriteRune(r)
+			continue
+		}
+		// apostrophes don't break words: "don't" -> "dont"
+		if r != '\'' && r != '’' {
+			pendingDash = true
+		}
+	}
+
+	return b.String()
+}
+
+// Levenshtein returns the edit distance between a and b, counted in runes.
+func Levenshtein(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	if len(ra) < len(rb) {
+		ra, rb = rb, ra
+	}
+	if len(rb) == 0 {
+		return len(ra)
+	}
+
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+
+	return prev[len(rb)]
+}
+
+func joinWords(words []string, upperFirst bool) string {
+	var b strings.Builder
+	for i, w := range words {
+		w = strings.ToLower(w)
+		if i == 0 && !upperFirst {
+			b.WriteString(w)
+			continue
+		}
+		r, size := utf8.DecodeRuneInString(w)
+		b.WriteRune(unicode.ToUpper(r))
+		b.WriteString(w[size:])
+	}
+
+	return b.String()
+}
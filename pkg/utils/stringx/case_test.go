@@ -0,0 +1,117 @@
+package stringx
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTruncate(t *testing.T) {
+	cases := []struct {
+		input    string
+		max      int
+		ellipsis string
+		expect   string
+	}{
+		{"hello", 10, "...", "hello"},
+		{"hello", 5, "...", "hello"},
+		{"hello world", 8, "...", "hello..."},
+		{"你好世界欢迎你", 5, "…", "你好世界…"},
+		{"hello", 2, "...", ".."},
+		{"hello", 3, "", "hel"},
+		{"hello", 0, "...", ""},
+	}
+
+	for _, each := range cases {
+		t.Run(each.input, func(t *testing.T) {
+			assert.Equal(t, each.expect, Truncate(each.input, each.max, each.ellipsis))
+		})
+	}
+}
+
+func TestWords(t *testing.T) {
+	cases := []struct {
+		input  string
+		expect []string
+	}{
+		{"userID", []string{"user", "ID"}},
+		{"HTTPServer", []string{"HTTP", "Server"}},
+		{"user_id", []string{"user", "id"}},
+		{"  hello-world v2Beta ", []string{"hello", "world", "v2", "Beta"}},
+		{"getHTTPResponseCode", []string{"get", "HTTP", "Response", "Code"}},
+		{"", nil},
+		{"__", nil},
+	}
+
+	for _, each := range cases {
+		t.Run(each.input, func(t *testing.T) {
+			assert.Equal(t, each.expect, Words(each.input))
+		})
+	}
+}
+
+func TestCaseConversion(t *testing.T) {
+	cases := []struct {
+		input  string
+		camel  string
+		pascal string
+		snake  string
+		kebab  string
+	}{
+		{"user_id", "userId", "UserId", "user_id", "user-id"},
+		{"UserID", "userId", "UserId", "user_id", "user-id"},
+		{"HTTPServer", "httpServer", "HttpServer", "http_server", "http-server"},
+		{"hello world", "helloWorld", "HelloWorld", "hello_world", "hello-world"},
+		{"", "", "", "", ""},
+	}
+
+	for _, each := range cases {
+		t.Run(each.input, func(t *testing.T) {
+			assert.Equal(t, each.camel, CamelCase(each.input))
+			assert.Equal(t, each.pascal, PascalCase(each.input))
+			assert.Equal(t, each.snake, SnakeCase(each.input))
+			assert.Equal(t, each.kebab, KebabCase(each.input))
+		})
+	}
+}
+
+func TestSlugify(t *testing.T) {
+	cases := []struct {
+		input  string
+		expect string
+	}{
+		{"Hello, World!", "hello-world"},
+		{"  Go 1.24 released  ", "go-1-24-released"},
+		{"Don't panic", "dont-panic"},
+		{"中文 标题", "中文-标题"},
+		{"---", ""},
+	}
+
+	for _, each := range cases {
+		t.Run(each.input, func(t *testing.T) {
+			assert.Equal(t, each.expect, Slugify(each.input))
+		})
+	}
+}
+
+func TestLevenshtein(t *testing.T) {
+	cases := []struct {
+		a, b   string
+		expect int
+	}{
+		{"", "", 0},
+		{"abc", "", 3},
+		{"", "abc", 3},
+		{"kitten", "sitting", 3},
+		{"flaw", "lawn", 2},
+		{"你好", "你们好", 1},
+		{"same", "same", 0},
+	}
+
+	for _, each := range cases {
+		t.Run(each.a+"/"+each.b, func(t *testing.T) {
+			assert.Equal(t, each.expect, Levenshtein(each.a, each.b))
+			assert.Equal(t, each.expect, Levenshtein(each.b, each.a))
+		})
+	}
+}
@@ -0,0 +1,276 @@
+// Package workflowlint reports code in Temporal workflow functions that breaks
+// deterministic replay, such as reading the wall clock, random numbers, I/O,
+// native goroutines and channels, and iterating over maps.
+package workflowlint
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"io/fs"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const workflowPkg = "go.temporal.io/sdk/workflow"
+
+type (
+	// Issue is a non-deterministic usage found in a workflow function.
+	Issue struct {
+		Pos     token.Position `json:"pos"`
+		Func    string         `json:"func"`
+		Message string         `json:"message"`
+	}
+
+	checker struct {
+		fset    *token.FileSet
+		imports map[string]string // local name -> import path
+		fn      string
+		issues  []Issue
+	}
+)
+
+// pkgRules maps import paths to the message reported on any use, or on the listed functions only.
+var pkgRules = map[string]struct {
+	funcs   []string
+	message string
+}{
+	"time": {
+		funcs:   []string{"Now", "Since", "Until", "Sleep", "After", "AfterFunc", "Tick", "NewTimer", "NewTicker"},
+		message: "use workflow.Now, workflow.Sleep or workflow.NewTimer instead of time.%s",
+	},
+	"math/rand":              {message: "random numbers differ on replay, use workflow.SideEffect"},
+	"math/rand/v2":           {message: "random numbers differ on replay, use workflow.SideEffect"},
+	"crypto/rand":            {message: "random numbers differ on replay, use workflow.SideEffect"},
+	"github.com/google/uuid": {message: "uuids differ on replay, use workflow.SideEffect"},
+	"os":                     {message: "os.%s is I/O, run it in an activity"},
+	"net/http":               {message: "http.%s is I/O, run it in an activity"},
+	"database/sql":           {message: "sql.%s is I/O, run it in an activity"},
+	"sync":                   {message: "sync.%s blocks the workflow goroutine, use workflow.Mutex or workflow.WaitGroup"},
+	"log":                    {message: "log.%s is written again on replay, use workflow.GetLogger"},
+}
+
+func (i Issue) String() string {
+	return fmt.Sprintf("%s: %s: %s", i.Pos, i.Func, i.Message)
+}
+
+// CheckDir checks the non-test go files in dir and its subdirectories,
+// skipping vendor, testdata and hidden directories.
+func CheckDir(dir string) ([]Issue, error) {
+	var issues []Issue
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			name := d.Name()
+			if path != dir && (name == "vendor" || name == "testdata" || strings.HasPrefix(name, ".")) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !strings.HasSuffix(path, ".go") || strings.HasSuffix(path, "_test.go") {
+			return nil
+		}
+
+		fileIssues, err := CheckFile(path, nil)
+		if err != nil {
+			return err
+		}
+		issues = append(issues, fileIssues...)
+		return nil
+	})
+
+	return issues, err
+}
+
+// CheckFile checks the workflow functions in a file, src is read from filename if nil.
+func CheckFile(filename string, src any) ([]Issue, error) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, filename, src, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	c := &checker{fset: fset, imports: fileImports(file)}
+	if _, ok := c.lookup(workflowPkg); !ok {
+		return nil, nil
+	}
+
+	for _, decl := range file.Decls {
+		fn, ok := decl.(*ast.FuncDecl)
+		if !ok || fn.Body == nil || !c.isWorkflowFunc(fn.Type) {
+			continue
+		}
+
+		c.fn = funcName(fn)
+		ast.Inspect(fn.Body, c.visit)
+	}
+
+	sort.SliceStable(c.issues, func(i, j int) bool {
+		return c.issues[i].Pos.Offset < c.issues[j].Pos.Offset
+	})
+	return c.issues, nil
+}
+
+func (c *checker) visit(n ast.Node) bool {
+	switch n := n.(type) {
+	case *ast.GoStmt:
+		c.report(n, "native goroutine, use workflow.Go")
+	case *ast.SelectStmt:
+		c.report(n, "native select, use workflow.NewSelector")
+	case *ast.SendStmt:
+		c.report(n, "native channel send, use workflow.Channel")
+	case *ast.UnaryExpr:
+		if n.Op == token.ARROW {
+			c.report(n, "native channel receive, use workflow.Channel")
+		}
+	case *ast.RangeStmt:
+		if isMap(n.X) {
+			c.report(n, "map iteration order is random, iterate over the sorted keys")
+		}
+	case *ast.SelectorExpr:
+		c.checkSelector(n)
+	}
+
+	return true
+}
+
+func (c *checker) checkSelector(sel *ast.SelectorExpr) {
+	ident, ok := sel.X.(*ast.Ident)
+	if !ok {
+		return
+	}
+	path, ok := c.imports[ident.Name]
+	if !ok {
+		return
+	}
+	rule, ok := pkgRules[path]
+	if !ok {
+		return
+	}
+
+	if len(rule.funcs) > 0 && !slices.Contains(rule.funcs, sel.Sel.Name) {
+		return
+	}
+	if strings.Contains(rule.message, "%s") {
+		c.report(sel, fmt.Sprintf(rule.message, sel.Sel.Name))
+	} else {
+		c.report(sel, rule.message)
+	}
+}
+
+func (c *checker) isWorkflowFunc(ft *ast.FuncType) bool {
+	if ft.Params == nil || len(ft.Params.List) == 0 {
+		return false
+	}
+
+	sel, ok := ft.Params.List[0].Type.(*ast.SelectorExpr)
+	if !ok || sel.Sel.Name != "Context" {
+		return false
+	}
+	ident, ok := sel.X.(*ast.Ident)
+	return ok && c.imports[ident.Name] == workflowPkg
+}
+
+func (c *checker) lookup(path string) (string, bool) {
+	for name, p := range c.imports {
+		if p == path {
+			return name, true
+		}
+	}
+	return "", false
+}
+
+func (c *checker) report(n ast.Node, message string) {
+	c.issues = append(c.issues, Issue{
+		Pos:     c.fset.Position(n.Pos()),
+		Func:    c.fn,
+		Message: message,
+	})
+}
+
+func fileImports(file *ast.File) map[string]string {
+	imports := make(map[string]string, len(file.Imports))
+	for _, spec := range file.Imports {
+		path, err := strconv.Unquote(spec.Path.Value)
+		if err != nil {
+			continue
+		}
+
+		var name string
+		switch {
+		case spec.Name != nil:
+			name = spec.Name.Name
+		case strings.HasPrefix(path, "math/rand/v"):
+			name = "rand"
+		default:
+			name = path[strings.LastIndex(path, "/")+1:]
+		}
+		if name != "_" && name != "." {
+			imports[name] = path
+		}
+	}
+
+	return imports
+}
+
+func funcName(fn *ast.FuncDecl) string {
+	if fn.Recv == nil || len(fn.Recv.List) == 0 {
+		return fn.Name.Name
+	}
+
+	recv := fn.Recv.List[0].Type
+	if star, ok := recv.(*ast.StarExpr); ok {
+		recv = star.X
+	}
+	if ident, ok := recv.(*ast.Ident); ok {
+		return ident.Name + "." + fn.Name.Name
+	}
+	return fn.Name.Name
+}
+
+// isMap reports whether expr is declared as a map in the same file,
+// by a map literal, make(map...) or a variable, field or parameter of map type.
+func isMap(expr ast.Expr) bool {
+	switch e := expr.(type) {
+	case *ast.CompositeLit:
+		_, ok := e.Type.(*ast.MapType)
+		return ok
+	case *ast.CallExpr:
+		if ident, ok := e.Fun.(*ast.Ident); ok && ident.Name == "make" && len(e.Args) > 0 {
+			_, ok = e.Args[0].(*ast.MapType)
+			return ok
+		}
+	case *ast.Ident:
+		if e.Obj == nil {
+			return false
+		}
+		switch decl := e.Obj.Decl.(type) {
+		case *ast.Field:
+			_, ok := decl.Type.(*ast.MapType)
+			return ok
+		case *ast.ValueSpec:
+			if _, ok := decl.Type.(*ast.MapType); ok {
+				return true
+			}
+			for i, name := range decl.Names {
+				if name.Name == e.Name && i < len(decl.Values) {
+					return isMap(decl.Values[i])
+				}
+			}
+		case *ast.AssignStmt:
+			for i, lhs := range decl.Lhs {
+				if ident, ok := lhs.(*ast.Ident); ok && ident.Name == e.Name && i < len(decl.Rhs) {
+					return isMap(decl.Rhs[i])
+				}
+			}
+		}
+	}
+
+	return false
+}
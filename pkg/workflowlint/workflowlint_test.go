@@ -0,0 +1,120 @@
+package workflowlint
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const header = `package workflows
+
+import (
+	"context"
+	"math/rand"
+	"sync"
+	"time"
+
+	"go.temporal.io/sdk/workflow"
+)
+
+var _ = context.Background
+var _ = rand.Int
+var _ sync.Mutex
+`
+
+func TestCheckFile(t *testing.T) {
+	cases := []struct {
+		name   string
+		body   string
+		expect []string
+	}{
+		{
+			name: "deterministic",
+			body: `func Wf(ctx workflow.Context, d time.Duration) error {
+	_ = workflow.Now(ctx)
+	return workflow.Sleep(ctx, d*time.Second)
+}`,
+		},
+		{
+			name:   "time",
+			body:   "func Wf(ctx workflow.Context) { _ = time.Now(); time.Sleep(time.Second) }",
+			expect: []string{"time.Now", "time.Sleep"},
+		},
+		{
+			name:   "rand",
+			body:   "func Wf(ctx workflow.Context) int { return rand.Intn(10) }",
+			expect: []string{"SideEffect"},
+		},
+		{
+			name: "goroutine and channels",
+			body: `func Wf(ctx workflow.Context) {
+	ch := make(chan int)
+	go func() { ch <- 1 }()
+	select {
+	case <-ch:
+	}
+}`,
+			expect: []string{"workflow.Go", "channel send", "workflow.NewSelector", "channel receive"},
+		},
+		{
+			name: "map range",
+			body: `func Wf(ctx workflow.Context, params map[string]int) {
+	m := map[string]int{}
+	for range m {
+	}
+	for range params {
+	}
+	s := []int{1}
+	for range s {
+	}
+}`,
+			expect: []string{"map iteration", "map iteration"},
+		},
+		{
+			name:   "method",
+			body:   "type W struct{ mu sync.Mutex }\n\nfunc (w *W) Run(ctx workflow.Context) { var wg sync.WaitGroup; wg.Wait() }",
+			expect: []string{"sync.WaitGroup"},
+		},
+		{
+			name: "not workflow",
+			body: "func Act(ctx context.Context) { _ = time.Now(); go func() {}() }",
+		},
+	}
+
+	for _, each := range cases {
+		t.Run(each.name, func(t *testing.T) {
+			issues, err := CheckFile("wf.go", header+each.body)
+			assert.NoError(t, err)
+			if assert.Len(t, issues, len(each.expect)) {
+				for i, issue := range issues {
+					assert.Contains(t, issue.Message, each.expect[i])
+				}
+			}
+		})
+	}
+}
+
+func TestCheckFileWithoutWorkflowImport(t *testing.T) {
+	issues, err := CheckFile("a.go", "package a\n\nimport \"time\"\n\nfunc F() { _ = time.Now() }\n")
+	assert.NoError(t, err)
+	assert.Empty(t, issues)
+}
+
+func TestCheckDir(t *testing.T) {
+	dir := t.TempDir()
+	src := header + "func Wf(ctx workflow.Context) { _ = time.Now() }\n"
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "wf.go"), []byte(src), 0o644))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "wf_test.go"), []byte(src), 0o644))
+	assert.NoError(t, os.MkdirAll(filepath.Join(dir, "testdata"), 0o755))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "testdata", "wf.go"), []byte(src), 0o644))
+
+	issues, err := CheckDir(dir)
+	assert.NoError(t, err)
+	if assert.Len(t, issues, 1) {
+		assert.Equal(t, "Wf", issues[0].Func)
+		assert.Equal(t, filepath.Join(dir, "wf.go"), issues[0].Pos.Filename)
+		assert.Contains(t, issues[0].String(), "wf.go:15:")
+	}
+}
@@ -29,7 +29,7 @@ func main() {
 func runSimpleWorkflow(c client.Client) {
 	workflowOptions := client.StartWorkflowOptions{
 		ID:        "simple-workflow-1",
-		TaskQueue: "example-task-queue",
+		TaskQueue: workflows.TaskQueue,
 	}
 
 	we, err := c.ExecuteWorkflow(context.Background(), workflowOptions, workflows.SimpleWorkflow, "World")
@@ -50,27 +50,34 @@ func runSimpleWorkflow(c client.Client) {
 }
 
 func runOrderWorkflow(c client.Client) {
-	workflowOptions := client.StartWorkflowOptions{
-		ID:        "order-workflow-001",
-		TaskQueue: "example-task-queue",
-	}
+	orders := workflows.NewOrderWorkflowClient(c)
 
 	input := workflows.OrderWorkflowInput{
-		OrderID:    "ORD-12345",
-		CustomerID: "CUST-001",
-		Amount:     99.99,
+		OrderID:         "ORD-12345",
+		CustomerID:      "CUST-001",
+		Amount:          99.99,
+		RequireApproval: true,
 	}
 
-	we, err := c.ExecuteWorkflow(context.Background(), workflowOptions, workflows.OrderWorkflow, input)
+	we, err := orders.Start(context.Background(), input)
 	if err != nil {
 		log.Fatalln("无法启动订单工作流", err)
 	}
 
 	log.Println("启动订单工作流", "WorkflowID", we.GetID(), "RunID", we.GetRunID())
 
+	// 审批订单
+	err = orders.SignalApprove(context.Background(), input.OrderID, workflows.Approval{Approved: true, Approver: "admin"})
+	if err != nil {
+		log.Fatalln("无法审批订单", err)
+	}
+
+	if status, err := orders.QueryStatus(context.Background(), input.OrderID); err == nil {
+		log.Println("订单工作流状态:", status)
+	}
+
 	// 等待工作流完成
-	var result workflows.OrderWorkflowResult
-	err = we.Get(context.Background(), &result)
+	result, err := we.Result(context.Background())
 	if err != nil {
 		log.Fatalln("订单工作流执行失败", err)
 	}
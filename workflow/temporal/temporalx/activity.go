@@ -0,0 +1,172 @@
+package temporalx
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"go.temporal.io/sdk/activity"
+	"go.temporal.io/sdk/temporal"
+	"go.temporal.io/sdk/workflow"
+)
+
+const (
+	defaultStartToCloseTimeout = time.Minute
+	defaultMaxAttempts         = 3
+	// heartbeatTimeoutFactor 心跳超时为心跳间隔的倍数，容忍偶尔的心跳延迟
+	heartbeatTimeoutFactor = 3
+)
+
+// ActivityOption 活动选项
+type ActivityOption func(ao *workflow.ActivityOptions)
+
+// WithTimeout 设置单次执行的超时时间（StartToCloseTimeout），默认 1 分钟
+func WithTimeout(timeout time.Duration) ActivityOption {
+	return func(ao *workflow.ActivityOptions) {
+		ao.StartToCloseTimeout = timeout
+	}
+}
+
+// WithScheduleToCloseTimeout 设置包括重试在内的总超时时间
+func WithScheduleToCloseTimeout(timeout time.Duration) ActivityOption {
+	return func(ao *workflow.ActivityOptions) {
+		ao.ScheduleToCloseTimeout = timeout
+	}
+}
+
+// WithRetry 设置重试策略，默认初始间隔 1s、指数 2、最大间隔 1 分钟、最多 3 次
+func WithRetry(policy temporal.RetryPolicy) ActivityOption {
+	return func(ao *workflow.ActivityOptions) {
+		ao.RetryPolicy = &policy
+	}
+}
+
+// WithMaxAttempts 设置最多执行次数，1 表示不重试，0 表示不限制
+func WithMaxAttempts(attempts int32) ActivityOption {
+	return func(ao *workflow.ActivityOptions) {
+		ao.RetryPolicy.MaximumAttempts = attempts
+	}
+}
+
+// WithNonRetryableErrors 设置不重试的错误类型（ApplicationError 的 Type）
+func WithNonRetryableErrors(errorTypes ...string) ActivityOption {
+	return func(ao *workflow.ActivityOptions) {
+		ao.RetryPolicy.NonRetryableErrorTypes = append(ao.RetryPolicy.NonRetryableErrorTypes, errorTypes...)
+	}
+}
+
+// WithHeartbeat 设置活动的心跳间隔，超过 3 个间隔没有心跳视为活动失败，
+// 活动中使用 AutoHeartbeat 按该间隔自动发送心跳
+func WithHeartbeat(interval time.Duration) ActivityOption {
+	return func(ao *workflow.ActivityOptions) {
+		ao.HeartbeatTimeout = interval * heartbeatTimeoutFactor
+	}
+}
+
+// WithTaskQueue 在指定的任务队列上执行活动，默认与工作流相同
+func WithTaskQueue(taskQueue string) ActivityOption {
+	return func(ao *workflow.ActivityOptions) {
+		ao.TaskQueue = taskQueue
+	}
+}
+
+// ExecuteActivity 以默认选项执行活动并等待结果，input 为 nil 时活动没有参数
+func ExecuteActivity[T any](ctx workflow.Context, activity, input any, opts ...ActivityOption) (T, error) {
+	var result T
+	err := executeActivity(ctx, activity, input, opts...).Get(ctx, &result)
+	return result, err
+}
+
+func executeActivity(ctx workflow.Context, activity, input any, opts ...ActivityOption) workflow.Future {
+	ao := workflow.ActivityOptions{
+		StartToCloseTimeout: defaultStartToCloseTimeout,
+		RetryPolicy: &temporal.RetryPolicy{
+			InitialInterval:    time.Second,
+			BackoffCoefficient: 2.0,
+			MaximumInterval:    time.Minute,
+			MaximumAttempts:    defaultMaxAttempts,
+		},
+	}
+	for _, opt := range opts {
+		opt(&ao)
+	}
+	ctx = workflow.WithActivityOptions(ctx, ao)
+
+	if input == nil {
+		return workflow.ExecuteActivity(ctx, activity)
+	}
+	return workflow.ExecuteActivity(ctx, activity, input)
+}
+
+// AutoHeartbeat 在活动中按 WithHeartbeat 设置的间隔自动发送心跳，活动结束时调用返回的函数停止。
+// 没有设置心跳超时时不发送心跳。
+//
+//	defer temporalx.AutoHeartbeat(ctx)()
+func AutoHeartbeat(ctx context.Context, details ...any) (stop func()) {
+	timeout := activity.GetInfo(ctx).HeartbeatTimeout
+	if timeout <= 0 {
+		return func() {}
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	go func() {
+		ticker := time.NewTicker(timeout / heartbeatTimeoutFactor)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				activity.RecordHeartbeat(ctx, details...)
+			}
+		}
+	}()
+
+	return cancel
+}
+
+// NonRetryable 将错误标记为不可重试，活动返回后不会再重试
+func NonRetryable(err error) error {
+	if err == nil {
+		return nil
+	}
+	return temporal.NewNonRetryableApplicationError(err.Error(), errorType(err), err)
+}
+
+// Retryable 将错误标记为可重试，重试次数由重试策略决定
+func Retryable(err error) error {
+	if err == nil {
+		return nil
+	}
+	return temporal.NewApplicationError(err.Error(), errorType(err), err)
+}
+
+// IsRetryable 判断活动错误是否可以重试：
+// 标记为不可重试的错误、取消和终止不可重试，超时和其他错误可以重试
+func IsRetryable(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	var appErr *temporal.ApplicationError
+	if errors.As(err, &appErr) {
+		return !appErr.NonRetryable()
+	}
+
+	var canceledErr *temporal.CanceledError
+	var terminatedErr *temporal.TerminatedError
+	return !errors.As(err, &canceledErr) && !errors.As(err, &terminatedErr)
+}
+
+// errorType 使用根错误的类型名作为 ApplicationError 的 Type，可用于 WithNonRetryableErrors
+func errorType(err error) string {
+	for {
+		next := errors.Unwrap(err)
+		if next == nil {
+			return fmt.Sprintf("%T", err)
+		}
+		err = next
+	}
+}
@@ -0,0 +1,107 @@
+package temporalx
+
+import (
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+
+	"go.temporal.io/sdk/workflow"
+)
+
+// BatchError 批量子工作流中失败的部分，key 为输入的下标
+type BatchError struct {
+	Total  int
+	Errors map[int]error
+}
+
+func (e *BatchError) Error() string {
+	indexes := make([]int, 0, len(e.Errors))
+	for i := range e.Errors {
+		indexes = append(indexes, i)
+	}
+	sort.Ints(indexes)
+
+	first := indexes[0]
+	return fmt.Sprintf("temporalx: %d of %d child workflows failed, #%d: %v",
+		len(e.Errors), e.Total, first, e.Errors[first])
+}
+
+func (e *BatchError) Unwrap() []error {
+	errs := make([]error, 0, len(e.Errors))
+	for _, err := range e.Errors {
+		errs = append(errs, err)
+	}
+	return errs
+}
+
+// ExecuteChildren 为每个输入启动一个子工作流，最多同时执行 parallelism 个（<= 0 不限制），
+// 结果与输入按下标对应。部分失败时返回成功的结果和 *BatchError。
+// 子工作流选项来自 workflow.WithChildOptions，ID 为选项中的 WorkflowID 加下标；未设置 WorkflowID 时
+// 为父工作流 ID 加批次 ID 和下标，批次 ID 通过 SideEffect 生成，同一工作流中的多个批次不会冲突，重放时保持一致。
+func ExecuteChildren[I, O any](ctx workflow.Context, childWorkflow any, inputs []I, parallelism int) ([]O, error) {
+	if parallelism <= 0 || parallelism > len(inputs) {
+		parallelism = len(inputs)
+	}
+
+	prefix, err := batchPrefix(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		results  = make([]O, len(inputs))
+		errs     = make(map[int]error)
+		selector = workflow.NewSelector(ctx)
+		next     int
+		running  int
+	)
+	start := func(i int) {
+		opts := workflow.GetChildWorkflowOptions(ctx)
+		opts.WorkflowID = prefix + "-" + strconv.Itoa(i)
+		future := workflow.ExecuteChildWorkflow(workflow.WithChildOptions(ctx, opts), childWorkflow, inputs[i])
+		selector.AddFuture(future, func(f workflow.Future) {
+			if err := f.Get(ctx, &results[i]); err != nil {
+				errs[i] = err
+			}
+			running--
+		})
+		running++
+	}
+
+	for next < len(inputs) || running > 0 {
+		for ; next < len(inputs) && running < parallelism; next++ {
+			start(next)
+		}
+		selector.Select(ctx)
+		if err := ctx.Err(); err != nil {
+			return results, errors.Join(err, batchError(len(inputs), errs))
+		}
+	}
+
+	return results, batchError(len(inputs), errs)
+}
+
+// batchPrefix 返回子工作流 ID 的前缀
+func batchPrefix(ctx workflow.Context) (string, error) {
+	if id := workflow.GetChildWorkflowOptions(ctx).WorkflowID; id != "" {
+		return id, nil
+	}
+
+	var batchID string
+	encoded := workflow.SideEffect(ctx, func(workflow.Context) any {
+		return rand.Text()[:8]
+	})
+	if err := encoded.Get(&batchID); err != nil {
+		return "", fmt.Errorf("temporalx: generate batch id: %w", err)
+	}
+	return workflow.GetInfo(ctx).WorkflowExecution.ID + "-" + batchID, nil
+}
+
+func batchError(total int, errs map[int]error) error {
+	if len(errs) == 0 {
+		return nil
+	}
+	return &BatchError{Total: total, Errors: errs}
+}
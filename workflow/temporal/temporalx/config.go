@@ -0,0 +1,76 @@
+package temporalx
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	defaultHostPort        = "localhost:7233"
+	defaultNamespace       = "default"
+	defaultShutdownTimeout = 30 * time.Second
+)
+
+var errTaskQueueNotSet = errors.New("temporalx: task queue must be set")
+
+type (
+	// Config Worker 配置
+	Config struct {
+		HostPort  string `yaml:"hostPort"`  // Temporal 服务地址，默认 localhost:7233
+		Namespace string `yaml:"namespace"` // 命名空间，默认 default
+		TaskQueue string `yaml:"taskQueue"` // 任务队列（必填）
+		Identity  string `yaml:"identity"`  // Worker 标识，默认由 SDK 生成
+
+		MaxConcurrentActivities         int     `yaml:"maxConcurrentActivities"`         // 最大并发活动数，0 使用 SDK 默认值
+		MaxConcurrentWorkflowTasks      int     `yaml:"maxConcurrentWorkflowTasks"`      // 最大并发工作流任务数，0 使用 SDK 默认值
+		MaxActivitiesPerSecond          float64 `yaml:"maxActivitiesPerSecond"`          // 每秒最多执行的活动数（单 Worker），0 不限制
+		MaxTaskQueueActivitiesPerSecond float64 `yaml:"maxTaskQueueActivitiesPerSecond"` // 每秒最多执行的活动数（整个任务队列），0 不限制
+
+		ShutdownTimeout time.Duration `yaml:"shutdownTimeout"` // 优雅退出时等待活动完成的时间，默认 30s
+
+		Metrics MetricsConfig `yaml:"metrics"` // Prometheus 指标
+	}
+
+	// MetricsConfig Prometheus 指标配置
+	MetricsConfig struct {
+		ListenAddress string `yaml:"listenAddress"` // 指标监听地址，例如 :9090，为空时不开启
+		Prefix        string `yaml:"prefix"`        // 指标名前缀
+	}
+)
+
+// LoadConfig 从 YAML 文件加载配置
+func LoadConfig(path string) (Config, error) {
+	var c Config
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return c, fmt.Errorf("temporalx: read config: %w", err)
+	}
+	if err = yaml.Unmarshal(data, &c); err != nil {
+		return c, fmt.Errorf("temporalx: parse config: %w", err)
+	}
+
+	return c, nil
+}
+
+// normalize 校验配置并填充默认值
+func (c *Config) normalize() error {
+	if len(c.TaskQueue) == 0 {
+		return errTaskQueueNotSet
+	}
+	if len(c.HostPort) == 0 {
+		c.HostPort = defaultHostPort
+	}
+	if len(c.Namespace) == 0 {
+		c.Namespace = defaultNamespace
+	}
+	if c.ShutdownTimeout <= 0 {
+		c.ShutdownTimeout = defaultShutdownTimeout
+	}
+
+	return nil
+}
@@ -0,0 +1,104 @@
+package temporalx
+
+import (
+	"context"
+
+	"go.temporal.io/sdk/client"
+	"go.temporal.io/sdk/workflow"
+)
+
+type (
+	// Signal 类型化的信号定义，工作流和调用方共用，避免信号名和参数类型不一致
+	Signal[T any] struct {
+		Name string
+	}
+
+	// Query 类型化的查询定义
+	Query[T any] struct {
+		Name string
+	}
+
+	// Update 类型化的更新定义，I 为参数，O 为结果
+	Update[I, O any] struct {
+		Name string
+	}
+)
+
+// NewSignal 定义信号
+func NewSignal[T any](name string) Signal[T] {
+	return Signal[T]{Name: name}
+}
+
+// NewQuery 定义查询
+func NewQuery[T any](name string) Query[T] {
+	return Query[T]{Name: name}
+}
+
+// NewUpdate 定义更新
+func NewUpdate[I, O any](name string) Update[I, O] {
+	return Update[I, O]{Name: name}
+}
+
+// Send 向工作流发送信号
+func (s Signal[T]) Send(ctx context.Context, c client.Client, workflowID string, arg T) error {
+	return c.SignalWorkflow(ctx, workflowID, "", s.Name, arg)
+}
+
+// Channel 在工作流中返回信号的通道
+func (s Signal[T]) Channel(ctx workflow.Context) workflow.ReceiveChannel {
+	return workflow.GetSignalChannel(ctx, s.Name)
+}
+
+// Receive 在工作流中阻塞等待信号
+func (s Signal[T]) Receive(ctx workflow.Context) T {
+	var arg T
+	s.Channel(ctx).Receive(ctx, &arg)
+	return arg
+}
+
+// ReceiveAsync 在工作流中非阻塞地读取信号，没有信号时 ok 为 false
+func (s Signal[T]) ReceiveAsync(ctx workflow.Context) (arg T, ok bool) {
+	ok = s.Channel(ctx).ReceiveAsync(&arg)
+	return arg, ok
+}
+
+// Query 查询工作流
+func (q Query[T]) Query(ctx context.Context, c client.Client, workflowID string) (T, error) {
+	var result T
+
+	value, err := c.QueryWorkflow(ctx, workflowID, "", q.Name)
+	if err != nil {
+		return result, err
+	}
+	err = value.Get(&result)
+
+	return result, err
+}
+
+// SetHandler 在工作流中注册查询处理函数
+func (q Query[T]) SetHandler(ctx workflow.Context, handler func() (T, error)) error {
+	return workflow.SetQueryHandler(ctx, q.Name, handler)
+}
+
+// Update 更新工作流并等待处理完成
+func (u Update[I, O]) Update(ctx context.Context, c client.Client, workflowID string, arg I) (O, error) {
+	var result O
+
+	handle, err := c.UpdateWorkflow(ctx, client.UpdateWorkflowOptions{
+		WorkflowID:   workflowID,
+		UpdateName:   u.Name,
+		Args:         []any{arg},
+		WaitForStage: client.WorkflowUpdateStageCompleted,
+	})
+	if err != nil {
+		return result, err
+	}
+	err = handle.Get(ctx, &result)
+
+	return result, err
+}
+
+// SetHandler 在工作流中注册更新处理函数
+func (u Update[I, O]) SetHandler(ctx workflow.Context, handler func(workflow.Context, I) (O, error)) error {
+	return workflow.SetUpdateHandler(ctx, u.Name, handler)
+}
@@ -0,0 +1,40 @@
+package temporalx
+
+import (
+	"io"
+	"time"
+
+	prom "github.com/prometheus/client_golang/prometheus"
+	"github.com/uber-go/tally/v4"
+	"github.com/uber-go/tally/v4/prometheus"
+	"go.temporal.io/sdk/client"
+	sdktally "go.temporal.io/sdk/contrib/tally"
+	"go.temporal.io/sdk/log"
+)
+
+const metricsReportInterval = time.Second
+
+// newPrometheusHandler 创建上报到 Prometheus 的指标处理器，指标在 c.ListenAddress 的 /metrics 暴露
+func newPrometheusHandler(c MetricsConfig, logger log.Logger) (client.MetricsHandler, io.Closer, error) {
+	reporter, err := prometheus.Configuration{
+		ListenAddress: c.ListenAddress,
+		TimerType:     "histogram",
+	}.NewReporter(prometheus.ConfigurationOptions{
+		Registry: prom.NewRegistry(),
+		OnError: func(err error) {
+			logger.Error("prometheus reporter error", "error", err)
+		},
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	scope, closer := tally.NewRootScope(tally.ScopeOptions{
+		Prefix:          c.Prefix,
+		CachedReporter:  reporter,
+		Separator:       prometheus.DefaultSeparator,
+		SanitizeOptions: &sdktally.PrometheusSanitizeOptions,
+	}, metricsReportInterval)
+
+	return sdktally.NewMetricsHandler(sdktally.NewPrometheusNamingScope(scope)), closer, nil
+}
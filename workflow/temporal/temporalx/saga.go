@@ -0,0 +1,115 @@
+package temporalx
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"runtime"
+
+	"go.temporal.io/sdk/workflow"
+)
+
+type (
+	// SagaOption Saga 选项
+	SagaOption func(s *Saga)
+
+	// Saga 记录每个已完成步骤的补偿活动，失败时按相反顺序执行补偿
+	//
+	//	saga := temporalx.NewSaga(ctx)
+	//	if _, err := temporalx.ExecuteActivity[string](ctx, "ProcessPayment", input); err != nil {
+	//		return errors.Join(err, saga.Compensate())
+	//	}
+	//	saga.AddCompensation("RefundPayment", input.OrderID)
+	Saga struct {
+		ctx           workflow.Context
+		compensations []compensation
+		opts          []ActivityOption
+		parallel      bool
+	}
+
+	compensation struct {
+		activity any
+		input    any
+		opts     []ActivityOption
+	}
+)
+
+// WithParallelCompensation 并行执行所有补偿，默认按相反顺序逐个执行
+func WithParallelCompensation() SagaOption {
+	return func(s *Saga) {
+		s.parallel = true
+	}
+}
+
+// WithCompensationOptions 设置所有补偿活动的默认选项，例如重试策略
+func WithCompensationOptions(opts ...ActivityOption) SagaOption {
+	return func(s *Saga) {
+		s.opts = append(s.opts, opts...)
+	}
+}
+
+// NewSaga 创建 Saga
+func NewSaga(ctx workflow.Context, opts ...SagaOption) *Saga {
+	s := &Saga{ctx: ctx}
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	return s
+}
+
+// AddCompensation 添加补偿活动，opts 覆盖 WithCompensationOptions 设置的选项
+func (s *Saga) AddCompensation(activity, input any, opts ...ActivityOption) {
+	s.compensations = append(s.compensations, compensation{
+		activity: activity,
+		input:    input,
+		opts:     opts,
+	})
+}
+
+// Compensate 按相反顺序执行补偿，某个补偿失败时继续执行其余补偿，返回所有失败的错误。
+// 补偿在独立的上下文中执行，工作流被取消时也会执行。
+func (s *Saga) Compensate() error {
+	ctx, _ := workflow.NewDisconnectedContext(s.ctx)
+	logger := workflow.GetLogger(ctx)
+
+	futures := make([]workflow.Future, len(s.compensations))
+	wait := func(i int) error {
+		if err := futures[i].Get(ctx, nil); err != nil {
+			logger.Error("saga compensation failed", "activity", activityName(s.compensations[i].activity), "error", err)
+			return err
+		}
+		return nil
+	}
+
+	var errs []error
+	for i := len(s.compensations) - 1; i >= 0; i-- {
+		c := s.compensations[i]
+		futures[i] = executeActivity(ctx, c.activity, c.input, append(s.opts[:len(s.opts):len(s.opts)], c.opts...)...)
+		if !s.parallel {
+			if err := wait(i); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+	if s.parallel {
+		for i := len(s.compensations) - 1; i >= 0; i-- {
+			if err := wait(i); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+	s.compensations = nil
+
+	return errors.Join(errs...)
+}
+
+func activityName(activity any) string {
+	if name, ok := activity.(string); ok {
+		return name
+	}
+	if v := reflect.ValueOf(activity); v.Kind() == reflect.Func {
+		return runtime.FuncForPC(v.Pointer()).Name()
+	}
+	return fmt.Sprintf("%T", activity)
+}
@@ -0,0 +1,82 @@
+package temporalx
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	enumspb "go.temporal.io/api/enums/v1"
+	"go.temporal.io/sdk/client"
+	"go.temporal.io/sdk/temporal"
+)
+
+// CronOptions 定时工作流配置
+type CronOptions struct {
+	ID        string                        // 调度 ID，同时作为工作流 ID 前缀
+	Cron      []string                      // cron 表达式，例如 "0 2 * * *"
+	Every     time.Duration                 // 固定间隔，可与 Cron 同时使用
+	Jitter    time.Duration                 // 每次触发随机延迟 [0, Jitter)，避免大量任务同时启动
+	Overlap   enumspb.ScheduleOverlapPolicy // 上次执行未结束时的处理策略，默认跳过
+	Workflow  any                           // 工作流函数或名称
+	Args      []any                         // 工作流参数
+	TaskQueue string                        // 任务队列
+	Paused    bool                          // 创建后暂停
+	Note      string                        // 备注
+}
+
+// CreateCron 创建定时工作流（基于 Temporal Schedule），已存在时更新配置
+func CreateCron(ctx context.Context, c client.Client, o CronOptions) (client.ScheduleHandle, error) {
+	if len(o.Cron) == 0 && o.Every <= 0 {
+		return nil, errors.New("temporalx: cron or every must be set")
+	}
+	if o.Overlap == enumspb.SCHEDULE_OVERLAP_POLICY_UNSPECIFIED {
+		o.Overlap = enumspb.SCHEDULE_OVERLAP_POLICY_SKIP
+	}
+
+	spec := client.ScheduleSpec{
+		CronExpressions: o.Cron,
+		Jitter:          o.Jitter,
+	}
+	if o.Every > 0 {
+		spec.Intervals = []client.ScheduleIntervalSpec{{Every: o.Every}}
+	}
+	action := &client.ScheduleWorkflowAction{
+		ID:        o.ID,
+		Workflow:  o.Workflow,
+		Args:      o.Args,
+		TaskQueue: o.TaskQueue,
+	}
+
+	scheduleClient := c.ScheduleClient()
+	handle, err := scheduleClient.Create(ctx, client.ScheduleOptions{
+		ID:      o.ID,
+		Spec:    spec,
+		Action:  action,
+		Overlap: o.Overlap,
+		Paused:  o.Paused,
+		Note:    o.Note,
+	})
+	if !errors.Is(err, temporal.ErrScheduleAlreadyRunning) {
+		return handle, err
+	}
+
+	handle = scheduleClient.GetHandle(ctx, o.ID)
+	err = handle.Update(ctx, client.ScheduleUpdateOptions{
+		DoUpdate: func(input client.ScheduleUpdateInput) (*client.ScheduleUpdate, error) {
+			schedule := input.Description.Schedule
+			schedule.Spec = &spec
+			schedule.Action = action
+			if schedule.Policy == nil {
+				schedule.Policy = &client.SchedulePolicies{}
+			}
+			schedule.Policy.Overlap = o.Overlap
+			return &client.ScheduleUpdate{Schedule: &schedule}, nil
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("temporalx: update schedule %s: %w", o.ID, err)
+	}
+
+	return handle, nil
+}
@@ -0,0 +1,91 @@
+package testing
+
+import (
+	"reflect"
+	gotesting "testing"
+
+	"github.com/stretchr/testify/mock"
+	"go.temporal.io/sdk/testsuite"
+)
+
+// Env 工作流测试环境，测试结束时检查所有活动 mock 的期望是否满足
+type Env struct {
+	*testsuite.TestWorkflowEnvironment
+	t gotesting.TB
+}
+
+// NewEnv 创建工作流测试环境，activities 为需要真实执行的活动（未 mock 的活动需要注册）
+func NewEnv(t gotesting.TB, activities ...any) *Env {
+	var suite testsuite.WorkflowTestSuite
+	env := suite.NewTestWorkflowEnvironment()
+	for _, act := range activities {
+		env.RegisterActivity(act)
+	}
+
+	e := &Env{TestWorkflowEnvironment: env, t: t}
+	t.Cleanup(func() {
+		env.AssertExpectations(t)
+	})
+
+	return e
+}
+
+// ExpectActivity mock 活动并期望执行 times 次（0 表示至少一次），返回 result 和 err。
+// args 为活动参数（不含 context），未指定时匹配任意参数；activity 为名称时必须指定 args。
+func (e *Env) ExpectActivity(activity any, times int, result any, err error, args ...any) *testsuite.MockCallWrapper {
+	matchers := []any{mock.Anything} // context
+	if len(args) > 0 {
+		matchers = append(matchers, args...)
+	} else {
+		matchers = append(matchers, anyArgs(activity)...)
+	}
+
+	returns := []any{err}
+	if result != nil || hasResult(activity) {
+		returns = []any{result, err}
+	}
+
+	call := e.OnActivity(activity, matchers...).Return(returns...)
+	if times > 0 {
+		call.Times(times)
+	}
+
+	return call
+}
+
+// ExecuteWorkflow 执行工作流并返回结果，工作流没有完成时测试立即失败
+func ExecuteWorkflow[O any](e *Env, workflow any, args ...any) (O, error) {
+	e.t.Helper()
+
+	var result O
+	e.TestWorkflowEnvironment.ExecuteWorkflow(workflow, args...)
+	if !e.IsWorkflowCompleted() {
+		e.t.Fatal("workflow not completed")
+	}
+	if err := e.GetWorkflowError(); err != nil {
+		return result, err
+	}
+
+	err := e.GetWorkflowResult(&result)
+	return result, err
+}
+
+// anyArgs 按活动函数的参数个数（不含 context）返回 mock.Anything
+func anyArgs(activity any) []any {
+	t := reflect.TypeOf(activity)
+	if t == nil || t.Kind() != reflect.Func || t.NumIn() <= 1 {
+		return nil
+	}
+
+	args := make([]any, t.NumIn()-1)
+	for i := range args {
+		args[i] = mock.Anything
+	}
+	return args
+}
+
+// hasResult 判断活动函数是否除 error 外还有返回值
+func hasResult(activity any) bool {
+	t := reflect.TypeOf(activity)
+	return t != nil && t.Kind() == reflect.Func && t.NumOut() > 1
+}
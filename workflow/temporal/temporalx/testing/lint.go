@@ -0,0 +1,21 @@
+package testing
+
+import (
+	gotesting "testing"
+
+	"github.com/tedwangl/go-util/pkg/workflowlint"
+)
+
+// CheckDeterminism 检查 dir 下的工作流函数是否有破坏确定性重放的代码，
+// 例如 time.Now、随机数、I/O、原生 goroutine/channel 和遍历 map，每个问题报告一个错误
+func CheckDeterminism(t gotesting.TB, dir string) {
+	t.Helper()
+
+	issues, err := workflowlint.CheckDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, issue := range issues {
+		t.Error(issue)
+	}
+}
@@ -0,0 +1,47 @@
+// Package testing 封装 Temporal 测试套件：历史重放检查、活动 mock 和确定性检查
+package testing
+
+import (
+	"path/filepath"
+	"strings"
+	gotesting "testing"
+
+	"go.temporal.io/sdk/worker"
+
+	"github.com/tedwangl/go-util/pkg/logger/zapx"
+)
+
+// ReplayHistory 使用导出的 JSON 历史（temporal workflow show --output json 或 Web UI 下载）重放工作流，
+// 工作流代码的改动与历史不兼容时测试失败
+func ReplayHistory(t gotesting.TB, historyFile string, workflows ...any) {
+	t.Helper()
+
+	replayer := worker.NewWorkflowReplayer()
+	for _, wf := range workflows {
+		replayer.RegisterWorkflow(wf)
+	}
+
+	if err := replayer.ReplayWorkflowHistoryFromJSONFile(zapx.NewTemporalLogger(), historyFile); err != nil {
+		t.Errorf("replay %s: %v", historyFile, err)
+	}
+}
+
+// ReplayHistoryDir 重放目录下所有 .json 历史，每个文件一个子测试
+func ReplayHistoryDir(t *gotesting.T, dir string, workflows ...any) {
+	t.Helper()
+
+	files, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) == 0 {
+		t.Skipf("no history in %s", dir)
+	}
+
+	for _, file := range files {
+		name := strings.TrimSuffix(filepath.Base(file), ".json")
+		t.Run(name, func(t *gotesting.T) {
+			ReplayHistory(t, file, workflows...)
+		})
+	}
+}
@@ -0,0 +1,190 @@
+package temporalx
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+
+	"go.temporal.io/sdk/client"
+	"go.temporal.io/sdk/interceptor"
+	"go.temporal.io/sdk/log"
+	"go.temporal.io/sdk/worker"
+
+	"github.com/tedwangl/go-util/pkg/logger/zapx"
+)
+
+type (
+	// Option Worker 选项
+	Option func(o *options)
+
+	options struct {
+		client             client.Client
+		logger             log.Logger
+		metricsHandler     client.MetricsHandler
+		interceptors       []interceptor.WorkerInterceptor
+		clientInterceptors []interceptor.ClientInterceptor
+	}
+
+	// Worker 封装 Temporal 客户端和 Worker，负责注册、启动和优雅退出
+	Worker struct {
+		conf      Config
+		client    client.Client
+		worker    worker.Worker
+		ownClient bool
+		closers   []io.Closer
+		closeOnce sync.Once
+	}
+)
+
+// WithClient 使用已有的客户端，Worker 关闭时不会关闭该客户端
+func WithClient(c client.Client) Option {
+	return func(o *options) {
+		o.client = c
+	}
+}
+
+// WithLogger 设置日志，默认使用 zapx（模块名 temporal）
+func WithLogger(logger log.Logger) Option {
+	return func(o *options) {
+		o.logger = logger
+	}
+}
+
+// WithMetricsHandler 设置指标处理器，优先于 Config.Metrics
+func WithMetricsHandler(handler client.MetricsHandler) Option {
+	return func(o *options) {
+		o.metricsHandler = handler
+	}
+}
+
+// WithInterceptors 添加 Worker 拦截器
+func WithInterceptors(interceptors ...interceptor.WorkerInterceptor) Option {
+	return func(o *options) {
+		o.interceptors = append(o.interceptors, interceptors...)
+	}
+}
+
+// WithClientInterceptors 添加客户端拦截器（使用 WithClient 时无效）
+func WithClientInterceptors(interceptors ...interceptor.ClientInterceptor) Option {
+	return func(o *options) {
+		o.clientInterceptors = append(o.clientInterceptors, interceptors...)
+	}
+}
+
+// NewWorker 按配置创建 Worker，未使用 WithClient 时会连接 Temporal 服务
+func NewWorker(conf Config, opts ...Option) (*Worker, error) {
+	if err := conf.normalize(); err != nil {
+		return nil, err
+	}
+
+	var o options
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if o.logger == nil {
+		o.logger = zapx.NewTemporalLogger()
+	}
+
+	w := &Worker{conf: conf}
+	if o.metricsHandler == nil && len(conf.Metrics.ListenAddress) > 0 {
+		handler, closer, err := newPrometheusHandler(conf.Metrics, o.logger)
+		if err != nil {
+			return nil, fmt.Errorf("temporalx: create metrics handler: %w", err)
+		}
+		o.metricsHandler = handler
+		w.closers = append(w.closers, closer)
+	}
+
+	w.client = o.client
+	if w.client == nil {
+		c, err := client.Dial(client.Options{
+			HostPort:       conf.HostPort,
+			Namespace:      conf.Namespace,
+			Identity:       conf.Identity,
+			Logger:         o.logger,
+			MetricsHandler: o.metricsHandler,
+			Interceptors:   o.clientInterceptors,
+		})
+		if err != nil {
+			w.Close()
+			return nil, fmt.Errorf("temporalx: dial %s: %w", conf.HostPort, err)
+		}
+		w.client, w.ownClient = c, true
+	}
+
+	w.worker = worker.New(w.client, conf.TaskQueue, worker.Options{
+		MaxConcurrentActivityExecutionSize:     conf.MaxConcurrentActivities,
+		MaxConcurrentWorkflowTaskExecutionSize: conf.MaxConcurrentWorkflowTasks,
+		WorkerActivitiesPerSecond:              conf.MaxActivitiesPerSecond,
+		TaskQueueActivitiesPerSecond:           conf.MaxTaskQueueActivitiesPerSecond,
+		Identity:                               conf.Identity,
+		WorkerStopTimeout:                      conf.ShutdownTimeout,
+		Interceptors:                           o.interceptors,
+	})
+
+	return w, nil
+}
+
+// Client 返回 Worker 使用的客户端，可用于启动工作流
+func (w *Worker) Client() client.Client {
+	return w.client
+}
+
+// RegisterWorkflows 注册工作流函数
+func (w *Worker) RegisterWorkflows(workflows ...any) *Worker {
+	for _, wf := range workflows {
+		w.worker.RegisterWorkflow(wf)
+	}
+	return w
+}
+
+// RegisterActivities 注册活动，传入结构体指针时注册其所有导出方法
+func (w *Worker) RegisterActivities(activities ...any) *Worker {
+	for _, act := range activities {
+		w.worker.RegisterActivity(act)
+	}
+	return w
+}
+
+// Start 启动 Worker 后立即返回，使用 Stop 停止
+func (w *Worker) Start() error {
+	return w.worker.Start()
+}
+
+// Run 启动 Worker 并阻塞，直到 ctx 结束或收到 SIGINT/SIGTERM，然后优雅退出
+func (w *Worker) Run(ctx context.Context) error {
+	defer w.Close()
+
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	stopCh := make(chan any)
+	go func() {
+		<-ctx.Done()
+		close(stopCh)
+	}()
+
+	return w.worker.Run(stopCh)
+}
+
+// Stop 停止 Worker，等待执行中的活动完成（最多 ShutdownTimeout），并释放资源
+func (w *Worker) Stop() {
+	w.worker.Stop()
+	w.Close()
+}
+
+// Close 释放 Worker 创建的客户端和指标资源
+func (w *Worker) Close() {
+	w.closeOnce.Do(func() {
+		if w.ownClient {
+			w.client.Close()
+		}
+		for _, closer := range w.closers {
+			_ = closer.Close()
+		}
+	})
+}
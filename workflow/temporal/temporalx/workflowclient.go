@@ -0,0 +1,128 @@
+package temporalx
+
+import (
+	"context"
+	"time"
+
+	enumspb "go.temporal.io/api/enums/v1"
+	"go.temporal.io/sdk/client"
+	"go.temporal.io/sdk/temporal"
+)
+
+type (
+	// WorkflowOption 启动工作流的选项
+	WorkflowOption func(o *client.StartWorkflowOptions)
+
+	// WorkflowClient 类型化的工作流客户端，I 为工作流输入，O 为工作流结果。
+	// 工作流 ID 由 ID 前缀和业务 key 组成，例如 order-ORD-12345，便于按业务 key 发送信号和查询。
+	WorkflowClient[I, O any] struct {
+		client    client.Client
+		workflow  any
+		taskQueue string
+		idPrefix  string
+		opts      []WorkflowOption
+	}
+
+	// WorkflowRun 类型化的工作流执行
+	WorkflowRun[O any] struct {
+		client.WorkflowRun
+	}
+)
+
+// WithExecutionTimeout 设置工作流执行（包括 continue-as-new）的超时时间
+func WithExecutionTimeout(timeout time.Duration) WorkflowOption {
+	return func(o *client.StartWorkflowOptions) {
+		o.WorkflowExecutionTimeout = timeout
+	}
+}
+
+// WithIDReusePolicy 设置工作流 ID 的复用策略
+func WithIDReusePolicy(policy enumspb.WorkflowIdReusePolicy) WorkflowOption {
+	return func(o *client.StartWorkflowOptions) {
+		o.WorkflowIDReusePolicy = policy
+	}
+}
+
+// WithSearchAttributes 设置工作流的搜索属性
+func WithSearchAttributes(attributes temporal.SearchAttributes) WorkflowOption {
+	return func(o *client.StartWorkflowOptions) {
+		o.TypedSearchAttributes = attributes
+	}
+}
+
+// NewWorkflowClient 创建类型化的工作流客户端，workflow 为工作流函数或名称，
+// opts 作为每次启动的默认选项
+func NewWorkflowClient[I, O any](c client.Client, workflow any, taskQueue, idPrefix string,
+	opts ...WorkflowOption) *WorkflowClient[I, O] {
+	return &WorkflowClient[I, O]{
+		client:    c,
+		workflow:  workflow,
+		taskQueue: taskQueue,
+		idPrefix:  idPrefix,
+		opts:      opts,
+	}
+}
+
+// WorkflowID 返回业务 key 对应的工作流 ID
+func (w *WorkflowClient[I, O]) WorkflowID(key string) string {
+	if len(w.idPrefix) == 0 {
+		return key
+	}
+	return w.idPrefix + "-" + key
+}
+
+// Start 启动工作流后立即返回
+func (w *WorkflowClient[I, O]) Start(ctx context.Context, key string, input I, opts ...WorkflowOption) (WorkflowRun[O], error) {
+	o := client.StartWorkflowOptions{
+		ID:        w.WorkflowID(key),
+		TaskQueue: w.taskQueue,
+	}
+	for _, opt := range w.opts {
+		opt(&o)
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	run, err := w.client.ExecuteWorkflow(ctx, o, w.workflow, input)
+	return WorkflowRun[O]{WorkflowRun: run}, err
+}
+
+// Execute 启动工作流并等待结果
+func (w *WorkflowClient[I, O]) Execute(ctx context.Context, key string, input I, opts ...WorkflowOption) (O, error) {
+	run, err := w.Start(ctx, key, input, opts...)
+	if err != nil {
+		var result O
+		return result, err
+	}
+
+	return run.Result(ctx)
+}
+
+// Result 等待业务 key 对应的最新一次执行的结果
+func (w *WorkflowClient[I, O]) Result(ctx context.Context, key string) (O, error) {
+	run := w.client.GetWorkflow(ctx, w.WorkflowID(key), "")
+	return WorkflowRun[O]{WorkflowRun: run}.Result(ctx)
+}
+
+// Cancel 请求取消工作流
+func (w *WorkflowClient[I, O]) Cancel(ctx context.Context, key string) error {
+	return w.client.CancelWorkflow(ctx, w.WorkflowID(key), "")
+}
+
+// Terminate 强制终止工作流，不会执行补偿
+func (w *WorkflowClient[I, O]) Terminate(ctx context.Context, key, reason string) error {
+	return w.client.TerminateWorkflow(ctx, w.WorkflowID(key), "", reason)
+}
+
+// Client 返回底层客户端
+func (w *WorkflowClient[I, O]) Client() client.Client {
+	return w.client
+}
+
+// Result 等待工作流完成并返回结果
+func (r WorkflowRun[O]) Result(ctx context.Context) (O, error) {
+	var result O
+	err := r.Get(ctx, &result)
+	return result, err
+}
@@ -1,16 +1,20 @@
 package workflows
 
 import (
+	"errors"
 	"time"
 
 	"go.temporal.io/sdk/workflow"
+
+	"github.com/tedwangl/go-util/workflow/temporal/temporalx"
 )
 
 // OrderWorkflowInput 订单工作流输入
 type OrderWorkflowInput struct {
-	OrderID    string
-	CustomerID string
-	Amount     float64
+	OrderID         string
+	CustomerID      string
+	Amount          float64
+	RequireApproval bool // 为 true 时验证后等待 ApproveSignal
 }
 
 // OrderWorkflowResult 订单工作流结果
@@ -25,6 +29,13 @@ func OrderWorkflow(ctx workflow.Context, input OrderWorkflowInput) (*OrderWorkfl
 	logger := workflow.GetLogger(ctx)
 	logger.Info("订单工作流开始", "OrderID", input.OrderID)
 
+	status := "验证中"
+	if err := StatusQuery.SetHandler(ctx, func() (string, error) {
+		return status, nil
+	}); err != nil {
+		return nil, err
+	}
+
 	// 配置活动选项
 	ao := workflow.ActivityOptions{
 		StartToCloseTimeout: 10 * time.Second,
@@ -37,6 +48,9 @@ func OrderWorkflow(ctx workflow.Context, input OrderWorkflowInput) (*OrderWorkfl
 	}
 	ctx = workflow.WithActivityOptions(ctx, ao)
 
+	// 每完成一步登记对应的补偿，失败时按相反顺序执行
+	saga := temporalx.NewSaga(ctx, temporalx.WithCompensationOptions(temporalx.WithTimeout(10*time.Second)))
+
 	// 步骤 1: 验证订单
 	var validateResult bool
 	err := workflow.ExecuteActivity(ctx, "ValidateOrder", input).Get(ctx, &validateResult)
@@ -51,25 +65,44 @@ func OrderWorkflow(ctx workflow.Context, input OrderWorkflowInput) (*OrderWorkfl
 			Timestamp: workflow.Now(ctx),
 		}, nil
 	}
+	saga.AddCompensation("CancelOrder", input.OrderID)
+
+	// 需要审批时等待审批信号，拒绝时取消订单
+	if input.RequireApproval {
+		status = "待审批"
+		approval := ApproveSignal.Receive(ctx)
+		if !approval.Approved {
+			logger.Info("订单被拒绝", "OrderID", input.OrderID, "Approver", approval.Approver)
+			if err = saga.Compensate(); err != nil {
+				return nil, err
+			}
+			return &OrderWorkflowResult{
+				OrderID:   input.OrderID,
+				Status:    "已拒绝",
+				Timestamp: workflow.Now(ctx),
+			}, nil
+		}
+	}
 
 	// 步骤 2: 处理支付
+	status = "支付中"
 	var paymentResult string
 	err = workflow.ExecuteActivity(ctx, "ProcessPayment", input).Get(ctx, &paymentResult)
 	if err != nil {
 		logger.Error("支付处理失败", "error", err)
 		// 补偿操作：取消订单
-		workflow.ExecuteActivity(ctx, "CancelOrder", input.OrderID)
-		return nil, err
+		return nil, errors.Join(err, saga.Compensate())
 	}
+	saga.AddCompensation("RefundPayment", input.OrderID)
 
 	// 步骤 3: 发货
+	status = "发货中"
 	var shipmentResult string
 	err = workflow.ExecuteActivity(ctx, "ShipOrder", input).Get(ctx, &shipmentResult)
 	if err != nil {
 		logger.Error("发货失败", "error", err)
-		// 补偿操作：退款
-		workflow.ExecuteActivity(ctx, "RefundPayment", input.OrderID)
-		return nil, err
+		// 补偿操作：退款、取消订单
+		return nil, errors.Join(err, saga.Compensate())
 	}
 
 	// 步骤 4: 发送通知
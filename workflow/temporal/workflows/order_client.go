@@ -0,0 +1,55 @@
+package workflows
+
+import (
+	"context"
+
+	"go.temporal.io/sdk/client"
+
+	"github.com/tedwangl/go-util/workflow/temporal/temporalx"
+)
+
+// TaskQueue 示例工作流的任务队列
+const TaskQueue = "example-task-queue"
+
+// Approval 审批信号参数
+type Approval struct {
+	Approved bool
+	Approver string
+	Comment  string
+}
+
+var (
+	// ApproveSignal 订单审批信号，OrderWorkflowInput.RequireApproval 为 true 时工作流等待该信号
+	ApproveSignal = temporalx.NewSignal[Approval]("approve")
+	// StatusQuery 查询订单工作流当前状态
+	StatusQuery = temporalx.NewQuery[string]("status")
+)
+
+// OrderWorkflowClient 订单工作流客户端，工作流 ID 为 order-<OrderID>
+type OrderWorkflowClient struct {
+	*temporalx.WorkflowClient[OrderWorkflowInput, *OrderWorkflowResult]
+}
+
+// NewOrderWorkflowClient 创建订单工作流客户端
+func NewOrderWorkflowClient(c client.Client) *OrderWorkflowClient {
+	return &OrderWorkflowClient{
+		WorkflowClient: temporalx.NewWorkflowClient[OrderWorkflowInput, *OrderWorkflowResult](
+			c, OrderWorkflow, TaskQueue, "order"),
+	}
+}
+
+// Start 启动订单工作流
+func (c *OrderWorkflowClient) Start(ctx context.Context, input OrderWorkflowInput) (
+	temporalx.WorkflowRun[*OrderWorkflowResult], error) {
+	return c.WorkflowClient.Start(ctx, input.OrderID, input)
+}
+
+// SignalApprove 审批订单
+func (c *OrderWorkflowClient) SignalApprove(ctx context.Context, orderID string, approval Approval) error {
+	return ApproveSignal.Send(ctx, c.Client(), c.WorkflowID(orderID), approval)
+}
+
+// QueryStatus 查询订单工作流当前状态
+func (c *OrderWorkflowClient) QueryStatus(ctx context.Context, orderID string) (string, error) {
+	return StatusQuery.Query(ctx, c.Client(), c.WorkflowID(orderID))
+}